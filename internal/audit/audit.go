@@ -0,0 +1,196 @@
+// Package audit maintains an append-only JSONL log of every automated action
+// otto takes against a repository or PR provider: pushes, comments, thread
+// resolutions, and build retries. The log exists so operators can answer
+// "what did the bot do, and when?" when otto holds push rights.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/store"
+)
+
+// Action identifies the kind of automated action recorded in the log.
+type Action string
+
+const (
+	ActionCommitPushed        Action = "commit_pushed"
+	ActionForcePushed         Action = "force_pushed"
+	ActionCommentPosted       Action = "comment_posted"
+	ActionInlineCommentPosted Action = "inline_comment_posted"
+	ActionCommentReplied      Action = "comment_replied"
+	ActionThreadResolved      Action = "thread_resolved"
+	ActionBuildRetried        Action = "build_retried"
+	ActionBuildCanceled       Action = "build_canceled"
+	ActionPushBlocked         Action = "push_blocked"
+	ActionBranchDeleted       Action = "branch_deleted"
+	ActionMarkedReady         Action = "marked_ready"
+	ActionReviewSubmitted     Action = "review_submitted"
+)
+
+// DiffStats summarizes the size of a pushed change.
+type DiffStats struct {
+	Files      int `json:"files"`
+	Insertions int `json:"insertions"`
+	Deletions  int `json:"deletions"`
+}
+
+// Entry is a single audit log record.
+type Entry struct {
+	Time     time.Time  `json:"time"`
+	Action   Action     `json:"action"`
+	Provider string     `json:"provider,omitempty"`
+	PRID     string     `json:"pr_id,omitempty"`
+	PRURL    string     `json:"pr_url,omitempty"`
+	Branch   string     `json:"branch,omitempty"`
+	Commit   string     `json:"commit,omitempty"`
+	ThreadID string     `json:"thread_id,omitempty"`
+	BuildID  string     `json:"build_id,omitempty"`
+	Detail   string     `json:"detail,omitempty"` // resolution, comment excerpt, commit message, etc.
+	Stats    *DiffStats `json:"diff_stats,omitempty"`
+}
+
+// Filter narrows the entries returned by Read.
+type Filter struct {
+	PRID  string    // only entries for this PR ID
+	Since time.Time // only entries at or after this time
+	Limit int       // keep only the most recent N entries (0 = all)
+}
+
+// maxDetailLen caps free-form detail text so comment bodies don't bloat the log.
+const maxDetailLen = 200
+
+// Path returns the audit log location under the otto data directory.
+func Path() string {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		dataDir = filepath.Join(os.TempDir(), "otto")
+	}
+	return filepath.Join(dataDir, "audit.jsonl")
+}
+
+// Record appends an entry to the audit log. Time defaults to now.
+func Record(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	e.Detail = truncate(e.Detail, maxDetailLen)
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshaling audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	path := Path()
+	return store.WithLock(path, store.DefaultLockTimeout, func() error {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("opening audit log: %w", err)
+		}
+		defer f.Close()
+		if _, err := f.Write(line); err != nil {
+			return fmt.Errorf("writing audit log: %w", err)
+		}
+		return nil
+	})
+}
+
+// Log records an entry and logs (rather than returns) any failure. Audit
+// writes must never abort the action they describe.
+func Log(e Entry) {
+	if err := Record(e); err != nil {
+		slog.Warn("failed to write audit log entry", "action", e.Action, "prID", e.PRID, "error", err)
+	}
+}
+
+// Read returns entries matching the filter in chronological order.
+// A missing log file yields no entries and no error.
+func Read(filter Filter) ([]Entry, error) {
+	path := Path()
+	var entries []Entry
+	err := store.WithReadLock(path, store.DefaultLockTimeout, func() error {
+		f, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return fmt.Errorf("opening audit log: %w", err)
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var e Entry
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				// Skip corrupt lines rather than failing the whole query.
+				continue
+			}
+			if filter.PRID != "" && e.PRID != filter.PRID {
+				continue
+			}
+			if !filter.Since.IsZero() && e.Time.Before(filter.Since) {
+				continue
+			}
+			entries = append(entries, e)
+		}
+		return scanner.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[len(entries)-filter.Limit:]
+	}
+	return entries, nil
+}
+
+var (
+	shortStatFiles      = regexp.MustCompile(`(\d+) files? changed`)
+	shortStatInsertions = regexp.MustCompile(`(\d+) insertions?\(\+\)`)
+	shortStatDeletions  = regexp.MustCompile(`(\d+) deletions?\(-\)`)
+)
+
+// ParseShortStat parses `git diff --shortstat` output, e.g.
+// " 3 files changed, 10 insertions(+), 2 deletions(-)".
+// Returns nil if the output contains no recognizable stats.
+func ParseShortStat(out string) *DiffStats {
+	var s DiffStats
+	found := false
+	if m := shortStatFiles.FindStringSubmatch(out); m != nil {
+		s.Files, _ = strconv.Atoi(m[1])
+		found = true
+	}
+	if m := shortStatInsertions.FindStringSubmatch(out); m != nil {
+		s.Insertions, _ = strconv.Atoi(m[1])
+		found = true
+	}
+	if m := shortStatDeletions.FindStringSubmatch(out); m != nil {
+		s.Deletions, _ = strconv.Atoi(m[1])
+		found = true
+	}
+	if !found {
+		return nil
+	}
+	return &s
+}
+
+// truncate shortens s to at most n runes, appending an ellipsis when cut.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
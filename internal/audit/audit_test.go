@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	old := time.Now().Add(-48 * time.Hour).UTC()
+	require.NoError(t, Record(Entry{Time: old, Action: ActionCommitPushed, PRID: "1", Commit: "aaaa1111"}))
+	require.NoError(t, Record(Entry{Action: ActionCommentReplied, PRID: "2", ThreadID: "7"}))
+	require.NoError(t, Record(Entry{Action: ActionBuildRetried, PRID: "1", BuildID: "99"}))
+
+	all, err := Read(Filter{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, ActionCommitPushed, all[0].Action)
+	assert.False(t, all[1].Time.IsZero(), "Record should default the timestamp")
+
+	byPR, err := Read(Filter{PRID: "1"})
+	require.NoError(t, err)
+	require.Len(t, byPR, 2)
+	assert.Equal(t, "99", byPR[1].BuildID)
+
+	recent, err := Read(Filter{Since: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Len(t, recent, 2)
+
+	limited, err := Read(Filter{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, limited, 1)
+	assert.Equal(t, ActionBuildRetried, limited[0].Action)
+}
+
+func TestRead_MissingFile(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	entries, err := Read(Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRecord_TruncatesDetail(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	long := make([]byte, 500)
+	for i := range long {
+		long[i] = 'x'
+	}
+	require.NoError(t, Record(Entry{Action: ActionCommentPosted, Detail: string(long)}))
+
+	entries, err := Read(Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Len(t, []rune(entries[0].Detail), maxDetailLen+1)
+}
+
+func TestParseShortStat(t *testing.T) {
+	s := ParseShortStat(" 3 files changed, 10 insertions(+), 2 deletions(-)\n")
+	require.NotNil(t, s)
+	assert.Equal(t, DiffStats{Files: 3, Insertions: 10, Deletions: 2}, *s)
+
+	s = ParseShortStat(" 1 file changed, 1 insertion(+)")
+	require.NotNil(t, s)
+	assert.Equal(t, DiffStats{Files: 1, Insertions: 1}, *s)
+
+	assert.Nil(t, ParseShortStat(""))
+}
+
+type stubBackend struct {
+	provider.PRBackend
+	retryErr error
+}
+
+func (s *stubBackend) Name() string { return "stub" }
+func (s *stubBackend) RetryBuild(ctx context.Context, pr *provider.PRInfo, buildID string) error {
+	return s.retryErr
+}
+func (s *stubBackend) CancelBuild(ctx context.Context, pr *provider.PRInfo, buildID string) error {
+	return nil
+}
+func (s *stubBackend) ResolveComment(ctx context.Context, pr *provider.PRInfo, threadID string, resolution provider.CommentResolution) error {
+	return nil
+}
+
+func TestWrapBackend_RecordsSuccessfulMutations(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	inner := &stubBackend{}
+	b := WrapBackend(inner)
+	assert.Same(t, b, WrapBackend(b), "wrapping twice should be a no-op")
+
+	pr := &provider.PRInfo{ID: "42", URL: "https://example.com/pr/42", SourceBranch: "feature"}
+	require.NoError(t, b.ResolveComment(context.Background(), pr, "5", provider.ResolutionWontFix))
+	require.NoError(t, b.RetryBuild(context.Background(), pr, "100"))
+	require.NoError(t, b.CancelBuild(context.Background(), pr, "102"))
+
+	inner.retryErr = errors.New("nope")
+	require.Error(t, b.RetryBuild(context.Background(), pr, "101"))
+
+	entries, err := Read(Filter{PRID: "42"})
+	require.NoError(t, err)
+	require.Len(t, entries, 3, "failed calls must not be recorded")
+	assert.Equal(t, ActionThreadResolved, entries[0].Action)
+	assert.Equal(t, "wontfix", entries[0].Detail)
+	assert.Equal(t, "stub", entries[0].Provider)
+	assert.Equal(t, "feature", entries[0].Branch)
+	assert.Equal(t, ActionBuildRetried, entries[1].Action)
+	assert.Equal(t, "100", entries[1].BuildID)
+	assert.Equal(t, ActionBuildCanceled, entries[2].Action)
+	assert.Equal(t, "102", entries[2].BuildID)
+}
@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// Backend wraps a provider.PRBackend and records every successful mutating
+// call (comments, replies, resolutions, build retries and cancellations,
+// publishing drafts, review verdicts) in the audit log.
+// Read-only calls pass straight through to the embedded backend.
+type Backend struct {
+	provider.PRBackend
+}
+
+// WrapBackend returns b wrapped for auditing. Already-wrapped backends are
+// returned unchanged so nested callers don't double-record.
+func WrapBackend(b provider.PRBackend) provider.PRBackend {
+	if _, ok := b.(*Backend); ok {
+		return b
+	}
+	return &Backend{PRBackend: b}
+}
+
+// Unwrap returns the underlying backend, e.g. for provider-specific type assertions.
+func (b *Backend) Unwrap() provider.PRBackend {
+	return b.PRBackend
+}
+
+// PostComment posts a general comment and records it.
+func (b *Backend) PostComment(ctx context.Context, pr *provider.PRInfo, body string) error {
+	if err := b.PRBackend.PostComment(ctx, pr, body); err != nil {
+		return err
+	}
+	Log(b.entry(ActionCommentPosted, pr, Entry{Detail: body}))
+	return nil
+}
+
+// PostInlineComment posts an inline comment and records it.
+func (b *Backend) PostInlineComment(ctx context.Context, pr *provider.PRInfo, comment provider.InlineComment) error {
+	if err := b.PRBackend.PostInlineComment(ctx, pr, comment); err != nil {
+		return err
+	}
+	Log(b.entry(ActionInlineCommentPosted, pr, Entry{Detail: comment.FilePath + ": " + comment.Body}))
+	return nil
+}
+
+// ReplyToComment replies to a thread and records it.
+func (b *Backend) ReplyToComment(ctx context.Context, pr *provider.PRInfo, threadID string, body string) error {
+	if err := b.PRBackend.ReplyToComment(ctx, pr, threadID, body); err != nil {
+		return err
+	}
+	Log(b.entry(ActionCommentReplied, pr, Entry{ThreadID: threadID, Detail: body}))
+	return nil
+}
+
+// ResolveComment resolves a thread and records it.
+func (b *Backend) ResolveComment(ctx context.Context, pr *provider.PRInfo, threadID string, resolution provider.CommentResolution) error {
+	if err := b.PRBackend.ResolveComment(ctx, pr, threadID, resolution); err != nil {
+		return err
+	}
+	Log(b.entry(ActionThreadResolved, pr, Entry{ThreadID: threadID, Detail: resolution.String()}))
+	return nil
+}
+
+// RetryBuild requeues a build and records it.
+func (b *Backend) RetryBuild(ctx context.Context, pr *provider.PRInfo, buildID string) error {
+	if err := b.PRBackend.RetryBuild(ctx, pr, buildID); err != nil {
+		return err
+	}
+	Log(b.entry(ActionBuildRetried, pr, Entry{BuildID: buildID}))
+	return nil
+}
+
+// CancelBuild cancels a build and records it.
+func (b *Backend) CancelBuild(ctx context.Context, pr *provider.PRInfo, buildID string) error {
+	if err := b.PRBackend.CancelBuild(ctx, pr, buildID); err != nil {
+		return err
+	}
+	Log(b.entry(ActionBuildCanceled, pr, Entry{BuildID: buildID}))
+	return nil
+}
+
+// MarkReady publishes a draft PR and records it.
+func (b *Backend) MarkReady(ctx context.Context, pr *provider.PRInfo) error {
+	if err := b.PRBackend.MarkReady(ctx, pr); err != nil {
+		return err
+	}
+	Log(b.entry(ActionMarkedReady, pr, Entry{}))
+	return nil
+}
+
+// SubmitReview submits a review verdict and records it.
+func (b *Backend) SubmitReview(ctx context.Context, pr *provider.PRInfo, verdict provider.ReviewVerdict, body string) error {
+	if err := b.PRBackend.SubmitReview(ctx, pr, verdict, body); err != nil {
+		return err
+	}
+	Log(b.entry(ActionReviewSubmitted, pr, Entry{Detail: verdict.String()}))
+	return nil
+}
+
+// entry fills in the provider and PR fields common to all backend actions.
+func (b *Backend) entry(action Action, pr *provider.PRInfo, e Entry) Entry {
+	e.Action = action
+	e.Provider = b.Name()
+	if pr != nil {
+		e.PRID = pr.ID
+		e.PRURL = pr.URL
+		e.Branch = pr.SourceBranch
+	}
+	return e
+}
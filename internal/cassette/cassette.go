@@ -0,0 +1,272 @@
+// Package cassette records the HTTP traffic of the provider backends to a
+// file and plays it back, so a poll cycle can be reproduced offline exactly
+// as the daemon saw it.
+//
+// A cassette is a JSON Lines file: a header line, then one line per
+// request and response in the order they happened. Request headers are not
+// recorded, so credentials never reach the file.
+package cassette
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotRecorded is returned when a replayed request has no recording.
+var ErrNotRecorded = errors.New("request not in cassette")
+
+// Header is the first line of a cassette.
+type Header struct {
+	RecordedAt time.Time       `json:"recorded_at"`
+	Meta       json.RawMessage `json:"meta,omitempty"` // caller-defined context, e.g. the state the cycle started from
+}
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"request_body,omitempty"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        string      `json:"body"`
+}
+
+// Recorder writes the exchanges of the transports it wraps to a cassette,
+// between Start and Stop.
+type Recorder struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// NewRecorder returns a recorder. It does not record until Start.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Wrap returns a transport that sends requests through base, or
+// http.DefaultTransport if nil, and records them to r.
+func (r *Recorder) Wrap(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &recordingTransport{rec: r, base: base}
+}
+
+type recordingTransport struct {
+	rec  *Recorder
+	base http.RoundTripper
+}
+
+// Start begins a new cassette at path, ending any current one. meta is
+// stored in the header.
+func (r *Recorder) Start(path string, meta any) error {
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("marshaling cassette metadata: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating cassette directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("creating cassette: %w", err)
+	}
+	enc := json.NewEncoder(f)
+	if err := enc.Encode(Header{RecordedAt: time.Now().UTC(), Meta: raw}); err != nil {
+		f.Close()
+		return fmt.Errorf("writing cassette header: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closeLocked()
+	r.f, r.enc = f, enc
+	return nil
+}
+
+// Stop ends the current cassette, if any.
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closeLocked()
+}
+
+func (r *Recorder) closeLocked() error {
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f, r.enc = nil, nil
+	return err
+}
+
+// RoundTrip sends req and records the exchange if a cassette is open.
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+	t.rec.mu.Lock()
+	defer t.rec.mu.Unlock()
+	if t.rec.enc != nil {
+		// A recording failure must not fail the request itself.
+		_ = t.rec.enc.Encode(Interaction{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			RequestBody: string(reqBody),
+			Status:      resp.StatusCode,
+			Header:      header,
+			Body:        string(body),
+		})
+	}
+	return resp, nil
+}
+
+// Player is an http.RoundTripper that answers requests from a cassette
+// instead of the network.
+type Player struct {
+	Header Header
+
+	mu      sync.Mutex
+	pending map[string][]*Interaction // by method and URL, in recorded order
+	total   int
+	replays int
+}
+
+// Load reads a cassette for playback.
+func Load(path string) (*Player, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening cassette: %w", err)
+	}
+	defer f.Close()
+
+	p := &Player{pending: make(map[string][]*Interaction)}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64<<20)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("reading cassette: %w", err)
+		}
+		return nil, fmt.Errorf("empty cassette %s", path)
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &p.Header); err != nil {
+		return nil, fmt.Errorf("parsing cassette header: %w", err)
+	}
+	for n := 2; scanner.Scan(); n++ {
+		var in Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &in); err != nil {
+			return nil, fmt.Errorf("parsing cassette line %d: %w", n, err)
+		}
+		key := in.Method + " " + in.URL
+		p.pending[key] = append(p.pending[key], &in)
+		p.total++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+	return p, nil
+}
+
+// Len returns the number of recorded interactions.
+func (p *Player) Len() int {
+	return p.total
+}
+
+// Replays returns the number of requests answered so far.
+func (p *Player) Replays() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.replays
+}
+
+// RoundTrip answers req with its recording. Repeated requests get their
+// recordings in order, the last one repeating once they run out. A request
+// whose body differs from every recording of its method and URL falls back
+// to those recordings, since bodies can carry timestamps.
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	url := req.URL.String()
+
+	p.mu.Lock()
+	in := p.next(req.Method+" "+url, string(body))
+	if in != nil {
+		p.replays++
+	}
+	p.mu.Unlock()
+	if in == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, req.Method, url)
+	}
+
+	return &http.Response{
+		StatusCode:    in.Status,
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        in.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(in.Body)),
+		ContentLength: int64(len(in.Body)),
+		Request:       req,
+	}, nil
+}
+
+// next takes the first pending recording under key with a matching body,
+// or the first one if none matches. The last recording is kept so it can
+// answer any further repeats.
+func (p *Player) next(key, body string) *Interaction {
+	ins := p.pending[key]
+	if len(ins) == 0 {
+		return nil
+	}
+	i := slices.IndexFunc(ins, func(in *Interaction) bool { return in.RequestBody == body })
+	if i < 0 {
+		i = 0
+	}
+	in := ins[i]
+	if len(ins) > 1 {
+		p.pending[key] = slices.Delete(slices.Clone(ins), i, i+1)
+	}
+	return in
+}
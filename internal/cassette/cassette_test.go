@@ -0,0 +1,87 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("ETag", "v1")
+		io.WriteString(w, r.Method+" "+r.URL.Path+" "+string(body)+" #"+string(rune('0'+calls)))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cycle.jsonl")
+	rec := NewRecorder()
+	client := &http.Client{Transport: rec.Wrap(nil)}
+	get := func(c *http.Client, url string) string {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := c.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return string(b)
+	}
+	post := func(c *http.Client, url, body string) (string, error) {
+		resp, err := c.Post(url, "text/plain", strings.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return string(b), nil
+	}
+
+	get(client, server.URL+"/before") // not recorded: no cassette open
+	require.NoError(t, rec.Start(path, map[string]string{"cycle": "1"}))
+	assert.Equal(t, "GET /a  #2", get(client, server.URL+"/a"))
+	assert.Equal(t, "GET /a  #3", get(client, server.URL+"/a"))
+	got, err := post(client, server.URL+"/c", "x")
+	require.NoError(t, err)
+	assert.Equal(t, "POST /c x #4", got)
+	got, err = post(client, server.URL+"/c", "y")
+	require.NoError(t, err)
+	assert.Equal(t, "POST /c y #5", got)
+	require.NoError(t, rec.Stop())
+	server.Close()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret", "request headers are not recorded")
+	assert.NotContains(t, string(data), "/before")
+
+	player, err := Load(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"cycle": "1"}`, string(player.Header.Meta))
+	assert.Equal(t, 4, player.Len())
+
+	client = &http.Client{Transport: player}
+	assert.Equal(t, "GET /a  #2", get(client, server.URL+"/a"))
+	assert.Equal(t, "GET /a  #3", get(client, server.URL+"/a"))
+	assert.Equal(t, "GET /a  #3", get(client, server.URL+"/a"), "the last recording repeats")
+
+	got, err = post(client, server.URL+"/c", "y")
+	require.NoError(t, err)
+	assert.Equal(t, "POST /c y #5", got, "matched by body")
+	got, err = post(client, server.URL+"/c", "z")
+	require.NoError(t, err)
+	assert.Equal(t, "POST /c x #4", got, "unmatched body falls back to the next recording")
+
+	_, err = post(client, server.URL+"/missing", "")
+	assert.ErrorIs(t, err, ErrNotRecorded)
+	assert.Equal(t, 5, player.Replays())
+}
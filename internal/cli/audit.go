@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/audit"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditPRFlag    string
+	auditSinceFlag string
+	auditLimitFlag int
+	auditJSONFlag  bool
+)
+
+func init() {
+	auditCmd.Flags().StringVar(&auditPRFlag, "pr", "", "Only show actions for this PR ID")
+	auditCmd.RegisterFlagCompletionFunc("pr", completePRFlag) //nolint:errcheck
+	auditCmd.Flags().StringVar(&auditSinceFlag, "since", "", "Only show actions newer than this duration (e.g. 24h, 7d)")
+	auditCmd.Flags().IntVar(&auditLimitFlag, "limit", 50, "Show at most N most recent entries (0 = all)")
+	auditCmd.Flags().BoolVar(&auditJSONFlag, "json", false, "Output raw JSONL entries")
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the log of automated actions",
+	Long: `Query otto's append-only audit log.
+
+Every automated action otto takes — commits pushed, comments posted,
+threads resolved, builds retried — is recorded with the PR ID, a
+timestamp, and diff stats where applicable. The log lives at
+` + dataPath("audit.jsonl") + `.`,
+	Example: `  otto audit
+  otto audit --pr 12345
+  otto audit --since 7d --limit 0
+  otto audit --json | jq .`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter := audit.Filter{PRID: auditPRFlag, Limit: auditLimitFlag}
+		if auditSinceFlag != "" {
+			d, err := parseSinceDuration(auditSinceFlag)
+			if err != nil {
+				return err
+			}
+			filter.Since = time.Now().Add(-d)
+		}
+
+		entries, err := audit.Read(filter)
+		if err != nil {
+			return fmt.Errorf("reading audit log: %w", err)
+		}
+
+		if auditJSONFlag {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			for _, e := range entries {
+				if err := enc.Encode(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if len(entries) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No audit entries found.")
+			return nil
+		}
+
+		headerStyle := lipgloss.NewStyle().Bold(true).Padding(0, 1)
+		cellStyle := lipgloss.NewStyle().Padding(0, 1)
+
+		rows := make([][]string, 0, len(entries))
+		for _, e := range entries {
+			target := e.Commit
+			if e.ThreadID != "" {
+				target = "thread " + e.ThreadID
+			} else if e.BuildID != "" {
+				target = "build " + e.BuildID
+			}
+			stats := ""
+			if e.Stats != nil {
+				stats = fmt.Sprintf("%d files +%d -%d", e.Stats.Files, e.Stats.Insertions, e.Stats.Deletions)
+			}
+			rows = append(rows, []string{
+				e.Time.Local().Format("2006-01-02 15:04:05"),
+				e.PRID,
+				string(e.Action),
+				target,
+				stats,
+				e.Detail,
+			})
+		}
+
+		t := table.New().
+			Border(lipgloss.NormalBorder()).
+			Headers("TIME", "PR", "ACTION", "TARGET", "DIFF", "DETAIL").
+			Rows(rows...).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				if row == table.HeaderRow {
+					return headerStyle
+				}
+				return cellStyle
+			})
+
+		fmt.Fprintln(cmd.OutOrStdout(), t)
+		return nil
+	},
+}
+
+// parseSinceDuration parses a Go duration, additionally accepting a "d"
+// suffix for whole days (e.g. "7d").
+func parseSinceDuration(s string) (time.Duration, error) {
+	var days int
+	if n, err := fmt.Sscanf(s, "%dd", &days); err == nil && n == 1 && fmt.Sprintf("%dd", days) == s {
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --since duration %q: %w", s, err)
+	}
+	return d, nil
+}
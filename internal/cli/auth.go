@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/secrets"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage stored credentials",
+	Long: `Store PATs, tokens, and other secrets outside the plaintext config.
+
+Secrets are named by the config key they fill and are applied over the
+config files when otto starts (environment variables still win). They
+are kept in the OS keychain — the macOS keychain, the Secret Service or
+kernel keyring on Linux, or the Windows Credential Manager — or, with
+secrets.backend set to "file" or where no keychain is available, in an
+age-encrypted file under ~/.config/otto.`,
+	Example: `  otto auth set pr.providers.github.token
+  echo "$ADO_PAT" | otto auth set pr.providers.ado.pat
+  otto auth list
+  otto auth remove jira.token`,
+}
+
+func init() {
+	authCmd.AddCommand(authSetCmd)
+	authCmd.AddCommand(authListCmd)
+	authCmd.AddCommand(authRemoveCmd)
+}
+
+// openSecrets opens the secrets store configured by secrets.backend.
+func openSecrets() (*secrets.Store, error) {
+	backend := ""
+	if appConfig != nil {
+		backend = appConfig.Secrets.Backend
+	}
+	return secrets.Open(backend)
+}
+
+var authSetCmd = &cobra.Command{
+	Use:   "set <key> [value]",
+	Short: "Store a secret",
+	Long: `Store a secret for a config key such as pr.providers.github.token.
+
+Without a value argument, the secret is read from a hidden prompt, or
+from stdin when it is not a terminal, so it stays out of shell history.`,
+	Example: `  otto auth set pr.providers.github.token
+  otto auth set models.endpoints.anthropic.api_key < key.txt`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+		if err := config.ValidateSecretKey(key); err != nil {
+			return err
+		}
+
+		var value string
+		switch {
+		case len(args) == 2:
+			value = args[1]
+		case term.IsTerminal(int(os.Stdin.Fd())):
+			fmt.Fprintf(cmd.ErrOrStderr(), "Value for %s: ", key)
+			data, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Fprintln(cmd.ErrOrStderr())
+			if err != nil {
+				return fmt.Errorf("reading secret: %w", err)
+			}
+			value = string(data)
+		default:
+			data, err := io.ReadAll(cmd.InOrStdin())
+			if err != nil {
+				return fmt.Errorf("reading secret: %w", err)
+			}
+			value = strings.TrimRight(string(data), "\r\n")
+		}
+		if value == "" {
+			return errors.New("empty secret")
+		}
+
+		store, err := openSecrets()
+		if err != nil {
+			return err
+		}
+		if err := store.Set(key, value); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Stored %s in the %s backend.\n", key, store.Backend())
+		return nil
+	},
+}
+
+var authListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored secrets (names only)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openSecrets()
+		if err != nil {
+			return err
+		}
+		stored, err := store.List()
+		if err != nil {
+			return err
+		}
+		if len(stored) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No secrets stored.")
+			return nil
+		}
+
+		headerStyle := lipgloss.NewStyle().Bold(true).Padding(0, 1)
+		cellStyle := lipgloss.NewStyle().Padding(0, 1)
+
+		var rows [][]string
+		for _, key := range slices.Sorted(maps.Keys(stored)) {
+			rows = append(rows, []string{key, stored[key]})
+		}
+		tbl := table.New().
+			Border(lipgloss.NormalBorder()).
+			Headers("KEY", "BACKEND").
+			Rows(rows...).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				if row == table.HeaderRow {
+					return headerStyle
+				}
+				return cellStyle
+			})
+
+		fmt.Fprintln(cmd.OutOrStdout(), tbl)
+		return nil
+	},
+}
+
+var authRemoveCmd = &cobra.Command{
+	Use:     "remove <key>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a stored secret",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openSecrets()
+		if err != nil {
+			return err
+		}
+		if err := store.Remove(args[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed %s.\n", args[0])
+		return nil
+	},
+}
@@ -0,0 +1,282 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/spf13/cobra"
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Apply one change across several repos",
+	Long: `Make the same LLM-driven change in several configured repos, with a PR
+for each, and follow the PRs together as a named batch.
+
+otto batch apply gives the change (a markdown prompt) to the LLM in a
+fresh worktree of each repo, runs the repo's checks, pushes what changed
+to otto/batch/<name>, and opens a PR that otto then tracks like any
+other. otto batch status shows where each repo's PR is.`,
+	Example: `  otto batch apply --repos api,web,worker --prompt-file bump-go.md
+  otto batch status bump-go`,
+}
+
+func init() {
+	batchApplyCmd.Flags().StringSlice("repos", nil, "Configured repos to change (comma-separated)")
+	batchApplyCmd.Flags().String("prompt-file", "", "Markdown file describing the change")
+	batchApplyCmd.Flags().String("name", "", "Batch name (default: the prompt file's name)")
+	batchApplyCmd.Flags().String("title", "", "PR title (default: the prompt's first heading)")
+	batchApplyCmd.Flags().String("target", "", "Target branch (default: each repo's default branch)")
+	_ = batchApplyCmd.MarkFlagRequired("repos")
+	_ = batchApplyCmd.MarkFlagRequired("prompt-file")
+	_ = batchApplyCmd.RegisterFlagCompletionFunc("repos", completeRepoNames)
+	batchStatusCmd.Flags().Bool("json", false, "Output raw JSON")
+	batchCmd.AddCommand(batchApplyCmd, batchStatusCmd)
+}
+
+var batchApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Make a change in several repos and open a PR in each",
+	Long: `Make the change described by --prompt-file in each of --repos, in
+order, and open a PR for each repo the LLM changed.
+
+Each repo gets a throwaway worktree off the latest of its target branch;
+the LLM makes the change there, the repo's checks run, and the result is
+pushed to otto/batch/<name>. A repo whose checks fail still gets its PR,
+with the failures in the description, and otto takes it from there. A
+repo the change doesn't apply to is left alone. One repo failing doesn't
+stop the others.`,
+	Example: `  otto batch apply --repos api,web --prompt-file bump-go.md
+  otto batch apply --repos api,web --prompt-file change.md --name log-json --title "chore: log as JSON"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		w := cmd.OutOrStdout()
+		repoNames, _ := cmd.Flags().GetStringSlice("repos")
+		promptFile, _ := cmd.Flags().GetString("prompt-file")
+		name, _ := cmd.Flags().GetString("name")
+		title, _ := cmd.Flags().GetString("title")
+		target, _ := cmd.Flags().GetString("target")
+
+		data, err := os.ReadFile(promptFile)
+		if err != nil {
+			return fmt.Errorf("reading prompt file: %w", err)
+		}
+		change := strings.TrimSpace(string(data))
+		if change == "" {
+			return fmt.Errorf("prompt file %s is empty", promptFile)
+		}
+		if name == "" {
+			name = strings.ToLower(strings.TrimSuffix(filepath.Base(promptFile), filepath.Ext(promptFile)))
+		}
+		if err := server.ValidateBatchName(name); err != nil {
+			return err
+		}
+		if _, err := server.LoadBatch(name); err == nil {
+			return fmt.Errorf("batch %q already exists; pick another --name", name)
+		}
+		if title == "" {
+			title = batchTitle(change, name)
+		}
+
+		repos := make([]*config.RepoConfig, 0, len(repoNames))
+		for _, rn := range repoNames {
+			rc := findRepo(rn)
+			if rc == nil {
+				return fmt.Errorf("repo %q is not configured (see otto repo list)", rn)
+			}
+			repos = append(repos, rc)
+		}
+
+		b := &server.Batch{Name: name, Title: title, Change: change, Created: time.Now().UTC()}
+		for _, rc := range repos {
+			b.Repos = append(b.Repos, server.BatchRepo{Repo: rc.Name})
+		}
+
+		llmClient, err := llm.NewFallbackClient(appConfig.Models, "")
+		if err != nil {
+			return err
+		}
+		if err := llmClient.Start(ctx); err != nil {
+			return fmt.Errorf("starting LLM client: %w", err)
+		}
+		defer llmClient.Stop()
+
+		if err := server.SaveBatch(b); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "Applying batch %s to %d repos\n", name, len(repos))
+		opened := 0
+		for i, rc := range repos {
+			fmt.Fprintf(w, "%s...\n", rc.Name)
+			r := server.ApplyBatchRepo(ctx, appConfig, llmClient, rc, b, target)
+			b.Repos[i] = r
+			if err := server.SaveBatch(b); err != nil {
+				return err
+			}
+			switch {
+			case r.PRURL != "":
+				opened++
+				fmt.Fprintf(w, "  ✓ Created PR #%s: %s\n", r.PRID, r.PRURL)
+				if r.Result != "" {
+					fmt.Fprintf(w, "  ⚠ %s\n", r.Result)
+				}
+			case r.Result == "no changes":
+				fmt.Fprintf(w, "  - No changes\n")
+			default:
+				fmt.Fprintf(w, "  ✗ %s\n", r.Result)
+			}
+		}
+
+		fmt.Fprintf(w, "Opened %d of %d PRs; follow them with: otto batch status %s\n", opened, len(repos), name)
+		if opened > 0 {
+			notifyDaemon(w)
+		}
+		return nil
+	},
+}
+
+var batchStatusCmd = &cobra.Command{
+	Use:   "status [name]",
+	Short: "Show batches, or where one batch's PRs are",
+	Args:  cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		batches, _ := server.ListBatches()
+		names := make([]string, 0, len(batches))
+		for _, b := range batches {
+			names = append(names, b.Name)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOut, _ := cmd.Flags().GetBool("json")
+		if len(args) == 0 {
+			return listBatches(cmd, jsonOut)
+		}
+
+		b, err := server.LoadBatch(args[0])
+		if err != nil {
+			return err
+		}
+		docs, err := server.BatchPRs(b)
+		if err != nil {
+			return fmt.Errorf("reading tracked PRs: %w", err)
+		}
+
+		if jsonOut {
+			type repoStatus struct {
+				server.BatchRepo
+				PR *server.PRDocument `json:"pr,omitempty"`
+			}
+			out := struct {
+				*server.Batch
+				Repos []repoStatus `json:"repos"`
+			}{Batch: b}
+			for i, r := range b.Repos {
+				out.Repos = append(out.Repos, repoStatus{BatchRepo: r, PR: docs[i]})
+			}
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(out)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Batch %s: %s (%s)\n", b.Name, b.Title, b.Created.Local().Format("2006-01-02 15:04"))
+		rows := make([][]string, 0, len(b.Repos))
+		for i, r := range b.Repos {
+			switch {
+			case docs[i] != nil:
+				rows = append(rows, []string{r.Repo, "#" + r.PRID, docs[i].Status, docs[i].WaitingOn})
+			case r.PRID != "":
+				rows = append(rows, []string{r.Repo, "#" + r.PRID, "untracked", r.PRURL})
+			case r.Result == "":
+				rows = append(rows, []string{r.Repo, "-", "pending", ""})
+			default:
+				rows = append(rows, []string{r.Repo, "-", "-", truncateStr(r.Result, 60)})
+			}
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), styledTable([]string{"REPO", "PR", "STATUS", "WAITING ON / RESULT"}, rows))
+		return nil
+	},
+}
+
+// listBatches prints every recorded batch.
+func listBatches(cmd *cobra.Command, jsonOut bool) error {
+	batches, err := server.ListBatches()
+	if err != nil {
+		return fmt.Errorf("reading batches: %w", err)
+	}
+
+	if jsonOut {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(batches)
+	}
+
+	if len(batches) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No batches.")
+		return nil
+	}
+
+	rows := make([][]string, 0, len(batches))
+	for _, b := range batches {
+		prs := 0
+		for _, r := range b.Repos {
+			if r.PRID != "" {
+				prs++
+			}
+		}
+		rows = append(rows, []string{b.Name, truncateStr(b.Title, 50), fmt.Sprintf("%d/%d", prs, len(b.Repos)), b.Created.Local().Format("2006-01-02 15:04")})
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), styledTable([]string{"NAME", "TITLE", "PRS", "CREATED"}, rows))
+	return nil
+}
+
+// styledTable renders rows as a bordered table with bold headers.
+func styledTable(headers []string, rows [][]string) *table.Table {
+	headerStyle := lipgloss.NewStyle().Bold(true).Padding(0, 1)
+	cellStyle := lipgloss.NewStyle().Padding(0, 1)
+	return table.New().
+		Border(lipgloss.NormalBorder()).
+		Headers(headers...).
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return headerStyle
+			}
+			return cellStyle
+		})
+}
+
+// batchTitle is the PR title of a batch: the change's first heading, or
+// a chore named after the batch.
+func batchTitle(change, name string) string {
+	for _, line := range strings.Split(change, "\n") {
+		if heading, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok && strings.TrimSpace(heading) != "" {
+			return strings.TrimSpace(heading)
+		}
+	}
+	return "chore: " + name
+}
+
+// findRepo returns the configured repo named name, or nil.
+func findRepo(name string) *config.RepoConfig {
+	for i := range appConfig.Repos {
+		if appConfig.Repos[i].Name == name {
+			return &appConfig.Repos[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/repo"
+	"github.com/spf13/cobra"
+)
+
+var branchCmd = &cobra.Command{
+	Use:   "branch",
+	Short: "Start and list work branches",
+	Long: `Start a branch for a piece of work, named by the repo's convention, in a
+worktree of its own, and list the branches started this way.`,
+	Example: `  otto branch new "Fix the login redirect loop"
+  otto branch list`,
+}
+
+func init() {
+	branchNewCmd.Flags().String("repo", "", "Configured repo (default: the repo of the current directory)")
+	branchNewCmd.Flags().String("name", "", "Branch name (default: derived from the description)")
+	branchNewCmd.Flags().String("base", "", "Branch or commit to start from (default: the primary checkout's HEAD)")
+	branchNewCmd.Flags().String("spec", "", "Start a spec with this slug for the branch")
+	_ = branchNewCmd.RegisterFlagCompletionFunc("repo", completeRepoNames)
+	branchListCmd.Flags().String("repo", "", "Only list this repo's branches")
+	branchListCmd.Flags().Bool("json", false, "Output raw JSON")
+	_ = branchListCmd.RegisterFlagCompletionFunc("repo", completeRepoNames)
+	branchCmd.AddCommand(branchNewCmd, branchListCmd)
+}
+
+var branchNewCmd = &cobra.Command{
+	Use:   "new <description>",
+	Short: "Create a named branch and worktree for a piece of work",
+	Long: `Create a branch for the work the description describes.
+
+The name is the description's words, lowercased and hyphenated (or
+--name), rendered through the repo's branch_template, so a template of
+"users/{{.User}}/{{.Name}}" gives users/<you>/fix-the-login-redirect-loop.
+{{.User}} is the local part of your git email. With the worktree
+strategy the branch is checked out in a new worktree under the repo's
+worktree_dir (default: worktrees/ beside the primary checkout), where
+the dashboard lists it.
+
+--spec starts a spec for the work at .otto/specs/<slug>.md in the
+worktree, seeded with the description, and links it to the branch.`,
+	Example: `  otto branch new "Fix the login redirect loop"
+  otto branch new "Add dark mode" --repo web --spec dark-mode
+  otto branch new "Hotfix for 1.4" --name hotfix-1.4 --base origin/release/1.4`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		w := cmd.OutOrStdout()
+		description := strings.Join(args, " ")
+		repoName, _ := cmd.Flags().GetString("repo")
+		name, _ := cmd.Flags().GetString("name")
+		base, _ := cmd.Flags().GetString("base")
+		spec, _ := cmd.Flags().GetString("spec")
+
+		var rc *config.RepoConfig
+		if repoName != "" {
+			if rc = findRepo(repoName); rc == nil {
+				return fmt.Errorf("repo %q is not configured (see otto repo list)", repoName)
+			}
+		} else {
+			var err error
+			if rc, err = repo.NewManager("").FindByCWD(appConfig); err != nil {
+				return fmt.Errorf("%w; give one with --repo", err)
+			}
+		}
+
+		rec, err := repo.NewBranch(*rc, description, repo.NewBranchOptions{Name: name, Base: base, Spec: spec})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Created branch %s\n", rec.Branch)
+		fmt.Fprintf(w, "  Worktree: %s\n", rec.WorkDir)
+		if rec.Spec != "" {
+			fmt.Fprintf(w, "  Spec:     %s\n", rec.SpecPath())
+		}
+		return nil
+	},
+}
+
+var branchListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List branches started with otto branch new",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repoName, _ := cmd.Flags().GetString("repo")
+		recs, err := repo.ListBranchRecords()
+		if err != nil {
+			return err
+		}
+		if repoName != "" {
+			kept := recs[:0]
+			for _, r := range recs {
+				if r.Repo == repoName {
+					kept = append(kept, r)
+				}
+			}
+			recs = kept
+		}
+
+		if jsonOut, _ := cmd.Flags().GetBool("json"); jsonOut {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(recs)
+		}
+
+		if len(recs) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No branches.")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(recs))
+		for _, r := range recs {
+			spec := r.Spec
+			if spec == "" {
+				spec = "-"
+			}
+			rows = append(rows, []string{r.Repo, r.Branch, spec, r.WorkDir, r.Created.Local().Format("2006-01-02 15:04")})
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), styledTable([]string{"REPO", "BRANCH", "SPEC", "WORKTREE", "CREATED"}, rows))
+		return nil
+	},
+}
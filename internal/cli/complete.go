@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/repo"
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// resolvePR returns the PR named by args[0], or the one to use when no ID
+// was given: the only tracked PR, or — on a terminal with several tracked —
+// the one the user picks.
+func resolvePR(args []string) (*server.PRDocument, error) {
+	if len(args) > 0 {
+		return server.FindPR(args[0])
+	}
+	pr, err := server.InferPR()
+	if !errors.Is(err, server.ErrMultiplePRs) || !isInteractive() {
+		return pr, err
+	}
+	prs, err := server.ListPRs()
+	if err != nil {
+		return nil, err
+	}
+	return pickPR(prs)
+}
+
+// isInteractive reports whether both stdin and stdout are terminals, so a
+// prompt can be shown and answered.
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// pickPR asks the user to choose one of prs. Typing / filters the list.
+func pickPR(prs []*server.PRDocument) (*server.PRDocument, error) {
+	options := make([]huh.Option[int], 0, len(prs))
+	for i, pr := range prs {
+		label := fmt.Sprintf("%s #%s  %-9s %s", pr.Provider, pr.ID, pr.Status, truncateStr(pr.Title, 60))
+		options = append(options, huh.NewOption(label, i))
+	}
+
+	var selected int
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[int]().
+				Title("Which PR? (/ to filter)").
+				Options(options...).
+				Height(min(len(options)+2, 15)).
+				Value(&selected),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return nil, fmt.Errorf("selection cancelled: %w", err)
+	}
+	return prs[selected], nil
+}
+
+// completePRIDs completes the first argument with tracked PR IDs, described
+// by their title and status.
+func completePRIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return trackedPRCompletions(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePRFlag completes a flag that takes a PR ID.
+func completePRFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return trackedPRCompletions(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func trackedPRCompletions(toComplete string) []string {
+	prs, err := server.ListPRs()
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, pr := range prs {
+		if strings.HasPrefix(pr.ID, toComplete) {
+			out = append(out, fmt.Sprintf("%s\t%s (%s)", pr.ID, truncateStr(pr.Title, 50), pr.Status))
+		}
+	}
+	return out
+}
+
+// completeTranscripts completes `pr transcript`: PR IDs, then the PR's
+// transcript numbers.
+func completeTranscripts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return trackedPRCompletions(toComplete), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		pr, err := server.FindPR(args[0])
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		transcripts, err := server.ListTranscripts(pr)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		out := make([]string, 0, len(transcripts))
+		for i, t := range transcripts {
+			out = append(out, strconv.Itoa(i+1)+"\t"+t.Title)
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeCassettes completes `pr replay` with recorded cycle file names,
+// falling back to file paths.
+func completeCassettes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	paths, err := server.ListCassettes()
+	if err != nil || len(paths) == 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	out := make([]string, 0, len(paths))
+	for _, p := range paths {
+		out = append(out, filepath.Base(p))
+	}
+	return out, cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeRepoNames completes the first argument with tracked repository
+// names.
+func completeRepoNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 || appConfig == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var out []string
+	for _, r := range repo.NewManager(configDir).List(appConfig) {
+		out = append(out, r.Name)
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -20,18 +21,25 @@ var configCmd = &cobra.Command{
 
 Otto merges configuration from several layers: built-in defaults,
 ~/.config/otto/otto.jsonc (user), and .otto/otto.jsonc (repo-local).
-Use 'config show' to inspect the merged result and 'config set' to
-write values to the repo-local file.`,
+Use 'config show' to inspect the merged result, 'config validate' to
+check the files, and 'config set' to write values to the repo-local file.`,
 	Example: `  otto config show
   otto config show --json
+  otto config show --effective
+  otto config validate
   otto config set models.primary "github-copilot/claude-opus-4.6"`,
 }
 
-var configJSONFlag bool
+var (
+	configJSONFlag      bool
+	configEffectiveFlag bool
+)
 
 func init() {
 	configShowCmd.Flags().BoolVar(&configJSONFlag, "json", false, "Output raw JSON without formatting")
+	configShowCmd.Flags().BoolVar(&configEffectiveFlag, "effective", false, "List every setting with the layer it comes from")
 	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configValidateCmd)
 	configCmd.AddCommand(configSetCmd)
 }
 
@@ -42,9 +50,15 @@ var configShowCmd = &cobra.Command{
 
 All layers (defaults, user, repo-local) are merged and the result is
 printed. Sensitive values such as tokens and webhook URLs are redacted.
-Use --json for machine-readable compact output.`,
+Use --json for machine-readable compact output.
+
+--effective lists every setting, defaults included, one per line with
+the layer that set it: default, a config file, secret (otto auth set),
+or an environment variable.`,
 	Example: `  otto config show
   otto config show --json
+  otto config show --effective
+  otto config show --effective | grep '^pr\.'
   otto config show --json | jq '.models'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := appConfig
@@ -59,6 +73,21 @@ Use --json for machine-readable compact output.`,
 		// Redact secrets before display.
 		redacted := redactConfig(cfg)
 
+		if configEffectiveFlag {
+			settings, err := config.Effective(redacted, configPath)
+			if err != nil {
+				return fmt.Errorf("listing settings: %w", err)
+			}
+			for _, s := range settings {
+				value, err := json.Marshal(s.Value)
+				if err != nil {
+					return fmt.Errorf("marshaling %s: %w", s.Key, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s = %s  (%s)\n", s.Key, value, s.Source)
+			}
+			return nil
+		}
+
 		var data []byte
 		var err error
 		if configJSONFlag {
@@ -75,6 +104,63 @@ Use --json for machine-readable compact output.`,
 	},
 }
 
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config files for errors",
+	Long: `Check the user, repo-local, and --config files against otto's
+config schema, then validate the merged result.
+
+Reports syntax errors, unknown keys, values of the wrong type, and bad
+durations with their file, line, and column, followed by problems that
+span settings, such as provider credentials missing a field. Exits
+non-zero if anything is reported. The daemon runs the same checks at
+startup and refuses to start on errors.`,
+	Example: `  otto config validate
+  otto config validate --config ./staging.jsonc`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		paths := config.Paths()
+		if configPath != "" {
+			paths = append(paths, configPath)
+		}
+
+		problems, fileErrors := 0, 0
+		for _, path := range paths {
+			issues, err := config.CheckFile(path)
+			if errors.Is(err, os.ErrNotExist) && path != configPath {
+				continue
+			}
+			if err != nil {
+				fmt.Fprintf(out, "%s: error: %v\n", path, err)
+				problems++
+				fileErrors++
+				continue
+			}
+			for _, is := range issues {
+				fmt.Fprintln(out, is.String())
+				if !is.Warning {
+					fileErrors++
+				}
+			}
+			problems += len(issues)
+		}
+		// Load would only repeat the first file error.
+		if fileErrors == 0 {
+			if _, err := config.Load(configPath); err != nil {
+				fmt.Fprintf(out, "error: %v\n", err)
+				problems++
+			}
+		}
+
+		if problems > 0 {
+			return fmt.Errorf("config has %d problem(s)", problems)
+		}
+		fmt.Fprintln(out, "config ok")
+		return nil
+	},
+}
+
 // redactConfig returns a copy of the config with secret fields masked.
 func redactConfig(cfg *config.Config) *config.Config {
 	copy := *cfg
@@ -94,11 +180,43 @@ func redactConfig(cfg *config.Config) *config.Config {
 			if v.Token != "" {
 				v.Token = "***"
 			}
+			if v.ClientSecret != "" {
+				v.ClientSecret = "***"
+			}
 			redacted[k] = v
 		}
 		copy.PR.Providers = redacted
 	}
 
+	if copy.Jira.Token != "" {
+		copy.Jira.Token = "***"
+	}
+
+	if copy.Dashboard.AdminToken != "" {
+		copy.Dashboard.AdminToken = "***"
+	}
+
+	// Redact LLM backend API keys.
+	if copy.Models.Endpoints != nil {
+		redacted := make(map[string]config.EndpointConfig, len(copy.Models.Endpoints))
+		for k, v := range copy.Models.Endpoints {
+			if v.APIKey != "" {
+				v.APIKey = "***"
+			}
+			redacted[k] = v
+		}
+		copy.Models.Endpoints = redacted
+	}
+
+	// Telemetry headers usually carry collector auth tokens.
+	if copy.Telemetry.Headers != nil {
+		redacted := make(map[string]string, len(copy.Telemetry.Headers))
+		for k := range copy.Telemetry.Headers {
+			redacted[k] = "***"
+		}
+		copy.Telemetry.Headers = redacted
+	}
+
 	return &copy
 }
 
@@ -114,7 +232,7 @@ as bool, integer, float, or string.
 Note: JSONC comments are not preserved on write.`,
 	Example: `  otto config set models.primary "github-copilot/claude-opus-4.6"
   otto config set server.port 8080
-  otto config set opencode.auto_start true`,
+  otto config set models.backend opencode`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		key := args[0]
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/eval"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var evalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Check prompts and models against recorded cases",
+	Long: `Replay a corpus of recorded build failures and review comments
+against the current prompts and models, and report how often otto
+reaches the expected classification.
+
+Failure cases are classified with pr-fix-analysis.md (infra, compile,
+test, lint, or code); comment cases are decided with
+pr-comment-respond.md (AGREE, BY_DESIGN, or WONT_FIX). Run it after
+editing a prompt override or switching models to catch regressions.`,
+	Example: `  otto eval run
+  otto eval run --corpus ./eval-cases --models primary,secondary`,
+}
+
+func init() {
+	evalRunCmd.Flags().String("corpus", "", "Directory of *.json cases (default: the built-in corpus)")
+	evalRunCmd.Flags().StringSlice("models", []string{config.RolePrimary}, "Model roles to evaluate")
+	evalRunCmd.Flags().String("kind", "", "Only run cases of this kind: failure or comment")
+	evalRunCmd.Flags().Float64("min-accuracy", 0, "Exit non-zero if any kind scores below this fraction (0-1)")
+	evalCmd.AddCommand(evalRunCmd)
+}
+
+var evalRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the eval corpus and report accuracy",
+	Long: `Run every case in the corpus once per model role and print each
+mismatch followed by the accuracy per case kind.
+
+A corpus directory holds *.json files, each one case or an array of
+cases:
+
+  {
+    "name": "failure-nuget-feed",
+    "kind": "failure",
+    "title": "Bump logging package",
+    "logs": "error NU1301: Unable to load the service index ...",
+    "expected": "infra"
+  }
+
+Comment cases carry a "comment" object (author, file, line, body,
+thread, code_context) and expect AGREE, BY_DESIGN, or WONT_FIX.`,
+	Example: `  otto eval run
+  otto eval run --kind failure --min-accuracy 0.9`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		out := cmd.OutOrStdout()
+		corpus, _ := cmd.Flags().GetString("corpus")
+		roles, _ := cmd.Flags().GetStringSlice("models")
+		kind, _ := cmd.Flags().GetString("kind")
+		minAccuracy, _ := cmd.Flags().GetFloat64("min-accuracy")
+
+		cases, err := eval.Load(corpus)
+		if err != nil {
+			return err
+		}
+		if kind != "" {
+			if kind != eval.KindFailure && kind != eval.KindComment {
+				return fmt.Errorf("invalid --kind %q: must be failure or comment", kind)
+			}
+			var filtered []eval.Case
+			for _, c := range cases {
+				if c.Kind == kind {
+					filtered = append(filtered, c)
+				}
+			}
+			cases = filtered
+		}
+		if len(cases) == 0 {
+			return fmt.Errorf("no %s cases to run", kind)
+		}
+
+		below := false
+		ran := 0
+		for _, role := range reviewRoles(appConfig.Models, roles) {
+			model := appConfig.Models.ModelFor(role)
+			fmt.Fprintf(out, "Evaluating %d cases with %s (%s)...\n", len(cases), model, role)
+			results, err := evalWithRole(ctx, role, cases)
+			if err != nil {
+				fmt.Fprintf(out, "  Warning: %s eval failed: %v\n", model, err)
+				continue
+			}
+			ran++
+			for _, s := range printEvalResults(out, results) {
+				if s.Accuracy() < minAccuracy {
+					below = true
+				}
+			}
+		}
+		if ran == 0 {
+			return fmt.Errorf("no model completed the eval")
+		}
+		if below {
+			return fmt.Errorf("accuracy below %.0f%%", minAccuracy*100)
+		}
+		return nil
+	},
+}
+
+// evalWithRole runs cases on the model configured for role. Each case gets
+// its own scratch directory, since comment cases may edit files.
+func evalWithRole(ctx context.Context, role string, cases []eval.Case) ([]eval.Result, error) {
+	backend, err := llm.NewBackend(appConfig.Models, role, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := backend.Start(ctx); err != nil {
+		return nil, fmt.Errorf("starting LLM client: %w", err)
+	}
+	defer backend.Stop()
+
+	results := make([]eval.Result, 0, len(cases))
+	for _, c := range cases {
+		r := eval.Result{Case: c}
+		workDir, err := os.MkdirTemp("", "otto-eval-")
+		if err != nil {
+			return nil, err
+		}
+		r.Got, r.Err = server.EvalCase(ctx, backend, c, workDir)
+		os.RemoveAll(workDir)
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// printEvalResults prints the cases that did not pass and the score per
+// kind, and returns the scores.
+func printEvalResults(w io.Writer, results []eval.Result) []eval.Score {
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			fmt.Fprintf(w, "  ERROR %s: %v\n", r.Case.Name, r.Err)
+		case !r.Passed():
+			fmt.Fprintf(w, "  FAIL  %s: got %s, want %s\n", r.Case.Name, r.Got, r.Case.Expected)
+		}
+	}
+	scores := eval.Scores(results)
+	for _, s := range scores {
+		fmt.Fprintf(w, "  %-8s %d/%d (%.0f%%)\n", s.Kind, s.Passed, s.Total, s.Accuracy()*100)
+	}
+	return scores
+}
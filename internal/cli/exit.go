@@ -0,0 +1,34 @@
+package cli
+
+import "errors"
+
+// Exit codes. Scripts can tell these apart; anything else that goes wrong
+// exits with ExitFailure.
+const (
+	ExitOK          = 0
+	ExitFailure     = 1
+	ExitPRFailed    = 2 // the PR reached "failed": otto gave up fixing it
+	ExitTimeout     = 3 // --wait ran out of time
+	ExitPRAbandoned = 4 // the PR was closed without merging
+)
+
+// ExitError is an error that sets the process exit code.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// ExitCode returns the process exit code for an error returned by Execute.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var ee *ExitError
+	if errors.As(err, &ee) {
+		return ee.Code
+	}
+	return ExitFailure
+}
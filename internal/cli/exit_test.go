@@ -0,0 +1,19 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, ExitOK, ExitCode(nil))
+	assert.Equal(t, ExitFailure, ExitCode(errors.New("boom")))
+	assert.Equal(t, ExitTimeout, ExitCode(&ExitError{Code: ExitTimeout, Err: errors.New("timed out")}))
+
+	wrapped := fmt.Errorf("waiting: %w", &ExitError{Code: ExitPRAbandoned, Err: errors.New("abandoned")})
+	assert.Equal(t, ExitPRAbandoned, ExitCode(wrapped))
+	assert.EqualError(t, wrapped, "waiting: abandoned")
+}
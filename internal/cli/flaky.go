@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flakyRepoFlag string
+	flakyJSONFlag bool
+)
+
+var flakyCmd = &cobra.Command{
+	Use:   "flaky",
+	Short: "Inspect the flaky-test knowledge base",
+	Long: `Inspect tests otto has learned are flaky.
+
+When FixPR analysis classifies a failing build as an infrastructure
+failure, the failing tests are recorded per repository. If the same
+tests — and nothing else — fail again, otto skips analysis and retries
+the builds directly (up to pr.max_flaky_retries times in a row).`,
+	Example: `  otto flaky list
+  otto flaky list --repo my-service
+  otto flaky accuracy`,
+}
+
+func init() {
+	flakyListCmd.Flags().StringVar(&flakyRepoFlag, "repo", "", "Only show flaky tests for this repository")
+	flakyListCmd.Flags().BoolVar(&flakyJSONFlag, "json", false, "Output raw JSON")
+	flakyCmd.AddCommand(flakyListCmd)
+	flakyAccuracyCmd.Flags().StringVar(&flakyRepoFlag, "repo", "", "Only show this repository")
+	flakyAccuracyCmd.Flags().BoolVar(&flakyJSONFlag, "json", false, "Output raw JSON")
+	flakyCmd.AddCommand(flakyAccuracyCmd)
+}
+
+var flakyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List known flaky tests, repeat offenders first",
+	Long: `List tests recorded as flaky, ordered by how often they have failed.
+
+SEEN counts failures classified as flaky by analysis; RETRIES counts
+later failures that were retried without analysis.`,
+	Example: `  otto flaky list
+  otto flaky list --repo my-service --json | jq .`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tests, err := server.ListFlakyTests(flakyRepoFlag)
+		if err != nil {
+			return fmt.Errorf("reading flaky tests: %w", err)
+		}
+
+		if flakyJSONFlag {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(tests)
+		}
+
+		if len(tests) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No flaky tests recorded.")
+			return nil
+		}
+
+		headerStyle := lipgloss.NewStyle().Bold(true).Padding(0, 1)
+		cellStyle := lipgloss.NewStyle().Padding(0, 1)
+
+		rows := make([][]string, 0, len(tests))
+		for _, t := range tests {
+			rows = append(rows, []string{
+				t.Repo,
+				t.Test,
+				t.Tool,
+				strconv.Itoa(t.Occurrences),
+				strconv.Itoa(t.AutoRetries),
+				t.LastSeen.Local().Format("2006-01-02 15:04"),
+				t.LastPR,
+			})
+		}
+
+		tbl := table.New().
+			Border(lipgloss.NormalBorder()).
+			Headers("REPO", "TEST", "TOOL", "SEEN", "RETRIES", "LAST SEEN", "LAST PR").
+			Rows(rows...).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				if row == table.HeaderRow {
+					return headerStyle
+				}
+				return cellStyle
+			})
+
+		fmt.Fprintln(cmd.OutOrStdout(), tbl)
+		return nil
+	},
+}
+
+var flakyAccuracyCmd = &cobra.Command{
+	Use:   "accuracy",
+	Short: "Show how well failure classifications held up, per repository",
+	Long: `Show how FixPR analysis has classified build failures in each
+repository, and how often its infrastructure calls were right.
+
+An infrastructure classification is confirmed when the retried builds
+pass. It recurred when the same builds failed the same way after the
+retry; otto then stops treating the failure as flaky, and if analysis
+calls it infrastructure again, reclassifies it as a code failure and
+fixes it (RECLASSIFIED). A low ACCURACY suggests the analysis prompt or
+pr.max_flaky_retries needs tuning for the repository.`,
+	Example: `  otto flaky accuracy
+  otto flaky accuracy --repo my-service --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stats, err := server.ListClassificationStats(flakyRepoFlag)
+		if err != nil {
+			return fmt.Errorf("reading classification stats: %w", err)
+		}
+
+		if flakyJSONFlag {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(stats)
+		}
+
+		if len(stats) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No classifications recorded.")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(stats))
+		for _, s := range stats {
+			total := 0
+			var categories []string
+			for _, c := range []server.FailureCategory{server.CategoryInfra, server.CategoryCompile, server.CategoryTest, server.CategoryLint, server.CategoryCode} {
+				if n := s.Categories[string(c)]; n > 0 {
+					total += n
+					categories = append(categories, fmt.Sprintf("%s %d", c, n))
+				}
+			}
+			accuracy := "-"
+			if a, ok := s.InfraAccuracy(); ok {
+				accuracy = fmt.Sprintf("%.0f%%", a*100)
+			}
+			rows = append(rows, []string{
+				s.Repo,
+				strconv.Itoa(total),
+				strings.Join(categories, ", "),
+				strconv.Itoa(s.InfraConfirmed),
+				strconv.Itoa(s.InfraRecurred),
+				strconv.Itoa(s.InfraReclassified),
+				accuracy,
+			})
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), styledTable([]string{"REPO", "ANALYSES", "BY CATEGORY", "CONFIRMED", "RECURRED", "RECLASSIFIED", "ACCURACY"}, rows))
+		return nil
+	},
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/spf13/cobra"
+)
+
+var issueJSONFlag bool
+
+var issueCmd = &cobra.Command{
+	Use:   "issue",
+	Short: "Inspect issue triage",
+	Long: `Inspect the triage of new issues.
+
+With issues.enabled, the daemon reads the issues (GitHub) and bugs (ADO)
+opened in the repositories of issues.watch rules. For each, the LLM
+classifies it, picks labels from issues.labels, checks it against the
+open issues for a duplicate (labeled issues.duplicate_label), and drafts
+a reply to the reporter, which is posted with issues.respond. Feature
+work gets a suggested spec slug. Issues opened before triage first runs
+are left alone.`,
+	Example: `  otto issue list
+  otto issue show 42`,
+}
+
+func init() {
+	issueListCmd.Flags().BoolVar(&issueJSONFlag, "json", false, "Output raw JSON")
+	issueShowCmd.Flags().BoolVar(&issueJSONFlag, "json", false, "Output raw JSON")
+	issueCmd.AddCommand(issueListCmd)
+	issueCmd.AddCommand(issueShowCmd)
+}
+
+var issueListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List triaged issues, most recent first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		triages, err := server.ListIssueTriages()
+		if err != nil {
+			return fmt.Errorf("reading issue triage: %w", err)
+		}
+
+		if issueJSONFlag {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(triages)
+		}
+
+		if len(triages) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No issues triaged.")
+			return nil
+		}
+
+		headerStyle := lipgloss.NewStyle().Bold(true).Padding(0, 1)
+		cellStyle := lipgloss.NewStyle().Padding(0, 1)
+
+		rows := make([][]string, 0, len(triages))
+		for _, t := range triages {
+			dup := ""
+			if t.DuplicateOf != "" {
+				dup = "#" + t.DuplicateOf
+			}
+			rows = append(rows, []string{
+				t.Repo,
+				"#" + t.ID,
+				truncateStr(t.Title, 50),
+				t.Kind,
+				strings.Join(t.Labels, ", "),
+				dup,
+				t.SpecSlug,
+				t.Triaged.Local().Format("2006-01-02 15:04"),
+			})
+		}
+
+		tbl := table.New().
+			Border(lipgloss.NormalBorder()).
+			Headers("REPO", "ISSUE", "TITLE", "KIND", "LABELS", "DUPLICATE OF", "SPEC", "TRIAGED").
+			Rows(rows...).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				if row == table.HeaderRow {
+					return headerStyle
+				}
+				return cellStyle
+			})
+
+		fmt.Fprintln(cmd.OutOrStdout(), tbl)
+		return nil
+	},
+}
+
+var issueShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show an issue's triage and drafted reply",
+	Long: `Show how an issue was triaged, with the drafted reply and, for
+feature work, the suggested spec slug. Use <repo>#<id> when the same
+number was triaged in more than one repository.`,
+	Example: `  otto issue show 42
+  otto issue show owner/repo#42`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := server.FindIssueTriage(args[0])
+		if err != nil {
+			return err
+		}
+
+		w := cmd.OutOrStdout()
+		if issueJSONFlag {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(t)
+		}
+
+		fmt.Fprintf(w, "%s#%s: %s\n", t.Repo, t.ID, t.Title)
+		if t.URL != "" {
+			fmt.Fprintf(w, "URL:       %s\n", t.URL)
+		}
+		fmt.Fprintf(w, "Kind:      %s\n", t.Kind)
+		if len(t.Labels) > 0 {
+			fmt.Fprintf(w, "Labels:    %s\n", strings.Join(t.Labels, ", "))
+		}
+		if t.DuplicateOf != "" {
+			fmt.Fprintf(w, "Duplicate: #%s\n", t.DuplicateOf)
+		}
+		if t.SpecSlug != "" {
+			fmt.Fprintf(w, "Spec:      %s\n", t.SpecSlug)
+		}
+		fmt.Fprintf(w, "Triaged:   %s\n", t.Triaged.Local().Format("2006-01-02 15:04"))
+		if t.Response != "" {
+			state := "drafted"
+			if t.Responded {
+				state = "posted"
+			}
+			fmt.Fprintf(w, "\nReply (%s):\n\n%s\n", state, t.Response)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/alanmeadows/otto/internal/mockprovider"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mockProviderPort     int
+	mockProviderScenario string
+	mockProviderRepo     string
+)
+
+func init() {
+	mockProviderCmd.Flags().IntVar(&mockProviderPort, "port", 8089, "Port to listen on")
+	mockProviderCmd.Flags().StringVar(&mockProviderScenario, "scenario", string(mockprovider.ScenarioFull), "Demo scenario: fix-once, merlinbot, or full")
+	mockProviderCmd.Flags().StringVar(&mockProviderRepo, "repo", "", "Local git repo to watch; the failing build turns green when the PR branch moves")
+}
+
+var mockProviderCmd = &cobra.Command{
+	Use:   "mock-provider",
+	Short: "Run a local fake ADO/GitHub API for demos",
+	Long: `Start a local HTTP server that emulates the Azure DevOps and GitHub
+APIs otto uses: pull requests, comment threads, builds, and build logs.
+Point a provider's base_url at it to run the full PR monitoring loop
+without any credentials.
+
+Scenarios:
+  fix-once   one PR whose CI build fails until a fix is pushed
+  merlinbot  one PR with a green build and three MerlinBot comments
+  full       both of the above on the same PR
+
+The failing build turns green when the otto/demo-fix branch moves in
+--repo, or when you POST to /_mock/advance. GET /_mock/state shows the
+current builds and threads.`,
+	Example: `  otto mock-provider
+  otto mock-provider --scenario merlinbot --port 9000
+  otto mock-provider --scenario fix-once --repo ./demo-repo`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		baseURL := "http://localhost:" + strconv.Itoa(mockProviderPort)
+		srv, err := mockprovider.New(mockprovider.Options{
+			Scenario:  mockprovider.Scenario(mockProviderScenario),
+			RepoDir:   mockProviderRepo,
+			PublicURL: baseURL,
+		})
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Mock provider (%s) listening on %s\n\n", mockProviderScenario, baseURL)
+		fmt.Fprintln(out, "Configure otto to use it:")
+		fmt.Fprintf(out, "  otto config set pr.providers.ado.base_url %s\n", baseURL)
+		fmt.Fprintf(out, "  otto config set pr.providers.ado.organization %s\n", mockprovider.Organization)
+		fmt.Fprintf(out, "  otto config set pr.providers.ado.project %s\n", mockprovider.Project)
+		fmt.Fprintln(out, "  otto config set pr.providers.ado.pat mock")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Then track the demo PR:")
+		fmt.Fprintf(out, "  otto pr add https://dev.azure.com/%s/%s/_git/%s/pullrequest/%d\n\n",
+			mockprovider.Organization, mockprovider.Project, mockprovider.Repository, mockprovider.PRNumber)
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+		defer stop()
+		return srv.ListenAndServe(ctx, fmt.Sprintf(":%d", mockProviderPort))
+	},
+}
@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/policy"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/alanmeadows/otto/internal/repo"
+	"github.com/alanmeadows/otto/internal/scaffold"
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var newCmd = &cobra.Command{
+	Use:   "new <kind> <name>",
+	Short: "Scaffold a new project from a template and open a PR",
+	Long: `Scaffold a new project — a service, a library, a CLI — from a template,
+and open a PR with it.
+
+The LLM creates the project from --template in a fresh worktree of a
+configured repo (--repo), under --path (default: the project's name),
+the repo's checks run, and the result is pushed to otto/new/<name> and
+opened as a PR that otto then tracks. With --create-repo, otto first
+creates a private repository through the provider API, clones it to
+--dir, pushes an initial commit to main, and adds it as a configured
+repo; the project then goes at its root.
+
+Templates are markdown files describing what to create. otto ships a
+few (see otto new templates); add your own, or replace a built-in one,
+as .otto/templates/<name>.md in a repo or ~/.config/otto/templates/<name>.md.
+They are Go templates with {{.Kind}}, {{.Name}}, {{.Description}},
+{{.Module}}, and {{.Path}}.`,
+	Example: `  otto new service billing --template go-service --repo platform --path services/billing
+  otto new service billing --template go-service --create-repo acme/billing
+  otto new templates`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		w := cmd.OutOrStdout()
+		kind, name := args[0], args[1]
+		templateName, _ := cmd.Flags().GetString("template")
+		repoName, _ := cmd.Flags().GetString("repo")
+		createRepo, _ := cmd.Flags().GetString("create-repo")
+		dir, _ := cmd.Flags().GetString("dir")
+		projectPath, _ := cmd.Flags().GetString("path")
+		description, _ := cmd.Flags().GetString("description")
+		target, _ := cmd.Flags().GetString("target")
+		noMonitor, _ := cmd.Flags().GetBool("no-monitor")
+
+		if (repoName == "") == (createRepo == "") {
+			return fmt.Errorf("give one of --repo or --create-repo")
+		}
+		tmpl, err := scaffold.Load(templateName)
+		if err != nil {
+			return err
+		}
+
+		var rc *config.RepoConfig
+		if createRepo != "" {
+			if rc, err = createScaffoldRepo(cmd, createRepo, dir); err != nil {
+				return err
+			}
+			if projectPath == "" {
+				projectPath = "."
+			}
+		} else {
+			if rc = findRepo(repoName); rc == nil {
+				return fmt.Errorf("repo %q is not configured (see otto repo list)", repoName)
+			}
+			if projectPath == "" {
+				projectPath = name
+			}
+		}
+		p := scaffold.Project{Kind: kind, Name: name, Description: description, Path: projectPath}
+
+		llmClient, err := llm.NewFallbackClient(appConfig.Models, "")
+		if err != nil {
+			return err
+		}
+		if err := llmClient.Start(ctx); err != nil {
+			return fmt.Errorf("starting LLM client: %w", err)
+		}
+		defer llmClient.Stop()
+
+		fmt.Fprintf(w, "Scaffolding %s %s in %s from %s...\n", kind, name, rc.Name, tmpl.Name)
+		cb, err := server.ScaffoldProject(ctx, appConfig, llmClient, rc, tmpl, p, target)
+		if err != nil {
+			return fmt.Errorf("scaffolding %s: %w", name, err)
+		}
+		if cb == nil {
+			return fmt.Errorf("the LLM created no files for %s", name)
+		}
+		fmt.Fprintf(w, "  ✓ Pushed %s → %s (%d files)\n", cb.Branch, cb.Target, len(cb.Files))
+		if cb.Failed {
+			fmt.Fprintf(w, "  ⚠ The repo's checks failed; see the PR description\n")
+		}
+
+		backend, providerName, err := repoBackend(rc.PrimaryDir)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Creating PR...\n")
+		prInfo, err := backend.CreatePR(ctx, provider.CreatePRParams{
+			Title:        fmt.Sprintf("feat: scaffold %s %s", kind, name),
+			Description:  server.NewProjectDescription(tmpl, p, cb),
+			SourceBranch: cb.Branch,
+			TargetBranch: cb.Target,
+			Labels:       policy.Labels(appConfig.PR.Labels, cb.Files),
+		})
+		if err != nil {
+			return fmt.Errorf("creating PR: %w", err)
+		}
+		fmt.Fprintf(w, "  ✓ Created PR #%s: %s\n", prInfo.ID, prInfo.URL)
+
+		if !noMonitor {
+			registerPRForMonitoring(ctx, w, backend, prInfo, providerName, nil)
+		}
+		return nil
+	},
+}
+
+var newTemplatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List the project templates",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		templates, err := scaffold.List()
+		if err != nil {
+			return fmt.Errorf("listing templates: %w", err)
+		}
+
+		if jsonOut, _ := cmd.Flags().GetBool("json"); jsonOut {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(templates)
+		}
+
+		rows := make([][]string, 0, len(templates))
+		for _, t := range templates {
+			rows = append(rows, []string{t.Name, t.Description, t.Source})
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), styledTable([]string{"NAME", "DESCRIPTION", "SOURCE"}, rows))
+		return nil
+	},
+}
+
+func init() {
+	newCmd.Flags().String("template", "", "Template to scaffold from (see otto new templates)")
+	newCmd.Flags().String("repo", "", "Configured repo to add the project to")
+	newCmd.Flags().String("create-repo", "", `Create this repository for the project instead ("owner/name" on GitHub, a name on ADO)`)
+	newCmd.Flags().String("dir", "", "Where to clone the created repository (default: ./<name>)")
+	newCmd.Flags().String("path", "", "Directory of the project in the repo (default: its name; the root of a created repo)")
+	newCmd.Flags().String("description", "", "What the project is for, for the LLM and the PR")
+	newCmd.Flags().String("target", "", "Target branch (default: the repo's default branch)")
+	newCmd.Flags().Bool("no-monitor", false, "Skip registering the PR for monitoring")
+	_ = newCmd.MarkFlagRequired("template")
+	_ = newCmd.RegisterFlagCompletionFunc("repo", completeRepoNames)
+	_ = newCmd.RegisterFlagCompletionFunc("template", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		templates, _ := scaffold.List()
+		names := make([]string, 0, len(templates))
+		for _, t := range templates {
+			names = append(names, t.Name)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	})
+	newTemplatesCmd.Flags().Bool("json", false, "Output raw JSON")
+	newCmd.AddCommand(newTemplatesCmd)
+}
+
+// createScaffoldRepo creates the repository name with the default
+// provider, clones it to dir with an initial commit on main, and adds it
+// as a configured repo.
+func createScaffoldRepo(cmd *cobra.Command, name, dir string) (*config.RepoConfig, error) {
+	ctx := cmd.Context()
+	w := cmd.OutOrStdout()
+	providerName := appConfig.PR.DefaultProvider
+	if providerName == "" {
+		providerName = "ado"
+	}
+	backend, err := buildRegistry().Get(providerName)
+	if err != nil {
+		return nil, fmt.Errorf("getting provider %q: %w", providerName, err)
+	}
+	creator, ok := backend.(provider.RepoCreator)
+	if !ok {
+		return nil, fmt.Errorf("provider %q can't create repositories", providerName)
+	}
+
+	repoName := path.Base(name)
+	if findRepo(repoName) != nil {
+		return nil, fmt.Errorf("repo %q is already configured", repoName)
+	}
+	if dir == "" {
+		dir = repoName
+	}
+	if dir, err = filepath.Abs(config.ExpandHome(dir)); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(w, "Creating repository %s...\n", name)
+	remote, err := creator.CreateRepository(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(w, "  ✓ Created %s\n", remote)
+	if err := server.InitRepository(ctx, appConfig, remote, dir, repoName, "main"); err != nil {
+		return nil, fmt.Errorf("initializing %s: %w", remote, err)
+	}
+	fmt.Fprintf(w, "  ✓ Cloned to %s with an initial commit on main\n", dir)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("getting config dir: %w", err)
+	}
+	rc := config.RepoConfig{
+		Name:           repoName,
+		PrimaryDir:     dir,
+		GitStrategy:    config.GitStrategyWorktree,
+		BranchTemplate: "otto/{{.Name}}",
+	}
+	if err := repo.NewManager(configDir).Add(appConfig, rc); err != nil {
+		return nil, fmt.Errorf("adding repo: %w", err)
+	}
+	fmt.Fprintf(w, "  ✓ Added repository %q\n", repoName)
+	return findRepo(repoName), nil
+}
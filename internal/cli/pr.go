@@ -9,10 +9,15 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/alanmeadows/otto/internal/audit"
+	"github.com/alanmeadows/otto/internal/config"
 	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/policy"
 	"github.com/alanmeadows/otto/internal/prompts"
 	"github.com/alanmeadows/otto/internal/provider"
 	"github.com/alanmeadows/otto/internal/provider/ado"
@@ -40,49 +45,25 @@ and pushes updated code — up to the configured max fix attempts.`,
 
 func init() {
 	prCmd.AddCommand(prAddCmd)
+	prCmd.AddCommand(prImportCmd)
 	prCmd.AddCommand(prListCmd)
 	prCmd.AddCommand(prStatusCmd)
 	prCmd.AddCommand(prRemoveCmd)
+	prCmd.AddCommand(prApproveCmd)
+	prCmd.AddCommand(prReadyCmd)
 	prCmd.AddCommand(prFixCmd)
+	prCmd.AddCommand(prResolveConflictsCmd)
+	prCmd.AddCommand(prRevertCmd)
 	prCmd.AddCommand(prLogCmd)
+	prCmd.AddCommand(prTranscriptCmd)
+	prCmd.AddCommand(prReplayCmd)
 	prCmd.AddCommand(prReviewCmd)
 	prCmd.AddCommand(prSubmitCmd)
 }
 
 // buildRegistry creates a provider registry populated with backends from config.
 func buildRegistry() *provider.Registry {
-	reg := provider.NewRegistry()
-
-	if appConfig != nil && appConfig.PR.Providers != nil {
-		// Register ADO backend if configured.
-		if adoCfg, ok := appConfig.PR.Providers["ado"]; ok {
-			auth := ado.NewAuthProvider(adoCfg.PAT)
-			adoBackend := ado.NewBackend(adoCfg.Organization, adoCfg.Project, auth)
-			reg.Register(adoBackend)
-		}
-
-		// Register GitHub backend if configured.
-		if ghCfg, ok := appConfig.PR.Providers["github"]; ok {
-			ghBack := ghbackend.NewBackend("", "", ghCfg.Token)
-			reg.Register(ghBack)
-		}
-	}
-
-	// Always register GitHub as a fallback — it can auth via GITHUB_TOKEN
-	// env var or gh CLI, and should match any github.com URL.
-	if !reg.HasBackendFor("github.com") {
-		token := os.Getenv("GITHUB_TOKEN")
-		if token == "" {
-			// Try gh CLI auth token.
-			if out, err := exec.Command("gh", "auth", "token").Output(); err == nil {
-				token = strings.TrimSpace(string(out))
-			}
-		}
-		ghBack := ghbackend.NewBackend("", "", token)
-		reg.Register(ghBack)
-	}
-
-	return reg
+	return server.BuildRegistry(appConfig)
 }
 
 var prAddCmd = &cobra.Command{
@@ -92,7 +73,10 @@ var prAddCmd = &cobra.Command{
 
 Otto detects the provider (GitHub or ADO) from the URL, fetches PR
 metadata, and creates a local PR document. The daemon will begin
-polling this PR for review feedback.`,
+polling this PR for review feedback. With pr.risk_analysis (on by
+default), the PR's diff is analyzed for size, touched areas, risky
+changes, and code changed without tests; the report goes in the PR
+document and a pr_tracked notification.`,
 	Example: `  otto pr add https://github.com/org/repo/pull/42
   otto pr add https://dev.azure.com/org/project/_git/repo/pullrequest/123`,
 	Args: cobra.ExactArgs(1),
@@ -134,12 +118,16 @@ polling this PR for review feedback.`,
 			MaxFixAttempts: maxAttempts,
 			Body:           fmt.Sprintf("# %s\n\n%s\n", prInfo.Title, prInfo.Description),
 		}
+		server.AnalyzeNewPR(ctx, appConfig, backend, prInfo, pr, nil)
 
 		if err := server.SavePR(pr); err != nil {
 			return fmt.Errorf("saving PR document: %w", err)
 		}
 
 		fmt.Fprintf(w, "Added PR #%s (%s) - %s\n", pr.ID, backend.Name(), prInfo.Title)
+		if pr.Risk != "" {
+			fmt.Fprintf(w, "  Risk: %s\n", pr.Risk)
+		}
 
 		// Trigger work item creation if configured (ADO only).
 		providerName := backend.Name()
@@ -173,12 +161,21 @@ polling this PR for review feedback.`,
 	},
 }
 
+// quiet trims pr list and pr status output to what a script needs.
+var quiet bool
+
+func init() {
+	prListCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Print one ID<tab>status line per PR, for scripts")
+	prStatusCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Print only the status, for scripts")
+}
+
 var prListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List tracked PRs",
 	Long: `Display all tracked pull requests in a table.
 
-Shows PR ID, provider, status, branches, and fix attempt counts.`,
+Shows PR ID, provider, status, branches, and fix attempt counts. With
+--quiet, prints one "ID<tab>status" line per PR instead.`,
 	Example: `  otto pr list`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		prs, err := server.ListPRs()
@@ -186,6 +183,12 @@ Shows PR ID, provider, status, branches, and fix attempt counts.`,
 			return fmt.Errorf("listing PRs: %w", err)
 		}
 
+		if quiet {
+			for _, pr := range prs {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", pr.ID, pr.Status)
+			}
+			return nil
+		}
 		if len(prs) == 0 {
 			fmt.Fprintln(cmd.OutOrStdout(), "No tracked PRs. Add one with: otto pr add <url>")
 			return nil
@@ -201,9 +204,9 @@ Shows PR ID, provider, status, branches, and fix attempt counts.`,
 			// Build stages column: checkmarks for completed stages.
 			var stages []string
 			if pr.MerlinBotDone {
-				stages = append(stages, "✓ merlinbot")
+				stages = append(stages, "✓ bots")
 			} else {
-				stages = append(stages, "○ merlinbot")
+				stages = append(stages, "○ bots")
 			}
 			if pr.FeedbackDone {
 				stages = append(stages, "✓ feedback")
@@ -252,22 +255,39 @@ var prStatusCmd = &cobra.Command{
 	Long: `Show detailed status for a tracked pull request.
 
 If no ID is given, otto infers the PR from the current branch.
-Displays provider, status, branches, URL, and fix attempt count.`,
+Displays provider, status, branches, the local worktree of the PR's
+branch (if it is checked out), URL, and fix attempt count.
+
+With --wait, blocks until the PR is green (or, with --until merged,
+merged), then shows its status. The exit code says how the wait ended:
+0 reached, 2 failed, 3 timed out, 4 abandoned, 1 any other error.
+With --quiet, only the status word is printed.`,
 	Example: `  otto pr status
-  otto pr status 42`,
+  otto pr status 42
+  otto pr status 42 --wait --until merged --timeout 30m -q`,
+	ValidArgsFunction: completePRIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var pr *server.PRDocument
-		var err error
-
-		if len(args) > 0 {
-			pr, err = server.FindPR(args[0])
-		} else {
-			pr, err = server.InferPR()
+		if err := checkWaitFlags(cmd); err != nil {
+			return err
 		}
+		pr, err := resolvePR(args)
 		if err != nil {
 			return err
 		}
 
+		var waitErr error
+		if prStatusWaitFlag {
+			progress := cmd.ErrOrStderr()
+			if quiet {
+				progress = io.Discard
+			}
+			pr, waitErr = waitForPR(cmd.Context(), pr, prStatusUntilFlag, prStatusTimeoutFlag, progress)
+		}
+		if quiet {
+			fmt.Fprintln(cmd.OutOrStdout(), pr.Status)
+			return waitErr
+		}
+
 		labelStyle := lipgloss.NewStyle().Bold(true)
 
 		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", labelStyle.Render("PR ID:"), pr.ID)
@@ -275,12 +295,42 @@ Displays provider, status, branches, URL, and fix attempt count.`,
 		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", labelStyle.Render("Status:"), pr.Status)
 		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", labelStyle.Render("Repo:"), pr.Repo)
 		fmt.Fprintf(cmd.OutOrStdout(), "%s %s → %s\n", labelStyle.Render("Branch:"), pr.Branch, pr.Target)
+		if wt := server.PRWorktree(cmd.Context(), appConfig, pr); wt != nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", labelStyle.Render("Worktree:"), describeWorktree(wt))
+		}
 		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", labelStyle.Render("URL:"), pr.URL)
+		if len(pr.WorkItems) > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s #%s\n", labelStyle.Render("Work Items:"), strings.Join(pr.WorkItems, ", #"))
+		}
 		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", labelStyle.Render("Created:"), pr.Created)
 		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", labelStyle.Render("Last Checked:"), pr.LastChecked)
 		fmt.Fprintf(cmd.OutOrStdout(), "%s %d/%d\n", labelStyle.Render("Fix Attempts:"), pr.FixAttempts, pr.MaxFixAttempts)
+		if len(pr.FixAttemptsByCategory) > 0 {
+			cats := make([]string, 0, len(pr.FixAttemptsByCategory))
+			for cat, n := range pr.FixAttemptsByCategory {
+				budget := "∞"
+				if b, ok := appConfig.PR.FixBudgets[cat]; ok && b >= 0 {
+					budget = strconv.Itoa(b)
+				}
+				cats = append(cats, fmt.Sprintf("%s %d/%s", cat, n, budget))
+			}
+			sort.Strings(cats)
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", labelStyle.Render("By Category:"), strings.Join(cats, ", "))
+		}
+		if pr.InfraRetries > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %d (next no sooner than %s)\n", labelStyle.Render("Infra Retries:"), pr.InfraRetries, pr.NextInfraRetry)
+		}
+		if pr.LastModel != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", labelStyle.Render("Last Model:"), pr.LastModel)
+		}
+		if pr.PolicyHold != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s (approve with 'otto pr approve %s')\n", labelStyle.Render("Policy Hold:"), pr.PolicyHold, pr.ID)
+		}
+		if pr.LastChecks != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", labelStyle.Render("Last Checks:"), pr.LastChecks)
+		}
 
-		return nil
+		return waitErr
 	},
 }
 
@@ -293,15 +343,9 @@ If no ID is given, otto infers the PR from the current branch.
 This does not close the PR on the remote provider.`,
 	Example: `  otto pr remove 42
   otto pr remove`,
+	ValidArgsFunction: completePRIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var pr *server.PRDocument
-		var err error
-
-		if len(args) > 0 {
-			pr, err = server.FindPR(args[0])
-		} else {
-			pr, err = server.InferPR()
-		}
+		pr, err := resolvePR(args)
 		if err != nil {
 			return err
 		}
@@ -315,6 +359,119 @@ This does not close the PR on the remote provider.`,
 	},
 }
 
+var prApproveCmd = &cobra.Command{
+	Use:   "approve [id]",
+	Short: "Approve a push held by the change policy",
+	Long: `Approve the commit a PR's policy hold is holding, so the daemon pushes it.
+
+When an automated commit touches a protected path or exceeds the diff
+size limits in pr.policy, otto holds it and stops fixing the PR. Review
+the held commit (see 'otto pr log'), then approve it: the daemon pushes
+exactly that commit on its next poll, provided the branch hasn't moved
+since it was held. If no ID is given, otto infers the PR from the
+current branch.`,
+	Example: `  otto pr approve 42
+  otto pr approve`,
+	ValidArgsFunction: completePRIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pr, err := resolvePR(args)
+		if err != nil {
+			return err
+		}
+		var hold, commit string
+		_, err = server.UpdatePR(pr.Provider, pr.ID, func(p *server.PRDocument) error {
+			if p.PolicyHold == "" {
+				return fmt.Errorf("PR #%s has no policy hold to approve", p.ID)
+			}
+			hold, commit = p.PolicyHold, p.PolicyHoldCommit
+			if len(commit) > 8 {
+				commit = commit[:8]
+			}
+			p.PolicyHold = ""
+			// Holds recorded without their commit have nothing to push.
+			p.PolicyApproved = commit != ""
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := server.RecordPREvent(pr.Provider, pr.ID, server.PREvent{Kind: server.PREventPolicyApproved, Detail: hold, Commit: commit}); err != nil {
+			slog.Warn("failed to record policy approval", "prID", pr.ID, "error", err)
+		}
+
+		if commit == "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "Released the policy hold on PR #%s\n", pr.ID)
+			return nil
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Approved commit %s for PR #%s; the daemon pushes it on its next poll\n", commit, pr.ID)
+		return nil
+	},
+}
+
+var prReadyCmd = &cobra.Command{
+	Use:   "ready [id]",
+	Short: "Publish a draft PR for review once its pipelines are green",
+	Long: `Mark a tracked draft PR ready for review through the provider API.
+
+Otto checks the PR's pipelines first and refuses unless they succeeded;
+--force publishes regardless. Once published, the daemon starts fixing
+and answering comments on the PR (drafts are only watched unless
+pr.work_on_drafts is set). If no ID is given, otto infers the PR from the
+current branch.`,
+	Example: `  otto pr ready 42
+  otto pr ready --force`,
+	ValidArgsFunction: completePRIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		force, _ := cmd.Flags().GetBool("force")
+
+		pr, err := resolvePR(args)
+		if err != nil {
+			return err
+		}
+
+		backend, err := buildRegistry().Get(pr.Provider)
+		if err != nil {
+			return fmt.Errorf("getting provider %q: %w", pr.Provider, err)
+		}
+		prInfo, err := backend.GetPR(ctx, pr.URL)
+		if err != nil {
+			return fmt.Errorf("fetching PR: %w", err)
+		}
+		if !prInfo.IsDraft {
+			return fmt.Errorf("PR #%s is not a draft", pr.ID)
+		}
+
+		if !force {
+			status, err := backend.GetPipelineStatus(ctx, prInfo)
+			if err != nil {
+				return fmt.Errorf("checking pipelines: %w", err)
+			}
+			if status.State != "succeeded" {
+				return fmt.Errorf("PR #%s pipelines are %s, not green; use --force to publish anyway", pr.ID, status.State)
+			}
+		}
+
+		if err := audit.WrapBackend(backend).MarkReady(ctx, prInfo); err != nil {
+			return fmt.Errorf("marking PR ready: %w", err)
+		}
+		_, err = server.UpdatePR(pr.Provider, pr.ID, func(p *server.PRDocument) error {
+			p.IsDraft = false
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("saving PR: %w", err)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "PR #%s is ready for review\n", pr.ID)
+		return nil
+	},
+}
+
+func init() {
+	prReadyCmd.Flags().Bool("force", false, "Publish even if pipelines are not green")
+}
+
 var prFixCmd = &cobra.Command{
 	Use:   "fix [id]",
 	Short: "Fix PR review issues",
@@ -325,17 +482,11 @@ resolution, and pushes the resulting changes. Increments the
 fix attempt counter. If no ID is given, infers from current branch.`,
 	Example: `  otto pr fix
   otto pr fix 42`,
+	ValidArgsFunction: completePRIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 
-		var pr *server.PRDocument
-		var err error
-
-		if len(args) > 0 {
-			pr, err = server.FindPR(args[0])
-		} else {
-			pr, err = server.InferPR()
-		}
+		pr, err := resolvePR(args)
 		if err != nil {
 			return err
 		}
@@ -348,12 +499,16 @@ fix attempt counter. If no ID is given, infers from current branch.`,
 		}
 
 		// Create LLM client.
-		llmClient := llm.NewCopilotClient(appConfig.Models.Primary)
+		llmClient, err := llm.NewFallbackClient(appConfig.Models, "")
+		if err != nil {
+			return err
+		}
 		if err := llmClient.Start(ctx); err != nil {
-			return fmt.Errorf("starting Copilot LLM client: %w", err)
+			return fmt.Errorf("starting LLM client: %w", err)
 		}
 		defer llmClient.Stop()
 
+		ctx = llm.WithProgress(ctx, progressPrinter(cmd.ErrOrStderr()))
 		if err := server.FixPR(ctx, pr, backend, llmClient, appConfig); err != nil {
 			return fmt.Errorf("fixing PR: %w", err)
 		}
@@ -363,35 +518,145 @@ fix attempt counter. If no ID is given, infers from current branch.`,
 	},
 }
 
-var prLogCmd = &cobra.Command{
-	Use:   "log [id]",
-	Short: "Show PR activity log",
-	Long: `Display the activity log for a tracked pull request.
-
-Shows the stored markdown body including review summaries, fix
-attempts, and any notes. If no ID is given, infers from the
-current branch.`,
-	Example: `  otto pr log
-  otto pr log 42`,
+var prResolveConflictsCmd = &cobra.Command{
+	Use:   "resolve-conflicts [id]",
+	Short: "Rebase a PR onto its target, resolving conflicts with the LLM",
+	Long: `Run otto's conflict resolution on a tracked PR now, outside the daemon.
+
+Otto rebases the PR branch onto the latest target branch. If git cannot
+resolve the conflicts, the LLM resolves them using the branch's commits
+as intent, then otto force-pushes (with lease). With --dry-run, otto
+performs the rebase in a throwaway worktree and prints the commits to
+replay, the conflicted files, and the plan, without pushing anything.
+If no ID is given, infers from current branch.`,
+	Example: `  otto pr resolve-conflicts --dry-run
+  otto pr resolve-conflicts 42`,
+	ValidArgsFunction: completePRIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var pr *server.PRDocument
-		var err error
+		ctx := cmd.Context()
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
 
-		if len(args) > 0 {
-			pr, err = server.FindPR(args[0])
-		} else {
-			pr, err = server.InferPR()
+		pr, err := resolvePR(args)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			preview, err := server.PreviewConflicts(ctx, pr, appConfig)
+			if err != nil {
+				return fmt.Errorf("previewing conflicts: %w", err)
+			}
+			printConflictPreview(cmd.OutOrStdout(), pr, preview)
+			return nil
+		}
+
+		reg := buildRegistry()
+		backend, err := reg.Get(pr.Provider)
+		if err != nil {
+			return fmt.Errorf("getting provider %q: %w", pr.Provider, err)
+		}
+
+		llmClient, err := llm.NewFallbackClient(appConfig.Models, "")
+		if err != nil {
+			return err
+		}
+		if err := llmClient.Start(ctx); err != nil {
+			return fmt.Errorf("starting LLM client: %w", err)
+		}
+		defer llmClient.Stop()
+
+		ctx = llm.WithProgress(ctx, progressPrinter(cmd.ErrOrStderr()))
+		if err := server.ResolveConflicts(ctx, pr, backend, llmClient, appConfig); err != nil {
+			return fmt.Errorf("resolving conflicts: %w", err)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "PR #%s rebased onto %s and pushed\n", pr.ID, strings.TrimPrefix(pr.Target, "refs/heads/"))
+		return nil
+	},
+}
+
+func init() {
+	prResolveConflictsCmd.Flags().Bool("dry-run", false, "Preview conflicted files and the plan without pushing")
+}
+
+// printConflictPreview renders a dry-run conflict resolution plan.
+func printConflictPreview(w io.Writer, pr *server.PRDocument, p *server.ConflictPreview) {
+	branch := strings.TrimPrefix(pr.Branch, "refs/heads/")
+	fmt.Fprintf(w, "PR #%s: rebase %s onto origin/%s\n\n", pr.ID, branch, p.Target)
+
+	fmt.Fprintf(w, "Commits to replay (%d):\n", len(p.Commits))
+	for _, c := range p.Commits {
+		fmt.Fprintf(w, "  %s\n", c)
+	}
+	if p.DiffStat != "" {
+		fmt.Fprintf(w, "\n%s\n", p.DiffStat)
+	}
+
+	fmt.Fprintln(w)
+	if p.Clean {
+		fmt.Fprintln(w, "No conflicts: the rebase applies cleanly.")
+		fmt.Fprintln(w, "\nPlan:")
+		fmt.Fprintf(w, "  1. Rebase %s onto origin/%s\n", branch, p.Target)
+		fmt.Fprintf(w, "  2. Force-push (with lease) to %s\n", branch)
+	} else {
+		fmt.Fprintf(w, "Conflicts replaying %s:\n", p.StoppedAt)
+		for _, f := range p.Conflicted {
+			fmt.Fprintf(w, "  %s\n", f)
 		}
+		fmt.Fprintln(w, "\nPlan:")
+		fmt.Fprintf(w, "  1. Rebase %s onto origin/%s\n", branch, p.Target)
+		fmt.Fprintln(w, "  2. Have the LLM resolve the conflict markers, using the commits above as intent, and continue the rebase (later commits may conflict too)")
+		fmt.Fprintf(w, "  3. Force-push (with lease) to %s\n", branch)
+	}
+	fmt.Fprintln(w, "\nDry run: nothing was pushed. Run without --dry-run to apply.")
+}
+
+var prTranscriptCmd = &cobra.Command{
+	Use:   "transcript <id> [attempt]",
+	Short: "Show the LLM transcripts of a PR's fixes",
+	Long: `Show exactly what the model saw and did while otto worked on a PR.
+
+Every FixPR, conflict resolution, and bot review session records its
+prompts, responses, and tool calls under
+` + dataPath("transcripts") + `. Without an attempt number, lists the
+PR's transcripts, numbered oldest first; with one, prints that
+transcript.`,
+	Example: `  otto pr transcript 42
+  otto pr transcript 42 3`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeTranscripts,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pr, err := server.FindPR(args[0])
 		if err != nil {
 			return err
 		}
 
-		if pr.Body == "" {
-			fmt.Fprintln(cmd.OutOrStdout(), "No activity log for this PR.")
+		transcripts, err := server.ListTranscripts(pr)
+		if err != nil {
+			return err
+		}
+		if len(transcripts) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No transcripts for this PR.")
 			return nil
 		}
 
-		fmt.Fprintln(cmd.OutOrStdout(), pr.Body)
+		out := cmd.OutOrStdout()
+		if len(args) == 1 {
+			for i, t := range transcripts {
+				fmt.Fprintf(out, "%3d  %s  %s\n", i+1, t.Started.Local().Format("2006-01-02 15:04:05"), t.Title)
+			}
+			return nil
+		}
+
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 || n > len(transcripts) {
+			return fmt.Errorf("attempt must be a number from 1 to %d", len(transcripts))
+		}
+		data, err := os.ReadFile(transcripts[n-1].Path)
+		if err != nil {
+			return fmt.Errorf("reading transcript: %w", err)
+		}
+		fmt.Fprint(out, string(data))
 		return nil
 	},
 }
@@ -402,10 +667,16 @@ var prSubmitCmd = &cobra.Command{
 	Long: `Push the current branch and create a pull request on the configured provider.
 
 This command is idempotent — if a PR already exists for the current branch,
-it prints the existing PR URL and skips creation. After creating the PR,
-otto optionally enables auto-complete, creates a linked work item, waits
+it prints the existing PR URL and skips creation. With pr.work_items.link,
+work items (ADO) or issues (GitHub) mentioned in the branch name or
+commit messages are linked to the new PR. After creating the PR, otto
+optionally enables auto-complete, creates a linked work item, waits
 for MerlinBot comments, evaluates and addresses them, then registers the
-PR for monitoring.
+PR for monitoring. With pr.risk_analysis (on by default), the description
+ends with a risk analysis of the branch's changes. pr.labels rules label
+the PR by the paths it changes, and with pr.title_convention.enabled a
+title that isn't a Conventional Commits title ("feat: ...") is reformatted
+by the LLM, or prefixed with a type guessed from the branch name.
 
 Flags:
   --title     Override the PR title (default: LLM-generated from spec/commits)
@@ -473,22 +744,9 @@ func submitPR(ctx context.Context, cmd *cobra.Command, titleOverride, targetBran
 	}
 
 	// Step 3: Get backend.
-	providerName := appConfig.PR.DefaultProvider
-	if providerName == "" {
-		providerName = "ado"
-	}
-	reg := buildRegistry()
-	backend, err := reg.Get(providerName)
+	backend, providerName, err := repoBackend(workDir)
 	if err != nil {
-		return fmt.Errorf("getting provider %q: %w", providerName, err)
-	}
-
-	// Set repository on backend if ADO.
-	if adoBackend, ok := backend.(*ado.Backend); ok {
-		repoName := repoNameFromRemote(workDir)
-		if repoName != "" {
-			adoBackend.SetRepository(repoName)
-		}
+		return err
 	}
 
 	// Step 4: Push branch.
@@ -531,7 +789,7 @@ func submitPR(ctx context.Context, cmd *cobra.Command, titleOverride, targetBran
 			}
 		}
 		if !noMonitor {
-			registerPRForMonitoring(w, existingPR, providerName)
+			registerPRForMonitoring(ctx, w, backend, existingPR, providerName, nil)
 		}
 		return nil
 	}
@@ -548,15 +806,51 @@ func submitPR(ctx context.Context, cmd *cobra.Command, titleOverride, targetBran
 		prDescription = fmt.Sprintf("Automated PR for branch %s", branchName)
 	}
 
+	// Make the title follow Conventional Commits, if configured.
+	if tc := appConfig.PR.TitleConvention; tc.Enabled && !tc.Conforms(prTitle) {
+		prTitle = conventionalPRTitle(ctx, workDir, branchName, targetBranch, prTitle, tc)
+	}
+
 	fmt.Fprintf(w, "PR Title: %s\n", prTitle)
 
+	// Analyze the change's risk and put the report in the description.
+	var risk *policy.RiskReport
+	if appConfig.PR.RiskAnalysis {
+		risk = localRiskReport(ctx, workDir, targetBranch)
+		if risk != nil {
+			fmt.Fprintf(w, "Risk: %s\n", risk.Summary())
+			prDescription = strings.TrimRight(prDescription, "\n") + "\n\n" + risk.Markdown()
+		}
+	}
+
+	// Link work items or issues mentioned in the branch name or commits.
+	var workItems []string
+	if appConfig.PR.WorkItems.Link {
+		workItems = appConfig.PR.WorkItems.Find(branchName, commitMessages(ctx, workDir, targetBranch))
+		if len(workItems) > 0 {
+			fmt.Fprintf(w, "Linking work items: #%s\n", strings.Join(workItems, ", #"))
+		}
+	}
+
+	// Label the PR by the paths it changes.
+	var labels []string
+	if len(appConfig.PR.Labels) > 0 {
+		labels = policy.Labels(appConfig.PR.Labels, changedFiles(ctx, workDir, targetBranch))
+		if len(labels) > 0 {
+			fmt.Fprintf(w, "Labels: %s\n", strings.Join(labels, ", "))
+		}
+	}
+
 	// Step 7: Create PR.
 	fmt.Fprintf(w, "Creating PR...\n")
 	prInfo, err := backend.CreatePR(ctx, provider.CreatePRParams{
-		Title:        prTitle,
-		Description:  prDescription,
-		SourceBranch: branchName,
-		TargetBranch: targetBranch,
+		Title:            prTitle,
+		Description:      prDescription,
+		SourceBranch:     branchName,
+		TargetBranch:     targetBranch,
+		WorkItems:        workItems,
+		ResolveWorkItems: appConfig.PR.WorkItems.ResolveOnMerge,
+		Labels:           labels,
 	})
 	if err != nil {
 		return fmt.Errorf("creating PR: %w", err)
@@ -611,7 +905,7 @@ func submitPR(ctx context.Context, cmd *cobra.Command, titleOverride, targetBran
 
 	// Step 10: Register for monitoring — daemon handles MerlinBot asynchronously.
 	if !noMonitor {
-		registerPRForMonitoring(w, prInfo, providerName)
+		registerPRForMonitoring(ctx, w, backend, prInfo, providerName, risk)
 	}
 
 	fmt.Fprintf(w, "\nDone! PR #%s: %s\n", prInfo.ID, prInfo.URL)
@@ -620,25 +914,10 @@ func submitPR(ctx context.Context, cmd *cobra.Command, titleOverride, targetBran
 
 // generatePRDescription uses the LLM to generate a PR title and description.
 func generatePRDescription(ctx context.Context, w io.Writer, workDir, branchName, targetBranch, titleOverride string) (string, string, error) {
-	// Gather commit log.
-	logCmd := exec.CommandContext(ctx, "git", "log", fmt.Sprintf("origin/%s..HEAD", targetBranch), "--oneline", "--no-decorate")
-	logCmd.Dir = workDir
-	logOut, err := logCmd.Output()
-	if err != nil {
-		// Fallback: use all recent commits.
-		logCmd = exec.CommandContext(ctx, "git", "log", "-20", "--oneline", "--no-decorate")
-		logCmd.Dir = workDir
-		logOut, _ = logCmd.Output()
-	}
-	commitLog := strings.TrimSpace(string(logOut))
-	if commitLog == "" {
-		commitLog = "(no commits)"
-	}
-
 	// Build prompt.
 	templateData := map[string]string{
 		"BranchName": branchName,
-		"CommitLog":  commitLog,
+		"CommitLog":  branchCommitLog(ctx, workDir, targetBranch),
 	}
 
 	prompt, err := prompts.Execute("pr-description.md", templateData)
@@ -647,9 +926,12 @@ func generatePRDescription(ctx context.Context, w io.Writer, workDir, branchName
 	}
 
 	// Create LLM client.
-	llmClient := llm.NewCopilotClient(appConfig.Models.Primary)
+	llmClient, err := llm.NewFallbackClient(appConfig.Models, "")
+	if err != nil {
+		return "", "", err
+	}
 	if err := llmClient.Start(ctx); err != nil {
-		return "", "", fmt.Errorf("starting Copilot LLM client: %w", err)
+		return "", "", fmt.Errorf("starting LLM client: %w", err)
 	}
 	defer llmClient.Stop()
 
@@ -680,6 +962,75 @@ func generatePRDescription(ctx context.Context, w io.Writer, workDir, branchName
 	return title, description, nil
 }
 
+// branchCommitLog returns the one-line log of the branch's commits not on
+// targetBranch, or of recent commits if the branch can't be compared.
+func branchCommitLog(ctx context.Context, workDir, targetBranch string) string {
+	logCmd := exec.CommandContext(ctx, "git", "log", fmt.Sprintf("origin/%s..HEAD", targetBranch), "--oneline", "--no-decorate")
+	logCmd.Dir = workDir
+	logOut, err := logCmd.Output()
+	if err != nil {
+		// Fallback: use all recent commits.
+		logCmd = exec.CommandContext(ctx, "git", "log", "-20", "--oneline", "--no-decorate")
+		logCmd.Dir = workDir
+		logOut, _ = logCmd.Output()
+	}
+	commitLog := strings.TrimSpace(string(logOut))
+	if commitLog == "" {
+		commitLog = "(no commits)"
+	}
+	return commitLog
+}
+
+// conventionalPRTitle asks the LLM to reformat title into a Conventional
+// Commits title with one of tc's types. If the LLM fails or its answer
+// still doesn't conform, title is prefixed with a type guessed from the
+// branch name instead.
+func conventionalPRTitle(ctx context.Context, workDir, branchName, targetBranch, title string, tc config.TitleConventionConfig) string {
+	reformatted, err := reformatPRTitle(ctx, workDir, targetBranch, title, tc)
+	if err != nil {
+		slog.Warn("failed to reformat PR title, prefixing it instead", "error", err)
+	} else if tc.Conforms(reformatted) {
+		return reformatted
+	} else {
+		slog.Warn("LLM title does not follow the title convention, prefixing original", "badTitle", reformatted)
+	}
+	return tc.Prefix(title, branchName)
+}
+
+// reformatPRTitle runs the pr-title prompt on title.
+func reformatPRTitle(ctx context.Context, workDir, targetBranch, title string, tc config.TitleConventionConfig) (string, error) {
+	prompt, err := prompts.Execute("pr-title.md", map[string]string{
+		"Title":     title,
+		"CommitLog": branchCommitLog(ctx, workDir, targetBranch),
+		"Types":     strings.Join(tc.AllowedTypes(), ", "),
+	})
+	if err != nil {
+		return "", fmt.Errorf("building PR title prompt: %w", err)
+	}
+
+	llmClient, err := llm.NewFallbackClient(appConfig.Models, "")
+	if err != nil {
+		return "", err
+	}
+	if err := llmClient.Start(ctx); err != nil {
+		return "", fmt.Errorf("starting LLM client: %w", err)
+	}
+	defer llmClient.Stop()
+
+	session, err := llmClient.CreateSession(ctx, "PR Title", workDir)
+	if err != nil {
+		return "", fmt.Errorf("creating session: %w", err)
+	}
+	defer llmClient.DeleteSession(ctx, session.ID)
+
+	resp, err := llmClient.SendPrompt(ctx, session.ID, prompt)
+	if err != nil {
+		return "", fmt.Errorf("LLM prompt failed: %w", err)
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(resp.Content), "\n")
+	return strings.Trim(strings.TrimSpace(line), "`\""), nil
+}
+
 // extractPRTitle pulls a clean title from the LLM response, falling back to
 // the branch name if the LLM produced conversational preamble instead.
 func extractPRTitle(description, branchName string) string {
@@ -718,29 +1069,12 @@ func extractPRTitle(description, branchName string) string {
 	return title
 }
 
-// registerPRForMonitoring saves the PR as a tracking document for the monitoring loop.
+// registerPRForMonitoring saves the PR as a tracking document for the monitoring loop,
+// with its risk analysis (fetched from backend unless risk is given).
 // MerlinBot handling is always deferred to the daemon.
-func registerPRForMonitoring(w io.Writer, prInfo *provider.PRInfo, providerName string) {
-	maxAttempts := 5
-	if appConfig != nil && appConfig.PR.MaxFixAttempts > 0 {
-		maxAttempts = appConfig.PR.MaxFixAttempts
-	}
-
-	pr := &server.PRDocument{
-		ID:             prInfo.ID,
-		Title:          prInfo.Title,
-		Provider:       providerName,
-		Repo:           prInfo.RepoID,
-		Branch:         prInfo.SourceBranch,
-		Target:         prInfo.TargetBranch,
-		Status:         "watching",
-		URL:            prInfo.URL,
-		Created:        time.Now().UTC().Format(time.RFC3339),
-		LastChecked:    time.Now().UTC().Format(time.RFC3339),
-		MaxFixAttempts: maxAttempts,
-		PipelineState:  "pending",
-		Body:           fmt.Sprintf("# %s\n\n%s\n", prInfo.Title, prInfo.Description),
-	}
+func registerPRForMonitoring(ctx context.Context, w io.Writer, backend provider.PRBackend, prInfo *provider.PRInfo, providerName string, risk *policy.RiskReport) {
+	pr := server.NewTrackedPR(prInfo, providerName, appConfig)
+	server.AnalyzeNewPR(ctx, appConfig, backend, prInfo, pr, risk)
 
 	if err := server.SavePR(pr); err != nil {
 		fmt.Fprintf(w, "  ⚠ Failed to register PR for monitoring: %v\n", err)
@@ -751,6 +1085,36 @@ func registerPRForMonitoring(w io.Writer, prInfo *provider.PRInfo, providerName
 	}
 }
 
+// localRiskReport analyzes the risk of the current branch's changes
+// against targetBranch, or returns nil if they can't be diffed.
+func localRiskReport(ctx context.Context, workDir, targetBranch string) *policy.RiskReport {
+	cmd := exec.CommandContext(ctx, "git", "diff", "--no-color", "--no-ext-diff", fmt.Sprintf("origin/%s...HEAD", targetBranch))
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		slog.Warn("failed to diff branch for risk analysis", "error", err)
+		return nil
+	}
+	return policy.AnalyzeRisk(string(out))
+}
+
+// changedFiles lists the files the current branch changes against
+// targetBranch, or none if they can't be diffed.
+func changedFiles(ctx context.Context, workDir, targetBranch string) []string {
+	cmd := exec.CommandContext(ctx, "git", "diff", "--name-only", "--no-color", fmt.Sprintf("origin/%s...HEAD", targetBranch))
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		slog.Warn("failed to list changed files for labeling", "error", err)
+		return nil
+	}
+	names := strings.TrimSpace(string(out))
+	if names == "" {
+		return nil
+	}
+	return strings.Split(names, "\n")
+}
+
 // notifyDaemon sends a POST /poll to the running daemon to trigger an immediate poll cycle.
 // Failures are non-fatal — the daemon will pick up the PR on the next regular cycle.
 func notifyDaemon(w io.Writer) {
@@ -769,6 +1133,34 @@ func notifyDaemon(w io.Writer) {
 	}
 }
 
+// repoBackend returns the default provider's backend, and its name, set to
+// the repository of workDir's origin remote.
+func repoBackend(workDir string) (provider.PRBackend, string, error) {
+	providerName := appConfig.PR.DefaultProvider
+	if providerName == "" {
+		providerName = "ado"
+	}
+	reg := buildRegistry()
+	backend, err := reg.Get(providerName)
+	if err != nil {
+		return nil, "", fmt.Errorf("getting provider %q: %w", providerName, err)
+	}
+
+	// Set repository on backend from the remote.
+	if adoBackend, ok := backend.(*ado.Backend); ok {
+		repoName := repoNameFromRemote(workDir)
+		if repoName != "" {
+			adoBackend.SetRepository(repoName)
+		}
+	}
+	if ghBackend, ok := backend.(*ghbackend.Backend); ok {
+		if owner, repoName := ownerRepoFromRemote(workDir); owner != "" {
+			ghBackend.SetRepository(owner, repoName)
+		}
+	}
+	return backend, providerName, nil
+}
+
 // repoNameFromRemote extracts the repository name from the git remote URL.
 func repoNameFromRemote(workDir string) string {
 	cmd := exec.Command("git", "remote", "get-url", "origin")
@@ -791,3 +1183,47 @@ func repoNameFromRemote(workDir string) string {
 	}
 	return ""
 }
+
+// ownerRepoFromRemote extracts the GitHub owner and repository name from
+// the origin remote URL (https://github.com/owner/repo.git or
+// git@github.com:owner/repo.git).
+func ownerRepoFromRemote(workDir string) (string, string) {
+	cmd := exec.Command("git", "remote", "get-url", "origin")
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", ""
+	}
+	remote := strings.TrimSuffix(strings.TrimSpace(string(out)), ".git")
+	parts := strings.FieldsFunc(remote, func(r rune) bool { return r == '/' || r == ':' })
+	if len(parts) < 2 {
+		return "", ""
+	}
+	return parts[len(parts)-2], parts[len(parts)-1]
+}
+
+// commitMessages returns the full messages of the commits on the current
+// branch that are not on origin/<targetBranch>.
+func commitMessages(ctx context.Context, workDir, targetBranch string) string {
+	cmd := exec.CommandContext(ctx, "git", "log", fmt.Sprintf("origin/%s..HEAD", targetBranch), "--format=%B")
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		slog.Warn("failed to read commit messages", "error", err)
+		return ""
+	}
+	return string(out)
+}
+
+// progressPrinter returns a progress callback that prints each step of an
+// in-flight LLM prompt to w, so long fixes don't look hung.
+func progressPrinter(w io.Writer) llm.ProgressFunc {
+	return func(p llm.Progress) {
+		switch p.Kind {
+		case llm.ProgressIntent:
+			fmt.Fprintf(w, "  … %s\n", p.Text)
+		default:
+			fmt.Fprintf(w, "  → %s\n", p.Text)
+		}
+	}
+}
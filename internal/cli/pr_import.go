@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var prImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Track all of your open PRs at once",
+	Long: `Find open pull requests on a provider and add every one that is not
+already tracked.
+
+By default otto imports the open PRs you authored. --branch-prefix
+narrows them to source branches under a prefix; given without --author,
+it imports PRs from any author on matching branches. On GitHub a search
+without an author needs --repo.`,
+	Example: `  otto pr import
+  otto pr import --branch-prefix users/alanmeadows/
+  otto pr import --provider github --repo org/repo --author octocat
+  otto pr import --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		w := cmd.OutOrStdout()
+		providerName, _ := cmd.Flags().GetString("provider")
+		author, _ := cmd.Flags().GetString("author")
+		branchPrefix, _ := cmd.Flags().GetString("branch-prefix")
+		repoName, _ := cmd.Flags().GetString("repo")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if providerName == "" {
+			providerName = appConfig.PR.DefaultProvider
+		}
+		if providerName == "" {
+			providerName = "ado"
+		}
+		if author == "" && branchPrefix == "" {
+			author = "@me"
+		}
+
+		backend, err := buildRegistry().Get(providerName)
+		if err != nil {
+			return fmt.Errorf("getting provider %q: %w", providerName, err)
+		}
+		prs, err := backend.ListPRs(ctx, provider.PRFilter{Author: author, BranchPrefix: branchPrefix, Repo: repoName})
+		if err != nil {
+			return fmt.Errorf("listing PRs: %w", err)
+		}
+
+		imported := 0
+		for _, info := range prs {
+			if _, err := server.LoadPR(providerName, info.ID); err == nil {
+				fmt.Fprintf(w, "  - PR #%s already tracked - %s\n", info.ID, info.Title)
+				continue
+			}
+			if dryRun {
+				fmt.Fprintf(w, "  + PR #%s would be imported - %s\n", info.ID, info.Title)
+				continue
+			}
+			if err := server.SavePR(server.NewTrackedPR(info, providerName, appConfig)); err != nil {
+				return fmt.Errorf("saving PR #%s: %w", info.ID, err)
+			}
+			fmt.Fprintf(w, "  ✓ Imported PR #%s - %s\n", info.ID, info.Title)
+			imported++
+		}
+
+		switch {
+		case len(prs) == 0:
+			fmt.Fprintln(w, "No matching open PRs found.")
+		case dryRun:
+			fmt.Fprintln(w, "Dry run: nothing was imported.")
+		default:
+			fmt.Fprintf(w, "Imported %d of %d open PRs.\n", imported, len(prs))
+			if imported > 0 {
+				notifyDaemon(w)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	prImportCmd.Flags().String("provider", "", "Provider to search (default: pr.default_provider, else ado)")
+	prImportCmd.Flags().String("author", "", `PR author; "@me" for yourself (default when --branch-prefix is not set)`)
+	prImportCmd.Flags().String("branch-prefix", "", "Only import PRs whose source branch starts with this prefix")
+	prImportCmd.Flags().String("repo", "", `Limit to one repository ("owner/repo" on GitHub, the repo name on ADO)`)
+	prImportCmd.Flags().Bool("dry-run", false, "List the PRs that would be imported without tracking them")
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	prLogKindFlag  []string
+	prLogSinceFlag string
+	prLogJSONFlag  bool
+	prLogStatsFlag bool
+)
+
+func init() {
+	prLogCmd.Flags().StringSliceVar(&prLogKindFlag, "kind", nil, "Only show events of these kinds (comma-separated or repeated)")
+	prLogCmd.Flags().StringVar(&prLogSinceFlag, "since", "", "Only show events newer than this duration (e.g. 24h, 7d)")
+	prLogCmd.Flags().BoolVar(&prLogJSONFlag, "json", false, "Output raw JSONL events")
+	prLogCmd.Flags().BoolVar(&prLogStatsFlag, "stats", false, "Summarize the events instead of listing them")
+	prLogCmd.RegisterFlagCompletionFunc("kind", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) { //nolint:errcheck
+		kinds := make([]string, len(server.PREventKinds))
+		for i, k := range server.PREventKinds {
+			kinds[i] = string(k)
+		}
+		return kinds, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+var prLogCmd = &cobra.Command{
+	Use:   "log [id]",
+	Short: "Show PR activity log",
+	Long: `Display the activity log for a tracked pull request.
+
+Otto records what happens to a PR — status changes, fix attempts,
+infra retries, review comments handled, policy holds and approvals — as
+events in a history file beside the PR document. The log shows the PR
+description followed by those events. --kind and --since filter the
+events (the description is then left out), --json prints them as JSONL
+for scripts, and --stats counts them.
+
+Kinds: status, fix_attempt, infra_retry, budget_exhausted, comment,
+policy_hold, policy_approved, fix_requested.
+
+If no ID is given, infers from the current branch.`,
+	Example: `  otto pr log
+  otto pr log 42
+  otto pr log 42 --kind fix_attempt,infra_retry --since 7d
+  otto pr log 42 --stats
+  otto pr log 42 --json | jq .`,
+	ValidArgsFunction: completePRIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, k := range prLogKindFlag {
+			if !slices.Contains(server.PREventKinds, server.PREventKind(k)) {
+				return fmt.Errorf("unknown event kind %q", k)
+			}
+		}
+		var since time.Time
+		if prLogSinceFlag != "" {
+			d, err := parseSinceDuration(prLogSinceFlag)
+			if err != nil {
+				return err
+			}
+			since = time.Now().Add(-d)
+		}
+
+		pr, err := resolvePR(args)
+		if err != nil {
+			return err
+		}
+		all, err := server.ReadPREvents(pr.Provider, pr.ID)
+		if err != nil {
+			return fmt.Errorf("reading PR history: %w", err)
+		}
+		events := all[:0:0]
+		for _, e := range all {
+			if e.Time.Before(since) {
+				continue
+			}
+			if len(prLogKindFlag) > 0 && !slices.Contains(prLogKindFlag, string(e.Kind)) {
+				continue
+			}
+			events = append(events, e)
+		}
+
+		w := cmd.OutOrStdout()
+		switch {
+		case prLogJSONFlag:
+			enc := json.NewEncoder(w)
+			for _, e := range events {
+				if err := enc.Encode(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		case prLogStatsFlag:
+			writePRLogStats(w, events)
+			return nil
+		}
+
+		filtered := len(prLogKindFlag) > 0 || !since.IsZero()
+		if filtered {
+			if len(events) == 0 {
+				fmt.Fprintln(w, "No matching events.")
+				return nil
+			}
+			// The events alone, without the description.
+			fmt.Fprint(w, server.RenderPRLog(&server.PRDocument{ID: pr.ID}, events))
+			return nil
+		}
+		if pr.Body == "" && len(events) == 0 {
+			fmt.Fprintln(w, "No activity log for this PR.")
+			return nil
+		}
+		fmt.Fprint(w, server.RenderPRLog(pr, events))
+		return nil
+	},
+}
+
+// writePRLogStats summarizes events: counts by kind, fix attempts by
+// failure category and outcome, and comment decisions.
+func writePRLogStats(w io.Writer, events []server.PREvent) {
+	if len(events) == 0 {
+		fmt.Fprintln(w, "No events.")
+		return
+	}
+	kinds := make(map[server.PREventKind]int)
+	categories := make(map[string]int)
+	decisions := make(map[string]int)
+	committed := 0
+	for _, e := range events {
+		kinds[e.Kind]++
+		switch e.Kind {
+		case server.PREventFixAttempt:
+			categories[e.Category]++
+			if e.Commit != "" {
+				committed++
+			}
+		case server.PREventComment:
+			decisions[e.Decision]++
+		}
+	}
+
+	fmt.Fprintf(w, "%d events, %s to %s\n", len(events),
+		events[0].Time.Local().Format("2006-01-02 15:04"), events[len(events)-1].Time.Local().Format("2006-01-02 15:04"))
+	for _, k := range server.PREventKinds {
+		if n := kinds[k]; n > 0 {
+			fmt.Fprintf(w, "  %-17s %d\n", k, n)
+		}
+	}
+	if n := kinds[server.PREventFixAttempt]; n > 0 {
+		fmt.Fprintf(w, "\nFix attempts: %d committed, %d blocked by checks\n", committed, n-committed)
+		writeCounts(w, categories)
+	}
+	if kinds[server.PREventComment] > 0 {
+		fmt.Fprintln(w, "\nComment decisions:")
+		writeCounts(w, decisions)
+	}
+}
+
+// writeCounts lists counts, largest first.
+func writeCounts(w io.Writer, counts map[string]int) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		name := k
+		if strings.TrimSpace(name) == "" {
+			name = "(none)"
+		}
+		fmt.Fprintf(w, "  %-17s %d\n", name, counts[k])
+	}
+}
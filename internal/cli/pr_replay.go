@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var prReplayCmd = &cobra.Command{
+	Use:   "replay [cassette]",
+	Short: "Rerun a recorded poll cycle offline",
+	Long: `Rerun a daemon poll cycle from its recording, to see exactly what the
+daemon saw and did.
+
+With server.record_cycles set, the daemon records the provider API
+traffic of its last N poll cycles, along with the tracked PR documents
+as they stood when each cycle began, under
+` + dataPath("cassettes") + `.
+Without an argument, replay lists the recordings, newest first; given
+one (a path or a file name from the list), it reruns that cycle with
+every provider request answered from the recording.
+
+The replay works on a scratch copy of the recorded PR documents, with no
+repositories, notifications, Jira, or LLM. Nothing is pushed or posted,
+and your tracked PRs are untouched. Steps that need a worktree or a
+model (fixes, conflict resolution, comment replies) stop there; the log
+shows how far they got.`,
+	Example: `  otto pr replay
+  otto pr replay 20261016T091500Z.jsonl -v`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeCassettes,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		w := cmd.OutOrStdout()
+		if len(args) == 0 {
+			paths, err := server.ListCassettes()
+			if err != nil {
+				return err
+			}
+			if len(paths) == 0 {
+				fmt.Fprintln(w, "No recorded poll cycles. Set server.record_cycles and restart the daemon to record them.")
+				return nil
+			}
+			for _, p := range paths {
+				fmt.Fprintln(w, filepath.Base(p))
+			}
+			return nil
+		}
+
+		path := args[0]
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			path = filepath.Join(server.CassetteDir(), args[0])
+		}
+		result, err := server.ReplayCycle(cmd.Context(), appConfig, path)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "\nReplayed cycle recorded %s: %d of %d recorded requests answered\n",
+			result.RecordedAt.Local().Format("2006-01-02 15:04:05"), result.Replayed, result.Recorded)
+		for _, pr := range result.PRs {
+			fmt.Fprintf(w, "  PR #%s %-10s %s\n", pr.ID, pr.Status, pr.Title)
+		}
+		return nil
+	},
+}
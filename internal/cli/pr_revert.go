@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/prompts"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/alanmeadows/otto/internal/provider/ado"
+	ghbackend "github.com/alanmeadows/otto/internal/provider/github"
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var prRevertCmd = &cobra.Command{
+	Use:   "revert <id>",
+	Short: "Open a PR reverting a merged PR",
+	Long: `Revert a merged PR: otto pushes a revert/pr-<id> branch off the latest
+target branch with the revert commit, opens a PR for it with an
+LLM-written description linking the original and saying why (--reason),
+and registers the new PR for monitoring.
+
+The PR may be tracked or already reaped; merged PRs stay available for
+180 days. Otto reverts the commit the PR merged as, against its first
+parent for a merge commit. For a rebase-merged PR, list each of its
+commits with --commit. A revert that conflicts with later changes is
+aborted and nothing is pushed.`,
+	Example: `  otto pr revert 42 --reason "breaks login on Safari"
+  otto pr revert 42 --commit 1a2b3c4 --commit 5d6e7f8`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePRIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		w := cmd.OutOrStdout()
+		reason, _ := cmd.Flags().GetString("reason")
+		commits, _ := cmd.Flags().GetStringSlice("commit")
+		noMonitor, _ := cmd.Flags().GetBool("no-monitor")
+
+		pr, err := server.FindMergedPR(args[0])
+		if err != nil {
+			return err
+		}
+		backend, err := buildRegistry().Get(pr.Provider)
+		if err != nil {
+			return fmt.Errorf("getting provider %q: %w", pr.Provider, err)
+		}
+		original, err := backend.GetPR(ctx, pr.URL)
+		if err != nil {
+			return fmt.Errorf("fetching PR: %w", err)
+		}
+		if original.Status != "completed" {
+			return fmt.Errorf("PR #%s is %s on %s, not merged", pr.ID, original.Status, pr.Provider)
+		}
+		if len(commits) == 0 {
+			if original.MergeCommit == "" {
+				return fmt.Errorf("%s doesn't report PR #%s's merge commit; give it with --commit", pr.Provider, pr.ID)
+			}
+			commits = []string{original.MergeCommit}
+		}
+
+		fmt.Fprintf(w, "Reverting PR #%s: %s\n", pr.ID, pr.Title)
+		rev, err := server.PrepareRevert(ctx, pr, appConfig, commits)
+		if err != nil {
+			return fmt.Errorf("reverting PR #%s: %w", pr.ID, err)
+		}
+		fmt.Fprintf(w, "  ✓ Pushed %s → %s\n", rev.Branch, rev.Target)
+
+		title := fmt.Sprintf("Revert %q", pr.Title)
+		if tc := appConfig.PR.TitleConvention; tc.Enabled {
+			title = tc.Prefix(pr.Title, rev.Branch)
+		}
+		description, err := generateRevertDescription(ctx, pr, rev, reason)
+		if err != nil {
+			slog.Warn("failed to generate revert description, using fallback", "error", err)
+			description = revertDescription(pr, rev, reason)
+		}
+
+		// Open the revert in the original's repository.
+		switch b := backend.(type) {
+		case *ado.Backend:
+			b.SetRepository(original.RepoID)
+		case *ghbackend.Backend:
+			b.SetRepository(original.Organization, original.RepoID)
+		}
+		fmt.Fprintf(w, "Creating PR...\n")
+		prInfo, err := backend.CreatePR(ctx, provider.CreatePRParams{
+			Title:        title,
+			Description:  description,
+			SourceBranch: rev.Branch,
+			TargetBranch: rev.Target,
+			WorkItems:    pr.WorkItems,
+		})
+		if err != nil {
+			return fmt.Errorf("creating PR: %w", err)
+		}
+		fmt.Fprintf(w, "  ✓ Created PR #%s: %s\n", prInfo.ID, prInfo.URL)
+
+		if !noMonitor {
+			registerPRForMonitoring(ctx, w, backend, prInfo, pr.Provider, nil)
+		}
+		return nil
+	},
+}
+
+func init() {
+	prRevertCmd.Flags().String("reason", "", "Why the PR is reverted, for the description")
+	prRevertCmd.Flags().StringSlice("commit", nil, "Commit to revert instead of the merge commit (repeatable)")
+	prRevertCmd.Flags().Bool("no-monitor", false, "Skip registering the revert PR for monitoring")
+}
+
+// generateRevertDescription uses the LLM to explain the revert of pr.
+func generateRevertDescription(ctx context.Context, pr *server.PRDocument, rev *server.Revert, reason string) (string, error) {
+	if reason == "" {
+		reason = "(none given)"
+	}
+	prompt, err := prompts.Execute("pr-revert.md", map[string]string{
+		"Title":       pr.Title,
+		"URL":         pr.URL,
+		"Description": pr.Description(),
+		"Reason":      reason,
+		"Commits":     strings.Join(rev.Commits, "\n"),
+		"DiffStat":    rev.DiffStat,
+	})
+	if err != nil {
+		return "", fmt.Errorf("building revert description prompt: %w", err)
+	}
+
+	llmClient, err := llm.NewFallbackClient(appConfig.Models, "")
+	if err != nil {
+		return "", err
+	}
+	if err := llmClient.Start(ctx); err != nil {
+		return "", fmt.Errorf("starting LLM client: %w", err)
+	}
+	defer llmClient.Stop()
+
+	workDir, _ := os.Getwd()
+	session, err := llmClient.CreateSession(ctx, "PR Revert", workDir)
+	if err != nil {
+		return "", fmt.Errorf("creating session: %w", err)
+	}
+	defer llmClient.DeleteSession(ctx, session.ID)
+
+	resp, err := llmClient.SendPrompt(ctx, session.ID, prompt)
+	if err != nil {
+		return "", fmt.Errorf("LLM prompt failed: %w", err)
+	}
+	description := strings.TrimSpace(resp.Content)
+	if description == "" {
+		return "", fmt.Errorf("LLM returned an empty description")
+	}
+	// The link to the original must survive whatever the LLM wrote.
+	if !strings.Contains(description, pr.URL) {
+		description = fmt.Sprintf("Reverts %s\n\n%s", pr.URL, description)
+	}
+	return description, nil
+}
+
+// revertDescription is the description of the revert of pr when the LLM
+// can't write one.
+func revertDescription(pr *server.PRDocument, rev *server.Revert, reason string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Summary\n\nReverts [#%s: %s](%s).\n", pr.ID, pr.Title, pr.URL)
+	if reason != "" {
+		fmt.Fprintf(&b, "\n**Reason:** %s\n", reason)
+	}
+	b.WriteString("\n## Reverted commits\n\n")
+	for _, c := range rev.Commits {
+		fmt.Fprintf(&b, "- %s\n", c)
+	}
+	return b.String()
+}
@@ -10,10 +10,12 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/alanmeadows/otto/internal/audit"
 	"github.com/alanmeadows/otto/internal/llm"
 	"github.com/alanmeadows/otto/internal/prompts"
 	"github.com/alanmeadows/otto/internal/provider"
 	"github.com/alanmeadows/otto/internal/repo"
+	"github.com/alanmeadows/otto/internal/server"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
@@ -21,12 +23,7 @@ import (
 )
 
 // reviewComment represents a single review comment parsed from LLM output.
-type reviewComment struct {
-	File     string `json:"file"`
-	Line     int    `json:"line"`
-	Severity string `json:"severity"`
-	Body     string `json:"body"`
-}
+type reviewComment = server.ReviewFinding
 
 var prReviewCmd = &cobra.Command{
 	Use:   "review <url> [guidance]",
@@ -41,10 +38,16 @@ automatically.
 
 An optional guidance argument can be provided to steer the review
 focus, e.g. "focus on error handling" or "check for race conditions
-in the worker pool".`,
+in the worker pool".
+
+With --as-reviewer, otto reviews someone else's PR the way the daemon's
+reviewer mode does: findings at or above pr.reviewer.min_severity are
+posted without prompting, and the review is submitted as an approval,
+a change request, or a comment according to pr.reviewer.`,
 	Example: `  otto pr review https://github.com/org/repo/pull/42
   otto pr review https://dev.azure.com/org/project/_git/repo/pullrequest/123
-  otto pr review https://github.com/org/repo/pull/42 "focus on error handling and concurrency safety"`,
+  otto pr review https://github.com/org/repo/pull/42 "focus on error handling and concurrency safety"
+  otto pr review https://github.com/org/repo/pull/42 --as-reviewer`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
@@ -53,6 +56,10 @@ in the worker pool".`,
 		if len(args) > 1 {
 			guidance = args[1]
 		}
+		asReviewer, _ := cmd.Flags().GetBool("as-reviewer")
+		if asReviewer && guidance == "" {
+			guidance = appConfig.PR.Reviewer.Guidance
+		}
 
 		// Step 1: Detect backend from URL.
 		reg := buildRegistry()
@@ -90,9 +97,12 @@ in the worker pool".`,
 		}
 
 		// Step 4: Create LLM client.
-		llmClient := llm.NewCopilotClient(appConfig.Models.Primary)
+		llmClient, err := llm.NewFallbackClient(appConfig.Models, "")
+		if err != nil {
+			return err
+		}
 		if err := llmClient.Start(ctx); err != nil {
-			return fmt.Errorf("starting Copilot LLM client: %w", err)
+			return fmt.Errorf("starting LLM client: %w", err)
 		}
 		defer llmClient.Stop()
 
@@ -138,6 +148,18 @@ in the worker pool".`,
 			return fmt.Errorf("parsing review response: %w", err)
 		}
 
+		if asReviewer {
+			posted, verdict, err := server.SubmitReviewerReview(ctx, audit.WrapBackend(backend), prInfo, comments, appConfig.PR.Reviewer, appConfig.PR.DisableAIFooter)
+			if err != nil {
+				return err
+			}
+			if err := server.MarkReviewed(backend.Name(), prInfo.ID); err != nil {
+				slog.Warn("failed to record review", "error", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Posted %d of %d review comments to PR #%s and submitted a %s review\n", posted, len(comments), prInfo.ID, verdict)
+			return nil
+		}
+
 		if len(comments) == 0 {
 			fmt.Fprintln(cmd.OutOrStdout(), "No issues found. The PR looks clean.")
 			return nil
@@ -206,7 +228,7 @@ in the worker pool".`,
 
 		// Step 10: Post approved comments via backend.PostInlineComment().
 		fmt.Fprintf(cmd.OutOrStdout(), "Posting %d comments...\n", len(selected))
-		posted, err := postReviewComments(ctx, cmd.OutOrStdout(), backend, prInfo, comments, selected, appConfig.PR.DisableAIFooter)
+		posted, err := postReviewComments(ctx, cmd.OutOrStdout(), audit.WrapBackend(backend), prInfo, comments, selected, appConfig.PR.DisableAIFooter)
 		if err != nil {
 			return fmt.Errorf("posting comments: %w", err)
 		}
@@ -216,6 +238,10 @@ in the worker pool".`,
 	},
 }
 
+func init() {
+	prReviewCmd.Flags().Bool("as-reviewer", false, "Post findings and submit a verdict per pr.reviewer, without prompting")
+}
+
 // postReviewComments posts the selected inline comments to the PR.
 func postReviewComments(ctx context.Context, w io.Writer, backend provider.PRBackend, prInfo *provider.PRInfo, comments []reviewComment, selected []int, disableFooter bool) (int, error) {
 	posted := 0
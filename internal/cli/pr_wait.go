@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/spf13/cobra"
+)
+
+// prWaitInterval is how often --wait rereads the PR's status.
+var prWaitInterval = 10 * time.Second
+
+var (
+	prStatusWaitFlag    bool
+	prStatusUntilFlag   string
+	prStatusTimeoutFlag time.Duration
+)
+
+func init() {
+	prStatusCmd.Flags().BoolVar(&prStatusWaitFlag, "wait", false, "Block until the PR reaches the --until status, fails, or is abandoned")
+	prStatusCmd.Flags().StringVar(&prStatusUntilFlag, "until", "green", "Status to wait for with --wait: green or merged")
+	prStatusCmd.Flags().DurationVar(&prStatusTimeoutFlag, "timeout", 0, "Give up waiting after this long (0 = no limit)")
+	prStatusCmd.RegisterFlagCompletionFunc("until", cobra.FixedCompletions([]string{"green", "merged"}, cobra.ShellCompDirectiveNoFileComp)) //nolint:errcheck
+}
+
+// checkWaitFlags rejects --until and --timeout without --wait, and unknown
+// --until values.
+func checkWaitFlags(cmd *cobra.Command) error {
+	if !prStatusWaitFlag {
+		for _, name := range []string{"until", "timeout"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s needs --wait", name)
+			}
+		}
+		return nil
+	}
+	if prStatusUntilFlag != "green" && prStatusUntilFlag != "merged" {
+		return fmt.Errorf("invalid --until %q: want green or merged", prStatusUntilFlag)
+	}
+	return nil
+}
+
+// waitForPR rereads pr's document, which the daemon keeps current, until
+// the PR reaches until ("green" or "merged"), fails, is abandoned, or
+// timeout (if nonzero) passes. It returns the PR as last read and, unless
+// the wait succeeded, an ExitError saying why it stopped. Progress goes to
+// progress, which may be io.Discard.
+func waitForPR(ctx context.Context, pr *server.PRDocument, until string, timeout time.Duration, progress io.Writer) (*server.PRDocument, error) {
+	if running, _, _, err := server.DaemonStatus(); err == nil && !running {
+		fmt.Fprintln(progress, "warning: the otto daemon is not running; the PR's status won't change until it is")
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	fmt.Fprintf(progress, "waiting for PR %s to be %s (now %s)\n", pr.ID, until, pr.Status)
+	ticker := time.NewTicker(prWaitInterval)
+	defer ticker.Stop()
+	last := pr.Status
+	for {
+		if pr.Status != last {
+			fmt.Fprintf(progress, "PR %s: %s → %s\n", pr.ID, last, pr.Status)
+			last = pr.Status
+		}
+		switch {
+		case pr.Status == until, until == "green" && pr.Status == "merged":
+			return pr, nil
+		case pr.Status == "failed":
+			return pr, &ExitError{Code: ExitPRFailed, Err: fmt.Errorf("PR %s failed", pr.ID)}
+		case pr.Status == "abandoned":
+			return pr, &ExitError{Code: ExitPRAbandoned, Err: fmt.Errorf("PR %s was abandoned", pr.ID)}
+		}
+
+		select {
+		case <-ctx.Done():
+			return pr, &ExitError{Code: ExitTimeout, Err: fmt.Errorf("timed out after %s waiting for PR %s to be %s (still %s)", timeout, pr.ID, until, pr.Status)}
+		case <-ticker.C:
+		}
+		next, err := server.LoadPR(pr.Provider, pr.ID)
+		if err != nil {
+			return pr, fmt.Errorf("rereading PR %s: %w", pr.ID, err)
+		}
+		pr = next
+	}
+}
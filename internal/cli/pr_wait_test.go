@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForPR(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	interval := prWaitInterval
+	prWaitInterval = 10 * time.Millisecond
+	t.Cleanup(func() { prWaitInterval = interval })
+
+	tests := []struct {
+		name   string
+		status string // as the daemon saved it
+		until  string
+		code   int
+	}{
+		{"green", "green", "green", ExitOK},
+		{"merged satisfies green", "merged", "green", ExitOK},
+		{"merged", "merged", "merged", ExitOK},
+		{"green is not merged", "green", "merged", ExitTimeout},
+		{"failed", "failed", "green", ExitPRFailed},
+		{"abandoned", "abandoned", "merged", ExitPRAbandoned},
+		{"still watching", "watching", "green", ExitTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, server.SavePR(&server.PRDocument{ID: "42", Provider: "ado", Status: tt.status}))
+
+			// The wait starts from a stale copy and picks up the saved
+			// status on its next read.
+			pr := &server.PRDocument{ID: "42", Provider: "ado", Status: "watching"}
+			got, err := waitForPR(context.Background(), pr, tt.until, 200*time.Millisecond, io.Discard)
+			assert.Equal(t, tt.code, ExitCode(err))
+			assert.Equal(t, tt.status, got.Status)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/prompts"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/alanmeadows/otto/internal/repo"
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	releaseFromFlag     string
+	releaseToFlag       string
+	releaseTagFlag      string
+	releaseOutputFlag   string
+	releasePublishFlag  bool
+	releaseWikiFlag     string
+	releaseWikiPathFlag string
+)
+
+func init() {
+	releaseNotesCmd.Flags().StringVar(&releaseFromFlag, "from", "", "Previous release's tag or ref; PRs merged after it are included (required)")
+	releaseNotesCmd.Flags().StringVar(&releaseToFlag, "to", "", "Tag or ref ending the release (default: now)")
+	releaseNotesCmd.Flags().StringVar(&releaseTagFlag, "tag", "", "Tag of the new release (default: --to)")
+	releaseNotesCmd.Flags().StringVarP(&releaseOutputFlag, "output", "o", "", "Write the notes to this markdown file instead of stdout")
+	releaseNotesCmd.Flags().BoolVar(&releasePublishFlag, "publish", false, "Publish the notes as a GitHub release or an ADO wiki page")
+	releaseNotesCmd.Flags().StringVar(&releaseWikiFlag, "wiki", "", "ADO wiki to publish to (default: the project wiki)")
+	releaseNotesCmd.Flags().StringVar(&releaseWikiPathFlag, "wiki-path", "", `ADO wiki page to publish to (default: "/Release Notes/<tag>")`)
+	releaseNotesCmd.MarkFlagRequired("from") //nolint:errcheck
+
+	releaseCmd.AddCommand(releaseNotesCmd)
+}
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Release tooling",
+}
+
+var releaseNotesCmd = &cobra.Command{
+	Use:   "notes",
+	Short: "Generate release notes from merged PRs",
+	Long: `Generate release notes for the current repository from the otto-tracked
+PRs merged since --from: their titles, descriptions, and linked work items
+(ADO) or issues (GitHub), grouped by the LLM into features, fixes, and
+other changes.
+
+The release runs from the commit date of --from to that of --to, or to now.
+PRs otto stopped tracking stay available for 180 days after they merged.
+The notes are printed as markdown, written to --output, or with --publish
+published as the GitHub release for --tag or as an ADO wiki page.`,
+	Example: `  otto release notes --from v1.2.0
+  otto release notes --from v1.2.0 --to v1.3.0 -o RELEASE_NOTES.md
+  otto release notes --from v1.2.0 --tag v1.3.0 --publish`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		w := cmd.OutOrStdout()
+		progress := cmd.ErrOrStderr()
+
+		tag := releaseTagFlag
+		if tag == "" {
+			tag = releaseToFlag
+		}
+		if releasePublishFlag && tag == "" {
+			return fmt.Errorf("--publish needs the release's --tag (or --to)")
+		}
+
+		repoCfg, err := repo.NewManager("").FindByCWD(appConfig)
+		if err != nil {
+			return fmt.Errorf("detecting repository: %w\nRegister this repo with: otto repo add", err)
+		}
+		workDir := repoCfg.PrimaryDir
+
+		since, err := refTime(ctx, workDir, releaseFromFlag)
+		if err != nil {
+			return err
+		}
+		until := time.Now()
+		if releaseToFlag != "" {
+			if until, err = refTime(ctx, workDir, releaseToFlag); err != nil {
+				return err
+			}
+		}
+
+		backend, providerName, err := repoBackend(workDir)
+		if err != nil {
+			return err
+		}
+		prs, err := server.MergedPRs(providerName, repoNameFromRemote(workDir), since, until)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(progress, "Found %d merged PRs since %s\n", len(prs), releaseFromFlag)
+
+		notes := server.PlainReleaseNotes(prs)
+		if len(prs) > 0 {
+			release := "changes since " + releaseFromFlag
+			if tag != "" {
+				release = tag + ": " + release
+			}
+			generated, err := generateReleaseNotes(ctx, workDir, release, prs)
+			if err != nil {
+				slog.Warn("failed to generate release notes, listing PRs instead", "error", err)
+			} else {
+				notes = generated
+			}
+		}
+
+		if releaseOutputFlag != "" {
+			if err := os.WriteFile(releaseOutputFlag, []byte(notes), 0644); err != nil {
+				return fmt.Errorf("writing release notes: %w", err)
+			}
+			fmt.Fprintf(progress, "  ✓ Wrote %s\n", releaseOutputFlag)
+		} else {
+			fmt.Fprint(w, notes)
+		}
+
+		if releasePublishFlag {
+			publisher, ok := backend.(provider.ReleasePublisher)
+			if !ok {
+				return fmt.Errorf("provider %q can't publish release notes", providerName)
+			}
+			url, err := publisher.PublishReleaseNotes(ctx, provider.ReleaseNotes{
+				Tag:      tag,
+				Body:     notes,
+				Wiki:     releaseWikiFlag,
+				WikiPath: releaseWikiPathFlag,
+			})
+			if err != nil {
+				return fmt.Errorf("publishing release notes: %w", err)
+			}
+			fmt.Fprintf(progress, "  ✓ Published %s\n", url)
+		}
+		return nil
+	},
+}
+
+// refTime returns the commit time of ref, a tag or other git ref.
+func refTime(ctx context.Context, workDir, ref string) (time.Time, error) {
+	cmd := exec.CommandContext(ctx, "git", "log", "-1", "--format=%cI", ref, "--")
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("resolving %q: %w", ref, err)
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(out)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("resolving %q: %w", ref, err)
+	}
+	return t, nil
+}
+
+// generateReleaseNotes uses the LLM to write grouped release notes for prs.
+func generateReleaseNotes(ctx context.Context, workDir, release string, prs []server.ReleasePR) (string, error) {
+	prompt, err := prompts.Execute("release-notes.md", map[string]string{
+		"Release": release,
+		"PRs":     server.ReleaseNotesInput(prs),
+	})
+	if err != nil {
+		return "", fmt.Errorf("building release notes prompt: %w", err)
+	}
+
+	llmClient, err := llm.NewFallbackClient(appConfig.Models, "")
+	if err != nil {
+		return "", err
+	}
+	if err := llmClient.Start(ctx); err != nil {
+		return "", fmt.Errorf("starting LLM client: %w", err)
+	}
+	defer llmClient.Stop()
+
+	session, err := llmClient.CreateSession(ctx, "Release Notes", workDir)
+	if err != nil {
+		return "", fmt.Errorf("creating session: %w", err)
+	}
+	defer llmClient.DeleteSession(ctx, session.ID)
+
+	resp, err := llmClient.SendPrompt(ctx, session.ID, prompt)
+	if err != nil {
+		return "", fmt.Errorf("LLM prompt failed: %w", err)
+	}
+	notes := strings.TrimSpace(resp.Content)
+	if notes == "" {
+		return "", fmt.Errorf("LLM returned empty release notes")
+	}
+	return notes + "\n", nil
+}
@@ -27,6 +27,7 @@ the current set.`,
 }
 
 func init() {
+	repoAddCmd.Flags().Bool("interactive", false, "Guided setup: detect the provider, check credentials, discover checks, and validate with a dry-run poll")
 	repoCmd.AddCommand(repoAddCmd)
 	repoCmd.AddCommand(repoRemoveCmd)
 	repoCmd.AddCommand(repoListCmd)
@@ -40,10 +41,25 @@ var repoAddCmd = &cobra.Command{
 Launches an interactive form to configure the repository name,
 primary directory, worktree directory, git strategy (worktree,
 branch, or hands-off), and branch naming template. If a name is
-provided as an argument it is pre-filled in the form.`,
+provided as an argument it is pre-filled in the form.
+
+With --interactive, a guided setup instead detects the provider from
+the origin remote and checks its credentials, asks for the git
+strategy, offers build and test commands for repos[].checks — found in
+the build files, or suggested by the LLM — writes the entry, and
+validates it with a dry-run poll: reaching origin, listing the repo's
+open PRs, and reading one's pipelines and comments.`,
 	Example: `  otto repo add
-  otto repo add my-service`,
+  otto repo add my-service
+  otto repo add --interactive`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			return runRepoWizard(cmd, name)
+		}
 		cwd, _ := os.Getwd()
 
 		var name, primaryDir, worktreeDir, branchTemplate string
@@ -124,8 +140,9 @@ var repoRemoveCmd = &cobra.Command{
 
 This only removes the repository from otto's tracking configuration.
 It does not delete the repository directory or any worktrees on disk.`,
-	Example: `  otto repo remove my-service`,
-	Args:    cobra.ExactArgs(1),
+	Example:           `  otto repo remove my-service`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRepoNames,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
 
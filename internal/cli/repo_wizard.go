@@ -0,0 +1,308 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/prompts"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/alanmeadows/otto/internal/repo"
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+)
+
+// runRepoWizard walks through adding a repository: it detects the remote's
+// provider and checks its credentials, asks for the git strategy, offers
+// discovered (or LLM-inferred) checks, writes the repos[] entry, and
+// validates it with a dry-run poll.
+func runRepoWizard(cmd *cobra.Command, name string) error {
+	ctx := cmd.Context()
+	w := cmd.OutOrStdout()
+
+	primaryDir, _ := os.Getwd()
+	err := huh.NewForm(huh.NewGroup(
+		huh.NewInput().
+			Title("Primary directory").
+			Description("The repository's main checkout").
+			Value(&primaryDir).
+			Validate(func(s string) error {
+				info, err := os.Stat(config.ExpandHome(s))
+				if err != nil || !info.IsDir() {
+					return fmt.Errorf("not a directory")
+				}
+				return nil
+			}),
+	)).Run()
+	if err != nil {
+		return fmt.Errorf("form cancelled: %w", err)
+	}
+	if primaryDir, err = filepath.Abs(config.ExpandHome(primaryDir)); err != nil {
+		return err
+	}
+
+	// Provider and credentials.
+	out, err := exec.CommandContext(ctx, "git", "-C", primaryDir, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return fmt.Errorf("%s has no origin remote; otto finds a repo's PRs by it", primaryDir)
+	}
+	remote := strings.TrimSpace(string(out))
+	fmt.Fprintf(w, "Remote: %s\n", remote)
+	backend, err := buildRegistry().Detect(remote)
+	if err != nil {
+		fmt.Fprintf(w, "  ⚠ No configured provider matches the remote; otto can't watch its PRs until one does (see otto config)\n")
+	} else {
+		fmt.Fprintf(w, "  ✓ Provider: %s\n", backend.Name())
+		verifyCredentials(ctx, w, backend)
+	}
+
+	// Name and git strategy.
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(remote), ".git")
+	}
+	gitStrategy := string(config.GitStrategyWorktree)
+	var worktreeDir string
+	branchTemplate := "otto/{{.Name}}"
+	err = huh.NewForm(huh.NewGroup(
+		huh.NewInput().
+			Title("Repository name").
+			Value(&name).
+			Validate(func(s string) error {
+				if s == "" {
+					return fmt.Errorf("name is required")
+				}
+				if findRepo(s) != nil {
+					return fmt.Errorf("repository %q already exists", s)
+				}
+				return nil
+			}),
+		huh.NewSelect[string]().
+			Title("Git strategy").
+			Description("How otto checks out branches to work on them").
+			Options(
+				huh.NewOption("Worktree (recommended)", "worktree"),
+				huh.NewOption("Branch", "branch"),
+				huh.NewOption("Hands-off (read only)", "hands-off"),
+			).
+			Value(&gitStrategy),
+		huh.NewInput().
+			Title("Worktree directory (leave empty for default)").
+			Value(&worktreeDir),
+		huh.NewInput().
+			Title("Branch template").
+			Value(&branchTemplate),
+	)).Run()
+	if err != nil {
+		return fmt.Errorf("form cancelled: %w", err)
+	}
+
+	// Checks.
+	checks := repo.DiscoverChecks(primaryDir)
+	if len(checks) > 0 {
+		fmt.Fprintf(w, "Found checks in the build files: %s\n", strings.Join(checks, ", "))
+	}
+	askLLM := len(checks) == 0
+	err = huh.NewConfirm().
+		Title("Ask the LLM to suggest build and test commands?").
+		Description("It reads the repo's README, build files, and CI workflows").
+		Value(&askLLM).
+		Run()
+	if err != nil {
+		return fmt.Errorf("form cancelled: %w", err)
+	}
+	if askLLM {
+		fmt.Fprintf(w, "Asking the LLM...\n")
+		inferred, err := inferChecks(ctx, name, primaryDir, checks)
+		if err != nil {
+			fmt.Fprintf(w, "  ⚠ %v\n", err)
+		} else {
+			checks = inferred
+		}
+	}
+	checksText := strings.Join(checks, "\n")
+	err = huh.NewForm(huh.NewGroup(
+		huh.NewText().
+			Title("Checks").
+			Description("Commands run in the worktree after each change otto makes, one per line").
+			Value(&checksText),
+	)).Run()
+	if err != nil {
+		return fmt.Errorf("form cancelled: %w", err)
+	}
+
+	rc := config.RepoConfig{
+		Name:           name,
+		PrimaryDir:     primaryDir,
+		WorktreeDir:    worktreeDir,
+		GitStrategy:    config.GitStrategy(gitStrategy),
+		BranchTemplate: branchTemplate,
+		Checks:         splitNonEmpty(checksText),
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return fmt.Errorf("getting config dir: %w", err)
+	}
+	if err := repo.NewManager(configDir).Add(appConfig, rc); err != nil {
+		return fmt.Errorf("adding repo: %w", err)
+	}
+	fmt.Fprintf(w, "Added repository %q (%s)\n", name, primaryDir)
+
+	if backend == nil {
+		return nil
+	}
+	fmt.Fprintf(w, "Dry-run poll...\n")
+	if problems := dryRunPoll(ctx, w, backend, findRepo(name), remote); problems > 0 {
+		fmt.Fprintf(w, "The dry-run poll found %d problem(s); fix them before relying on otto for %s.\n", problems, name)
+		return nil
+	}
+	fmt.Fprintf(w, "Ready: otto can watch %s's PRs.\n", name)
+	return nil
+}
+
+// verifyCredentials checks backend's credentials when it can, reporting
+// how to fix them when the provider rejects them.
+func verifyCredentials(ctx context.Context, w io.Writer, backend provider.PRBackend) {
+	checker, ok := backend.(provider.AuthChecker)
+	if !ok {
+		fmt.Fprintf(w, "  - %s can't check its credentials on its own; the dry-run poll below tests them\n", backend.Name())
+		return
+	}
+	expires, err := checker.CheckAuth(ctx)
+	switch {
+	case errors.Is(err, provider.ErrAuthFailed):
+		fmt.Fprintf(w, "  ✗ %s rejected the credentials; store new ones with otto auth set\n", backend.Name())
+	case err != nil:
+		fmt.Fprintf(w, "  ⚠ Could not check the credentials: %v\n", err)
+	case expires.IsZero():
+		fmt.Fprintf(w, "  ✓ Credentials valid\n")
+	default:
+		fmt.Fprintf(w, "  ✓ Credentials valid until %s\n", expires.Local().Format("2006-01-02"))
+	}
+}
+
+// inferChecks asks the LLM for the repo's checks, given those discovered
+// from its build files.
+func inferChecks(ctx context.Context, name, dir string, discovered []string) ([]string, error) {
+	summary := "(not analyzed)"
+	if s, err := repo.AnalyzeCodebase(dir); err == nil {
+		summary = s.String()
+	}
+	found := "(none)"
+	if len(discovered) > 0 {
+		found = strings.Join(discovered, "\n")
+	}
+	prompt, err := prompts.Execute("repo-checks.md", map[string]string{
+		"Repo":       name,
+		"Summary":    summary,
+		"Discovered": found,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("building checks prompt: %w", err)
+	}
+
+	llmClient, err := llm.NewFallbackClient(appConfig.Models, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := llmClient.Start(ctx); err != nil {
+		return nil, fmt.Errorf("starting LLM client: %w", err)
+	}
+	defer llmClient.Stop()
+
+	session, err := llmClient.CreateSession(ctx, "Repo checks: "+name, dir)
+	if err != nil {
+		return nil, fmt.Errorf("creating session: %w", err)
+	}
+	defer llmClient.DeleteSession(ctx, session.ID)
+
+	resp, err := llmClient.SendPrompt(ctx, session.ID, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("LLM prompt failed: %w", err)
+	}
+	result, err := llm.ParseJSONResponse[struct {
+		Checks []string `json:"checks"`
+	}](ctx, llmClient, session.ID, resp.Content)
+	if err != nil {
+		return nil, fmt.Errorf("parsing LLM checks: %w", err)
+	}
+	checks := splitNonEmpty(strings.Join(result.Checks, "\n"))
+	if len(checks) == 0 {
+		return nil, fmt.Errorf("the LLM suggested no checks")
+	}
+	return checks, nil
+}
+
+// dryRunPoll does what a monitoring poll reads for rc — git access,
+// mapping a PR's remote to the repo, listing its open PRs, and reading
+// one's pipelines and comments — without acting on anything, and
+// reports each step. It returns how many steps failed.
+func dryRunPoll(ctx context.Context, w io.Writer, backend provider.PRBackend, rc *config.RepoConfig, remote string) int {
+	problems := 0
+	step := func(err error, ok string) {
+		if err != nil {
+			problems++
+			fmt.Fprintf(w, "  ✗ %v\n", err)
+			return
+		}
+		fmt.Fprintf(w, "  ✓ %s\n", ok)
+	}
+
+	out, err := exec.CommandContext(ctx, "git", "-C", rc.PrimaryDir, "ls-remote", "--heads", "origin").CombinedOutput()
+	if err != nil {
+		err = fmt.Errorf("git can't reach origin: %s", strings.TrimSpace(string(out)))
+	}
+	step(err, "git can reach origin")
+
+	mapped, err := repo.NewManager("").FindByRemoteURL(appConfig, remote)
+	if err == nil && mapped.Name != rc.Name {
+		err = fmt.Errorf("PRs on %s map to repo %q, configured earlier", remote, mapped.Name)
+	}
+	step(err, fmt.Sprintf("PRs on %s map to %s", remote, rc.Name))
+
+	filter := provider.PRFilter{Repo: repoNameFromRemote(rc.PrimaryDir)}
+	if owner, name := ownerRepoFromRemote(rc.PrimaryDir); backend.Name() == "github" && owner != "" {
+		filter.Repo = owner + "/" + name
+	}
+	prs, err := backend.ListPRs(ctx, filter)
+	if err != nil {
+		step(fmt.Errorf("listing open PRs: %w", err), "")
+		return problems
+	}
+	step(nil, fmt.Sprintf("Listed %d open PRs", len(prs)))
+	if len(prs) == 0 {
+		return problems
+	}
+
+	pr := prs[0]
+	status, err := backend.GetPipelineStatus(ctx, pr)
+	if err != nil {
+		step(fmt.Errorf("reading PR #%s's pipelines: %w", pr.ID, err), "")
+	} else {
+		step(nil, fmt.Sprintf("Read PR #%s's pipelines (%s)", pr.ID, status.State))
+	}
+	comments, err := backend.GetComments(ctx, pr)
+	if err != nil {
+		step(fmt.Errorf("reading PR #%s's comments: %w", pr.ID, err), "")
+	} else {
+		step(nil, fmt.Sprintf("Read PR #%s's %d comments", pr.ID, len(comments)))
+	}
+	return problems
+}
+
+// splitNonEmpty returns the trimmed, non-empty lines of s.
+func splitNonEmpty(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
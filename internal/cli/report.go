@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportSinceFlag  string
+	reportFormatFlag string
+)
+
+func init() {
+	reportCmd.Flags().StringVar(&reportSinceFlag, "since", "30d", "Report on this much history (e.g. 7d, 30d, 72h)")
+	reportCmd.Flags().StringVar(&reportFormatFlag, "format", "markdown", "Output format: markdown or csv")
+	reportCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"markdown", "csv"}, cobra.ShellCompDirectiveNoFileComp)) //nolint:errcheck
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize otto's PR metrics over a period",
+	Long: `Aggregate the history of tracked PRs into team metrics: PRs opened,
+merged, and abandoned; mean time from a PR's creation to its first green
+pipeline; how often otto's fixes turned the pipeline green; the share of
+failures that were infrastructure rather than code; and review comments
+otto handled and resolved.
+
+Merged and abandoned PRs stay in reports for 180 days after otto stops
+tracking them. Metrics come from PR history, which otto records from
+this version on. Output is a markdown table, or CSV with --format csv.`,
+	Example: `  otto report
+  otto report --since 7d
+  otto report --since 90d --format csv > otto-q3.csv`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if reportFormatFlag != "markdown" && reportFormatFlag != "csv" {
+			return fmt.Errorf("invalid --format %q: want markdown or csv", reportFormatFlag)
+		}
+		d, err := parseSinceDuration(reportSinceFlag)
+		if err != nil {
+			return err
+		}
+		until := time.Now()
+		r, err := server.BuildReport(until.Add(-d), until)
+		if err != nil {
+			return err
+		}
+
+		rows := reportRows(r)
+		w := cmd.OutOrStdout()
+		if reportFormatFlag == "csv" {
+			return writeReportCSV(w, rows)
+		}
+		fmt.Fprintf(w, "## otto report: %s to %s\n\n", r.Since.Local().Format("2006-01-02"), r.Until.Local().Format("2006-01-02"))
+		fmt.Fprintln(w, "| Metric | Value |")
+		fmt.Fprintln(w, "|--------|-------|")
+		for _, row := range rows {
+			fmt.Fprintf(w, "| %s | %s |\n", row[0], row[1])
+		}
+		return nil
+	},
+}
+
+// reportRows lists the report's metrics as name/value pairs.
+func reportRows(r *server.Report) [][2]string {
+	percent := func(f float64) string {
+		if f < 0 {
+			return "n/a"
+		}
+		return fmt.Sprintf("%.0f%%", f*100)
+	}
+	meanToGreen := "n/a"
+	if r.PRsGreen > 0 {
+		meanToGreen = r.MeanTimeToGreen.Round(time.Minute).String()
+	}
+
+	rows := [][2]string{
+		{"PRs opened", strconv.Itoa(r.PRsOpened)},
+		{"PRs merged", strconv.Itoa(r.PRsMerged)},
+		{"PRs abandoned", strconv.Itoa(r.PRsAbandoned)},
+		{"PRs first green", strconv.Itoa(r.PRsGreen)},
+		{"Mean time to green", meanToGreen},
+		{"Fix attempts", strconv.Itoa(r.FixAttempts)},
+		{"Fixes that went green", strconv.Itoa(r.FixesSucceeded)},
+		{"Fixes that failed again", strconv.Itoa(r.FixesFailed)},
+		{"Fix success rate", percent(r.FixSuccessRate())},
+		{"Infra retries", strconv.Itoa(r.InfraRetries)},
+		{"Infra failure share", percent(r.InfraFailureShare())},
+	}
+	for _, c := range sortedKeys(r.FixesByCategory) {
+		rows = append(rows, [2]string{"Fixes: " + c, strconv.Itoa(r.FixesByCategory[c])})
+	}
+	rows = append(rows,
+		[2]string{"Comments handled", strconv.Itoa(r.CommentsHandled)},
+		[2]string{"Comments auto-resolved", strconv.Itoa(r.CommentsResolved)},
+	)
+	for _, d := range sortedKeys(r.CommentDecisions) {
+		rows = append(rows, [2]string{"Comments: " + d, strconv.Itoa(r.CommentDecisions[d])})
+	}
+	return rows
+}
+
+func writeReportCSV(w io.Writer, rows [][2]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"metric", "value"}); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := cw.Write(row[:]); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
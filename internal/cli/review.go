@@ -0,0 +1,279 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/prompts"
+	"github.com/alanmeadows/otto/internal/repo"
+	"github.com/spf13/cobra"
+)
+
+// localFinding is a review comment merged across the models that raised it.
+type localFinding struct {
+	reviewComment
+	Models []string
+}
+
+var reviewCmd = &cobra.Command{
+	Use:   "review [guidance]",
+	Short: "Review the current branch before opening a PR",
+	Long: `Run an LLM code review over the current branch's changes locally,
+before a pull request exists.
+
+Each configured model role in --models reviews the diff against --base
+with the same prompt as otto pr review. Findings at the same file and
+line are merged, keeping the most serious severity and noting which
+models raised them, then printed grouped by severity.
+
+With --fix, the primary model fixes the error and warning findings in
+the working tree. Nothing is committed; inspect the result with git diff.`,
+	Example: `  otto review
+  otto review --base develop
+  otto review --fix "focus on error handling"`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := llm.WithProgress(cmd.Context(), progressPrinter(cmd.ErrOrStderr()))
+		out := cmd.OutOrStdout()
+		base, _ := cmd.Flags().GetString("base")
+		roles, _ := cmd.Flags().GetStringSlice("models")
+		fix, _ := cmd.Flags().GetBool("fix")
+		var guidance string
+		if len(args) > 0 {
+			guidance = args[0]
+		}
+
+		workDir := config.RepoRoot()
+		if workDir == "" {
+			return fmt.Errorf("otto review must be run inside a git repository")
+		}
+		base, err := resolveBaseRef(workDir, base)
+		if err != nil {
+			return err
+		}
+		branch := gitOutput(workDir, "rev-parse", "--abbrev-ref", "HEAD")
+		commits := gitOutput(workDir, "log", "--oneline", base+"..HEAD")
+		if commits == "" {
+			fmt.Fprintf(out, "No commits on %s since %s; nothing to review.\n", branch, base)
+			return nil
+		}
+
+		templateData := map[string]string{
+			"pr_title":       branch,
+			"pr_description": "Local review of the branch's commits:\n\n" + commits,
+			"target_branch":  base,
+		}
+		if summary, err := repo.AnalyzeCodebase(workDir); err != nil {
+			slog.Warn("codebase analysis failed, continuing without summary", "error", err)
+		} else if summary != nil {
+			templateData["codebase_summary"] = summary.String()
+		}
+		if guidance != "" {
+			templateData["guidance"] = guidance
+		}
+		prompt, err := prompts.Execute("pr-review.md", templateData)
+		if err != nil {
+			return fmt.Errorf("building review prompt: %w", err)
+		}
+
+		var findings []localFinding
+		reviewed := 0
+		for _, role := range reviewRoles(appConfig.Models, roles) {
+			model := appConfig.Models.ModelFor(role)
+			fmt.Fprintf(out, "Reviewing %s against %s with %s (%s)...\n", branch, base, model, role)
+			comments, err := reviewWithRole(ctx, role, workDir, prompt)
+			if err != nil {
+				fmt.Fprintf(out, "  Warning: %s review failed: %v\n", model, err)
+				continue
+			}
+			reviewed++
+			findings = mergeFindings(findings, comments, model)
+		}
+		if reviewed == 0 {
+			return fmt.Errorf("no model completed the review")
+		}
+
+		printFindings(out, findings)
+
+		if fix {
+			return fixFindings(ctx, out, workDir, findings)
+		}
+		return nil
+	},
+}
+
+func init() {
+	reviewCmd.Flags().String("base", "main", "Branch to diff against (falls back to origin/<base>)")
+	reviewCmd.Flags().StringSlice("models", []string{config.RolePrimary, config.RoleSecondary}, "Model roles that review the branch")
+	reviewCmd.Flags().Bool("fix", false, "Fix error and warning findings in the working tree")
+}
+
+// resolveBaseRef returns base if it names a commit in workDir, otherwise
+// origin/<base>.
+func resolveBaseRef(workDir, base string) (string, error) {
+	for _, ref := range []string{base, "origin/" + base} {
+		cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+		cmd.Dir = workDir
+		if cmd.Run() == nil {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("base branch %q not found locally or on origin", base)
+}
+
+// gitOutput runs git in workDir and returns its trimmed output, or "" on
+// failure.
+func gitOutput(workDir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// reviewRoles returns the requested roles that have a model configured,
+// skipping roles that would repeat a model already reviewing.
+func reviewRoles(models config.ModelsConfig, roles []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, role := range roles {
+		model := models.ModelFor(role)
+		key := models.BackendFor(role) + "\x00" + model
+		if model == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, role)
+	}
+	return out
+}
+
+// reviewWithRole runs the review prompt on the model configured for role.
+func reviewWithRole(ctx context.Context, role, workDir, prompt string) ([]reviewComment, error) {
+	backend, err := llm.NewBackend(appConfig.Models, role, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := backend.Start(ctx); err != nil {
+		return nil, fmt.Errorf("starting LLM client: %w", err)
+	}
+	defer backend.Stop()
+
+	session, err := backend.CreateSession(ctx, "Local Review", workDir)
+	if err != nil {
+		return nil, fmt.Errorf("creating review session: %w", err)
+	}
+	defer backend.DeleteSession(ctx, session.ID)
+
+	resp, err := backend.SendPrompt(ctx, session.ID, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("review prompt failed: %w", err)
+	}
+	return llm.ParseJSONResponse[[]reviewComment](ctx, backend, session.ID, resp.Content)
+}
+
+// mergeFindings adds model's comments to findings. A comment at the same
+// file and line as an existing finding is folded into it, keeping the
+// more serious severity.
+func mergeFindings(findings []localFinding, comments []reviewComment, model string) []localFinding {
+	for _, c := range comments {
+		c.Severity = strings.ToLower(c.Severity)
+		if !slices.Contains(config.ReviewSeverities, c.Severity) {
+			c.Severity = "warning"
+		}
+		i := slices.IndexFunc(findings, func(f localFinding) bool { return f.File == c.File && f.Line == c.Line })
+		if i < 0 {
+			findings = append(findings, localFinding{reviewComment: c, Models: []string{model}})
+			continue
+		}
+		f := &findings[i]
+		if slices.Index(config.ReviewSeverities, c.Severity) < slices.Index(config.ReviewSeverities, f.Severity) {
+			f.Severity, f.Body = c.Severity, c.Body
+		}
+		if !slices.Contains(f.Models, model) {
+			f.Models = append(f.Models, model)
+		}
+	}
+	return findings
+}
+
+// printFindings prints findings grouped by severity, most serious first.
+func printFindings(w io.Writer, findings []localFinding) {
+	if len(findings) == 0 {
+		fmt.Fprintln(w, "\nNo issues found. The branch looks clean.")
+		return
+	}
+	for _, sev := range config.ReviewSeverities {
+		var group []localFinding
+		for _, f := range findings {
+			if f.Severity == sev {
+				group = append(group, f)
+			}
+		}
+		if len(group) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s (%d)\n", strings.ToUpper(sev), len(group))
+		for _, f := range group {
+			fmt.Fprintf(w, "  %s:%d  [%s]\n", f.File, f.Line, strings.Join(f.Models, ", "))
+			for _, line := range strings.Split(strings.TrimSpace(f.Body), "\n") {
+				fmt.Fprintf(w, "      %s\n", line)
+			}
+		}
+	}
+}
+
+// fixFindings has the primary model fix the error and warning findings in
+// workDir, without committing.
+func fixFindings(ctx context.Context, w io.Writer, workDir string, findings []localFinding) error {
+	var list strings.Builder
+	n := 0
+	for _, f := range findings {
+		if f.Severity == "nitpick" {
+			continue
+		}
+		n++
+		fmt.Fprintf(&list, "%d. [%s] %s:%d\n%s\n\n", n, f.Severity, f.File, f.Line, f.Body)
+	}
+	if n == 0 {
+		fmt.Fprintln(w, "\nNo error or warning findings to fix.")
+		return nil
+	}
+
+	prompt, err := prompts.Execute("review-fix.md", map[string]string{"findings": list.String()})
+	if err != nil {
+		return fmt.Errorf("building fix prompt: %w", err)
+	}
+
+	llmClient, err := llm.NewFallbackClient(appConfig.Models, "")
+	if err != nil {
+		return err
+	}
+	if err := llmClient.Start(ctx); err != nil {
+		return fmt.Errorf("starting LLM client: %w", err)
+	}
+	defer llmClient.Stop()
+
+	session, err := llmClient.CreateSession(ctx, "Local Review Fix", workDir)
+	if err != nil {
+		return fmt.Errorf("creating fix session: %w", err)
+	}
+	defer llmClient.DeleteSession(ctx, session.ID)
+
+	fmt.Fprintf(w, "\nFixing %d finding(s)...\n", n)
+	resp, err := llmClient.SendPrompt(ctx, session.ID, prompt)
+	if err != nil {
+		return fmt.Errorf("fix prompt failed: %w", err)
+	}
+	fmt.Fprintf(w, "%s\n\nChanges are uncommitted; inspect them with git diff.\n", strings.TrimSpace(resp.Content))
+	return nil
+}
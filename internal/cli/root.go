@@ -1,11 +1,17 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/alanmeadows/otto/internal/config"
 	"github.com/alanmeadows/otto/internal/logging"
+	"github.com/alanmeadows/otto/internal/store"
+	"github.com/alanmeadows/otto/internal/telemetry"
 	"github.com/spf13/cobra"
 )
 
@@ -16,7 +22,11 @@ var (
 	verbose    bool
 	configPath string
 	appConfig  *config.Config
-	rootCmd    = &cobra.Command{
+
+	// shutdownTelemetry flushes any buffered trace spans on exit.
+	shutdownTelemetry telemetry.ShutdownFunc
+
+	rootCmd = &cobra.Command{
 		Use:   "otto",
 		Short: "LLM-powered PR lifecycle manager",
 		Long: `Otto orchestrates LLM-driven workflows through the GitHub Copilot SDK
@@ -41,25 +51,70 @@ func init() {
 		logging.Setup(verbose)
 		cfg, err := config.Load(configPath)
 		if err != nil {
-			return err
+			if cmd != configValidateCmd {
+				return err
+			}
+			// 'config validate' reports the error itself.
+			defaults := config.DefaultConfig()
+			cfg = &defaults
 		}
 		appConfig = cfg
+		store.DefaultLockTimeout = cfg.Server.LockTimeoutDuration()
+
+		shutdown, err := telemetry.Setup(cmd.Context(), cfg.Telemetry, Version)
+		if err != nil {
+			// Tracing is diagnostic only; never block a command on it.
+			slog.Warn("failed to initialize tracing", "error", err)
+		}
+		shutdownTelemetry = shutdown
 		return nil
 	}
 
 	// Wire all subcommands
 	rootCmd.AddCommand(prCmd)
+	rootCmd.AddCommand(reviewCmd)
 	rootCmd.AddCommand(repoCmd)
 	rootCmd.AddCommand(worktreeCmd)
+	rootCmd.AddCommand(branchCmd)
+	rootCmd.AddCommand(searchCmd)
 	rootCmd.AddCommand(serverCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(releaseCmd)
+	rootCmd.AddCommand(issueCmd)
+	rootCmd.AddCommand(scheduleCmd)
+	rootCmd.AddCommand(batchCmd)
+	rootCmd.AddCommand(newCmd)
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(authCmd)
+	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(flakyCmd)
+	rootCmd.AddCommand(evalCmd)
+	rootCmd.AddCommand(mockProviderCmd)
 
 	// Enable built-in shell completion command (bash, zsh, fish, powershell)
 	rootCmd.CompletionOptions.DisableDefaultCmd = false
 }
 
+// dataPath returns the path of elem under otto's data directory, for help
+// text, or a description of it when the directory can't be resolved.
+func dataPath(elem ...string) string {
+	dir, err := config.DataDir()
+	if err != nil {
+		return filepath.Join(elem...) + " in otto's data directory"
+	}
+	return filepath.Join(append([]string{dir}, elem...)...)
+}
+
 func Execute() error {
 	err := rootCmd.Execute()
+	if shutdownTelemetry != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if shutdownErr := shutdownTelemetry(ctx); shutdownErr != nil {
+			slog.Warn("failed to flush traces", "error", shutdownErr)
+		}
+		cancel()
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 	}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/spf13/cobra"
+)
+
+var scheduleJSONFlag bool
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Inspect scheduled tasks",
+	Long: `Inspect the recurring tasks the daemon runs from the schedules config.
+
+Each task has a name, a cron schedule in local time (e.g. "0 2 * * *",
+or @daily, @weekly), and an action:
+
+  rebase   rebase the open PRs otto tracks (only repo's, if set) onto
+           their target branches, resolving conflicts as it does for a
+           conflicted PR
+  command  run command in a fresh worktree of repo off target, and open
+           a PR with whatever it changed; otto then tracks the PR
+
+Results go out as task_completed or task_failed notifications. A task
+the daemon was down for runs once when it is back; a new task first
+runs at its next scheduled time.`,
+	Example: `  otto config set schedules '[{"name": "nightly-rebase", "schedule": "0 2 * * *", "action": "rebase"}]'
+  otto schedule list`,
+}
+
+func init() {
+	scheduleListCmd.Flags().BoolVar(&scheduleJSONFlag, "json", false, "Output raw JSON")
+	scheduleCmd.AddCommand(scheduleListCmd)
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List scheduled tasks with their last and next runs",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tasks, err := server.ListScheduledTasks(appConfig)
+		if err != nil {
+			return fmt.Errorf("reading scheduled tasks: %w", err)
+		}
+
+		if scheduleJSONFlag {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(tasks)
+		}
+
+		if len(tasks) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No scheduled tasks configured.")
+			return nil
+		}
+
+		headerStyle := lipgloss.NewStyle().Bold(true).Padding(0, 1)
+		cellStyle := lipgloss.NewStyle().Padding(0, 1)
+
+		const when = "2006-01-02 15:04"
+		rows := make([][]string, 0, len(tasks))
+		for _, t := range tasks {
+			last, result := "never", ""
+			if t.Last != nil && !t.Last.Started.IsZero() {
+				last = t.Last.Started.Local().Format(when)
+				result = t.Last.Result
+				if t.Last.Error != "" {
+					result = "failed: " + t.Last.Error
+				}
+				if t.Last.PRURL != "" {
+					result += " " + t.Last.PRURL
+				}
+			}
+			next := "-"
+			if t.Disabled {
+				next = "disabled"
+			} else if !t.Next.IsZero() {
+				next = t.Next.Local().Format(when)
+			}
+			rows = append(rows, []string{t.Name, t.Schedule, t.Action, t.Repo, last, truncateStr(result, 60), next})
+		}
+
+		tbl := table.New().
+			Border(lipgloss.NormalBorder()).
+			Headers("NAME", "SCHEDULE", "ACTION", "REPO", "LAST RUN", "RESULT", "NEXT RUN").
+			Rows(rows...).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				if row == table.HeaderRow {
+					return headerStyle
+				}
+				return cellStyle
+			})
+
+		fmt.Fprintln(cmd.OutOrStdout(), tbl)
+		return nil
+	},
+}
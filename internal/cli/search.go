@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/codesearch"
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/repo"
+	"github.com/spf13/cobra"
+)
+
+// searchPreviewLines is how much of each hit is shown without --json.
+const searchPreviewLines = 8
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search a repo's code by meaning",
+	Long: `Search a repo's source for the snippets closest in meaning to the query,
+using the repo's embeddings index.
+
+The index is enabled per repo with search_index and embedded with
+pr.context_pack.embedding_model. The daemon keeps it up to date with
+the primary checkout as it polls; search first indexes whatever the
+checkout being searched has that the index lacks, so the first search
+of a large repo takes a while.
+
+Without --repo, the checkout the current directory is in is searched.`,
+	Example: `  otto search "where are refunds issued"
+  otto search "retry with backoff" --repo my-service -n 5
+  otto search "token refresh" --json | jq '.[].path'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		w := cmd.OutOrStdout()
+		query := strings.Join(args, " ")
+		repoName, _ := cmd.Flags().GetString("repo")
+		limit, _ := cmd.Flags().GetInt("limit")
+		jsonOut, _ := cmd.Flags().GetBool("json")
+
+		var rc *config.RepoConfig
+		var dir string
+		if repoName != "" {
+			if rc = findRepo(repoName); rc == nil {
+				return fmt.Errorf("repo %q is not configured (see otto repo list)", repoName)
+			}
+			dir = rc.PrimaryDir
+		} else {
+			var err error
+			if rc, err = repo.NewManager("").FindByCWD(appConfig); err != nil {
+				return fmt.Errorf("%w; give one with --repo", err)
+			}
+			dir = gitOutput(".", "rev-parse", "--show-toplevel")
+		}
+
+		ix, err := codesearch.Open(appConfig, *rc)
+		if err != nil {
+			return err
+		}
+		stats, err := ix.Update(ctx, dir)
+		if err != nil {
+			return fmt.Errorf("updating search index: %w", err)
+		}
+		if stats.Embedded > 0 && !jsonOut {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Indexed %d new chunks of %s.\n", stats.Embedded, rc.Name)
+		}
+		hits, err := ix.Search(ctx, dir, query, limit)
+		if err != nil {
+			return fmt.Errorf("searching: %w", err)
+		}
+
+		if jsonOut {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(hits)
+		}
+		if len(hits) == 0 {
+			fmt.Fprintln(w, "No matches.")
+			return nil
+		}
+		for i, h := range hits {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "%s:%d-%d  (%.2f)\n", h.Path, h.StartLine, h.EndLine, h.Score)
+			lines := strings.Split(strings.TrimRight(h.Content, "\n"), "\n")
+			for _, line := range lines[:min(len(lines), searchPreviewLines)] {
+				fmt.Fprintf(w, "    %s\n", line)
+			}
+			if len(lines) > searchPreviewLines {
+				fmt.Fprintf(w, "    ... (%d more lines)\n", len(lines)-searchPreviewLines)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	searchCmd.Flags().String("repo", "", "Configured repo to search (default: the checkout of the current directory)")
+	searchCmd.Flags().IntP("limit", "n", 10, "Maximum number of results")
+	searchCmd.Flags().Bool("json", false, "Output raw JSON")
+	_ = searchCmd.RegisterFlagCompletionFunc("repo", completeRepoNames)
+}
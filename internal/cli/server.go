@@ -7,17 +7,21 @@ import (
 
 	"github.com/alanmeadows/otto/internal/config"
 	"github.com/alanmeadows/otto/internal/server"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
 	"github.com/spf13/cobra"
 )
 
 var serverCmd = &cobra.Command{
-	Use:   "server",
-	Short: "Manage the otto daemon",
+	Use:     "server",
+	Aliases: []string{"daemon"},
+	Short:   "Manage the otto daemon",
 	Long: `Start, stop, and manage the otto background daemon.
 
 The daemon runs an HTTP API and periodically polls tracked PRs for
 new review comments. It can be run in the foreground for debugging
-or installed as a systemd user service for persistent operation.`,
+or installed as a systemd user service (Linux) or launchd agent
+(macOS) for persistent operation.`,
 	Example: `  otto server start
   otto server start --foreground --port 9090
   otto server status
@@ -33,14 +37,17 @@ var noTunnelFlag bool
 var upgradeChannelFlag string
 var insecureTunnelFlag bool
 var insecureDashboardFlag bool
+var recoveryFlag bool
 
 func init() {
 	serverCmd.AddCommand(serverStartCmd)
 	serverCmd.AddCommand(serverStopCmd)
 	serverCmd.AddCommand(serverRestartCmd)
+	serverCmd.AddCommand(serverReloadCmd)
 	serverCmd.AddCommand(serverUpgradeCmd)
 	serverCmd.AddCommand(serverStatusCmd)
 	serverCmd.AddCommand(serverInstallCmd)
+	serverCmd.AddCommand(serverUninstallCmd)
 	serverCmd.AddCommand(serverLogsCmd)
 
 	serverStartCmd.Flags().BoolVar(&foregroundFlag, "foreground", false, "Run in foreground (don't daemonize)")
@@ -51,6 +58,7 @@ func init() {
 	serverStartCmd.Flags().BoolVar(&noTunnelFlag, "no-tunnel", false, "Disable Azure DevTunnel for dashboard")
 	serverStartCmd.Flags().BoolVar(&insecureTunnelFlag, "insecure-tunnel", false, "Launch tunnel without authentication (anonymous access)")
 	serverStartCmd.Flags().BoolVar(&insecureDashboardFlag, "insecure-dashboard", false, "Disable dashboard passcode requirement (fully open)")
+	serverStatusCmd.Flags().BoolVar(&recoveryFlag, "recovery", false, "Show the recovery audit from the last daemon boot")
 	serverUpgradeCmd.Flags().StringVar(&upgradeChannelFlag, "channel", "", "Upgrade channel: \"release\" (default) or \"main\" (build from source)")
 }
 
@@ -170,6 +178,25 @@ auto-reconnect after restart.`,
 	},
 }
 
+var serverReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Reload the daemon's config",
+	Long: `Signal the daemon (SIGHUP) to reload its configuration without a
+restart. The daemon also reloads on its own when a config file changes.
+
+Models, providers, repos, notifications, and the poll interval take
+effect before the next poll; tracked PRs and in-progress work are
+untouched. Ports, dashboard, and telemetry settings need a restart.`,
+	Example: `  otto server reload`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := server.ReloadDaemon(); err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "reload signaled; see 'otto server logs' for the result")
+		return nil
+	},
+}
+
 var serverUpgradeCmd = &cobra.Command{
 	Use:   "upgrade",
 	Short: "Upgrade and restart the otto daemon",
@@ -195,9 +222,20 @@ var serverStatusCmd = &cobra.Command{
 	Short: "Show daemon status",
 	Long: `Show whether the otto daemon is running.
 
-Displays the PID, uptime, endpoints, and tunnel URL when active.`,
-	Example: `  otto server status`,
+Displays the PID, uptime, whether the API answers, endpoints, and
+tunnel URL when active.
+
+With --recovery, shows the audit the daemon ran at its last boot:
+stuck PRs, orphaned worktrees, interrupted rebases, unpushed fix
+commits, stale locks, and undelivered notifications — and whether
+each was repaired automatically or needs attention.`,
+	Example: `  otto server status
+  otto server status --recovery`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if recoveryFlag {
+			return printRecoveryReport(cmd)
+		}
+
 		running, pid, uptime, err := server.DaemonStatus()
 		if err != nil {
 			return err
@@ -210,6 +248,20 @@ Displays the PID, uptime, endpoints, and tunnel URL when active.`,
 
 		fmt.Fprintf(cmd.OutOrStdout(), "daemon is running (PID %d, uptime %s)\n", pid, uptime.Round(1*1e9))
 
+		// Credential health from the daemon's last auth check.
+		if health, err := server.LoadAuthHealth(); err == nil {
+			for _, h := range health {
+				switch {
+				case !h.OK:
+					fmt.Fprintf(cmd.OutOrStdout(), "  auth:      %s credentials rejected, polling paused: %s\n", h.Provider, h.Error)
+				case !h.ExpiresAt.IsZero():
+					fmt.Fprintf(cmd.OutOrStdout(), "  auth:      %s ok, expires %s\n", h.Provider, h.ExpiresAt.Local().Format("2006-01-02 15:04"))
+				default:
+					fmt.Fprintf(cmd.OutOrStdout(), "  auth:      %s ok\n", h.Provider)
+				}
+			}
+		}
+
 		// Load config to determine ports.
 		cfg, cfgErr := config.Load()
 		if cfgErr != nil {
@@ -220,6 +272,11 @@ Displays the PID, uptime, endpoints, and tunnel URL when active.`,
 		if apiPort == 0 {
 			apiPort = 4097
 		}
+		if health, err := server.DaemonHealth(apiPort); err != nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "  health:    API not responding: %v\n", err)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "  health:    ok, %d PR(s) tracked\n", health.PRCount)
+		}
 		fmt.Fprintf(cmd.OutOrStdout(), "  api:       http://localhost:%d\n", apiPort)
 
 		dashPort := cfg.Dashboard.Port
@@ -247,6 +304,48 @@ Displays the PID, uptime, endpoints, and tunnel URL when active.`,
 	},
 }
 
+// printRecoveryReport renders the last boot's recovery audit as a table.
+func printRecoveryReport(cmd *cobra.Command) error {
+	report, err := server.LoadRecoveryReport()
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if report == nil {
+		fmt.Fprintln(out, "no recovery audit has run yet (it runs when the daemon starts)")
+		return nil
+	}
+
+	fmt.Fprintf(out, "recovery audit at %s: %d issue(s), %d need attention\n",
+		report.Time.Local().Format(time.DateTime), len(report.Issues), len(report.NeedsAttention()))
+	if len(report.Issues) == 0 {
+		return nil
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Padding(0, 1)
+	cellStyle := lipgloss.NewStyle().Padding(0, 1)
+	rows := make([][]string, 0, len(report.Issues))
+	for _, is := range report.Issues {
+		result := "repaired"
+		if !is.Repaired {
+			result = "needs attention"
+		}
+		rows = append(rows, []string{is.Kind, is.Subject, result, is.Detail})
+	}
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		Headers("KIND", "SUBJECT", "RESULT", "DETAIL").
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return headerStyle
+			}
+			return cellStyle
+		})
+	fmt.Fprintln(out, t)
+	return nil
+}
+
 var serverLogsCmd = &cobra.Command{
 	Use:     "logs",
 	Short:   "Show the daemon log file path",
@@ -264,15 +363,28 @@ var serverLogsCmd = &cobra.Command{
 
 var serverInstallCmd = &cobra.Command{
 	Use:   "install",
-	Short: "Install as systemd user service",
-	Long: `Install the otto daemon as a systemd user service.
-
-Creates a systemd unit file under ~/.config/systemd/user/ so the
-daemon starts automatically on login. Use 'systemctl --user' to
-manage the service after installation.`,
+	Short: "Install as a systemd user service or launchd agent",
+	Long: `Install the otto daemon as a service that starts at login and is
+restarted if it crashes.
+
+On Linux, creates a systemd unit file under ~/.config/systemd/user/;
+use 'systemctl --user' to manage the service after installation. On
+macOS, creates a launchd agent under ~/Library/LaunchAgents/ and loads
+it, which starts the daemon. Either way, 'otto server stop' stops the
+daemon without it being restarted.`,
 	Example: `  otto server install`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return server.InstallSystemdService()
+		return server.InstallService()
+	},
+}
+
+var serverUninstallCmd = &cobra.Command{
+	Use:     "uninstall",
+	Short:   "Remove the systemd user service or launchd agent",
+	Long:    `Stop the otto service and remove what 'otto server install' created.`,
+	Example: `  otto server uninstall`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return server.UninstallService()
 	},
 }
 
@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/server"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show an overview of otto",
+	Long: `Show the state of otto's moving parts in one table: the daemon and
+its API, tracked PRs by status, the Copilot server (and the OpenCode
+server, when a model role uses it), the dashboard and its tunnel,
+provider credentials, and when PRs were last polled.
+
+Everything that doesn't need the daemon (tracked PRs, credentials from
+the last check) is shown even when it is stopped. Use 'otto server
+status' for the daemon alone.`,
+	Example: `  otto status`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rows, err := statusRows(cmd.Context())
+		if err != nil {
+			return err
+		}
+		headerStyle := lipgloss.NewStyle().Bold(true).Padding(0, 1)
+		cellStyle := lipgloss.NewStyle().Padding(0, 1)
+		t := table.New().
+			Border(lipgloss.NormalBorder()).
+			Headers("COMPONENT", "STATUS", "DETAIL").
+			Rows(rows...).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				if row == table.HeaderRow {
+					return headerStyle
+				}
+				return cellStyle
+			})
+		fmt.Fprintln(cmd.OutOrStdout(), t)
+		return nil
+	},
+}
+
+// prStatusOrder is the order PR statuses are listed in.
+var prStatusOrder = []string{"watching", "fixing", "green", "failed", "merged", "abandoned"}
+
+// statusRows gathers one row per component for `otto status`.
+func statusRows(ctx context.Context) ([][]string, error) {
+	var rows [][]string
+
+	apiPort := appConfig.Server.Port
+	if apiPort == 0 {
+		apiPort = 4097
+	}
+	dashPort := appConfig.Dashboard.Port
+	if dashPort == 0 {
+		dashPort = 4098
+	}
+
+	// Daemon and API.
+	running, pid, uptime, err := server.DaemonStatus()
+	if err != nil {
+		return nil, err
+	}
+	var health *server.StatusResponse
+	if running {
+		rows = append(rows, []string{"daemon", "running", fmt.Sprintf("PID %d, uptime %s", pid, uptime.Round(time.Second))})
+		if health, err = server.DaemonHealth(apiPort); err != nil {
+			rows = append(rows, []string{"api", "not responding", err.Error()})
+		} else {
+			rows = append(rows, []string{"api", "ok", fmt.Sprintf("http://localhost:%d", apiPort)})
+		}
+	} else {
+		rows = append(rows, []string{"daemon", "stopped", "start with: otto server start"})
+	}
+
+	// Tracked PRs, read from disk so they show without the daemon.
+	prs, err := server.ListPRs()
+	if err != nil {
+		return nil, err
+	}
+	rows = append(rows, []string{"prs", fmt.Sprintf("%d tracked", len(prs)), prCountSummary(prs)})
+
+	// Poll times: the daemon's last poll, and the newest PR check.
+	var lastChecked time.Time
+	for _, pr := range prs {
+		if t, err := time.Parse(time.RFC3339, pr.LastChecked); err == nil && t.After(lastChecked) {
+			lastChecked = t
+		}
+	}
+	switch {
+	case health != nil && !health.LastPoll.IsZero():
+		rows = append(rows, []string{"last poll", formatAgo(health.LastPoll), health.LastPoll.Local().Format(time.DateTime)})
+	case !lastChecked.IsZero():
+		rows = append(rows, []string{"last poll", formatAgo(lastChecked), "newest PR check; daemon has not polled since starting"})
+	default:
+		rows = append(rows, []string{"last poll", "never", ""})
+	}
+
+	// Copilot server.
+	addr := server.CopilotServerAddr(appConfig.Dashboard.CopilotServerOverride)
+	if health != nil && health.CopilotServer != "" {
+		addr = health.CopilotServer
+	}
+	if server.CopilotServerReachable(addr) {
+		rows = append(rows, []string{"copilot", "ok", addr})
+	} else {
+		rows = append(rows, []string{"copilot", "unreachable", addr})
+	}
+
+	// OpenCode server, for the first role that uses it.
+	for _, role := range []string{config.RolePrimary, config.RoleSecondary, config.RoleTertiary} {
+		model := appConfig.Models.ModelFor(role)
+		if model == "" || appConfig.Models.BackendFor(role) != config.BackendOpenCode {
+			continue
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		err := llm.NewOpenCodeClient(model, appConfig.Models.Endpoints[config.BackendOpenCode].BaseURL).Start(checkCtx)
+		cancel()
+		if err != nil {
+			rows = append(rows, []string{"opencode", "unreachable", err.Error()})
+		} else {
+			rows = append(rows, []string{"opencode", "ok", "serving " + role + " (" + model + ")"})
+		}
+		break
+	}
+
+	// Dashboard and tunnel.
+	if conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", dashPort), 2*time.Second); err != nil {
+		rows = append(rows, []string{"dashboard", "down", fmt.Sprintf("http://localhost:%d", dashPort)})
+		rows = append(rows, []string{"tunnel", "down", ""})
+	} else {
+		conn.Close()
+		rows = append(rows, []string{"dashboard", "ok", fmt.Sprintf("http://localhost:%d", dashPort)})
+		if url := server.PollTunnelURLQuick(dashPort); url != "" {
+			rows = append(rows, []string{"tunnel", "ok", url})
+		} else {
+			rows = append(rows, []string{"tunnel", "down", "see 'otto server logs' for the reason"})
+		}
+	}
+
+	// The daemon's file locks: waits and timeouts.
+	if health != nil {
+		l := health.Locks
+		state := "ok"
+		if l.Timeouts > 0 {
+			state = "timeouts"
+		}
+		rows = append(rows, []string{"locks", state, fmt.Sprintf("%d taken, %d waited (max %s), %d timed out",
+			l.Acquired, l.Contended, l.MaxWait.Round(time.Millisecond), l.Timeouts)})
+	}
+
+	// Credentials from the last auth check.
+	authHealth, _ := server.LoadAuthHealth()
+	if health != nil {
+		authHealth = health.Auth
+	}
+	for _, h := range authHealth {
+		switch {
+		case !h.OK:
+			rows = append(rows, []string{"auth: " + h.Provider, "rejected", "polling paused: " + h.Error})
+		case !h.ExpiresAt.IsZero():
+			rows = append(rows, []string{"auth: " + h.Provider, "ok", "expires " + h.ExpiresAt.Local().Format("2006-01-02 15:04")})
+		default:
+			rows = append(rows, []string{"auth: " + h.Provider, "ok", ""})
+		}
+	}
+	return rows, nil
+}
+
+// prCountSummary lists PR counts by status, e.g. "2 watching, 1 fixing".
+// Paused PRs are counted separately as well.
+func prCountSummary(prs []*server.PRDocument) string {
+	counts := make(map[string]int)
+	paused := 0
+	for _, pr := range prs {
+		counts[pr.Status]++
+		if pr.Paused {
+			paused++
+		}
+	}
+	var parts []string
+	for _, st := range prStatusOrder {
+		if n := counts[st]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, st))
+			delete(counts, st)
+		}
+	}
+	// Any status not in the usual list, in a stable order.
+	var rest []string
+	for st := range counts {
+		rest = append(rest, st)
+	}
+	sort.Strings(rest)
+	for _, st := range rest {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[st], st))
+	}
+	if paused > 0 {
+		parts = append(parts, fmt.Sprintf("%d paused", paused))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatAgo renders how long ago t was, e.g. "3m ago".
+func formatAgo(t time.Time) string {
+	d := time.Since(t).Round(time.Second)
+	if d < time.Second {
+		return "just now"
+	}
+	return d.String() + " ago"
+}
@@ -1,11 +1,22 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/repo"
+	"github.com/alanmeadows/otto/internal/server"
 	"github.com/spf13/cobra"
 )
 
+// worktreeStatusTimeout bounds reading each worktree's git state.
+const worktreeStatusTimeout = 5 * time.Second
+
 var worktreeCmd = &cobra.Command{
 	Use:   "worktree",
 	Short: "Manage git worktrees",
@@ -23,6 +34,9 @@ func init() {
 	worktreeCmd.AddCommand(worktreeAddCmd)
 	worktreeCmd.AddCommand(worktreeListCmd)
 	worktreeCmd.AddCommand(worktreeRemoveCmd)
+	worktreeListCmd.Flags().String("repo", "", "Only list this repo's worktrees")
+	worktreeListCmd.Flags().Bool("json", false, "Output raw JSON")
+	_ = worktreeListCmd.RegisterFlagCompletionFunc("repo", completeRepoNames)
 }
 
 var worktreeAddCmd = &cobra.Command{
@@ -43,12 +57,83 @@ the worktree is checked out in the configured worktree directory.`,
 var worktreeListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List worktrees",
-	Long: `List all git worktrees managed by otto.
+	Long: `List the working directories of the configured repos: the directories
+under each repo's worktree_dir (its primary checkout when it has none)
+and the worktrees created by otto branch new.
 
-Displays the worktree name, branch, and directory path.`,
-	Example: `  otto worktree list`,
+Each shows its checked-out branch, how far it is ahead of and behind its
+upstream, whether it has uncommitted changes, and the PR otto tracks for
+the branch, if any. Git is read for all worktrees at once, a few seconds
+at most each.`,
+	Example: `  otto worktree list
+  otto worktree list --repo api --json`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Fprintln(cmd.OutOrStdout(), "not implemented yet")
+		repoName, _ := cmd.Flags().GetString("repo")
+		repos := appConfig.Repos
+		if repoName != "" {
+			rc := findRepo(repoName)
+			if rc == nil {
+				return fmt.Errorf("repo %q is not configured (see otto repo list)", repoName)
+			}
+			repos = []config.RepoConfig{*rc}
+		}
+
+		wts := repo.ListWorktrees(cmd.Context(), repos, worktreeStatusTimeout)
+		prs, err := server.PRsByBranch()
+		if err != nil {
+			return fmt.Errorf("reading tracked PRs: %w", err)
+		}
+		if repoName != "" {
+			kept := wts[:0]
+			for _, wt := range wts {
+				if wt.Repo == repoName {
+					kept = append(kept, wt)
+				}
+			}
+			wts = kept
+		}
+
+		if jsonOut, _ := cmd.Flags().GetBool("json"); jsonOut {
+			type worktreeJSON struct {
+				repo.Worktree
+				PR *server.PRDocument `json:"pr,omitempty"`
+			}
+			out := make([]worktreeJSON, 0, len(wts))
+			for _, wt := range wts {
+				out = append(out, worktreeJSON{Worktree: wt, PR: prs[wt.Branch]})
+			}
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(out)
+		}
+
+		if len(wts) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No worktrees.")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(wts))
+		for _, wt := range wts {
+			if wt.Error != "" {
+				rows = append(rows, []string{wt.Repo, wt.Name, "?", "", "", wt.Error, wt.Path})
+				continue
+			}
+			sync := "-"
+			if wt.Upstream != "" {
+				sync = fmt.Sprintf("↑%d ↓%d", wt.Ahead, wt.Behind)
+			}
+			dirty := ""
+			if wt.Dirty {
+				dirty = "✎"
+			}
+			pr := "-"
+			if doc := prs[wt.Branch]; doc != nil {
+				pr = fmt.Sprintf("#%s %s", doc.ID, doc.Status)
+			}
+			rows = append(rows, []string{wt.Repo, wt.Name, wt.Branch, sync, dirty, pr, wt.Path})
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), styledTable([]string{"REPO", "NAME", "BRANCH", "SYNC", "DIRTY", "PR", "PATH"}, rows))
 		return nil
 	},
 }
@@ -67,3 +152,34 @@ The associated branch is not deleted automatically.`,
 		return nil
 	},
 }
+
+// describeWorktree renders wt's path, with the home directory as ~, and
+// its git state, e.g. "~/wt/feature-x (dirty, 2 ahead)".
+func describeWorktree(wt *repo.Worktree) string {
+	path := wt.Path
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = filepath.Join("~", rel)
+		}
+	}
+	if wt.Error != "" {
+		return fmt.Sprintf("%s (%s)", path, wt.Error)
+	}
+	var state []string
+	if wt.Dirty {
+		state = append(state, "dirty")
+	}
+	if wt.Ahead > 0 {
+		state = append(state, fmt.Sprintf("%d ahead", wt.Ahead))
+	}
+	if wt.Behind > 0 {
+		state = append(state, fmt.Sprintf("%d behind", wt.Behind))
+	}
+	if wt.Spec != "" {
+		state = append(state, "spec "+wt.Spec)
+	}
+	if len(state) == 0 {
+		return path + " (clean)"
+	}
+	return fmt.Sprintf("%s (%s)", path, strings.Join(state, ", "))
+}
@@ -0,0 +1,440 @@
+// Package codesearch keeps an embeddings index of a repo's source code,
+// split into overlapping line ranges, and searches it for the snippets
+// closest to a query. Chunks are keyed by git blob, so bringing the index
+// up to date with a new commit embeds only the files the commit changed,
+// and checkouts of different branches share what they have in common.
+package codesearch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/store"
+)
+
+const (
+	chunkLines   = 60      // lines per chunk
+	chunkStep    = 50      // chunks overlap so code near a boundary is whole in one
+	chunkBytes   = 4000    // of each chunk that is embedded
+	queryBytes   = 8000    // of the query that is embedded
+	embedBatch   = 64      // texts per embeddings request
+	fileBatch    = 256     // files read from git at a time
+	maxFileBytes = 1 << 20 // larger files are not indexed
+
+	// Blobs that no indexed checkout has had for this long are dropped.
+	retainUnseen = 30 * 24 * time.Hour
+	// Blobs still in use are marked so at most this often, to spare
+	// rewriting the index on every update.
+	seenRefresh = 24 * time.Hour
+)
+
+// Hit is a snippet that matched a search.
+type Hit struct {
+	Path      string  `json:"path"`
+	StartLine int     `json:"start_line"`
+	EndLine   int     `json:"end_line"`
+	Score     float64 `json:"score"` // cosine similarity to the query
+	Content   string  `json:"content"`
+}
+
+// Stats reports what an update did.
+type Stats struct {
+	Files    int // source files in the checkout
+	Embedded int // chunks embedded because their files were new
+}
+
+// Index is one repo's search index.
+type Index struct {
+	Embedder llm.Embedder
+	// Path is the index file; see IndexPath.
+	Path string
+}
+
+// IndexPath returns where repoName's index is kept in the data directory.
+func IndexPath(repoName string) (string, error) {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "search", repoName+".gob"), nil
+}
+
+// Open returns rc's index, embedding with the model configured in
+// pr.context_pack. It fails when rc has no index enabled or no model is
+// configured.
+func Open(cfg *config.Config, rc config.RepoConfig) (*Index, error) {
+	if !rc.SearchIndex {
+		return nil, fmt.Errorf("repo %q has no search index (set search_index in its config)", rc.Name)
+	}
+	cp := cfg.PR.ContextPack
+	if cp.EmbeddingModel == "" {
+		return nil, fmt.Errorf("search indexes need an embedding model (set pr.context_pack.embedding_model)")
+	}
+	e, err := llm.NewEmbedder(cfg.Models, cp.EmbeddingBackend, cp.EmbeddingModel)
+	if err != nil {
+		return nil, err
+	}
+	p, err := IndexPath(rc.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &Index{Embedder: e, Path: p}, nil
+}
+
+// index is the on-disk form. It is gob-encoded: vectors make it too large
+// for JSON to be practical.
+type index struct {
+	Model string
+	Blobs map[string]*blob
+}
+
+type blob struct {
+	Chunks []chunk // none for files that aren't indexed, e.g. binaries
+	Seen   time.Time
+}
+
+type chunk struct {
+	Start, End int // 1-based, inclusive
+	Vector     []float32
+}
+
+// treeFile is a source file in a commit.
+type treeFile struct {
+	path string
+	blob string
+}
+
+// Update brings the index up to date with HEAD of the checkout at dir,
+// embedding the source files it hasn't seen. Chunks embedded before an
+// error are kept.
+func (ix *Index) Update(ctx context.Context, dir string) (Stats, error) {
+	files, err := sourceFiles(ctx, dir)
+	if err != nil {
+		return Stats{}, err
+	}
+	stats := Stats{Files: len(files)}
+	known := ix.load().Blobs
+
+	var missing []string
+	seen := make(map[string]bool)
+	for _, f := range files {
+		if known[f.blob] == nil && !seen[f.blob] {
+			seen[f.blob] = true
+			missing = append(missing, f.blob)
+		}
+	}
+
+	fresh := make(map[string]*blob)
+	for start := 0; start < len(missing) && err == nil; start += fileBatch {
+		var n int
+		n, err = ix.embedBlobs(ctx, dir, missing[start:min(len(missing), start+fileBatch)], fresh)
+		stats.Embedded += n
+	}
+	if saveErr := ix.save(fresh, files); err == nil {
+		err = saveErr
+	}
+	return stats, err
+}
+
+// embedBlobs chunks and embeds blobs into fresh and returns the number of
+// chunks embedded.
+func (ix *Index) embedBlobs(ctx context.Context, dir string, blobs []string, fresh map[string]*blob) (int, error) {
+	contents, err := readBlobs(ctx, dir, blobs)
+	if err != nil {
+		return 0, err
+	}
+	type pending struct {
+		blob  string
+		chunk int
+		text  string
+	}
+	var todo []pending
+	for _, sha := range blobs {
+		b := &blob{}
+		data := contents[sha]
+		if len(data) > 0 && bytes.IndexByte(data[:min(len(data), 8000)], 0) < 0 {
+			lines := strings.Split(string(data), "\n")
+			for start := 1; start <= len(lines); start += chunkStep {
+				end := min(len(lines), start+chunkLines-1)
+				text := strings.Join(lines[start-1:end], "\n")
+				if strings.TrimSpace(text) != "" {
+					b.Chunks = append(b.Chunks, chunk{Start: start, End: end})
+					todo = append(todo, pending{sha, len(b.Chunks) - 1, text[:min(len(text), chunkBytes)]})
+				}
+				if end == len(lines) {
+					break
+				}
+			}
+		}
+		fresh[sha] = b
+	}
+
+	for start := 0; start < len(todo); start += embedBatch {
+		batch := todo[start:min(len(todo), start+embedBatch)]
+		texts := make([]string, len(batch))
+		for i, p := range batch {
+			texts[i] = p.text
+		}
+		vectors, err := ix.Embedder.Embed(ctx, texts)
+		if err != nil {
+			// Drop the blobs not fully embedded so the next update
+			// retries them.
+			for _, p := range todo[start:] {
+				delete(fresh, p.blob)
+			}
+			return start, err
+		}
+		for i, p := range batch {
+			fresh[p.blob].Chunks[p.chunk].Vector = vectors[i]
+		}
+	}
+	return len(todo), nil
+}
+
+// Search returns the limit snippets of the checkout at dir closest to
+// query, at most one per file. Files the index hasn't seen are not
+// searched; call Update first to include them.
+func (ix *Index) Search(ctx context.Context, dir, query string, limit int) ([]Hit, error) {
+	files, err := sourceFiles(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	known := ix.load().Blobs
+	if len(known) == 0 {
+		return nil, nil
+	}
+	q, err := ix.Embedder.Embed(ctx, []string{query[:min(len(query), queryBytes)]})
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		Hit
+		blob string
+	}
+	var ranked []scored
+	for _, f := range files {
+		b := known[f.blob]
+		if b == nil {
+			continue
+		}
+		var best *scored
+		for _, c := range b.Chunks {
+			if score := Cosine(q[0], c.Vector); best == nil || score > best.Score {
+				best = &scored{Hit{Path: f.path, StartLine: c.Start, EndLine: c.End, Score: score}, f.blob}
+			}
+		}
+		if best != nil {
+			ranked = append(ranked, *best)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+	ranked = ranked[:min(len(ranked), limit)]
+
+	blobs := make([]string, len(ranked))
+	for i, r := range ranked {
+		blobs[i] = r.blob
+	}
+	contents, err := readBlobs(ctx, dir, blobs)
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]Hit, len(ranked))
+	for i, r := range ranked {
+		lines := strings.Split(string(contents[r.blob]), "\n")
+		r.Content = strings.Join(lines[min(len(lines), r.StartLine-1):min(len(lines), r.EndLine)], "\n")
+		hits[i] = r.Hit
+	}
+	return hits, nil
+}
+
+// Markdown renders hits for a prompt.
+func Markdown(hits []Hit) string {
+	var s strings.Builder
+	for i, h := range hits {
+		if i > 0 {
+			s.WriteByte('\n')
+		}
+		fmt.Fprintf(&s, "### %s (lines %d-%d, similarity %.2f)\n\n```\n%s\n```\n", h.Path, h.StartLine, h.EndLine, h.Score, strings.TrimRight(h.Content, "\n"))
+	}
+	return s.String()
+}
+
+// load reads the index, best effort: a missing or unreadable index, or
+// one built with another model, is empty.
+func (ix *Index) load() *index {
+	d := &index{Model: ix.Embedder.Model(), Blobs: make(map[string]*blob)}
+	_ = store.WithReadLock(ix.Path, store.DefaultLockTimeout, func() error {
+		d = ix.read()
+		return nil
+	})
+	return d
+}
+
+func (ix *Index) read() *index {
+	empty := &index{Model: ix.Embedder.Model(), Blobs: make(map[string]*blob)}
+	f, err := os.Open(ix.Path)
+	if err != nil {
+		return empty
+	}
+	defer f.Close()
+	var d index
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(&d); err != nil || d.Model != empty.Model || d.Blobs == nil {
+		return empty
+	}
+	return &d
+}
+
+// save merges fresh blobs into the index, marks the blobs of files as
+// seen, and drops blobs unseen for too long. The index is rewritten only
+// if that changes it.
+func (ix *Index) save(fresh map[string]*blob, files []treeFile) error {
+	return store.WithLock(ix.Path, store.DefaultLockTimeout, func() error {
+		d := ix.read()
+		now := time.Now()
+		changed := len(fresh) > 0
+		for sha, b := range fresh {
+			b.Seen = now
+			d.Blobs[sha] = b
+		}
+		for _, f := range files {
+			if b := d.Blobs[f.blob]; b != nil && now.Sub(b.Seen) > seenRefresh {
+				b.Seen = now
+				changed = true
+			}
+		}
+		for sha, b := range d.Blobs {
+			if now.Sub(b.Seen) > retainUnseen {
+				delete(d.Blobs, sha)
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+
+		tmp := ix.Path + ".tmp"
+		f, err := os.Create(tmp)
+		if err != nil {
+			return err
+		}
+		w := bufio.NewWriter(f)
+		if err := gob.NewEncoder(w).Encode(d); err != nil {
+			f.Close()
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		return os.Rename(tmp, ix.Path)
+	})
+}
+
+// sourceFiles lists the source files in HEAD of the checkout at dir.
+func sourceFiles(ctx context.Context, dir string) ([]treeFile, error) {
+	cmd := exec.CommandContext(ctx, "git", "ls-tree", "-r", "-l", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-tree: %w", err)
+	}
+	var files []treeFile
+	for _, line := range strings.Split(string(out), "\n") {
+		// "<mode> blob <sha> <size>\t<path>"
+		meta, p, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 || fields[1] != "blob" || !IsSource(p) {
+			continue
+		}
+		if size, err := strconv.Atoi(fields[3]); err != nil || size > maxFileBytes {
+			continue
+		}
+		files = append(files, treeFile{path: p, blob: fields[2]})
+	}
+	return files, nil
+}
+
+// readBlobs reads blobs from the repo at dir with one git cat-file.
+func readBlobs(ctx context.Context, dir string, blobs []string) (map[string][]byte, error) {
+	contents := make(map[string][]byte, len(blobs))
+	if len(blobs) == 0 {
+		return contents, nil
+	}
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "--batch")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(strings.Join(blobs, "\n") + "\n")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+
+	r := bufio.NewReader(bytes.NewReader(out))
+	for {
+		// "<sha> <type> <size>\n<content>\n", or "<sha> missing\n"
+		header, err := r.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			return contents, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			continue
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("git cat-file: bad header %q", strings.TrimSpace(header))
+		}
+		data := make([]byte, size+1)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("git cat-file: %w", err)
+		}
+		contents[fields[0]] = data[:size]
+	}
+}
+
+// IsSource reports whether p looks like source code worth indexing.
+func IsSource(p string) bool {
+	switch path.Ext(p) {
+	case ".go", ".py", ".ts", ".tsx", ".js", ".jsx", ".java", ".kt", ".cs", ".rs", ".rb",
+		".c", ".h", ".cc", ".cpp", ".hpp", ".swift", ".scala", ".php", ".sh", ".ps1",
+		".yaml", ".yml", ".tf", ".sql", ".proto":
+		return true
+	}
+	return false
+}
+
+// Cosine is the cosine similarity of a and b.
+func Cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range min(len(a), len(b)) {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,114 @@
+package codesearch
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keywordEmbedder embeds texts by which of its keywords they mention.
+type keywordEmbedder struct {
+	model    string
+	keywords []string
+	texts    int
+}
+
+func (e *keywordEmbedder) Model() string { return e.model }
+
+func (e *keywordEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.texts += len(texts)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float32, len(e.keywords))
+		for k, kw := range e.keywords {
+			if strings.Contains(text, kw) {
+				vectors[i][k] = 1
+			}
+		}
+	}
+	return vectors, nil
+}
+
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	args = append([]string{"-C", dir, "-c", "user.email=t@t", "-c", "user.name=T"}, args...)
+	out, err := exec.Command("git", args...).CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func commitFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+	}
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-qm", "update")
+}
+
+func TestIndex(t *testing.T) {
+	dir := t.TempDir()
+	gitRun(t, dir, "init", "-q")
+
+	var billing strings.Builder
+	for i := 1; i <= 120; i++ {
+		if i == 110 {
+			billing.WriteString("func refund() {}\n")
+		} else {
+			billing.WriteString("// invoice\n")
+		}
+	}
+	commitFiles(t, dir, map[string]string{
+		"auth/login.go":       "package auth\n\nfunc login() {}\n",
+		"billing/invoice.go":  billing.String(),
+		"docs/refund.md":      "refund policy\n",
+		"billing/invoice.bin": "\x00\x01",
+	})
+
+	e := &keywordEmbedder{model: "test", keywords: []string{"login", "refund"}}
+	ix := &Index{Embedder: e, Path: filepath.Join(t.TempDir(), "repo.gob")}
+	ctx := context.Background()
+
+	stats, err := ix.Update(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Files: 2, Embedded: 4}, stats) // login.go, invoice.go lines 1-60, 51-110, 101-120
+
+	hits, err := ix.Search(ctx, dir, "where are refunds issued? refund", 1)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "billing/invoice.go", hits[0].Path)
+	assert.Equal(t, 51, hits[0].StartLine)
+	assert.Equal(t, 110, hits[0].EndLine)
+	assert.InDelta(t, 1.0, hits[0].Score, 0.001)
+	assert.True(t, strings.HasSuffix(hits[0].Content, "func refund() {}"))
+
+	// Only changed files are embedded again.
+	stats, err = ix.Update(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.Embedded)
+	commitFiles(t, dir, map[string]string{"auth/login.go": "package auth\n\nfunc login() { refund() }\n"})
+	stats, err = ix.Update(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Embedded)
+
+	hits, err = ix.Search(ctx, dir, "login", 5)
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.Equal(t, "auth/login.go", hits[0].Path)
+
+	// An index built with another model is rebuilt.
+	other := &Index{Embedder: &keywordEmbedder{model: "other"}, Path: ix.Path}
+	hits, err = other.Search(ctx, dir, "login", 5)
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+	stats, err = other.Update(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, 4, stats.Embedded)
+}
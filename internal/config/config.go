@@ -2,19 +2,28 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 
 	"dario.cat/mergo"
 	"github.com/tidwall/jsonc"
+
+	"github.com/alanmeadows/otto/internal/secrets"
 )
 
 // Load reads and merges configuration from user-level and repo-level JSONC files.
 // Resolution order: user config (~/.config/otto/otto.jsonc) → deep-merged with repo config (.otto/otto.jsonc).
 // An optional overridePath loads an additional layer that takes precedence over all file-based configs.
+// Secrets stored with `otto auth set` apply over the files, and environment
+// variables over everything.
 func Load(overridePath ...string) (*Config, error) {
 	cfg := DefaultConfig()
 
@@ -22,10 +31,12 @@ func Load(overridePath ...string) (*Config, error) {
 	userDir, err := os.UserConfigDir()
 	if err == nil {
 		userPath := filepath.Join(userDir, "otto", "otto.jsonc")
-		if userMap, err := loadJSONC(userPath); err == nil {
-			if err := mergeIntoConfig(&cfg, userMap); err != nil {
-				return nil, fmt.Errorf("merging user config: %w", err)
-			}
+		userMap, err := loadLayer(userPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := mergeIntoConfig(&cfg, userMap); err != nil {
+			return nil, fmt.Errorf("merging user config: %w", err)
 		}
 	}
 
@@ -33,30 +44,166 @@ func Load(overridePath ...string) (*Config, error) {
 	repoRoot := findRepoRoot()
 	if repoRoot != "" {
 		repoPath := filepath.Join(repoRoot, ".otto", "otto.jsonc")
-		if repoMap, err := loadJSONC(repoPath); err == nil {
-			if err := mergeIntoConfig(&cfg, repoMap); err != nil {
-				return nil, fmt.Errorf("merging repo config: %w", err)
-			}
+		repoMap, err := loadLayer(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := mergeIntoConfig(&cfg, repoMap); err != nil {
+			return nil, fmt.Errorf("merging repo config: %w", err)
 		}
 	}
 
 	// Load --config override (highest file precedence)
 	if len(overridePath) > 0 && overridePath[0] != "" {
-		if overrideMap, err := loadJSONC(overridePath[0]); err == nil {
-			if err := mergeIntoConfig(&cfg, overrideMap); err != nil {
-				return nil, fmt.Errorf("merging override config: %w", err)
-			}
-		} else {
+		if _, err := os.Stat(overridePath[0]); err != nil {
 			return nil, fmt.Errorf("loading override config %q: %w", overridePath[0], err)
 		}
+		overrideMap, err := loadLayer(overridePath[0])
+		if err != nil {
+			return nil, err
+		}
+		if err := mergeIntoConfig(&cfg, overrideMap); err != nil {
+			return nil, fmt.Errorf("merging override config: %w", err)
+		}
+	}
+
+	// Secrets stored with `otto auth set`
+	if err := applySecrets(&cfg); err != nil {
+		slog.Warn("could not read stored secrets", "error", err)
 	}
 
 	// Environment variable overrides
 	applyEnvOverrides(&cfg)
 
+	if err := cfg.Models.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.PR.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Server.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Dashboard.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Issues.Validate(); err != nil {
+		return nil, err
+	}
+	if err := ValidateSchedules(cfg.Schedules); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
 
+// Paths returns the config files Load reads, whether or not they exist:
+// the user config and, inside a git repository, the repo config.
+func Paths() []string {
+	var paths []string
+	if userDir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(userDir, "otto", "otto.jsonc"))
+	}
+	if repoRoot := findRepoRoot(); repoRoot != "" {
+		paths = append(paths, filepath.Join(repoRoot, ".otto", "otto.jsonc"))
+	}
+	return paths
+}
+
+// applySecrets sets the config keys stored in the secrets store over the
+// file-based layers.
+func applySecrets(cfg *Config) error {
+	store, err := secrets.Open(cfg.Secrets.Backend)
+	if err != nil {
+		return err
+	}
+	stored, err := store.List()
+	if err != nil {
+		return err
+	}
+	names := slices.Sorted(maps.Keys(stored))
+	for _, name := range names {
+		value, err := store.Get(name)
+		if err != nil {
+			slog.Warn("skipping stored secret", "key", name, "error", err)
+			continue
+		}
+		if err := mergeIntoConfig(cfg, nestedValue(name, value)); err != nil {
+			return fmt.Errorf("applying secret %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// nestedValue turns a dotted key and its value into nested maps.
+func nestedValue(key string, value any) map[string]any {
+	parts := strings.Split(key, ".")
+	m := map[string]any{parts[len(parts)-1]: value}
+	for i := len(parts) - 2; i >= 0; i-- {
+		m = map[string]any{parts[i]: m}
+	}
+	return m
+}
+
+// ValidateSecretKey checks that key names a string config value, such as
+// "pr.providers.github.token", that a stored secret can fill.
+func ValidateSecretKey(key string) error {
+	t := reflect.TypeOf(Config{})
+	parts := strings.Split(key, ".")
+	for i := 0; i < len(parts); i++ {
+		part := parts[i]
+		if part == "" {
+			return fmt.Errorf("invalid key %q", key)
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByJSONName(t, part)
+			if !ok {
+				return fmt.Errorf("unknown config key %q", key)
+			}
+			t = field.Type
+		case reflect.Map:
+			// The segment names a map entry, e.g. a provider.
+			t = t.Elem()
+		default:
+			return fmt.Errorf("unknown config key %q", key)
+		}
+	}
+	if t.Kind() != reflect.String {
+		return fmt.Errorf("config key %q is not a string value", key)
+	}
+	return nil
+}
+
+// fieldByJSONName finds the struct field serialized under name.
+func fieldByJSONName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == name && tag != "-" {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// loadLayer reads the config file at path for Load. A missing file is an
+// empty layer; a file with syntax or type errors fails the load with the
+// location of the first one.
+func loadLayer(path string) (map[string]any, error) {
+	issues, err := CheckFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := issueError(issues); err != nil {
+		return nil, err
+	}
+	return loadJSONC(path)
+}
+
 // loadJSONC reads a JSONC file and returns it as a map.
 func loadJSONC(path string) (map[string]any, error) {
 	data, err := os.ReadFile(path)
@@ -123,23 +270,27 @@ func findRepoRoot() string {
 	return strings.TrimSpace(string(out))
 }
 
+// envOverrides are the environment variables that set config keys, over
+// every other layer.
+var envOverrides = []struct{ env, key string }{
+	{"OTTO_ADO_PAT", "pr.providers.ado.pat"},
+	{"OTTO_ADO_CLIENT_SECRET", "pr.providers.ado.client_secret"},
+	{"GITHUB_TOKEN", "pr.providers.github.token"},
+	{"OTTO_JIRA_TOKEN", "jira.token"},
+	{"ANTHROPIC_API_KEY", "models.endpoints." + BackendAnthropic + ".api_key"},
+	{"OPENAI_API_KEY", "models.endpoints." + BackendOpenAI + ".api_key"},
+}
+
 // applyEnvOverrides applies environment variable overrides to the config.
 func applyEnvOverrides(cfg *Config) {
-	if pat := os.Getenv("OTTO_ADO_PAT"); pat != "" {
-		if cfg.PR.Providers == nil {
-			cfg.PR.Providers = make(map[string]ProviderConfig)
+	for _, o := range envOverrides {
+		value := os.Getenv(o.env)
+		if value == "" {
+			continue
 		}
-		ado := cfg.PR.Providers["ado"]
-		ado.PAT = pat
-		cfg.PR.Providers["ado"] = ado
-	}
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		if cfg.PR.Providers == nil {
-			cfg.PR.Providers = make(map[string]ProviderConfig)
+		if err := mergeIntoConfig(cfg, nestedValue(o.key, value)); err != nil {
+			slog.Warn("ignoring environment override", "env", o.env, "error", err)
 		}
-		gh := cfg.PR.Providers["github"]
-		gh.Token = token
-		cfg.PR.Providers["github"] = gh
 	}
 }
 
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"testing"
 	"time"
 )
@@ -117,6 +119,26 @@ func TestApplyEnvOverrides(t *testing.T) {
 	}
 }
 
+func TestApplyEnvOverrides_LLMKeys(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Models.Endpoints = map[string]EndpointConfig{
+		BackendOpenAI: {BaseURL: "https://llm.example.com/v1"},
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant")
+	t.Setenv("OPENAI_API_KEY", "sk-oai")
+
+	applyEnvOverrides(&cfg)
+
+	if got := cfg.Models.Endpoints[BackendAnthropic].APIKey; got != "sk-ant" {
+		t.Errorf("expected anthropic key=sk-ant, got %s", got)
+	}
+	openai := cfg.Models.Endpoints[BackendOpenAI]
+	if openai.APIKey != "sk-oai" || openai.BaseURL != "https://llm.example.com/v1" {
+		t.Errorf("expected openai key set and base_url kept, got %+v", openai)
+	}
+}
+
 func TestServerConfigParsePollInterval_Invalid(t *testing.T) {
 	s := ServerConfig{PollInterval: "not-a-duration"}
 	if s.ParsePollInterval() != 10*time.Minute {
@@ -210,3 +232,389 @@ func TestLoadMergesUserAndOverride(t *testing.T) {
 		t.Errorf("expected models.secondary=gpt-5.2-codex, got %s", cfg.Models.Secondary)
 	}
 }
+
+func TestPRConfigTimeouts(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.PR.ParseFixTimeout(); got != 15*time.Minute {
+		t.Errorf("expected fix timeout 15m, got %v", got)
+	}
+	if got := cfg.PR.ParseConflictTimeout(); got != 10*time.Minute {
+		t.Errorf("expected conflict timeout 10m, got %v", got)
+	}
+	if got := cfg.PR.ParseMerlinBotTimeout(); got != 10*time.Minute {
+		t.Errorf("expected merlinbot timeout 10m, got %v", got)
+	}
+
+	p := PRConfig{FixTimeout: "30m", ConflictTimeout: "bogus", MerlinBotTimeout: "-5m"}
+	if got := p.ParseFixTimeout(); got != 30*time.Minute {
+		t.Errorf("expected fix timeout 30m, got %v", got)
+	}
+	if got := p.ParseConflictTimeout(); got != DefaultConflictTimeout {
+		t.Errorf("expected fallback for invalid conflict timeout, got %v", got)
+	}
+	if got := p.ParseMerlinBotTimeout(); got != DefaultMerlinBotTimeout {
+		t.Errorf("expected fallback for negative merlinbot timeout, got %v", got)
+	}
+}
+
+func TestDashboardConfigValidate(t *testing.T) {
+	if err := (DashboardConfig{}).Validate(); err != nil {
+		t.Errorf("expected no admin token to validate, got %v", err)
+	}
+	if err := (DashboardConfig{AdminToken: "short"}).Validate(); err == nil {
+		t.Error("expected error for a short admin_token")
+	}
+	if err := (DashboardConfig{AdminToken: "0123456789abcdef"}).Validate(); err != nil {
+		t.Errorf("expected 16-character admin_token to validate, got %v", err)
+	}
+	if err := (DashboardConfig{TunnelProvider: "tailscale"}).Validate(); err != nil {
+		t.Errorf("expected tailscale tunnel provider to validate, got %v", err)
+	}
+	if err := (DashboardConfig{TunnelProvider: "frp"}).Validate(); err == nil {
+		t.Error("expected error for an unknown tunnel_provider")
+	}
+}
+
+func TestPRConfigValidate(t *testing.T) {
+	if err := DefaultConfig().PR.Validate(); err != nil {
+		t.Errorf("expected defaults to validate, got %v", err)
+	}
+	if err := (PRConfig{}).Validate(); err != nil {
+		t.Errorf("expected empty timeouts to validate, got %v", err)
+	}
+	if err := (PRConfig{FixTimeout: "soon"}).Validate(); err == nil {
+		t.Error("expected error for malformed fix_timeout")
+	}
+	if err := (PRConfig{ConflictTimeout: "0s"}).Validate(); err == nil {
+		t.Error("expected error for zero conflict_timeout")
+	}
+	if err := (PRConfig{Watch: []WatchRule{{Repo: "org/repo"}}}).Validate(); err == nil {
+		t.Error("expected error for watch rule without criteria")
+	}
+	if err := (PRConfig{Watch: []WatchRule{{Repo: "org/repo", Label: "otto"}}}).Validate(); err != nil {
+		t.Errorf("expected labeled watch rule to validate, got %v", err)
+	}
+	if err := (PRConfig{DefaultProvider: "gitlab"}).Validate(); err == nil {
+		t.Error("expected error for an unknown default_provider")
+	}
+	if err := (PRConfig{Watch: []WatchRule{{Provider: "ado-contoso", Label: "otto"}}}).Validate(); err == nil {
+		t.Error("expected error for a watch rule naming an unconfigured provider")
+	}
+	if err := (PRConfig{Providers: map[string]ProviderConfig{"ado": {Project: "Core"}}}).Validate(); err == nil {
+		t.Error("expected error for an ADO project without an organization")
+	}
+	instance := ProviderConfig{Type: "ado", Organization: "contoso"}
+	if err := (PRConfig{Providers: map[string]ProviderConfig{"ado-contoso": instance}}).Validate(); err != nil {
+		t.Errorf("expected ADO instance to validate, got %v", err)
+	}
+	if err := (PRConfig{Providers: map[string]ProviderConfig{"ado-contoso": {Type: "ado"}}}).Validate(); err == nil {
+		t.Error("expected error for ADO instance without an organization")
+	}
+	if err := (PRConfig{Providers: map[string]ProviderConfig{"gh-work": {Type: "github"}}}).Validate(); err == nil {
+		t.Error("expected error for an instance of a provider without instances")
+	}
+	instance.ClientSecret = "secret"
+	if err := (PRConfig{Providers: map[string]ProviderConfig{"ado-contoso": instance}}).Validate(); err == nil {
+		t.Error("expected error for ADO instance client secret without tenant and client IDs")
+	}
+	if got := (PRConfig{Providers: map[string]ProviderConfig{"ado-contoso": instance}}).ProviderType("ado-contoso"); got != "ado" {
+		t.Errorf("ProviderType(ado-contoso) = %q, want ado", got)
+	}
+	bot := BotHandler{Name: "coderabbit", Authors: []string{"coderabbitai"}}
+	if err := (PRConfig{Bots: []BotHandler{bot, bot}}).Validate(); err == nil {
+		t.Error("expected error for duplicate bot names")
+	}
+	if err := (PRConfig{Bots: []BotHandler{{Name: "sonar"}}}).Validate(); err == nil {
+		t.Error("expected error for bot without authors")
+	}
+	bot.Resolution = "sometimes"
+	if err := (PRConfig{Bots: []BotHandler{bot}}).Validate(); err == nil {
+		t.Error("expected error for unknown bot resolution")
+	}
+	if err := (PRConfig{WorkItems: WorkItemConfig{Patterns: []string{`#\d+`}}}).Validate(); err == nil {
+		t.Error("expected error for work item pattern without a capture group")
+	}
+	sp := ProviderConfig{TenantID: "t", ClientID: "c", ClientSecret: "s"}
+	if err := (PRConfig{Providers: map[string]ProviderConfig{"ado": sp}}).Validate(); err != nil {
+		t.Errorf("expected service principal auth to validate, got %v", err)
+	}
+	sp.ClientCertificatePath = "/keys/sp.pem"
+	if err := (PRConfig{Providers: map[string]ProviderConfig{"ado": sp}}).Validate(); err == nil {
+		t.Error("expected error for service principal with both a secret and a certificate")
+	}
+	if err := (PRConfig{Providers: map[string]ProviderConfig{"ado": {ClientSecret: "s"}}}).Validate(); err == nil {
+		t.Error("expected error for service principal without tenant and client IDs")
+	}
+	app := ProviderConfig{AppID: 1, InstallationID: 2, PrivateKeyPath: "/keys/otto.pem"}
+	if err := (PRConfig{Providers: map[string]ProviderConfig{"github": app}}).Validate(); err != nil {
+		t.Errorf("expected GitHub App auth to validate, got %v", err)
+	}
+	app.InstallationID = 0
+	if err := (PRConfig{Providers: map[string]ProviderConfig{"github": app}}).Validate(); err == nil {
+		t.Error("expected error for GitHub App auth without an installation ID")
+	}
+	if err := (PRConfig{RateLimits: map[string]RateLimitConfig{"*": {RequestsPerHour: 4000}, "ado/contoso": {Reserve: 50}}}).Validate(); err != nil {
+		t.Errorf("expected rate limits to validate, got %v", err)
+	}
+	if err := (PRConfig{RateLimits: map[string]RateLimitConfig{"github/org": {}}}).Validate(); err == nil {
+		t.Error("expected error for org-scoped GitHub rate limit")
+	}
+	if err := (PRConfig{RateLimits: map[string]RateLimitConfig{"ado": {RequestsPerHour: -1}}}).Validate(); err == nil {
+		t.Error("expected error for negative rate limit")
+	}
+	if err := (PRConfig{Reviewer: ReviewerConfig{Enabled: true}}).Validate(); err == nil {
+		t.Error("expected error for enabled reviewer without watch rules")
+	}
+	if err := (PRConfig{Reviewer: ReviewerConfig{Enabled: true, Watch: []WatchRule{{Repo: "org/repo"}}}}).Validate(); err != nil {
+		t.Errorf("expected repo-wide reviewer watch rule to validate, got %v", err)
+	}
+	if err := (PRConfig{Reviewer: ReviewerConfig{MinSeverity: "critical"}}).Validate(); err == nil {
+		t.Error("expected error for unknown reviewer min_severity")
+	}
+}
+
+func TestWorkItemConfigFind(t *testing.T) {
+	w := WorkItemConfig{}
+	got := w.Find("users/me/4321-fix-retries", "Retry transient errors\n\nFixes AB#1234, see #4321 and PR 77")
+	if want := []string{"1234", "4321"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	w.Patterns = []string{`JIRA-(\d+)`}
+	if got := w.Find("feature/JIRA-9-login"); !slices.Equal(got, []string{"9"}) {
+		t.Errorf("expected custom pattern match, got %v", got)
+	}
+}
+
+func TestModelsConfigRoles(t *testing.T) {
+	m := ModelsConfig{
+		Primary:      "claude-sonnet-4",
+		Tertiary:     "llama3.1",
+		Backend:      BackendAnthropic,
+		RoleBackends: map[string]string{RoleTertiary: BackendOllama},
+	}
+	if got := m.ModelFor(RoleTertiary); got != "llama3.1" {
+		t.Errorf("expected tertiary model llama3.1, got %s", got)
+	}
+	if got := m.BackendFor(RolePrimary); got != BackendAnthropic {
+		t.Errorf("expected primary on anthropic, got %s", got)
+	}
+	if got := m.BackendFor(RoleTertiary); got != BackendOllama {
+		t.Errorf("expected tertiary on ollama, got %s", got)
+	}
+	if got := (ModelsConfig{}).BackendFor(RolePrimary); got != BackendCopilot {
+		t.Errorf("expected copilot by default, got %s", got)
+	}
+}
+
+func TestModelsConfigValidate(t *testing.T) {
+	if err := DefaultConfig().Models.Validate(); err != nil {
+		t.Errorf("expected defaults to validate, got %v", err)
+	}
+	if err := (ModelsConfig{Backend: "gemini"}).Validate(); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+	if err := (ModelsConfig{RoleBackends: map[string]string{"quaternary": BackendOpenAI}}).Validate(); err == nil {
+		t.Error("expected error for unknown role")
+	}
+	if err := (ModelsConfig{RoleBackends: map[string]string{RoleSecondary: "bard"}}).Validate(); err == nil {
+		t.Error("expected error for unknown role backend")
+	}
+	if err := (ModelsConfig{Fallback: FallbackConfig{Chain: []string{RolePrimary, "backup"}}}).Validate(); err == nil {
+		t.Error("expected error for unknown fallback chain role")
+	}
+	if err := (ModelsConfig{Fallback: FallbackConfig{Retries: -1}}).Validate(); err == nil {
+		t.Error("expected error for negative retries")
+	}
+	if err := (ModelsConfig{Fallback: FallbackConfig{PromptTimeout: "later"}}).Validate(); err == nil {
+		t.Error("expected error for malformed prompt_timeout")
+	}
+}
+
+func TestIssuesConfigValidate(t *testing.T) {
+	if err := DefaultConfig().Issues.Validate(); err != nil {
+		t.Errorf("expected defaults to validate, got %v", err)
+	}
+	if err := (IssuesConfig{Enabled: true}).Validate(); err == nil {
+		t.Error("expected error for enabled without watch rules")
+	}
+	if err := (IssuesConfig{Enabled: true, Watch: []IssueWatchRule{{Repo: "org/repo"}}, Labels: []string{"bug"}}).Validate(); err != nil {
+		t.Errorf("expected valid config, got %v", err)
+	}
+	if err := (IssuesConfig{Labels: []string{"bug", " "}}).Validate(); err == nil {
+		t.Error("expected error for empty label")
+	}
+}
+
+func TestValidateSchedules(t *testing.T) {
+	valid := []ScheduledTask{
+		{Name: "nightly-rebase", Schedule: "0 2 * * *", Action: TaskRebase},
+		{Name: "lint", Schedule: "@weekly", Action: TaskCommand, Repo: "svc", Command: "make lint-fix"},
+	}
+	if err := ValidateSchedules(valid); err != nil {
+		t.Errorf("expected valid schedules, got %v", err)
+	}
+	for name, tasks := range map[string][]ScheduledTask{
+		"no name":         {{Schedule: "@daily", Action: TaskRebase}},
+		"duplicate name":  {valid[0], valid[0]},
+		"bad schedule":    {{Name: "x", Schedule: "0 25 * * *", Action: TaskRebase}},
+		"unknown action":  {{Name: "x", Schedule: "@daily", Action: "deploy"}},
+		"command no repo": {{Name: "x", Schedule: "@daily", Action: TaskCommand, Command: "make"}},
+	} {
+		if err := ValidateSchedules(tasks); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestFallbackConfigDurations(t *testing.T) {
+	f := DefaultConfig().Models.Fallback
+	if got := f.ParseRetryBackoff(); got != 10*time.Second {
+		t.Errorf("expected 10s retry backoff, got %v", got)
+	}
+	if got := f.ParsePromptTimeout(); got != 0 {
+		t.Errorf("expected no prompt timeout by default, got %v", got)
+	}
+	f.PromptTimeout = "4m"
+	if got := f.ParsePromptTimeout(); got != 4*time.Minute {
+		t.Errorf("expected 4m prompt timeout, got %v", got)
+	}
+}
+
+func TestFixBackoffConfig(t *testing.T) {
+	f := DefaultConfig().PR.FixBackoff
+	for attempts, want := range []time.Duration{0, 5 * time.Minute, 10 * time.Minute, 20 * time.Minute, 40 * time.Minute, time.Hour, time.Hour} {
+		if got := f.Delay(attempts); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+	if got := f.ParseHumanPushCooldown(); got != 30*time.Minute {
+		t.Errorf("expected 30m human push cooldown, got %v", got)
+	}
+
+	off := FixBackoffConfig{BaseDelay: "0s", HumanPushCooldown: "0s"}
+	if got := off.Delay(3); got != 0 {
+		t.Errorf("expected no delay when base_delay is 0s, got %v", got)
+	}
+	if got := off.ParseHumanPushCooldown(); got != 0 {
+		t.Errorf("expected no cooldown when human_push_cooldown is 0s, got %v", got)
+	}
+	if err := (PRConfig{FixBackoff: off}).Validate(); err != nil {
+		t.Errorf("expected zero backoffs to validate, got %v", err)
+	}
+	if err := (PRConfig{FixBackoff: FixBackoffConfig{MaxDelay: "-1m"}}).Validate(); err == nil {
+		t.Error("expected error for negative max_delay")
+	}
+	if err := (PRConfig{OnHumanPush: HumanPushPause}).Validate(); err != nil {
+		t.Errorf("expected on_human_push pause to validate, got %v", err)
+	}
+	if err := (PRConfig{OnHumanPush: "ignore"}).Validate(); err == nil {
+		t.Error("expected error for unknown on_human_push")
+	}
+}
+
+func TestTitleConvention(t *testing.T) {
+	tc := TitleConventionConfig{Enabled: true}
+	for title, want := range map[string]bool{
+		"feat: add retries":         true,
+		"fix(api): handle 429s":     true,
+		"refactor!: drop v1 client": true,
+		"Add retries":               false,
+		"feature: add retries":      false,
+		"fix:no space":              false,
+		"fix: ":                     false,
+		"Fix: capitalized type":     false,
+	} {
+		if got := tc.Conforms(title); got != want {
+			t.Errorf("Conforms(%q) = %v, want %v", title, got, want)
+		}
+	}
+	if (TitleConventionConfig{Types: []string{"story"}}).Conforms("feat: x") {
+		t.Error("expected configured types to replace the defaults")
+	}
+
+	for branch, want := range map[string]string{
+		"fix/retry-429":     "fix: Add retries",
+		"feature/retries":   "feat: Add retries",
+		"hotfix-123":        "fix: Add retries",
+		"users/dev/retries": "chore: Add retries",
+	} {
+		if got := tc.Prefix("Add retries", branch); got != want {
+			t.Errorf("Prefix(%q) = %q, want %q", branch, got, want)
+		}
+	}
+	if got := (TitleConventionConfig{Types: []string{"story"}}).Prefix("x", "fix/y"); got != "story: x" {
+		t.Errorf("Prefix with custom types = %q, want %q", got, "story: x")
+	}
+
+	if err := (PRConfig{TitleConvention: TitleConventionConfig{Types: []string{"Feat"}}}).Validate(); err == nil {
+		t.Error("expected error for an uppercase type")
+	}
+	if err := (PRConfig{Labels: []LabelRule{{Label: "docs"}}}).Validate(); err == nil {
+		t.Error("expected error for a label rule without paths")
+	}
+	if err := (PRConfig{Labels: []LabelRule{{Label: "docs", Paths: []string{"docs/**"}}}}).Validate(); err != nil {
+		t.Errorf("expected label rule to validate, got %v", err)
+	}
+}
+
+func TestPRConfigLogCacheBytes(t *testing.T) {
+	if got := DefaultConfig().PR.LogCacheBytes(); got != 64<<20 {
+		t.Errorf("expected 64 MiB default, got %d", got)
+	}
+	if got := (PRConfig{LogCacheMB: -1}).LogCacheBytes(); got != 0 {
+		t.Errorf("expected 0 for negative size, got %d", got)
+	}
+}
+
+func TestValidateSecretKey(t *testing.T) {
+	for _, key := range []string{"pr.providers.github.token", "pr.providers.ado.pat", "jira.token", "models.endpoints.anthropic.api_key"} {
+		if err := ValidateSecretKey(key); err != nil {
+			t.Errorf("ValidateSecretKey(%q) = %v", key, err)
+		}
+	}
+	for _, key := range []string{"pr.providers.github", "pr.max_fix_attempts", "pr.providers.github.nope", "jira..token"} {
+		if err := ValidateSecretKey(key); err == nil {
+			t.Errorf("ValidateSecretKey(%q) succeeded, want error", key)
+		}
+	}
+}
+
+func TestNestedValueMerges(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := mergeIntoConfig(&cfg, nestedValue("pr.providers.github.token", "ghp_x")); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.PR.Providers["github"].Token; got != "ghp_x" {
+		t.Errorf("token = %q, want ghp_x", got)
+	}
+}
+
+func TestDataDir(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", xdg)
+	dir, err := DataDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(xdg, "otto"); dir != want {
+		t.Errorf("with XDG_DATA_HOME: got %s, want %s", dir, want)
+	}
+
+	t.Setenv("XDG_DATA_HOME", "")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	local := t.TempDir()
+	t.Setenv("LOCALAPPDATA", local)
+	dir, err = DataDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(home, ".local", "share", "otto")
+	if runtime.GOOS == "windows" {
+		want = filepath.Join(local, "otto")
+	}
+	if dir != want {
+		t.Errorf("default: got %s, want %s", dir, want)
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// DataDir returns the directory otto keeps its state in: $XDG_DATA_HOME/otto
+// when XDG_DATA_HOME is set, otherwise %LOCALAPPDATA%\otto on Windows and
+// ~/.local/share/otto elsewhere.
+func DataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "otto"), nil
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+			return filepath.Join(dir, "otto"), nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err == nil && home == "" {
+		err = errors.New("home directory is empty")
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory; set $HOME or $XDG_DATA_HOME: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "otto"), nil
+}
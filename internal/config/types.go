@@ -1,10 +1,16 @@
 package config
 
 import (
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/alanmeadows/otto/internal/cron"
 )
 
 // ExpandHome replaces a leading "~/" in a path with the user's home directory.
@@ -32,12 +38,143 @@ type Config struct {
 	Server        ServerConfig        `json:"server"`
 	Dashboard     DashboardConfig     `json:"dashboard"`
 	Notifications NotificationsConfig `json:"notifications"`
+	Telemetry     TelemetryConfig     `json:"telemetry"`
+	Git           GitConfig           `json:"git"`
+	Jira          JiraConfig          `json:"jira"`
+	Issues        IssuesConfig        `json:"issues"`
+	Schedules     []ScheduledTask     `json:"schedules"`
+	Secrets       SecretsConfig       `json:"secrets"`
 }
 
-// ModelsConfig defines the LLM models used by the Copilot SDK.
+// ModelsConfig defines the LLM models and the backends that serve them.
 type ModelsConfig struct {
 	Primary   string `json:"primary"`
 	Secondary string `json:"secondary"`
+	Tertiary  string `json:"tertiary,omitempty"`
+
+	// Backend is the LLM backend used for every role unless overridden in
+	// RoleBackends: "copilot" (default), "opencode", "anthropic", "openai",
+	// or "ollama".
+	Backend      string            `json:"backend"`
+	RoleBackends map[string]string `json:"role_backends,omitempty"` // role ("primary", "secondary", "tertiary") -> backend
+
+	// Endpoints holds per-backend connection settings, keyed by backend name.
+	Endpoints map[string]EndpointConfig `json:"endpoints,omitempty"`
+
+	// Fallback controls retries and failover between roles when a prompt
+	// fails or times out.
+	Fallback FallbackConfig `json:"fallback"`
+}
+
+// FallbackConfig defines the retry/fallback chain for LLM prompts.
+type FallbackConfig struct {
+	Chain         []string `json:"chain"`                    // roles tried in order; roles without a model are skipped
+	Retries       int      `json:"retries"`                  // retries per role before falling back to the next
+	RetryBackoff  string   `json:"retry_backoff"`            // wait between retries of the same role
+	PromptTimeout string   `json:"prompt_timeout,omitempty"` // per-attempt deadline; empty uses the backend's own limit
+}
+
+// DefaultRetryBackoff is the wait between retries when retry_backoff is
+// empty or invalid.
+const DefaultRetryBackoff = 10 * time.Second
+
+// ParseRetryBackoff returns the retry backoff as a time.Duration.
+func (f FallbackConfig) ParseRetryBackoff() time.Duration {
+	return parsePositiveDuration(f.RetryBackoff, DefaultRetryBackoff)
+}
+
+// ParsePromptTimeout returns the per-attempt prompt deadline, or 0 if unset.
+func (f FallbackConfig) ParsePromptTimeout() time.Duration {
+	return parsePositiveDuration(f.PromptTimeout, 0)
+}
+
+// EndpointConfig holds connection settings for an LLM backend.
+type EndpointConfig struct {
+	BaseURL string `json:"base_url,omitempty"` // API root; empty uses the backend's default
+	APIKey  string `json:"api_key,omitempty"`
+}
+
+// Model roles. Each role names a model and may be served by its own backend.
+const (
+	RolePrimary   = "primary"
+	RoleSecondary = "secondary"
+	RoleTertiary  = "tertiary"
+)
+
+// LLM backend names accepted in models.backend and models.role_backends.
+const (
+	BackendCopilot   = "copilot"
+	BackendOpenCode  = "opencode"
+	BackendAnthropic = "anthropic"
+	BackendOpenAI    = "openai"
+	BackendOllama    = "ollama"
+)
+
+// ModelFor returns the model configured for role, or "" for an unknown role.
+func (m ModelsConfig) ModelFor(role string) string {
+	switch role {
+	case RolePrimary:
+		return m.Primary
+	case RoleSecondary:
+		return m.Secondary
+	case RoleTertiary:
+		return m.Tertiary
+	}
+	return ""
+}
+
+// BackendFor returns the backend that serves role.
+func (m ModelsConfig) BackendFor(role string) string {
+	if b := m.RoleBackends[role]; b != "" {
+		return b
+	}
+	if m.Backend != "" {
+		return m.Backend
+	}
+	return BackendCopilot
+}
+
+// Validate reports unknown roles or backend names and malformed fallback
+// settings.
+func (m ModelsConfig) Validate() error {
+	known := func(b string) bool {
+		switch b {
+		case BackendCopilot, BackendOpenCode, BackendAnthropic, BackendOpenAI, BackendOllama:
+			return true
+		}
+		return false
+	}
+	if m.Backend != "" && !known(m.Backend) {
+		return fmt.Errorf("invalid models.backend %q", m.Backend)
+	}
+	for role, b := range m.RoleBackends {
+		if role != RolePrimary && role != RoleSecondary && role != RoleTertiary {
+			return fmt.Errorf("invalid models.role_backends role %q", role)
+		}
+		if !known(b) {
+			return fmt.Errorf("invalid models.role_backends.%s %q", role, b)
+		}
+	}
+	for _, role := range m.Fallback.Chain {
+		if role != RolePrimary && role != RoleSecondary && role != RoleTertiary {
+			return fmt.Errorf("invalid models.fallback.chain role %q", role)
+		}
+	}
+	if m.Fallback.Retries < 0 {
+		return fmt.Errorf("invalid models.fallback.retries %d: must not be negative", m.Fallback.Retries)
+	}
+	for _, t := range []struct{ key, value string }{
+		{"models.fallback.retry_backoff", m.Fallback.RetryBackoff},
+		{"models.fallback.prompt_timeout", m.Fallback.PromptTimeout},
+	} {
+		if t.value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(t.value); err != nil || d <= 0 {
+			return fmt.Errorf("invalid %s %q: must be a positive duration", t.key, t.value)
+		}
+	}
+	return nil
 }
 
 // PRConfig holds PR lifecycle management settings.
@@ -46,6 +183,600 @@ type PRConfig struct {
 	MaxFixAttempts  int                       `json:"max_fix_attempts"`
 	DisableAIFooter bool                      `json:"disable_ai_footer,omitempty"` // omit "This response was generated by AI" footer from PR comments
 	Providers       map[string]ProviderConfig `json:"providers"`
+
+	// Per-operation deadlines, as Go durations (e.g. "15m"). They keep a
+	// stuck LLM session from blocking the monitoring loop indefinitely.
+	FixTimeout       string `json:"fix_timeout"`
+	ConflictTimeout  string `json:"conflict_timeout"`
+	MerlinBotTimeout string `json:"merlinbot_timeout"`
+
+	// FixBudgets caps fix attempts per failure category ("compile", "test",
+	// "lint", "code"), in addition to MaxFixAttempts. A missing or negative
+	// entry means no per-category cap; 0 disables code fixes for that
+	// category. Infrastructure failures are always retried with backoff.
+	FixBudgets map[string]int `json:"fix_budgets"`
+
+	// LogCacheMB bounds the on-disk build log cache, in megabytes.
+	LogCacheMB int `json:"log_cache_mb"`
+
+	// MaxFlakyRetries caps how many times in a row a PR whose only failures
+	// are known flaky tests is retried without LLM analysis. A negative
+	// value disables the shortcut.
+	MaxFlakyRetries int `json:"max_flaky_retries"`
+
+	// FixPerBuild gives each failed build its own analysis and fix, each
+	// committed separately, instead of one analysis of all failed builds'
+	// logs, so one noisy pipeline can't drown out another's error.
+	FixPerBuild bool `json:"fix_per_build,omitempty"`
+
+	// CancelDoomedBuilds cancels a running build as soon as one of its
+	// tasks or jobs fails, so the fix starts without waiting out the rest
+	// of a long pipeline.
+	CancelDoomedBuilds bool `json:"cancel_doomed_builds,omitempty"`
+
+	// PostDiagnosisComments posts FixPR's build failure diagnosis on the PR
+	// as a collapsed comment, so reviewers can see what otto concluded.
+	PostDiagnosisComments bool `json:"post_diagnosis_comments,omitempty"`
+
+	// RiskAnalysis analyzes a PR's diff when it is added or submitted: its
+	// size, the areas it touches, risky changes such as migrations or auth
+	// code, and changed code without test changes. The report goes in the
+	// PR document, the submitted PR's description, and a pr_tracked
+	// notification.
+	RiskAnalysis bool `json:"risk_analysis"`
+
+	// SuggestCommentFixes posts the fix for an agreed review comment as a
+	// suggestion in the reply ("```suggestion" on GitHub, a patch on ADO)
+	// instead of committing and pushing it.
+	SuggestCommentFixes bool `json:"suggest_comment_fixes,omitempty"`
+
+	// MaxValidationLoops caps how many times a fix whose repo checks fail is
+	// sent back to the LLM before the fix is committed anyway. A negative
+	// value runs the checks once without asking for further fixes.
+	MaxValidationLoops int `json:"max_validation_loops"`
+
+	// Policy limits what automated commits may change.
+	Policy PolicyConfig `json:"policy"`
+
+	// WorkOnDrafts lets the daemon fix, rebase, and answer comments on draft
+	// PRs. By default drafts are only watched.
+	WorkOnDrafts bool `json:"work_on_drafts,omitempty"`
+
+	// DeleteBranchOnMerge deletes the PR's source branch from origin once
+	// the daemon sees the PR merged.
+	DeleteBranchOnMerge bool `json:"delete_branch_on_merge,omitempty"`
+
+	// Watch rules make the daemon track matching open PRs on its own,
+	// without an otto pr add for each.
+	Watch []WatchRule `json:"watch,omitempty"`
+
+	// Bots registers automated reviewers whose comments are evaluated in
+	// one batch instead of answered one by one. providers.ado.merlinbot,
+	// providers.github.copilot_review, and providers.github.coderabbit add
+	// built-in handlers; a bot of the same name replaces a built-in one.
+	Bots []BotHandler `json:"bots,omitempty"`
+
+	// FixBackoff spaces out fix attempts on a PR and holds them off while
+	// someone else is pushing to its branch.
+	FixBackoff FixBackoffConfig `json:"fix_backoff"`
+
+	// OnHumanPush is what the daemon does when someone other than otto
+	// pushes to a PR's branch: cooldown (default) holds fixes off for
+	// fix_backoff.human_push_cooldown, reset also starts the PR's fix
+	// attempts over, and pause pauses the PR until it is resumed.
+	OnHumanPush string `json:"on_human_push,omitempty"`
+
+	// WorktreePool keeps fix worktrees per branch between poll cycles
+	// instead of checking out a fresh one for every operation.
+	WorktreePool WorktreePoolConfig `json:"worktree_pool"`
+
+	// ContextPack puts likely relevant repo context in fix and comment
+	// prompts up front.
+	ContextPack ContextPackConfig `json:"context_pack"`
+
+	// RateLimits budgets provider API requests, keyed by "*" (all
+	// requests), a provider name, or "ado/<organization>".
+	RateLimits map[string]RateLimitConfig `json:"rate_limits,omitempty"`
+
+	// WorkItems links work items (ADO) or issues (GitHub) referenced in
+	// the branch name or commit messages to PRs created by otto pr submit.
+	WorkItems WorkItemConfig `json:"work_items"`
+
+	// Labels add labels (GitHub) or tags (ADO) to PRs created by otto pr
+	// submit, by the paths their changes touch.
+	Labels []LabelRule `json:"labels,omitempty"`
+
+	// TitleConvention makes otto pr submit's titles follow Conventional
+	// Commits, e.g. "feat: add retries" or "fix(api): handle 429s".
+	TitleConvention TitleConventionConfig `json:"title_convention"`
+
+	// Reviewer makes otto review other people's PRs: the daemon reviews
+	// PRs matching its watch rules once each, and otto pr review
+	// --as-reviewer applies the same criteria to a single PR.
+	Reviewer ReviewerConfig `json:"reviewer"`
+}
+
+// WatchRule selects open PRs in a repository (or a whole ADO project) for
+// automatic tracking. A PR must satisfy every criterion that is set;
+// Authors matches any one of its entries. Use several rules to watch PRs
+// that match one criterion or another.
+type WatchRule struct {
+	Provider     string   `json:"provider,omitempty"`      // default: pr.default_provider
+	Repo         string   `json:"repo,omitempty"`          // "owner/repo" on GitHub, repo name on ADO; empty = whole ADO project
+	Authors      []string `json:"authors,omitempty"`       // logins or ADO unique/display names; "@me" = yourself
+	Label        string   `json:"label,omitempty"`
+	BranchPrefix string   `json:"branch_prefix,omitempty"` // source branch prefix, without refs/heads/
+}
+
+// BotHandler describes an automated reviewer. Its comments are kept out of
+// regular comment handling and evaluated together with Prompt, which must
+// answer in the THREAD/REASON/ACTION format of merlinbot-evaluate.md.
+type BotHandler struct {
+	Name              string   `json:"name"`
+	Provider          string   `json:"provider,omitempty"`            // empty = every provider
+	Authors           []string `json:"authors"`                       // case-insensitive substrings of the comment author
+	NoFeedbackPhrases []string `json:"no_feedback_phrases,omitempty"` // a comment containing one means the bot has nothing to flag
+	Prompt            string   `json:"prompt,omitempty"`              // prompt template name; default bot-evaluate.md
+	Resolution        string   `json:"resolution,omitempty"`          // which evaluated threads to resolve: all (default), fixed, or none
+	InlineOnly        bool     `json:"inline_only,omitempty"`         // evaluate only comments on a file line, not summary posts
+}
+
+// Bot handler resolution policies.
+const (
+	BotResolveAll   = "all"   // resolve every evaluated thread
+	BotResolveFixed = "fixed" // resolve fixed threads; leave rebuttals open for a human
+	BotResolveNone  = "none"  // reply only
+)
+
+// Reactions to a human push (pr.on_human_push).
+const (
+	HumanPushCooldown = "cooldown" // hold fixes off for the cooldown
+	HumanPushReset    = "reset"    // hold fixes off and reset fix attempts
+	HumanPushPause    = "pause"    // pause the PR until resumed
+)
+
+// WorktreePoolConfig bounds the pool of cached per-branch worktrees.
+type WorktreePoolConfig struct {
+	Enabled   bool   `json:"enabled,omitempty"`
+	MaxDiskMB int    `json:"max_disk_mb"` // total size before least recently used worktrees are removed
+	MaxIdle   string `json:"max_idle"`    // Go duration a worktree may sit unused before removal
+}
+
+// ContextPackConfig controls the repo context otto assembles for fix and
+// comment-evaluation prompts — the directory tree, recent commits, and
+// excerpts of the files a failure or comment points at — so the LLM
+// starts from them instead of discovering them through tools.
+type ContextPackConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	MaxKB   int  `json:"max_kb,omitempty"` // size budget of a pack; default 48
+
+	// EmbeddingModel, when set, also ranks the repo's files by similarity
+	// to the failure or comment, with this model served by the
+	// EmbeddingBackend endpoint of models.endpoints.
+	EmbeddingModel   string `json:"embedding_model,omitempty"`
+	EmbeddingBackend string `json:"embedding_backend,omitempty"` // openai (default) or ollama
+}
+
+// DefaultContextPackMaxKB is the size budget of a context pack when
+// max_kb is unset.
+const DefaultContextPackMaxKB = 48
+
+// MaxBytes returns the size budget of a context pack in bytes.
+func (c ContextPackConfig) MaxBytes() int {
+	if c.MaxKB <= 0 {
+		return DefaultContextPackMaxKB * 1024
+	}
+	return c.MaxKB * 1024
+}
+
+// FixBackoffConfig sets the waits between a PR's fix attempts. Each is a Go
+// duration; "0s" turns the wait off.
+type FixBackoffConfig struct {
+	BaseDelay         string `json:"base_delay"`          // wait after the first fix attempt, doubling after each further one
+	MaxDelay          string `json:"max_delay"`           // ceiling on the doubled wait
+	HumanPushCooldown string `json:"human_push_cooldown"` // wait after someone other than otto pushes to the branch
+}
+
+// RateLimitConfig is a request budget for one rate limit key.
+type RateLimitConfig struct {
+	RequestsPerHour int `json:"requests_per_hour,omitempty"` // 0 = no hourly budget
+	Reserve         int `json:"reserve,omitempty"`           // pause when the provider reports this many or fewer requests left
+}
+
+// WorkItemConfig controls work item and issue linking on PR creation.
+type WorkItemConfig struct {
+	Link           bool     `json:"link,omitempty"`
+	Patterns       []string `json:"patterns,omitempty"` // regexps whose first group is an ID; default DefaultWorkItemPatterns
+	ResolveOnMerge bool     `json:"resolve_on_merge"`   // resolve linked items when the PR merges
+}
+
+// LabelRule adds Label to a submitted PR whose changes touch a file
+// matching any of Paths, globs as in pr.policy.protected_paths.
+type LabelRule struct {
+	Label string   `json:"label"`
+	Paths []string `json:"paths"`
+}
+
+// TitleConventionConfig enforces Conventional Commits PR titles on otto pr
+// submit. A title that doesn't conform is reformatted by the LLM.
+type TitleConventionConfig struct {
+	Enabled bool     `json:"enabled,omitempty"`
+	Types   []string `json:"types,omitempty"` // allowed type prefixes; default DefaultTitleTypes
+}
+
+// DefaultTitleTypes are the Conventional Commits types allowed in PR
+// titles when title_convention.types is empty.
+var DefaultTitleTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// AllowedTypes returns the type prefixes a PR title may use.
+func (t TitleConventionConfig) AllowedTypes() []string {
+	if len(t.Types) == 0 {
+		return DefaultTitleTypes
+	}
+	return t.Types
+}
+
+// conventionalTitle splits a title into type, optional "(scope)" and "!",
+// and subject.
+var conventionalTitle = regexp.MustCompile(`^([a-z]+)(\([^()]+\))?!?: \S`)
+
+// Conforms reports whether title is "<type>[(scope)][!]: <subject>" with
+// one of the allowed types.
+func (t TitleConventionConfig) Conforms(title string) bool {
+	m := conventionalTitle.FindStringSubmatch(title)
+	return m != nil && slices.Contains(t.AllowedTypes(), m[1])
+}
+
+// branchTypes map branch name prefixes to the title types they suggest.
+var branchTypes = map[string]string{
+	"feature": "feat",
+	"bug":     "fix",
+	"bugfix":  "fix",
+	"hotfix":  "fix",
+	"doc":     "docs",
+}
+
+// Prefix makes title conform without the LLM: it is prefixed with the type
+// the branch name starts with ("fix/retry-429" gives fix), else chore, else
+// the first allowed type.
+func (t TitleConventionConfig) Prefix(title, branch string) string {
+	allowed := t.AllowedTypes()
+	typ, _, _ := strings.Cut(strings.ToLower(branch), "/")
+	typ, _, _ = strings.Cut(typ, "-")
+	if alias, ok := branchTypes[typ]; ok {
+		typ = alias
+	}
+	if !slices.Contains(allowed, typ) {
+		typ = "chore"
+		if !slices.Contains(allowed, typ) {
+			typ = allowed[0]
+		}
+	}
+	return typ + ": " + strings.TrimSpace(title)
+}
+
+// DefaultWorkItemPatterns find "#123" and "AB#123" mentions and numeric
+// branch segments like "users/me/123-fix-retries".
+var DefaultWorkItemPatterns = []string{`#(\d+)\b`, `(?:^|/)(\d+)[-_]`}
+
+// Find returns the work item IDs the patterns match in texts, in order of
+// first mention. Invalid patterns are skipped; Validate reports them.
+func (w WorkItemConfig) Find(texts ...string) []string {
+	patterns := w.Patterns
+	if len(patterns) == 0 {
+		patterns = DefaultWorkItemPatterns
+	}
+	var ids []string
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil || re.NumSubexp() < 1 {
+			continue
+		}
+		for _, text := range texts {
+			for _, m := range re.FindAllStringSubmatch(text, -1) {
+				if m[1] != "" && !slices.Contains(ids, m[1]) {
+					ids = append(ids, m[1])
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// ReviewerConfig controls reviews of PRs otto did not author. Findings at
+// or above MinSeverity are posted as inline comments; a finding at or
+// above BlockingSeverity requests changes when RequestChanges is set, and
+// a review without one approves when Approve is set.
+type ReviewerConfig struct {
+	Enabled          bool        `json:"enabled,omitempty"`
+	Watch            []WatchRule `json:"watch,omitempty"`           // PRs the daemon reviews
+	MinSeverity      string      `json:"min_severity"`              // error, warning (default), or nitpick
+	BlockingSeverity string      `json:"blocking_severity"`         // error (default), warning, or nitpick
+	Approve          bool        `json:"approve,omitempty"`         // approve PRs with no blocking findings
+	RequestChanges   bool        `json:"request_changes,omitempty"` // request changes on PRs with blocking findings
+	Guidance         string      `json:"guidance,omitempty"`        // extra focus passed to every review
+}
+
+// Review severities, most serious first.
+var ReviewSeverities = []string{"error", "warning", "nitpick"}
+
+// DefaultWorktreePoolMaxIdle is used when max_idle is empty or invalid.
+const DefaultWorktreePoolMaxIdle = 72 * time.Hour
+
+// MaxBytes returns the pool's disk budget in bytes; non-positive values
+// yield 0, meaning no size bound.
+func (w WorktreePoolConfig) MaxBytes() int64 {
+	if w.MaxDiskMB <= 0 {
+		return 0
+	}
+	return int64(w.MaxDiskMB) << 20
+}
+
+// ParseMaxIdle returns how long a pooled worktree may sit unused.
+func (w WorktreePoolConfig) ParseMaxIdle() time.Duration {
+	return parsePositiveDuration(w.MaxIdle, DefaultWorktreePoolMaxIdle)
+}
+
+// PolicyConfig limits the changes otto pushes on its own. A push that
+// breaks a rule is held until a human approves it with "otto pr approve".
+type PolicyConfig struct {
+	// ProtectedPaths are globs (with ** for any number of directories)
+	// that automated commits may not touch, e.g. "deploy/prod/**".
+	ProtectedPaths []string `json:"protected_paths,omitempty"`
+	// MaxDiffLines caps inserted plus deleted lines in one push; 0 = no cap.
+	MaxDiffLines int `json:"max_diff_lines,omitempty"`
+	// MaxDiffFiles caps the files changed in one push; 0 = no cap.
+	MaxDiffFiles int `json:"max_diff_files,omitempty"`
+}
+
+// Enabled reports whether any policy rule is configured.
+func (p PolicyConfig) Enabled() bool {
+	return len(p.ProtectedPaths) > 0 || p.MaxDiffLines > 0 || p.MaxDiffFiles > 0
+}
+
+// LogCacheBytes returns the build log cache bound in bytes; non-positive
+// values yield 0, which the cache treats as its default.
+func (p PRConfig) LogCacheBytes() int64 {
+	if p.LogCacheMB <= 0 {
+		return 0
+	}
+	return int64(p.LogCacheMB) << 20
+}
+
+// Default fix backoff waits, used when the configured value is empty or
+// invalid.
+const (
+	DefaultFixBackoffBaseDelay = 5 * time.Minute
+	DefaultFixBackoffMaxDelay  = time.Hour
+	DefaultHumanPushCooldown   = 30 * time.Minute
+)
+
+// Delay returns the wait before the next fix attempt on a PR that has had
+// attempts fix attempts.
+func (f FixBackoffConfig) Delay(attempts int) time.Duration {
+	d := parseNonNegativeDuration(f.BaseDelay, DefaultFixBackoffBaseDelay)
+	ceiling := parseNonNegativeDuration(f.MaxDelay, DefaultFixBackoffMaxDelay)
+	if attempts <= 0 || d == 0 {
+		return 0
+	}
+	for i := 1; i < attempts && d < ceiling; i++ {
+		d *= 2
+	}
+	return min(d, ceiling)
+}
+
+// ParseHumanPushCooldown returns how long fixes wait after someone other
+// than otto pushes to a PR's branch.
+func (f FixBackoffConfig) ParseHumanPushCooldown() time.Duration {
+	return parseNonNegativeDuration(f.HumanPushCooldown, DefaultHumanPushCooldown)
+}
+
+// Default per-operation deadlines, used when the configured value is empty
+// or invalid.
+const (
+	DefaultFixTimeout       = 15 * time.Minute
+	DefaultConflictTimeout  = 10 * time.Minute
+	DefaultMerlinBotTimeout = 10 * time.Minute
+)
+
+// ParseFixTimeout returns the FixPR deadline as a time.Duration.
+func (p PRConfig) ParseFixTimeout() time.Duration {
+	return parsePositiveDuration(p.FixTimeout, DefaultFixTimeout)
+}
+
+// ParseConflictTimeout returns the ResolveConflicts deadline as a time.Duration.
+func (p PRConfig) ParseConflictTimeout() time.Duration {
+	return parsePositiveDuration(p.ConflictTimeout, DefaultConflictTimeout)
+}
+
+// ParseMerlinBotTimeout returns the MerlinBot handling deadline as a time.Duration.
+func (p PRConfig) ParseMerlinBotTimeout() time.Duration {
+	return parsePositiveDuration(p.MerlinBotTimeout, DefaultMerlinBotTimeout)
+}
+
+// Validate reports malformed or non-positive duration values and watch
+// rules that would match every PR. Empty durations are allowed and fall
+// back to the defaults.
+func (p PRConfig) Validate() error {
+	for _, t := range []struct{ key, value string }{
+		{"pr.fix_timeout", p.FixTimeout},
+		{"pr.conflict_timeout", p.ConflictTimeout},
+		{"pr.merlinbot_timeout", p.MerlinBotTimeout},
+		{"pr.worktree_pool.max_idle", p.WorktreePool.MaxIdle},
+	} {
+		if t.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(t.value)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", t.key, t.value, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("invalid %s %q: must be positive", t.key, t.value)
+		}
+	}
+	for _, t := range []struct{ key, value string }{
+		{"pr.fix_backoff.base_delay", p.FixBackoff.BaseDelay},
+		{"pr.fix_backoff.max_delay", p.FixBackoff.MaxDelay},
+		{"pr.fix_backoff.human_push_cooldown", p.FixBackoff.HumanPushCooldown},
+	} {
+		if t.value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(t.value); err != nil || d < 0 {
+			return fmt.Errorf("invalid %s %q: must be a duration of zero or more", t.key, t.value)
+		}
+	}
+	switch p.ContextPack.EmbeddingBackend {
+	case "", BackendOpenAI, BackendOllama:
+	default:
+		return fmt.Errorf("invalid pr.context_pack.embedding_backend %q: must be openai or ollama", p.ContextPack.EmbeddingBackend)
+	}
+	switch p.OnHumanPush {
+	case "", HumanPushCooldown, HumanPushReset, HumanPushPause:
+	default:
+		return fmt.Errorf("invalid pr.on_human_push %q: must be cooldown, reset, or pause", p.OnHumanPush)
+	}
+	if !p.knownProvider(p.DefaultProvider) {
+		return fmt.Errorf("invalid pr.default_provider %q: not ado, github, or a configured pr.providers entry", p.DefaultProvider)
+	}
+	for i, w := range p.Watch {
+		if len(w.Authors) == 0 && w.Label == "" && w.BranchPrefix == "" {
+			return fmt.Errorf("invalid pr.watch[%d]: needs authors, label, or branch_prefix", i)
+		}
+		if !p.knownProvider(w.Provider) {
+			return fmt.Errorf("invalid pr.watch[%d]: provider %q is not ado, github, or a configured pr.providers entry", i, w.Provider)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(p.Providers)) {
+		prov := p.Providers[name]
+		switch {
+		case prov.Type != "" && prov.Type != "ado":
+			return fmt.Errorf("invalid pr.providers.%s: type %q must be ado (the only provider with named instances)", name, prov.Type)
+		case prov.Type == "ado" && name == "github":
+			return fmt.Errorf("invalid pr.providers.github: type must not be ado")
+		case prov.Type == "ado" && name != "ado" && prov.Organization == "":
+			return fmt.Errorf("invalid pr.providers.%s: an ADO instance needs an organization", name)
+		case p.ProviderType(name) == "ado" && prov.Project != "" && prov.Organization == "":
+			return fmt.Errorf("invalid pr.providers.%s: project %q needs an organization", name, prov.Project)
+		}
+		if p.ProviderType(name) != "ado" || (prov.ClientSecret == "" && prov.ClientCertificatePath == "") {
+			continue
+		}
+		switch {
+		case prov.ClientSecret != "" && prov.ClientCertificatePath != "":
+			return fmt.Errorf("invalid pr.providers.%s: set client_secret or client_certificate_path, not both", name)
+		case prov.ManagedIdentity:
+			return fmt.Errorf("invalid pr.providers.%s: managed_identity does not take a client secret or certificate", name)
+		case prov.TenantID == "" || prov.ClientID == "":
+			return fmt.Errorf("invalid pr.providers.%s: service principal auth needs tenant_id and client_id", name)
+		}
+	}
+	if gh := p.Providers["github"]; (gh.AppID != 0 || gh.InstallationID != 0 || gh.PrivateKeyPath != "") &&
+		(gh.AppID <= 0 || gh.InstallationID <= 0 || gh.PrivateKeyPath == "") {
+		return fmt.Errorf("invalid pr.providers.github: GitHub App auth needs app_id, installation_id, and private_key_path")
+	}
+	for key, l := range p.RateLimits {
+		name, org, scoped := strings.Cut(key, "/")
+		if key != "*" && (name == "" || (scoped && (name != "ado" || org == ""))) {
+			return fmt.Errorf("invalid pr.rate_limits key %q: want *, a provider name, or ado/<organization>", key)
+		}
+		if l.RequestsPerHour < 0 || l.Reserve < 0 {
+			return fmt.Errorf("invalid pr.rate_limits.%s: values must not be negative", key)
+		}
+	}
+	for i, pattern := range p.WorkItems.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pr.work_items.patterns[%d]: %w", i, err)
+		}
+		if re.NumSubexp() < 1 {
+			return fmt.Errorf("invalid pr.work_items.patterns[%d] %q: needs a group capturing the ID", i, pattern)
+		}
+	}
+	for i, l := range p.Labels {
+		if l.Label == "" || len(l.Paths) == 0 {
+			return fmt.Errorf("invalid pr.labels[%d]: needs a label and paths", i)
+		}
+	}
+	for _, typ := range p.TitleConvention.Types {
+		if typ == "" || strings.Trim(typ, "abcdefghijklmnopqrstuvwxyz") != "" {
+			return fmt.Errorf("invalid pr.title_convention.types entry %q: must be lowercase letters", typ)
+		}
+	}
+	if p.Reviewer.Enabled && len(p.Reviewer.Watch) == 0 {
+		return fmt.Errorf("invalid pr.reviewer: enabled needs at least one watch rule")
+	}
+	for i, w := range p.Reviewer.Watch {
+		if len(w.Authors) == 0 && w.Label == "" && w.BranchPrefix == "" && w.Repo == "" {
+			return fmt.Errorf("invalid pr.reviewer.watch[%d]: needs repo, authors, label, or branch_prefix", i)
+		}
+	}
+	for _, t := range []struct{ key, value string }{
+		{"pr.reviewer.min_severity", p.Reviewer.MinSeverity},
+		{"pr.reviewer.blocking_severity", p.Reviewer.BlockingSeverity},
+	} {
+		if t.value != "" && !slices.Contains(ReviewSeverities, t.value) {
+			return fmt.Errorf("invalid %s %q: must be error, warning, or nitpick", t.key, t.value)
+		}
+	}
+	names := make(map[string]bool)
+	for i, b := range p.Bots {
+		switch {
+		case b.Name == "":
+			return fmt.Errorf("invalid pr.bots[%d]: needs a name", i)
+		case names[b.Name]:
+			return fmt.Errorf("invalid pr.bots[%d]: duplicate name %q", i, b.Name)
+		case len(b.Authors) == 0:
+			return fmt.Errorf("invalid pr.bots[%d] (%s): needs authors", i, b.Name)
+		}
+		switch b.Resolution {
+		case "", BotResolveAll, BotResolveFixed, BotResolveNone:
+		default:
+			return fmt.Errorf("invalid pr.bots[%d] (%s): resolution %q must be all, fixed, or none", i, b.Name, b.Resolution)
+		}
+		names[b.Name] = true
+	}
+	return nil
+}
+
+// ProviderType returns the provider type of the pr.providers entry name:
+// its type field for a named ADO instance, otherwise the name itself.
+func (p PRConfig) ProviderType(name string) string {
+	if t := p.Providers[name].Type; t != "" {
+		return t
+	}
+	return name
+}
+
+// parsePositiveDuration parses s, returning def if s is empty, malformed,
+// or not positive.
+func parsePositiveDuration(s string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// parseNonNegativeDuration is parsePositiveDuration for settings where zero
+// turns something off.
+func parseNonNegativeDuration(s string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return def
+	}
+	return d
+}
+
+// knownProvider reports whether name is empty, a built-in provider, or a
+// configured pr.providers entry.
+func (p PRConfig) knownProvider(name string) bool {
+	if name == "" || name == "ado" || name == "github" {
+		return true
+	}
+	_, ok := p.Providers[name]
+	return ok
 }
 
 // ProviderConfig holds provider-specific PR settings (ADO, GitHub).
@@ -53,6 +784,11 @@ type PRConfig struct {
 // since the providers map is keyed by provider name ("ado", "github") and a single struct
 // simplifies the JSON schema and deep merge logic.
 type ProviderConfig struct {
+	// Type makes the entry a named instance of a provider, e.g. a second
+	// ADO organization with its own credentials under "ado-contoso". Only
+	// "ado" has instances; empty means the entry's key is the provider.
+	Type string `json:"type,omitempty"`
+
 	// ADO fields
 	Organization   string `json:"organization,omitempty"`
 	Project        string `json:"project,omitempty"`
@@ -62,9 +798,48 @@ type ProviderConfig struct {
 	CreateWorkItem bool   `json:"create_work_item,omitempty"`
 	// WorkItemAreaPath is the ADO area path for created work items (e.g., "One\\Compute\\AzLocal").
 	WorkItemAreaPath string `json:"work_item_area_path,omitempty"`
+	// TenantID, ClientID, and ClientSecret or ClientCertificatePath
+	// authenticate as an Entra service principal instead of via the Azure
+	// CLI; ManagedIdentity uses the host's identity (ClientID selects a
+	// user-assigned one).
+	TenantID              string `json:"tenant_id,omitempty"`
+	ClientID              string `json:"client_id,omitempty"`
+	ClientSecret          string `json:"client_secret,omitempty"`
+	ClientCertificatePath string `json:"client_certificate_path,omitempty"`
+	ManagedIdentity       bool   `json:"managed_identity,omitempty"`
 
 	// GitHub fields
 	Token string `json:"token,omitempty"`
+	// AppID, InstallationID, and PrivateKeyPath authenticate as a GitHub
+	// App installation instead of with Token.
+	AppID          int64  `json:"app_id,omitempty"`
+	InstallationID int64  `json:"installation_id,omitempty"`
+	PrivateKeyPath string `json:"private_key_path,omitempty"`
+	// CopilotReview and CodeRabbit enable the built-in bot handlers for
+	// those AI reviewers' comments.
+	CopilotReview bool `json:"copilot_review,omitempty"`
+	CodeRabbit    bool `json:"coderabbit,omitempty"`
+
+	// BaseURL overrides the provider API root (e.g. a GitHub Enterprise host
+	// or a local `otto mock-provider`). Empty uses the public service.
+	BaseURL string `json:"base_url,omitempty"`
+
+	// CheckFilter restricts which builds and checks count toward a PR's
+	// pipeline state, so optional or nightly ones don't trigger fixes.
+	CheckFilter CheckFilterConfig `json:"check_filter,omitempty"`
+}
+
+// CheckFilterConfig selects the pipeline checks otto watches and fixes.
+// Patterns match check (GitHub) or pipeline (ADO) names; * matches any run
+// of characters and ? any one.
+type CheckFilterConfig struct {
+	// RequiredOnly keeps only checks required for the PR to merge: GitHub
+	// required status checks, ADO blocking build policies.
+	RequiredOnly bool `json:"required_only,omitempty"`
+	// Include keeps only checks matching one of these patterns.
+	Include []string `json:"include,omitempty"`
+	// Exclude drops checks matching one of these patterns.
+	Exclude []string `json:"exclude,omitempty"`
 }
 
 // GitStrategy defines how otto manages branches/worktrees for a repo.
@@ -84,16 +859,44 @@ type RepoConfig struct {
 	GitStrategy    GitStrategy `json:"git_strategy"`
 	BranchTemplate string      `json:"branch_template"`
 	BranchPatterns []string    `json:"branch_patterns"`
+
+	// Checks are shell commands (build, unit tests, linters) run in the
+	// worktree after an LLM fix, before it is committed.
+	Checks []string `json:"checks,omitempty"`
+
+	// FetchDepth limits otto's fetches of PR branches to this many commits
+	// (git fetch --depth), for repos too large to fetch full history; 0
+	// fetches everything.
+	FetchDepth int `json:"fetch_depth,omitempty"`
+	// SparsePaths restricts the worktrees otto creates to these directories
+	// (cone-mode sparse checkout); empty checks out the whole tree.
+	SparsePaths []string `json:"sparse_paths,omitempty"`
+
+	// SearchIndex keeps an embeddings index of the repo's source for
+	// `otto search` and for fix and review prompts, embedded with
+	// pr.context_pack.embedding_model and updated as the daemon polls.
+	SearchIndex bool `json:"search_index,omitempty"`
 }
 
 // ServerConfig holds daemon settings.
 type ServerConfig struct {
 	PollInterval   string `json:"poll_interval"`
 	Port           int    `json:"port"`
-	LogDir         string `json:"log_dir"`
+	LogDir         string `json:"log_dir"`                    // empty = "logs" under the data directory (see DataDir)
 	SourceDir      string `json:"source_dir,omitempty"`       // path to otto source for dev upgrades (git pull && make install)
 	UpgradeChannel string `json:"upgrade_channel,omitempty"`  // "release" (default, go install @latest) or "main" (build from source_dir)
 	NoPRMonitoring bool   `json:"-"`                          // runtime-only: skip PR monitoring loop
+	// RecordCycles keeps the provider API traffic of the last N poll
+	// cycles for `otto pr replay`; 0 disables recording.
+	RecordCycles int `json:"record_cycles,omitempty"`
+	// AuthCheckInterval is how often provider credentials are validated
+	// between polls; AuthExpiryWarning is how long before a reported
+	// expiry to send a notification.
+	AuthCheckInterval string `json:"auth_check_interval,omitempty"`
+	AuthExpiryWarning string `json:"auth_expiry_warning,omitempty"`
+	// LockTimeout is how long the daemon and CLI wait for another process
+	// to release a PR or state file lock before giving up.
+	LockTimeout string `json:"lock_timeout,omitempty"`
 }
 
 // ParsePollInterval returns the poll interval as a time.Duration.
@@ -105,6 +908,61 @@ func (s ServerConfig) ParsePollInterval() time.Duration {
 	return d
 }
 
+// Validate reports malformed server durations. Empty values fall back to
+// the defaults.
+func (s ServerConfig) Validate() error {
+	for _, t := range []struct {
+		key, value string
+		zeroOK     bool
+	}{
+		{"server.poll_interval", s.PollInterval, false},
+		{"server.auth_check_interval", s.AuthCheckInterval, false},
+		{"server.auth_expiry_warning", s.AuthExpiryWarning, true},
+		{"server.lock_timeout", s.LockTimeout, false},
+	} {
+		if t.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(t.value)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", t.key, t.value, err)
+		}
+		if d < 0 || (d == 0 && !t.zeroOK) {
+			return fmt.Errorf("invalid %s %q: must be positive", t.key, t.value)
+		}
+	}
+	return nil
+}
+
+// AuthCheckIntervalDuration returns AuthCheckInterval, 15 minutes if unset
+// or invalid.
+func (s ServerConfig) AuthCheckIntervalDuration() time.Duration {
+	d, err := time.ParseDuration(s.AuthCheckInterval)
+	if err != nil || d <= 0 {
+		return 15 * time.Minute
+	}
+	return d
+}
+
+// AuthExpiryWarningDuration returns AuthExpiryWarning, 72 hours if unset
+// or invalid.
+func (s ServerConfig) AuthExpiryWarningDuration() time.Duration {
+	d, err := time.ParseDuration(s.AuthExpiryWarning)
+	if err != nil || d < 0 {
+		return 72 * time.Hour
+	}
+	return d
+}
+
+// LockTimeoutDuration returns LockTimeout, 15 seconds if unset or invalid.
+func (s ServerConfig) LockTimeoutDuration() time.Duration {
+	d, err := time.ParseDuration(s.LockTimeout)
+	if err != nil || d <= 0 {
+		return 15 * time.Second
+	}
+	return d
+}
+
 // DashboardConfig holds settings for the Copilot session dashboard.
 // The dashboard and tunnel are enabled by default and controlled at
 // runtime via --no-dashboard / --no-tunnel flags.
@@ -113,10 +971,13 @@ type DashboardConfig struct {
 	TunnelID        string   `json:"tunnel_id"`             // persistent tunnel name (e.g. "otto-dash"); empty = ephemeral
 	TunnelAccess    string   `json:"tunnel_access"`         // "anonymous", "tenant", or "authenticated" (default)
 	TunnelAllowOrg  string   `json:"tunnel_allow_org"`      // GitHub org to grant access (e.g. "my-org")
+	TunnelProvider  string   `json:"tunnel_provider,omitempty"` // "devtunnel" (default), "tailscale", or "ngrok"
+	TunnelDomain    string   `json:"tunnel_domain,omitempty"`   // ngrok reserved domain; empty = random
 	OwnerEmail      string   `json:"owner_email"`           // dashboard owner email (auto-detected from tunnel JWT if empty)
 	OwnerNickname   string   `json:"owner_nickname"`        // display name for session owner in chat bubbles (default: "owner")
 	AllowedUsers    []string `json:"allowed_users"`         // emails allowed full dashboard access (e.g. ["alice@microsoft.com"])
 	RequireKey      *bool    `json:"require_key,omitempty"` // require passcode for remote dashboard access (default: true)
+	AdminToken      string   `json:"admin_token,omitempty"` // fixed dashboard passcode; empty = random key per start
 
 	// Runtime-only fields (set by CLI flags or server startup, not persisted).
 	Enabled         bool   `json:"-"` // controlled by --no-dashboard
@@ -125,28 +986,221 @@ type DashboardConfig struct {
 	CopilotServerOverride string `json:"copilot_server,omitempty"` // user-managed server URL; empty = otto manages one
 }
 
+// minAdminTokenLen is the shortest dashboard admin token accepted.
+const minAdminTokenLen = 16
+
+// Validate checks the dashboard settings.
+func (d DashboardConfig) Validate() error {
+	switch d.TunnelProvider {
+	case "", "devtunnel", "tailscale", "ngrok":
+	default:
+		return fmt.Errorf("unknown dashboard.tunnel_provider %q (want devtunnel, tailscale, or ngrok)", d.TunnelProvider)
+	}
+	if d.AdminToken != "" && len(d.AdminToken) < minAdminTokenLen {
+		return fmt.Errorf("dashboard.admin_token must be at least %d characters", minAdminTokenLen)
+	}
+	return nil
+}
+
 // NotificationsConfig holds notification settings.
 type NotificationsConfig struct {
 	TeamsWebhookURL string   `json:"teams_webhook_url"`
 	Events          []string `json:"events"`
 }
 
+// TelemetryConfig holds OpenTelemetry tracing settings. When enabled, spans
+// for fix pipelines, conflict resolution, and provider calls are exported via
+// OTLP/HTTP. Standard OTEL_EXPORTER_OTLP_* environment variables are honored
+// when Endpoint is empty.
+type TelemetryConfig struct {
+	Enabled     bool              `json:"enabled"`
+	Endpoint    string            `json:"endpoint,omitempty"`     // OTLP/HTTP endpoint host:port (e.g. "localhost:4318")
+	URLPath     string            `json:"url_path,omitempty"`     // override the default /v1/traces path
+	Insecure    bool              `json:"insecure,omitempty"`     // use plain HTTP instead of HTTPS
+	Headers     map[string]string `json:"headers,omitempty"`      // extra request headers (e.g. auth tokens)
+	ServiceName string            `json:"service_name,omitempty"` // resource service.name (default: "otto")
+	SampleRatio float64           `json:"sample_ratio,omitempty"` // fraction of traces to sample, 0 < r <= 1 (default: 1)
+}
+
+// GitConfig sets the identity, and optionally the signature, of the
+// commits otto makes on its own. Empty fields fall back to the repository's
+// own git configuration.
+type GitConfig struct {
+	AuthorName  string `json:"author_name,omitempty"`  // author and committer name
+	AuthorEmail string `json:"author_email,omitempty"` // author and committer email
+	// SigningKey enables commit signing: a GPG key ID, or for the "ssh"
+	// format a public key path or literal "key::ssh-ed25519 ...".
+	SigningKey    string `json:"signing_key,omitempty"`
+	SigningFormat string `json:"signing_format,omitempty"` // "openpgp" (default), "ssh", or "x509"
+}
+
+// SecretsConfig controls where `otto auth set` stores credentials.
+type SecretsConfig struct {
+	// Backend is "keychain" (the OS keychain), "file" (an age-encrypted
+	// file), or empty for the keychain where one is available.
+	Backend string `json:"backend,omitempty"`
+}
+
+// JiraConfig connects otto to a Jira site. Issues whose keys appear in a
+// tracked PR's title or branch are moved to InReviewStatus when the PR goes
+// green and to DoneStatus when it merges.
+type JiraConfig struct {
+	BaseURL        string   `json:"base_url,omitempty"` // e.g. "https://example.atlassian.net"
+	Email          string   `json:"email,omitempty"`    // Jira Cloud account; empty sends Token as a bearer PAT
+	Token          string   `json:"token,omitempty"`    // API token or PAT (or OTTO_JIRA_TOKEN)
+	Projects       []string `json:"projects,omitempty"` // project keys to match; empty = any key
+	InReviewStatus string   `json:"in_review_status"`   // empty = no transition on green
+	DoneStatus     string   `json:"done_status"`        // empty = no transition on merge
+}
+
+// Enabled reports whether a Jira site is configured.
+func (j JiraConfig) Enabled() bool {
+	return j.BaseURL != "" && j.Token != ""
+}
+
+// IssuesConfig controls issue triage: the daemon reads the issues (GitHub)
+// and bugs (ADO) opened in the watched repositories, labels them, flags
+// duplicates of open ones, and drafts a reply or names the spec the work
+// belongs to. Issues opened before triage first runs are left alone.
+type IssuesConfig struct {
+	Enabled        bool             `json:"enabled,omitempty"`
+	Watch          []IssueWatchRule `json:"watch,omitempty"`   // repositories whose issues are triaged
+	Labels         []string         `json:"labels,omitempty"`  // labels triage may apply; empty = none
+	DuplicateLabel string           `json:"duplicate_label"`   // applied to duplicates; empty = none
+	Respond        bool             `json:"respond,omitempty"` // post the drafted reply as a comment
+}
+
+// IssueWatchRule names a repository whose new issues are triaged.
+type IssueWatchRule struct {
+	Provider string `json:"provider,omitempty"` // default: pr.default_provider
+	Repo     string `json:"repo,omitempty"`     // "owner/repo" on GitHub; ignored on ADO, which watches the project
+}
+
+// Validate checks the issue triage settings.
+func (c IssuesConfig) Validate() error {
+	if c.Enabled && len(c.Watch) == 0 {
+		return fmt.Errorf("invalid issues: enabled needs at least one watch rule")
+	}
+	for i, l := range c.Labels {
+		if strings.TrimSpace(l) == "" {
+			return fmt.Errorf("invalid issues.labels[%d]: empty label", i)
+		}
+	}
+	return nil
+}
+
+// Scheduled task actions.
+const (
+	// TaskRebase rebases the open PRs otto tracks onto their target
+	// branches, resolving conflicts as it does for a conflicted PR.
+	TaskRebase = "rebase"
+	// TaskCommand runs a shell command on a fresh branch off the target
+	// and opens a PR with whatever it changed.
+	TaskCommand = "command"
+)
+
+// ScheduledTask is recurring automation the daemon runs on a cron
+// schedule, in local time.
+type ScheduledTask struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`          // cron expression, e.g. "0 2 * * *" or "@weekly"
+	Action   string `json:"action"`            // rebase or command
+	Repo     string `json:"repo,omitempty"`    // repos entry; command: required, rebase: only its PRs
+	Command  string `json:"command,omitempty"` // shell command (command), e.g. "golangci-lint run --fix"
+	Target   string `json:"target,omitempty"`  // branch the PR targets (command); default: the remote's default branch
+	Title    string `json:"title,omitempty"`   // PR title (command); default: "chore: <name>"
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// ValidateSchedules checks the scheduled tasks.
+func ValidateSchedules(tasks []ScheduledTask) error {
+	names := make(map[string]bool)
+	for i, t := range tasks {
+		switch {
+		case t.Name == "":
+			return fmt.Errorf("invalid schedules[%d]: needs a name", i)
+		case names[t.Name]:
+			return fmt.Errorf("invalid schedules[%d]: duplicate name %q", i, t.Name)
+		}
+		names[t.Name] = true
+		if _, err := cron.Parse(t.Schedule); err != nil {
+			return fmt.Errorf("invalid schedules[%d] (%s): %w", i, t.Name, err)
+		}
+		switch t.Action {
+		case TaskRebase:
+		case TaskCommand:
+			if t.Repo == "" || strings.TrimSpace(t.Command) == "" {
+				return fmt.Errorf("invalid schedules[%d] (%s): command needs repo and command", i, t.Name)
+			}
+		default:
+			return fmt.Errorf("invalid schedules[%d] (%s): action %q must be rebase or command", i, t.Name, t.Action)
+		}
+	}
+	return nil
+}
+
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() Config {
 	return Config{
 		Models: ModelsConfig{
 			Primary:   "claude-opus-4.6",
 			Secondary: "gpt-5.2-codex",
+			Backend:   BackendCopilot,
+			Fallback: FallbackConfig{
+				Chain:        []string{RolePrimary, RoleSecondary, RoleTertiary},
+				Retries:      1,
+				RetryBackoff: "10s",
+			},
 		},
 		PR: PRConfig{
 			DefaultProvider: "ado",
 			MaxFixAttempts:  5,
 			Providers:       make(map[string]ProviderConfig),
+
+			FixTimeout:       "15m",
+			ConflictTimeout:  "10m",
+			MerlinBotTimeout: "10m",
+
+			FixBudgets: map[string]int{
+				"compile": 3,
+				"test":    2,
+				"lint":    1,
+			},
+
+			LogCacheMB: 64,
+
+			MaxFlakyRetries: 3,
+
+			MaxValidationLoops: 2,
+
+			RiskAnalysis: true,
+
+			FixBackoff: FixBackoffConfig{
+				BaseDelay:         "5m",
+				MaxDelay:          "1h",
+				HumanPushCooldown: "30m",
+			},
+
+			WorktreePool: WorktreePoolConfig{
+				MaxDiskMB: 10240,
+				MaxIdle:   "72h",
+			},
+
+			WorkItems: WorkItemConfig{
+				ResolveOnMerge: true,
+			},
+
+			Reviewer: ReviewerConfig{
+				MinSeverity:      "warning",
+				BlockingSeverity: "error",
+			},
 		},
 		Server: ServerConfig{
-			PollInterval: "10m",
-			Port:         4097,
-			LogDir:       "~/.local/share/otto/logs",
+			PollInterval:      "10m",
+			Port:              4097,
+			AuthCheckInterval: "15m",
+			AuthExpiryWarning: "72h",
+			LockTimeout:       "15s",
 		},
 		Dashboard: DashboardConfig{
 			Port:            4098,
@@ -155,6 +1209,17 @@ func DefaultConfig() Config {
 			AutoStartTunnel: true,
 			OwnerNickname:   "owner",
 		},
+		Telemetry: TelemetryConfig{
+			ServiceName: "otto",
+			SampleRatio: 1,
+		},
+		Jira: JiraConfig{
+			InReviewStatus: "In Review",
+			DoneStatus:     "Done",
+		},
+		Issues: IssuesConfig{
+			DuplicateLabel: "duplicate",
+		},
 	}
 }
 
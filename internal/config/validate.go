@@ -0,0 +1,338 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tidwall/jsonc"
+
+	"github.com/alanmeadows/otto/internal/secrets"
+)
+
+// Issue is a problem found in a config file, with its location.
+type Issue struct {
+	File    string
+	Line    int
+	Column  int
+	Key     string // dotted key path; empty for syntax errors
+	Message string
+	Warning bool // unknown keys are warnings, everything else an error
+}
+
+// String renders the issue as "file:line:col: severity: key: message".
+func (is Issue) String() string {
+	loc := is.File
+	if is.Line > 0 {
+		loc = fmt.Sprintf("%s:%d:%d", is.File, is.Line, is.Column)
+	}
+	severity := "error"
+	if is.Warning {
+		severity = "warning"
+	}
+	if is.Key == "" {
+		return fmt.Sprintf("%s: %s: %s", loc, severity, is.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", loc, severity, is.Key, is.Message)
+}
+
+// durationKeys are the setting names whose values are Go durations.
+var durationKeys = map[string]bool{
+	"retry_backoff":       true,
+	"prompt_timeout":      true,
+	"fix_timeout":         true,
+	"conflict_timeout":    true,
+	"merlinbot_timeout":   true,
+	"max_idle":            true,
+	"base_delay":          true,
+	"max_delay":           true,
+	"human_push_cooldown": true,
+	"poll_interval":       true,
+	"auth_check_interval": true,
+	"auth_expiry_warning": true,
+	"lock_timeout":        true,
+}
+
+var anyType = reflect.TypeOf((*any)(nil)).Elem()
+
+// CheckFile checks the config file at path against the Config schema:
+// syntax, unknown keys, value types, and durations. Cross-field rules,
+// such as provider credentials, are checked by Load once the layers are
+// merged.
+func CheckFile(path string) ([]Issue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return checkJSONC(path, data), nil
+}
+
+// checker walks a config file's tokens alongside the Config type.
+type checker struct {
+	file   string
+	data   []byte
+	issues []Issue
+}
+
+func checkJSONC(file string, data []byte) []Issue {
+	c := &checker{file: file, data: data}
+	// ToJSON blanks comments and trailing commas without moving anything,
+	// so offsets into js are offsets into data.
+	js := jsonc.ToJSON(data)
+
+	var top any
+	if err := json.Unmarshal(js, &top); err != nil {
+		var se *json.SyntaxError
+		if errors.As(err, &se) {
+			c.add(se.Offset, "", se.Error(), false)
+		} else {
+			c.add(0, "", err.Error(), false)
+		}
+		return c.issues
+	}
+	if _, ok := top.(map[string]any); !ok {
+		c.add(c.skip(0), "", "the config must be a JSON object", false)
+		return c.issues
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	c.value(dec, "", reflect.TypeOf(Config{}))
+	return c.issues
+}
+
+// value checks the next value in dec, found under key, against t.
+func (c *checker) value(dec *json.Decoder, key string, t reflect.Type) {
+	off := c.skip(dec.InputOffset())
+	tok, err := dec.Token()
+	if err != nil {
+		return // syntax errors are reported before the walk
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if tok == nil {
+		return // null leaves the setting unset
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '[' {
+			elem := anyType
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array:
+				elem = t.Elem()
+			case reflect.Interface:
+			default:
+				c.mismatch(off, key, t, "an array")
+			}
+			for i := 0; dec.More(); i++ {
+				c.value(dec, fmt.Sprintf("%s[%d]", key, i), elem)
+			}
+			_, _ = dec.Token()
+			return
+		}
+		switch t.Kind() {
+		case reflect.Struct, reflect.Map, reflect.Interface:
+		default:
+			c.mismatch(off, key, t, "an object")
+			t = anyType
+		}
+		for dec.More() {
+			keyOff := c.skip(dec.InputOffset())
+			kt, err := dec.Token()
+			if err != nil {
+				return
+			}
+			name, _ := kt.(string)
+			sub := name
+			if key != "" {
+				sub = key + "." + name
+			}
+			switch t.Kind() {
+			case reflect.Struct:
+				if f, ok := fieldByJSONName(t, name); ok {
+					c.value(dec, sub, f.Type)
+				} else {
+					c.add(keyOff, sub, "unknown key", true)
+					c.value(dec, sub, anyType)
+				}
+			case reflect.Map:
+				c.value(dec, sub, t.Elem())
+			default:
+				c.value(dec, sub, anyType)
+			}
+		}
+		_, _ = dec.Token()
+	case string:
+		switch t.Kind() {
+		case reflect.String:
+			if tok != "" && durationKeys[key[strings.LastIndex(key, ".")+1:]] {
+				if d, err := time.ParseDuration(tok); err != nil {
+					c.add(off, key, fmt.Sprintf("invalid duration %q (want e.g. \"90s\", \"15m\", \"2h\")", tok), false)
+				} else if d < 0 {
+					c.add(off, key, fmt.Sprintf("duration %q must not be negative", tok), false)
+				}
+			}
+		case reflect.Interface:
+		default:
+			c.mismatch(off, key, t, "a string")
+		}
+	case json.Number:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if _, err := tok.Int64(); err != nil {
+				c.add(off, key, fmt.Sprintf("expected an integer, got %s", tok), false)
+			}
+		case reflect.Float32, reflect.Float64, reflect.Interface:
+		default:
+			c.mismatch(off, key, t, "a number")
+		}
+	case bool:
+		if t.Kind() != reflect.Bool && t.Kind() != reflect.Interface {
+			c.mismatch(off, key, t, "a boolean")
+		}
+	}
+}
+
+func (c *checker) mismatch(off int64, key string, want reflect.Type, got string) {
+	c.add(off, key, fmt.Sprintf("expected %s, got %s", describeType(want), got), false)
+}
+
+// describeType names the JSON form of t.
+func describeType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	default:
+		return "an integer"
+	}
+}
+
+// skip advances off past the whitespace and separators before a token.
+func (c *checker) skip(off int64) int64 {
+	for off < int64(len(c.data)) {
+		switch c.data[off] {
+		case ' ', '\t', '\r', '\n', ':', ',':
+			off++
+			continue
+		}
+		break
+	}
+	return off
+}
+
+func (c *checker) add(off int64, key, msg string, warning bool) {
+	off = min(off, int64(len(c.data)))
+	before := c.data[:off]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := int(off) - (bytes.LastIndexByte(before, '\n') + 1) + 1
+	c.issues = append(c.issues, Issue{
+		File: c.file, Line: line, Column: col, Key: key, Message: msg, Warning: warning,
+	})
+}
+
+// issueError returns the first error in issues, or nil if there are only
+// warnings.
+func issueError(issues []Issue) error {
+	for _, is := range issues {
+		if !is.Warning {
+			return errors.New(is.String())
+		}
+	}
+	return nil
+}
+
+// Setting is one effective config value and the layer that set it.
+type Setting struct {
+	Key    string
+	Value  any
+	Source string
+}
+
+// Effective flattens cfg into its settings, sorted by key. Each is labeled
+// with the layer that set it last: "default", a config file, "secret" for
+// `otto auth set`, or "env NAME". Arrays are single settings. Callers
+// should pass a redacted cfg.
+func Effective(cfg *Config, overridePath ...string) ([]Setting, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	values := make(map[string]any)
+	flatten("", m, values)
+
+	sources := make(map[string]string)
+	paths := Paths()
+	if len(overridePath) > 0 && overridePath[0] != "" {
+		paths = append(paths, overridePath[0])
+	}
+	for _, path := range paths {
+		layer, err := loadJSONC(path)
+		if err != nil {
+			continue
+		}
+		keys := make(map[string]any)
+		flatten("", layer, keys)
+		for key := range keys {
+			sources[key] = path
+		}
+	}
+	if store, err := secrets.Open(cfg.Secrets.Backend); err == nil {
+		if stored, err := store.List(); err == nil {
+			for key := range stored {
+				sources[key] = "secret"
+			}
+		}
+	}
+	for _, o := range envOverrides {
+		if os.Getenv(o.env) != "" {
+			sources[o.key] = "env " + o.env
+		}
+	}
+
+	settings := make([]Setting, 0, len(values))
+	for key, value := range values {
+		source := sources[key]
+		if source == "" {
+			source = "default"
+		}
+		settings = append(settings, Setting{Key: key, Value: value, Source: source})
+	}
+	slices.SortFunc(settings, func(a, b Setting) int { return strings.Compare(a.Key, b.Key) })
+	return settings, nil
+}
+
+// flatten records the leaves of m under their dotted keys in out.
+func flatten(prefix string, m map[string]any, out map[string]any) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if sub, ok := v.(map[string]any); ok {
+			flatten(key, sub, out)
+			continue
+		}
+		out[key] = v
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "otto.jsonc")
+	data := `{
+  // comments are fine
+  "models": {"primary": "m", "primery": "typo"},
+  "server": {"port": "4097", "poll_interval": "5 minutes"},
+  "pr": {
+    "providers": {"ado": {"organization": "contoso", "pat": true}},
+    "watch": [{"label": "otto", "authors": "me"}],
+  },
+}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := CheckFile(path)
+	if err != nil {
+		t.Fatalf("CheckFile: %v", err)
+	}
+	want := []string{
+		path + ":3:30: warning: models.primery: unknown key",
+		path + ":4:22: error: server.port: expected an integer, got a string",
+		path + ":4:47: error: server.poll_interval: invalid duration \"5 minutes\"",
+		path + ":6:61: error: pr.providers.ado.pat: expected a string, got a boolean",
+		path + ":7:44: error: pr.watch[0].authors: expected an array, got a string",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %d: %v", len(want), len(issues), issues)
+	}
+	for i, is := range issues {
+		if !strings.HasPrefix(is.String(), want[i]) {
+			t.Errorf("issue %d: expected prefix %q, got %q", i, want[i], is.String())
+		}
+	}
+}
+
+func TestCheckFile_SyntaxError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "otto.jsonc")
+	if err := os.WriteFile(path, []byte("{\n  \"models\": {\"primary\" \"m\"}\n}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	issues, err := CheckFile(path)
+	if err != nil {
+		t.Fatalf("CheckFile: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Line != 2 || issues[0].Warning {
+		t.Fatalf("expected one error on line 2, got %v", issues)
+	}
+}
+
+func TestCheckFile_Defaults(t *testing.T) {
+	cfg := DefaultConfig()
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issues := checkJSONC("defaults", data); len(issues) != 0 {
+		t.Errorf("expected the default config to match the schema, got %v", issues)
+	}
+}
+
+func TestLoadFailsOnInvalidFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GIT_CEILING_DIRECTORIES", t.TempDir())
+
+	path := filepath.Join(t.TempDir(), "override.jsonc")
+	if err := os.WriteFile(path, []byte(`{"pr": {"fix_timeout": 15}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), path+":1:24") {
+		t.Errorf("expected a located error, got %v", err)
+	}
+}
+
+func TestEffective(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("GIT_CEILING_DIRECTORIES", t.TempDir())
+	t.Setenv("GITHUB_TOKEN", "gh-token")
+
+	path := filepath.Join(t.TempDir(), "override.jsonc")
+	if err := os.WriteFile(path, []byte(`{"models": {"primary": "m"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	settings, err := Effective(cfg, path)
+	if err != nil {
+		t.Fatalf("Effective: %v", err)
+	}
+
+	sources := make(map[string]string)
+	for _, s := range settings {
+		sources[s.Key] = s.Source
+	}
+	for key, want := range map[string]string{
+		"models.primary":            path,
+		"models.secondary":          "default",
+		"pr.providers.github.token": "env GITHUB_TOKEN",
+	} {
+		if sources[key] != want {
+			t.Errorf("%s: expected source %q, got %q", key, want, sources[key])
+		}
+	}
+}
@@ -0,0 +1,381 @@
+// Package contextpack assembles the repo context otto puts in fix and
+// comment-evaluation prompts: the repo's layout, its recent commits, and
+// excerpts of the files a failure or comment most likely concerns — the
+// files it names, the tests that failed, and, with an embedding model,
+// the files most similar to it. The LLM starts from the pack instead of
+// discovering those files through tools on every prompt.
+package contextpack
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/codesearch"
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+)
+
+const (
+	maxTreeLines    = 80      // directory lines in a pack's layout
+	maxCommits      = 10      // recent commits in a pack
+	maxMentions     = 10      // files named by the query that are excerpted
+	maxFailingTests = 5       // failing tests whose definitions are excerpted
+	excerptRadius   = 30      // lines around a known line in an excerpt
+	headLines       = 80      // lines from the top of a file without one
+	maxFileBytes    = 1 << 20 // larger files are never excerpted
+)
+
+// FileRef is a file, and optionally a line in it, known to matter.
+type FileRef struct {
+	Path   string
+	Line   int    // 0 = no particular line
+	Reason string // e.g. "commented on"
+}
+
+// Request describes what a pack is for.
+type Request struct {
+	// Query is what the prompt is about: build logs, a diagnosis, or a
+	// review comment. Files it names and tests it reports failing are
+	// excerpted, and it is what files are ranked by similarity to.
+	Query string
+	// Files are known to matter, e.g. the file a comment is on; they are
+	// excerpted first.
+	Files []FileRef
+	// MaxBytes bounds the pack's size; 0 uses the default budget.
+	MaxBytes int
+}
+
+// Excerpt is part of one file.
+type Excerpt struct {
+	Path      string `json:"path"`
+	Reason    string `json:"reason"` // why the file is in the pack
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Content   string `json:"content"`
+}
+
+// Pack is the context assembled for one prompt.
+type Pack struct {
+	Tree     string    `json:"tree"`
+	Commits  string    `json:"commits"`
+	Excerpts []Excerpt `json:"excerpts"`
+}
+
+// Builder assembles packs.
+type Builder struct {
+	// Embedder, if set, ranks the repo's files by similarity to the query.
+	Embedder llm.Embedder
+	// Index, if set, is searched for the snippets most similar to the
+	// query instead, as indexed: keeping it up to date is left to its
+	// owner, since a first index of a large repo takes a while.
+	Index *codesearch.Index
+	// CacheDir keeps file embeddings between packs, keyed by git blob;
+	// "" embeds every file each time.
+	CacheDir string
+}
+
+// New returns a Builder set up by cfg, with an embedder when cfg names an
+// embedding model that models can serve.
+func New(cfg config.ContextPackConfig, models config.ModelsConfig) *Builder {
+	b := &Builder{}
+	if dataDir, err := config.DataDir(); err == nil {
+		b.CacheDir = filepath.Join(dataDir, "contextpack")
+	}
+	if cfg.EmbeddingModel != "" {
+		e, err := llm.NewEmbedder(models, cfg.EmbeddingBackend, cfg.EmbeddingModel)
+		if err != nil {
+			slog.Warn("context pack embeddings disabled", "error", err)
+		} else {
+			b.Embedder = e
+		}
+	}
+	return b
+}
+
+// trackedFile is a file in the index.
+type trackedFile struct {
+	path string
+	blob string
+}
+
+// candidate is a file that may go in the pack.
+type candidate struct {
+	path   string
+	line   int
+	reason string
+}
+
+// Build assembles the pack for req from the git checkout at dir. Files
+// are excerpted in order of how directly req points at them — named in
+// req.Files, named in the query, a failing test's definition, similar to
+// the query — until the budget runs out.
+func (b *Builder) Build(ctx context.Context, dir string, req Request) (*Pack, error) {
+	budget := req.MaxBytes
+	if budget <= 0 {
+		budget = config.DefaultContextPackMaxKB * 1024
+	}
+	files, err := trackedFiles(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pack := &Pack{Tree: layout(files)}
+	if out, err := git(ctx, dir, "log", "-n", strconv.Itoa(maxCommits), "--format=%h %s (%an, %ar)"); err == nil {
+		pack.Commits = strings.TrimSpace(out)
+	}
+	budget -= len(pack.Tree) + len(pack.Commits)
+
+	var cands []candidate
+	seen := make(map[string]bool)
+	add := func(c candidate) {
+		if !seen[c.path] {
+			seen[c.path] = true
+			cands = append(cands, c)
+		}
+	}
+	for _, f := range req.Files {
+		if p := resolve(files, f.Path); p != "" {
+			add(candidate{path: p, line: f.Line, reason: f.Reason})
+		}
+	}
+	for _, m := range mentionedFiles(files, req.Query) {
+		add(m)
+	}
+	for _, t := range failingTests(ctx, dir, req.Query) {
+		add(t)
+	}
+	if strings.TrimSpace(req.Query) != "" {
+		var similar []candidate
+		var err error
+		switch {
+		case b.Index != nil:
+			similar, err = b.searchIndex(ctx, dir, req.Query)
+		case b.Embedder != nil:
+			similar, err = b.similarFiles(ctx, dir, files, seen, req.Query)
+		}
+		if err != nil {
+			slog.Warn("ranking files by embedding failed", "dir", dir, "error", err)
+		}
+		for _, c := range similar {
+			add(c)
+		}
+	}
+
+	for _, c := range cands {
+		if budget <= 0 {
+			break
+		}
+		ex, ok := excerpt(dir, c, budget)
+		if !ok {
+			continue
+		}
+		pack.Excerpts = append(pack.Excerpts, ex)
+		budget -= len(ex.Content)
+	}
+	return pack, nil
+}
+
+// Markdown renders the pack for a prompt.
+func (p *Pack) Markdown() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "### Layout\n\n```\n%s\n```\n", p.Tree)
+	if p.Commits != "" {
+		fmt.Fprintf(&s, "\n### Recent commits\n\n```\n%s\n```\n", p.Commits)
+	}
+	for _, ex := range p.Excerpts {
+		fmt.Fprintf(&s, "\n### %s (lines %d-%d, %s)\n\n```\n%s\n```\n", ex.Path, ex.StartLine, ex.EndLine, ex.Reason, strings.TrimRight(ex.Content, "\n"))
+	}
+	return s.String()
+}
+
+// trackedFiles lists the files in dir's index with their blobs.
+func trackedFiles(ctx context.Context, dir string) ([]trackedFile, error) {
+	out, err := git(ctx, dir, "ls-files", "-s")
+	if err != nil {
+		return nil, err
+	}
+	var files []trackedFile
+	for _, line := range strings.Split(out, "\n") {
+		// "<mode> <blob> <stage>\t<path>"
+		meta, p, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 || fields[0] == "160000" { // skip submodules
+			continue
+		}
+		files = append(files, trackedFile{path: p, blob: fields[1]})
+	}
+	return files, nil
+}
+
+// layout summarizes the repo's directories two levels deep, with their
+// file counts, and its top-level files.
+func layout(files []trackedFile) string {
+	counts := make(map[string]int)
+	var top []string
+	for _, f := range files {
+		parts := strings.Split(f.path, "/")
+		if len(parts) == 1 {
+			top = append(top, f.path)
+			continue
+		}
+		counts[parts[0]+"/"]++
+		if len(parts) > 2 {
+			counts[parts[0]+"/"+parts[1]+"/"]++
+		}
+	}
+	dirs := make([]string, 0, len(counts))
+	for d := range counts {
+		dirs = append(dirs, d)
+	}
+	sort.Strings(dirs)
+
+	var lines []string
+	for _, d := range dirs {
+		indent := ""
+		if strings.Count(d, "/") == 2 {
+			indent = "  "
+		}
+		lines = append(lines, fmt.Sprintf("%s%s (%d files)", indent, d, counts[d]))
+	}
+	lines = append(lines, top...)
+	if len(lines) > maxTreeLines {
+		lines = append(lines[:maxTreeLines], fmt.Sprintf("... (%d more)", len(lines)-maxTreeLines))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pathRef matches what looks like a file path, with an optional line.
+var pathRef = regexp.MustCompile(`[\w./\\-]*[\w-]\.[A-Za-z][A-Za-z0-9]{0,5}(?::(\d+))?`)
+
+// mentionedFiles returns the tracked files the query names, in order,
+// with the line named with each.
+func mentionedFiles(files []trackedFile, query string) []candidate {
+	var found []candidate
+	seen := make(map[string]bool)
+	for _, m := range pathRef.FindAllStringSubmatch(query, -1) {
+		ref := strings.SplitN(m[0], ":", 2)[0]
+		p := resolve(files, ref)
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		line, _ := strconv.Atoi(m[1])
+		found = append(found, candidate{path: p, line: line, reason: "mentioned"})
+		if len(found) == maxMentions {
+			break
+		}
+	}
+	return found
+}
+
+// resolve maps ref — repo-relative, or an absolute path from a CI agent's
+// checkout — to a tracked file, or "".
+func resolve(files []trackedFile, ref string) string {
+	ref = strings.TrimPrefix(strings.ReplaceAll(ref, `\`, "/"), "./")
+	if ref == "" {
+		return ""
+	}
+	var match string
+	for _, f := range files {
+		switch {
+		case f.path == ref:
+			return f.path
+		case strings.HasSuffix(ref, "/"+f.path), strings.HasSuffix(f.path, "/"+ref):
+			if match == "" || len(f.path) < len(match) {
+				match = f.path
+			}
+		}
+	}
+	return match
+}
+
+var failingTestPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`--- FAIL: (\w+)`),                 // go test
+	regexp.MustCompile(`FAILED [^\s:]+::(?:\w+::)?(\w+)`), // pytest
+}
+
+// failingTests finds the definitions of the tests the query reports
+// failing.
+func failingTests(ctx context.Context, dir, query string) []candidate {
+	var names []string
+	seen := make(map[string]bool)
+	for _, re := range failingTestPatterns {
+		for _, m := range re.FindAllStringSubmatch(query, -1) {
+			if !seen[m[1]] && len(names) < maxFailingTests {
+				seen[m[1]] = true
+				names = append(names, m[1])
+			}
+		}
+	}
+
+	var found []candidate
+	for _, name := range names {
+		out, err := git(ctx, dir, "grep", "-n", "-E", `(func|def) +(\([^)]*\) *)?`+regexp.QuoteMeta(name)+`\b`)
+		if err != nil {
+			continue
+		}
+		// "<path>:<line>:<text>"
+		first := strings.SplitN(strings.SplitN(out, "\n", 2)[0], ":", 3)
+		if len(first) < 3 {
+			continue
+		}
+		line, _ := strconv.Atoi(first[1])
+		found = append(found, candidate{path: first[0], line: line, reason: "failing test " + name})
+	}
+	return found
+}
+
+// excerpt reads the part of c's file that goes in the pack, at most
+// budget bytes: the lines around c.line, or the top of the file.
+func excerpt(dir string, c candidate, budget int) (Excerpt, bool) {
+	p := filepath.Join(dir, filepath.FromSlash(c.path))
+	info, err := os.Stat(p)
+	if err != nil || info.Size() > maxFileBytes {
+		return Excerpt{}, false
+	}
+	data, err := os.ReadFile(p)
+	if err != nil || bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 { // binary
+		return Excerpt{}, false
+	}
+	lines := strings.Split(string(data), "\n")
+	start, end := 1, min(len(lines), headLines)
+	if c.line > 0 {
+		start = max(1, c.line-excerptRadius)
+		end = min(len(lines), c.line+excerptRadius)
+	}
+
+	var s strings.Builder
+	last := start - 1
+	for i := start; i <= end; i++ {
+		if s.Len()+len(lines[i-1])+1 > budget {
+			break
+		}
+		s.WriteString(lines[i-1])
+		s.WriteByte('\n')
+		last = i
+	}
+	if last < start {
+		return Excerpt{}, false
+	}
+	return Excerpt{Path: c.path, Reason: c.reason, StartLine: start, EndLine: last, Content: s.String()}, true
+}
+
+// git runs git in dir and returns its output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,125 @@
+package contextpack
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRepo creates a git repo with files committed.
+func newRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.email=t@t", "-c", "user.name=T", "add", "."},
+		{"-c", "user.email=t@t", "-c", "user.name=T", "commit", "-qm", "initial import"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return dir
+}
+
+func numbered(n int) string {
+	var s strings.Builder
+	for i := 1; i <= n; i++ {
+		s.WriteString("line\n")
+	}
+	return s.String()
+}
+
+func TestBuild(t *testing.T) {
+	dir := newRepo(t, map[string]string{
+		"README.md":                     "# widget\n",
+		"internal/auth/login.go":        numbered(100),
+		"internal/auth/login_test.go":   "package auth\n\nfunc TestLoginRedirect(t *testing.T) {}\n",
+		"internal/billing/invoice.go":   "package billing\n",
+		"internal/billing/invoice.json": "{}\n",
+	})
+
+	logs := `##[error]/home/vsts/work/1/s/internal/auth/login.go:60:2: undefined: redirect
+--- FAIL: TestLoginRedirect (0.00s)`
+	pack, err := (&Builder{}).Build(context.Background(), dir, Request{
+		Query: logs,
+		Files: []FileRef{{Path: "README.md", Reason: "commented on"}},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, pack.Tree, "internal/ (4 files)")
+	assert.Contains(t, pack.Tree, "  internal/auth/ (2 files)")
+	assert.Contains(t, pack.Tree, "README.md")
+	assert.Contains(t, pack.Commits, "initial import")
+
+	require.Len(t, pack.Excerpts, 3)
+	assert.Equal(t, Excerpt{Path: "README.md", Reason: "commented on", StartLine: 1, EndLine: 2, Content: "# widget\n\n"}, pack.Excerpts[0])
+	assert.Equal(t, "internal/auth/login.go", pack.Excerpts[1].Path)
+	assert.Equal(t, "mentioned", pack.Excerpts[1].Reason)
+	assert.Equal(t, 30, pack.Excerpts[1].StartLine)
+	assert.Equal(t, 90, pack.Excerpts[1].EndLine)
+	assert.Equal(t, "internal/auth/login_test.go", pack.Excerpts[2].Path)
+	assert.Equal(t, "failing test TestLoginRedirect", pack.Excerpts[2].Reason)
+
+	md := pack.Markdown()
+	assert.Contains(t, md, "### internal/auth/login.go (lines 30-90, mentioned)")
+}
+
+func TestBuildBudget(t *testing.T) {
+	dir := newRepo(t, map[string]string{"a.go": numbered(100), "b.go": numbered(100)})
+	pack, err := (&Builder{}).Build(context.Background(), dir, Request{Query: "a.go b.go", MaxBytes: 200})
+	require.NoError(t, err)
+	require.Len(t, pack.Excerpts, 1)
+	assert.Equal(t, "a.go", pack.Excerpts[0].Path)
+	assert.Less(t, pack.Excerpts[0].EndLine, 100)
+}
+
+// fakeEmbedder embeds texts by whether they mention billing.
+type fakeEmbedder struct{ calls int }
+
+func (f *fakeEmbedder) Model() string { return "fake/model" }
+
+func (f *fakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	f.calls++
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if strings.Contains(text, "billing") {
+			vectors[i] = []float32{1, 0}
+		} else {
+			vectors[i] = []float32{0, 1}
+		}
+	}
+	return vectors, nil
+}
+
+func TestBuildSimilarFiles(t *testing.T) {
+	dir := newRepo(t, map[string]string{
+		"internal/auth/login.go":      "package auth\n",
+		"internal/billing/invoice.go": "package billing\n",
+		"docs/billing.md":             "not source\n",
+	})
+	e := &fakeEmbedder{}
+	b := &Builder{Embedder: e, CacheDir: t.TempDir()}
+
+	pack, err := b.Build(context.Background(), dir, Request{Query: "billing charges twice"})
+	require.NoError(t, err)
+	require.NotEmpty(t, pack.Excerpts)
+	assert.Equal(t, "internal/billing/invoice.go", pack.Excerpts[0].Path)
+	assert.Equal(t, "similar, 1.00", pack.Excerpts[0].Reason)
+	assert.Equal(t, 2, e.calls) // files, then the query
+
+	// File vectors come from the cache the second time.
+	_, err = b.Build(context.Background(), dir, Request{Query: "billing"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, e.calls)
+}
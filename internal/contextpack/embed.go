@@ -0,0 +1,170 @@
+package contextpack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/alanmeadows/otto/internal/codesearch"
+	"github.com/alanmeadows/otto/internal/store"
+)
+
+const (
+	maxEmbedFiles  = 400  // files ranked per pack
+	embedTextBytes = 2000 // of each file's start that is embedded
+	embedQueryMax  = 8000 // of the query that is embedded
+	embedBatch     = 64   // texts per embeddings request
+	maxSimilar     = 4    // most similar files excerpted
+)
+
+// similarFiles ranks the repo's source files, other than those in skip, by
+// the similarity of their embeddings to the query's and returns the
+// closest. File vectors are cached by blob, so a file is embedded again
+// only once it changes.
+func (b *Builder) similarFiles(ctx context.Context, dir string, files []trackedFile, skip map[string]bool, query string) ([]candidate, error) {
+	var pool []trackedFile
+	for _, f := range files {
+		if codesearch.IsSource(f.path) && !skip[f.path] {
+			pool = append(pool, f)
+		}
+	}
+	if len(pool) > maxEmbedFiles {
+		// Favor files near those the request already points at.
+		near := func(p string) bool {
+			for s := range skip {
+				if filepath.Dir(s) == filepath.Dir(p) {
+					return true
+				}
+			}
+			return false
+		}
+		sort.SliceStable(pool, func(i, j int) bool { return near(pool[i].path) && !near(pool[j].path) })
+		pool = pool[:maxEmbedFiles]
+	}
+	if len(pool) == 0 {
+		return nil, nil
+	}
+
+	vectors, err := b.fileVectors(ctx, dir, pool)
+	if err != nil {
+		return nil, err
+	}
+	q, err := b.Embedder.Embed(ctx, []string{query[:min(len(query), embedQueryMax)]})
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		path  string
+		score float64
+	}
+	var ranked []scored
+	for _, f := range pool {
+		if v, ok := vectors[f.blob]; ok {
+			ranked = append(ranked, scored{f.path, codesearch.Cosine(q[0], v)})
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	var found []candidate
+	for _, r := range ranked[:min(len(ranked), maxSimilar)] {
+		found = append(found, candidate{path: r.path, reason: fmt.Sprintf("similar, %.2f", r.score)})
+	}
+	return found, nil
+}
+
+// searchIndex returns the files of the snippets in b.Index closest to the
+// query, each at its snippet.
+func (b *Builder) searchIndex(ctx context.Context, dir, query string) ([]candidate, error) {
+	hits, err := b.Index.Search(ctx, dir, query, maxSimilar)
+	if err != nil {
+		return nil, err
+	}
+	found := make([]candidate, len(hits))
+	for i, h := range hits {
+		found[i] = candidate{path: h.Path, line: (h.StartLine + h.EndLine) / 2, reason: fmt.Sprintf("search, %.2f", h.Score)}
+	}
+	return found, nil
+}
+
+// fileVectors returns the embeddings of files by blob, embedding those
+// not cached yet.
+func (b *Builder) fileVectors(ctx context.Context, dir string, files []trackedFile) (map[string][]float32, error) {
+	cache := b.loadCache()
+	var missing []trackedFile
+	for _, f := range files {
+		if _, ok := cache[f.blob]; !ok {
+			missing = append(missing, f)
+		}
+	}
+
+	for start := 0; start < len(missing); start += embedBatch {
+		batch := missing[start:min(len(missing), start+embedBatch)]
+		texts := make([]string, len(batch))
+		for i, f := range batch {
+			data, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.path)))
+			texts[i] = f.path + "\n" + string(data[:min(len(data), embedTextBytes)])
+		}
+		vectors, err := b.Embedder.Embed(ctx, texts)
+		if err != nil {
+			return nil, err
+		}
+		for i, f := range batch {
+			cache[f.blob] = vectors[i]
+		}
+	}
+	if len(missing) > 0 {
+		b.saveCache(cache)
+	}
+	return cache, nil
+}
+
+var unsafeModelChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func (b *Builder) cachePath() string {
+	if b.CacheDir == "" {
+		return ""
+	}
+	return filepath.Join(b.CacheDir, "embeddings-"+unsafeModelChars.ReplaceAllString(b.Embedder.Model(), "_")+".json")
+}
+
+// loadCache reads the cached vectors of the embedder's model; a missing
+// or unreadable cache is empty.
+func (b *Builder) loadCache() map[string][]float32 {
+	cache := make(map[string][]float32)
+	p := b.cachePath()
+	if p == "" {
+		return cache
+	}
+	_ = store.WithReadLock(p, store.DefaultLockTimeout, func() error {
+		data, err := os.ReadFile(p)
+		if err == nil {
+			_ = json.Unmarshal(data, &cache)
+		}
+		return nil
+	})
+	return cache
+}
+
+// saveCache writes cache, best effort; vectors that aren't saved are
+// recomputed next time.
+func (b *Builder) saveCache(cache map[string][]float32) {
+	p := b.cachePath()
+	if p == "" {
+		return
+	}
+	_ = store.WithLock(p, store.DefaultLockTimeout, func() error {
+		data, err := json.Marshal(cache)
+		if err != nil {
+			return err
+		}
+		tmp := p + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return err
+		}
+		return os.Rename(tmp, p)
+	})
+}
@@ -0,0 +1,57 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNext(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2026, 3, 4, 10, 30, 15, 0, time.UTC)
+	for _, tc := range []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 4, 10, 31, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2026, 3, 5, 2, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2026, 3, 4, 10, 40, 0, 0, time.UTC)},
+		{"0 9 * * mon", time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2026, 3, 8, 9, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2026, 3, 4, 13, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)},
+		// With both day fields set, either matches: the 15th or a Friday.
+		{"0 0 15 * fri", time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"30 10 4 3 *", time.Date(2027, 3, 4, 10, 30, 0, 0, time.UTC)},
+	} {
+		s, err := Parse(tc.expr)
+		require.NoError(t, err, tc.expr)
+		assert.Equal(t, tc.want, s.Next(from), tc.expr)
+	}
+
+	s, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(from).IsZero(), "February 30th never comes")
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"@fortnightly",
+	} {
+		_, err := Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
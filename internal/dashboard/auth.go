@@ -0,0 +1,307 @@
+package dashboard
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Dashboard access control ---
+//
+// The dashboard is protected by a secret key: dashboard.admin_token when
+// set, otherwise a random key generated at each start. Remote users present
+// it once, as ?key=<secret> in the URL (the tunnel's keyed link) or through
+// the passcode prompt, and get a signed session cookie. Without one they see
+// the prompt. Local access (localhost, not through a proxy) always passes.
+//
+// Mutating requests also need the X-CSRF-Token header to carry the value of
+// the otto_csrf cookie, which the dashboard's own pages can read and other
+// sites cannot.
+
+const (
+	sessionCookie = "otto_session"
+	csrfCookie    = "otto_csrf"
+	csrfHeader    = "X-CSRF-Token"
+	sessionTTL    = 30 * 24 * time.Hour
+)
+
+// sign returns an HMAC of msg under the dashboard key.
+func (s *Server) sign(msg string) string {
+	mac := hmac.New(sha256.New, []byte(s.dashboardKey))
+	mac.Write([]byte(msg))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newSession returns a session cookie value: an expiry and its signature.
+// Sessions need no server state, survive restarts when the key is fixed,
+// and all end when the key changes.
+func (s *Server) newSession() string {
+	exp := strconv.FormatInt(time.Now().Add(sessionTTL).Unix(), 10)
+	return exp + "." + s.sign("session:"+exp)
+}
+
+// validSession reports whether v is an unexpired session signed with the
+// current key.
+func (s *Server) validSession(v string) bool {
+	exp, sig, ok := strings.Cut(v, ".")
+	if !ok {
+		return false
+	}
+	n, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() > n {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(s.sign("session:"+exp)))
+}
+
+// csrfToken returns the CSRF token bound to the request's session, or to
+// no session for local and open-dashboard access.
+func (s *Server) csrfToken(r *http.Request) string {
+	session := ""
+	if c, err := r.Cookie(sessionCookie); err == nil && s.validSession(c.Value) {
+		session = c.Value
+	}
+	return s.sign("csrf:" + session)
+}
+
+// keyMatches compares a presented key with the dashboard key in constant
+// time.
+func (s *Server) keyMatches(key string) bool {
+	return key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(s.dashboardKey)) == 1
+}
+
+// isLocalRequest reports whether r came straight from this machine, judged
+// by the connection's peer address: the Host header is the client's to
+// set. A request relayed by a reverse proxy is remote even if the proxy
+// reached us on localhost.
+func isLocalRequest(r *http.Request) bool {
+	if r.Header.Get("X-Forwarded-For") != "" || r.Header.Get("X-Forwarded-Host") != "" || r.Header.Get("Forwarded") != "" {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isSecureRequest reports whether the browser reached us over HTTPS,
+// directly or through a proxy.
+func isSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// isDashboardAccessAllowed checks if the request has the correct dashboard key.
+// Local requests are always allowed. Remote requests need ?key= or a session
+// cookie, unless require_key is set to false in config (--open-dashboard).
+func (s *Server) isDashboardAccessAllowed(r *http.Request) bool {
+	if isLocalRequest(r) {
+		return true
+	}
+
+	// Open dashboard mode: skip key check entirely.
+	if s.cfg.Dashboard.RequireKey != nil && !*s.cfg.Dashboard.RequireKey {
+		return true
+	}
+
+	if s.keyMatches(r.URL.Query().Get("key")) {
+		return true
+	}
+	if c, err := r.Cookie(sessionCookie); err == nil && s.validSession(c.Value) {
+		return true
+	}
+	return false
+}
+
+// startSession sets a fresh session cookie and its CSRF cookie.
+func (s *Server) startSession(w http.ResponseWriter, r *http.Request) {
+	session := s.newSession()
+	secure := isSecureRequest(r)
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    session,
+		Path:     "/",
+		MaxAge:   int(sessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    s.sign("csrf:" + session),
+		Path:     "/",
+		MaxAge:   int(sessionTTL.Seconds()),
+		Secure:   secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// ensureCSRFCookie sets the CSRF cookie if the browser doesn't have the
+// current one.
+func (s *Server) ensureCSRFCookie(w http.ResponseWriter, r *http.Request) {
+	token := s.csrfToken(r)
+	if c, err := r.Cookie(csrfCookie); err == nil && c.Value == token {
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    token,
+		Path:     "/",
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// validCSRF reports whether a mutating request carries the CSRF token.
+// Safe methods need none.
+func (s *Server) validCSRF(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return hmac.Equal([]byte(r.Header.Get(csrfHeader)), []byte(s.csrfToken(r)))
+}
+
+// sameOrigin reports whether a browser request came from a dashboard page.
+// It guards the WebSocket, which cannot carry the CSRF header. Requests
+// without an Origin header are not from a browser page.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host) || strings.EqualFold(u.Host, r.Header.Get("X-Forwarded-Host"))
+}
+
+// redirectWithoutKey starts a session for a request that came in with
+// ?key= and redirects to the same URL without it, so the key doesn't leak
+// into browser history. It reports whether it did.
+func (s *Server) redirectWithoutKey(w http.ResponseWriter, r *http.Request) bool {
+	if !s.keyMatches(r.URL.Query().Get("key")) {
+		return false
+	}
+	s.startSession(w, r)
+	clean := *r.URL
+	q := clean.Query()
+	q.Del("key")
+	clean.RawQuery = q.Encode()
+	http.Redirect(w, r, clean.String(), http.StatusFound)
+	return true
+}
+
+// guardDashboard wraps a HandlerFunc with access control and CSRF checks.
+func (s *Server) guardDashboard(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.isDashboardAccessAllowed(r) {
+			s.serveKeyPrompt(w, r, false)
+			return
+		}
+		if s.redirectWithoutKey(w, r) {
+			return
+		}
+		if !s.validCSRF(r) {
+			http.Error(w, "missing or invalid CSRF token", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requireDashboardAccess wraps an http.Handler with access control.
+func (s *Server) requireDashboardAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.isDashboardAccessAllowed(r) {
+			s.serveKeyPrompt(w, r, false)
+			return
+		}
+		if s.redirectWithoutKey(w, r) {
+			return
+		}
+		s.ensureCSRFCookie(w, r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleWS serves the dashboard WebSocket to pages of this dashboard only.
+func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "cross-origin WebSocket rejected", http.StatusForbidden)
+		return
+	}
+	s.bridge.HandleWS(w, r)
+}
+
+// handleLogin checks the passcode posted by the prompt page and starts a
+// session.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if !s.keyMatches(r.PostFormValue("key")) {
+		s.serveKeyPrompt(w, r, true)
+		return
+	}
+	s.startSession(w, r)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// handleLogout ends the browser's session.
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	for _, name := range []string{sessionCookie, csrfCookie} {
+		http.SetCookie(w, &http.Cookie{Name: name, Value: "", Path: "/", MaxAge: -1})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveKeyPrompt shows a passcode entry page for unauthorized users. The
+// passcode is posted to /auth/login rather than put in the URL.
+func (s *Server) serveKeyPrompt(w http.ResponseWriter, r *http.Request, failed bool) {
+	errClass := "err"
+	if failed {
+		errClass = "err shown"
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusUnauthorized)
+	fmt.Fprint(w, strings.Replace(keyPromptHTML, "{{ERR_CLASS}}", errClass, 1))
+}
+
+const keyPromptHTML = `<!DOCTYPE html>
+<html><head>
+<meta charset="UTF-8"><meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>Otto Dashboard — Access Required</title>
+<style>
+  body { background: #0d1117; color: #e6edf3; font-family: -apple-system, sans-serif;
+         display: flex; align-items: center; justify-content: center; height: 100vh; margin: 0; }
+  .box { background: #161b22; border: 1px solid #30363d; border-radius: 12px; padding: 32px;
+         max-width: 360px; width: 90%; text-align: center; }
+  h2 { margin: 0 0 8px; font-size: 18px; }
+  p { font-size: 13px; color: #8b949e; margin: 0 0 20px; }
+  input { width: 100%; padding: 10px 14px; background: #21262d; border: 1px solid #30363d;
+          border-radius: 8px; color: #e6edf3; font-size: 15px; text-align: center;
+          outline: none; box-sizing: border-box; }
+  input:focus { border-color: #58a6ff; }
+  button { margin-top: 14px; padding: 8px 24px; background: #58a6ff; color: #fff;
+           border: none; border-radius: 8px; font-size: 14px; cursor: pointer; }
+  button:hover { background: #79c0ff; }
+  .err { color: #f85149; font-size: 12px; margin-top: 8px; display: none; }
+  .err.shown { display: block; }
+</style>
+</head><body>
+<form class="box" method="POST" action="/auth/login">
+  <h2>🔐 Otto Dashboard</h2>
+  <p>Enter the access key to continue</p>
+  <input name="key" type="password" placeholder="Passcode" autocomplete="off" autofocus>
+  <div class="{{ERR_CLASS}}">Invalid passcode</div>
+  <br><button type="submit">Enter</button>
+</form>
+</body></html>`
@@ -0,0 +1,103 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAuthTestServer() (*Server, *http.ServeMux) {
+	cfg := config.DefaultConfig()
+	s := &Server{cfg: &cfg, dashboardKey: "0123456789abcdef"}
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	mux.HandleFunc("GET /api/thing", s.guardDashboard(ok))
+	mux.HandleFunc("POST /api/thing", s.guardDashboard(ok))
+	mux.HandleFunc("POST /auth/login", s.handleLogin)
+	return s, mux
+}
+
+func TestDashboardLoginAndCSRF(t *testing.T) {
+	s, mux := newAuthTestServer()
+
+	// Remote requests without a session get the prompt.
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "http://otto.example.com/api/thing", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// A wrong passcode shows the prompt again.
+	form := url.Values{"key": {"wrong"}}
+	req := httptest.NewRequest("POST", "http://otto.example.com/auth/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	form.Set("key", s.dashboardKey)
+	req = httptest.NewRequest("POST", "http://otto.example.com/auth/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 2)
+	var csrf string
+	for _, c := range cookies {
+		if c.Name == csrfCookie {
+			csrf = c.Value
+		}
+	}
+
+	do := func(method, token string) int {
+		req := httptest.NewRequest(method, "http://otto.example.com/api/thing", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		if token != "" {
+			req.Header.Set(csrfHeader, token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusOK, do("GET", ""))
+	assert.Equal(t, http.StatusForbidden, do("POST", ""), "mutations need the CSRF token")
+	assert.Equal(t, http.StatusForbidden, do("POST", "bogus"))
+	assert.Equal(t, http.StatusOK, do("POST", csrf))
+
+	// Sessions end when the key changes.
+	s.dashboardKey = "fedcba9876543210"
+	assert.Equal(t, http.StatusUnauthorized, do("GET", ""))
+}
+
+func TestIsLocalRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://localhost:4098/", nil)
+	req.RemoteAddr = "127.0.0.1:52100"
+	assert.True(t, isLocalRequest(req))
+	req.RemoteAddr = "[::1]:52100"
+	assert.True(t, isLocalRequest(req))
+
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	assert.False(t, isLocalRequest(req), "a reverse proxy on localhost is not local access")
+
+	spoofed := httptest.NewRequest("GET", "http://localhost:4098/", nil)
+	spoofed.RemoteAddr = "203.0.113.7:40000"
+	assert.False(t, isLocalRequest(spoofed), "the Host header is the client's to set")
+
+	assert.False(t, isLocalRequest(httptest.NewRequest("GET", "http://otto.example.com/", nil)))
+}
+
+func TestSameOrigin(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://otto.example.com/ws", nil)
+	assert.True(t, sameOrigin(req))
+	req.Header.Set("Origin", "https://otto.example.com")
+	assert.True(t, sameOrigin(req))
+	req.Header.Set("Origin", "https://evil.example.net")
+	assert.False(t, sameOrigin(req))
+}
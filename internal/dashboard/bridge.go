@@ -41,6 +41,7 @@ type wsClient struct {
 	mu            sync.Mutex // serializes writes
 	sessionFilter string     // if set, only receive events for this session (shared view)
 	readOnly      bool       // if true, can't send prompts
+	shareToken    string     // share link the client connected with, if any
 }
 
 // NewBridge creates a Bridge wired to the given copilot Manager.
@@ -88,8 +89,9 @@ func (b *Bridge) HandleWS(w http.ResponseWriter, r *http.Request) {
 	b.readLoop(ctx, id, client)
 }
 
-// HandleSharedWS is the HTTP handler for /ws/shared/{token} — read-only, single session.
-func (b *Bridge) HandleSharedWS(w http.ResponseWriter, r *http.Request, sessionName string, mode string) {
+// HandleSharedWS is the HTTP handler for /ws/shared/{token} — single session,
+// read-only unless the share's mode is "readwrite".
+func (b *Bridge) HandleSharedWS(w http.ResponseWriter, r *http.Request, token, sessionName, mode string) {
 	c, err := websocket.Accept(w, r, &websocket.AcceptOptions{
 		InsecureSkipVerify: true,
 	})
@@ -102,7 +104,7 @@ func (b *Bridge) HandleSharedWS(w http.ResponseWriter, r *http.Request, sessionN
 	b.mu.Lock()
 	b.nextID++
 	id := fmt.Sprintf("shared-%d", b.nextID)
-	client := &wsClient{conn: c, ctx: ctx, sessionFilter: sessionName, readOnly: mode != "readwrite"}
+	client := &wsClient{conn: c, ctx: ctx, sessionFilter: sessionName, readOnly: mode != "readwrite", shareToken: token}
 	b.clients[id] = client
 	b.mu.Unlock()
 
@@ -127,6 +129,37 @@ func (b *Bridge) HandleSharedWS(w http.ResponseWriter, r *http.Request, sessionN
 	b.readLoop(ctx, id, client)
 }
 
+// DisconnectShare closes the connections of clients that joined through
+// the given share token, so revoking a link takes effect immediately.
+func (b *Bridge) DisconnectShare(token string) {
+	b.mu.RLock()
+	var conns []*websocket.Conn
+	for _, c := range b.clients {
+		if c.shareToken == token {
+			conns = append(conns, c.conn)
+		}
+	}
+	b.mu.RUnlock()
+	for _, c := range conns {
+		c.Close(websocket.StatusPolicyViolation, "share revoked")
+	}
+}
+
+// sharedAllowed reports whether a shared client may send a message of the
+// given type. Shared clients can only read their own session, and prompt
+// or abort it when the share is interactive; everything else is the
+// dashboard owner's.
+func sharedAllowed(client *wsClient, msgType string) bool {
+	switch msgType {
+	case MsgGetHistory:
+		return true
+	case MsgSendMessage, MsgAbortSession:
+		return !client.readOnly
+	default:
+		return false
+	}
+}
+
 func (b *Bridge) readLoop(ctx context.Context, id string, client *wsClient) {
 	defer func() {
 		b.mu.Lock()
@@ -153,6 +186,10 @@ func (b *Bridge) readLoop(ctx context.Context, id string, client *wsClient) {
 }
 
 func (b *Bridge) handleClientMessage(ctx context.Context, client *wsClient, msg BridgeMessage) {
+	if client.sessionFilter != "" && !sharedAllowed(client, msg.Type) {
+		slog.Debug("ignoring message from shared client", "type", msg.Type, "session", client.sessionFilter)
+		return
+	}
 	switch msg.Type {
 	case MsgGetSessions:
 		b.sendSessionsList(client)
@@ -162,6 +199,9 @@ func (b *Bridge) handleClientMessage(ctx context.Context, client *wsClient, msg
 		if err := json.Unmarshal(msg.Payload, &p); err != nil {
 			return
 		}
+		if client.sessionFilter != "" {
+			p.SessionName = client.sessionFilter
+		}
 		history, err := b.manager.GetHistory(p.SessionName)
 		if err != nil {
 			return
@@ -199,6 +239,9 @@ func (b *Bridge) handleClientMessage(ctx context.Context, client *wsClient, msg
 		if err := json.Unmarshal(msg.Payload, &p); err != nil {
 			return
 		}
+		if client.sessionFilter != "" {
+			p.SessionName = client.sessionFilter
+		}
 		go func() {
 			if err := b.manager.AbortSession(ctx, p.SessionName); err != nil {
 				slog.Warn("abort session failed", "session", p.SessionName, "error", err)
@@ -768,6 +811,30 @@ func (b *Bridge) BroadcastWorktrees(worktrees []WorktreeSummary) {
 	b.broadcast(MsgWorktreesList, WorktreesListPayload{Worktrees: worktrees})
 }
 
+// BroadcastPRs sends the tracked PR list to dashboard clients. Shared
+// session viewers are not sent it.
+func (b *Bridge) BroadcastPRs(prs any) {
+	data, err := json.Marshal(BridgeMessage{Type: MsgPRsList, Payload: mustMarshal(prs)})
+	if err != nil {
+		return
+	}
+
+	b.mu.RLock()
+	clients := make([]*wsClient, 0, len(b.clients))
+	for _, c := range b.clients {
+		if c.sessionFilter == "" {
+			clients = append(clients, c)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, c := range clients {
+		c.mu.Lock()
+		_ = c.conn.Write(c.ctx, websocket.MessageText, data)
+		c.mu.Unlock()
+	}
+}
+
 // enqueueMessage adds a prompt to the server-side queue for a session.
 // Messages are delivered sequentially, surviving client disconnects.
 func (b *Bridge) enqueueMessage(sessionName, prompt string) {
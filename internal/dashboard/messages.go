@@ -50,6 +50,7 @@ const (
 	MsgDashboardConfig      = "dashboard_config"
 	MsgWatchHistory         = "watch_history"
 	MsgWatchEvent           = "watch_event"
+	MsgPRsList              = "prs_list"
 
 	// Subagent lifecycle.
 	MsgSubagentStarted     = "subagent_started"
@@ -195,10 +196,24 @@ type WorktreesListPayload struct {
 }
 
 type WorktreeSummary struct {
-	Name     string `json:"name"`
-	Path     string `json:"path"`
-	Branch   string `json:"branch"`
-	RepoName string `json:"repo_name"`
+	Name     string      `json:"name"`
+	Path     string      `json:"path"`
+	Branch   string      `json:"branch"`
+	RepoName string      `json:"repo_name"`
+	Spec     string      `json:"spec,omitempty"` // linked by otto branch new
+	Upstream string      `json:"upstream,omitempty"`
+	Ahead    int         `json:"ahead"`
+	Behind   int         `json:"behind"`
+	Dirty    bool        `json:"dirty"`
+	Error    string      `json:"error,omitempty"` // why the git state is missing
+	PR       *WorktreePR `json:"pr,omitempty"`
+}
+
+// WorktreePR is the tracked PR of a worktree's branch.
+type WorktreePR struct {
+	ID     string `json:"id"`
+	URL    string `json:"url"`
+	Status string `json:"status"`
 }
 
 type ReasoningDeltaPayload struct {
@@ -9,6 +9,8 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -34,17 +36,21 @@ type Server struct {
 	GetPRFn      func(id string) (any, error)
 	AddPRFn      func(ctx context.Context, url string) (any, error)
 	RemovePRFn   func(id string) error
+	FixPRFn      func(id string) (any, error)
+	PausePRFn    func(id string, paused bool) (any, error)
+	BranchPRsFn  func() map[string]WorktreePR // tracked PRs by source branch
 	dashboardKey string // secret key for dashboard access
 }
 
 // ShareToken represents a time-limited share link for a single session.
+// Tokens are persisted so links survive a daemon restart.
 type ShareToken struct {
 	Token       string    `json:"token"`
 	SessionName string    `json:"session_name"`
 	SessionID   string    `json:"session_id"`
 	ExpiresAt   time.Time `json:"expires_at"`
 	CreatedAt   time.Time `json:"created_at"`
-	Mode        string    `json:"mode"` // "readonly" or "readwrite"
+	Mode        string    `json:"mode"` // "readonly" (view) or "readwrite" (interact)
 }
 
 // NewServer creates a dashboard server with all subsystems.
@@ -63,15 +69,21 @@ func NewServer(cfg *config.Config) *Server {
 	}
 	bridge := NewBridge(mgr, ownerNick, context.Background()) // serverCtx set in Start()
 	tmgr := tunnel.NewManagerWithConfig(tunnel.Config{
+		Provider:    cfg.Dashboard.TunnelProvider,
 		TunnelID:    cfg.Dashboard.TunnelID,
 		Access:      cfg.Dashboard.TunnelAccess,
 		AllowOrg:    cfg.Dashboard.TunnelAllowOrg,
+		Domain:      cfg.Dashboard.TunnelDomain,
 	})
 
-	// Generate a dashboard access key.
-	keyBytes := make([]byte, 16)
-	rand.Read(keyBytes)
-	dashKey := fmt.Sprintf("%x", keyBytes)
+	// Use the configured admin token as the dashboard access key, or
+	// generate one.
+	dashKey := cfg.Dashboard.AdminToken
+	if dashKey == "" {
+		keyBytes := make([]byte, 16)
+		rand.Read(keyBytes)
+		dashKey = fmt.Sprintf("%x", keyBytes)
+	}
 
 	s := &Server{
 		manager:      mgr,
@@ -79,7 +91,7 @@ func NewServer(cfg *config.Config) *Server {
 		tunnelMgr:    tmgr,
 		cfg:          cfg,
 		dashboardKey: dashKey,
-		shareTokens: make(map[string]*ShareToken),
+		shareTokens: loadShareTokens(),
 	}
 
 	// Wire tunnel status changes into the bridge.
@@ -100,24 +112,19 @@ func NewServer(cfg *config.Config) *Server {
 
 	// Wire tunnel and worktree commands from WebSocket to server.
 	bridge.onStartTunnel = func() {
-		if !tmgr.IsInstalled() {
-			slog.Warn("devtunnel CLI is not installed. Install with: curl -sL https://aka.ms/DevTunnelCliInstall | bash")
-			bridge.BroadcastTunnelStatus(false, "devtunnel not installed")
-			return
-		}
-		if !tunnel.IsBgtaskInstalled() {
-			slog.Warn("bgtask is not installed — install with: go install github.com/philsphicas/bgtask/cmd/bgtask@latest")
-			bridge.BroadcastTunnelStatus(false, "bgtask not installed")
+		if hint := tmgr.Missing(); hint != "" {
+			slog.Warn("tunnel cannot start", "provider", tmgr.ProviderName(), "reason", hint)
+			bridge.BroadcastTunnelStatus(false, hint)
 			return
 		}
 		port := cfg.Dashboard.Port
 		if port == 0 {
 			port = 4098
 		}
-		slog.Info("starting devtunnel", "port", port)
+		slog.Info("starting tunnel", "provider", tmgr.ProviderName(), "port", port)
 		go func() {
 			if err := tmgr.Start(context.Background(), port); err != nil {
-				slog.Error("devtunnel start failed", "error", err)
+				slog.Error("tunnel start failed", "error", err)
 			}
 		}()
 	}
@@ -133,6 +140,7 @@ func NewServer(cfg *config.Config) *Server {
 			TunnelID: p.TunnelID,
 			Access:   p.Access,
 			AllowOrg: p.AllowOrg,
+			Domain:   cfg.Dashboard.TunnelDomain,
 		})
 		// Also persist to otto config file.
 		cfg.Dashboard.TunnelID = p.TunnelID
@@ -232,24 +240,20 @@ func (s *Server) Start(ctx context.Context, port int) error {
 
 	// Auto-start tunnel if configured.
 	if s.cfg.Dashboard.AutoStartTunnel {
-		if !tunnel.IsBgtaskInstalled() {
-			slog.Warn("tunnel skipped: bgtask is not installed — install with: go install github.com/philsphicas/bgtask/cmd/bgtask@latest")
-		} else if !s.tunnelMgr.IsInstalled() {
-			slog.Warn("tunnel skipped: devtunnel is not installed. Install with: curl -sL https://aka.ms/DevTunnelCliInstall | bash (or on Windows: winget install Microsoft.devtunnel)")
-		} else {
-			slog.Info("starting tunnel", "tunnel_id", s.cfg.Dashboard.TunnelID, "access", s.cfg.Dashboard.TunnelAccess, "forwarding_port", port)
-			go func() {
-				if err := s.tunnelMgr.Start(ctx, port); err != nil {
-					slog.Warn("tunnel start failed", "error", err)
-				}
-			}()
-		}
+		slog.Info("starting tunnel", "provider", s.tunnelMgr.ProviderName(), "tunnel_id", s.cfg.Dashboard.TunnelID, "access", s.cfg.Dashboard.TunnelAccess, "forwarding_port", port)
+		go func() {
+			if err := s.tunnelMgr.Start(ctx, port); err != nil {
+				slog.Warn("tunnel start failed", "error", err)
+			}
+		}()
 	} else {
 		slog.Info("tunnel disabled via --no-tunnel")
 	}
 
 	// Poll ~/.copilot/session-state/ for changes and push updates to clients.
 	go s.watchPersistedSessions(ctx)
+	// Likewise for tracked PRs.
+	go s.watchPRs(ctx)
 
 	// Shutdown on context cancellation.
 	go func() {
@@ -294,6 +298,9 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/prs/{id}", s.guardDashboard(s.handleGetPR))
 	mux.HandleFunc("POST /api/prs", s.guardDashboard(s.handleAddPR))
 	mux.HandleFunc("DELETE /api/prs/{id}", s.guardDashboard(s.handleRemovePR))
+	mux.HandleFunc("POST /api/prs/{id}/fix", s.guardDashboard(s.handleFixPR))
+	mux.HandleFunc("POST /api/prs/{id}/pause", s.guardDashboard(s.handlePausePR(true)))
+	mux.HandleFunc("POST /api/prs/{id}/resume", s.guardDashboard(s.handlePausePR(false)))
 	mux.HandleFunc("GET /api/repos", s.guardDashboard(s.handleListRepos))
 	mux.HandleFunc("POST /api/repos", s.guardDashboard(s.handleAddRepo))
 	mux.HandleFunc("DELETE /api/repos/{name}", s.guardDashboard(s.handleRemoveRepo))
@@ -301,20 +308,18 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/tunnel/status", s.guardDashboard(s.handleTunnelStatus))
 	mux.HandleFunc("POST /api/tunnel/start", s.guardDashboard(s.handleStartTunnel))
 	mux.HandleFunc("POST /api/tunnel/stop", s.guardDashboard(s.handleStopTunnel))
+	mux.HandleFunc("GET /api/share", s.guardDashboard(s.handleListShares))
 	mux.HandleFunc("POST /api/share", s.guardDashboard(s.handleCreateShare))
+	mux.HandleFunc("DELETE /api/share/{token}", s.guardDashboard(s.handleRevokeShare))
 	mux.HandleFunc("GET /ws", s.guardDashboard(s.handleWS))
+	mux.HandleFunc("POST /auth/login", s.handleLogin)
+	mux.HandleFunc("POST /auth/logout", s.guardDashboard(s.handleLogout))
 
 	// Shared session view — token-gated, NO dashboard auth required.
 	mux.HandleFunc("GET /shared/{token}", s.handleSharedSession)
 	mux.HandleFunc("GET /ws/shared/{token}", s.handleSharedWS)
 }
 
-// --- WebSocket ---
-
-func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
-	s.bridge.HandleWS(w, r)
-}
-
 // --- REST Handlers ---
 
 func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
@@ -440,9 +445,46 @@ func (s *Server) handleRemovePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	slog.Info("PR removed via dashboard", "id", id)
+	s.broadcastPRs()
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (s *Server) handleFixPR(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if s.FixPRFn == nil {
+		http.Error(w, "not configured", http.StatusNotImplemented)
+		return
+	}
+	pr, err := s.FixPRFn(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	slog.Info("PR fix requested via dashboard", "id", id)
+	s.broadcastPRs()
+	w.WriteHeader(http.StatusAccepted)
+	writeJSON(w, pr)
+}
+
+// handlePausePR pauses (paused) or resumes monitoring of a PR.
+func (s *Server) handlePausePR(paused bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if s.PausePRFn == nil {
+			http.Error(w, "not configured", http.StatusNotImplemented)
+			return
+		}
+		pr, err := s.PausePRFn(id, paused)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		slog.Info("PR monitoring changed via dashboard", "id", id, "paused", paused)
+		s.broadcastPRs()
+		writeJSON(w, pr)
+	}
+}
+
 func (s *Server) handleListRepos(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, s.cfg.Repos)
 }
@@ -573,7 +615,7 @@ func (s *Server) handleUpdateRepo(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleTunnelStatus(w http.ResponseWriter, r *http.Request) {
 	running, url := s.tunnelMgr.Status()
-	p := TunnelStatusPayload{Running: running, URL: url}
+	p := TunnelStatusPayload{Running: running, URL: url, Hint: s.tunnelMgr.StatusHint()}
 	if running && url != "" {
 		p.KeyedURL = url + "?key=" + s.dashboardKey
 	}
@@ -581,8 +623,8 @@ func (s *Server) handleTunnelStatus(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleStartTunnel(w http.ResponseWriter, r *http.Request) {
-	if !s.tunnelMgr.IsInstalled() {
-		http.Error(w, "devtunnel CLI is not installed", http.StatusPreconditionFailed)
+	if hint := s.tunnelMgr.Missing(); hint != "" {
+		http.Error(w, hint, http.StatusPreconditionFailed)
 		return
 	}
 	port := s.cfg.Dashboard.Port
@@ -607,45 +649,36 @@ func (s *Server) handleStopTunnel(w http.ResponseWriter, r *http.Request) {
 
 // --- Worktree integration ---
 
+// worktreeStatusTimeout bounds reading each worktree's git state, so one
+// slow checkout (a network filesystem, a huge repo) doesn't hold up the
+// listing.
+const worktreeStatusTimeout = 3 * time.Second
+
 func (s *Server) listWorktrees() []WorktreeSummary {
+	var prs map[string]WorktreePR
+	if s.BranchPRsFn != nil {
+		prs = s.BranchPRsFn()
+	}
 	var worktrees []WorktreeSummary
-	for _, repo := range s.cfg.Repos {
-		if repo.WorktreeDir == "" {
-			// No worktree directory configured — list the primary dir.
-			worktrees = append(worktrees, WorktreeSummary{
-				Name:     repo.Name,
-				Path:     repo.PrimaryDir,
-				Branch:   "main",
-				RepoName: repo.Name,
-			})
-			continue
+	for _, wt := range repo.ListWorktrees(context.Background(), s.cfg.Repos, worktreeStatusTimeout) {
+		summary := WorktreeSummary{
+			Name:     wt.Name,
+			Path:     wt.Path,
+			Branch:   wt.Branch,
+			RepoName: wt.Repo,
+			Spec:     wt.Spec,
+			Upstream: wt.Upstream,
+			Ahead:    wt.Ahead,
+			Behind:   wt.Behind,
+			Dirty:    wt.Dirty,
+			Error:    wt.Error,
 		}
-		// List worktrees from the configured directory.
-		wts := listDirWorktrees(repo.Name, repo.WorktreeDir)
-		worktrees = append(worktrees, wts...)
-	}
-	return worktrees
-}
-
-// listDirWorktrees lists subdirectories under the worktree dir as worktrees.
-func listDirWorktrees(repoName, wtDir string) []WorktreeSummary {
-	var result []WorktreeSummary
-	entries, err := os.ReadDir(wtDir)
-	if err != nil {
-		return result
-	}
-	for _, e := range entries {
-		if !e.IsDir() {
-			continue
+		if pr, ok := prs[wt.Branch]; ok {
+			summary.PR = &pr
 		}
-		result = append(result, WorktreeSummary{
-			Name:     e.Name(),
-			Path:     wtDir + "/" + e.Name(),
-			Branch:   e.Name(),
-			RepoName: repoName,
-		})
+		worktrees = append(worktrees, summary)
 	}
-	return result
+	return worktrees
 }
 
 func writeJSON(w http.ResponseWriter, v any) {
@@ -653,145 +686,6 @@ func writeJSON(w http.ResponseWriter, v any) {
 	json.NewEncoder(w).Encode(v)
 }
 
-// --- Dashboard access control via URL key ---
-//
-// The dashboard is protected by a secret key embedded in the URL query string.
-// When accessed via a tunnel, users must have ?key=<secret> in the URL or
-// a valid otto_key cookie. Without it, they see a passcode prompt.
-// Local access (localhost) always passes without a key.
-
-const dashboardKeyCookie = "otto_key"
-
-// isDashboardAccessAllowed checks if the request has the correct dashboard key.
-// Local requests are always allowed. Remote requests need ?key= or cookie,
-// unless require_key is set to false in config (--open-dashboard).
-func (s *Server) isDashboardAccessAllowed(r *http.Request) bool {
-	// Local access — always allowed.
-	host := r.Host
-	if strings.HasPrefix(host, "localhost") || strings.HasPrefix(host, "127.0.0.1") || strings.HasPrefix(host, "[::1]") {
-		return true
-	}
-
-	// Open dashboard mode: skip key check entirely.
-	if s.cfg.Dashboard.RequireKey != nil && !*s.cfg.Dashboard.RequireKey {
-		return true
-	}
-
-	// Check ?key= query param.
-	if r.URL.Query().Get("key") == s.dashboardKey {
-		return true
-	}
-
-	// Check cookie (set after first successful key auth).
-	if c, err := r.Cookie(dashboardKeyCookie); err == nil && c.Value == s.dashboardKey {
-		return true
-	}
-
-	return false
-}
-
-// guardDashboard wraps a HandlerFunc with access control.
-func (s *Server) guardDashboard(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if !s.isDashboardAccessAllowed(r) {
-			s.serveKeyPrompt(w, r)
-			return
-		}
-		// If they came in with ?key=, set cookie so subsequent requests work
-		// (WebSocket, API calls, etc.) and redirect to clean URL.
-		if key := r.URL.Query().Get("key"); key == s.dashboardKey {
-			http.SetCookie(w, &http.Cookie{
-				Name:     dashboardKeyCookie,
-				Value:    s.dashboardKey,
-				Path:     "/",
-				MaxAge:   86400 * 30, // 30 days
-				HttpOnly: true,
-				SameSite: http.SameSiteLaxMode,
-			})
-			// Redirect to strip key from URL (so it doesn't leak in browser history).
-			clean := *r.URL
-			q := clean.Query()
-			q.Del("key")
-			clean.RawQuery = q.Encode()
-			http.Redirect(w, r, clean.String(), http.StatusFound)
-			return
-		}
-		next(w, r)
-	}
-}
-
-// requireDashboardAccess wraps an http.Handler with access control.
-func (s *Server) requireDashboardAccess(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !s.isDashboardAccessAllowed(r) {
-			s.serveKeyPrompt(w, r)
-			return
-		}
-		if key := r.URL.Query().Get("key"); key == s.dashboardKey {
-			http.SetCookie(w, &http.Cookie{
-				Name:     dashboardKeyCookie,
-				Value:    s.dashboardKey,
-				Path:     "/",
-				MaxAge:   86400 * 30,
-				HttpOnly: true,
-				SameSite: http.SameSiteLaxMode,
-			})
-			clean := *r.URL
-			q := clean.Query()
-			q.Del("key")
-			clean.RawQuery = q.Encode()
-			http.Redirect(w, r, clean.String(), http.StatusFound)
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
-}
-
-// serveKeyPrompt shows a passcode entry page for unauthorized users.
-func (s *Server) serveKeyPrompt(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.WriteHeader(http.StatusUnauthorized)
-	fmt.Fprint(w, `<!DOCTYPE html>
-<html><head>
-<meta charset="UTF-8"><meta name="viewport" content="width=device-width, initial-scale=1.0">
-<title>Otto Dashboard — Access Required</title>
-<style>
-  body { background: #0d1117; color: #e6edf3; font-family: -apple-system, sans-serif;
-         display: flex; align-items: center; justify-content: center; height: 100vh; margin: 0; }
-  .box { background: #161b22; border: 1px solid #30363d; border-radius: 12px; padding: 32px;
-         max-width: 360px; width: 90%; text-align: center; }
-  h2 { margin: 0 0 8px; font-size: 18px; }
-  p { font-size: 13px; color: #8b949e; margin: 0 0 20px; }
-  input { width: 100%; padding: 10px 14px; background: #21262d; border: 1px solid #30363d;
-          border-radius: 8px; color: #e6edf3; font-size: 15px; text-align: center;
-          outline: none; box-sizing: border-box; }
-  input:focus { border-color: #58a6ff; }
-  button { margin-top: 14px; padding: 8px 24px; background: #58a6ff; color: #fff;
-           border: none; border-radius: 8px; font-size: 14px; cursor: pointer; }
-  button:hover { background: #79c0ff; }
-  .err { color: #f85149; font-size: 12px; margin-top: 8px; display: none; }
-</style>
-</head><body>
-<div class="box">
-  <h2>🔐 Otto Dashboard</h2>
-  <p>Enter the access key to continue</p>
-  <input id="key" type="password" placeholder="Passcode" autocomplete="off">
-  <div class="err" id="err">Invalid passcode</div>
-  <br><button onclick="tryKey()">Enter</button>
-</div>
-<script>
-document.getElementById('key').addEventListener('keydown', function(e) {
-  if (e.key === 'Enter') tryKey();
-});
-function tryKey() {
-  var k = document.getElementById('key').value.trim();
-  if (!k) return;
-  window.location.href = '/?key=' + encodeURIComponent(k);
-}
-</script>
-</body></html>`)
-}
-
 // watchPersistedSessions polls ~/.copilot/session-state/ for changes and
 // pushes updated persisted session lists to connected dashboard clients.
 func (s *Server) watchPersistedSessions(ctx context.Context) {
@@ -814,6 +708,46 @@ func (s *Server) watchPersistedSessions(ctx context.Context) {
 	}
 }
 
+// watchPRs pushes the tracked PR list to clients whenever it changes, so
+// status, stages, and live LLM activity update without polling.
+func (s *Server) watchPRs(ctx context.Context) {
+	if s.ListPRsFn == nil {
+		return
+	}
+	ticker := time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+
+	var last []byte
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			prs, err := s.ListPRsFn()
+			if err != nil {
+				continue
+			}
+			data, err := json.Marshal(prs)
+			if err != nil || string(data) == string(last) {
+				continue
+			}
+			last = data
+			s.bridge.BroadcastPRs(json.RawMessage(data))
+		}
+	}
+}
+
+// broadcastPRs pushes the current PR list right away, after a change made
+// from the dashboard.
+func (s *Server) broadcastPRs() {
+	if s.ListPRsFn == nil {
+		return
+	}
+	if prs, err := s.ListPRsFn(); err == nil {
+		s.bridge.BroadcastPRs(prs)
+	}
+}
+
 // persistedHash computes a quick fingerprint of the persisted session list
 // so we only broadcast when something actually changed.
 func persistedHash(sessions []copilot.PersistedSession) string {
@@ -830,7 +764,7 @@ func (s *Server) handleCreateShare(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		SessionName string `json:"session_name"`
 		DurationMin int    `json:"duration_min"` // 0 = default 60 minutes
-		Mode        string `json:"mode"`         // "readonly" or "readwrite"; default "readonly"
+		Mode        string `json:"mode"`         // "readonly"/"view" or "readwrite"/"interact"; default "readonly"
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request", http.StatusBadRequest)
@@ -844,10 +778,7 @@ func (s *Server) handleCreateShare(w http.ResponseWriter, r *http.Request) {
 	if dur <= 0 {
 		dur = time.Hour
 	}
-	mode := req.Mode
-	if mode != "readwrite" {
-		mode = "readonly"
-	}
+	mode := shareMode(req.Mode)
 
 	var sessionID string
 	for _, si := range s.manager.ListSessions() {
@@ -870,6 +801,7 @@ func (s *Server) handleCreateShare(w http.ResponseWriter, r *http.Request) {
 	s.tokenMu.Lock()
 	s.shareTokens[token] = st
 	s.tokenMu.Unlock()
+	s.saveShareTokens()
 
 	slog.Info("share token created", "session", req.SessionName, "mode", mode, "token", token[:8]+"...", "expires", st.ExpiresAt.Format(time.RFC3339))
 
@@ -891,12 +823,125 @@ func (s *Server) validateShareToken(token string) *ShareToken {
 			s.tokenMu.Lock()
 			delete(s.shareTokens, token)
 			s.tokenMu.Unlock()
+			s.saveShareTokens()
 		}
 		return nil
 	}
 	return st
 }
 
+// shareMode maps a requested share mode to "readonly" or "readwrite",
+// accepting "view" and "interact" as aliases.
+func shareMode(mode string) string {
+	switch mode {
+	case "readwrite", "interact":
+		return "readwrite"
+	default:
+		return "readonly"
+	}
+}
+
+func (s *Server) handleListShares(w http.ResponseWriter, r *http.Request) {
+	type shareInfo struct {
+		*ShareToken
+		URL string `json:"url"`
+	}
+	now := time.Now()
+	s.tokenMu.RLock()
+	shares := make([]shareInfo, 0, len(s.shareTokens))
+	for token, st := range s.shareTokens {
+		if now.Before(st.ExpiresAt) {
+			shares = append(shares, shareInfo{ShareToken: st, URL: "/shared/" + token})
+		}
+	}
+	s.tokenMu.RUnlock()
+	slices.SortFunc(shares, func(a, b shareInfo) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	writeJSON(w, shares)
+}
+
+func (s *Server) handleRevokeShare(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+	s.tokenMu.Lock()
+	st, ok := s.shareTokens[token]
+	delete(s.shareTokens, token)
+	s.tokenMu.Unlock()
+	if !ok {
+		http.Error(w, "share not found", http.StatusNotFound)
+		return
+	}
+	s.saveShareTokens()
+	s.bridge.DisconnectShare(token)
+
+	slog.Info("share token revoked", "session", st.SessionName, "token", token[:min(8, len(token))]+"...")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sharesPath returns the share token store under the otto data directory.
+func sharesPath() string {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		dataDir = filepath.Join(os.TempDir(), "otto")
+	}
+	return filepath.Join(dataDir, "shares.json")
+}
+
+// loadShareTokens reads the persisted share tokens, dropping expired ones.
+// A missing or unreadable store yields an empty set.
+func loadShareTokens() map[string]*ShareToken {
+	tokens := make(map[string]*ShareToken)
+	data, err := os.ReadFile(sharesPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("failed to read share tokens", "error", err)
+		}
+		return tokens
+	}
+	var list []*ShareToken
+	if err := json.Unmarshal(data, &list); err != nil {
+		slog.Warn("failed to parse share tokens", "path", sharesPath(), "error", err)
+		return tokens
+	}
+	now := time.Now()
+	for _, st := range list {
+		if st.Token != "" && now.Before(st.ExpiresAt) {
+			tokens[st.Token] = st
+		}
+	}
+	return tokens
+}
+
+// saveShareTokens writes the current share tokens to disk. The store holds
+// live credentials, so it is only readable by the owner. The lock is held
+// throughout so concurrent saves don't interleave.
+func (s *Server) saveShareTokens() {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+	list := make([]*ShareToken, 0, len(s.shareTokens))
+	for _, st := range s.shareTokens {
+		list = append(list, st)
+	}
+	slices.SortFunc(list, func(a, b *ShareToken) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		slog.Warn("failed to encode share tokens", "error", err)
+		return
+	}
+	path := sharesPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		slog.Warn("failed to save share tokens", "error", err)
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		slog.Warn("failed to save share tokens", "error", err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		slog.Warn("failed to save share tokens", "error", err)
+	}
+}
+
 func (s *Server) handleSharedSession(w http.ResponseWriter, r *http.Request) {
 	token := r.PathValue("token")
 	st := s.validateShareToken(token)
@@ -945,7 +990,7 @@ func (s *Server) handleSharedWS(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid or expired share link", http.StatusForbidden)
 		return
 	}
-	s.bridge.HandleSharedWS(w, r, st.SessionName, st.Mode)
+	s.bridge.HandleSharedWS(w, r, token, st.SessionName, st.Mode)
 }
 
 func generateToken() string {
@@ -0,0 +1,73 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareMode(t *testing.T) {
+	assert.Equal(t, "readwrite", shareMode("readwrite"))
+	assert.Equal(t, "readwrite", shareMode("interact"))
+	assert.Equal(t, "readonly", shareMode("view"))
+	assert.Equal(t, "readonly", shareMode(""))
+}
+
+func TestShareTokensPersistAndRevoke(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	s := &Server{
+		bridge: &Bridge{clients: make(map[string]*wsClient)},
+		shareTokens: map[string]*ShareToken{
+			"live":    {Token: "live", SessionName: "a", Mode: "readwrite", CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)},
+			"expired": {Token: "expired", SessionName: "b", Mode: "readonly", CreatedAt: time.Now(), ExpiresAt: time.Now().Add(-time.Minute)},
+		},
+	}
+	s.saveShareTokens()
+	info, err := os.Stat(sharesPath())
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Expired tokens are dropped on load.
+	loaded := loadShareTokens()
+	require.Len(t, loaded, 1)
+	assert.Equal(t, "readwrite", loaded["live"].Mode)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/share", s.handleListShares)
+	mux.HandleFunc("DELETE /api/share/{token}", s.handleRevokeShare)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/share", nil))
+	var shares []map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &shares))
+	require.Len(t, shares, 1)
+	assert.Equal(t, "/shared/live", shares[0]["url"])
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("DELETE", "/api/share/live", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Nil(t, s.validateShareToken("live"))
+	assert.Empty(t, loadShareTokens(), "revocation is persisted")
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("DELETE", "/api/share/live", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSharedAllowed(t *testing.T) {
+	view := &wsClient{sessionFilter: "a", readOnly: true}
+	interact := &wsClient{sessionFilter: "a"}
+	assert.True(t, sharedAllowed(view, MsgGetHistory))
+	assert.False(t, sharedAllowed(view, MsgSendMessage))
+	assert.False(t, sharedAllowed(view, MsgAbortSession))
+	assert.True(t, sharedAllowed(interact, MsgSendMessage))
+	assert.True(t, sharedAllowed(interact, MsgAbortSession))
+	assert.False(t, sharedAllowed(interact, MsgCreateSession))
+	assert.False(t, sharedAllowed(interact, MsgStartTunnel))
+}
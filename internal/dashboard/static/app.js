@@ -12,7 +12,6 @@ const state = {
     activeSession: null,
     reconnectDelay: 1000,
     reconnectTimer: null,
-    prPollTimer: null,
     streamingContent: {},  // sessionName -> accumulated content
     renderedMessageCount: 0,  // how many history messages we've rendered
     tunnelRunning: false,
@@ -22,6 +21,27 @@ const state = {
     pendingPrompts: [],    // prompts awaiting server broadcast
 };
 
+// --- CSRF ---
+
+// The server rejects mutating API requests that don't echo the otto_csrf
+// cookie in the X-CSRF-Token header, so add it to every fetch.
+const nativeFetch = window.fetch.bind(window);
+window.fetch = function(url, opts) {
+    opts = opts || {};
+    const method = (opts.method || 'GET').toUpperCase();
+    if (method !== 'GET' && method !== 'HEAD') {
+        const headers = new Headers(opts.headers || {});
+        headers.set('X-CSRF-Token', csrfToken());
+        opts = Object.assign({}, opts, { headers: headers });
+    }
+    return nativeFetch(url, opts);
+};
+
+function csrfToken() {
+    const m = document.cookie.match(/(?:^|;\s*)otto_csrf=([^;]*)/);
+    return m ? decodeURIComponent(m[1]) : '';
+}
+
 // --- WebSocket ---
 
 function connect() {
@@ -40,11 +60,12 @@ function connect() {
         if (state.activeSession) {
             send('get_history', { session_name: state.activeSession });
         }
+        // The PR list is pushed over the socket when it changes (prs_list);
+        // fetch it once to catch up after a reconnect.
         fetchPRs();
         fetchRepos();
-        // Refresh PRs every 60 seconds.
-        if (state.prPollTimer) clearInterval(state.prPollTimer);
-        state.prPollTimer = setInterval(fetchPRs, 60000);
+        // Worktrees link sessions to the PRs of their branches.
+        send('list_worktrees', {});
     };
 
     ws.onmessage = (evt) => {
@@ -58,7 +79,6 @@ function connect() {
 
     ws.onclose = () => {
         setConnectionStatus('disconnected');
-        if (state.prPollTimer) { clearInterval(state.prPollTimer); state.prPollTimer = null; }
         scheduleReconnect();
     };
 
@@ -121,6 +141,7 @@ function handleMessage(msg) {
         case 'dashboard_config': handleDashboardConfig(msg.payload); break;
         case 'watch_history': handleWatchHistory(msg.payload); break;
         case 'watch_event': handleWatchEvent(msg.payload); break;
+        case 'prs_list': handlePRsList(msg.payload); break;
         // Subagent lifecycle.
         case 'subagent_started': handleSubagentStarted(msg.payload); break;
         case 'subagent_completed': handleSubagentCompleted(msg.payload); break;
@@ -296,13 +317,28 @@ function handleError(payload) {
 
 var _allWorktrees = [];
 
+// worktreeState summarizes a worktree's git state for its picker label,
+// e.g. " ↑2 ↓1 ✎ [PR #42 watching]".
+function worktreeState(wt) {
+    if (wt.error) return ' (' + wt.error + ')';
+    let s = '';
+    if (wt.ahead) s += ' ↑' + wt.ahead;
+    if (wt.behind) s += ' ↓' + wt.behind;
+    if (wt.dirty) s += ' ✎';
+    if (wt.spec) s += ' [spec: ' + wt.spec + ']';
+    if (wt.pr) s += ' [PR #' + wt.pr.id + ' ' + wt.pr.status + ']';
+    return s;
+}
+
 function handleWorktreesList(payload) {
     _allWorktrees = (payload.worktrees || []).map(wt => ({
         path: wt.path,
-        label: wt.repo_name + '/' + wt.branch + ' — ' + wt.path,
+        label: wt.repo_name + '/' + wt.branch + worktreeState(wt) + ' — ' + wt.path,
         repo: wt.repo_name,
         branch: wt.branch,
+        pr: wt.pr,
     }));
+    if (state.activeSession) updateChatHeader();
 
     // Show/hide the "no repos" hint.
     const hint = document.getElementById('no-repos-hint');
@@ -493,6 +529,7 @@ function watchSession(sessionId, title) {
     renderRepos();
     document.getElementById('empty-state').classList.add('hidden');
     document.getElementById('pr-detail-view').classList.add('hidden');
+    document.getElementById('pr-table-view').classList.add('hidden');
     document.getElementById('repo-detail-view').classList.add('hidden');
     document.getElementById('chat-view').classList.remove('hidden');
 
@@ -824,6 +861,7 @@ function viewSavedSession(sessionId, title) {
     renderRepos();
     document.getElementById('empty-state').classList.add('hidden');
     document.getElementById('pr-detail-view').classList.add('hidden');
+    document.getElementById('pr-table-view').classList.add('hidden');
     document.getElementById('repo-detail-view').classList.add('hidden');
     document.getElementById('chat-view').classList.remove('hidden');
 
@@ -909,14 +947,25 @@ function fetchPRs() {
     const url = '/api/prs' + (keyParam ? '?key=' + encodeURIComponent(keyParam) : '');
     fetch(url)
         .then(r => r.json())
-        .then(prs => {
-            state.trackedPRs = prs || [];
-            renderPRs();
-        })
-        .catch(() => {
-            state.trackedPRs = [];
-            renderPRs();
-        });
+        .then(prs => handlePRsList(prs))
+        .catch(() => handlePRsList([]));
+}
+
+// handlePRsList applies a PR list from the server, pushed over the socket
+// or fetched, and refreshes whichever PR view is showing.
+function handlePRsList(prs) {
+    const previous = state.trackedPRs.find(pr => pr.id === state.selectedPR);
+    state.trackedPRs = prs || [];
+    renderPRs();
+    if (!document.getElementById('pr-table-view').classList.contains('hidden')) {
+        renderPRTable();
+    }
+    // Reload the detail pane when the selected PR changed.
+    const current = state.trackedPRs.find(pr => pr.id === state.selectedPR);
+    if (current && JSON.stringify(current) !== JSON.stringify(previous) &&
+        !document.getElementById('pr-detail-view').classList.contains('hidden')) {
+        loadPRDetail(current.id);
+    }
 }
 
 function renderPRs() {
@@ -930,7 +979,7 @@ function renderPRs() {
         const el = document.createElement('div');
         el.className = 'pr-item' + (state.selectedPR === pr.id ? ' active' : '');
         el.dataset.prId = pr.id;
-        const statusIcon = prStatusIcon(pr.status, pr.pipeline_state);
+        const statusIcon = prStatusIcon(pr.status, pr.pipeline_state, pr.paused);
         const waitingOn = prWaitingOn(pr);
         el.innerHTML = `
             <div class="pr-item-header">
@@ -941,7 +990,8 @@ function renderPRs() {
                 <span class="pr-id">#${escapeHtml(pr.id)}</span>
                 <span class="pr-repo">${escapeHtml(pr.repo || '')}</span>
                 ${waitingOn ? `<span class="pr-waiting">${escapeHtml(waitingOn)}</span>` : ''}
-            </div>`;
+            </div>
+            ${pr.activity ? `<div class="pr-activity">⚙️ ${escapeHtml(pr.activity)}</div>` : ''}`;
         el.addEventListener('click', () => selectPR(pr.id));
         container.appendChild(el);
     }
@@ -957,26 +1007,30 @@ function selectPR(id) {
     document.getElementById('empty-state').classList.add('hidden');
     document.getElementById('chat-view').classList.add('hidden');
     document.getElementById('pr-detail-view').classList.remove('hidden');
+    document.getElementById('pr-table-view').classList.add('hidden');
     document.getElementById('repo-detail-view').classList.add('hidden');
     document.getElementById('pr-detail-body').innerHTML = '<div style="color:var(--text-muted);padding:20px">Loading…</div>';
+    loadPRDetail(id);
+
+    if (window.innerWidth <= 768) {
+        document.getElementById('sidebar').classList.remove('open');
+    }
+}
 
+function loadPRDetail(id) {
     const keyParam = new URLSearchParams(location.search).get('key');
     const url = '/api/prs/' + encodeURIComponent(id) + (keyParam ? '?key=' + encodeURIComponent(keyParam) : '');
     fetch(url)
         .then(r => { if (!r.ok) throw new Error('Not found'); return r.json(); })
-        .then(pr => renderPRDetail(pr))
+        .then(pr => { if (pr.id === state.selectedPR) renderPRDetail(pr); })
         .catch(err => {
             document.getElementById('pr-detail-body').innerHTML =
                 '<div style="color:var(--red);padding:20px">Failed to load PR: ' + escapeHtml(err.message) + '</div>';
         });
-
-    if (window.innerWidth <= 768) {
-        document.getElementById('sidebar').classList.remove('open');
-    }
 }
 
 function renderPRDetail(pr) {
-    const icon = prStatusIcon(pr.status, pr.pipeline_state);
+    const icon = prStatusIcon(pr.status, pr.pipeline_state, pr.paused);
     document.getElementById('pr-detail-icon').textContent = icon;
     document.getElementById('pr-detail-title').textContent = pr.title || 'PR #' + pr.id;
     document.getElementById('pr-detail-link').href = pr.url || '#';
@@ -997,7 +1051,18 @@ function renderPRDetail(pr) {
         `<span class="pr-detail-tag">${escapeHtml(pr.repo || '')}</span>`,
         pr.last_checked ? `<span class="pr-detail-tag">checked ${timeAgo(pr.last_checked)}</span>` : '',
     ].filter(Boolean).join(' ');
-    document.getElementById('pr-detail-meta').innerHTML = meta;
+    document.getElementById('pr-detail-meta').innerHTML = meta + prWorktreeTags(pr);
+
+    // Actions available in the PR's current state
+    const actions = prActions(pr);
+    document.getElementById('pr-fix-btn').disabled = !actions.fix;
+    document.getElementById('pr-pause-btn').classList.toggle('hidden', !actions.pause);
+    document.getElementById('pr-resume-btn').classList.toggle('hidden', !actions.resume);
+
+    // Live LLM progress while otto works on the PR
+    const activityEl = document.getElementById('pr-detail-activity');
+    activityEl.textContent = pr.activity ? '⚙️ ' + pr.activity : '';
+    activityEl.classList.toggle('hidden', !pr.activity);
 
     // Status grid — visual cards for each stage dimension
     const grid = document.getElementById('pr-detail-status-grid');
@@ -1020,6 +1085,12 @@ function renderPRDetail(pr) {
         progressEl.innerHTML = '';
     }
 
+    // Diagnosis of the latest build fix
+    const diagnosisEl = document.getElementById('pr-detail-diagnosis');
+    diagnosisEl.innerHTML = pr.diagnosis
+        ? '<h4 class="timeline-heading">Latest diagnosis</h4>' + renderMarkdown(pr.diagnosis)
+        : '';
+
     // Timeline — parse attempt entries from body
     const timelineEl = document.getElementById('pr-detail-timeline');
     timelineEl.innerHTML = renderFixTimeline(pr.body || '');
@@ -1034,6 +1105,19 @@ function renderPRDetail(pr) {
     }
 }
 
+// prWorktreeTags renders the local worktree of a PR's branch and links to
+// the sessions working in it.
+function prWorktreeTags(pr) {
+    if (!pr.worktree) return '';
+    let html = ' <span class="pr-detail-tag" title="' + escapeHtml(pr.worktree.path) + '">📁 ' +
+        escapeHtml(pr.worktree.name) + escapeHtml(worktreeState(pr.worktree)) + '</span>';
+    state.sessions.filter(s => inDir(s.working_dir, pr.worktree.path)).forEach(s => {
+        html += ' <a class="pr-detail-tag link-tag" data-session="' + escapeHtml(s.name) +
+            '" onclick="selectSession(this.dataset.session)">💬 ' + escapeHtml(s.name) + '</a>';
+    });
+    return html;
+}
+
 function renderStatusGrid(pr) {
     const cards = [];
 
@@ -1042,7 +1126,7 @@ function renderStatusGrid(pr) {
         merged: 'var(--green)', abandoned: 'var(--text-muted)', fixing: 'var(--yellow)',
         failed: 'var(--red)', green: 'var(--green)', watching: 'var(--accent)',
     };
-    cards.push(statusCard('Status', prStatusIcon(pr.status, pr.pipeline_state),
+    cards.push(statusCard('Status', prStatusIcon(pr.status, pr.pipeline_state, pr.paused),
         pr.status || 'unknown', statusColors[pr.status] || 'var(--text-secondary)'));
 
     // Pipeline
@@ -1059,9 +1143,9 @@ function renderStatusGrid(pr) {
     cards.push(statusCard('Conflicts', pr.has_conflicts ? '⚠️' : '✅',
         pr.has_conflicts ? 'conflicts' : 'clean', pr.has_conflicts ? 'var(--red)' : 'var(--green)'));
 
-    // MerlinBot (only show if relevant — when not done)
-    if (!pr.merlinbot_done || pr.provider === 'ado') {
-        cards.push(statusCard('MerlinBot', pr.merlinbot_done ? '✅' : '🤖',
+    // Bot reviews (only show if relevant — pending, or some bot has reviewed)
+    if (!pr.merlinbot_done || (pr.bots_done || []).length > 0) {
+        cards.push(statusCard('Bot Reviews', pr.merlinbot_done ? '✅' : '🤖',
             pr.merlinbot_done ? 'clear' : 'pending', pr.merlinbot_done ? 'var(--green)' : 'var(--yellow)'));
     }
 
@@ -1078,7 +1162,7 @@ function statusCard(label, icon, value, color) {
 
 function renderFixTimeline(body) {
     // Parse "### Attempt N", "### Infra Retry", and "### Comment by" entries
-    const regex = /^### (Attempt \d+|Infra Retry|Comment by .+?)\s*[-–—]\s*(.+)$/gm;
+    const regex = /^### (Attempt \d+|Infra Retry|Fix Requested|Comment by .+?)\s*[-–—]\s*(.+)$/gm;
     const entries = [];
     let match;
     while ((match = regex.exec(body)) !== null) {
@@ -1143,8 +1227,8 @@ function formatTimelineDate(str) {
 }
 
 function stripTimelineEntries(body) {
-    // Remove ### Attempt / ### Infra Retry / ### Comment by blocks.
-    return body.replace(/^### (?:Attempt \d+|Infra Retry|Comment by .+?)[^\n]*\n(?:(?!^###)[^\n]*\n?)*/gm, '').trim();
+    // Remove ### Attempt / ### Infra Retry / ### Fix Requested / ### Comment by blocks.
+    return body.replace(/^### (?:Attempt \d+|Infra Retry|Fix Requested|Comment by .+?)[^\n]*\n(?:(?!^###)[^\n]*\n?)*/gm, '').trim();
 }
 
 function renderMarkdownSimple(md) {
@@ -1164,7 +1248,8 @@ function renderMarkdownSimple(md) {
         .replace(/\n/g, '<br>');
 }
 
-function prStatusIcon(status, pipelineState) {
+function prStatusIcon(status, pipelineState, paused) {
+    if (paused && status !== 'merged' && status !== 'abandoned') return '⏸️';
     switch (status) {
         case 'merged': return '✅';
         case 'abandoned': return '🚫';
@@ -1182,13 +1267,95 @@ function prWaitingOn(pr) {
     const parts = [];
     if (pr.status === 'merged') return 'merged';
     if (pr.status === 'abandoned') return 'abandoned';
+    if (pr.paused) return 'paused';
     if (pr.has_conflicts) parts.push('conflicts');
     if (pr.pipeline_state && pr.pipeline_state !== 'succeeded') parts.push('pipeline');
     if (!pr.feedback_done) parts.push('feedback');
-    if (!pr.merlinbot_done) parts.push('merlinbot');
+    if (!pr.merlinbot_done) parts.push('bot reviews');
     return parts.length > 0 ? parts.join(', ') : '';
 }
 
+// prActions reports which PR actions apply in the PR's current state; the
+// daemon enforces the same rules.
+function prActions(pr) {
+    const open = pr.status !== 'merged' && pr.status !== 'abandoned';
+    return {
+        fix: open && !pr.paused && pr.status !== 'fixing' && pr.pipeline_state === 'failed',
+        pause: open && !pr.paused,
+        resume: open && !!pr.paused,
+    };
+}
+
+// prAction posts a PR action (fix, pause, or resume) to the daemon.
+function prAction(id, action) {
+    const keyParam = new URLSearchParams(location.search).get('key');
+    const url = '/api/prs/' + encodeURIComponent(id) + '/' + action + (keyParam ? '?key=' + encodeURIComponent(keyParam) : '');
+    fetch(url, { method: 'POST' })
+    .then(r => {
+        if (!r.ok) return r.text().then(t => { throw new Error(t); });
+        // The daemon pushes the updated list; refresh the detail pane now.
+        if (state.selectedPR === id) loadPRDetail(id);
+    })
+    .catch(err => alert('Failed to ' + action + ' PR: ' + err.message));
+}
+
+function showPRTable() {
+    state.selectedPR = null;
+    state.selectedRepo = null;
+    state.activeSession = null;
+    renderSessionList();
+    renderPRs();
+    renderRepos();
+    document.getElementById('empty-state').classList.add('hidden');
+    document.getElementById('chat-view').classList.add('hidden');
+    document.getElementById('pr-detail-view').classList.add('hidden');
+    document.getElementById('repo-detail-view').classList.add('hidden');
+    document.getElementById('pr-table-view').classList.remove('hidden');
+    renderPRTable();
+
+    if (window.innerWidth <= 768) {
+        document.getElementById('sidebar').classList.remove('open');
+    }
+}
+
+function renderPRTable() {
+    const tbody = document.getElementById('pr-table-body');
+    if (state.trackedPRs.length === 0) {
+        tbody.innerHTML = '<tr><td colspan="6" class="pr-table-empty">No tracked PRs</td></tr>';
+        return;
+    }
+    tbody.innerHTML = '';
+    for (const pr of state.trackedPRs) {
+        const actions = prActions(pr);
+        const tr = document.createElement('tr');
+        tr.innerHTML = `
+            <td><span class="pr-status-icon">${prStatusIcon(pr.status, pr.pipeline_state, pr.paused)}</span>
+                <span class="pr-id">#${escapeHtml(pr.id)}</span> ${escapeHtml(pr.title || '')}
+                ${pr.activity ? `<div class="pr-activity">⚙️ ${escapeHtml(pr.activity)}</div>` : ''}</td>
+            <td>${escapeHtml(pr.paused ? 'paused' : pr.status || '')}</td>
+            <td>${escapeHtml(pr.pipeline_state || 'unknown')}</td>
+            <td>${escapeHtml(pr.waiting_on || prWaitingOn(pr))}</td>
+            <td>${pr.fix_attempts} / ${pr.max_fix_attempts}</td>
+            <td class="pr-table-actions">
+                <button class="btn btn-sm" data-action="fix" ${actions.fix ? '' : 'disabled'}>Fix</button>
+                ${actions.pause ? '<button class="btn btn-sm" data-action="pause">Pause</button>' : ''}
+                ${actions.resume ? '<button class="btn btn-sm" data-action="resume">Resume</button>' : ''}
+                <button class="btn btn-sm btn-danger" data-action="remove">Remove</button>
+            </td>`;
+        tr.addEventListener('click', (e) => {
+            const action = e.target.dataset && e.target.dataset.action;
+            if (!action) {
+                selectPR(pr.id);
+            } else if (action === 'remove') {
+                removePR(pr.id);
+            } else {
+                prAction(pr.id, action);
+            }
+        });
+        tbody.appendChild(tr);
+    }
+}
+
 function addPRFromDashboard() {
     var prURL = prompt('PR URL (GitHub or Azure DevOps):');
     if (!prURL) return;
@@ -1217,9 +1384,12 @@ function removePR(id) {
     fetch(url, { method: 'DELETE' })
     .then(r => {
         if (!r.ok) return r.text().then(t => { throw new Error(t); });
-        state.selectedPR = null;
-        document.getElementById('pr-detail-view').classList.add('hidden');
-        document.getElementById('empty-state').classList.remove('hidden');
+        // Removing from the table leaves the table up.
+        if (state.selectedPR === id) {
+            state.selectedPR = null;
+            document.getElementById('pr-detail-view').classList.add('hidden');
+            document.getElementById('empty-state').classList.remove('hidden');
+        }
         fetchPRs();
     })
     .catch(err => alert('Failed to remove PR: ' + err.message));
@@ -1276,6 +1446,7 @@ function selectRepo(name) {
     document.getElementById('empty-state').classList.add('hidden');
     document.getElementById('chat-view').classList.add('hidden');
     document.getElementById('pr-detail-view').classList.add('hidden');
+    document.getElementById('pr-table-view').classList.add('hidden');
     document.getElementById('repo-detail-view').classList.remove('hidden');
 
     const repo = state.trackedRepos.find(r => r.name === name);
@@ -1551,6 +1722,20 @@ function updateChatHeader() {
     const stateStr = s.is_processing ? 'processing' : (s.state === 'error' ? 'error' : 'idle');
     statusEl.className = `status-badge ${stateStr}`;
     statusEl.textContent = stateStr;
+
+    // Link to the tracked PR of the worktree the session works in.
+    const prEl = document.getElementById('chat-session-pr');
+    const wt = _allWorktrees.find(wt => wt.pr && inDir(s.working_dir, wt.path));
+    prEl.classList.toggle('hidden', !wt);
+    if (wt) {
+        prEl.textContent = 'PR #' + wt.pr.id + ' · ' + wt.pr.status;
+        prEl.onclick = () => selectPR(wt.pr.id);
+    }
+}
+
+// inDir reports whether path is dir or inside it.
+function inDir(path, dir) {
+    return !!path && !!dir && (path === dir || path.startsWith(dir.replace(/\/+$/, '') + '/'));
 }
 
 function updateActivity(text) {
@@ -1772,6 +1957,7 @@ function selectSession(name) {
     document.getElementById('empty-state').classList.add('hidden');
     document.getElementById('chat-view').classList.remove('hidden');
     document.getElementById('pr-detail-view').classList.add('hidden');
+    document.getElementById('pr-table-view').classList.add('hidden');
     document.getElementById('repo-detail-view').classList.add('hidden');
     document.getElementById('chat-messages').innerHTML = '';
     updateChatHeader();
@@ -1932,6 +2118,7 @@ function shareSession() {
     html += '<option value="240">4 hours</option>';
     html += '<option value="1440">24 hours</option>';
     html += '</select></div>';
+    html += '<div id="share-active" style="margin-bottom:12px;font-size:12px"></div>';
     html += '<div style="display:flex;gap:8px;justify-content:flex-end">';
     html += '<button onclick="this.closest(\'div\').parentElement.remove();document.getElementById(\'share-backdrop\')?.remove()" class="btn">Cancel</button>';
     html += '<button onclick="doShare()" class="btn btn-primary">Create Link</button>';
@@ -1942,6 +2129,39 @@ function shareSession() {
     container.id = 'share-dialog';
     container.innerHTML = html;
     document.body.appendChild(container);
+    loadActiveShares();
+}
+
+// loadActiveShares lists the active share links for the current session in
+// the share dialog, each with a revoke button.
+function loadActiveShares() {
+    fetch('/api/share')
+    .then(function(r) { return r.json(); })
+    .then(function(shares) {
+        var el = document.getElementById('share-active');
+        if (!el) return;
+        var mine = (shares || []).filter(function(s) { return s.session_name === state.activeSession; });
+        if (mine.length === 0) { el.innerHTML = ''; return; }
+        var html = '<label style="color:var(--text-secondary)">Active links</label>';
+        mine.forEach(function(s) {
+            var label = s.mode === 'readwrite' ? '✏️' : '🔒';
+            html += '<div style="display:flex;align-items:center;gap:8px;margin-top:4px">';
+            html += '<span style="flex:1">' + label + ' ' + escapeHtml(s.token.slice(0, 8)) + '… expires ' + new Date(s.expires_at).toLocaleTimeString() + '</span>';
+            html += '<button class="btn" onclick="revokeShare(\'' + escapeHtml(s.token) + '\')">Revoke</button>';
+            html += '</div>';
+        });
+        el.innerHTML = html;
+    })
+    .catch(function() {});
+}
+
+function revokeShare(token) {
+    fetch('/api/share/' + encodeURIComponent(token), { method: 'DELETE' })
+    .then(function(r) {
+        if (!r.ok && r.status !== 404) throw new Error(r.statusText);
+        loadActiveShares();
+    })
+    .catch(function(err) { alert('Failed to revoke: ' + err); });
 }
 
 function doShare() {
@@ -2034,6 +2254,12 @@ document.addEventListener('DOMContentLoaded', () => {
     document.getElementById('pr-remove-btn').addEventListener('click', () => {
         if (state.selectedPR) removePR(state.selectedPR);
     });
+    document.getElementById('pr-table-btn').addEventListener('click', showPRTable);
+    for (const action of ['fix', 'pause', 'resume']) {
+        document.getElementById('pr-' + action + '-btn').addEventListener('click', () => {
+            if (state.selectedPR) prAction(state.selectedPR, action);
+        });
+    }
     document.getElementById('repo-remove-btn').addEventListener('click', () => {
         if (state.selectedRepo) removeRepo(state.selectedRepo);
     });
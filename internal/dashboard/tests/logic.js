@@ -69,6 +69,16 @@ function validateMessageOrder(messages) {
     return issues;
 }
 
+// Determines which PR actions apply in the PR's current state (prActions).
+function prActions(pr) {
+    const open = pr.status !== 'merged' && pr.status !== 'abandoned';
+    return {
+        fix: open && !pr.paused && pr.status !== 'fixing' && pr.pipeline_state === 'failed',
+        pause: open && !pr.paused,
+        resume: open && !!pr.paused,
+    };
+}
+
 module.exports = {
     hashStr,
     reconcileHistory,
@@ -77,4 +87,5 @@ module.exports = {
     sessionClickMode,
     pendingId,
     validateMessageOrder,
+    prActions,
 };
@@ -13,6 +13,7 @@ const {
     sessionClickMode,
     pendingId,
     validateMessageOrder,
+    prActions,
 } = require('./logic.js');
 
 // --- hashStr ---
@@ -218,3 +219,29 @@ describe('validateMessageOrder', () => {
         assert.equal(issues.length, 0);
     });
 });
+
+// --- prActions ---
+
+describe('prActions', () => {
+    test('offers fix only for a failing pipeline', () => {
+        assert.equal(prActions({ status: 'watching', pipeline_state: 'failed' }).fix, true);
+        assert.equal(prActions({ status: 'watching', pipeline_state: 'succeeded' }).fix, false);
+        assert.equal(prActions({ status: 'fixing', pipeline_state: 'failed' }).fix, false);
+    });
+
+    test('offers fix for a PR out of fix attempts', () => {
+        assert.equal(prActions({ status: 'failed', pipeline_state: 'failed' }).fix, true);
+    });
+
+    test('swaps pause for resume on paused PRs', () => {
+        assert.deepEqual(prActions({ status: 'watching', pipeline_state: 'failed', paused: true }),
+            { fix: false, pause: false, resume: true });
+        assert.deepEqual(prActions({ status: 'green', pipeline_state: 'succeeded' }),
+            { fix: false, pause: true, resume: false });
+    });
+
+    test('offers nothing on closed PRs', () => {
+        assert.deepEqual(prActions({ status: 'merged', pipeline_state: 'succeeded' }),
+            { fix: false, pause: false, resume: false });
+    });
+});
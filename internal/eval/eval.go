@@ -0,0 +1,165 @@
+// Package eval holds a corpus of recorded build failures and review
+// comments with the classification otto should reach for each, so prompt
+// and model changes can be checked against it before they ship.
+package eval
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+//go:embed corpus/*.json
+var builtin embed.FS
+
+// Case kinds.
+const (
+	KindFailure = "failure" // build logs classified by pr-fix-analysis.md
+	KindComment = "comment" // review comment decided by pr-comment-respond.md
+)
+
+// expected lists the valid expectations per kind.
+var expected = map[string][]string{
+	KindFailure: {"infra", "compile", "test", "lint", "code"},
+	KindComment: {"AGREE", "BY_DESIGN", "WONT_FIX"},
+}
+
+// Case is one recorded input and the classification otto should reach.
+type Case struct {
+	Name        string   `json:"name"`
+	Kind        string   `json:"kind"`
+	Title       string   `json:"title,omitempty"`       // PR title
+	Description string   `json:"description,omitempty"` // PR description
+	Logs        string   `json:"logs,omitempty"`        // failure cases: failed build logs
+	Comment     *Comment `json:"comment,omitempty"`     // comment cases
+	Expected    string   `json:"expected"`              // failure category or comment decision
+}
+
+// Comment is a recorded review comment.
+type Comment struct {
+	Author      string `json:"author"`
+	File        string `json:"file,omitempty"`
+	Line        int    `json:"line,omitempty"`
+	Body        string `json:"body"`
+	Thread      string `json:"thread,omitempty"`       // earlier replies, oldest first
+	CodeContext string `json:"code_context,omitempty"` // numbered lines around the comment
+}
+
+func (c Case) validate() error {
+	values, ok := expected[c.Kind]
+	switch {
+	case c.Name == "":
+		return fmt.Errorf("case needs a name")
+	case !ok:
+		return fmt.Errorf("case %s: unknown kind %q", c.Name, c.Kind)
+	case !slices.Contains(values, c.Expected):
+		return fmt.Errorf("case %s: expected must be one of %s", c.Name, strings.Join(values, ", "))
+	case c.Kind == KindFailure && c.Logs == "":
+		return fmt.Errorf("case %s: failure case needs logs", c.Name)
+	case c.Kind == KindComment && (c.Comment == nil || c.Comment.Body == ""):
+		return fmt.Errorf("case %s: comment case needs a comment body", c.Name)
+	}
+	return nil
+}
+
+// Load reads the cases in dir's *.json files, each holding one case or an
+// array of cases, sorted by name. An empty dir loads the built-in corpus.
+func Load(dir string) ([]Case, error) {
+	fsys := fs.FS(builtin)
+	pattern := "corpus/*.json"
+	if dir != "" {
+		fsys, pattern = os.DirFS(dir), "*.json"
+	}
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no eval cases in %s", dir)
+	}
+
+	var cases []Case
+	names := make(map[string]string)
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		var batch []Case
+		if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+			err = json.Unmarshal(data, &batch)
+		} else {
+			batch = make([]Case, 1)
+			err = json.Unmarshal(data, &batch[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", filepath.Base(file), err)
+		}
+		for _, c := range batch {
+			if err := c.validate(); err != nil {
+				return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+			}
+			if prev, dup := names[c.Name]; dup {
+				return nil, fmt.Errorf("%s: case %s already defined in %s", filepath.Base(file), c.Name, prev)
+			}
+			names[c.Name] = filepath.Base(file)
+			cases = append(cases, c)
+		}
+	}
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+// Result is the outcome of running one case.
+type Result struct {
+	Case Case
+	Got  string
+	Err  error
+}
+
+// Passed reports whether the case ran and reached the expected value.
+func (r Result) Passed() bool {
+	return r.Err == nil && strings.EqualFold(r.Got, r.Case.Expected)
+}
+
+// Score counts the passing results of one kind.
+type Score struct {
+	Kind   string
+	Passed int
+	Total  int
+}
+
+// Accuracy returns the fraction of cases that passed.
+func (s Score) Accuracy() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Passed) / float64(s.Total)
+}
+
+// Scores returns a score per kind present in results, failures first.
+func Scores(results []Result) []Score {
+	var scores []Score
+	for _, kind := range []string{KindFailure, KindComment} {
+		s := Score{Kind: kind}
+		for _, r := range results {
+			if r.Case.Kind != kind {
+				continue
+			}
+			s.Total++
+			if r.Passed() {
+				s.Passed++
+			}
+		}
+		if s.Total > 0 {
+			scores = append(scores, s)
+		}
+	}
+	return scores
+}
@@ -0,0 +1,63 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBuiltin(t *testing.T) {
+	cases, err := Load("")
+	require.NoError(t, err)
+
+	kinds := make(map[string]int)
+	for _, c := range cases {
+		kinds[c.Kind]++
+	}
+	assert.Positive(t, kinds[KindFailure])
+	assert.Positive(t, kinds[KindComment])
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	write("b.json", `{"name": "b", "kind": "failure", "logs": "timeout", "expected": "infra"}`)
+	write("a.json", `[{"name": "a", "kind": "comment", "comment": {"author": "r", "body": "typo"}, "expected": "AGREE"}]`)
+	write("notes.txt", "ignored")
+
+	cases, err := Load(dir)
+	require.NoError(t, err)
+	require.Len(t, cases, 2)
+	assert.Equal(t, "a", cases[0].Name)
+	assert.Equal(t, "b", cases[1].Name)
+
+	write("c.json", `{"name": "b", "kind": "failure", "logs": "timeout", "expected": "infra"}`)
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, "already defined in b.json")
+
+	write("c.json", `{"name": "c", "kind": "failure", "logs": "timeout", "expected": "INFRASTRUCTURE"}`)
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, "expected must be one of infra, compile, test, lint, code")
+
+	_, err = Load(t.TempDir())
+	assert.ErrorContains(t, err, "no eval cases")
+}
+
+func TestScores(t *testing.T) {
+	failure := Case{Kind: KindFailure, Expected: "infra"}
+	comment := Case{Kind: KindComment, Expected: "AGREE"}
+	results := []Result{
+		{Case: comment, Got: "agree"},
+		{Case: failure, Got: "infra"},
+		{Case: failure, Got: "test"},
+		{Case: failure, Err: assert.AnError},
+	}
+	scores := Scores(results)
+	assert.Equal(t, []Score{{Kind: KindFailure, Passed: 1, Total: 3}, {Kind: KindComment, Passed: 1, Total: 1}}, scores)
+	assert.InDelta(t, 1.0/3, scores[0].Accuracy(), 1e-9)
+}
@@ -0,0 +1,169 @@
+// Package httpcache is an http.RoundTripper that revalidates repeated GET
+// requests with their last ETag or Last-Modified value. An unchanged
+// resource comes back as a small 304, which GitHub does not count against
+// the rate limit, and is answered from memory as the original 200.
+package httpcache
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxBytes bounds the bodies a Transport keeps.
+const DefaultMaxBytes = 32 << 20
+
+// Transport caches validated GET responses in memory, least recently used
+// first out.
+type Transport struct {
+	base     http.RoundTripper
+	maxBytes int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // of *entry, most recent at the front
+	size    int
+
+	hits int
+}
+
+type entry struct {
+	key    string
+	status int
+	header http.Header
+	body   []byte
+}
+
+// New returns a Transport that sends requests through base, or
+// http.DefaultTransport if nil, keeping up to DefaultMaxBytes of bodies.
+func New(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		base:     base,
+		maxBytes: DefaultMaxBytes,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Hits returns how many responses were answered from the cache.
+func (t *Transport) Hits() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.hits
+}
+
+// cacheKey identifies a request. The credentials are part of it (hashed)
+// so one identity never sees another's cached response.
+func cacheKey(req *http.Request) string {
+	h := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return req.URL.String() + "\n" + req.Header.Get("Accept") + "\n" + hex.EncodeToString(h[:8])
+}
+
+// RoundTrip sends req, conditionally when a validated response for it is
+// cached.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+	key := cacheKey(req)
+
+	t.mu.Lock()
+	var cached *entry
+	var etag, lastModified string
+	if el, ok := t.entries[key]; ok {
+		cached = el.Value.(*entry)
+		etag, lastModified = cached.header.Get("ETag"), cached.header.Get("Last-Modified")
+		t.lru.MoveToFront(el)
+	}
+	t.mu.Unlock()
+
+	if cached != nil {
+		req = req.Clone(req.Context())
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		// The 304 carries fresh values for headers like the rate limit.
+		t.mu.Lock()
+		header := cached.header.Clone()
+		for k, v := range resp.Header {
+			header[k] = v
+		}
+		cached.header = header
+		t.hits++
+		t.mu.Unlock()
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", cached.status, http.StatusText(cached.status)),
+			StatusCode:    cached.status,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(cached.body)),
+			ContentLength: int64(len(cached.body)),
+			Request:       resp.Request,
+		}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK || !cacheable(resp.Header) {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.store(&entry{key: key, status: resp.StatusCode, header: resp.Header.Clone(), body: body})
+	return resp, nil
+}
+
+// cacheable reports whether a response has a validator and may be stored.
+func cacheable(h http.Header) bool {
+	if h.Get("ETag") == "" && h.Get("Last-Modified") == "" {
+		return false
+	}
+	return !strings.Contains(strings.ToLower(h.Get("Cache-Control")), "no-store")
+}
+
+// store adds e, evicting the least recently used entries past maxBytes.
+func (t *Transport) store(e *entry) {
+	if len(e.body) > t.maxBytes/4 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if el, ok := t.entries[e.key]; ok {
+		t.size -= len(el.Value.(*entry).body)
+		t.lru.Remove(el)
+	}
+	t.entries[e.key] = t.lru.PushFront(e)
+	t.size += len(e.body)
+	for t.size > t.maxBytes {
+		oldest := t.lru.Back()
+		old := oldest.Value.(*entry)
+		t.lru.Remove(oldest)
+		delete(t.entries, old.key)
+		t.size -= len(old.body)
+	}
+}
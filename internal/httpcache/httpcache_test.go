@@ -0,0 +1,90 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalRequests(t *testing.T) {
+	version := 1
+	notModified := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"v` + strconv.Itoa(version) + `"`
+		if r.URL.Path == "/plain" {
+			io.WriteString(w, "no validator")
+			return
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(100-notModified))
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("X-RateLimit-Remaining", "100")
+		io.WriteString(w, "body "+etag)
+	}))
+	defer server.Close()
+
+	cache := New(nil)
+	client := &http.Client{Transport: cache}
+	get := func(path, auth string) (string, *http.Response) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", auth)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return string(b), resp
+	}
+
+	body, _ := get("/pr", "alice")
+	assert.Equal(t, `body "v1"`, body)
+	body, resp := get("/pr", "alice")
+	assert.Equal(t, `body "v1"`, body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "a 304 is answered as the cached 200")
+	assert.Equal(t, "99", resp.Header.Get("X-RateLimit-Remaining"), "headers from the 304 win")
+	assert.Equal(t, 1, notModified)
+
+	body, _ = get("/pr", "bob")
+	assert.Equal(t, `body "v1"`, body)
+	assert.Equal(t, 1, notModified, "another identity does not share the entry")
+
+	version = 2
+	body, _ = get("/pr", "alice")
+	assert.Equal(t, `body "v2"`, body, "a changed resource is refetched")
+
+	get("/plain", "alice")
+	get("/plain", "alice")
+	assert.Equal(t, 1, notModified, "responses without a validator are not cached")
+	assert.Equal(t, 1, cache.Hits())
+}
+
+func TestEviction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"x"`)
+		io.WriteString(w, "0123456789")
+	}))
+	defer server.Close()
+
+	cache := New(nil)
+	cache.maxBytes = 40
+	client := &http.Client{Transport: cache}
+	for _, path := range []string{"/a", "/b", "/c", "/d", "/e"} {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Len(t, cache.entries, 4)
+	assert.Equal(t, 40, cache.size)
+	oldest, err := http.NewRequest(http.MethodGet, server.URL+"/a", nil)
+	require.NoError(t, err)
+	assert.NotContains(t, cache.entries, cacheKey(oldest), "the least recently used entry is evicted first")
+}
@@ -0,0 +1,187 @@
+// Package jira is a small client for the Jira REST API (v2), covering what
+// otto needs to follow an issue through a PR's lifecycle: reading an issue
+// and moving it to a named status.
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+)
+
+// keyPattern matches Jira issue keys such as "PROJ-123".
+var keyPattern = regexp.MustCompile(`\b([A-Z][A-Z0-9_]+-\d+)\b`)
+
+// Client talks to one Jira site.
+type Client struct {
+	baseURL    string
+	email      string
+	token      string
+	httpClient *http.Client
+}
+
+// New returns a client for the site in cfg.
+func New(cfg config.JiraConfig) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(cfg.BaseURL, "/"),
+		email:      cfg.Email,
+		token:      cfg.Token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Issue is the part of a Jira issue otto uses.
+type Issue struct {
+	Key         string
+	Summary     string
+	Description string
+	Status      string
+}
+
+// FindKeys returns the issue keys mentioned in texts, in order of first
+// mention. When projects is non-empty, only keys in those projects count.
+func FindKeys(projects []string, texts ...string) []string {
+	var keys []string
+	for _, text := range texts {
+		for _, m := range keyPattern.FindAllStringSubmatch(text, -1) {
+			key := m[1]
+			project := key[:strings.LastIndex(key, "-")]
+			if len(projects) > 0 && !slices.Contains(projects, project) {
+				continue
+			}
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// GetIssue fetches an issue by key.
+func (c *Client) GetIssue(ctx context.Context, key string) (*Issue, error) {
+	var raw struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary     string `json:"summary"`
+			Description string `json:"description"`
+			Status      struct {
+				Name string `json:"name"`
+			} `json:"status"`
+		} `json:"fields"`
+	}
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "?fields=summary,description,status"
+	if err := c.do(ctx, http.MethodGet, path, nil, &raw); err != nil {
+		return nil, fmt.Errorf("getting Jira issue %s: %w", key, err)
+	}
+	return &Issue{
+		Key:         raw.Key,
+		Summary:     raw.Fields.Summary,
+		Description: raw.Fields.Description,
+		Status:      raw.Fields.Status.Name,
+	}, nil
+}
+
+// Transition moves an issue to the named status, matched case-insensitively
+// against each available transition's target status and then its name. It
+// reports whether the issue moved; an issue already in the status does not.
+func (c *Client) Transition(ctx context.Context, key, status string) (bool, error) {
+	issue, err := c.GetIssue(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if strings.EqualFold(issue.Status, status) {
+		return false, nil
+	}
+
+	var list struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+	if err := c.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return false, fmt.Errorf("listing transitions for %s: %w", key, err)
+	}
+	id := ""
+	for _, t := range list.Transitions {
+		if strings.EqualFold(t.To.Name, status) {
+			id = t.ID
+			break
+		}
+	}
+	if id == "" {
+		for _, t := range list.Transitions {
+			if strings.EqualFold(t.Name, status) {
+				id = t.ID
+				break
+			}
+		}
+	}
+	if id == "" {
+		return false, fmt.Errorf("no transition from %q to %q for %s", issue.Status, status, key)
+	}
+
+	body := map[string]any{"transition": map[string]string{"id": id}}
+	if err := c.do(ctx, http.MethodPost, path, body, nil); err != nil {
+		return false, fmt.Errorf("transitioning %s to %q: %w", key, status, err)
+	}
+	return true, nil
+}
+
+// do sends a request and decodes a JSON response into out, if non-nil.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshaling request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// Jira Cloud takes an account email and API token; Server and Data
+	// Center take a personal access token as a bearer token.
+	if c.email != "" {
+		req.SetBasicAuth(c.email, c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
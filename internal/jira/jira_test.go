@@ -0,0 +1,78 @@
+package jira
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindKeys(t *testing.T) {
+	texts := []string{"PROJ-12: fix retries (see OPS-3)", "users/me/PROJ-12-retries", "utf-8 and Proj-4 are not keys"}
+	assert.Equal(t, []string{"PROJ-12", "OPS-3"}, FindKeys(nil, texts...))
+	assert.Equal(t, []string{"OPS-3"}, FindKeys([]string{"OPS"}, texts...))
+	assert.Empty(t, FindKeys(nil, "no keys here"))
+}
+
+func TestTransition(t *testing.T) {
+	status := "In Progress"
+	var posted string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/2/issue/PROJ-1", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "me@example.com", user)
+		assert.Equal(t, "secret", pass)
+		json.NewEncoder(w).Encode(map[string]any{
+			"key":    "PROJ-1",
+			"fields": map[string]any{"summary": "Retries", "description": "Add retries.", "status": map[string]string{"name": status}},
+		})
+	})
+	mux.HandleFunc("GET /rest/api/2/issue/PROJ-1/transitions", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"transitions": []map[string]any{
+			{"id": "21", "name": "Start review", "to": map[string]string{"name": "In Review"}},
+			{"id": "31", "name": "Done", "to": map[string]string{"name": "Closed"}},
+		}})
+	})
+	mux.HandleFunc("POST /rest/api/2/issue/PROJ-1/transitions", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Transition struct {
+				ID string `json:"id"`
+			} `json:"transition"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		posted = body.Transition.ID
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := New(config.JiraConfig{BaseURL: server.URL + "/", Email: "me@example.com", Token: "secret"})
+
+	issue, err := c.GetIssue(t.Context(), "PROJ-1")
+	require.NoError(t, err)
+	assert.Equal(t, &Issue{Key: "PROJ-1", Summary: "Retries", Description: "Add retries.", Status: "In Progress"}, issue)
+
+	moved, err := c.Transition(t.Context(), "PROJ-1", "in review")
+	require.NoError(t, err)
+	assert.True(t, moved)
+	assert.Equal(t, "21", posted, "matched by target status")
+
+	moved, err = c.Transition(t.Context(), "PROJ-1", "Done")
+	require.NoError(t, err)
+	assert.True(t, moved)
+	assert.Equal(t, "31", posted, "matched by transition name")
+
+	posted, status = "", "Done"
+	moved, err = c.Transition(t.Context(), "PROJ-1", "Done")
+	require.NoError(t, err)
+	assert.False(t, moved, "already in the status")
+	assert.Empty(t, posted)
+
+	_, err = c.Transition(t.Context(), "PROJ-1", "Blocked")
+	assert.ErrorContains(t, err, `no transition from "Done" to "Blocked"`)
+}
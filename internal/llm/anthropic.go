@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultAnthropicBaseURL = "https://api.anthropic.com"
+	anthropicVersion        = "2023-06-01"
+	anthropicMaxTokens      = 8192
+)
+
+// NewAnthropicClient creates an APIClient backed by the Anthropic Messages
+// API. An empty baseURL uses the public API.
+func NewAnthropicClient(model, baseURL, apiKey string) *APIClient {
+	if baseURL == "" {
+		baseURL = defaultAnthropicBaseURL
+	}
+	api := &anthropicAPI{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		http:    &http.Client{},
+	}
+	c := newAPIClient("anthropic", model, api)
+	c.checkFn = func() error {
+		if apiKey == "" {
+			return fmt.Errorf("anthropic backend requires models.endpoints.anthropic.api_key or ANTHROPIC_API_KEY")
+		}
+		return nil
+	}
+	return c
+}
+
+type anthropicAPI struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+type anthropicBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []anthropicBlock `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+func (a *anthropicAPI) complete(ctx context.Context, model string, history []chatTurn, tools []toolSpec) (chatTurn, error) {
+	req := struct {
+		Model     string             `json:"model"`
+		MaxTokens int                `json:"max_tokens"`
+		Messages  []anthropicMessage `json:"messages"`
+		Tools     []anthropicTool    `json:"tools,omitempty"`
+	}{
+		Model:     model,
+		MaxTokens: anthropicMaxTokens,
+		Messages:  toAnthropicMessages(history),
+	}
+	for _, t := range tools {
+		req.Tools = append(req.Tools, anthropicTool{Name: t.Name, Description: t.Description, InputSchema: t.Parameters})
+	}
+
+	var resp struct {
+		Content []anthropicBlock `json:"content"`
+	}
+	headers := map[string]string{
+		"x-api-key":         a.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+	if err := postJSON(ctx, a.http, a.baseURL+"/v1/messages", headers, req, &resp); err != nil {
+		return chatTurn{}, fmt.Errorf("anthropic API: %w", err)
+	}
+
+	reply := chatTurn{Role: "assistant"}
+	var text []string
+	for _, b := range resp.Content {
+		switch b.Type {
+		case "text":
+			text = append(text, b.Text)
+		case "tool_use":
+			reply.ToolCalls = append(reply.ToolCalls, toolCall{ID: b.ID, Name: b.Name, Args: b.Input})
+		}
+	}
+	reply.Content = strings.Join(text, "\n")
+	return reply, nil
+}
+
+// toAnthropicMessages converts history to Messages API form. Tool results
+// travel as tool_result blocks in a user message, and consecutive results
+// must share one message.
+func toAnthropicMessages(history []chatTurn) []anthropicMessage {
+	var msgs []anthropicMessage
+	for _, t := range history {
+		switch t.Role {
+		case "user":
+			msgs = append(msgs, anthropicMessage{Role: "user", Content: []anthropicBlock{{Type: "text", Text: t.Content}}})
+		case "assistant":
+			var blocks []anthropicBlock
+			if t.Content != "" {
+				blocks = append(blocks, anthropicBlock{Type: "text", Text: t.Content})
+			}
+			for _, call := range t.ToolCalls {
+				input := call.Args
+				if len(input) == 0 {
+					input = json.RawMessage(`{}`)
+				}
+				blocks = append(blocks, anthropicBlock{Type: "tool_use", ID: call.ID, Name: call.Name, Input: input})
+			}
+			msgs = append(msgs, anthropicMessage{Role: "assistant", Content: blocks})
+		case "tool":
+			result := anthropicBlock{Type: "tool_result", ToolUseID: t.ToolCallID, Content: t.Content}
+			if n := len(msgs); n > 0 && msgs[n-1].Role == "user" && msgs[n-1].Content[0].Type == "tool_result" {
+				msgs[n-1].Content = append(msgs[n-1].Content, result)
+				continue
+			}
+			msgs = append(msgs, anthropicMessage{Role: "user", Content: []anthropicBlock{result}})
+		}
+	}
+	return msgs
+}
@@ -0,0 +1,228 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxToolRounds bounds the tool-use loop of a single prompt.
+const maxToolRounds = 100
+
+// chatTurn is one message in a provider-neutral conversation.
+type chatTurn struct {
+	Role       string     // "user", "assistant", or "tool"
+	Content    string     // text content
+	ToolCalls  []toolCall // tool invocations requested by an assistant turn
+	ToolCallID string     // the call a tool turn answers
+}
+
+// toolCall is a tool invocation requested by the model.
+type toolCall struct {
+	ID   string
+	Name string
+	Args json.RawMessage
+}
+
+// chatCompleter sends a conversation to a stateless chat API and returns
+// the assistant's next turn. Each direct API backend implements one.
+type chatCompleter interface {
+	complete(ctx context.Context, model string, history []chatTurn, tools []toolSpec) (chatTurn, error)
+}
+
+// APIClient implements Client on top of a stateless chat-completion API.
+// It keeps each session's history in memory and, for sessions with a
+// working directory, runs the workspace tool loop on the model's behalf.
+type APIClient struct {
+	name     string // backend name, for logs and errors
+	model    string
+	api      chatCompleter
+	checkFn  func() error // optional configuration check run by Start
+	sessions map[string]*apiSession
+	nextID   int
+	mu       sync.Mutex
+}
+
+type apiSession struct {
+	title   string
+	workDir string
+	history []chatTurn
+	cancel  context.CancelFunc // cancels the in-flight prompt, if any
+	busy    sync.Mutex         // serializes prompts within the session
+}
+
+func newAPIClient(name, model string, api chatCompleter) *APIClient {
+	return &APIClient{
+		name:     name,
+		model:    model,
+		api:      api,
+		sessions: make(map[string]*apiSession),
+	}
+}
+
+// Start validates the client configuration. Direct API clients hold no
+// long-lived connection, so there is nothing else to start.
+func (c *APIClient) Start(_ context.Context) error {
+	if c.checkFn != nil {
+		if err := c.checkFn(); err != nil {
+			return err
+		}
+	}
+	slog.Info("LLM client ready", "backend", c.name, "model", c.model)
+	return nil
+}
+
+// Stop aborts in-flight prompts and drops all sessions.
+func (c *APIClient) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, s := range c.sessions {
+		if s.cancel != nil {
+			s.cancel()
+		}
+		delete(c.sessions, id)
+	}
+	return nil
+}
+
+func (c *APIClient) CreateSession(_ context.Context, title string, workDir string) (*SessionInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	id := fmt.Sprintf("%s-%d", c.name, c.nextID)
+	c.sessions[id] = &apiSession{title: title, workDir: workDir}
+	slog.Debug("creating LLM session", "backend", c.name, "title", title, "model", c.model, "workDir", workDir)
+	return &SessionInfo{ID: id, Title: title}, nil
+}
+
+func (c *APIClient) SendPrompt(ctx context.Context, sessionID string, prompt string) (*PromptResponse, error) {
+	c.mu.Lock()
+	s, ok := c.sessions[sessionID]
+	c.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+
+	s.busy.Lock()
+	defer s.busy.Unlock()
+
+	// Same ceiling as the Copilot client: tool-heavy fixes can take minutes.
+	promptCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+	c.mu.Lock()
+	s.cancel = cancel
+	c.mu.Unlock()
+
+	var tools []toolSpec
+	if s.workDir != "" {
+		tools = workspaceTools
+	}
+
+	s.history = append(s.history, chatTurn{Role: "user", Content: prompt})
+	for round := 0; round < maxToolRounds; round++ {
+		reply, err := c.api.complete(promptCtx, c.model, s.history, tools)
+		if err != nil {
+			return nil, fmt.Errorf("sending prompt: %w", err)
+		}
+		s.history = append(s.history, reply)
+		if len(reply.ToolCalls) == 0 {
+			slog.Info("LLM prompt completed", "backend", c.name, "session", sessionID, "response_length", len(reply.Content), "tool_rounds", round)
+			return &PromptResponse{Content: reply.Content, Model: c.model}, nil
+		}
+		for _, call := range reply.ToolCalls {
+			slog.Debug("running LLM tool call", "backend", c.name, "session", sessionID, "tool", call.Name)
+			reportProgress(ctx, Progress{Kind: ProgressTool, Text: describeToolCall(call.Name, call.Args)})
+			s.history = append(s.history, chatTurn{
+				Role:       "tool",
+				Content:    runTool(promptCtx, s.workDir, call),
+				ToolCallID: call.ID,
+			})
+		}
+	}
+	return nil, fmt.Errorf("sending prompt: exceeded %d tool rounds", maxToolRounds)
+}
+
+func (c *APIClient) GetMessages(_ context.Context, sessionID string) ([]Message, error) {
+	c.mu.Lock()
+	s, ok := c.sessions[sessionID]
+	c.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+
+	s.busy.Lock()
+	defer s.busy.Unlock()
+	var msgs []Message
+	for _, t := range s.history {
+		if t.Role == "tool" || t.Content == "" {
+			continue
+		}
+		msgs = append(msgs, Message{Role: t.Role, Content: t.Content})
+	}
+	return msgs, nil
+}
+
+func (c *APIClient) DeleteSession(_ context.Context, sessionID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.sessions[sessionID]; ok {
+		if s.cancel != nil {
+			s.cancel()
+		}
+		delete(c.sessions, sessionID)
+	}
+	return nil
+}
+
+func (c *APIClient) AbortSession(_ context.Context, sessionID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.sessions[sessionID]; ok && s.cancel != nil {
+		s.cancel()
+	}
+	return nil
+}
+
+// postJSON POSTs body as JSON to url and decodes the JSON response into out.
+// Non-2xx responses are returned as errors carrying the response body.
+func postJSON(ctx context.Context, hc *http.Client, url string, headers map[string]string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
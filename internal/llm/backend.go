@@ -0,0 +1,56 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alanmeadows/otto/internal/config"
+)
+
+// Backend is a Client with a lifecycle. Every configurable LLM backend
+// implements it.
+type Backend interface {
+	Client
+
+	// Start connects to or validates the backend. Call before any session.
+	Start(ctx context.Context) error
+
+	// Stop releases sessions and connections.
+	Stop() error
+}
+
+var (
+	_ Backend = (*CopilotClient)(nil)
+	_ Backend = (*OpenCodeClient)(nil)
+	_ Backend = (*APIClient)(nil)
+)
+
+// NewBackend builds the backend configured for role (config.RolePrimary,
+// config.RoleSecondary, config.RoleTertiary) using that role's model.
+// copilotServerURL, if set, points Copilot backends at a shared server
+// instead of spawning their own.
+func NewBackend(models config.ModelsConfig, role, copilotServerURL string) (Backend, error) {
+	model := models.ModelFor(role)
+	if model == "" {
+		return nil, fmt.Errorf("no model configured for role %q (models.%s)", role, role)
+	}
+
+	name := models.BackendFor(role)
+	ep := models.Endpoints[name]
+	switch name {
+	case config.BackendCopilot:
+		if copilotServerURL != "" {
+			return NewCopilotClientWithServer(model, copilotServerURL), nil
+		}
+		return NewCopilotClient(model), nil
+	case config.BackendOpenCode:
+		return NewOpenCodeClient(model, ep.BaseURL), nil
+	case config.BackendAnthropic:
+		return NewAnthropicClient(model, ep.BaseURL, ep.APIKey), nil
+	case config.BackendOpenAI:
+		return NewOpenAIClient(model, ep.BaseURL, ep.APIKey), nil
+	case config.BackendOllama:
+		return NewOllamaClient(model, ep.BaseURL), nil
+	}
+	return nil, fmt.Errorf("unknown LLM backend %q for role %q", name, role)
+}
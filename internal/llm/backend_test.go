@@ -0,0 +1,304 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBackend_SelectsPerRole(t *testing.T) {
+	models := config.ModelsConfig{
+		Primary:   "claude-sonnet-4",
+		Secondary: "gpt-4.1",
+		Tertiary:  "llama3.1",
+		Backend:   config.BackendAnthropic,
+		RoleBackends: map[string]string{
+			config.RoleSecondary: config.BackendOpenAI,
+			config.RoleTertiary:  config.BackendOllama,
+		},
+	}
+
+	b, err := NewBackend(models, config.RolePrimary, "")
+	require.NoError(t, err)
+	assert.Equal(t, "anthropic", b.(*APIClient).name)
+
+	b, err = NewBackend(models, config.RoleSecondary, "")
+	require.NoError(t, err)
+	assert.Equal(t, "openai", b.(*APIClient).name)
+
+	b, err = NewBackend(models, config.RoleTertiary, "")
+	require.NoError(t, err)
+	assert.Equal(t, "ollama", b.(*APIClient).name)
+
+	models.Backend = config.BackendCopilot
+	b, err = NewBackend(models, config.RolePrimary, "http://localhost:9999")
+	require.NoError(t, err)
+	assert.IsType(t, &CopilotClient{}, b)
+
+	models.Tertiary = ""
+	_, err = NewBackend(models, config.RoleTertiary, "")
+	assert.ErrorContains(t, err, "no model configured")
+}
+
+func TestAPIClient_StartRequiresKey(t *testing.T) {
+	assert.Error(t, NewAnthropicClient("m", "", "").Start(context.Background()))
+	assert.Error(t, NewOpenAIClient("m", "", "").Start(context.Background()))
+	assert.NoError(t, NewOpenAIClient("m", "http://localhost:8000/v1", "").Start(context.Background()))
+	assert.NoError(t, NewOllamaClient("m", "").Start(context.Background()))
+}
+
+func TestAnthropicClient_ToolLoop(t *testing.T) {
+	workDir := t.TempDir()
+	var requests []map[string]any
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "sk-test", r.Header.Get("x-api-key"))
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		if len(requests) == 1 {
+			json.NewEncoder(w).Encode(map[string]any{"content": []map[string]any{
+				{"type": "text", "text": "Writing the fix."},
+				{"type": "tool_use", "id": "tu_1", "name": "write_file", "input": map[string]string{"path": "fix.txt", "content": "fixed"}},
+			}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"content": []map[string]any{
+			{"type": "text", "text": "Done."},
+		}})
+	}))
+	defer ts.Close()
+
+	c := NewAnthropicClient("claude-sonnet-4", ts.URL, "sk-test")
+	require.NoError(t, c.Start(context.Background()))
+	ctx := context.Background()
+	s, err := c.CreateSession(ctx, "fix", workDir)
+	require.NoError(t, err)
+
+	var progress []Progress
+	progressCtx := WithProgress(ctx, func(p Progress) { progress = append(progress, p) })
+	resp, err := c.SendPrompt(progressCtx, s.ID, "fix the build")
+	require.NoError(t, err)
+	assert.Equal(t, "Done.", resp.Content)
+	assert.Equal(t, []Progress{{Kind: ProgressTool, Text: "write_file fix.txt"}}, progress)
+
+	data, err := os.ReadFile(filepath.Join(workDir, "fix.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "fixed", string(data))
+
+	require.Len(t, requests, 2)
+	assert.Len(t, requests[0]["tools"], len(workspaceTools))
+	msgs := requests[1]["messages"].([]any)
+	require.Len(t, msgs, 3)
+	result := msgs[2].(map[string]any)["content"].([]any)[0].(map[string]any)
+	assert.Equal(t, "tool_result", result["type"])
+	assert.Equal(t, "tu_1", result["tool_use_id"])
+
+	history, err := c.GetMessages(ctx, s.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []Message{
+		{Role: "user", Content: "fix the build"},
+		{Role: "assistant", Content: "Writing the fix."},
+		{Role: "assistant", Content: "Done."},
+	}, history)
+}
+
+func TestOpenAIClient_ToolLoop(t *testing.T) {
+	workDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "main.go"), []byte("package main"), 0644))
+	calls := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		var req struct {
+			Messages []openAIMessage `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		calls++
+
+		if calls == 1 {
+			json.NewEncoder(w).Encode(map[string]any{"choices": []map[string]any{{"message": map[string]any{
+				"role": "assistant",
+				"tool_calls": []map[string]any{{
+					"id": "call_1", "type": "function",
+					"function": map[string]string{"name": "read_file", "arguments": `{"path":"main.go"}`},
+				}},
+			}}}})
+			return
+		}
+		last := req.Messages[len(req.Messages)-1]
+		assert.Equal(t, "tool", last.Role)
+		assert.Equal(t, "call_1", last.ToolCallID)
+		json.NewEncoder(w).Encode(map[string]any{"choices": []map[string]any{{"message": map[string]any{
+			"role": "assistant", "content": "read: " + last.Content,
+		}}}})
+	}))
+	defer ts.Close()
+
+	c := NewOpenAIClient("gpt-4.1", ts.URL, "sk-test")
+	ctx := context.Background()
+	s, err := c.CreateSession(ctx, "review", workDir)
+	require.NoError(t, err)
+
+	resp, err := c.SendPrompt(ctx, s.ID, "look at main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "read: package main", resp.Content)
+	assert.Equal(t, 2, calls)
+}
+
+func TestAPIClient_NoToolsWithoutWorkDir(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.NotContains(t, req, "tools")
+		json.NewEncoder(w).Encode(map[string]any{"choices": []map[string]any{{"message": map[string]any{
+			"role": "assistant", "content": "ok",
+		}}}})
+	}))
+	defer ts.Close()
+
+	c := NewOllamaClient("llama3.1", ts.URL)
+	ctx := context.Background()
+	s, err := c.CreateSession(ctx, "describe", "")
+	require.NoError(t, err)
+	resp, err := c.SendPrompt(ctx, s.ID, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Content)
+}
+
+func TestAPIClient_HTTPError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"overloaded"}`, http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c := NewAnthropicClient("m", ts.URL, "k")
+	ctx := context.Background()
+	s, err := c.CreateSession(ctx, "x", "")
+	require.NoError(t, err)
+	_, err = c.SendPrompt(ctx, s.ID, "hi")
+	assert.ErrorContains(t, err, "503")
+	assert.ErrorContains(t, err, "overloaded")
+}
+
+func TestOpenCodeClient(t *testing.T) {
+	var gotModel map[string]any
+	var deleted bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /session", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("POST /session", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/work/repo", r.URL.Query().Get("directory"))
+		w.Write([]byte(`{"id":"ses_1"}`))
+	})
+	mux.HandleFunc("POST /session/ses_1/message", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/work/repo", r.URL.Query().Get("directory"))
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		gotModel, _ = req["model"].(map[string]any)
+		w.Write([]byte(`{"info":{"role":"assistant"},"parts":[{"type":"step-start"},{"type":"text","text":"fixed it"}]}`))
+	})
+	mux.HandleFunc("DELETE /session/ses_1", func(w http.ResponseWriter, r *http.Request) {
+		deleted = true
+		w.Write([]byte(`true`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	c := NewOpenCodeClient("anthropic/claude-sonnet-4", ts.URL)
+	ctx := context.Background()
+	require.NoError(t, c.Start(ctx))
+
+	s, err := c.CreateSession(ctx, "fix", "/work/repo")
+	require.NoError(t, err)
+	assert.Equal(t, "ses_1", s.ID)
+
+	resp, err := c.SendPrompt(ctx, s.ID, "fix the build")
+	require.NoError(t, err)
+	assert.Equal(t, "fixed it", resp.Content)
+	assert.Equal(t, map[string]any{"providerID": "anthropic", "modelID": "claude-sonnet-4"}, gotModel)
+
+	require.NoError(t, c.DeleteSession(ctx, s.ID))
+	assert.True(t, deleted)
+}
+
+func TestOpenCodeClient_PruneSessions(t *testing.T) {
+	var deleted []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /session", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/work/repo", r.URL.Query().Get("directory"))
+		w.Write([]byte(`[{"id":"ses_1","title":"PR Fix #7 attempt 1"},{"id":"ses_2","title":"other"}]`))
+	})
+	mux.HandleFunc("DELETE /session/{id}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/work/repo", r.URL.Query().Get("directory"))
+		deleted = append(deleted, r.PathValue("id"))
+		w.Write([]byte(`true`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	c := NewOpenCodeClient("", ts.URL)
+	n, err := c.PruneSessions(context.Background(), "/work/repo", func(title string) bool {
+		return strings.HasPrefix(title, "PR Fix #7")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"ses_1"}, deleted)
+}
+
+func TestWorkspacePath_RejectsEscape(t *testing.T) {
+	dir := t.TempDir()
+	p, err := workspacePath(dir, "src/main.go")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "src", "main.go"), p)
+
+	_, err = workspacePath(dir, "../outside.txt")
+	assert.Error(t, err)
+	_, err = workspacePath(dir, "/etc/passwd")
+	assert.Error(t, err)
+
+	out := runTool(context.Background(), dir, toolCall{Name: "write_file", Args: json.RawMessage(`{"path":"../x","content":"y"}`)})
+	assert.Contains(t, out, "outside the working directory")
+}
+
+func TestOpenAIEmbedder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embeddings", r.URL.Path)
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "nomic-embed-text", req.Model)
+		assert.Equal(t, []string{"a", "b"}, req.Input)
+		// Out of order, as the API allows.
+		json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{
+			{"index": 1, "embedding": []float32{0, 1}},
+			{"index": 0, "embedding": []float32{1, 0}},
+		}})
+	}))
+	defer ts.Close()
+
+	models := config.ModelsConfig{Endpoints: map[string]config.EndpointConfig{config.BackendOllama: {BaseURL: ts.URL}}}
+	e, err := NewEmbedder(models, config.BackendOllama, "nomic-embed-text")
+	require.NoError(t, err)
+	vectors, err := e.Embed(context.Background(), []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, vectors)
+
+	_, err = NewEmbedder(config.ModelsConfig{}, config.BackendAnthropic, "x")
+	assert.Error(t, err)
+}
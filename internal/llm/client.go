@@ -117,6 +117,22 @@ func (c *CopilotClient) SendPrompt(ctx context.Context, sessionID string, prompt
 	promptCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
 	defer cancel()
 
+	if hasProgress(ctx) {
+		unsubscribe := session.On(func(evt sdk.SessionEvent) {
+			switch evt.Type {
+			case sdk.ToolExecutionStart:
+				if evt.Data.ToolName != nil {
+					reportProgress(ctx, Progress{Kind: ProgressTool, Text: describeToolCall(*evt.Data.ToolName, evt.Data.Arguments)})
+				}
+			case sdk.AssistantIntent:
+				if evt.Data.Intent != nil {
+					reportProgress(ctx, Progress{Kind: ProgressIntent, Text: *evt.Data.Intent})
+				}
+			}
+		})
+		defer unsubscribe()
+	}
+
 	resp, err := session.SendAndWait(promptCtx, sdk.MessageOptions{
 		Prompt: prompt,
 	})
@@ -134,7 +150,7 @@ func (c *CopilotClient) SendPrompt(ctx context.Context, sessionID string, prompt
 		slog.Debug("LLM response preview", "first_200", content[:min(200, len(content))])
 	}
 
-	return &PromptResponse{Content: content}, nil
+	return &PromptResponse{Content: content, Model: c.model}, nil
 }
 
 func (c *CopilotClient) GetMessages(_ context.Context, sessionID string) ([]Message, error) {
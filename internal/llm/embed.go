@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/alanmeadows/otto/internal/config"
+)
+
+// Embedder turns texts into embedding vectors.
+type Embedder interface {
+	// Model names the embedding model, e.g. to key cached vectors.
+	Model() string
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewEmbedder returns an Embedder for model served by backend's endpoint in
+// models.Endpoints: "openai" (the default) or "ollama", both through the
+// OpenAI-compatible embeddings API.
+func NewEmbedder(models config.ModelsConfig, backend, model string) (Embedder, error) {
+	if backend == "" {
+		backend = config.BackendOpenAI
+	}
+	ep := models.Endpoints[backend]
+	switch backend {
+	case config.BackendOpenAI:
+		if ep.BaseURL == "" && ep.APIKey == "" {
+			return nil, fmt.Errorf("openai embeddings require models.endpoints.openai.api_key or OPENAI_API_KEY")
+		}
+		if ep.BaseURL == "" {
+			ep.BaseURL = defaultOpenAIBaseURL
+		}
+	case config.BackendOllama:
+		if ep.BaseURL == "" {
+			ep.BaseURL = defaultOllamaBaseURL
+		}
+	default:
+		return nil, fmt.Errorf("backend %q does not serve embeddings (use openai or ollama)", backend)
+	}
+	return &openAIEmbedder{api: newOpenAIAPI(ep.BaseURL, ep.APIKey), model: model}, nil
+}
+
+type openAIEmbedder struct {
+	api   *openAIAPI
+	model string
+}
+
+func (e *openAIEmbedder) Model() string { return e.model }
+
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	req := struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}{Model: e.model, Input: texts}
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	headers := map[string]string{}
+	if e.api.apiKey != "" {
+		headers["Authorization"] = "Bearer " + e.api.apiKey
+	}
+	if err := postJSON(ctx, e.api.http, e.api.baseURL+"/embeddings", headers, req, &resp); err != nil {
+		return nil, fmt.Errorf("embeddings API: %w", err)
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("embeddings API: got %d vectors for %d inputs", len(resp.Data), len(texts))
+	}
+	sort.Slice(resp.Data, func(i, j int) bool { return resp.Data[i].Index < resp.Data[j].Index })
+	vectors := make([][]float32, len(resp.Data))
+	for i, d := range resp.Data {
+		vectors[i] = d.Embedding
+	}
+	return vectors, nil
+}
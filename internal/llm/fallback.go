@@ -0,0 +1,371 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+)
+
+// FallbackClient runs prompts against an ordered chain of model roles. A
+// failed or timed-out prompt is retried on the same role, then moved to the
+// next role in the chain. Backends are started lazily, so fallback roles
+// cost nothing until they are needed.
+type FallbackClient struct {
+	tiers         []*fallbackTier
+	retries       int
+	backoff       time.Duration
+	promptTimeout time.Duration
+	sessions      map[string]*fallbackSession
+	nextID        int
+	mu            sync.Mutex
+}
+
+type fallbackTier struct {
+	role     string
+	model    string
+	backend  Backend
+	started  bool
+	startErr error
+	mu       sync.Mutex
+}
+
+// fallbackSession maps a caller-visible session onto a session on the tier
+// currently serving it. The transcript lets a later tier pick up a
+// conversation an earlier tier started.
+type fallbackSession struct {
+	title      string
+	workDir    string
+	transcript []Message
+	busy       sync.Mutex // serializes prompts within the session
+
+	mu      sync.Mutex // guards tier and innerID, which Abort reads mid-prompt
+	tier    int
+	innerID string // session on tiers[tier]; "" until opened
+}
+
+func (s *fallbackSession) current() (tier int, innerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tier, s.innerID
+}
+
+func (s *fallbackSession) set(tier int, innerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tier, s.innerID = tier, innerID
+}
+
+var _ Backend = (*FallbackClient)(nil)
+
+// NewFallbackClient builds a FallbackClient from models.fallback. Roles in
+// the chain with no configured model are skipped, as are repeats of an
+// earlier role's backend and model.
+func NewFallbackClient(models config.ModelsConfig, copilotServerURL string) (*FallbackClient, error) {
+	chain := models.Fallback.Chain
+	if len(chain) == 0 {
+		chain = []string{config.RolePrimary}
+	}
+
+	c := &FallbackClient{
+		retries:       models.Fallback.Retries,
+		backoff:       models.Fallback.ParseRetryBackoff(),
+		promptTimeout: models.Fallback.ParsePromptTimeout(),
+		sessions:      make(map[string]*fallbackSession),
+	}
+	seen := make(map[string]bool)
+	for _, role := range chain {
+		model := models.ModelFor(role)
+		if model == "" {
+			continue
+		}
+		key := models.BackendFor(role) + "\x00" + model
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		b, err := NewBackend(models, role, copilotServerURL)
+		if err != nil {
+			return nil, err
+		}
+		c.tiers = append(c.tiers, &fallbackTier{role: role, model: model, backend: b})
+	}
+	if len(c.tiers) == 0 {
+		return nil, fmt.Errorf("models.fallback.chain has no role with a configured model")
+	}
+	return c, nil
+}
+
+// Start starts the first tier that can be started. Later tiers start on
+// first use.
+func (c *FallbackClient) Start(ctx context.Context) error {
+	var errs []error
+	for _, t := range c.tiers {
+		err := t.start(ctx)
+		if err == nil {
+			return nil
+		}
+		slog.Warn("LLM backend unavailable, trying next in fallback chain", "role", t.role, "model", t.model, "error", err)
+		errs = append(errs, fmt.Errorf("%s: %w", t.role, err))
+	}
+	return errors.Join(errs...)
+}
+
+// Stop stops every tier that was started.
+func (c *FallbackClient) Stop() error {
+	c.mu.Lock()
+	c.sessions = make(map[string]*fallbackSession)
+	c.mu.Unlock()
+
+	var errs []error
+	for _, t := range c.tiers {
+		t.mu.Lock()
+		if t.started {
+			errs = append(errs, t.backend.Stop())
+			t.started = false
+		}
+		t.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+func (t *fallbackTier) start(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.started {
+		return nil
+	}
+	if t.startErr != nil {
+		return t.startErr
+	}
+	if err := t.backend.Start(ctx); err != nil {
+		t.startErr = err
+		return err
+	}
+	t.started = true
+	return nil
+}
+
+func (c *FallbackClient) CreateSession(ctx context.Context, title string, workDir string) (*SessionInfo, error) {
+	s := &fallbackSession{title: title, workDir: workDir}
+
+	// Open the session eagerly on the first usable tier so creation errors
+	// surface here, as they do for a plain backend.
+	var errs []error
+	for tier := range c.tiers {
+		innerID, err := c.openInner(ctx, tier, s)
+		if err == nil {
+			s.set(tier, innerID)
+			break
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", c.tiers[tier].role, err))
+	}
+	if _, innerID := s.current(); innerID == "" {
+		return nil, fmt.Errorf("creating session: %w", errors.Join(errs...))
+	}
+
+	c.mu.Lock()
+	c.nextID++
+	id := fmt.Sprintf("fallback-%d", c.nextID)
+	c.sessions[id] = s
+	c.mu.Unlock()
+	return &SessionInfo{ID: id, Title: title}, nil
+}
+
+// openInner starts the given tier and opens a session on it for s.
+func (c *FallbackClient) openInner(ctx context.Context, tier int, s *fallbackSession) (string, error) {
+	t := c.tiers[tier]
+	if err := t.start(ctx); err != nil {
+		return "", err
+	}
+	info, err := t.backend.CreateSession(ctx, s.title, s.workDir)
+	if err != nil {
+		return "", err
+	}
+	return info.ID, nil
+}
+
+func (c *FallbackClient) SendPrompt(ctx context.Context, sessionID string, prompt string) (*PromptResponse, error) {
+	s, err := c.session(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	s.busy.Lock()
+	defer s.busy.Unlock()
+
+	tier, innerID := s.current()
+	var lastErr error
+	for ; tier < len(c.tiers); tier++ {
+		t := c.tiers[tier]
+		p := prompt
+		if innerID == "" {
+			var err error
+			if innerID, err = c.openInner(ctx, tier, s); err != nil {
+				slog.Warn("LLM fallback tier unavailable", "role", t.role, "model", t.model, "error", err)
+				lastErr = err
+				continue
+			}
+			s.set(tier, innerID)
+			// A fresh session on a later tier needs the conversation so far.
+			if len(s.transcript) > 0 {
+				p = withTranscript(s.transcript, prompt)
+			}
+		}
+
+		for attempt := 0; attempt <= c.retries; attempt++ {
+			if attempt > 0 {
+				select {
+				case <-ctx.Done():
+					return nil, fmt.Errorf("sending prompt: %w", errors.Join(lastErr, ctx.Err()))
+				case <-time.After(c.backoff):
+				}
+			}
+
+			resp, err := c.attempt(ctx, t, innerID, p)
+			if err == nil {
+				if resp.Model == "" {
+					resp.Model = t.model
+				}
+				s.transcript = append(s.transcript,
+					Message{Role: "user", Content: prompt},
+					Message{Role: "assistant", Content: resp.Content})
+				return resp, nil
+			}
+			lastErr = err
+
+			// The caller's own deadline or cancellation ends the chain:
+			// another model would not get any more time.
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			slog.Warn("LLM prompt failed", "role", t.role, "model", t.model, "attempt", attempt+1, "error", err)
+		}
+
+		// Hand the conversation to the next tier.
+		_ = t.backend.DeleteSession(context.WithoutCancel(ctx), innerID)
+		innerID = ""
+		s.set(tier, "")
+		if tier+1 < len(c.tiers) {
+			next := c.tiers[tier+1]
+			slog.Warn("falling back to next model", "from", t.model, "to", next.model, "role", next.role)
+		}
+	}
+
+	// Every tier is exhausted; leave the session on the last one so a
+	// later prompt can try it again.
+	s.set(len(c.tiers)-1, "")
+	return nil, fmt.Errorf("all models in the fallback chain failed: %w", lastErr)
+}
+
+// attempt sends one prompt on tier t, bounded by the per-attempt timeout.
+// A timed-out prompt is aborted so the session can be reused for a retry.
+func (c *FallbackClient) attempt(ctx context.Context, t *fallbackTier, innerID, prompt string) (*PromptResponse, error) {
+	attemptCtx := ctx
+	if c.promptTimeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, c.promptTimeout)
+		defer cancel()
+	}
+	resp, err := t.backend.SendPrompt(attemptCtx, innerID, prompt)
+	if err != nil && attemptCtx.Err() != nil && ctx.Err() == nil {
+		_ = t.backend.AbortSession(ctx, innerID)
+		return nil, fmt.Errorf("prompt timed out after %s: %w", c.promptTimeout, err)
+	}
+	return resp, err
+}
+
+// withTranscript prefixes prompt with the earlier exchanges of a session
+// that is moving to a new model.
+func withTranscript(transcript []Message, prompt string) string {
+	var b strings.Builder
+	b.WriteString("This conversation is being continued from another model. The earlier exchange was:\n\n")
+	for _, m := range transcript {
+		fmt.Fprintf(&b, "### %s\n\n%s\n\n", m.Role, m.Content)
+	}
+	b.WriteString("---\n\n")
+	b.WriteString(prompt)
+	return b.String()
+}
+
+func (c *FallbackClient) session(id string) (*fallbackSession, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+	return s, nil
+}
+
+func (c *FallbackClient) GetMessages(_ context.Context, sessionID string) ([]Message, error) {
+	s, err := c.session(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	s.busy.Lock()
+	defer s.busy.Unlock()
+	msgs := make([]Message, len(s.transcript))
+	copy(msgs, s.transcript)
+	return msgs, nil
+}
+
+func (c *FallbackClient) DeleteSession(ctx context.Context, sessionID string) error {
+	c.mu.Lock()
+	s, ok := c.sessions[sessionID]
+	delete(c.sessions, sessionID)
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	// Abort first so a prompt still holding the session lock returns.
+	if tier, id := s.current(); id != "" {
+		_ = c.tiers[tier].backend.AbortSession(ctx, id)
+	}
+	s.busy.Lock()
+	defer s.busy.Unlock()
+	tier, id := s.current()
+	if id == "" {
+		return nil
+	}
+	return c.tiers[tier].backend.DeleteSession(ctx, id)
+}
+
+// PruneSessions prunes matching sessions on every started tier whose
+// backend keeps sessions server-side.
+func (c *FallbackClient) PruneSessions(ctx context.Context, dir string, match func(title string) bool) (int, error) {
+	total := 0
+	var errs []error
+	for _, t := range c.tiers {
+		t.mu.Lock()
+		started := t.started
+		t.mu.Unlock()
+		pruner, ok := t.backend.(SessionPruner)
+		if !started || !ok {
+			continue
+		}
+		n, err := pruner.PruneSessions(ctx, dir, match)
+		total += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.role, err))
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+func (c *FallbackClient) AbortSession(ctx context.Context, sessionID string) error {
+	s, err := c.session(sessionID)
+	if err != nil {
+		return nil
+	}
+	if tier, id := s.current(); id != "" {
+		return c.tiers[tier].backend.AbortSession(ctx, id)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedBackend is a Backend whose prompts fail a set number of times
+// before succeeding.
+type scriptedBackend struct {
+	*MockClient
+	failures int32 // remaining prompts that fail
+	hang     bool  // block until ctx is done instead of failing
+	startErr error
+	calls    atomic.Int32
+}
+
+func newScriptedBackend(reply string, failures int32) *scriptedBackend {
+	m := NewMockClient()
+	m.DefaultResult = reply
+	return &scriptedBackend{MockClient: m, failures: failures}
+}
+
+func (b *scriptedBackend) Start(context.Context) error { return b.startErr }
+func (b *scriptedBackend) Stop() error                 { return nil }
+
+func (b *scriptedBackend) SendPrompt(ctx context.Context, sessionID, prompt string) (*PromptResponse, error) {
+	b.calls.Add(1)
+	if b.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if atomic.AddInt32(&b.failures, -1) >= 0 {
+		return nil, errors.New("model overloaded")
+	}
+	return b.MockClient.SendPrompt(ctx, sessionID, prompt)
+}
+
+func newTestFallback(retries int, tiers ...*scriptedBackend) *FallbackClient {
+	c := &FallbackClient{
+		retries:  retries,
+		backoff:  time.Millisecond,
+		sessions: make(map[string]*fallbackSession),
+	}
+	roles := []string{config.RolePrimary, config.RoleSecondary, config.RoleTertiary}
+	for i, b := range tiers {
+		c.tiers = append(c.tiers, &fallbackTier{role: roles[i], model: "model-" + roles[i], backend: b})
+	}
+	return c
+}
+
+func TestFallbackClient_RetriesThenSucceeds(t *testing.T) {
+	primary := newScriptedBackend("from primary", 1)
+	secondary := newScriptedBackend("from secondary", 0)
+	c := newTestFallback(1, primary, secondary)
+	ctx := context.Background()
+	require.NoError(t, c.Start(ctx))
+
+	s, err := c.CreateSession(ctx, "fix", "/tmp")
+	require.NoError(t, err)
+	resp, err := c.SendPrompt(ctx, s.ID, "fix it")
+	require.NoError(t, err)
+	assert.Equal(t, "from primary", resp.Content)
+	assert.Equal(t, "model-primary", resp.Model)
+	assert.EqualValues(t, 2, primary.calls.Load())
+	assert.Zero(t, secondary.calls.Load())
+}
+
+func TestFallbackClient_FallsBackWithTranscript(t *testing.T) {
+	primary := newScriptedBackend("evaluated", 0)
+	secondary := newScriptedBackend("fixed", 0)
+	c := newTestFallback(0, primary, secondary)
+	ctx := context.Background()
+
+	s, err := c.CreateSession(ctx, "merlinbot", "/tmp")
+	require.NoError(t, err)
+	_, err = c.SendPrompt(ctx, s.ID, "evaluate comments")
+	require.NoError(t, err)
+
+	// Primary starts failing mid-conversation.
+	primary.failures = 5
+	resp, err := c.SendPrompt(ctx, s.ID, "apply fix")
+	require.NoError(t, err)
+	assert.Equal(t, "fixed", resp.Content)
+	assert.Equal(t, "model-secondary", resp.Model)
+
+	history := secondary.GetPromptHistory()
+	require.Len(t, history, 1)
+	assert.Contains(t, history[0].Prompt, "evaluate comments")
+	assert.Contains(t, history[0].Prompt, "evaluated")
+	assert.Contains(t, history[0].Prompt, "apply fix")
+
+	// The primary session was cleaned up; later prompts stay on secondary.
+	assert.Empty(t, primary.Sessions)
+	_, err = c.SendPrompt(ctx, s.ID, "again")
+	require.NoError(t, err)
+	assert.Len(t, secondary.GetPromptHistory(), 2)
+
+	msgs, err := c.GetMessages(ctx, s.ID)
+	require.NoError(t, err)
+	assert.Len(t, msgs, 6)
+}
+
+func TestFallbackClient_PromptTimeout(t *testing.T) {
+	primary := newScriptedBackend("", 0)
+	primary.hang = true
+	secondary := newScriptedBackend("fast", 0)
+	c := newTestFallback(0, primary, secondary)
+	c.promptTimeout = 20 * time.Millisecond
+	ctx := context.Background()
+
+	s, err := c.CreateSession(ctx, "fix", "")
+	require.NoError(t, err)
+	resp, err := c.SendPrompt(ctx, s.ID, "go")
+	require.NoError(t, err)
+	assert.Equal(t, "fast", resp.Content)
+}
+
+func TestFallbackClient_CallerDeadlineStopsChain(t *testing.T) {
+	primary := newScriptedBackend("", 0)
+	primary.hang = true
+	secondary := newScriptedBackend("never", 0)
+	c := newTestFallback(2, primary, secondary)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	s, err := c.CreateSession(ctx, "fix", "")
+	require.NoError(t, err)
+	_, err = c.SendPrompt(ctx, s.ID, "go")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.EqualValues(t, 1, primary.calls.Load())
+	assert.Zero(t, secondary.calls.Load())
+}
+
+func TestFallbackClient_AllFail(t *testing.T) {
+	c := newTestFallback(1, newScriptedBackend("", 10), newScriptedBackend("", 10))
+	ctx := context.Background()
+	s, err := c.CreateSession(ctx, "fix", "")
+	require.NoError(t, err)
+	_, err = c.SendPrompt(ctx, s.ID, "go")
+	assert.ErrorContains(t, err, "all models in the fallback chain failed")
+	assert.ErrorContains(t, err, "model overloaded")
+}
+
+func TestFallbackClient_SkipsUnstartableTier(t *testing.T) {
+	primary := newScriptedBackend("", 0)
+	primary.startErr = errors.New("no API key")
+	secondary := newScriptedBackend("ok", 0)
+	c := newTestFallback(0, primary, secondary)
+	ctx := context.Background()
+	require.NoError(t, c.Start(ctx))
+
+	s, err := c.CreateSession(ctx, "fix", "")
+	require.NoError(t, err)
+	resp, err := c.SendPrompt(ctx, s.ID, "go")
+	require.NoError(t, err)
+	assert.Equal(t, "model-secondary", resp.Model)
+}
+
+func TestNewFallbackClient_Chain(t *testing.T) {
+	models := config.DefaultConfig().Models
+	c, err := NewFallbackClient(models, "")
+	require.NoError(t, err)
+	// Tertiary has no model by default and is skipped.
+	require.Len(t, c.tiers, 2)
+	assert.Equal(t, models.Primary, c.tiers[0].model)
+	assert.Equal(t, models.Secondary, c.tiers[1].model)
+
+	models.Secondary = models.Primary
+	c, err = NewFallbackClient(models, "")
+	require.NoError(t, err)
+	assert.Len(t, c.tiers, 1, "duplicate backend+model is tried once")
+
+	models.Fallback.Chain = []string{config.RoleTertiary}
+	_, err = NewFallbackClient(models, "")
+	assert.Error(t, err)
+}
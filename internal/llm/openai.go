@@ -0,0 +1,135 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOllamaBaseURL = "http://localhost:11434/v1"
+)
+
+// NewOpenAIClient creates an APIClient backed by an OpenAI-compatible chat
+// completions endpoint (OpenAI, Azure OpenAI proxies, vLLM, LM Studio, ...).
+// An empty baseURL uses the public OpenAI API, which requires apiKey.
+func NewOpenAIClient(model, baseURL, apiKey string) *APIClient {
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	c := newAPIClient("openai", model, newOpenAIAPI(baseURL, apiKey))
+	c.checkFn = func() error {
+		if apiKey == "" && baseURL == defaultOpenAIBaseURL {
+			return fmt.Errorf("openai backend requires models.endpoints.openai.api_key or OPENAI_API_KEY")
+		}
+		return nil
+	}
+	return c
+}
+
+// NewOllamaClient creates an APIClient backed by a local Ollama server via
+// its OpenAI-compatible endpoint. An empty baseURL uses localhost:11434.
+func NewOllamaClient(model, baseURL string) *APIClient {
+	if baseURL == "" {
+		baseURL = defaultOllamaBaseURL
+	}
+	return newAPIClient("ollama", model, newOpenAIAPI(baseURL, ""))
+}
+
+type openAIAPI struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+func newOpenAIAPI(baseURL, apiKey string) *openAIAPI {
+	return &openAIAPI{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		http:    &http.Client{},
+	}
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    string           `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openAITool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description"`
+		Parameters  map[string]any `json:"parameters"`
+	} `json:"function"`
+}
+
+func (o *openAIAPI) complete(ctx context.Context, model string, history []chatTurn, tools []toolSpec) (chatTurn, error) {
+	req := struct {
+		Model    string          `json:"model"`
+		Messages []openAIMessage `json:"messages"`
+		Tools    []openAITool    `json:"tools,omitempty"`
+	}{Model: model}
+
+	for _, t := range history {
+		msg := openAIMessage{Role: t.Role, Content: t.Content, ToolCallID: t.ToolCallID}
+		for _, call := range t.ToolCalls {
+			tc := openAIToolCall{ID: call.ID, Type: "function"}
+			tc.Function.Name = call.Name
+			tc.Function.Arguments = string(call.Args)
+			if tc.Function.Arguments == "" {
+				tc.Function.Arguments = "{}"
+			}
+			msg.ToolCalls = append(msg.ToolCalls, tc)
+		}
+		req.Messages = append(req.Messages, msg)
+	}
+	for _, t := range tools {
+		tool := openAITool{Type: "function"}
+		tool.Function.Name = t.Name
+		tool.Function.Description = t.Description
+		tool.Function.Parameters = t.Parameters
+		req.Tools = append(req.Tools, tool)
+	}
+
+	var resp struct {
+		Choices []struct {
+			Message openAIMessage `json:"message"`
+		} `json:"choices"`
+	}
+	headers := map[string]string{}
+	if o.apiKey != "" {
+		headers["Authorization"] = "Bearer " + o.apiKey
+	}
+	if err := postJSON(ctx, o.http, o.baseURL+"/chat/completions", headers, req, &resp); err != nil {
+		return chatTurn{}, fmt.Errorf("chat completions API: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return chatTurn{}, fmt.Errorf("chat completions API: response has no choices")
+	}
+
+	msg := resp.Choices[0].Message
+	reply := chatTurn{Role: "assistant", Content: msg.Content}
+	for _, tc := range msg.ToolCalls {
+		reply.ToolCalls = append(reply.ToolCalls, toolCall{
+			ID:   tc.ID,
+			Name: tc.Function.Name,
+			Args: json.RawMessage(tc.Function.Arguments),
+		})
+	}
+	return reply, nil
+}
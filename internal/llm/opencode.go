@@ -0,0 +1,208 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultOpenCodeBaseURL = "http://127.0.0.1:4096"
+
+// OpenCodeClient implements Client against a running `opencode serve`
+// instance. OpenCode runs its own agent loop and tools, so sessions are
+// created and driven entirely server-side.
+type OpenCodeClient struct {
+	baseURL string
+	model   string
+	http    *http.Client
+	dirs    map[string]string // sessionID -> working directory
+	mu      sync.Mutex
+}
+
+// NewOpenCodeClient creates an OpenCodeClient. model is "provider/model"
+// (e.g. "anthropic/claude-sonnet-4"); a bare or empty model uses the
+// server's default. An empty baseURL uses 127.0.0.1:4096.
+func NewOpenCodeClient(model, baseURL string) *OpenCodeClient {
+	if baseURL == "" {
+		baseURL = defaultOpenCodeBaseURL
+	}
+	return &OpenCodeClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		http:    &http.Client{},
+		dirs:    make(map[string]string),
+	}
+}
+
+// Start checks that the OpenCode server is reachable.
+func (c *OpenCodeClient) Start(ctx context.Context) error {
+	if err := c.do(ctx, http.MethodGet, "/session", "", nil, nil); err != nil {
+		return fmt.Errorf("connecting to opencode server at %s: %w", c.baseURL, err)
+	}
+	slog.Info("opencode LLM client started", "server", c.baseURL, "model", c.model)
+	return nil
+}
+
+// Stop forgets local session state. Sessions live on the OpenCode server
+// and are removed by DeleteSession.
+func (c *OpenCodeClient) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dirs = make(map[string]string)
+	return nil
+}
+
+func (c *OpenCodeClient) CreateSession(ctx context.Context, title string, workDir string) (*SessionInfo, error) {
+	var session struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/session", workDir, map[string]string{"title": title}, &session); err != nil {
+		return nil, fmt.Errorf("creating session: %w", err)
+	}
+	c.mu.Lock()
+	c.dirs[session.ID] = workDir
+	c.mu.Unlock()
+	slog.Debug("creating opencode session", "title", title, "model", c.model, "workDir", workDir)
+	return &SessionInfo{ID: session.ID, Title: title}, nil
+}
+
+type openCodeMessage struct {
+	Info struct {
+		Role string `json:"role"`
+	} `json:"info"`
+	Parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"parts"`
+}
+
+func (m openCodeMessage) text() string {
+	var b strings.Builder
+	for _, p := range m.Parts {
+		if p.Type == "text" {
+			b.WriteString(p.Text)
+		}
+	}
+	return b.String()
+}
+
+func (c *OpenCodeClient) SendPrompt(ctx context.Context, sessionID string, prompt string) (*PromptResponse, error) {
+	body := map[string]any{
+		"parts": []map[string]string{{"type": "text", "text": prompt}},
+	}
+	if providerID, modelID, ok := strings.Cut(c.model, "/"); ok {
+		body["model"] = map[string]string{"providerID": providerID, "modelID": modelID}
+	}
+
+	// Same ceiling as the Copilot client: tool-heavy fixes can take minutes.
+	promptCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	var resp openCodeMessage
+	if err := c.do(promptCtx, http.MethodPost, "/session/"+url.PathEscape(sessionID)+"/message", c.dir(sessionID), body, &resp); err != nil {
+		return nil, fmt.Errorf("sending prompt: %w", err)
+	}
+	content := resp.text()
+	slog.Info("LLM prompt completed", "backend", "opencode", "session", sessionID, "response_length", len(content))
+	return &PromptResponse{Content: content, Model: c.model}, nil
+}
+
+func (c *OpenCodeClient) GetMessages(ctx context.Context, sessionID string) ([]Message, error) {
+	var resp []openCodeMessage
+	if err := c.do(ctx, http.MethodGet, "/session/"+url.PathEscape(sessionID)+"/message", c.dir(sessionID), nil, &resp); err != nil {
+		return nil, fmt.Errorf("getting messages: %w", err)
+	}
+	msgs := make([]Message, 0, len(resp))
+	for _, m := range resp {
+		msgs = append(msgs, Message{Role: m.Info.Role, Content: m.text()})
+	}
+	return msgs, nil
+}
+
+func (c *OpenCodeClient) DeleteSession(ctx context.Context, sessionID string) error {
+	dir := c.dir(sessionID)
+	c.mu.Lock()
+	delete(c.dirs, sessionID)
+	c.mu.Unlock()
+	return c.do(ctx, http.MethodDelete, "/session/"+url.PathEscape(sessionID), dir, nil, nil)
+}
+
+// PruneSessions deletes the sessions in dir's OpenCode project whose titles
+// match. OpenCode groups every worktree of a repository into one project,
+// so dir can be any checkout of it.
+func (c *OpenCodeClient) PruneSessions(ctx context.Context, dir string, match func(title string) bool) (int, error) {
+	var sessions []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/session", dir, nil, &sessions); err != nil {
+		return 0, fmt.Errorf("listing sessions: %w", err)
+	}
+	deleted := 0
+	for _, s := range sessions {
+		if !match(s.Title) {
+			continue
+		}
+		if err := c.do(ctx, http.MethodDelete, "/session/"+url.PathEscape(s.ID), dir, nil, nil); err != nil {
+			return deleted, fmt.Errorf("deleting session %s: %w", s.ID, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (c *OpenCodeClient) AbortSession(ctx context.Context, sessionID string) error {
+	return c.do(ctx, http.MethodPost, "/session/"+url.PathEscape(sessionID)+"/abort", c.dir(sessionID), nil, nil)
+}
+
+func (c *OpenCodeClient) dir(sessionID string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dirs[sessionID]
+}
+
+// do sends a request to the OpenCode server. OpenCode scopes sessions to a
+// project directory, passed as the directory query parameter.
+func (c *OpenCodeClient) do(ctx context.Context, method, path, dir string, body, out any) error {
+	u := c.baseURL + path
+	if dir != "" {
+		u += "?directory=" + url.QueryEscape(dir)
+	}
+	if method == http.MethodPost {
+		if body == nil {
+			body = struct{}{}
+		}
+		return postJSON(ctx, c.http, u, nil, body, out)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// Progress kinds.
+const (
+	ProgressTool   = "tool"   // the model invoked a tool
+	ProgressIntent = "intent" // the model reported what it is working on
+)
+
+// Progress is one step of an in-flight prompt.
+type Progress struct {
+	Kind string
+	Text string
+}
+
+// ProgressFunc receives a prompt's progress while SendPrompt runs. It is
+// called from the goroutine driving the prompt and must not block.
+type ProgressFunc func(Progress)
+
+type progressKey struct{}
+
+// WithProgress returns a context whose prompts stream their progress to fn.
+// Clients that can observe a prompt's steps (tool calls, intents) report
+// them to the function carried by the context passed to SendPrompt; others
+// report nothing. Callers deep in a pipeline get progress without every
+// layer threading a callback.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// reportProgress sends p to ctx's ProgressFunc, if any.
+func reportProgress(ctx context.Context, p Progress) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && fn != nil {
+		fn(p)
+	}
+}
+
+// hasProgress reports whether ctx carries a ProgressFunc.
+func hasProgress(ctx context.Context) bool {
+	fn, ok := ctx.Value(progressKey{}).(ProgressFunc)
+	return ok && fn != nil
+}
+
+// describeToolCall summarizes a tool invocation for progress output: the
+// tool name and its most telling argument, e.g. "read_file main.go".
+func describeToolCall(name string, args any) string {
+	var m map[string]any
+	switch a := args.(type) {
+	case map[string]any:
+		m = a
+	case json.RawMessage:
+		_ = json.Unmarshal(a, &m)
+	}
+	for _, key := range []string{"command", "path", "pattern", "query", "url", "description"} {
+		if v, ok := m[key].(string); ok && v != "" {
+			v = strings.Join(strings.Fields(v), " ")
+			if len(v) > 80 {
+				v = v[:77] + "..."
+			}
+			return name + " " + v
+		}
+	}
+	return name
+}
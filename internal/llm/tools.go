@@ -0,0 +1,175 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Direct API backends (Anthropic, OpenAI, Ollama) have no agent runtime of
+// their own, so sessions with a working directory get this small tool set
+// to read, edit, and build code the way Copilot and OpenCode sessions can.
+
+// toolSpec describes a tool offered to the model. Parameters is a JSON schema.
+type toolSpec struct {
+	Name        string
+	Description string
+	Parameters  map[string]any
+}
+
+const (
+	// maxToolOutput bounds what a single tool result feeds back into the
+	// conversation so one noisy build doesn't exhaust the context window.
+	maxToolOutput = 32 * 1024
+
+	// commandTimeout bounds a single run_command invocation.
+	commandTimeout = 5 * time.Minute
+)
+
+func pathParam(desc string) map[string]any {
+	return map[string]any{"type": "string", "description": desc}
+}
+
+var workspaceTools = []toolSpec{
+	{
+		Name:        "read_file",
+		Description: "Read a file from the working directory.",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"path": pathParam("File path relative to the working directory.")},
+			"required":   []string{"path"},
+		},
+	},
+	{
+		Name:        "write_file",
+		Description: "Create or overwrite a file in the working directory with the given content.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"path":    pathParam("File path relative to the working directory."),
+				"content": map[string]any{"type": "string", "description": "Full new file content."},
+			},
+			"required": []string{"path", "content"},
+		},
+	},
+	{
+		Name:        "list_files",
+		Description: "List the entries of a directory in the working directory.",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"path": pathParam("Directory path relative to the working directory; empty for the root.")},
+		},
+	},
+	{
+		Name:        "run_command",
+		Description: "Run a shell command in the working directory and return its combined output and exit status.",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"command": map[string]any{"type": "string", "description": "Command line passed to sh -c."}},
+			"required":   []string{"command"},
+		},
+	},
+}
+
+// runTool executes a tool call inside workDir. Failures are returned as
+// text so the model can see and react to them.
+func runTool(ctx context.Context, workDir string, call toolCall) string {
+	var args struct {
+		Path    string `json:"path"`
+		Content string `json:"content"`
+		Command string `json:"command"`
+	}
+	if len(call.Args) > 0 {
+		if err := json.Unmarshal(call.Args, &args); err != nil {
+			return fmt.Sprintf("error: invalid arguments: %v", err)
+		}
+	}
+
+	switch call.Name {
+	case "read_file":
+		path, err := workspacePath(workDir, args.Path)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		if len(data) > maxToolOutput {
+			return string(data[:maxToolOutput]) + "\n... [truncated]"
+		}
+		return string(data)
+
+	case "write_file":
+		path, err := workspacePath(workDir, args.Path)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "error: " + err.Error()
+		}
+		if err := os.WriteFile(path, []byte(args.Content), 0644); err != nil {
+			return "error: " + err.Error()
+		}
+		return fmt.Sprintf("wrote %d bytes to %s", len(args.Content), args.Path)
+
+	case "list_files":
+		path, err := workspacePath(workDir, args.Path)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() {
+				names = append(names, e.Name()+"/")
+			} else {
+				names = append(names, e.Name())
+			}
+		}
+		sort.Strings(names)
+		return strings.Join(names, "\n")
+
+	case "run_command":
+		if strings.TrimSpace(args.Command) == "" {
+			return "error: command is required"
+		}
+		cmdCtx, cancel := context.WithTimeout(ctx, commandTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(cmdCtx, "sh", "-c", args.Command)
+		cmd.Dir = workDir
+		out, err := cmd.CombinedOutput()
+		if len(out) > maxToolOutput {
+			// Keep the tail: build and test failures are summarized at the end.
+			out = append([]byte("[truncated] ...\n"), out[len(out)-maxToolOutput:]...)
+		}
+		status := "exit status 0"
+		if err != nil {
+			status = err.Error()
+		}
+		return fmt.Sprintf("%s\n(%s)", out, status)
+	}
+	return fmt.Sprintf("error: unknown tool %q", call.Name)
+}
+
+// workspacePath resolves p against workDir, rejecting paths that escape it.
+func workspacePath(workDir, p string) (string, error) {
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(workDir, p)
+	}
+	p = filepath.Clean(p)
+	rel, err := filepath.Rel(workDir, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q is outside the working directory", p)
+	}
+	return p, nil
+}
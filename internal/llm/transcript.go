@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TranscriptClient wraps a Client and records every session it creates —
+// prompts, responses, and tool calls — as a markdown file in dir, so what
+// the model saw and did can be audited afterwards. Recording is best
+// effort: a transcript that cannot be written never fails the prompt.
+type TranscriptClient struct {
+	Client
+	dir   string
+	mu    sync.Mutex
+	paths map[string]string // session ID → transcript file
+}
+
+// NewTranscriptClient returns inner with its sessions recorded in dir.
+func NewTranscriptClient(inner Client, dir string) *TranscriptClient {
+	return &TranscriptClient{Client: inner, dir: dir, paths: make(map[string]string)}
+}
+
+var transcriptSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+func (c *TranscriptClient) CreateSession(ctx context.Context, title string, workDir string) (*SessionInfo, error) {
+	info, err := c.Client.CreateSession(ctx, title, workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	slug := strings.Trim(transcriptSlugChars.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	path := filepath.Join(c.dir, fmt.Sprintf("%s-%s.md", now.Format("20060102T150405.000000000"), slug))
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		slog.Warn("failed to create transcript directory", "dir", c.dir, "error", err)
+		return info, nil
+	}
+	c.mu.Lock()
+	c.paths[info.ID] = path
+	c.mu.Unlock()
+	c.append(info.ID, fmt.Sprintf("# %s\n\n- Started: %s\n- Work dir: %s\n", title, now.Format(time.RFC3339), workDir))
+	return info, nil
+}
+
+func (c *TranscriptClient) SendPrompt(ctx context.Context, sessionID string, prompt string) (*PromptResponse, error) {
+	c.append(sessionID, fmt.Sprintf("\n## Prompt (%s)\n\n%s\n", time.Now().UTC().Format(time.TimeOnly), prompt))
+
+	// Record tool calls as they happen, still forwarding them to any
+	// progress callback the caller installed.
+	prev, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	started := false
+	ctx = WithProgress(ctx, func(p Progress) {
+		if !started {
+			c.append(sessionID, "\n## Steps\n\n")
+			started = true
+		}
+		c.append(sessionID, fmt.Sprintf("- %s %s: %s\n", time.Now().UTC().Format(time.TimeOnly), p.Kind, p.Text))
+		if prev != nil {
+			prev(p)
+		}
+	})
+
+	resp, err := c.Client.SendPrompt(ctx, sessionID, prompt)
+	stamp := time.Now().UTC().Format(time.TimeOnly)
+	switch {
+	case err != nil:
+		c.append(sessionID, fmt.Sprintf("\n## Error (%s)\n\n%v\n", stamp, err))
+	case resp.Model != "":
+		c.append(sessionID, fmt.Sprintf("\n## Response (%s, %s)\n\n%s\n", stamp, resp.Model, resp.Content))
+	default:
+		c.append(sessionID, fmt.Sprintf("\n## Response (%s)\n\n%s\n", stamp, resp.Content))
+	}
+	return resp, err
+}
+
+func (c *TranscriptClient) DeleteSession(ctx context.Context, sessionID string) error {
+	c.mu.Lock()
+	delete(c.paths, sessionID)
+	c.mu.Unlock()
+	return c.Client.DeleteSession(ctx, sessionID)
+}
+
+// append adds text to the session's transcript, if it has one.
+func (c *TranscriptClient) append(sessionID, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	path, ok := c.paths[sessionID]
+	if !ok {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		slog.Warn("failed to open transcript", "path", path, "error", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		slog.Warn("failed to write transcript", "path", path, "error", err)
+	}
+}
@@ -0,0 +1,43 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriptClient(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "transcripts")
+	mock := NewMockClient()
+	mock.DefaultResult = "CLASSIFICATION: COMPILE"
+	c := NewTranscriptClient(mock, dir)
+	ctx := context.Background()
+
+	s, err := c.CreateSession(ctx, "PR Fix Analysis #42", "/tmp/wt")
+	require.NoError(t, err)
+	resp, err := c.SendPrompt(ctx, s.ID, "Analyze these logs")
+	require.NoError(t, err)
+	assert.Equal(t, "CLASSIFICATION: COMPILE", resp.Content)
+
+	mock.PromptErr = errors.New("rate limited")
+	_, err = c.SendPrompt(ctx, s.ID, "Try again")
+	require.Error(t, err)
+	require.NoError(t, c.DeleteSession(ctx, s.ID))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*-pr-fix-analysis-42.md"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	transcript := string(data)
+	assert.Contains(t, transcript, "# PR Fix Analysis #42")
+	assert.Contains(t, transcript, "- Work dir: /tmp/wt")
+	assert.Contains(t, transcript, "Analyze these logs")
+	assert.Contains(t, transcript, "CLASSIFICATION: COMPILE")
+	assert.Contains(t, transcript, "rate limited")
+}
@@ -11,6 +11,7 @@ type SessionInfo struct {
 // PromptResponse represents the result of a prompt.
 type PromptResponse struct {
 	Content string
+	Model   string // model that produced the response, when the backend reports it
 }
 
 // Message represents a message from a session.
@@ -36,3 +37,12 @@ type Client interface {
 	// AbortSession aborts a running prompt in a session.
 	AbortSession(ctx context.Context, sessionID string) error
 }
+
+// SessionPruner is implemented by clients whose sessions live on a server
+// and can outlive an interrupted operation (OpenCode), so sessions left
+// behind can be found and deleted later.
+type SessionPruner interface {
+	// PruneSessions deletes the sessions in the project at dir whose titles
+	// match, and returns how many were deleted.
+	PruneSessions(ctx context.Context, dir string, match func(title string) bool) (int, error)
+}
@@ -0,0 +1,113 @@
+package logcache
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// Backend wraps a provider.PRBackend and serves GetBuildLogs from a Cache.
+// Cached logs are dropped when the build is retried or is seen running
+// again, since a rerun keeps its build ID on some providers (ADO). Logs of
+// a build last seen running are partial, so they are never cached.
+type Backend struct {
+	provider.PRBackend
+	cache *Cache
+
+	mu      sync.Mutex
+	running map[string]bool // keys of builds last seen not completed
+}
+
+// WrapBackend returns b wrapped with cache. Backends that already have a
+// cache layer anywhere in their wrapper chain are returned unchanged.
+func WrapBackend(b provider.PRBackend, cache *Cache) provider.PRBackend {
+	for inner := b; inner != nil; {
+		if _, ok := inner.(*Backend); ok {
+			return b
+		}
+		u, ok := inner.(interface{ Unwrap() provider.PRBackend })
+		if !ok {
+			break
+		}
+		inner = u.Unwrap()
+	}
+	return &Backend{PRBackend: b, cache: cache, running: make(map[string]bool)}
+}
+
+// Unwrap returns the underlying backend, e.g. for provider-specific type assertions.
+func (b *Backend) Unwrap() provider.PRBackend {
+	return b.PRBackend
+}
+
+// key identifies a build's logs across providers and repositories.
+func (b *Backend) key(pr *provider.PRInfo, buildID string) string {
+	return strings.Join([]string{b.Name(), pr.Organization, pr.Project, pr.RepoID, buildID}, "/")
+}
+
+// GetBuildLogs returns cached logs for the build, fetching and caching them
+// on a miss. Cache errors fall through to the provider. Builds still
+// running are always fetched.
+func (b *Backend) GetBuildLogs(ctx context.Context, pr *provider.PRInfo, buildID string) (string, error) {
+	key := b.key(pr, buildID)
+	b.mu.Lock()
+	running := b.running[key]
+	b.mu.Unlock()
+	if running {
+		return b.PRBackend.GetBuildLogs(ctx, pr, buildID)
+	}
+
+	if logs, ok, err := b.cache.Get(key); err != nil {
+		slog.Warn("build log cache read failed", "buildID", buildID, "error", err)
+	} else if ok {
+		slog.Debug("build log cache hit", "buildID", buildID)
+		return logs, nil
+	}
+
+	logs, err := b.PRBackend.GetBuildLogs(ctx, pr, buildID)
+	if err != nil {
+		return "", err
+	}
+	if err := b.cache.Put(key, logs); err != nil {
+		slog.Warn("build log cache write failed", "buildID", buildID, "error", err)
+	}
+	return logs, nil
+}
+
+// GetPipelineStatus passes through, invalidating cached logs for any build
+// that is no longer completed (requeued from the provider UI, for example).
+func (b *Backend) GetPipelineStatus(ctx context.Context, pr *provider.PRInfo) (*provider.PipelineStatus, error) {
+	status, err := b.PRBackend.GetPipelineStatus(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	for _, build := range status.Builds {
+		running := build.Status != "" && build.Status != "completed"
+		if running {
+			b.invalidate(pr, build.ID)
+		}
+		b.mu.Lock()
+		if running {
+			b.running[b.key(pr, build.ID)] = true
+		} else {
+			delete(b.running, b.key(pr, build.ID))
+		}
+		b.mu.Unlock()
+	}
+	return status, nil
+}
+
+// RetryBuild retries the build and drops its cached logs.
+func (b *Backend) RetryBuild(ctx context.Context, pr *provider.PRInfo, buildID string) error {
+	err := b.PRBackend.RetryBuild(ctx, pr, buildID)
+	b.invalidate(pr, buildID)
+	return err
+}
+
+func (b *Backend) invalidate(pr *provider.PRInfo, buildID string) {
+	if err := b.cache.Invalidate(b.key(pr, buildID)); err != nil {
+		slog.Warn("build log cache invalidation failed", "buildID", buildID, "error", err)
+	}
+}
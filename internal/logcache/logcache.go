@@ -0,0 +1,219 @@
+// Package logcache keeps downloaded build logs on disk so repeated fix
+// attempts and analyses of the same failed build don't refetch timelines
+// and logs from the provider. Log bodies are stored content-addressed
+// (by SHA-256), so identical logs from different builds share one blob;
+// an index maps each build to its blob. Total blob size is bounded, with
+// least-recently-used builds evicted first.
+package logcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/store"
+)
+
+// DefaultMaxBytes bounds the cache when no size is configured.
+const DefaultMaxBytes = 64 << 20
+
+// entry maps one build to its cached log blob.
+type entry struct {
+	Hash     string    `json:"hash"`
+	Size     int64     `json:"size"`
+	Stored   time.Time `json:"stored"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// index is the on-disk map of build keys to entries.
+type index struct {
+	Entries map[string]*entry `json:"entries"`
+}
+
+// Cache is a size-bounded, content-addressed build log store rooted at Dir.
+type Cache struct {
+	Dir      string
+	MaxBytes int64
+}
+
+// Dir returns the default cache location under the otto data directory.
+func Dir() string {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		dataDir = filepath.Join(os.TempDir(), "otto")
+	}
+	return filepath.Join(dataDir, "logcache")
+}
+
+// New returns a cache in the default location. maxBytes <= 0 uses
+// DefaultMaxBytes.
+func New(maxBytes int64) *Cache {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	return &Cache{Dir: Dir(), MaxBytes: maxBytes}
+}
+
+func (c *Cache) indexPath() string { return filepath.Join(c.Dir, "index.json") }
+
+func (c *Cache) blobPath(hash string) string { return filepath.Join(c.Dir, hash+".log") }
+
+// Get returns the cached log for key. A miss returns ok=false and no error.
+func (c *Cache) Get(key string) (logs string, ok bool, err error) {
+	err = store.WithLock(c.indexPath(), store.DefaultLockTimeout, func() error {
+		idx, err := c.readIndex()
+		if err != nil {
+			return err
+		}
+		e := idx.Entries[key]
+		if e == nil {
+			return nil
+		}
+		data, err := os.ReadFile(c.blobPath(e.Hash))
+		if err != nil {
+			// Blob lost (manual cleanup, crash mid-write): treat as a miss.
+			delete(idx.Entries, key)
+			return c.writeIndex(idx)
+		}
+		logs, ok = string(data), true
+		e.LastUsed = time.Now().UTC()
+		return c.writeIndex(idx)
+	})
+	return logs, ok, err
+}
+
+// Put stores logs under key, then evicts least-recently-used entries until
+// the cache fits in MaxBytes. A single log larger than MaxBytes is not cached.
+func (c *Cache) Put(key, logs string) error {
+	size := int64(len(logs))
+	if size > c.MaxBytes {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(logs))
+	hash := hex.EncodeToString(sum[:])
+
+	return store.WithLock(c.indexPath(), store.DefaultLockTimeout, func() error {
+		idx, err := c.readIndex()
+		if err != nil {
+			return err
+		}
+		if !store.Exists(c.blobPath(hash)) {
+			tmp := c.blobPath(hash) + ".tmp"
+			if err := os.WriteFile(tmp, []byte(logs), 0600); err != nil {
+				return fmt.Errorf("writing log blob: %w", err)
+			}
+			if err := os.Rename(tmp, c.blobPath(hash)); err != nil {
+				return fmt.Errorf("writing log blob: %w", err)
+			}
+		}
+		now := time.Now().UTC()
+		old := idx.Entries[key]
+		idx.Entries[key] = &entry{Hash: hash, Size: size, Stored: now, LastUsed: now}
+		if old != nil && old.Hash != hash {
+			c.removeIfUnreferenced(idx, old.Hash)
+		}
+		c.evict(idx)
+		return c.writeIndex(idx)
+	})
+}
+
+// Invalidate drops the entry for key, e.g. after the build is retried and
+// its logs will change.
+func (c *Cache) Invalidate(key string) error {
+	return store.WithLock(c.indexPath(), store.DefaultLockTimeout, func() error {
+		idx, err := c.readIndex()
+		if err != nil {
+			return err
+		}
+		e := idx.Entries[key]
+		if e == nil {
+			return nil
+		}
+		delete(idx.Entries, key)
+		c.removeIfUnreferenced(idx, e.Hash)
+		return c.writeIndex(idx)
+	})
+}
+
+// evict removes least-recently-used entries until the unique blobs fit in
+// MaxBytes. Callers hold the index lock.
+func (c *Cache) evict(idx *index) {
+	blobSizes := make(map[string]int64)
+	for _, e := range idx.Entries {
+		blobSizes[e.Hash] = e.Size
+	}
+	var total int64
+	for _, s := range blobSizes {
+		total += s
+	}
+	if total <= c.MaxBytes {
+		return
+	}
+
+	keys := make([]string, 0, len(idx.Entries))
+	for k := range idx.Entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return idx.Entries[keys[i]].LastUsed.Before(idx.Entries[keys[j]].LastUsed)
+	})
+	for _, k := range keys {
+		if total <= c.MaxBytes {
+			break
+		}
+		e := idx.Entries[k]
+		delete(idx.Entries, k)
+		if c.removeIfUnreferenced(idx, e.Hash) {
+			total -= e.Size
+		}
+	}
+}
+
+// removeIfUnreferenced deletes the blob for hash when no entry points at it
+// and reports whether it did.
+func (c *Cache) removeIfUnreferenced(idx *index, hash string) bool {
+	for _, e := range idx.Entries {
+		if e.Hash == hash {
+			return false
+		}
+	}
+	_ = os.Remove(c.blobPath(hash))
+	return true
+}
+
+func (c *Cache) readIndex() (*index, error) {
+	idx := &index{Entries: make(map[string]*entry)}
+	data, err := os.ReadFile(c.indexPath())
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading log cache index: %w", err)
+	}
+	if err := json.Unmarshal(data, idx); err != nil {
+		// A corrupt index only costs refetches; start over.
+		return &index{Entries: make(map[string]*entry)}, nil
+	}
+	if idx.Entries == nil {
+		idx.Entries = make(map[string]*entry)
+	}
+	return idx, nil
+}
+
+func (c *Cache) writeIndex(idx *index) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("marshaling log cache index: %w", err)
+	}
+	tmp := c.indexPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("writing log cache index: %w", err)
+	}
+	return os.Rename(tmp, c.indexPath())
+}
@@ -0,0 +1,157 @@
+package logcache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/audit"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCache(t *testing.T, maxBytes int64) *Cache {
+	t.Helper()
+	return &Cache{Dir: t.TempDir(), MaxBytes: maxBytes}
+}
+
+func blobCount(t *testing.T, c *Cache) int {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(c.Dir, "*.log"))
+	require.NoError(t, err)
+	return len(matches)
+}
+
+func TestCache_PutGet(t *testing.T) {
+	c := newTestCache(t, 1<<20)
+
+	_, ok, err := c.Get("ado/org/proj/repo/1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, c.Put("ado/org/proj/repo/1", "error CS1002: ; expected"))
+	logs, ok, err := c.Get("ado/org/proj/repo/1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "error CS1002: ; expected", logs)
+}
+
+func TestCache_ContentAddressed(t *testing.T) {
+	c := newTestCache(t, 1<<20)
+
+	require.NoError(t, c.Put("a", "same failure"))
+	require.NoError(t, c.Put("b", "same failure"))
+	assert.Equal(t, 1, blobCount(t, c), "identical logs share one blob")
+
+	require.NoError(t, c.Invalidate("a"))
+	logs, ok, err := c.Get("b")
+	require.NoError(t, err)
+	assert.True(t, ok, "blob still referenced by b")
+	assert.Equal(t, "same failure", logs)
+
+	require.NoError(t, c.Invalidate("b"))
+	assert.Zero(t, blobCount(t, c))
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newTestCache(t, 25)
+
+	require.NoError(t, c.Put("old", strings.Repeat("a", 10)))
+	require.NoError(t, c.Put("used", strings.Repeat("b", 10)))
+	_, _, err := c.Get("old") // touch old so "used" is now the LRU entry
+	require.NoError(t, err)
+	require.NoError(t, c.Put("new", strings.Repeat("c", 10)))
+
+	_, ok, _ := c.Get("used")
+	assert.False(t, ok, "least recently used entry evicted")
+	_, ok, _ = c.Get("old")
+	assert.True(t, ok)
+	_, ok, _ = c.Get("new")
+	assert.True(t, ok)
+	assert.Equal(t, 2, blobCount(t, c))
+
+	require.NoError(t, c.Put("huge", strings.Repeat("d", 100)))
+	_, ok, _ = c.Get("huge")
+	assert.False(t, ok, "logs larger than the cache are not stored")
+}
+
+func TestCache_MissingBlobIsMiss(t *testing.T) {
+	c := newTestCache(t, 1<<20)
+	require.NoError(t, c.Put("k", "logs"))
+	matches, _ := filepath.Glob(filepath.Join(c.Dir, "*.log"))
+	require.Len(t, matches, 1)
+	require.NoError(t, os.Remove(matches[0]))
+
+	_, ok, err := c.Get("k")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+type stubBackend struct {
+	provider.PRBackend
+	fetches int
+	status  *provider.PipelineStatus
+}
+
+func (s *stubBackend) Name() string { return "stub" }
+func (s *stubBackend) GetBuildLogs(ctx context.Context, pr *provider.PRInfo, buildID string) (string, error) {
+	s.fetches++
+	return "logs for " + buildID, nil
+}
+func (s *stubBackend) GetPipelineStatus(ctx context.Context, pr *provider.PRInfo) (*provider.PipelineStatus, error) {
+	return s.status, nil
+}
+func (s *stubBackend) RetryBuild(ctx context.Context, pr *provider.PRInfo, buildID string) error {
+	return nil
+}
+
+func TestWrapBackend_CachesAndInvalidates(t *testing.T) {
+	inner := &stubBackend{}
+	b := WrapBackend(inner, newTestCache(t, 1<<20))
+	ctx := context.Background()
+	pr := &provider.PRInfo{ID: "7", RepoID: "repo"}
+
+	for i := 0; i < 3; i++ {
+		logs, err := b.GetBuildLogs(ctx, pr, "100")
+		require.NoError(t, err)
+		assert.Equal(t, "logs for 100", logs)
+	}
+	assert.Equal(t, 1, inner.fetches, "repeat fetches served from cache")
+
+	require.NoError(t, b.RetryBuild(ctx, pr, "100"))
+	_, err := b.GetBuildLogs(ctx, pr, "100")
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.fetches, "retry invalidates")
+
+	inner.status = &provider.PipelineStatus{Builds: []provider.BuildInfo{{ID: "100", Status: "inProgress"}}}
+	_, err = b.GetPipelineStatus(ctx, pr)
+	require.NoError(t, err)
+	_, err = b.GetBuildLogs(ctx, pr, "100")
+	require.NoError(t, err)
+	assert.Equal(t, 3, inner.fetches, "running build invalidates")
+	_, err = b.GetBuildLogs(ctx, pr, "100")
+	require.NoError(t, err)
+	assert.Equal(t, 4, inner.fetches, "running build's partial logs aren't cached")
+
+	inner.status = &provider.PipelineStatus{Builds: []provider.BuildInfo{{ID: "100", Status: "completed"}}}
+	_, err = b.GetPipelineStatus(ctx, pr)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = b.GetBuildLogs(ctx, pr, "100")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 5, inner.fetches, "completed build cached again")
+}
+
+func TestWrapBackend_NoDoubleWrap(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	cache := newTestCache(t, 1<<20)
+	b := WrapBackend(&stubBackend{}, cache)
+	assert.Same(t, b, WrapBackend(b, cache))
+
+	audited := audit.WrapBackend(b)
+	assert.Same(t, audited, WrapBackend(audited, cache), "cache layer found beneath audit layer")
+}
@@ -0,0 +1,276 @@
+// Package logdistill condenses CI job logs for LLM analysis. It recognizes
+// common failure signatures (compiler, linker, and test framework output,
+// npm and NuGet errors), folds repeats of the same failure together,
+// extracts file:line locations, and keeps only the log lines around the
+// errors. The result is a short structured summary followed by the
+// surrounding log context, so fix prompts spend their tokens on the
+// failures instead of on build noise.
+package logdistill
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Kind is the broad class of a recognized failure.
+type Kind string
+
+const (
+	KindCompile Kind = "compile"
+	KindLink    Kind = "link"
+	KindTest    Kind = "test"
+	KindPackage Kind = "package" // npm / NuGet restore and install
+	KindError   Kind = "error"   // CI error annotation with no known signature
+)
+
+const (
+	contextWindow   = 5   // lines kept on each side of an error
+	fallbackLines   = 50  // tail kept when nothing marks an error
+	maxFindings     = 30  // distinct findings listed in the summary
+	maxLocations    = 20  // file:line entries listed in the summary
+	maxContextLines = 400 // cap on the error context section
+)
+
+// Finding is one distinct failure recognized in a log. Repeats of the same
+// failure at the same location are folded into a single Finding.
+type Finding struct {
+	Kind    Kind
+	Tool    string // e.g. "go", "csc", "tsc", "go test", "pytest", "npm"
+	File    string
+	Line    int
+	Column  int
+	Code    string // compiler or package manager code, e.g. CS1002, NU1101
+	Test    string // failing test name, for KindTest
+	Message string
+	Raw     string // first log line the finding was recognized on
+	Count   int    // occurrences in the log
+
+	index int // line index of the first occurrence
+}
+
+// Location returns "file:line" (or just the file) for the finding, or "".
+func (f *Finding) Location() string {
+	switch {
+	case f.File == "":
+		return ""
+	case f.Line == 0:
+		return f.File
+	default:
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+}
+
+var (
+	hexPattern    = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+	digitsPattern = regexp.MustCompile(`\d+`)
+	spacePattern  = regexp.MustCompile(`\s+`)
+)
+
+// Fingerprint identifies the failure independently of where in the log it
+// appeared and of volatile details (numbers, addresses, line numbers), so
+// the same failure in different runs gets the same fingerprint. Test
+// failures are identified by the test alone.
+func (f *Finding) Fingerprint() string {
+	parts := []string{string(f.Kind), f.Tool, f.Code, f.File, f.Test}
+	if f.Kind != KindTest {
+		parts = append(parts, normalize(f.Message))
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func normalize(msg string) string {
+	msg = hexPattern.ReplaceAllString(msg, "0x")
+	msg = digitsPattern.ReplaceAllString(msg, "N")
+	return strings.ToLower(spacePattern.ReplaceAllString(strings.TrimSpace(msg), " "))
+}
+
+// Summary is the distilled form of one log.
+type Summary struct {
+	Findings []*Finding // distinct failures, in order of first appearance
+	Context  string     // log lines around the errors
+}
+
+// Distill recognizes failures in log and extracts the context around them.
+// Lines are expected to be free of ANSI escape codes.
+func Distill(log string) *Summary {
+	lines := strings.Split(log, "\n")
+	d := &distiller{lines: lines, byKey: make(map[string]*Finding)}
+
+	var anchors []int
+	seenMarkers := make(map[string]bool)
+	for i, line := range lines {
+		text, marker := stripDecorations(line)
+		if marker {
+			// Annotations are often repeated in a job's closing summary;
+			// anchor context on the first of each.
+			if key := normalize(text); !seenMarkers[key] {
+				seenMarkers[key] = true
+				anchors = append(anchors, i)
+			}
+		}
+		if f := d.match(i, text, marker); f != nil {
+			d.add(f)
+		}
+	}
+	for _, f := range d.findings {
+		anchors = append(anchors, f.index)
+	}
+
+	return &Summary{Findings: d.findings, Context: extractContext(lines, anchors)}
+}
+
+// ErrorContext returns only the log lines around errors, without the
+// failure summary.
+func ErrorContext(log string) string {
+	return Distill(log).Context
+}
+
+// String renders the summary followed by the error context. A log with no
+// recognized failures renders as its context alone.
+func (s *Summary) String() string {
+	if len(s.Findings) == 0 {
+		return s.Context
+	}
+
+	total := 0
+	for _, f := range s.Findings {
+		total += f.Count
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Failure summary: %d distinct failure(s), %d occurrence(s)\n", len(s.Findings), total)
+	var locations []string
+	seenLocations := make(map[string]bool)
+	for i, f := range s.Findings {
+		if loc := f.Location(); loc != "" && !seenLocations[loc] {
+			seenLocations[loc] = true
+			locations = append(locations, loc)
+		}
+		if i >= maxFindings {
+			continue
+		}
+		b.WriteString("- ")
+		b.WriteString(f.summaryLine())
+		b.WriteString("\n")
+	}
+	if len(s.Findings) > maxFindings {
+		fmt.Fprintf(&b, "- ... and %d more\n", len(s.Findings)-maxFindings)
+	}
+	if len(locations) > 0 {
+		if len(locations) > maxLocations {
+			locations = append(locations[:maxLocations], "...")
+		}
+		fmt.Fprintf(&b, "Locations: %s\n", strings.Join(locations, ", "))
+	}
+	b.WriteString("\nError context:\n")
+	b.WriteString(s.Context)
+	return b.String()
+}
+
+// summaryLine renders a finding on one line. The raw log text is kept so
+// the line still reads as the tool printed it.
+func (f *Finding) summaryLine() string {
+	label := string(f.Kind)
+	if f.Tool != "" {
+		label += "/" + f.Tool
+	}
+	line := fmt.Sprintf("[%s] %s", label, f.Raw)
+	if loc := f.Location(); loc != "" && !strings.Contains(f.Raw, f.File) {
+		line += " at " + loc
+	}
+	if f.Message != "" && !strings.Contains(f.Raw, f.Message) {
+		line += ": " + f.Message
+	}
+	if f.Count > 1 {
+		line += fmt.Sprintf(" (x%d)", f.Count)
+	}
+	return line
+}
+
+// extractContext keeps contextWindow lines around each anchor, separating
+// non-adjacent windows with "...". With no anchors it keeps the log's tail.
+func extractContext(lines []string, anchors []int) string {
+	if len(anchors) == 0 {
+		start := len(lines) - fallbackLines
+		if start < 0 {
+			start = 0
+		}
+		return strings.Join(lines[start:], "\n")
+	}
+
+	included := make(map[int]bool)
+	for _, idx := range anchors {
+		start := max(idx-contextWindow, 0)
+		end := min(idx+contextWindow+1, len(lines))
+		for i := start; i < end; i++ {
+			included[i] = true
+		}
+	}
+
+	var result strings.Builder
+	written := 0
+	prevIncluded := false
+	for i, line := range lines {
+		if !included[i] {
+			prevIncluded = false
+			continue
+		}
+		if written == maxContextLines {
+			result.WriteString("... (context truncated)\n")
+			break
+		}
+		if !prevIncluded && i > 0 {
+			result.WriteString("...\n")
+		}
+		result.WriteString(line)
+		result.WriteString("\n")
+		written++
+		prevIncluded = true
+	}
+	return result.String()
+}
+
+// distiller holds the state of one Distill pass.
+type distiller struct {
+	lines    []string
+	findings []*Finding
+	byKey    map[string]*Finding
+}
+
+// add records f, folding it into an earlier finding with the same
+// fingerprint and location.
+func (d *distiller) add(f *Finding) {
+	key := f.Fingerprint() + "@" + f.Location()
+	if prev, ok := d.byKey[key]; ok {
+		prev.Count++
+		return
+	}
+	f.Count = 1
+	d.byKey[key] = f
+	d.findings = append(d.findings, f)
+}
+
+// text returns line i with decorations stripped, or "" past the end.
+func (d *distiller) text(i int) string {
+	if i < 0 || i >= len(d.lines) {
+		return ""
+	}
+	text, _ := stripDecorations(d.lines[i])
+	return text
+}
+
+var timestampPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?Z\s?`)
+
+// stripDecorations removes the CI timestamp prefix and any ##[error]
+// marker from line, reporting whether the marker was present.
+func stripDecorations(line string) (string, bool) {
+	line = strings.TrimRight(timestampPattern.ReplaceAllString(line, ""), "\r")
+	if i := strings.Index(line, "##[error]"); i >= 0 {
+		return strings.TrimSpace(line[i+len("##[error]"):]), true
+	}
+	return line, false
+}
@@ -0,0 +1,181 @@
+package logdistill
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorContext(t *testing.T) {
+	t.Run("with error markers", func(t *testing.T) {
+		var lines []string
+		for i := 0; i < 20; i++ {
+			lines = append(lines, fmt.Sprintf("line %d: normal output", i))
+		}
+		lines[10] = "line 10: ##[error]Something went wrong"
+
+		result := ErrorContext(strings.Join(lines, "\n"))
+		assert.Contains(t, result, "##[error]Something went wrong")
+		assert.Contains(t, result, "line 5: normal output")
+		assert.Contains(t, result, "line 15: normal output")
+		assert.NotContains(t, result, "line 4: normal output")
+	})
+
+	t.Run("without error markers", func(t *testing.T) {
+		var lines []string
+		for i := 0; i < 100; i++ {
+			lines = append(lines, fmt.Sprintf("line %d", i))
+		}
+
+		result := ErrorContext(strings.Join(lines, "\n"))
+		assert.Contains(t, result, "line 99")
+		assert.Contains(t, result, "line 50")
+		assert.NotContains(t, result, "line 49")
+	})
+
+	t.Run("anchors on recognized failures", func(t *testing.T) {
+		var lines []string
+		for i := 0; i < 40; i++ {
+			lines = append(lines, fmt.Sprintf("line %d", i))
+		}
+		lines[5] = "pkg/foo.go:12:3: undefined: Bar"
+
+		result := ErrorContext(strings.Join(lines, "\n"))
+		assert.Contains(t, result, "undefined: Bar")
+		assert.NotContains(t, result, "line 39", "tail fallback not used")
+	})
+}
+
+func TestDistill_Signatures(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+		want Finding
+	}{
+		{
+			name: "csc via msbuild",
+			log:  `src/Widget.cs(42,17): error CS0103: The name 'foo' does not exist in the current context [/src/Widget.csproj]`,
+			want: Finding{Kind: KindCompile, Tool: "csc", File: "src/Widget.cs", Line: 42, Column: 17, Code: "CS0103", Message: "The name 'foo' does not exist in the current context"},
+		},
+		{
+			name: "tsc",
+			log:  `src/app.ts(3,10): error TS2304: Cannot find name 'x'.`,
+			want: Finding{Kind: KindCompile, Tool: "tsc", File: "src/app.ts", Line: 3, Column: 10, Code: "TS2304", Message: "Cannot find name 'x'."},
+		},
+		{
+			name: "go compiler",
+			log:  `2024-05-01T10:00:00.1234567Z internal/x/y.go:7:2: undefined: zap`,
+			want: Finding{Kind: KindCompile, Tool: "go", File: "internal/x/y.go", Line: 7, Column: 2, Message: "undefined: zap"},
+		},
+		{
+			name: "clang",
+			log:  `lib/parse.cpp:88:5: error: use of undeclared identifier 'tok'`,
+			want: Finding{Kind: KindCompile, Tool: "cc", File: "lib/parse.cpp", Line: 88, Column: 5, Message: "use of undeclared identifier 'tok'"},
+		},
+		{
+			name: "rustc",
+			log:  "error[E0425]: cannot find value `y` in this scope\n --> src/main.rs:4:13\n  |",
+			want: Finding{Kind: KindCompile, Tool: "rustc", File: "src/main.rs", Line: 4, Column: 13, Code: "E0425", Message: "cannot find value `y` in this scope"},
+		},
+		{
+			name: "ld undefined reference",
+			log:  "/usr/bin/ld: main.o: in function `main': main.c:(.text+0x1a): undefined reference to `compute'",
+			want: Finding{Kind: KindLink, Tool: "ld", Message: "undefined reference to `compute'"},
+		},
+		{
+			name: "msvc linker",
+			log:  `LINK : fatal error LNK1104: cannot open file 'foo.lib'`,
+			want: Finding{Kind: KindLink, Tool: "link", Code: "LNK1104", Message: "cannot open file 'foo.lib'"},
+		},
+		{
+			name: "nuget",
+			log:  `/src/App/App.csproj : error NU1101: Unable to find package Foo.Bar. No packages exist with this id in source(s): nuget.org`,
+			want: Finding{Kind: KindPackage, Tool: "nuget", File: "/src/App/App.csproj", Code: "NU1101", Message: "Unable to find package Foo.Bar. No packages exist with this id in source(s): nuget.org"},
+		},
+		{
+			name: "npm",
+			log:  "npm ERR! code ERESOLVE\nnpm ERR! ERESOLVE unable to resolve dependency tree\nnpm ERR! \n",
+			want: Finding{Kind: KindPackage, Tool: "npm", Code: "ERESOLVE", Message: "ERESOLVE unable to resolve dependency tree"},
+		},
+		{
+			name: "go test",
+			log:  "--- FAIL: TestParse (0.00s)\n    parse_test.go:31: expected 2, got 3\nFAIL",
+			want: Finding{Kind: KindTest, Tool: "go test", Test: "TestParse", File: "parse_test.go", Line: 31, Message: "expected 2, got 3"},
+		},
+		{
+			name: "pytest",
+			log:  "FAILED tests/test_api.py::TestClient::test_get - AssertionError: 404 != 200",
+			want: Finding{Kind: KindTest, Tool: "pytest", File: "tests/test_api.py", Test: "TestClient::test_get", Message: "AssertionError: 404 != 200"},
+		},
+		{
+			name: "jest",
+			log:  "  ● Cart › adds items\n\n    expect(received).toBe(expected)\n\n      at Object.<anonymous> (src/cart.test.ts:14:23)",
+			want: Finding{Kind: KindTest, Tool: "jest", Test: "Cart › adds items", File: "src/cart.test.ts", Line: 14, Column: 23, Message: "expect(received).toBe(expected)"},
+		},
+		{
+			name: "dotnet test",
+			log:  "  Failed Shop.Tests.CartTests.AddsItem [12 ms]\n  Error Message:\n   Assert.Equal() Failure\n  Stack Trace:\n     at Shop.Tests.CartTests.AddsItem() in /src/Shop.Tests/CartTests.cs:line 27",
+			want: Finding{Kind: KindTest, Tool: "dotnet test", Test: "Shop.Tests.CartTests.AddsItem", File: "/src/Shop.Tests/CartTests.cs", Line: 27, Message: "Assert.Equal() Failure"},
+		},
+		{
+			name: "github annotation",
+			log:  "::error file=app.js,line=10,col=15::Something failed",
+			want: Finding{Kind: KindError, File: "app.js", Line: 10, Column: 15, Message: "Something failed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Distill(tt.log)
+			require.Len(t, s.Findings, 1)
+			got := *s.Findings[0]
+			got.Raw, got.index, got.Count = "", 0, 0
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDistill_GroupsDuplicates(t *testing.T) {
+	log := strings.Join([]string{
+		`src/A.cs(3,1): error CS1002: ; expected [/src/A.csproj]`,
+		`src/A.cs(9,1): error CS1002: ; expected [/src/A.csproj]`,
+		`Build FAILED.`,
+		`src/A.cs(3,1): error CS1002: ; expected [/src/A.csproj]`,
+		`##[error]src/A.cs(3,1): Error CS1002: ; expected`,
+		`##[error]Process completed with exit code 1.`,
+	}, "\n")
+
+	s := Distill(log)
+	require.Len(t, s.Findings, 2, "same error at the same location is folded; exit code line ignored")
+	assert.Equal(t, 3, s.Findings[0].Count)
+	assert.Equal(t, 1, s.Findings[1].Count)
+	assert.Equal(t, s.Findings[0].Fingerprint(), s.Findings[1].Fingerprint(), "fingerprint ignores line numbers")
+
+	out := s.String()
+	assert.Contains(t, out, "Failure summary: 2 distinct failure(s), 4 occurrence(s)")
+	assert.Contains(t, out, "error CS1002: ; expected [/src/A.csproj] (x3)")
+	assert.Contains(t, out, "Locations: src/A.cs:3, src/A.cs:9")
+	assert.Contains(t, out, "Error context:\n")
+}
+
+func TestFinding_FingerprintStableAcrossRuns(t *testing.T) {
+	a := Distill("--- FAIL: TestRetry (1.20s)\n    retry_test.go:40: took 1203ms")
+	b := Distill("--- FAIL: TestRetry (0.90s)\n    retry_test.go:41: took 904ms")
+	require.Len(t, a.Findings, 1)
+	require.Len(t, b.Findings, 1)
+	assert.Equal(t, a.Findings[0].Fingerprint(), b.Findings[0].Fingerprint())
+
+	c := Distill("pkg/a.go:1:1: undefined: X")
+	d := Distill("pkg/a.go:5:1: undefined: Y")
+	assert.NotEqual(t, c.Findings[0].Fingerprint(), d.Findings[0].Fingerprint())
+}
+
+func TestSummary_StringWithoutFindings(t *testing.T) {
+	log := "step 1\nstep 2\n##[error]Process completed with exit code 2."
+	s := Distill(log)
+	assert.Empty(t, s.Findings)
+	assert.Equal(t, s.Context, s.String())
+	assert.Contains(t, s.String(), "exit code 2")
+}
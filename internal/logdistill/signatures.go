@@ -0,0 +1,369 @@
+package logdistill
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// matcher recognizes a failure signature on line i (already stripped of
+// decorations as text). It may look at neighbouring lines for a location
+// or message and returns nil when the line does not match.
+type matcher func(d *distiller, i int, text string) *Finding
+
+// matchers are tried in order; the first match wins. Test runners come
+// first since their failure lines can embed compiler-like locations.
+var matchers = []matcher{
+	matchGoTest,
+	matchPytest,
+	matchJest,
+	matchDotnetTest,
+	matchJUnit,
+	matchMSBuild,
+	matchCoded,
+	matchGo,
+	matchCFamily,
+	matchJava,
+	matchRust,
+	matchLinker,
+	matchNpm,
+}
+
+// match runs the matchers on line i. Error annotations no matcher
+// recognizes become generic findings, except for the runner's closing
+// "exited with code N" lines, which carry no information.
+func (d *distiller) match(i int, text string, marker bool) *Finding {
+	raw := strings.TrimSpace(text)
+	if raw == "" {
+		return nil
+	}
+	for _, m := range matchers {
+		if f := m(d, i, text); f != nil {
+			f.Raw = raw
+			f.index = i
+			return f
+		}
+	}
+	if m := workflowCommandPattern.FindStringSubmatch(raw); m != nil {
+		f := &Finding{Kind: KindError, Message: strings.TrimSpace(m[2]), Raw: raw, index: i}
+		for _, prop := range strings.Split(m[1], ",") {
+			k, v, _ := strings.Cut(strings.TrimSpace(prop), "=")
+			switch k {
+			case "file":
+				f.File = v
+			case "line":
+				f.Line = atoi(v)
+			case "col":
+				f.Column = atoi(v)
+			}
+		}
+		return f
+	}
+	if marker && !exitCodePattern.MatchString(raw) {
+		return &Finding{Kind: KindError, Message: raw, Raw: raw, index: i}
+	}
+	return nil
+}
+
+var (
+	// GitHub Actions workflow command: ::error file=a.go,line=3::message
+	workflowCommandPattern = regexp.MustCompile(`^::error(?:\s+([^:]*))?::(.*)$`)
+	exitCodePattern        = regexp.MustCompile(`(?i)(exit(ed)? (with )?code|return code|exit status) -?\d+`)
+)
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(s))
+	return n
+}
+
+// lookahead returns the first submatch of re within n lines after i,
+// stopping early at a line that matches stop (if non-nil).
+func (d *distiller) lookahead(i, n int, re, stop *regexp.Regexp) []string {
+	for j := i + 1; j <= i+n && j < len(d.lines); j++ {
+		text := d.text(j)
+		if stop != nil && stop.MatchString(text) {
+			return nil
+		}
+		if m := re.FindStringSubmatch(text); m != nil {
+			return m
+		}
+	}
+	return nil
+}
+
+// lookbehind is lookahead in the other direction.
+func (d *distiller) lookbehind(i, n int, re, stop *regexp.Regexp) []string {
+	for j := i - 1; j >= i-n && j >= 0; j-- {
+		text := d.text(j)
+		if stop != nil && stop.MatchString(text) {
+			return nil
+		}
+		if m := re.FindStringSubmatch(text); m != nil {
+			return m
+		}
+	}
+	return nil
+}
+
+// --- test runners ---
+
+var (
+	goTestFailPattern = regexp.MustCompile(`^\s*--- FAIL: (\S+)`)
+	goTestRunPattern  = regexp.MustCompile(`^\s*=== RUN`)
+	goTestLogPattern  = regexp.MustCompile(`^\s+(\S+_test\.go):(\d+): (.+)$`)
+)
+
+// matchGoTest handles "--- FAIL: TestX". The assertion location is printed
+// after the FAIL line normally and before it under -v.
+func matchGoTest(d *distiller, i int, text string) *Finding {
+	m := goTestFailPattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	f := &Finding{Kind: KindTest, Tool: "go test", Test: m[1]}
+	loc := d.lookahead(i, 5, goTestLogPattern, goTestFailPattern)
+	if loc == nil {
+		loc = d.lookbehind(i, 10, goTestLogPattern, goTestRunPattern)
+	}
+	if loc != nil {
+		f.File, f.Line, f.Message = loc[1], atoi(loc[2]), loc[3]
+	}
+	return f
+}
+
+var pytestPattern = regexp.MustCompile(`^FAILED\s+(\S+?)::(\S+)(?:\s+-\s+(.+))?$`)
+
+func matchPytest(_ *distiller, _ int, text string) *Finding {
+	m := pytestPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if m == nil {
+		return nil
+	}
+	return &Finding{Kind: KindTest, Tool: "pytest", File: m[1], Test: m[2], Message: m[3]}
+}
+
+var (
+	jestPattern      = regexp.MustCompile(`^\s*● (.+?)\s*$`)
+	jestFramePattern = regexp.MustCompile(`at .*\(([^()\s]+\.(?:[cm]?[jt]sx?)):(\d+):(\d+)\)`)
+)
+
+func matchJest(d *distiller, i int, text string) *Finding {
+	m := jestPattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	f := &Finding{Kind: KindTest, Tool: "jest", Test: m[1]}
+	for j := i + 1; j < len(d.lines) && j <= i+5; j++ {
+		if msg := strings.TrimSpace(d.text(j)); msg != "" {
+			f.Message = msg
+			break
+		}
+	}
+	for j := i + 1; j < len(d.lines) && j <= i+30; j++ {
+		text := d.text(j)
+		if jestPattern.MatchString(text) {
+			break
+		}
+		if loc := jestFramePattern.FindStringSubmatch(text); loc != nil && !strings.Contains(loc[1], "node_modules") {
+			f.File, f.Line, f.Column = loc[1], atoi(loc[2]), atoi(loc[3])
+			break
+		}
+	}
+	return f
+}
+
+var (
+	dotnetTestPattern     = regexp.MustCompile(`^\s*Failed (.+?) \[[^\]]*\]\s*$`)
+	dotnetMessagePattern  = regexp.MustCompile(`^\s*Error Message:\s*$`)
+	dotnetLocationPattern = regexp.MustCompile(` in (.+?):line (\d+)`)
+)
+
+func matchDotnetTest(d *distiller, i int, text string) *Finding {
+	m := dotnetTestPattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	f := &Finding{Kind: KindTest, Tool: "dotnet test", Test: m[1]}
+	for j := i + 1; j < len(d.lines) && j <= i+40; j++ {
+		text := d.text(j)
+		if dotnetTestPattern.MatchString(text) {
+			break
+		}
+		if f.Message == "" && dotnetMessagePattern.MatchString(text) {
+			f.Message = strings.TrimSpace(d.text(j + 1))
+			continue
+		}
+		if loc := dotnetLocationPattern.FindStringSubmatch(text); loc != nil {
+			f.File, f.Line = loc[1], atoi(loc[2])
+			break
+		}
+	}
+	return f
+}
+
+var junitPattern = regexp.MustCompile(`^\[ERROR\]\s+(\S+)\s+Time elapsed:.*<<<\s+(?:FAILURE|ERROR)!`)
+
+func matchJUnit(_ *distiller, _ int, text string) *Finding {
+	m := junitPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if m == nil {
+		return nil
+	}
+	return &Finding{Kind: KindTest, Tool: "junit", Test: m[1]}
+}
+
+// --- compilers and package managers ---
+
+// codedTools maps compiler/tool code prefixes to the tool and kind.
+var codedTools = map[string]struct {
+	tool string
+	kind Kind
+}{
+	"CS":  {"csc", KindCompile},
+	"BC":  {"vbc", KindCompile},
+	"FS":  {"fsc", KindCompile},
+	"TS":  {"tsc", KindCompile},
+	"C":   {"cl", KindCompile},
+	"MSB": {"msbuild", KindCompile},
+	"NU":  {"nuget", KindPackage},
+	"LNK": {"link", KindLink},
+}
+
+var codePrefixPattern = regexp.MustCompile(`^[A-Z]+`)
+
+func codedFinding(file, code, msg string) *Finding {
+	t, ok := codedTools[codePrefixPattern.FindString(code)]
+	if !ok {
+		t.tool, t.kind = "msbuild", KindCompile
+	}
+	return &Finding{Kind: t.kind, Tool: t.tool, File: file, Code: code, Message: msg}
+}
+
+// msbuildPattern matches the canonical MSBuild error format used by csc,
+// tsc, cl, and friends: file(line,col): error CODE: message [project]
+var msbuildPattern = regexp.MustCompile(`^\s*(?:\d+>)?(.+?)\((\d+)(?:,(\d+))?(?:,\d+,\d+)?\)\s*:\s*(?:[Ff]atal )?[Ee]rror ([A-Z]+\d+)\s*:\s*(.+?)(?:\s+\[[^\]]+\])?\s*$`)
+
+func matchMSBuild(_ *distiller, _ int, text string) *Finding {
+	m := msbuildPattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	f := codedFinding(strings.TrimSpace(m[1]), m[4], m[5])
+	f.Line, f.Column = atoi(m[2]), atoi(m[3])
+	return f
+}
+
+// codedPattern matches coded errors without a line number, such as
+// "proj.csproj : error NU1101: ..." or "LINK : fatal error LNK1104: ...".
+var codedPattern = regexp.MustCompile(`^\s*(?:\d+>)?(?:(.*?)\s*:\s*)?(?:[Ff]atal )?[Ee]rror ([A-Z]+\d{3,5})\s*:\s*(.+?)(?:\s+\[[^\]]+\])?\s*$`)
+
+func matchCoded(_ *distiller, _ int, text string) *Finding {
+	m := codedPattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	file := m[1]
+	if !strings.ContainsAny(file, `/\.`) {
+		file = "" // a tool name such as LINK or CSC, not a path
+	}
+	return codedFinding(file, m[2], m[3])
+}
+
+var goPattern = regexp.MustCompile(`^\s*(\S+\.go):(\d+):(\d+):\s+(.+)$`)
+
+func matchGo(_ *distiller, _ int, text string) *Finding {
+	m := goPattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	return &Finding{Kind: KindCompile, Tool: "go", File: m[1], Line: atoi(m[2]), Column: atoi(m[3]), Message: m[4]}
+}
+
+var cFamilyPattern = regexp.MustCompile(`^\s*(\S+\.(?:c|cc|cpp|cxx|h|hh|hpp|m|mm)):(\d+):(\d+):\s+(?:fatal )?error:\s+(.+)$`)
+
+func matchCFamily(_ *distiller, _ int, text string) *Finding {
+	m := cFamilyPattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	return &Finding{Kind: KindCompile, Tool: "cc", File: m[1], Line: atoi(m[2]), Column: atoi(m[3]), Message: m[4]}
+}
+
+var (
+	javacPattern = regexp.MustCompile(`^\s*(\S+\.java):(\d+):\s+error:\s+(.+)$`)
+	mavenPattern = regexp.MustCompile(`^\s*\[ERROR\]\s+(\S+\.(?:java|kt|scala)):\[(\d+),(\d+)\]\s+(.+)$`)
+)
+
+func matchJava(_ *distiller, _ int, text string) *Finding {
+	if m := javacPattern.FindStringSubmatch(text); m != nil {
+		return &Finding{Kind: KindCompile, Tool: "javac", File: m[1], Line: atoi(m[2]), Message: m[3]}
+	}
+	if m := mavenPattern.FindStringSubmatch(text); m != nil {
+		return &Finding{Kind: KindCompile, Tool: "maven", File: m[1], Line: atoi(m[2]), Column: atoi(m[3]), Message: m[4]}
+	}
+	return nil
+}
+
+var (
+	rustPattern         = regexp.MustCompile(`^\s*error(?:\[(E\d{4})\])?:\s+(.+)$`)
+	rustLocationPattern = regexp.MustCompile(`^\s*-->\s+(\S+?):(\d+):(\d+)`)
+)
+
+// matchRust handles rustc's "error[E0425]: message" followed by a
+// "--> file:line:col" pointer. A bare "error: ..." is only taken when the
+// pointer follows, since many tools print lines of that shape.
+func matchRust(d *distiller, i int, text string) *Finding {
+	m := rustPattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	loc := d.lookahead(i, 3, rustLocationPattern, nil)
+	if loc == nil && m[1] == "" {
+		return nil
+	}
+	f := &Finding{Kind: KindCompile, Tool: "rustc", Code: m[1], Message: m[2]}
+	if loc != nil {
+		f.File, f.Line, f.Column = loc[1], atoi(loc[2]), atoi(loc[3])
+	}
+	return f
+}
+
+var (
+	undefinedReferencePattern = regexp.MustCompile("(undefined reference to [`'\"]?[^`'\"]+[`'\"]?)")
+	ldErrorPattern            = regexp.MustCompile(`\b(?:ld(?:\.lld|\.gold)?|collect2):\s+error:\s+(.+)$`)
+	undefinedSymbolsPattern   = regexp.MustCompile(`^\s*(Undefined symbols? for architecture \S+?):?$`)
+)
+
+func matchLinker(_ *distiller, _ int, text string) *Finding {
+	for _, re := range []*regexp.Regexp{undefinedReferencePattern, ldErrorPattern, undefinedSymbolsPattern} {
+		if m := re.FindStringSubmatch(text); m != nil {
+			return &Finding{Kind: KindLink, Tool: "ld", Message: m[1]}
+		}
+	}
+	return nil
+}
+
+var (
+	npmCodePattern    = regexp.MustCompile(`^\s*npm (?:ERR!|error) code (\S+)`)
+	npmMessagePattern = regexp.MustCompile(`^\s*npm (?:ERR!|error) (.+)$`)
+	npmDetailPattern  = regexp.MustCompile(`^(?:code|errno|syscall|path|A complete log|Log files)\b`)
+)
+
+// matchNpm takes npm's "npm ERR! code X" line as the failure and the first
+// descriptive npm error line after it as the message.
+func matchNpm(d *distiller, i int, text string) *Finding {
+	m := npmCodePattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	f := &Finding{Kind: KindPackage, Tool: "npm", Code: m[1]}
+	for j := i + 1; j < len(d.lines) && j <= i+6; j++ {
+		msg := npmMessagePattern.FindStringSubmatch(d.text(j))
+		if msg == nil {
+			break
+		}
+		if body := strings.TrimSpace(msg[1]); body != "" && !npmDetailPattern.MatchString(body) {
+			f.Message = body
+			break
+		}
+	}
+	return f
+}
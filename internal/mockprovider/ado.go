@@ -0,0 +1,419 @@
+package mockprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ADO JSON shapes. These mirror the subset of fields the ado backend decodes.
+
+type adoIdentity struct {
+	DisplayName string `json:"displayName"`
+	ID          string `json:"id"`
+	UniqueName  string `json:"uniqueName"`
+}
+
+type adoComment struct {
+	ID              int         `json:"id"`
+	Content         string      `json:"content"`
+	Author          adoIdentity `json:"author"`
+	CommentType     string      `json:"commentType"`
+	ParentCommentID int         `json:"parentCommentId,omitempty"`
+	PublishedDate   time.Time   `json:"publishedDate"`
+}
+
+type adoLineOffset struct {
+	Line   int `json:"line"`
+	Offset int `json:"offset"`
+}
+
+type adoThreadContext struct {
+	FilePath       string         `json:"filePath"`
+	RightFileStart *adoLineOffset `json:"rightFileStart,omitempty"`
+	RightFileEnd   *adoLineOffset `json:"rightFileEnd,omitempty"`
+}
+
+type adoThread struct {
+	ID            int               `json:"id"`
+	Status        int               `json:"status"`
+	ThreadContext *adoThreadContext `json:"threadContext,omitempty"`
+	Comments      []adoComment      `json:"comments"`
+	PublishedDate time.Time         `json:"publishedDate"`
+}
+
+type adoLink struct {
+	Href string `json:"href"`
+}
+
+type adoBuildDefinition struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type adoBuild struct {
+	ID           int                `json:"id"`
+	BuildNumber  string             `json:"buildNumber"`
+	Status       string             `json:"status"`
+	Result       string             `json:"result"`
+	SourceBranch string             `json:"sourceBranch"`
+	Definition   adoBuildDefinition `json:"definition"`
+	Links        struct {
+		Web adoLink `json:"web"`
+	} `json:"_links"`
+}
+
+// registerADORoutes wires the Azure DevOps REST endpoints used by the ado backend.
+func (s *Server) registerADORoutes(mux *http.ServeMux) {
+	const git = "/{org}/{project}/_apis/git/repositories/{repo}"
+	const builds = "/{org}/{project}/_apis/build/builds"
+
+	mux.HandleFunc("GET /{org}/_apis/connectiondata", s.adoConnectionData)
+
+	mux.HandleFunc("GET "+git+"/pullrequests", s.adoListPRs)
+	mux.HandleFunc("GET "+git+"/pullrequests/{id}", s.adoGetPR)
+	mux.HandleFunc("PATCH "+git+"/pullrequests/{id}", s.adoUpdatePR)
+	mux.HandleFunc("GET "+git+"/pullrequests/{id}/threads", s.adoListThreads)
+	mux.HandleFunc("POST "+git+"/pullrequests/{id}/threads", s.adoCreateThread)
+	mux.HandleFunc("PATCH "+git+"/pullrequests/{id}/threads/{tid}", s.adoUpdateThread)
+	mux.HandleFunc("POST "+git+"/pullrequests/{id}/threads/{tid}/comments", s.adoCreateComment)
+
+	mux.HandleFunc("GET "+builds, s.adoListBuilds)
+	mux.HandleFunc("POST "+builds, s.adoQueueBuild)
+	mux.HandleFunc("GET "+builds+"/{bid}", s.adoGetBuild)
+	mux.HandleFunc("GET "+builds+"/{bid}/timeline", s.adoTimeline)
+	mux.HandleFunc("GET "+builds+"/{bid}/logs/{logid}", s.adoLog)
+	mux.HandleFunc("GET "+builds+"/{bid}/artifacts", s.adoArtifacts)
+}
+
+func (s *Server) adoConnectionData(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"authenticatedUser": adoIdentity{DisplayName: "Otto Demo", ID: "otto-demo", UniqueName: "otto@example.com"},
+	})
+}
+
+// adoPR renders a pull request in ADO's JSON shape.
+func (s *Server) adoPR(r *http.Request, pr *pullRequest) map[string]any {
+	org, project, repo := r.PathValue("org"), r.PathValue("project"), r.PathValue("repo")
+	if repo == "" {
+		repo = Repository
+	}
+	return map[string]any{
+		"pullRequestId": pr.ID,
+		"title":         pr.Title,
+		"description":   pr.Description,
+		"status":        pr.Status,
+		"mergeStatus":   pr.MergeStatus,
+		"sourceRefName": pr.Source,
+		"targetRefName": pr.Target,
+		"createdBy":     adoIdentity{DisplayName: "Demo Author", ID: "demo-author"},
+		"repository":    map[string]any{"id": repo, "name": repo},
+		"_links": map[string]any{"web": adoLink{
+			Href: fmt.Sprintf("https://dev.azure.com/%s/%s/_git/%s/pullrequest/%d", org, project, repo, pr.ID),
+		}},
+	}
+}
+
+func (s *Server) adoListPRs(w http.ResponseWriter, r *http.Request) {
+	source := r.URL.Query().Get("searchCriteria.sourceRefName")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var value []map[string]any
+	for _, pr := range s.prs {
+		if source != "" && pr.Source != source {
+			continue
+		}
+		value = append(value, s.adoPR(r, pr))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"value": value, "count": len(value)})
+}
+
+func (s *Server) adoGetPR(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr, ok := s.prs[atoi(r.PathValue("id"))]
+	if !ok {
+		adoNotFound(w, "pull request")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.adoPR(r, pr))
+}
+
+func (s *Server) adoUpdatePR(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		AutoCompleteSetBy *adoIdentity `json:"autoCompleteSetBy"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr, ok := s.prs[atoi(r.PathValue("id"))]
+	if !ok {
+		adoNotFound(w, "pull request")
+		return
+	}
+	if body.AutoCompleteSetBy != nil {
+		pr.AutoComplete = true
+	}
+	writeJSON(w, http.StatusOK, s.adoPR(r, pr))
+}
+
+func (s *Server) adoListThreads(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshLocked()
+
+	pr, ok := s.prs[atoi(r.PathValue("id"))]
+	if !ok {
+		adoNotFound(w, "pull request")
+		return
+	}
+	value := make([]adoThread, 0, len(pr.Threads))
+	for _, t := range pr.Threads {
+		value = append(value, toADOThread(t))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"value": value, "count": len(value)})
+}
+
+func (s *Server) adoCreateThread(w http.ResponseWriter, r *http.Request) {
+	var body adoThread
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		adoBadRequest(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr, ok := s.prs[atoi(r.PathValue("id"))]
+	if !ok {
+		adoNotFound(w, "pull request")
+		return
+	}
+	t := &thread{ID: s.id(), Status: body.Status}
+	if t.Status == 0 {
+		t.Status = 1
+	}
+	if body.ThreadContext != nil {
+		t.FilePath = body.ThreadContext.FilePath
+		if body.ThreadContext.RightFileStart != nil {
+			t.Line = body.ThreadContext.RightFileStart.Line
+		}
+	}
+	for _, c := range body.Comments {
+		t.Comments = append(t.Comments, &comment{
+			ID: len(t.Comments) + 1, Author: "Otto Demo", Content: c.Content,
+			CommentType: "text", Published: time.Now().UTC(),
+		})
+	}
+	pr.Threads = append(pr.Threads, t)
+	writeJSON(w, http.StatusOK, toADOThread(t))
+}
+
+func (s *Server) adoUpdateThread(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Status int `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		adoBadRequest(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.findThreadLocked(atoi(r.PathValue("id")), atoi(r.PathValue("tid")))
+	if t == nil {
+		adoNotFound(w, "thread")
+		return
+	}
+	if body.Status != 0 {
+		t.Status = body.Status
+	}
+	writeJSON(w, http.StatusOK, toADOThread(t))
+}
+
+func (s *Server) adoCreateComment(w http.ResponseWriter, r *http.Request) {
+	var body adoComment
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		adoBadRequest(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.findThreadLocked(atoi(r.PathValue("id")), atoi(r.PathValue("tid")))
+	if t == nil {
+		adoNotFound(w, "thread")
+		return
+	}
+	c := &comment{
+		ID: len(t.Comments) + 1, ParentID: body.ParentCommentID, Author: "Otto Demo",
+		Content: body.Content, CommentType: "text", Published: time.Now().UTC(),
+	}
+	t.Comments = append(t.Comments, c)
+	writeJSON(w, http.StatusOK, toADOComment(c))
+}
+
+func (s *Server) adoListBuilds(w http.ResponseWriter, r *http.Request) {
+	branch := r.URL.Query().Get("branchName")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshLocked()
+
+	// ADO returns builds newest first.
+	var value []adoBuild
+	for i := len(s.builds) - 1; i >= 0; i-- {
+		b := s.builds[i]
+		if branch != "" && b.Branch != branch {
+			continue
+		}
+		value = append(value, toADOBuild(r, b))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"value": value, "count": len(value)})
+}
+
+func (s *Server) adoQueueBuild(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Definition struct {
+			ID int `json:"id"`
+		} `json:"definition"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		adoBadRequest(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var from *build
+	for i := len(s.builds) - 1; i >= 0; i-- {
+		if s.builds[i].DefID == body.Definition.ID {
+			from = s.builds[i]
+			break
+		}
+	}
+	if from == nil {
+		adoNotFound(w, "build definition")
+		return
+	}
+	writeJSON(w, http.StatusOK, toADOBuild(r, s.queueBuildLocked(from)))
+}
+
+func (s *Server) adoGetBuild(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.findBuildLocked(atoi(r.PathValue("bid")))
+	if b == nil {
+		adoNotFound(w, "build")
+		return
+	}
+	writeJSON(w, http.StatusOK, toADOBuild(r, b))
+}
+
+// adoTimeline reports a single Task record whose log ID equals the build ID.
+func (s *Server) adoTimeline(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.findBuildLocked(atoi(r.PathValue("bid")))
+	if b == nil {
+		adoNotFound(w, "build")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"records": []map[string]any{{
+			"type":   "Task",
+			"name":   "go test ./...",
+			"state":  "completed",
+			"result": b.Result,
+			"log":    map[string]any{"id": b.ID},
+		}},
+	})
+}
+
+func (s *Server) adoLog(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	b := s.findBuildLocked(atoi(r.PathValue("bid")))
+	s.mu.Unlock()
+	if b == nil {
+		adoNotFound(w, "build")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprint(w, b.Log)
+}
+
+func (s *Server) adoArtifacts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"value": []any{}, "count": 0})
+}
+
+// findThreadLocked returns the thread with the given ID on a PR, or nil.
+func (s *Server) findThreadLocked(prID, threadID int) *thread {
+	pr, ok := s.prs[prID]
+	if !ok {
+		return nil
+	}
+	for _, t := range pr.Threads {
+		if t.ID == threadID {
+			return t
+		}
+	}
+	return nil
+}
+
+func toADOComment(c *comment) adoComment {
+	return adoComment{
+		ID:              c.ID,
+		Content:         c.Content,
+		Author:          adoIdentity{DisplayName: c.Author, ID: c.Author},
+		CommentType:     c.CommentType,
+		ParentCommentID: c.ParentID,
+		PublishedDate:   c.Published,
+	}
+}
+
+func toADOThread(t *thread) adoThread {
+	out := adoThread{ID: t.ID, Status: t.Status}
+	if t.FilePath != "" {
+		out.ThreadContext = &adoThreadContext{
+			FilePath:       t.FilePath,
+			RightFileStart: &adoLineOffset{Line: t.Line, Offset: 1},
+			RightFileEnd:   &adoLineOffset{Line: t.Line, Offset: 1},
+		}
+	}
+	for _, c := range t.Comments {
+		out.Comments = append(out.Comments, toADOComment(c))
+	}
+	if len(t.Comments) > 0 {
+		out.PublishedDate = t.Comments[0].Published
+	}
+	return out
+}
+
+func toADOBuild(r *http.Request, b *build) adoBuild {
+	out := adoBuild{
+		ID:           b.ID,
+		BuildNumber:  strconv.Itoa(b.ID),
+		Status:       b.Status,
+		Result:       b.Result,
+		SourceBranch: b.Branch,
+		Definition:   adoBuildDefinition{ID: b.DefID, Name: b.DefName},
+	}
+	out.Links.Web.Href = fmt.Sprintf("https://dev.azure.com/%s/%s/_build/results?buildId=%d",
+		r.PathValue("org"), r.PathValue("project"), b.ID)
+	return out
+}
+
+func adoNotFound(w http.ResponseWriter, what string) {
+	writeJSON(w, http.StatusNotFound, map[string]any{
+		"message": what + " not found", "typeKey": "NotFoundException",
+	})
+}
+
+func adoBadRequest(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusBadRequest, map[string]any{
+		"message": err.Error(), "typeKey": "InvalidArgumentValueException",
+	})
+}
@@ -0,0 +1,361 @@
+package mockprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitHub comment IDs are derived from the shared thread model: a comment's ID
+// is threadID*commentIDStride + its index within the thread (1-based).
+const commentIDStride = 100
+
+// mockHeadSHA is the head commit reported for every mock pull request.
+const mockHeadSHA = "0123456789abcdef0123456789abcdef01234567"
+
+// registerGitHubRoutes wires the GitHub Enterprise-style REST and GraphQL
+// endpoints used by the github backend.
+func (s *Server) registerGitHubRoutes(mux *http.ServeMux) {
+	const repo = "/api/v3/repos/{owner}/{repo}"
+
+	mux.HandleFunc("GET "+repo+"/pulls/{n}", s.ghGetPR)
+	mux.HandleFunc("GET "+repo+"/commits/{sha}/check-runs", s.ghCheckRuns)
+	mux.HandleFunc("GET "+repo+"/commits/{sha}/status", s.ghCombinedStatus)
+	mux.HandleFunc("GET "+repo+"/issues/{n}/comments", s.ghListIssueComments)
+	mux.HandleFunc("POST "+repo+"/issues/{n}/comments", s.ghCreateIssueComment)
+	mux.HandleFunc("GET "+repo+"/pulls/{n}/comments", s.ghListReviewComments)
+	mux.HandleFunc("POST "+repo+"/pulls/{n}/comments", s.ghCreateReviewComment)
+	mux.HandleFunc("GET "+repo+"/pulls/{n}/reviews", s.ghListReviews)
+	mux.HandleFunc("POST "+repo+"/pulls/{n}/reviews", s.ghCreateReview)
+	mux.HandleFunc("GET "+repo+"/actions/runs/{id}/jobs", s.ghListJobs)
+	mux.HandleFunc("GET "+repo+"/actions/jobs/{id}/logs", s.ghJobLogs)
+	mux.HandleFunc("POST /api/graphql", s.ghGraphQL)
+}
+
+func (s *Server) ghGetPR(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr, ok := s.prs[atoi(r.PathValue("n"))]
+	if !ok {
+		ghNotFound(w)
+		return
+	}
+	owner, repo := r.PathValue("owner"), r.PathValue("repo")
+	state := "open"
+	if pr.Status != "active" {
+		state = "closed"
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"number":   pr.ID,
+		"title":    pr.Title,
+		"body":     pr.Description,
+		"state":    state,
+		"merged":   pr.Status == "completed",
+		"html_url": fmt.Sprintf("https://github.com/%s/%s/pull/%d", owner, repo, pr.ID),
+		"user":     map[string]any{"login": "demo-author"},
+		"head":     map[string]any{"ref": strings.TrimPrefix(pr.Source, "refs/heads/"), "sha": mockHeadSHA},
+		"base":     map[string]any{"ref": strings.TrimPrefix(pr.Target, "refs/heads/")},
+	})
+}
+
+// ghCheckRuns reports the latest build per definition as check runs.
+func (s *Server) ghCheckRuns(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshLocked()
+
+	var runs []map[string]any
+	for _, b := range s.latestBuildsLocked() {
+		runs = append(runs, map[string]any{
+			"id":         b.ID,
+			"name":       b.DefName,
+			"status":     "completed",
+			"conclusion": ghConclusion(b.Result),
+			"html_url":   fmt.Sprintf("https://github.com/%s/%s/actions/runs/%d", r.PathValue("owner"), r.PathValue("repo"), b.ID),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"total_count": len(runs), "check_runs": runs})
+}
+
+func (s *Server) ghCombinedStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"state": "success", "sha": r.PathValue("sha"), "statuses": []any{}})
+}
+
+func (s *Server) ghListIssueComments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr, ok := s.prs[atoi(r.PathValue("n"))]
+	if !ok {
+		ghNotFound(w)
+		return
+	}
+	out := []map[string]any{}
+	for _, t := range pr.Threads {
+		if t.FilePath != "" {
+			continue
+		}
+		for i, c := range t.Comments {
+			out = append(out, ghComment(t, i, c))
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) ghCreateIssueComment(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		ghBadRequest(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr, ok := s.prs[atoi(r.PathValue("n"))]
+	if !ok {
+		ghNotFound(w)
+		return
+	}
+	t := &thread{ID: s.id(), Status: 1}
+	c := &comment{ID: 1, Author: "otto-demo", Content: body.Body, CommentType: "text", Published: time.Now().UTC()}
+	t.Comments = append(t.Comments, c)
+	pr.Threads = append(pr.Threads, t)
+	writeJSON(w, http.StatusCreated, ghComment(t, 0, c))
+}
+
+func (s *Server) ghListReviewComments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshLocked()
+
+	pr, ok := s.prs[atoi(r.PathValue("n"))]
+	if !ok {
+		ghNotFound(w)
+		return
+	}
+	out := []map[string]any{}
+	for _, t := range pr.Threads {
+		if t.FilePath == "" {
+			continue
+		}
+		for i, c := range t.Comments {
+			out = append(out, ghComment(t, i, c))
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// ghCreateReviewComment handles replies (in_reply_to) to review comments.
+func (s *Server) ghCreateReviewComment(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Body      string `json:"body"`
+		InReplyTo int    `json:"in_reply_to"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		ghBadRequest(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.findThreadLocked(atoi(r.PathValue("n")), body.InReplyTo/commentIDStride)
+	if t == nil {
+		ghNotFound(w)
+		return
+	}
+	c := &comment{
+		ID: len(t.Comments) + 1, ParentID: 1, Author: "otto-demo",
+		Content: body.Body, CommentType: "text", Published: time.Now().UTC(),
+	}
+	t.Comments = append(t.Comments, c)
+	writeJSON(w, http.StatusCreated, ghComment(t, len(t.Comments)-1, c))
+}
+
+// ghCreateReview turns each review comment into a new inline thread.
+func (s *Server) ghCreateReview(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Body     string `json:"body"`
+		Comments []struct {
+			Path string `json:"path"`
+			Line int    `json:"line"`
+			Body string `json:"body"`
+		} `json:"comments"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		ghBadRequest(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr, ok := s.prs[atoi(r.PathValue("n"))]
+	if !ok {
+		ghNotFound(w)
+		return
+	}
+	for _, rc := range body.Comments {
+		t := &thread{ID: s.id(), Status: 1, FilePath: rc.Path, Line: rc.Line}
+		t.Comments = append(t.Comments, &comment{
+			ID: 1, Author: "otto-demo", Content: rc.Body, CommentType: "text", Published: time.Now().UTC(),
+		})
+		pr.Threads = append(pr.Threads, t)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": s.id(), "state": "COMMENTED", "body": body.Body})
+}
+
+// ghListReviews reports no review summaries; the mock keeps only threads.
+func (s *Server) ghListReviews(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, []map[string]any{})
+}
+
+// ghListJobs reports a single job per run; the job ID equals the run ID.
+func (s *Server) ghListJobs(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.findBuildLocked(atoi(r.PathValue("id")))
+	if b == nil {
+		ghNotFound(w)
+		return
+	}
+	conclusion := ghConclusion(b.Result)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"total_count": 1,
+		"jobs": []map[string]any{{
+			"id":         b.ID,
+			"run_id":     b.ID,
+			"name":       b.DefName,
+			"status":     "completed",
+			"conclusion": conclusion,
+			"steps": []map[string]any{
+				{"name": "go test ./...", "number": 1, "status": "completed", "conclusion": conclusion},
+			},
+		}},
+	})
+}
+
+// ghJobLogs redirects to the raw log, as GitHub does.
+func (s *Server) ghJobLogs(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, fmt.Sprintf("%s/_mock/logs/%s", s.publicURL(r), r.PathValue("id")), http.StatusFound)
+}
+
+var digits = regexp.MustCompile(`\d+`)
+
+// ghGraphQL implements just enough of the reviewThreads query and the
+// resolveReviewThread mutation. Threads are reported with node ID
+// "PRRT_<thread ID>"; the mutation accepts a thread ID or any of its
+// comment IDs, optionally wrapped in a node ID.
+func (s *Server) ghGraphQL(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Query     string `json:"query"`
+		Variables struct {
+			Number int `json:"number"`
+			Input  struct {
+				ThreadID string `json:"threadId"`
+			} `json:"input"`
+		} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		ghBadRequest(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if strings.Contains(body.Query, "mergeQueueEntry") {
+		// The mock's branches have no merge queue.
+		writeJSON(w, http.StatusOK, map[string]any{
+			"data": map[string]any{"repository": map[string]any{"pullRequest": map[string]any{
+				"isMergeQueueEnabled": false,
+				"mergeQueueEntry":     nil,
+			}}},
+		})
+		return
+	}
+	if strings.Contains(body.Query, "reviewThreads") {
+		nodes := []map[string]any{}
+		if pr, ok := s.prs[body.Variables.Number]; ok {
+			for _, t := range pr.Threads {
+				if t.FilePath == "" {
+					continue
+				}
+				nodes = append(nodes, map[string]any{
+					"id":         fmt.Sprintf("PRRT_%d", t.ID),
+					"isResolved": t.Status >= 2,
+					"comments":   map[string]any{"nodes": []map[string]any{{"databaseId": t.ID*commentIDStride + 1}}},
+				})
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"data": map[string]any{"repository": map[string]any{"pullRequest": map[string]any{
+				"reviewThreads": map[string]any{
+					"nodes":    nodes,
+					"pageInfo": map[string]any{"hasNextPage": false, "endCursor": ""},
+				},
+			}}},
+		})
+		return
+	}
+
+	id, _ := strconv.Atoi(digits.FindString(body.Variables.Input.ThreadID))
+	resolved := false
+	for prID := range s.prs {
+		for _, tid := range []int{id, id / commentIDStride} {
+			if t := s.findThreadLocked(prID, tid); t != nil {
+				t.Status = 2
+				resolved = true
+				break
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"resolveReviewThread": map[string]any{"thread": map[string]any{"isResolved": resolved}},
+		},
+	})
+}
+
+// ghComment renders comment i of thread t in GitHub's JSON shape.
+func ghComment(t *thread, i int, c *comment) map[string]any {
+	out := map[string]any{
+		"id":         t.ID*commentIDStride + i + 1,
+		"body":       c.Content,
+		"user":       map[string]any{"login": c.Author},
+		"created_at": c.Published.Format(time.RFC3339),
+	}
+	if t.FilePath != "" {
+		out["path"] = strings.TrimPrefix(t.FilePath, "/")
+		out["line"] = t.Line
+		if i > 0 {
+			out["in_reply_to_id"] = t.ID*commentIDStride + 1
+		}
+	}
+	return out
+}
+
+// ghConclusion maps an ADO build result to a GitHub check conclusion.
+func ghConclusion(result string) string {
+	switch result {
+	case "succeeded":
+		return "success"
+	case "failed":
+		return "failure"
+	case "canceled":
+		return "cancelled"
+	default:
+		return ""
+	}
+}
+
+func ghNotFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]any{"message": "Not Found"})
+}
+
+func ghBadRequest(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusBadRequest, map[string]any{"message": err.Error()})
+}
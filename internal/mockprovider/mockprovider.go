@@ -0,0 +1,416 @@
+// Package mockprovider implements a local HTTP server that emulates enough of
+// the Azure DevOps and GitHub REST APIs (pull requests, comment threads,
+// builds, and build logs) for otto's provider backends to run the full PR
+// monitoring loop against it. It backs `otto mock-provider` for demos and
+// onboarding, so the loop can be exercised with zero credentials.
+//
+// State is held in memory and driven by a named scenario. A scenario's
+// failing build turns green once the PR branch moves — detected by watching
+// a local git repository — or when POST /_mock/advance is called.
+package mockprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scenario names a scripted demo setup.
+type Scenario string
+
+const (
+	// ScenarioFixOnce has one PR whose CI build fails until a fix is pushed.
+	ScenarioFixOnce Scenario = "fix-once"
+	// ScenarioMerlinBot has one PR with a green build and three MerlinBot threads.
+	ScenarioMerlinBot Scenario = "merlinbot"
+	// ScenarioFull combines the failing build and the MerlinBot threads.
+	ScenarioFull Scenario = "full"
+)
+
+// Scenarios lists the supported scenario names.
+func Scenarios() []Scenario {
+	return []Scenario{ScenarioFixOnce, ScenarioMerlinBot, ScenarioFull}
+}
+
+// Fixed identifiers used by every scenario. They are exported so the CLI can
+// print ready-to-use config and PR URLs.
+const (
+	Organization = "mockorg"
+	Project      = "demo"
+	Repository   = "demo-repo"
+	SourceBranch = "otto/demo-fix"
+	TargetBranch = "main"
+	PRNumber     = 1
+)
+
+// Options configures a mock provider server.
+type Options struct {
+	Scenario Scenario
+	// RepoDir is an optional local git repository (bare or not) standing in
+	// for the PR's origin. When set, a failing build turns green once
+	// SourceBranch moves away from the commit it pointed at on startup.
+	RepoDir string
+	// PublicURL is the externally visible base URL, used to build links such
+	// as GitHub job log redirects. Defaults to the request host.
+	PublicURL string
+}
+
+// Server is the in-memory mock provider.
+type Server struct {
+	opts Options
+
+	mu          sync.Mutex
+	nextID      int
+	prs         map[int]*pullRequest
+	builds      []*build // oldest first
+	initialHead string
+	fixed       bool
+}
+
+type pullRequest struct {
+	ID           int
+	Title        string
+	Description  string
+	Source       string // refs/heads/...
+	Target       string // refs/heads/...
+	Status       string
+	MergeStatus  string
+	AutoComplete bool
+	Threads      []*thread
+}
+
+type thread struct {
+	ID       int
+	Status   int // 1=active, 2=fixed, 3=wontFix, 4=closed, 5=byDesign
+	FilePath string
+	Line     int
+	Comments []*comment
+}
+
+type comment struct {
+	ID          int
+	ParentID    int
+	Author      string
+	Content     string
+	CommentType string
+	Published   time.Time
+}
+
+type build struct {
+	ID      int
+	DefID   int
+	DefName string
+	Branch  string
+	Status  string
+	Result  string
+	Log     string
+}
+
+// New creates a mock provider seeded with the given scenario.
+func New(opts Options) (*Server, error) {
+	if opts.Scenario == "" {
+		opts.Scenario = ScenarioFull
+	}
+	s := &Server{
+		opts:   opts,
+		nextID: 100,
+		prs:    make(map[int]*pullRequest),
+	}
+	if err := s.seed(); err != nil {
+		return nil, err
+	}
+	if opts.RepoDir != "" {
+		head, err := s.branchHead()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s in %s: %w", SourceBranch, opts.RepoDir, err)
+		}
+		s.initialHead = head
+	}
+	return s, nil
+}
+
+// id returns a fresh identifier. Callers must hold s.mu.
+func (s *Server) id() int {
+	s.nextID++
+	return s.nextID
+}
+
+// seed populates the PR, builds, and threads for the configured scenario.
+func (s *Server) seed() error {
+	pr := &pullRequest{
+		ID:          PRNumber,
+		Title:       "Add request retry helper",
+		Description: "Adds a small retry helper used by the HTTP client.\n\nThis PR is served by `otto mock-provider`.",
+		Source:      "refs/heads/" + SourceBranch,
+		Target:      "refs/heads/" + TargetBranch,
+		Status:      "active",
+		MergeStatus: "succeeded",
+	}
+	s.prs[pr.ID] = pr
+
+	mergeRef := fmt.Sprintf("refs/pull/%d/merge", pr.ID)
+	switch s.opts.Scenario {
+	case ScenarioFixOnce, ScenarioFull:
+		s.builds = append(s.builds, &build{
+			ID: s.id(), DefID: 1, DefName: "CI", Branch: mergeRef,
+			Status: "completed", Result: "failed", Log: failingLog,
+		})
+	case ScenarioMerlinBot:
+		s.builds = append(s.builds, &build{
+			ID: s.id(), DefID: 1, DefName: "CI", Branch: mergeRef,
+			Status: "completed", Result: "succeeded", Log: passingLog,
+		})
+	default:
+		return fmt.Errorf("unknown scenario %q (supported: %v)", s.opts.Scenario, Scenarios())
+	}
+
+	if s.opts.Scenario == ScenarioMerlinBot || s.opts.Scenario == ScenarioFull {
+		for _, c := range merlinBotComments {
+			t := &thread{ID: s.id(), Status: 1, FilePath: c.file, Line: c.line}
+			t.Comments = append(t.Comments, &comment{
+				ID: 1, Author: "MerlinBot", Content: c.body, CommentType: "text",
+				Published: time.Now().UTC().Add(-time.Hour),
+			})
+			pr.Threads = append(pr.Threads, t)
+		}
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler serving both provider APIs.
+// ADO routes live at the root (as on dev.azure.com); GitHub routes live under
+// /api/v3 and /api/graphql (as on GitHub Enterprise).
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	s.registerADORoutes(mux)
+	s.registerGitHubRoutes(mux)
+	mux.HandleFunc("POST /_mock/advance", s.handleAdvance)
+	mux.HandleFunc("GET /_mock/state", s.handleState)
+	mux.HandleFunc("GET /_mock/logs/{id}", s.handleRawLog)
+	return logRequests(mux)
+}
+
+// ListenAndServe serves the mock provider on addr until ctx is cancelled.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{Addr: addr, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("mock provider shutdown error", "error", err)
+		}
+	}()
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return fmt.Errorf("mock provider: %w", err)
+	}
+	return nil
+}
+
+// Advance marks the scenario's fix as landed: a new, passing build is
+// appended for every failing pipeline.
+func (s *Server) Advance() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.advanceLocked()
+}
+
+func (s *Server) advanceLocked() {
+	if s.fixed {
+		return
+	}
+	s.fixed = true
+	latest := s.latestBuildsLocked()
+	for _, b := range latest {
+		if b.Result == "succeeded" {
+			continue
+		}
+		s.builds = append(s.builds, &build{
+			ID: s.id(), DefID: b.DefID, DefName: b.DefName, Branch: b.Branch,
+			Status: "completed", Result: "succeeded", Log: passingLog,
+		})
+	}
+	slog.Info("mock provider advanced: builds are now green")
+}
+
+// refreshLocked checks the watched repository for a moved branch head and
+// advances the scenario when it changes.
+func (s *Server) refreshLocked() {
+	if s.fixed || s.opts.RepoDir == "" {
+		return
+	}
+	head, err := s.branchHead()
+	if err != nil {
+		slog.Debug("mock provider could not read branch head", "error", err)
+		return
+	}
+	if head != s.initialHead {
+		slog.Info("mock provider detected push", "branch", SourceBranch, "head", head)
+		s.advanceLocked()
+	}
+}
+
+// branchHead returns the commit SourceBranch points at in RepoDir.
+func (s *Server) branchHead() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "refs/heads/"+SourceBranch)
+	cmd.Dir = s.opts.RepoDir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// latestBuildsLocked returns the newest build per definition, newest first.
+func (s *Server) latestBuildsLocked() []*build {
+	seen := make(map[int]bool)
+	var out []*build
+	for i := len(s.builds) - 1; i >= 0; i-- {
+		b := s.builds[i]
+		if seen[b.DefID] {
+			continue
+		}
+		seen[b.DefID] = true
+		out = append(out, b)
+	}
+	return out
+}
+
+// findBuildLocked returns the build with the given ID, or nil.
+func (s *Server) findBuildLocked(id int) *build {
+	for _, b := range s.builds {
+		if b.ID == id {
+			return b
+		}
+	}
+	return nil
+}
+
+// queueBuildLocked queues a new run of an existing build's definition. The
+// result mirrors the scenario state: still failing until the fix lands.
+func (s *Server) queueBuildLocked(from *build) *build {
+	nb := &build{
+		ID: s.id(), DefID: from.DefID, DefName: from.DefName, Branch: from.Branch,
+		Status: "completed", Result: "succeeded", Log: passingLog,
+	}
+	if !s.fixed && (s.opts.Scenario == ScenarioFixOnce || s.opts.Scenario == ScenarioFull) {
+		nb.Result = "failed"
+		nb.Log = failingLog
+	}
+	s.builds = append(s.builds, nb)
+	return nb
+}
+
+func (s *Server) handleAdvance(w http.ResponseWriter, r *http.Request) {
+	s.Advance()
+	writeJSON(w, http.StatusOK, map[string]any{"fixed": true})
+}
+
+// handleState dumps the mock state for debugging demos.
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type threadState struct {
+		ID       int    `json:"id"`
+		Status   int    `json:"status"`
+		File     string `json:"file,omitempty"`
+		Comments int    `json:"comments"`
+	}
+	var threads []threadState
+	for _, pr := range s.prs {
+		for _, t := range pr.Threads {
+			threads = append(threads, threadState{ID: t.ID, Status: t.Status, File: t.FilePath, Comments: len(t.Comments)})
+		}
+	}
+	sort.Slice(threads, func(i, j int) bool { return threads[i].ID < threads[j].ID })
+
+	var builds []map[string]any
+	for _, b := range s.builds {
+		builds = append(builds, map[string]any{"id": b.ID, "name": b.DefName, "result": b.Result})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"scenario": s.opts.Scenario,
+		"fixed":    s.fixed,
+		"builds":   builds,
+		"threads":  threads,
+	})
+}
+
+// handleRawLog serves a build log as plain text (GitHub log redirects land here).
+func (s *Server) handleRawLog(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	b := s.findBuildLocked(atoi(r.PathValue("id")))
+	s.mu.Unlock()
+	if b == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprint(w, b.Log)
+}
+
+// publicURL returns the base URL clients should use to reach this server.
+func (s *Server) publicURL(r *http.Request) string {
+	if s.opts.PublicURL != "" {
+		return strings.TrimSuffix(s.opts.PublicURL, "/")
+	}
+	return "http://" + r.Host
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("mock provider failed to encode response", "error", err)
+	}
+}
+
+// atoi parses an integer path value, returning 0 on error.
+func atoi(s string) int {
+	var n int
+	_, _ = fmt.Sscanf(s, "%d", &n)
+	return n
+}
+
+// logRequests logs each request at debug level.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slog.Debug("mock provider request", "method", r.Method, "path", r.URL.Path)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Scripted content ------------------------------------------------------------
+
+const failingLog = `2026-01-01T00:00:00.000Z Starting: go test ./...
+2026-01-01T00:00:01.000Z ok   example.com/demo/client  0.012s
+2026-01-01T00:00:02.000Z --- FAIL: TestRetry (0.00s)
+2026-01-01T00:00:02.000Z     retry_test.go:18: expected 3 attempts, got 4
+##[error]retry.go:14: off-by-one in retry loop: condition should be attempt < max, not attempt <= max
+2026-01-01T00:00:02.000Z FAIL example.com/demo/retry 0.004s
+##[error]Bash exited with code '1'.
+2026-01-01T00:00:03.000Z Finishing: go test ./...`
+
+const passingLog = `2026-01-01T00:00:00.000Z Starting: go test ./...
+2026-01-01T00:00:01.000Z ok   example.com/demo/client  0.012s
+2026-01-01T00:00:02.000Z ok   example.com/demo/retry   0.004s
+2026-01-01T00:00:03.000Z Finishing: go test ./...`
+
+var merlinBotComments = []struct {
+	file string
+	line int
+	body string
+}{
+	{"/retry.go", 9, "**MerlinBot** — Exported function `Retry` is missing a doc comment. Public APIs should document their behavior and error semantics."},
+	{"/retry.go", 14, "**MerlinBot** — The retry loop sleeps for a fixed interval. Consider exponential backoff with jitter to avoid thundering-herd retries."},
+	{"/client.go", 27, "**MerlinBot** — `http.DefaultClient` has no timeout. Use a client with an explicit `Timeout` to avoid hanging requests."},
+}
@@ -0,0 +1,165 @@
+package mockprovider
+
+import (
+	"context"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/alanmeadows/otto/internal/provider/ado"
+	ghbackend "github.com/alanmeadows/otto/internal/provider/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, opts Options) (*Server, *httptest.Server) {
+	t.Helper()
+	s, err := New(opts)
+	require.NoError(t, err)
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+func newADOBackend(baseURL string) *ado.Backend {
+	b := ado.NewBackend(Organization, Project, ado.NewAuthProvider("mock"))
+	b.SetBaseURL(baseURL)
+	return b
+}
+
+func TestNew_UnknownScenario(t *testing.T) {
+	_, err := New(Options{Scenario: "nope"})
+	assert.ErrorContains(t, err, "unknown scenario")
+}
+
+func TestADO_FixOnceScenario(t *testing.T) {
+	s, ts := newTestServer(t, Options{Scenario: ScenarioFixOnce})
+	b := newADOBackend(ts.URL)
+	ctx := context.Background()
+
+	pr, err := b.GetPR(ctx, "https://dev.azure.com/mockorg/demo/_git/demo-repo/pullrequest/1")
+	require.NoError(t, err)
+	assert.Equal(t, "1", pr.ID)
+	assert.Equal(t, "refs/heads/"+SourceBranch, pr.SourceBranch)
+
+	status, err := b.GetPipelineStatus(ctx, pr)
+	require.NoError(t, err)
+	assert.Equal(t, "failed", status.State)
+	require.Len(t, status.Builds, 1)
+
+	logs, err := b.GetBuildLogs(ctx, pr, status.Builds[0].ID)
+	require.NoError(t, err)
+	assert.Contains(t, logs, "off-by-one in retry loop")
+
+	// Retrying before the fix lands still fails.
+	require.NoError(t, b.RetryBuild(ctx, pr, status.Builds[0].ID))
+	status, err = b.GetPipelineStatus(ctx, pr)
+	require.NoError(t, err)
+	assert.Equal(t, "failed", status.State)
+
+	s.Advance()
+	status, err = b.GetPipelineStatus(ctx, pr)
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", status.State)
+}
+
+func TestADO_MerlinBotScenario(t *testing.T) {
+	_, ts := newTestServer(t, Options{Scenario: ScenarioMerlinBot})
+	b := newADOBackend(ts.URL)
+	ctx := context.Background()
+
+	pr, err := b.GetPR(ctx, "https://dev.azure.com/mockorg/demo/_git/demo-repo/pullrequest/1")
+	require.NoError(t, err)
+
+	status, err := b.GetPipelineStatus(ctx, pr)
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", status.State)
+
+	comments, err := b.GetComments(ctx, pr)
+	require.NoError(t, err)
+	require.Len(t, comments, 3)
+	for _, c := range comments {
+		assert.Equal(t, "MerlinBot", c.Author)
+		assert.False(t, c.IsResolved)
+	}
+
+	require.NoError(t, b.ReplyToComment(ctx, pr, comments[0].ThreadID, "Added a doc comment."))
+	require.NoError(t, b.ResolveComment(ctx, pr, comments[0].ThreadID, provider.ResolutionFixed))
+
+	comments, err = b.GetComments(ctx, pr)
+	require.NoError(t, err)
+	var resolved, replies int
+	for _, c := range comments {
+		if c.IsResolved {
+			resolved++
+		}
+		if c.Author == "Otto Demo" {
+			replies++
+		}
+	}
+	assert.Equal(t, 1, replies)
+	assert.Positive(t, resolved)
+}
+
+func TestGitHub_FixOnceScenario(t *testing.T) {
+	s, ts := newTestServer(t, Options{Scenario: ScenarioFull})
+	b := ghbackend.NewBackend(Organization, Repository, "mock")
+	require.NoError(t, b.SetBaseURL(ts.URL))
+	ctx := context.Background()
+
+	pr, err := b.GetPR(ctx, "https://github.com/mockorg/demo-repo/pull/1")
+	require.NoError(t, err)
+	assert.Equal(t, SourceBranch, pr.SourceBranch)
+
+	status, err := b.GetPipelineStatus(ctx, pr)
+	require.NoError(t, err)
+	assert.Equal(t, "failed", status.State)
+	require.Len(t, status.Builds, 1)
+
+	logs, err := b.GetBuildLogs(ctx, pr, status.Builds[0].ID)
+	require.NoError(t, err)
+	assert.Contains(t, logs, "off-by-one in retry loop")
+
+	comments, err := b.GetComments(ctx, pr)
+	require.NoError(t, err)
+	assert.Len(t, comments, 3)
+	require.NoError(t, b.ReplyToComment(ctx, pr, comments[0].ID, "Done."))
+
+	s.Advance()
+	status, err = b.GetPipelineStatus(ctx, pr)
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", status.State)
+}
+
+func TestRepoWatch_AdvancesOnPush(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	run("init", "-q")
+	run("-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "init")
+	run("branch", SourceBranch)
+
+	_, ts := newTestServer(t, Options{Scenario: ScenarioFixOnce, RepoDir: dir})
+	b := newADOBackend(ts.URL)
+	ctx := context.Background()
+	pr := &provider.PRInfo{ID: "1", RepoID: Repository, Project: Project, Organization: Organization}
+
+	status, err := b.GetPipelineStatus(ctx, pr)
+	require.NoError(t, err)
+	assert.Equal(t, "failed", status.State)
+
+	run("checkout", "-q", SourceBranch)
+	run("-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "fix")
+
+	status, err = b.GetPipelineStatus(ctx, pr)
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", status.State)
+}
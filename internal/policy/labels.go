@@ -0,0 +1,24 @@
+package policy
+
+import (
+	"slices"
+
+	"github.com/alanmeadows/otto/internal/config"
+)
+
+// Labels returns the labels of the rules matching any of files, in rule
+// order and without duplicates.
+func Labels(rules []config.LabelRule, files []string) []string {
+	var labels []string
+	for _, rule := range rules {
+		if slices.Contains(labels, rule.Label) {
+			continue
+		}
+		if slices.ContainsFunc(files, func(f string) bool {
+			return slices.ContainsFunc(rule.Paths, func(pattern string) bool { return Match(pattern, f) })
+		}) {
+			labels = append(labels, rule.Label)
+		}
+	}
+	return labels
+}
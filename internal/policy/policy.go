@@ -0,0 +1,128 @@
+// Package policy checks the changes otto is about to push on its own
+// against configured limits: protected paths automated commits may not
+// touch, caps on how large a single push may be, and credentials that must
+// never be pushed. It also sizes up a PR's changes for its risk analysis
+// and labels.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/config"
+)
+
+// Rule names reported in violations.
+const (
+	RuleProtectedPath = "protected_path"
+	RuleMaxDiffLines  = "max_diff_lines"
+	RuleMaxDiffFiles  = "max_diff_files"
+)
+
+// Change describes a set of commits about to be pushed.
+type Change struct {
+	Files      []string // slash-separated paths relative to the repo root
+	Insertions int
+	Deletions  int
+}
+
+// Violation is one broken policy rule.
+type Violation struct {
+	Rule   string
+	Detail string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Rule, v.Detail)
+}
+
+// Check returns the rules in p that c breaks, or nil if it complies.
+func Check(p config.PolicyConfig, c Change) []Violation {
+	var violations []Violation
+	for _, f := range c.Files {
+		for _, pattern := range p.ProtectedPaths {
+			if Match(pattern, f) {
+				violations = append(violations, Violation{RuleProtectedPath, fmt.Sprintf("%s matches %q", f, pattern)})
+				break
+			}
+		}
+	}
+	if lines := c.Insertions + c.Deletions; p.MaxDiffLines > 0 && lines > p.MaxDiffLines {
+		violations = append(violations, Violation{RuleMaxDiffLines, fmt.Sprintf("%d lines changed, limit %d", lines, p.MaxDiffLines)})
+	}
+	if p.MaxDiffFiles > 0 && len(c.Files) > p.MaxDiffFiles {
+		violations = append(violations, Violation{RuleMaxDiffFiles, fmt.Sprintf("%d files changed, limit %d", len(c.Files), p.MaxDiffFiles)})
+	}
+	return violations
+}
+
+// Match reports whether name matches the slash-separated glob pattern.
+// Segments use path.Match syntax; a "**" segment matches any number of
+// directories, including none. A pattern without a slash matches the base
+// name at any depth, as in .gitignore.
+func Match(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// Diff returns the change between base and HEAD in the git repository at
+// workDir, limited to paths when any are given.
+func Diff(ctx context.Context, workDir, base string, paths ...string) (Change, error) {
+	args := []string{"diff", "--numstat", "--no-renames", base, "HEAD"}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return Change{}, fmt.Errorf("git diff --numstat %s: %w", base, err)
+	}
+	return parseNumstat(string(out)), nil
+}
+
+// parseNumstat parses "git diff --numstat" output. Binary files ("-")
+// count as changed files with no lines.
+func parseNumstat(out string) Change {
+	var c Change
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		c.Insertions += added
+		c.Deletions += deleted
+		c.Files = append(c.Files, fields[2])
+	}
+	return c
+}
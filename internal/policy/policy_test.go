@@ -0,0 +1,72 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"**/secrets/*", "secrets/key.pem", true},
+		{"**/secrets/*", "app/config/secrets/key.pem", true},
+		{"**/secrets/*", "app/secrets/nested/key.pem", false},
+		{"deploy/prod/**", "deploy/prod/values.yaml", true},
+		{"deploy/prod/**", "deploy/prod/eu/values.yaml", true},
+		{"deploy/prod/**", "deploy/staging/values.yaml", false},
+		{"/go.mod", "go.mod", true},
+		{"*.pem", "certs/server.pem", true},
+		{"*.pem", "certs/server.pem.txt", false},
+		{".github/workflows/*.yml", ".github/workflows/ci.yml", true},
+		{".github/workflows/*.yml", "sub/.github/workflows/ci.yml", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Match(tt.pattern, tt.name), "%s vs %s", tt.pattern, tt.name)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p := config.PolicyConfig{
+		ProtectedPaths: []string{"deploy/prod/**", "*.pem"},
+		MaxDiffLines:   100,
+		MaxDiffFiles:   2,
+	}
+
+	assert.Empty(t, Check(p, Change{Files: []string{"main.go"}, Insertions: 10, Deletions: 5}))
+
+	violations := Check(p, Change{
+		Files:      []string{"deploy/prod/values.yaml", "certs/a.pem", "main.go"},
+		Insertions: 90,
+		Deletions:  20,
+	})
+	require.Len(t, violations, 4)
+	assert.Equal(t, RuleProtectedPath, violations[0].Rule)
+	assert.Contains(t, violations[0].Detail, "deploy/prod/values.yaml")
+	assert.Equal(t, RuleProtectedPath, violations[1].Rule)
+	assert.Equal(t, "max_diff_lines: 110 lines changed, limit 100", violations[2].String())
+	assert.Equal(t, RuleMaxDiffFiles, violations[3].Rule)
+}
+
+func TestLabels(t *testing.T) {
+	rules := []config.LabelRule{
+		{Label: "docs", Paths: []string{"docs/**", "*.md"}},
+		{Label: "area/api", Paths: []string{"internal/api/**"}},
+		{Label: "docs", Paths: []string{"site/**"}},
+		{Label: "ci", Paths: []string{".github/workflows/*"}},
+	}
+	assert.Equal(t, []string{"docs", "area/api"}, Labels(rules, []string{"README.md", "internal/api/v1/handler.go", "site/index.html"}))
+	assert.Empty(t, Labels(rules, []string{"main.go"}))
+}
+
+func TestParseNumstat(t *testing.T) {
+	c := parseNumstat("10\t2\tmain.go\n-\t-\tlogo.png\n3\t0\tdocs/a b.md\n")
+	assert.Equal(t, []string{"main.go", "logo.png", "docs/a b.md"}, c.Files)
+	assert.Equal(t, 13, c.Insertions)
+	assert.Equal(t, 2, c.Deletions)
+	assert.Empty(t, parseNumstat("").Files)
+}
@@ -0,0 +1,247 @@
+package policy
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// RiskReport is a reviewer's first look at a PR: how big it is, where it
+// lands, what in it is risky, and which changed code has no test changed
+// alongside it.
+type RiskReport struct {
+	Files     int
+	Additions int
+	Deletions int
+
+	// Areas are the directories touched, up to two levels deep, with the
+	// number of files changed in each, most touched first.
+	Areas []Area
+	// Risks name the risky kinds of change found, each with the first
+	// file that showed it.
+	Risks []Risk
+	// Untested lists changed source files with no test file changed in
+	// the same directory.
+	Untested []string
+}
+
+// Area is a directory a change touches.
+type Area struct {
+	Dir   string
+	Files int
+}
+
+// Risk is a risky kind of change and the file that showed it.
+type Risk struct {
+	Kind string
+	File string
+}
+
+// riskRules recognize risky changes by the changed file's path or by the
+// text of a changed line.
+var riskRules = []struct {
+	kind    string
+	path    *regexp.Regexp
+	content *regexp.Regexp
+}{
+	{"database migration", regexp.MustCompile(`(?i)(^|/)(migrations?|migrate)/|\.sql$`), regexp.MustCompile(`(?i)\b(alter|drop)\s+table\b`)},
+	{"auth or security code", regexp.MustCompile(`(?i)(auth(n|z|entication|orization)?([^a-z]|$)|login|saml|token|secret|credential|password|permission|rbac|crypto)`), nil},
+	{"CI/CD configuration", regexp.MustCompile(`(?i)(^\.github/workflows/|azure-pipelines|\.gitlab-ci|(^|/)jenkinsfile$)`), nil},
+	{"dependency change", regexp.MustCompile(`(^|/)(go\.mod|package\.json|requirements\.txt|pyproject\.toml|Cargo\.toml|pom\.xml|build\.gradle|packages\.config|[^/]+\.csproj)$`), nil},
+	{"infrastructure", regexp.MustCompile(`(?i)(\.tf$|\.bicep$|(^|/)dockerfile$|(^|/)(helm|charts|k8s|kubernetes)/)`), nil},
+}
+
+// testFilePattern matches test files in the common languages' layouts.
+var testFilePattern = regexp.MustCompile(`(?i)(_test\.go$|\.(test|spec)\.[jt]sx?$|(^|/)test_[^/]*\.py$|_test\.py$|tests?\.cs$|test\.java$|(^|/)(tests?|__tests__)/)`)
+
+// sourceExts are the extensions of files whose changes want tests.
+var sourceExts = []string{
+	".go", ".py", ".js", ".jsx", ".ts", ".tsx", ".cs", ".java", ".kt", ".rs",
+	".rb", ".c", ".cc", ".cpp", ".h", ".hpp", ".swift", ".ps1", ".sh",
+}
+
+// AnalyzeRisk builds a RiskReport from a unified diff, as GetDiff returns.
+func AnalyzeRisk(diff string) *RiskReport {
+	r := &RiskReport{}
+	var files, deleted []string
+	risky := make(map[string]bool)
+	flag := func(kind, file string) {
+		if !risky[kind] {
+			risky[kind] = true
+			r.Risks = append(r.Risks, Risk{Kind: kind, File: file})
+		}
+	}
+
+	var file string
+	inHeader := false // between a "diff --git" line and its first hunk
+	sc := bufio.NewScanner(strings.NewReader(diff))
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		text := sc.Text()
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			// The new name; renames are counted where they land.
+			_, name, _ := strings.Cut(text, " b/")
+			file = name
+			files = append(files, file)
+			inHeader = true
+			for _, rule := range riskRules {
+				if rule.path.MatchString(file) {
+					flag(rule.kind, file)
+				}
+			}
+		case strings.HasPrefix(text, "@@"):
+			inHeader = false
+		case inHeader:
+			if text == "+++ /dev/null" {
+				deleted = append(deleted, file)
+			}
+		case strings.HasPrefix(text, "+"), strings.HasPrefix(text, "-"):
+			if text[0] == '+' {
+				r.Additions++
+			} else {
+				r.Deletions++
+			}
+			for _, rule := range riskRules {
+				if rule.content != nil && rule.content.MatchString(text[1:]) {
+					flag(rule.kind, file)
+				}
+			}
+		}
+	}
+	r.Files = len(files)
+
+	areas := make(map[string]int)
+	testedDirs := make(map[string]bool)
+	for _, f := range files {
+		areas[area(f)]++
+		if testFilePattern.MatchString(f) {
+			testedDirs[path.Dir(f)] = true
+		}
+	}
+	for dir, n := range areas {
+		r.Areas = append(r.Areas, Area{Dir: dir, Files: n})
+	}
+	slices.SortFunc(r.Areas, func(a, b Area) int {
+		if a.Files != b.Files {
+			return b.Files - a.Files
+		}
+		return strings.Compare(a.Dir, b.Dir)
+	})
+	for _, f := range files {
+		if slices.Contains(deleted, f) || testFilePattern.MatchString(f) ||
+			!slices.Contains(sourceExts, strings.ToLower(path.Ext(f))) || testedDirs[path.Dir(f)] {
+			continue
+		}
+		r.Untested = append(r.Untested, f)
+	}
+	return r
+}
+
+// area returns the directory of file, up to two levels deep, or "." for a
+// file at the repository root.
+func area(file string) string {
+	parts := strings.Split(path.Dir(file), "/")
+	return strings.Join(parts[:min(len(parts), 2)], "/")
+}
+
+// Lines returns the number of lines added and deleted.
+func (r *RiskReport) Lines() int {
+	return r.Additions + r.Deletions
+}
+
+// Size rates the change by lines changed: small, medium, large, or very
+// large.
+func (r *RiskReport) Size() string {
+	switch n := r.Lines(); {
+	case n < 50:
+		return "small"
+	case n < 250:
+		return "medium"
+	case n < 1000:
+		return "large"
+	default:
+		return "very large"
+	}
+}
+
+// Level rates the change's overall risk, low, medium, or high, from its
+// size, the risky kinds of change in it, and how much of its code is
+// untested.
+func (r *RiskReport) Level() string {
+	score := len(r.Risks)
+	switch r.Size() {
+	case "large":
+		score++
+	case "very large":
+		score += 2
+	}
+	if len(r.Untested) > 0 {
+		score++
+	}
+	switch {
+	case score >= 3:
+		return "high"
+	case score >= 1:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// Summary is a one-line form of the report, e.g. "high risk: large change
+// (612 lines, 14 files); database migration, auth or security code; 3
+// files untested".
+func (r *RiskReport) Summary() string {
+	s := fmt.Sprintf("%s risk: %s change (%d lines, %d files)", r.Level(), r.Size(), r.Lines(), r.Files)
+	if len(r.Risks) > 0 {
+		kinds := make([]string, len(r.Risks))
+		for i, risk := range r.Risks {
+			kinds[i] = risk.Kind
+		}
+		s += "; " + strings.Join(kinds, ", ")
+	}
+	switch n := len(r.Untested); n {
+	case 0:
+	case 1:
+		s += "; 1 file untested"
+	default:
+		s += fmt.Sprintf("; %d files untested", n)
+	}
+	return s
+}
+
+// Markdown renders the report as a section for a PR description.
+func (r *RiskReport) Markdown() string {
+	var b strings.Builder
+	b.WriteString("## Risk analysis\n\n")
+	fmt.Fprintf(&b, "- **Risk**: %s\n", r.Level())
+	fmt.Fprintf(&b, "- **Size**: %s (+%d/-%d lines, %d files)\n", r.Size(), r.Additions, r.Deletions, r.Files)
+	if len(r.Areas) > 0 {
+		areas := make([]string, len(r.Areas))
+		for i, a := range r.Areas {
+			areas[i] = fmt.Sprintf("`%s` (%d)", a.Dir, a.Files)
+		}
+		fmt.Fprintf(&b, "- **Areas**: %s\n", strings.Join(areas, ", "))
+	}
+	for _, risk := range r.Risks {
+		fmt.Fprintf(&b, "- **%s**: `%s`\n", capitalize(risk.Kind), risk.File)
+	}
+	if len(r.Untested) > 0 {
+		b.WriteString("- **No test changes alongside**:\n")
+		for _, f := range r.Untested {
+			fmt.Fprintf(&b, "  - `%s`\n", f)
+		}
+	}
+	return b.String()
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,78 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeRisk(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/internal/auth/token.go b/internal/auth/token.go",
+		"--- a/internal/auth/token.go",
+		"+++ b/internal/auth/token.go",
+		"@@ -1,2 +1,3 @@",
+		" package auth",
+		"-var ttl = 1",
+		"+var ttl = 2",
+		"+var skew = 3",
+		"diff --git a/db/migrations/0042_users.sql b/db/migrations/0042_users.sql",
+		"new file mode 100644",
+		"--- /dev/null",
+		"+++ b/db/migrations/0042_users.sql",
+		"@@ -0,0 +1,2 @@",
+		"+ALTER TABLE users ADD COLUMN email text;",
+		"+--- not a header",
+		"diff --git a/internal/server/api.go b/internal/server/api.go",
+		"--- a/internal/server/api.go",
+		"+++ b/internal/server/api.go",
+		"@@ -5 +5 @@",
+		"-old",
+		"+new",
+		"diff --git a/internal/server/api_test.go b/internal/server/api_test.go",
+		"--- a/internal/server/api_test.go",
+		"+++ b/internal/server/api_test.go",
+		"@@ -5 +5 @@",
+		"+new",
+		"diff --git a/legacy/old.go b/legacy/old.go",
+		"deleted file mode 100644",
+		"--- a/legacy/old.go",
+		"+++ /dev/null",
+		"@@ -1 +0,0 @@",
+		"-package legacy",
+		"diff --git a/README.md b/README.md",
+		"--- a/README.md",
+		"+++ b/README.md",
+		"@@ -1 +1 @@",
+		"-# x",
+		"+# y",
+	}, "\n")
+
+	r := AnalyzeRisk(diff)
+	assert.Equal(t, 6, r.Files)
+	assert.Equal(t, 7, r.Additions)
+	assert.Equal(t, 4, r.Deletions)
+	assert.Equal(t, []Area{
+		{Dir: "internal/server", Files: 2},
+		{Dir: ".", Files: 1},
+		{Dir: "db/migrations", Files: 1},
+		{Dir: "internal/auth", Files: 1},
+		{Dir: "legacy", Files: 1},
+	}, r.Areas)
+	assert.Equal(t, []Risk{
+		{Kind: "auth or security code", File: "internal/auth/token.go"},
+		{Kind: "database migration", File: "db/migrations/0042_users.sql"},
+	}, r.Risks)
+	assert.Equal(t, []string{"internal/auth/token.go"}, r.Untested, "api.go has its test changed; deleted and non-code files need none")
+
+	assert.Equal(t, "small", r.Size())
+	assert.Equal(t, "high", r.Level())
+	assert.Equal(t, "high risk: small change (11 lines, 6 files); auth or security code, database migration; 1 file untested", r.Summary())
+	md := r.Markdown()
+	assert.Contains(t, md, "## Risk analysis")
+	assert.Contains(t, md, "- **Database migration**: `db/migrations/0042_users.sql`")
+	assert.Contains(t, md, "  - `internal/auth/token.go`")
+
+	assert.Equal(t, "low", AnalyzeRisk("").Level())
+}
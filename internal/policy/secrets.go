@@ -0,0 +1,98 @@
+package policy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SecretFinding is a line added by a change that looks like a credential.
+// The matched text itself is never kept.
+type SecretFinding struct {
+	File string
+	Line int
+	Rule string
+}
+
+func (f SecretFinding) String() string {
+	return fmt.Sprintf("%s:%d: %s", f.File, f.Line, f.Rule)
+}
+
+// secretRules are credential formats distinctive enough to block on. Generic
+// high-entropy strings are deliberately not matched.
+var secretRules = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{"AWS access key ID", regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA)[0-9A-Z]{16}\b`)},
+	{"AWS secret access key", regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+]{40}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{60,})\b`)},
+	{"Azure DevOps PAT", regexp.MustCompile(`\b[A-Za-z0-9]{52,76}AZDO[A-Za-z0-9]{4}\b`)},
+	{"Azure DevOps PAT", regexp.MustCompile(`(?i)(?:pat|token|password)["']?\s*[:=]\s*["']?[a-z2-7]{52}\b`)},
+	{"Azure storage key", regexp.MustCompile(`(?i)AccountKey=[A-Za-z0-9+/]{86}==`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+}
+
+// ScanSecrets returns the lines added between base and HEAD in the git
+// repository at workDir, limited to paths when any are given, that match a
+// known credential format.
+func ScanSecrets(ctx context.Context, workDir, base string, paths ...string) ([]SecretFinding, error) {
+	args := []string{"diff", "--unified=0", "--no-color", "--no-ext-diff", base, "HEAD"}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s: %w", base, err)
+	}
+	return scanDiff(string(out)), nil
+}
+
+// scanDiff scans the added lines of a unified diff.
+func scanDiff(diff string) []SecretFinding {
+	var findings []SecretFinding
+	var file string
+	line := 0
+	sc := bufio.NewScanner(strings.NewReader(diff))
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		text := sc.Text()
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+		case strings.HasPrefix(text, "@@ "):
+			line = hunkStart(text)
+		case strings.HasPrefix(text, "+"):
+			for _, rule := range secretRules {
+				if rule.re.MatchString(text[1:]) {
+					findings = append(findings, SecretFinding{File: file, Line: line, Rule: rule.name})
+					break
+				}
+			}
+			line++
+		case strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+	return findings
+}
+
+// hunkStart returns the first new-file line number of a "@@ -a,b +c,d @@"
+// hunk header.
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 0
+	}
+	start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+	n, _ := strconv.Atoi(start)
+	return n
+}
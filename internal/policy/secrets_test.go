@@ -0,0 +1,60 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanDiff(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/config/app.env b/config/app.env",
+		"--- a/config/app.env",
+		"+++ b/config/app.env",
+		"@@ -3,0 +4,2 @@",
+		"+REGION=us-east-1",
+		"+AWS_ACCESS_KEY_ID=AKIA" + "IOSFODNN7EXAMPLE",
+		"@@ -10 +12 @@",
+		"-AWS_SECRET_ACCESS_KEY=",
+		"+AWS_SECRET_ACCESS_KEY=wJalrXUtnFEMI/K7MDENG/bPxRfiCY" + "EXAMPLEKEY",
+		"diff --git a/certs/dev.key b/certs/dev.key",
+		"new file mode 100644",
+		"--- /dev/null",
+		"+++ b/certs/dev.key",
+		"@@ -0,0 +1,3 @@",
+		"+-----BEGIN RSA " + "PRIVATE KEY-----",
+		"+MIIEow...",
+		"+-----END RSA PRIVATE KEY-----",
+		"diff --git a/ci.yml b/ci.yml",
+		"--- a/ci.yml",
+		"+++ b/ci.yml",
+		"@@ -1 +1 @@",
+		"-token: gh" + "p_" + strings.Repeat("a", 36),
+		"+token: ${{ secrets.GITHUB_TOKEN }}",
+	}, "\n")
+
+	findings := scanDiff(diff)
+	require.Len(t, findings, 3, "removed lines are not scanned")
+	assert.Equal(t, "config/app.env:5: AWS access key ID", findings[0].String())
+	assert.Equal(t, "config/app.env:12: AWS secret access key", findings[1].String())
+	assert.Equal(t, "certs/dev.key:1: private key", findings[2].String())
+}
+
+func TestScanDiff_Rules(t *testing.T) {
+	tests := map[string]string{
+		"GitHub token":      `GH_TOKEN = "gh` + `p_` + strings.Repeat("A1", 18) + `"`,
+		"Azure DevOps PAT":  `"pat": "` + strings.Repeat("abcd2345", 6) + `abcd"`,
+		"Slack token":       `url: https://hooks?token=xox` + `b-1234567890-abcdef`,
+		"Azure storage key": `DefaultEndpointsProtocol=https;AccountName=x;AccountKey=` + strings.Repeat("A", 86) + `==`,
+	}
+	for rule, line := range tests {
+		findings := scanDiff("+++ b/f\n@@ -0,0 +1 @@\n+" + line)
+		if assert.Len(t, findings, 1, rule) {
+			assert.Equal(t, rule, findings[0].Rule)
+		}
+	}
+
+	assert.Empty(t, scanDiff("+++ b/f\n@@ -0,0 +1,2 @@\n+const keyName = \"AWS_SECRET_ACCESS_KEY\"\n+hash := \"3f786850e387550fdab836ed7e6dc881de23001b\""))
+}
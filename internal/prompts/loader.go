@@ -7,20 +7,24 @@ import (
 	"os"
 	"path/filepath"
 	"text/template"
+
+	"github.com/alanmeadows/otto/internal/config"
 )
 
 //go:embed *.md
 var builtinFS embed.FS
 
-// Load returns the prompt template for the given name.
-// Checks user override at ~/.config/otto/prompts/<name> first.
+// Load returns the prompt template for the given name. Overrides are
+// checked in order — the repo's .otto/prompts/<name>, then
+// ~/.config/otto/prompts/<name> — before the built-in template.
 func Load(name string) (*template.Template, error) {
-	// Check user override
-	configDir, err := os.UserConfigDir()
-	if err == nil {
-		userPath := filepath.Join(configDir, "otto", "prompts", name)
-		if data, err := os.ReadFile(userPath); err == nil {
-			return template.New(name).Parse(string(data))
+	for _, dir := range overrideDirs() {
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			tmpl, err := template.New(name).Parse(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("parsing prompt override %s: %w", filepath.Join(dir, name), err)
+			}
+			return tmpl, nil
 		}
 	}
 
@@ -32,6 +36,19 @@ func Load(name string) (*template.Template, error) {
 	return template.New(name).Parse(string(data))
 }
 
+// overrideDirs returns the directories searched for prompt overrides, most
+// specific first, mirroring the repo-over-user order of config.Load.
+func overrideDirs() []string {
+	var dirs []string
+	if repoRoot := config.RepoRoot(); repoRoot != "" {
+		dirs = append(dirs, filepath.Join(repoRoot, ".otto", "prompts"))
+	}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, "otto", "prompts"))
+	}
+	return dirs
+}
+
 // Execute loads a template and executes it with the given data map.
 func Execute(name string, data map[string]string) (string, error) {
 	tmpl, err := Load(name)
@@ -1,6 +1,9 @@
 package prompts
 
 import (
+"os"
+"os/exec"
+"path/filepath"
 "testing"
 
 "github.com/stretchr/testify/assert"
@@ -8,10 +11,21 @@ import (
 )
 
 var expectedTemplates = []string{
+"batch-change.md",
+"bot-evaluate.md",
+"issue-triage.md",
 "merlinbot-evaluate.md",
+"new-project.md",
 "pr-comment-respond.md",
 "pr-description.md",
+"pr-fix-analysis.md",
+"pr-fix.md",
 "pr-review.md",
+"pr-revert.md",
+"pr-title.md",
+"release-notes.md",
+"repo-checks.md",
+"review-fix.md",
 }
 
 func TestLoadAllTemplates(t *testing.T) {
@@ -90,3 +104,64 @@ result, err := Execute("merlinbot-evaluate.md", data)
 require.NoError(t, err)
 assert.Contains(t, result, "Security issue found")
 }
+
+func TestExecuteBotTemplate(t *testing.T) {
+data := map[string]string{
+"Bot":      "coderabbit",
+"Comments": "THREAD 7 [main.go:3]:\nUnchecked error",
+}
+
+result, err := Execute("bot-evaluate.md", data)
+require.NoError(t, err)
+assert.Contains(t, result, `"coderabbit"`)
+assert.Contains(t, result, "Unchecked error")
+assert.NotContains(t, result, "Pull Request Diff")
+}
+
+func TestExecuteFixTemplates(t *testing.T) {
+result, err := Execute("pr-fix-analysis.md", map[string]string{
+"pr_id":      "42",
+"pr_title":   "Add retry",
+"build_logs": "=== Build: ci ===\nundefined: retry",
+})
+require.NoError(t, err)
+assert.Contains(t, result, `PR #42: "Add retry"`)
+assert.Contains(t, result, "undefined: retry")
+
+result, err = Execute("pr-fix.md", map[string]string{
+"pr_id":     "42",
+"pr_title":  "Add retry",
+"diagnosis": "CLASSIFICATION: COMPILE",
+})
+require.NoError(t, err)
+assert.Contains(t, result, "CLASSIFICATION: COMPILE")
+}
+
+func TestLoadOverrides(t *testing.T) {
+configDir := t.TempDir()
+t.Setenv("XDG_CONFIG_HOME", configDir)
+t.Setenv("HOME", configDir)
+repoDir := t.TempDir()
+require.NoError(t, exec.Command("git", "init", "-q", repoDir).Run())
+t.Chdir(repoDir)
+
+userDir := filepath.Join(configDir, "otto", "prompts")
+require.NoError(t, os.MkdirAll(userDir, 0o755))
+require.NoError(t, os.WriteFile(filepath.Join(userDir, "pr-fix.md"), []byte("user {{.pr_id}}"), 0o644))
+
+result, err := Execute("pr-fix.md", map[string]string{"pr_id": "42"})
+require.NoError(t, err)
+assert.Equal(t, "user 42", result)
+
+repoPrompts := filepath.Join(repoDir, ".otto", "prompts")
+require.NoError(t, os.MkdirAll(repoPrompts, 0o755))
+require.NoError(t, os.WriteFile(filepath.Join(repoPrompts, "pr-fix.md"), []byte("repo {{.pr_id}}"), 0o644))
+
+result, err = Execute("pr-fix.md", map[string]string{"pr_id": "42"})
+require.NoError(t, err)
+assert.Equal(t, "repo 42", result, "the repo override wins over the user one")
+
+require.NoError(t, os.WriteFile(filepath.Join(repoPrompts, "pr-fix.md"), []byte("{{.pr_id"), 0o644))
+_, err = Load("pr-fix.md")
+assert.ErrorContains(t, err, "parsing prompt override")
+}
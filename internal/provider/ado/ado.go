@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -11,10 +12,14 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/alanmeadows/otto/internal/httpcache"
+	"github.com/alanmeadows/otto/internal/logdistill"
 	"github.com/alanmeadows/otto/internal/provider"
 )
 
@@ -33,7 +38,10 @@ type Backend struct {
 	project      string
 	repository   string
 	httpClient   *http.Client
-	baseURL      string // override for testing
+	baseURL      string   // override for testing
+	projectIDs   sync.Map // "org/project" -> project GUID, for policy lookups
+	instance     string   // name of a scoped instance; empty for "ado"
+	checks       provider.CheckFilter
 }
 
 // NewBackend creates a new ADO backend for the given organization and project.
@@ -42,28 +50,83 @@ func NewBackend(organization, project string, auth *AuthProvider) *Backend {
 		auth:         auth,
 		organization: organization,
 		project:      project,
-		httpClient:   &http.Client{Timeout: 120 * time.Second},
+		httpClient:   &http.Client{Timeout: 120 * time.Second, Transport: httpcache.New(nil)},
 	}
 }
 
+// SetCheckFilter restricts the builds GetPipelineStatus reports. Blocking
+// build policies on the PR's target branch mark the required builds.
+func (b *Backend) SetCheckFilter(f provider.CheckFilter) {
+	b.checks = f
+}
+
+// SetBaseURL overrides the ADO REST API root (default https://dev.azure.com),
+// e.g. to point at `otto mock-provider`.
+func (b *Backend) SetBaseURL(baseURL string) {
+	b.baseURL = strings.TrimSuffix(baseURL, "/")
+}
+
+// SetTransport sends the backend's requests through rt, e.g. to record or
+// replay them. rt replaces the default transport and its conditional
+// request cache.
+func (b *Backend) SetTransport(rt http.RoundTripper) {
+	b.httpClient.Transport = rt
+}
+
 // SetRepository sets the default repository name for API calls.
 func (b *Backend) SetRepository(repo string) {
 	b.repository = repo
 }
 
-// Name returns "ado".
+// SetInstance names the backend and scopes it to its organization (and
+// project, if set), so several ADO backends, each with its own
+// credentials, can sit side by side in one registry.
+func (b *Backend) SetInstance(name string) {
+	b.instance = name
+}
+
+// Name returns "ado", or the instance name of a scoped backend.
 func (b *Backend) Name() string {
+	if b.instance != "" {
+		return b.instance
+	}
 	return "ado"
 }
 
-// MatchesURL returns true if the URL belongs to Azure DevOps.
+// MatchesURL returns true if the URL belongs to Azure DevOps and, for a
+// scoped instance, to its organization and project.
 func (b *Backend) MatchesURL(rawURL string) bool {
 	u, err := url.Parse(rawURL)
 	if err != nil {
 		return false
 	}
 	host := strings.ToLower(u.Hostname())
-	return strings.HasSuffix(host, ".visualstudio.com") || host == "dev.azure.com"
+	if !strings.HasSuffix(host, ".visualstudio.com") && host != "dev.azure.com" {
+		return false
+	}
+	if b.instance == "" {
+		return true
+	}
+	org, project := urlScope(u)
+	return strings.EqualFold(org, b.organization) && (b.project == "" || strings.EqualFold(project, b.project))
+}
+
+// urlScope returns the organization and project an ADO URL points into.
+func urlScope(u *url.URL) (org, project string) {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	host := strings.ToLower(u.Hostname())
+	if strings.HasSuffix(host, ".visualstudio.com") {
+		org = strings.TrimSuffix(host, ".visualstudio.com")
+		if len(parts) > 0 && strings.EqualFold(parts[0], "DefaultCollection") {
+			parts = parts[1:]
+		}
+	} else {
+		org, parts = parts[0], parts[1:]
+	}
+	if len(parts) > 0 {
+		project = parts[0]
+	}
+	return org, project
 }
 
 // GetPR retrieves pull request information by ID or URL.
@@ -112,6 +175,13 @@ func (b *Backend) GetPR(ctx context.Context, id string) (*provider.PRInfo, error
 			org, project, adoPR.Repository.Name, adoPR.PullRequestID)
 	}
 
+	// Until the PR completes, the last merge commit is the trial merge
+	// its builds run on.
+	var mergeCommit string
+	if adoPR.Status == "completed" {
+		mergeCommit = adoPR.LastMergeCommit.CommitID
+	}
+
 	return &provider.PRInfo{
 		ID:           strconv.Itoa(adoPR.PullRequestID),
 		Title:        adoPR.Title,
@@ -125,6 +195,10 @@ func (b *Backend) GetPR(ctx context.Context, id string) (*provider.PRInfo, error
 		RepoID:       adoPR.Repository.Name,
 		Project:      project,
 		Organization: org,
+		IsDraft:      adoPR.IsDraft,
+		Fork:         adoPR.ForkSource != nil,
+		HeadCommit:   adoPR.LastMergeSourceCommit.CommitID,
+		MergeCommit:  mergeCommit,
 	}, nil
 }
 
@@ -170,16 +244,38 @@ func (b *Backend) GetPipelineStatus(ctx context.Context, pr *provider.PRInfo) (*
 		Builds: make([]provider.BuildInfo, 0, len(latestBuilds)),
 	}
 
+	// Branch policies are reported alongside builds; failing to read them
+	// must not hide the build state.
+	policies, requiredBuilds, err := b.getPolicyChecks(ctx, org, project, pr.ID)
+	if err != nil {
+		if errors.Is(err, ErrAuthExpired) {
+			return nil, err
+		}
+		slog.Warn("failed to get branch policy evaluations", "prID", pr.ID, "error", err)
+	}
+	status.Policies = policies
+
+	// Without the policies, which builds are required is unknown, so
+	// none are dropped for being optional.
+	filter := b.checks
+	if requiredBuilds == nil {
+		filter.RequiredOnly = false
+	}
+
 	hasCompleted := false
 	allSucceeded := true
 
 	for _, build := range latestBuilds {
 		bi := provider.BuildInfo{
-			ID:     strconv.Itoa(build.ID),
-			Name:   build.Definition.Name,
-			Status: build.Status,
-			Result: build.Result,
-			URL:    build.Links.Web.Href,
+			ID:       strconv.Itoa(build.ID),
+			Name:     build.Definition.Name,
+			Status:   build.Status,
+			Result:   build.Result,
+			URL:      build.Links.Web.Href,
+			Required: requiredBuilds[build.Definition.ID],
+		}
+		if !filter.Keep(bi) {
+			continue
 		}
 		status.Builds = append(status.Builds, bi)
 
@@ -208,12 +304,16 @@ func (b *Backend) GetPipelineStatus(ctx context.Context, pr *provider.PRInfo) (*
 	}
 
 	// Only report "succeeded" when every build has explicitly passed.
-	if len(latestBuilds) > 0 && allSucceeded && hasCompleted {
+	if len(status.Builds) > 0 && allSucceeded && hasCompleted {
 		status.State = "succeeded"
 	}
 
-	if len(latestBuilds) == 0 {
+	switch {
+	case len(latestBuilds) == 0:
 		status.State = "pending"
+	case len(status.Builds) == 0:
+		// Every build was filtered out; none stand in the way.
+		status.State = "succeeded"
 	}
 
 	return status, nil
@@ -250,25 +350,35 @@ func (b *Backend) GetComments(ctx context.Context, pr *provider.PRInfo) ([]provi
 		isResolved := isThreadResolved(thread.Status)
 
 		var filePath string
-		var line int
+		var line, iteration, changeTrackingID int
 		if thread.ThreadContext != nil {
 			filePath = thread.ThreadContext.FilePath
 			if thread.ThreadContext.RightFileStart != nil {
 				line = thread.ThreadContext.RightFileStart.Line
 			}
 		}
+		if tc := thread.PRThreadContext; tc != nil {
+			changeTrackingID = tc.ChangeTrackingID
+			if tc.IterationContext != nil {
+				// The right-hand side of the compared pair is the code
+				// the reviewer was looking at.
+				iteration = tc.IterationContext.SecondComparingIteration
+			}
+		}
 
 		for _, c := range thread.Comments {
 			comments = append(comments, provider.Comment{
-				ID:          strconv.Itoa(c.ID),
-				ThreadID:    strconv.Itoa(thread.ID),
-				Author:      c.Author.DisplayName,
-				Body:        c.Content,
-				CommentType: c.CommentType,
-				IsResolved:  isResolved,
-				FilePath:    filePath,
-				Line:        line,
-				CreatedAt:   c.PublishedDate,
+				ID:               strconv.Itoa(c.ID),
+				ThreadID:         strconv.Itoa(thread.ID),
+				Author:           c.Author.DisplayName,
+				Body:             c.Content,
+				CommentType:      c.CommentType,
+				IsResolved:       isResolved,
+				FilePath:         filePath,
+				Line:             line,
+				Iteration:        iteration,
+				ChangeTrackingID: changeTrackingID,
+				CreatedAt:        c.PublishedDate,
 			})
 		}
 	}
@@ -522,6 +632,8 @@ func (b *Backend) ResolveComment(ctx context.Context, pr *provider.PRInfo, threa
 }
 
 // GetBuildLogs retrieves and distills build logs for a specific build, focusing on errors.
+// The timeline is live, so a build still in progress reports the tasks that
+// have failed so far.
 func (b *Backend) GetBuildLogs(ctx context.Context, pr *provider.PRInfo, buildID string) (string, error) {
 	org := b.resolveOrg(pr)
 	project := b.resolveProject(pr)
@@ -552,7 +664,7 @@ func (b *Backend) GetBuildLogs(ctx context.Context, pr *provider.PRInfo, buildID
 			continue
 		}
 
-		errorSummary.WriteString(fmt.Sprintf("=== Failed Task: %s ===\n", record.Name))
+		errorSummary.WriteString(fmt.Sprintf("%sTask: %s ===\n", provider.FailedLogSection, record.Name))
 
 		// Include any reported issues.
 		for _, issue := range record.Issues {
@@ -583,7 +695,7 @@ func (b *Backend) GetBuildLogs(ctx context.Context, pr *provider.PRInfo, buildID
 		// Step 4: Strip ANSI escape codes.
 		logText := stripANSI(string(logBytes))
 
-		// Step 5: Apply error-anchored truncation.
+		// Step 5: Distill failures and error-anchored context.
 		distilled := extractErrorContext(logText)
 		errorSummary.WriteString(distilled)
 		errorSummary.WriteString("\n")
@@ -602,80 +714,37 @@ func stripANSI(s string) string {
 	return ansiPattern.ReplaceAllString(s, "")
 }
 
-// extractErrorContext extracts context windows around ##[error] markers in build logs.
+// extractErrorContext distills a job log into a summary of recognized
+// failures followed by the log lines around them.
 func extractErrorContext(log string) string {
-	lines := strings.Split(log, "\n")
-	const contextWindow = 5
-
-	var errorIndices []int
-	for i, line := range lines {
-		if strings.Contains(line, "##[error]") {
-			errorIndices = append(errorIndices, i)
-		}
-	}
-
-	if len(errorIndices) == 0 {
-		// No error markers found; return last 50 lines as fallback.
-		start := len(lines) - 50
-		if start < 0 {
-			start = 0
-		}
-		return strings.Join(lines[start:], "\n")
-	}
-
-	// Collect unique lines within context windows around errors.
-	included := make(map[int]bool)
-	for _, idx := range errorIndices {
-		start := idx - contextWindow
-		if start < 0 {
-			start = 0
-		}
-		end := idx + contextWindow + 1
-		if end > len(lines) {
-			end = len(lines)
-		}
-		for i := start; i < end; i++ {
-			included[i] = true
-		}
-	}
-
-	var result strings.Builder
-	prevIncluded := false
-	for i, line := range lines {
-		if included[i] {
-			if !prevIncluded && i > 0 {
-				result.WriteString("...\n")
-			}
-			result.WriteString(line)
-			result.WriteString("\n")
-			prevIncluded = true
-		} else {
-			prevIncluded = false
-		}
-	}
-
-	return result.String()
+	return logdistill.Distill(log).String()
 }
 
 // doRequest makes an authenticated HTTP request to the ADO API.
 // It handles rate limiting with exponential backoff on 429 responses.
 func (b *Backend) doRequest(ctx context.Context, method, path string, body any) (*http.Response, error) {
-	return b.doRequestFull(ctx, method, path, body, "application/json", "application/json")
+	return b.doRequestFull(ctx, method, path, body, "application/json", "application/json", nil)
 }
 
 // doRequestWithAccept makes an authenticated HTTP request with a custom Accept header.
 func (b *Backend) doRequestWithAccept(ctx context.Context, method, path string, body any, accept string) (*http.Response, error) {
-	return b.doRequestFull(ctx, method, path, body, "application/json", accept)
+	return b.doRequestFull(ctx, method, path, body, "application/json", accept, nil)
 }
 
 // doRequestWithContentType makes an authenticated HTTP request with a custom Content-Type.
 func (b *Backend) doRequestWithContentType(ctx context.Context, method, path string, body any, contentType string) (*http.Response, error) {
-	return b.doRequestFull(ctx, method, path, body, contentType, "application/json")
+	return b.doRequestFull(ctx, method, path, body, contentType, "application/json", nil)
+}
+
+// doRequestWithHeader makes an authenticated HTTP request with extra headers.
+func (b *Backend) doRequestWithHeader(ctx context.Context, method, path string, body any, header http.Header) (*http.Response, error) {
+	return b.doRequestFull(ctx, method, path, body, "application/json", "application/json", header)
 }
 
-// doRequestFull makes an authenticated HTTP request with custom Content-Type and Accept headers.
+// doRequestFull makes an authenticated HTTP request with custom Content-Type and Accept headers,
+// plus any extra headers.
 // It handles rate limiting with exponential backoff on 429 responses.
-func (b *Backend) doRequestFull(ctx context.Context, method, path string, body any, contentType, accept string) (*http.Response, error) {
+func (b *Backend) doRequestFull(ctx context.Context, method, path string, body any, contentType, accept string, header http.Header) (*http.Response, error) {
 	baseURL := b.baseURL
 	if baseURL == "" {
 		baseURL = "https://dev.azure.com"
@@ -710,6 +779,9 @@ func (b *Backend) doRequestFull(ctx context.Context, method, path string, body a
 		}
 		req.Header.Set("Authorization", authHeader)
 		req.Header.Set("Accept", accept)
+		for k, v := range header {
+			req.Header[k] = v
+		}
 
 		if body != nil {
 			req.Header.Set("Content-Type", contentType)
@@ -867,6 +939,114 @@ func (b *Backend) RetryBuild(ctx context.Context, pr *provider.PRInfo, buildID s
 	return b.queueFreshBuild(ctx, org, project, buildID, pr)
 }
 
+// CancelBuild asks ADO to cancel a running build. The build reports status
+// "cancelling" until its agents stop, then result "canceled".
+func (b *Backend) CancelBuild(ctx context.Context, pr *provider.PRInfo, buildID string) error {
+	org := b.resolveOrg(pr)
+	project := b.resolveProject(pr)
+	path := fmt.Sprintf("/%s/%s/_apis/build/builds/%s",
+		url.PathEscape(org), url.PathEscape(project), buildID)
+
+	resp, err := b.doRequest(ctx, http.MethodPatch, path, map[string]any{"status": "cancelling"})
+	if err != nil {
+		return fmt.Errorf("failed to cancel build %s: %w", buildID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return b.parseError(resp)
+	}
+	return nil
+}
+
+// CommitAuthor returns the author email of a commit in the PR's repository.
+func (b *Backend) CommitAuthor(ctx context.Context, pr *provider.PRInfo, commit string) (string, error) {
+	path := fmt.Sprintf("/%s/%s/_apis/git/repositories/%s/commits/%s",
+		url.PathEscape(b.resolveOrg(pr)), url.PathEscape(b.resolveProject(pr)), url.PathEscape(b.resolveRepo(pr)), url.PathEscape(commit))
+
+	resp, err := b.doRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit %s: %w", commit, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", b.parseError(resp)
+	}
+
+	var c adoCommit
+	if err := json.NewDecoder(resp.Body).Decode(&c); err != nil {
+		return "", fmt.Errorf("decoding commit %s: %w", commit, err)
+	}
+	return c.Author.Email, nil
+}
+
+// PublishReleaseNotes writes notes to a page of the project's wiki (or
+// notes.Wiki), replacing the page if it exists.
+func (b *Backend) PublishReleaseNotes(ctx context.Context, notes provider.ReleaseNotes) (string, error) {
+	wiki := notes.Wiki
+	if wiki == "" {
+		wiki = b.project + ".wiki"
+	}
+	pagePath := notes.WikiPath
+	if pagePath == "" {
+		pagePath = "/Release Notes/" + notes.Tag
+	}
+	path := fmt.Sprintf("/%s/%s/_apis/wiki/wikis/%s/pages?path=%s",
+		url.PathEscape(b.organization), url.PathEscape(b.project), url.PathEscape(wiki), url.QueryEscape(pagePath))
+
+	// Replacing a page takes its current version, its ETag, in If-Match.
+	resp, err := b.doRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get wiki page %s: %w", pagePath, err)
+	}
+	var header http.Header
+	switch resp.StatusCode {
+	case http.StatusOK:
+		header = http.Header{"If-Match": {resp.Header.Get("ETag")}}
+	case http.StatusNotFound:
+	default:
+		defer resp.Body.Close()
+		return "", b.parseError(resp)
+	}
+	resp.Body.Close()
+
+	resp, err = b.doRequestWithHeader(ctx, http.MethodPut, path, adoWikiPage{Content: notes.Body}, header)
+	if err != nil {
+		return "", fmt.Errorf("failed to write wiki page %s: %w", pagePath, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", b.parseError(resp)
+	}
+
+	var page adoWikiPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return "", fmt.Errorf("decoding wiki page %s: %w", pagePath, err)
+	}
+	return page.RemoteURL, nil
+}
+
+// CreateRepository creates the repository name in the backend's project
+// and returns its clone URL.
+func (b *Backend) CreateRepository(ctx context.Context, name string) (string, error) {
+	path := fmt.Sprintf("/%s/%s/_apis/git/repositories", url.PathEscape(b.organization), url.PathEscape(b.project))
+	resp, err := b.doRequest(ctx, http.MethodPost, path, map[string]string{"name": name})
+	if err != nil {
+		return "", fmt.Errorf("failed to create repository %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", b.parseError(resp)
+	}
+
+	var repo adoRepository
+	if err := json.NewDecoder(resp.Body).Decode(&repo); err != nil {
+		return "", fmt.Errorf("decoding repository %s: %w", name, err)
+	}
+	return repo.RemoteURL, nil
+}
+
 // listBuildArtifacts returns the current artifacts for a build.
 func (b *Backend) listBuildArtifacts(ctx context.Context, org, project, buildID string) ([]adoArtifact, error) {
 	listPath := fmt.Sprintf("/%s/%s/_apis/build/builds/%s/artifacts",
@@ -975,6 +1155,15 @@ func (b *Backend) CreatePR(ctx context.Context, params provider.CreatePRParams)
 		Title:         params.Title,
 		Description:   params.Description,
 	}
+	for _, id := range params.WorkItems {
+		body.WorkItemRefs = append(body.WorkItemRefs, adoResourceRef{ID: id})
+	}
+	if len(params.WorkItems) > 0 && params.ResolveWorkItems {
+		body.CompletionOptions = &adoCompletionOptions{TransitionWorkItems: true}
+	}
+	for _, label := range params.Labels {
+		body.Labels = append(body.Labels, adoLabel{Name: label})
+	}
 
 	resp, err := b.doRequest(ctx, http.MethodPost, path, body)
 	if err != nil {
@@ -1009,6 +1198,9 @@ func (b *Backend) CreatePR(ctx context.Context, params provider.CreatePRParams)
 		RepoID:       adoPR.Repository.Name,
 		Project:      b.project,
 		Organization: b.organization,
+		IsDraft:      adoPR.IsDraft,
+		Fork:         adoPR.ForkSource != nil,
+		WorkItems:    params.WorkItems,
 	}, nil
 }
 
@@ -1064,9 +1256,147 @@ func (b *Backend) FindExistingPR(ctx context.Context, sourceBranch string) (*pro
 		RepoID:       adoPR.Repository.Name,
 		Project:      b.project,
 		Organization: b.organization,
+		IsDraft:      adoPR.IsDraft,
+		Fork:         adoPR.ForkSource != nil,
 	}, nil
 }
 
+// ListPRs returns the active PRs in the backend's project matching filter.
+// "@me" is resolved to the authenticated identity and searched by creator
+// ID; other authors match the creator's unique name or display name.
+func (b *Backend) ListPRs(ctx context.Context, filter provider.PRFilter) ([]*provider.PRInfo, error) {
+	path := fmt.Sprintf("/%s/%s/_apis/git/pullrequests", url.PathEscape(b.organization), url.PathEscape(b.project))
+	if filter.Repo != "" {
+		path = fmt.Sprintf("/%s/%s/_apis/git/repositories/%s/pullrequests",
+			url.PathEscape(b.organization), url.PathEscape(b.project), url.PathEscape(filter.Repo))
+	}
+	query := "?searchCriteria.status=active"
+	author := filter.Author
+	if author == "@me" {
+		identity, err := b.getCurrentUser(ctx, b.organization)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current user: %w", err)
+		}
+		query += "&searchCriteria.creatorId=" + url.QueryEscape(identity.ID)
+		author = ""
+	}
+
+	const pageSize = 100
+	var prs []*provider.PRInfo
+	for skip := 0; ; skip += pageSize {
+		resp, err := b.doRequest(ctx, http.MethodGet, fmt.Sprintf("%s%s&$top=%d&$skip=%d", path, query, pageSize, skip), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list PRs: %w", err)
+		}
+		var prList adoPullRequestList
+		if resp.StatusCode != http.StatusOK {
+			err = b.parseError(resp)
+		} else if decErr := json.NewDecoder(resp.Body).Decode(&prList); decErr != nil {
+			err = fmt.Errorf("failed to decode PR list response: %w", decErr)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, adoPR := range prList.Value {
+			if author != "" && !strings.EqualFold(adoPR.CreatedBy.UniqueName, author) && !strings.EqualFold(adoPR.CreatedBy.DisplayName, author) {
+				continue
+			}
+			if filter.Label != "" && !slices.ContainsFunc(adoPR.Labels, func(l adoLabel) bool { return strings.EqualFold(l.Name, filter.Label) }) {
+				continue
+			}
+			webURL := adoPR.Links.Web.Href
+			if webURL == "" {
+				webURL = fmt.Sprintf("https://dev.azure.com/%s/%s/_git/%s/pullrequest/%d",
+					b.organization, b.project, adoPR.Repository.Name, adoPR.PullRequestID)
+			}
+			pr := &provider.PRInfo{
+				ID:           strconv.Itoa(adoPR.PullRequestID),
+				Title:        adoPR.Title,
+				Description:  adoPR.Description,
+				Status:       adoPR.Status,
+				MergeStatus:  adoPR.MergeStatus,
+				SourceBranch: adoPR.SourceRefName,
+				TargetBranch: adoPR.TargetRefName,
+				Author:       adoPR.CreatedBy.DisplayName,
+				URL:          webURL,
+				RepoID:       adoPR.Repository.Name,
+				Project:      b.project,
+				Organization: b.organization,
+				IsDraft:      adoPR.IsDraft,
+				Fork:         adoPR.ForkSource != nil,
+			}
+			if filter.Matches(pr) {
+				prs = append(prs, pr)
+			}
+		}
+		if len(prList.Value) < pageSize {
+			return prs, nil
+		}
+	}
+}
+
+// MarkReady publishes a draft PR by clearing its isDraft flag.
+func (b *Backend) MarkReady(ctx context.Context, pr *provider.PRInfo) error {
+	path := fmt.Sprintf("/%s/%s/_apis/git/repositories/%s/pullrequests/%s",
+		url.PathEscape(b.resolveOrg(pr)), url.PathEscape(b.resolveProject(pr)), url.PathEscape(b.resolveRepo(pr)), pr.ID)
+
+	resp, err := b.doRequest(ctx, http.MethodPatch, path, map[string]any{"isDraft": false})
+	if err != nil {
+		return fmt.Errorf("failed to publish draft PR: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return b.parseError(resp)
+	}
+	return nil
+}
+
+// Reviewer votes, as ADO records them on a PR's reviewers.
+const (
+	voteApproved      = 10
+	voteWaitForAuthor = -5
+)
+
+// SubmitReview posts body as a PR comment and, for an approve or
+// request-changes verdict, casts the authenticated user's reviewer vote
+// (approved, or waiting for author).
+func (b *Backend) SubmitReview(ctx context.Context, pr *provider.PRInfo, verdict provider.ReviewVerdict, body string) error {
+	if body != "" {
+		if err := b.PostComment(ctx, pr, body); err != nil {
+			return err
+		}
+	}
+
+	var vote int
+	switch verdict {
+	case provider.ReviewApprove:
+		vote = voteApproved
+	case provider.ReviewRequestChanges:
+		vote = voteWaitForAuthor
+	default:
+		return nil
+	}
+
+	user, err := b.getCurrentUser(ctx, b.resolveOrg(pr))
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("%s/pullrequests/%s/reviewers/%s", b.repoAPIPath(pr), pr.ID, url.PathEscape(user.ID))
+	resp, err := b.doRequest(ctx, http.MethodPut, path, map[string]any{"vote": vote})
+	if err != nil {
+		return fmt.Errorf("failed to vote on PR: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return b.parseError(resp)
+	}
+	return nil
+}
+
 // ensureRefPrefix adds "refs/heads/" prefix if not already present.
 func ensureRefPrefix(branch string) string {
 	if strings.HasPrefix(branch, "refs/") {
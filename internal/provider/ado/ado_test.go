@@ -53,6 +53,33 @@ func TestMatchesURL(t *testing.T) {
 	}
 }
 
+func TestMatchesURL_Instance(t *testing.T) {
+	b := NewBackend("contoso", "Platform", NewAuthProvider("pat"))
+	b.SetInstance("ado-contoso")
+	assert.Equal(t, "ado-contoso", b.Name())
+
+	tests := []struct {
+		url     string
+		matches bool
+	}{
+		{"https://dev.azure.com/contoso/Platform/_git/repo/pullrequest/123", true},
+		{"https://dev.azure.com/Contoso/platform/_git/repo/pullrequest/123", true},
+		{"https://contoso.visualstudio.com/Platform/_git/repo/pullrequest/123", true},
+		{"https://contoso.visualstudio.com/DefaultCollection/Platform/_git/repo/pullrequest/123", true},
+		{"https://dev.azure.com/contoso/Other/_git/repo/pullrequest/123", false},
+		{"https://dev.azure.com/fabrikam/Platform/_git/repo/pullrequest/123", false},
+		{"https://github.com/contoso/Platform/pull/123", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.matches, b.MatchesURL(tt.url))
+		})
+	}
+
+	b.project = ""
+	assert.True(t, b.MatchesURL("https://dev.azure.com/contoso/Other/_git/repo/pullrequest/123"))
+}
+
 func TestGetPR(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !strings.Contains(r.URL.Path, "/pullrequests/1234") {
@@ -72,6 +99,8 @@ func TestGetPR(t *testing.T) {
 		}
 		resp.Repository.Name = "testrepo"
 		resp.Repository.ID = "repo-id"
+		resp.LastMergeSourceCommit.CommitID = "f00dfeed"
+		resp.LastMergeCommit.CommitID = "trialmerge"
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(resp)
@@ -91,6 +120,8 @@ func TestGetPR(t *testing.T) {
 		assert.Equal(t, "refs/heads/main", pr.TargetBranch)
 		assert.Equal(t, "Test User", pr.Author)
 		assert.Equal(t, "testrepo", pr.RepoID)
+		assert.Equal(t, "f00dfeed", pr.HeadCommit)
+		assert.Empty(t, pr.MergeCommit, "an active PR's last merge commit is a trial merge")
 	})
 
 	t.Run("invalid ID", func(t *testing.T) {
@@ -100,6 +131,37 @@ func TestGetPR(t *testing.T) {
 	})
 }
 
+func TestListPRs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/testorg/_apis/connectiondata":
+			fmt.Fprint(w, `{"authenticatedUser":{"id":"me-id"}}`)
+		case "/testorg/testproject/_apis/git/pullrequests":
+			assert.Equal(t, "active", r.URL.Query().Get("searchCriteria.status"))
+			assert.Equal(t, "me-id", r.URL.Query().Get("searchCriteria.creatorId"))
+			list := adoPullRequestList{Value: []adoPullRequest{
+				{PullRequestID: 1, Title: "mine", SourceRefName: "refs/heads/users/me/a"},
+				{PullRequestID: 2, Title: "other", SourceRefName: "refs/heads/feature/b"},
+			}}
+			for i := range list.Value {
+				list.Value[i].Repository.Name = "testrepo"
+			}
+			json.NewEncoder(w).Encode(list)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	b := newTestBackend(t, server)
+	prs, err := b.ListPRs(context.Background(), provider.PRFilter{Author: "@me", BranchPrefix: "users/me/"})
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, "1", prs[0].ID)
+	assert.Equal(t, "https://dev.azure.com/testorg/testproject/_git/testrepo/pullrequest/1", prs[0].URL)
+}
+
 func TestGetPRFromURL(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Verify the URL contains the right org/project/repo/PR path.
@@ -179,6 +241,84 @@ func TestGetPipelineStatus(t *testing.T) {
 	assert.Equal(t, "failed", status.Builds[1].Result)
 }
 
+func TestGetPipelineStatus_Policies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/testorg/testproject/_apis/build/builds":
+			fmt.Fprint(w, `{"value":[{"id":1,"status":"completed","result":"succeeded","definition":{"name":"CI"}}]}`)
+		case "/testorg/_apis/projects/testproject":
+			fmt.Fprint(w, `{"id":"proj-guid"}`)
+		case "/testorg/testproject/_apis/policy/evaluations":
+			assert.Equal(t, "vstfs:///CodeReview/CodeReviewId/proj-guid/1234", r.URL.Query().Get("artifactId"))
+			fmt.Fprint(w, `{"value":[
+				{"status":"approved","configuration":{"isEnabled":true,"isBlocking":true,"type":{"displayName":"Build"}}},
+				{"status":"rejected","configuration":{"isEnabled":true,"isBlocking":true,"type":{"displayName":"Work item linking"}}},
+				{"status":"running","configuration":{"isEnabled":true,"isBlocking":true,"type":{"displayName":"Minimum number of reviewers"}}},
+				{"status":"approved","configuration":{"isEnabled":true,"isBlocking":true,"type":{"displayName":"Comment requirements"}}},
+				{"status":"rejected","configuration":{"isEnabled":true,"isBlocking":false,"type":{"displayName":"Required reviewers"}}},
+				{"status":"notApplicable","configuration":{"isEnabled":true,"isBlocking":true,"type":{"displayName":"Required reviewers"}}}]}`)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	b := newTestBackend(t, server)
+	status, err := b.GetPipelineStatus(context.Background(), &provider.PRInfo{ID: "1234"})
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", status.State, "policies do not change the build state")
+	assert.Len(t, status.Policies, 4)
+	assert.Equal(t, []string{"work item link missing", "required reviewers"}, status.PendingPolicies())
+}
+
+func TestGetPipelineStatus_CheckFilter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/testorg/testproject/_apis/build/builds":
+			fmt.Fprint(w, `{"value":[
+				{"id":3,"status":"completed","result":"failed","definition":{"id":30,"name":"Nightly Perf"}},
+				{"id":2,"status":"completed","result":"failed","definition":{"id":20,"name":"Optional Lint"}},
+				{"id":1,"status":"completed","result":"succeeded","definition":{"id":10,"name":"CI"}}]}`)
+		case "/testorg/_apis/projects/testproject":
+			fmt.Fprint(w, `{"id":"proj-guid"}`)
+		case "/testorg/testproject/_apis/policy/evaluations":
+			fmt.Fprint(w, `{"value":[
+				{"status":"approved","configuration":{"isEnabled":true,"isBlocking":true,"type":{"displayName":"Build"},"settings":{"buildDefinitionId":10}}},
+				{"status":"rejected","configuration":{"isEnabled":true,"isBlocking":true,"type":{"displayName":"Build"},"settings":{"buildDefinitionId":30}}},
+				{"status":"rejected","configuration":{"isEnabled":true,"isBlocking":false,"type":{"displayName":"Build"},"settings":{"buildDefinitionId":20}}}]}`)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	b := newTestBackend(t, server)
+	pr := &provider.PRInfo{ID: "1234"}
+
+	b.SetCheckFilter(provider.CheckFilter{RequiredOnly: true})
+	status, err := b.GetPipelineStatus(context.Background(), pr)
+	require.NoError(t, err)
+	assert.Equal(t, "failed", status.State)
+	require.Len(t, status.Builds, 2)
+	assert.Equal(t, "Nightly Perf", status.Builds[0].Name)
+	assert.True(t, status.Builds[0].Required)
+
+	b.SetCheckFilter(provider.CheckFilter{RequiredOnly: true, Exclude: []string{"Nightly*"}})
+	status, err = b.GetPipelineStatus(context.Background(), pr)
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", status.State)
+	require.Len(t, status.Builds, 1)
+	assert.Equal(t, "CI", status.Builds[0].Name)
+
+	b.SetCheckFilter(provider.CheckFilter{Include: []string{"Docs"}})
+	status, err = b.GetPipelineStatus(context.Background(), pr)
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", status.State, "no builds left to wait on")
+	assert.Empty(t, status.Builds)
+}
+
 func TestGetComments(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resp := adoThreadList{
@@ -203,6 +343,10 @@ func TestGetComments(t *testing.T) {
 						RightFileStart: &adoLineOffset{Line: 42, Offset: 1},
 						RightFileEnd:   &adoLineOffset{Line: 42, Offset: 1},
 					},
+					PRThreadContext: &adoPRThreadContext{
+						ChangeTrackingID: 7,
+						IterationContext: &adoIterationContext{FirstComparingIteration: 1, SecondComparingIteration: 3},
+					},
 					Comments: []adoComment{
 						{
 							ID:          1,
@@ -254,6 +398,9 @@ func TestGetComments(t *testing.T) {
 	assert.True(t, comments[1].IsResolved)
 	assert.Equal(t, "/src/main.go", comments[1].FilePath)
 	assert.Equal(t, 42, comments[1].Line)
+	assert.Equal(t, 3, comments[1].Iteration)
+	assert.Equal(t, 7, comments[1].ChangeTrackingID)
+	assert.Equal(t, 0, comments[0].Iteration)
 
 	// System comment (active, no longer filtered).
 	assert.Equal(t, "System message", comments[2].Body)
@@ -387,6 +534,83 @@ func TestResolveComment(t *testing.T) {
 	}
 }
 
+func TestCancelBuild(t *testing.T) {
+	var receivedBody map[string]any
+	var receivedPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			http.Error(w, "expected PATCH", http.StatusMethodNotAllowed)
+			return
+		}
+		receivedPath = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&receivedBody)
+		w.Write([]byte(`{"id": 42, "status": "cancelling"}`))
+	}))
+	defer server.Close()
+
+	b := newTestBackend(t, server)
+	require.NoError(t, b.CancelBuild(context.Background(), &provider.PRInfo{ID: "1"}, "42"))
+	assert.Equal(t, "/testorg/testproject/_apis/build/builds/42", receivedPath)
+	assert.Equal(t, "cancelling", receivedBody["status"])
+}
+
+func TestCommitAuthor(t *testing.T) {
+	var receivedPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedPath = r.URL.Path
+		w.Write([]byte(`{"commitId": "f00dfeed", "author": {"name": "Dev", "email": "dev@example.com"}}`))
+	}))
+	defer server.Close()
+
+	b := newTestBackend(t, server)
+	email, err := b.CommitAuthor(context.Background(), &provider.PRInfo{ID: "1"}, "f00dfeed")
+	require.NoError(t, err)
+	assert.Equal(t, "dev@example.com", email)
+	assert.Equal(t, "/testorg/testproject/_apis/git/repositories/testrepo/commits/f00dfeed", receivedPath)
+}
+
+func TestPublishReleaseNotes(t *testing.T) {
+	var ifMatch, content, query string
+	exists := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/testorg/testproject/_apis/wiki/wikis/testproject.wiki/pages", r.URL.Path)
+		query = r.URL.Query().Get("path")
+		switch r.Method {
+		case http.MethodGet:
+			if !exists {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"message": "page not found"}`))
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(`{"path": "/Release Notes/v1.3.0", "content": "old"}`))
+		case http.MethodPut:
+			ifMatch = r.Header.Get("If-Match")
+			var page adoWikiPage
+			json.NewDecoder(r.Body).Decode(&page)
+			content = page.Content
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"path": "/Release Notes/v1.3.0", "remoteUrl": "https://dev.azure.com/testorg/testproject/_wiki/wikis/testproject.wiki/1"}`))
+		}
+	}))
+	defer server.Close()
+
+	b := newTestBackend(t, server)
+	url, err := b.PublishReleaseNotes(context.Background(), provider.ReleaseNotes{Tag: "v1.3.0", Body: "## Features"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://dev.azure.com/testorg/testproject/_wiki/wikis/testproject.wiki/1", url)
+	assert.Equal(t, "/Release Notes/v1.3.0", query)
+	assert.Equal(t, "## Features", content)
+	assert.Empty(t, ifMatch, "a new page is created without a version")
+
+	// An existing page is replaced at its current version.
+	exists = true
+	_, err = b.PublishReleaseNotes(context.Background(), provider.ReleaseNotes{Tag: "v1.3.0", Body: "## Fixes"})
+	require.NoError(t, err)
+	assert.Equal(t, `"v1"`, ifMatch)
+	assert.Equal(t, "## Fixes", content)
+}
+
 func TestGetBuildLogs(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch {
@@ -693,6 +917,161 @@ func TestWorkflowAddressBot(t *testing.T) {
 	// This currently just logs; no error expected.
 }
 
+func TestMarkReady(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/testorg/testproject/_apis/git/repositories/testrepo/pullrequests/1234", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		fmt.Fprint(w, `{"pullRequestId":1234,"isDraft":false}`)
+	}))
+	defer server.Close()
+
+	b := newTestBackend(t, server)
+	err := b.MarkReady(context.Background(), &provider.PRInfo{ID: "1234", RepoID: "testrepo"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"isDraft": false}, body)
+}
+
+func TestCreateRepository(t *testing.T) {
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/testorg/testproject/_apis/git/repositories", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"r1","name":"billing","remoteUrl":"https://dev.azure.com/testorg/testproject/_git/billing"}`)
+	}))
+	defer server.Close()
+
+	b := newTestBackend(t, server)
+	remote, err := b.CreateRepository(context.Background(), "billing")
+	require.NoError(t, err)
+	assert.Equal(t, "https://dev.azure.com/testorg/testproject/_git/billing", remote)
+	assert.Equal(t, "billing", body["name"])
+}
+
+func TestCreatePR_LinksWorkItems(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/testorg/testproject/_apis/git/repositories/testrepo/pullrequests", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		fmt.Fprint(w, `{"pullRequestId":77,"title":"Add retries","status":"active","repository":{"name":"testrepo"}}`)
+	}))
+	defer server.Close()
+
+	b := newTestBackend(t, server)
+	pr, err := b.CreatePR(context.Background(), provider.CreatePRParams{
+		Title:            "Add retries",
+		SourceBranch:     "users/me/1234-retries",
+		TargetBranch:     "main",
+		WorkItems:        []string{"1234", "5678"},
+		ResolveWorkItems: true,
+		Labels:           []string{"area/api"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1234", "5678"}, pr.WorkItems)
+	assert.Equal(t, []any{map[string]any{"id": "1234"}, map[string]any{"id": "5678"}}, body["workItemRefs"])
+	assert.Equal(t, map[string]any{"transitionWorkItems": true}, body["completionOptions"])
+	assert.Equal(t, []any{map[string]any{"name": "area/api"}}, body["labels"])
+}
+
+func TestSubmitReview(t *testing.T) {
+	var comments int
+	var vote map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/testorg/_apis/connectiondata":
+			fmt.Fprint(w, `{"authenticatedUser":{"id":"user-1","displayName":"Otto"}}`)
+		case "/testorg/testproject/_apis/git/repositories/testrepo/pullrequests/1234/threads":
+			comments++
+			fmt.Fprint(w, `{"id":1}`)
+		case "/testorg/testproject/_apis/git/repositories/testrepo/pullrequests/1234/reviewers/user-1":
+			assert.Equal(t, http.MethodPut, r.Method)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&vote))
+			fmt.Fprint(w, `{"id":"user-1","vote":-5}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	b := newTestBackend(t, server)
+	pr := &provider.PRInfo{ID: "1234", RepoID: "testrepo"}
+	require.NoError(t, b.SubmitReview(context.Background(), pr, provider.ReviewRequestChanges, "Two issues found."))
+	assert.Equal(t, 1, comments)
+	assert.Equal(t, map[string]any{"vote": float64(-5)}, vote)
+
+	// A plain comment verdict casts no vote.
+	vote = nil
+	require.NoError(t, b.SubmitReview(context.Background(), pr, provider.ReviewComment, "Looks fine."))
+	assert.Equal(t, 2, comments)
+	assert.Nil(t, vote)
+}
+
+func TestGetFileAtIteration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/testorg/testproject/_apis/git/repositories/testrepo/pullrequests/1234/iterations/2":
+			fmt.Fprint(w, `{"id":2,"sourceRefCommit":{"commitId":"abc123"}}`)
+		case "/testorg/testproject/_apis/git/repositories/testrepo/items":
+			assert.Equal(t, "/src/main.go", r.URL.Query().Get("path"))
+			assert.Equal(t, "abc123", r.URL.Query().Get("versionDescriptor.version"))
+			assert.Equal(t, "commit", r.URL.Query().Get("versionDescriptor.versionType"))
+			assert.Equal(t, "text/plain", r.Header.Get("Accept"))
+			fmt.Fprint(w, "package main\n")
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	b := newTestBackend(t, server)
+	content, err := b.GetFileAtIteration(context.Background(), &provider.PRInfo{ID: "1234", RepoID: "testrepo"}, 2, "src/main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", content)
+}
+
+func TestGetDiff(t *testing.T) {
+	repoPath := "/testorg/testproject/_apis/git/repositories/testrepo"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case repoPath + "/pullrequests/1234/iterations":
+			fmt.Fprint(w, `{"value":[{"id":1},{"id":2,"sourceRefCommit":{"commitId":"head"},"commonRefCommit":{"commitId":"base"}}]}`)
+		case repoPath + "/pullrequests/1234/iterations/2/changes":
+			assert.Equal(t, "0", r.URL.Query().Get("$compareTo"))
+			fmt.Fprint(w, `{"changeEntries":[
+				{"changeType":"edit","item":{"path":"/main.go","gitObjectType":"blob"}},
+				{"changeType":"add","item":{"path":"/new.go","gitObjectType":"blob"}},
+				{"changeType":"add","item":{"path":"/pkg","isFolder":true}}]}`)
+		case repoPath + "/items":
+			files := map[string]string{
+				"/main.go@base": "package main\n\nfunc a() {}\n",
+				"/main.go@head": "package main\n\nfunc b() {}\n",
+				"/new.go@head":  "package main\n",
+			}
+			content, ok := files[r.URL.Query().Get("path")+"@"+r.URL.Query().Get("versionDescriptor.version")]
+			if !ok {
+				t.Errorf("unexpected item request: %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, content)
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	b := newTestBackend(t, server)
+	diff, err := b.GetDiff(context.Background(), &provider.PRInfo{ID: "1234", RepoID: "testrepo"})
+	require.NoError(t, err)
+	assert.Equal(t, "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,3 +1,3 @@\n package main\n \n-func a() {}\n+func b() {}\n"+
+		"diff --git a/new.go b/new.go\n--- /dev/null\n+++ b/new.go\n@@ -0,0 +1,1 @@\n+package main\n", diff)
+}
+
 func TestRetryBuild_AlwaysQueuesFreshBuild(t *testing.T) {
 	var freshBuildQueued bool
 	var freshBuildBody map[string]any
@@ -729,3 +1108,26 @@ func TestRetryBuild_AlwaysQueuesFreshBuild(t *testing.T) {
 	assert.True(t, freshBuildQueued, "should always queue a fresh build, never retry in-place")
 	assert.Equal(t, "abc123def456", freshBuildBody["sourceVersion"], "fresh build should propagate sourceVersion")
 }
+
+func TestCheckAuth(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/testorg/_apis/connectiondata", r.URL.Path)
+		w.WriteHeader(status)
+		w.Write([]byte(`{"authenticatedUser":{"id":"u1"}}`))
+	}))
+	defer server.Close()
+	b := newTestBackend(t, server)
+
+	_, err := b.CheckAuth(t.Context())
+	require.NoError(t, err)
+
+	status = http.StatusUnauthorized
+	_, err = b.CheckAuth(t.Context())
+	assert.ErrorIs(t, err, provider.ErrAuthFailed)
+
+	status = http.StatusInternalServerError
+	_, err = b.CheckAuth(t.Context())
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, provider.ErrAuthFailed)
+}
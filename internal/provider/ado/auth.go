@@ -17,15 +17,19 @@ const adoResourceID = "499b84ac-1321-427f-aa17-267ca6975798"
 
 // AuthProvider provides authentication tokens for ADO API calls.
 // It supports two strategies:
-//  1. Entra ID (Azure AD) tokens obtained via the Azure CLI
+//  1. Entra ID (Azure AD) tokens obtained via the Azure CLI, or for a
+//     service principal or managed identity (see NewCredentialAuthProvider)
 //  2. Personal Access Token (PAT) as a fallback
 //
 // Tokens are cached and refreshed automatically when expired.
 type AuthProvider struct {
 	pat         string // from config or OTTO_ADO_PAT env
+	patOnly     bool   // never ask the Azure CLI
 	cachedToken string // Entra token
 	tokenExpiry time.Time
 	mu          sync.Mutex
+	// tokenSource acquires Entra tokens in place of the Azure CLI.
+	tokenSource func(ctx context.Context) (string, time.Time, error)
 	// execCommand is a hook for testing — defaults to exec.CommandContext.
 	execCommand func(ctx context.Context, name string, args ...string) *exec.Cmd
 }
@@ -42,6 +46,12 @@ func NewAuthProvider(pat string) *AuthProvider {
 	}
 }
 
+// NewPATAuthProvider creates an AuthProvider that always uses pat and never
+// runs the Azure CLI, e.g. when replaying recorded traffic offline.
+func NewPATAuthProvider(pat string) *AuthProvider {
+	return &AuthProvider{pat: pat, patOnly: true}
+}
+
 // InvalidateToken clears the cached Entra ID token, forcing a fresh
 // acquisition on the next GetAuthHeader call. Used when ADO returns
 // HTTP 203 (auth redirect), indicating the token has expired.
@@ -65,13 +75,21 @@ func (a *AuthProvider) GetAuthHeader(ctx context.Context) (string, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	if a.patOnly {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(":"+a.pat)), nil
+	}
+
 	// Return cached Entra token if still valid (with 5-minute buffer).
 	if a.cachedToken != "" && time.Now().Before(a.tokenExpiry.Add(-5*time.Minute)) {
 		return "Bearer " + a.cachedToken, nil
 	}
 
-	// Try Entra ID token via Azure CLI.
-	token, expiry, err := a.getEntraToken(ctx)
+	// Try Entra ID token via the configured credential or Azure CLI.
+	getToken := a.getEntraToken
+	if a.tokenSource != nil {
+		getToken = a.tokenSource
+	}
+	token, expiry, err := getToken(ctx)
 	if err == nil {
 		a.cachedToken = token
 		a.tokenExpiry = expiry
@@ -0,0 +1,251 @@
+package ado
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Credential is an Entra ID identity that acquires ADO tokens directly,
+// for hosts without an interactive `az login`: a service principal with a
+// client secret or certificate, or the host's managed identity.
+type Credential struct {
+	TenantID        string
+	ClientID        string // service principal, or a user-assigned managed identity
+	ClientSecret    string
+	CertificatePath string // PEM file holding the certificate and its private key
+	ManagedIdentity bool
+}
+
+// Default token endpoints.
+const (
+	defaultAuthorityURL = "https://login.microsoftonline.com"
+	defaultIMDSURL      = "http://169.254.169.254/metadata/identity/oauth2/token"
+)
+
+// credentialSource acquires tokens for a Credential.
+type credentialSource struct {
+	cred         Credential
+	cert         *x509.Certificate
+	key          *rsa.PrivateKey
+	authorityURL string
+	imdsURL      string
+	httpClient   *http.Client
+}
+
+// NewCredentialAuthProvider creates an AuthProvider that acquires Entra ID
+// tokens for cred instead of asking the Azure CLI, falling back to pat (or
+// OTTO_ADO_PAT) if that fails.
+func NewCredentialAuthProvider(cred Credential, pat string) (*AuthProvider, error) {
+	src, err := newCredentialSource(cred)
+	if err != nil {
+		return nil, err
+	}
+	a := NewAuthProvider(pat)
+	a.tokenSource = src.token
+	return a, nil
+}
+
+func newCredentialSource(cred Credential) (*credentialSource, error) {
+	src := &credentialSource{
+		cred:         cred,
+		authorityURL: defaultAuthorityURL,
+		imdsURL:      defaultIMDSURL,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+	}
+	switch {
+	case cred.ManagedIdentity:
+	case cred.TenantID == "" || cred.ClientID == "":
+		return nil, errors.New("service principal auth needs a tenant ID and client ID")
+	case cred.CertificatePath != "":
+		data, err := os.ReadFile(cred.CertificatePath)
+		if err != nil {
+			return nil, fmt.Errorf("reading client certificate: %w", err)
+		}
+		if src.cert, src.key, err = parseCertificate(data); err != nil {
+			return nil, err
+		}
+	case cred.ClientSecret == "":
+		return nil, errors.New("service principal auth needs a client secret or certificate")
+	}
+	return src, nil
+}
+
+// parseCertificate reads the first certificate and RSA private key from
+// PEM data.
+func parseCertificate(data []byte) (*x509.Certificate, *rsa.PrivateKey, error) {
+	var cert *x509.Certificate
+	var key *rsa.PrivateKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		switch {
+		case block.Type == "CERTIFICATE" && cert == nil:
+			c, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, nil, fmt.Errorf("parsing client certificate: %w", err)
+			}
+			cert = c
+		case strings.HasSuffix(block.Type, "PRIVATE KEY") && key == nil:
+			parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("parsing client certificate key: %w", err)
+			}
+			rsaKey, ok := parsed.(*rsa.PrivateKey)
+			if !ok {
+				return nil, nil, errors.New("client certificate key is not an RSA key")
+			}
+			key = rsaKey
+		}
+	}
+	if cert == nil || key == nil {
+		return nil, nil, errors.New("client certificate file needs a PEM certificate and private key")
+	}
+	return cert, key, nil
+}
+
+// token acquires a token for ADO.
+func (s *credentialSource) token(ctx context.Context) (string, time.Time, error) {
+	if s.cred.ManagedIdentity {
+		return s.managedIdentityToken(ctx)
+	}
+
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", s.authorityURL, url.PathEscape(s.cred.TenantID))
+	form := url.Values{
+		"grant_type": {"client_credentials"},
+		"client_id":  {s.cred.ClientID},
+		"scope":      {adoResourceID + "/.default"},
+	}
+	if s.key != nil {
+		assertion, err := s.clientAssertion(tokenURL)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		form.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+		form.Set("client_assertion", assertion)
+	} else {
+		form.Set("client_secret", s.cred.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("creating token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := s.do(req, &resp); err != nil {
+		return "", time.Time{}, fmt.Errorf("service principal token request failed: %w", err)
+	}
+	return resp.AccessToken, time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second), nil
+}
+
+// managedIdentityToken asks the Azure Instance Metadata Service for a
+// token for the host's identity.
+func (s *credentialSource) managedIdentityToken(ctx context.Context) (string, time.Time, error) {
+	q := url.Values{"api-version": {"2018-02-01"}, "resource": {adoResourceID}}
+	if s.cred.ClientID != "" {
+		q.Set("client_id", s.cred.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.imdsURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("creating token request: %w", err)
+	}
+	req.Header.Set("Metadata", "true")
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresOn   string `json:"expires_on"` // Unix seconds
+	}
+	if err := s.do(req, &resp); err != nil {
+		return "", time.Time{}, fmt.Errorf("managed identity token request failed: %w", err)
+	}
+	expiry := time.Now().Add(30 * time.Minute)
+	if secs, err := strconv.ParseInt(resp.ExpiresOn, 10, 64); err == nil {
+		expiry = time.Unix(secs, 0)
+	}
+	return resp.AccessToken, expiry, nil
+}
+
+// clientAssertion returns a JWT proving possession of the certificate's
+// key, valid for ten minutes.
+func (s *credentialSource) clientAssertion(audience string) (string, error) {
+	thumbprint := sha1.Sum(s.cert.Raw)
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"x5t": base64.RawURLEncoding.EncodeToString(thumbprint[:]),
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"aud": audience,
+		"iss": s.cred.ClientID,
+		"sub": s.cred.ClientID,
+		"jti": hex.EncodeToString(nonce),
+		"nbf": now.Unix(),
+		"exp": now.Add(10 * time.Minute).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("signing client assertion: %w", err)
+	}
+	return signed + "." + enc.EncodeToString(sig), nil
+}
+
+// do sends a token request and decodes its JSON response into out.
+func (s *credentialSource) do(req *http.Request, out any) error {
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decoding token response: %w", err)
+	}
+	return nil
+}
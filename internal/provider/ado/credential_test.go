@@ -0,0 +1,122 @@
+package ado
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServicePrincipalSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/tenant-1/oauth2/v2.0/token", r.URL.Path)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "client-1", r.PostForm.Get("client_id"))
+		assert.Equal(t, "s3cret", r.PostForm.Get("client_secret"))
+		assert.Equal(t, adoResourceID+"/.default", r.PostForm.Get("scope"))
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "sp-token", "expires_in": 3600})
+	}))
+	defer server.Close()
+
+	src, err := newCredentialSource(Credential{TenantID: "tenant-1", ClientID: "client-1", ClientSecret: "s3cret"})
+	require.NoError(t, err)
+	src.authorityURL = server.URL
+	auth := &AuthProvider{tokenSource: src.token}
+
+	header, err := auth.GetAuthHeader(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer sp-token", header)
+}
+
+func TestServicePrincipalCertificate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "otto"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "sp.pem")
+	data := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})...)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Empty(t, r.PostForm.Get("client_secret"))
+		parts := strings.Split(r.PostForm.Get("client_assertion"), ".")
+		require.Len(t, parts, 3)
+		sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig))
+		claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		var c map[string]any
+		require.NoError(t, json.Unmarshal(claims, &c))
+		assert.Equal(t, server.URL+"/tenant-1/oauth2/v2.0/token", c["aud"])
+		assert.Equal(t, "client-1", c["sub"])
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "cert-token", "expires_in": 3600})
+	}))
+	defer server.Close()
+
+	src, err := newCredentialSource(Credential{TenantID: "tenant-1", ClientID: "client-1", CertificatePath: path})
+	require.NoError(t, err)
+	src.authorityURL = server.URL
+	token, _, err := src.token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "cert-token", token)
+}
+
+func TestManagedIdentity(t *testing.T) {
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.Header.Get("Metadata"))
+		assert.Equal(t, adoResourceID, r.URL.Query().Get("resource"))
+		assert.Equal(t, "identity-1", r.URL.Query().Get("client_id"))
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "mi-token",
+			"expires_on":   strconv.FormatInt(expiry.Unix(), 10),
+		})
+	}))
+	defer server.Close()
+
+	src, err := newCredentialSource(Credential{ClientID: "identity-1", ManagedIdentity: true})
+	require.NoError(t, err)
+	src.imdsURL = server.URL
+	token, got, err := src.token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "mi-token", token)
+	assert.True(t, expiry.Equal(got))
+}
+
+func TestCredentialValidation(t *testing.T) {
+	_, err := NewCredentialAuthProvider(Credential{ClientSecret: "s"}, "")
+	assert.ErrorContains(t, err, "tenant ID and client ID")
+	_, err = NewCredentialAuthProvider(Credential{TenantID: "t", ClientID: "c"}, "")
+	assert.ErrorContains(t, err, "client secret or certificate")
+}
@@ -0,0 +1,200 @@
+package ado
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ADO serves file contents but not patches, so GetDiff builds unified
+// diffs itself from the before and after versions of each changed file.
+
+// diffContextLines is the number of unchanged lines around each hunk.
+const diffContextLines = 3
+
+// maxDiffCells bounds the LCS table; files whose changed regions exceed it
+// are diffed as a wholesale replacement instead.
+const maxDiffCells = 4_000_000
+
+// diffOp is one line of an edit script: ' ' kept, '-' removed, '+' added.
+type diffOp struct {
+	kind byte
+	text string
+}
+
+// unifiedDiff returns a git-style unified diff turning oldText into newText.
+// oldPath or newPath is empty for an added or deleted file. It returns ""
+// when the contents are equal.
+func unifiedDiff(oldPath, newPath, oldText, newText string) string {
+	if isBinary(oldText) || isBinary(newText) {
+		if oldText == newText {
+			return ""
+		}
+		return fmt.Sprintf("%sBinary files %s and %s differ\n",
+			diffHeader(oldPath, newPath), diffName("a", oldPath), diffName("b", newPath))
+	}
+
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+	hunks := formatHunks(ops)
+	if hunks == "" && oldPath == newPath {
+		return ""
+	}
+
+	var buf strings.Builder
+	buf.WriteString(diffHeader(oldPath, newPath))
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", diffName("a", oldPath), diffName("b", newPath))
+	buf.WriteString(hunks)
+	return buf.String()
+}
+
+// diffHeader returns the "diff --git" line naming both sides of a change.
+func diffHeader(oldPath, newPath string) string {
+	if oldPath == "" {
+		oldPath = newPath
+	}
+	if newPath == "" {
+		newPath = oldPath
+	}
+	return fmt.Sprintf("diff --git a/%s b/%s\n", oldPath, newPath)
+}
+
+// diffName returns the ---/+++ name for one side of a change.
+func diffName(side, path string) string {
+	if path == "" {
+		return "/dev/null"
+	}
+	return side + "/" + path
+}
+
+func isBinary(s string) bool {
+	return strings.IndexByte(s, 0) >= 0
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns an edit script turning a into b. The common prefix and
+// suffix are trimmed before the LCS so typical PR edits stay cheap.
+func diffLines(a, b []string) []diffOp {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, l := range a[:pre] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	ops = append(ops, diffMiddle(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, l := range a[len(a)-suf:] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	return ops
+}
+
+// diffMiddle diffs the region between the common prefix and suffix.
+func diffMiddle(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	var ops []diffOp
+	if n*m > maxDiffCells {
+		for _, l := range a {
+			ops = append(ops, diffOp{'-', l})
+		}
+		for _, l := range b {
+			ops = append(ops, diffOp{'+', l})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// formatHunks renders the changed regions of an edit script as hunks with
+// diffContextLines of context, merging hunks whose context overlaps.
+func formatHunks(ops []diffOp) string {
+	// oldPos[k] and newPos[k] count the old and new lines in ops[:k].
+	oldPos := make([]int, len(ops)+1)
+	newPos := make([]int, len(ops)+1)
+	var changes []int
+	for k, op := range ops {
+		oldPos[k+1], newPos[k+1] = oldPos[k], newPos[k]
+		if op.kind != '+' {
+			oldPos[k+1]++
+		}
+		if op.kind != '-' {
+			newPos[k+1]++
+		}
+		if op.kind != ' ' {
+			changes = append(changes, k)
+		}
+	}
+
+	var buf strings.Builder
+	for c := 0; c < len(changes); {
+		start := max(changes[c]-diffContextLines, 0)
+		last := changes[c]
+		for c++; c < len(changes) && changes[c]-last <= 2*diffContextLines; c++ {
+			last = changes[c]
+		}
+		end := min(last+diffContextLines+1, len(ops))
+
+		oldStart, oldCount := oldPos[start]+1, oldPos[end]-oldPos[start]
+		newStart, newCount := newPos[start]+1, newPos[end]-newPos[start]
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, op := range ops[start:end] {
+			buf.WriteByte(op.kind)
+			buf.WriteString(op.text)
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.String()
+}
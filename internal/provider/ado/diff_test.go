@@ -0,0 +1,67 @@
+package ado
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func numberedLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return lines
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	old := numberedLines(20)
+	updated := append([]string{}, old...)
+	updated[1] = "changed 2"
+	updated = append(updated[:15], updated[16:]...) // drop line 16
+
+	diff := unifiedDiff("f.txt", "f.txt", strings.Join(old, "\n")+"\n", strings.Join(updated, "\n")+"\n")
+	assert.Equal(t, `diff --git a/f.txt b/f.txt
+--- a/f.txt
++++ b/f.txt
+@@ -1,5 +1,5 @@
+ line 1
+-line 2
++changed 2
+ line 3
+ line 4
+ line 5
+@@ -13,7 +13,6 @@
+ line 13
+ line 14
+ line 15
+-line 16
+ line 17
+ line 18
+ line 19
+`, diff)
+}
+
+func TestUnifiedDiff_MergesNearbyHunks(t *testing.T) {
+	old := numberedLines(10)
+	updated := append([]string{}, old...)
+	updated[2] = "x"
+	updated[7] = "y"
+
+	diff := unifiedDiff("f", "f", strings.Join(old, "\n"), strings.Join(updated, "\n"))
+	assert.Equal(t, 1, strings.Count(diff, "@@ -"))
+	assert.Contains(t, diff, "@@ -1,10 +1,10 @@\n")
+}
+
+func TestUnifiedDiff_Unchanged(t *testing.T) {
+	assert.Empty(t, unifiedDiff("f", "f", "a\nb\n", "a\nb\n"))
+}
+
+func TestUnifiedDiff_DeletedAndBinary(t *testing.T) {
+	assert.Equal(t, "diff --git a/gone b/gone\n--- a/gone\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		unifiedDiff("gone", "", "a\nb\n", ""))
+	assert.Equal(t, "diff --git a/img b/img\nBinary files a/img and b/img differ\n",
+		unifiedDiff("img", "img", "\x00\x01", "\x00\x02"))
+}
@@ -0,0 +1,150 @@
+package ado
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// maxIssues bounds how many bugs ListIssues returns, the most the work
+// items batch API reads at once.
+const maxIssues = 200
+
+// ListIssues returns the project's open bugs created since filter.Since,
+// oldest first. Bugs belong to the project, not a repository, so
+// filter.Repo is ignored.
+func (b *Backend) ListIssues(ctx context.Context, filter provider.IssueFilter) ([]*provider.Issue, error) {
+	query := "SELECT [System.Id] FROM WorkItems WHERE [System.TeamProject] = @project" +
+		" AND [System.WorkItemType] = 'Bug' AND [System.State] NOT IN ('Closed', 'Done', 'Removed', 'Resolved')"
+	if !filter.Since.IsZero() {
+		query += fmt.Sprintf(" AND [System.CreatedDate] >= '%s'", filter.Since.UTC().Format(time.RFC3339))
+	}
+	query += " ORDER BY [System.CreatedDate] ASC"
+
+	wiqlPath := fmt.Sprintf("/%s/%s/_apis/wit/wiql?timePrecision=true&$top=%d",
+		url.PathEscape(b.organization), url.PathEscape(b.project), maxIssues)
+	resp, err := b.doRequest(ctx, http.MethodPost, wiqlPath, map[string]string{"query": query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bugs: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, b.parseError(resp)
+	}
+	var result adoWiqlResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding bug query: %w", err)
+	}
+	if len(result.WorkItems) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(result.WorkItems))
+	for _, wi := range result.WorkItems {
+		ids = append(ids, strconv.Itoa(wi.ID))
+	}
+	itemsPath := fmt.Sprintf("/%s/%s/_apis/wit/workitems?ids=%s&$expand=links",
+		url.PathEscape(b.organization), url.PathEscape(b.project), strings.Join(ids, ","))
+	resp, err = b.doRequest(ctx, http.MethodGet, itemsPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bugs: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, b.parseError(resp)
+	}
+	var items adoWorkItemList
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, fmt.Errorf("decoding bugs: %w", err)
+	}
+
+	issues := make([]*provider.Issue, 0, len(items.Value))
+	for _, wi := range items.Value {
+		body := wi.Fields.ReproSteps
+		if body == "" {
+			body = wi.Fields.Description
+		}
+		issues = append(issues, &provider.Issue{
+			ID:      strconv.Itoa(wi.ID),
+			Repo:    b.project,
+			Title:   wi.Fields.Title,
+			Body:    body,
+			URL:     wi.Links.HTML.Href,
+			Author:  wi.Fields.CreatedBy.DisplayName,
+			Labels:  splitTags(wi.Fields.Tags),
+			Created: wi.Fields.CreatedDate,
+		})
+	}
+	return issues, nil
+}
+
+// LabelIssue adds labels to the bug's tags.
+func (b *Backend) LabelIssue(ctx context.Context, issue *provider.Issue, labels []string) error {
+	tags := issue.Labels
+	for _, l := range labels {
+		if !containsFold(tags, l) {
+			tags = append(tags, l)
+		}
+	}
+	patch := []adoWorkItemPatchOp{{Op: "add", Path: "/fields/System.Tags", Value: strings.Join(tags, "; ")}}
+	resp, err := b.doRequestWithContentType(ctx, http.MethodPatch, b.workItemPath(issue), patch, "application/json-patch+json")
+	if err != nil {
+		return fmt.Errorf("failed to tag bug %s: %w", issue.ID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return b.parseError(resp)
+	}
+	issue.Labels = tags
+	return nil
+}
+
+// CommentOnIssue posts a markdown comment on the bug.
+func (b *Backend) CommentOnIssue(ctx context.Context, issue *provider.Issue, body string) error {
+	resp, err := b.doRequest(ctx, http.MethodPost, b.workItemPath(issue)+"/comments?format=markdown", map[string]string{"text": body})
+	if err != nil {
+		return fmt.Errorf("failed to comment on bug %s: %w", issue.ID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return b.parseError(resp)
+	}
+	return nil
+}
+
+// workItemPath returns the API path of the bug issue.
+func (b *Backend) workItemPath(issue *provider.Issue) string {
+	project := issue.Repo
+	if project == "" {
+		project = b.project
+	}
+	return fmt.Sprintf("/%s/%s/_apis/wit/workitems/%s",
+		url.PathEscape(b.organization), url.PathEscape(project), url.PathEscape(issue.ID))
+}
+
+// splitTags splits a work item's "a; b" tags field.
+func splitTags(tags string) []string {
+	var out []string
+	for _, t := range strings.Split(tags, ";") {
+		if t = strings.TrimSpace(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package ado
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+func TestIssues(t *testing.T) {
+	since := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	var query, itemIDs, tagsContentType, comment string
+	var patch []adoWorkItemPatchOp
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/testorg/testproject/_apis/wit/wiql":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			query = body["query"]
+			w.Write([]byte(`{"workItems": [{"id": 7}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/testorg/testproject/_apis/wit/workitems":
+			itemIDs = r.URL.Query().Get("ids")
+			w.Write([]byte(`{"value": [{"id": 7, "fields": {
+				"System.Title": "Crash on start", "Microsoft.VSTS.TCM.ReproSteps": "<div>Start it.</div>",
+				"System.Tags": "triage; ui", "System.CreatedDate": "2026-05-02T10:00:00Z",
+				"System.CreatedBy": {"displayName": "Dev"}},
+				"_links": {"html": {"href": "https://dev.azure.com/testorg/testproject/_workitems/edit/7"}}}]}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/testorg/testproject/_apis/wit/workitems/7":
+			tagsContentType = r.Header.Get("Content-Type")
+			json.NewDecoder(r.Body).Decode(&patch)
+			w.Write([]byte(`{"id": 7}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/testorg/testproject/_apis/wit/workitems/7/comments":
+			assert.Equal(t, "markdown", r.URL.Query().Get("format"))
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			comment = body["text"]
+			w.Write([]byte(`{"id": 1}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	b := newTestBackend(t, server)
+	issues, err := b.ListIssues(context.Background(), provider.IssueFilter{Since: since})
+	require.NoError(t, err)
+	assert.Contains(t, query, "[System.WorkItemType] = 'Bug'")
+	assert.Contains(t, query, "[System.CreatedDate] >= '2026-05-01T00:00:00Z'")
+	assert.Equal(t, "7", itemIDs)
+	require.Len(t, issues, 1)
+	issue := issues[0]
+	assert.Equal(t, "7", issue.ID)
+	assert.Equal(t, "testproject", issue.Repo)
+	assert.Equal(t, "<div>Start it.</div>", issue.Body, "a bug's repro steps are its body")
+	assert.Equal(t, "Dev", issue.Author)
+	assert.Equal(t, []string{"triage", "ui"}, issue.Labels)
+	assert.Equal(t, "https://dev.azure.com/testorg/testproject/_workitems/edit/7", issue.URL)
+	assert.True(t, issue.Created.Equal(since.Add(34*time.Hour)))
+
+	// Tags are one field; new ones are added to those already there.
+	require.NoError(t, b.LabelIssue(context.Background(), issue, []string{"UI", "crash"}))
+	assert.Equal(t, "application/json-patch+json", tagsContentType)
+	require.Len(t, patch, 1)
+	assert.Equal(t, "/fields/System.Tags", patch[0].Path)
+	assert.Equal(t, "triage; ui; crash", patch[0].Value)
+
+	require.NoError(t, b.CommentOnIssue(context.Background(), issue, "Thanks!"))
+	assert.Equal(t, "Thanks!", comment)
+}
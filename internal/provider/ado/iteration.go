@@ -0,0 +1,165 @@
+package ado
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// GetFileAtIteration returns filePath's content at the source commit of
+// the given PR iteration. Iteration commits stay reachable on the server
+// after a force-push, so this works for snapshots the branch no longer has.
+func (b *Backend) GetFileAtIteration(ctx context.Context, pr *provider.PRInfo, iteration int, filePath string) (string, error) {
+	repoPath := b.repoAPIPath(pr)
+
+	resp, err := b.doRequest(ctx, http.MethodGet, fmt.Sprintf("%s/pullrequests/%s/iterations/%d", repoPath, pr.ID, iteration), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get iteration: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", b.parseError(resp)
+	}
+
+	var iter adoIteration
+	if err := json.NewDecoder(resp.Body).Decode(&iter); err != nil {
+		return "", fmt.Errorf("failed to decode iteration: %w", err)
+	}
+	if iter.SourceRefCommit.CommitID == "" {
+		return "", fmt.Errorf("iteration %d has no source commit", iteration)
+	}
+
+	return b.getItemContent(ctx, repoPath, filePath, iter.SourceRefCommit.CommitID)
+}
+
+// GetDiff returns the unified diff of the PR's latest iteration against
+// its merge base with the target branch. ADO has no patch endpoint, so
+// each changed file is fetched at both commits and diffed locally.
+func (b *Backend) GetDiff(ctx context.Context, pr *provider.PRInfo) (string, error) {
+	repoPath := b.repoAPIPath(pr)
+	prPath := fmt.Sprintf("%s/pullrequests/%s", repoPath, pr.ID)
+
+	resp, err := b.doRequest(ctx, http.MethodGet, prPath+"/iterations", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to list iterations: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", b.parseError(resp)
+	}
+
+	var iterations adoIterationList
+	if err := json.NewDecoder(resp.Body).Decode(&iterations); err != nil {
+		return "", fmt.Errorf("failed to decode iterations: %w", err)
+	}
+	if len(iterations.Value) == 0 {
+		return "", fmt.Errorf("PR %s has no iterations", pr.ID)
+	}
+	latest := iterations.Value[len(iterations.Value)-1]
+	source, base := latest.SourceRefCommit.CommitID, latest.CommonRefCommit.CommitID
+	if source == "" || base == "" {
+		return "", fmt.Errorf("iteration %d is missing its source or base commit", latest.ID)
+	}
+
+	changes, err := b.getIterationChanges(ctx, prPath, latest.ID)
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	for _, c := range changes {
+		if c.Item.IsFolder || (c.Item.GitObjectType != "" && c.Item.GitObjectType != "blob") {
+			continue
+		}
+		newPath, oldPath := c.Item.Path, c.Item.Path
+		if c.OriginalPath != "" {
+			oldPath = c.OriginalPath
+		}
+
+		var oldText, newText string
+		if strings.Contains(c.ChangeType, "add") {
+			oldPath = ""
+		} else if oldText, err = b.getItemContent(ctx, repoPath, oldPath, base); err != nil {
+			return "", err
+		}
+		if strings.Contains(c.ChangeType, "delete") {
+			newPath = ""
+		} else if newText, err = b.getItemContent(ctx, repoPath, newPath, source); err != nil {
+			return "", err
+		}
+
+		buf.WriteString(unifiedDiff(strings.TrimPrefix(oldPath, "/"), strings.TrimPrefix(newPath, "/"), oldText, newText))
+	}
+	return buf.String(), nil
+}
+
+// getIterationChanges returns every file change in an iteration compared
+// to the target branch, following the API's paging.
+func (b *Backend) getIterationChanges(ctx context.Context, prPath string, iteration int) ([]adoIterationChange, error) {
+	var changes []adoIterationChange
+	skip := 0
+	for {
+		path := fmt.Sprintf("%s/iterations/%d/changes?$compareTo=0&$top=2000&$skip=%d", prPath, iteration, skip)
+		resp, err := b.doRequest(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get iteration changes: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			err := b.parseError(resp)
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var page adoIterationChanges
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode iteration changes: %w", err)
+		}
+		changes = append(changes, page.ChangeEntries...)
+		if page.NextSkip == 0 {
+			return changes, nil
+		}
+		skip = page.NextSkip
+	}
+}
+
+// getItemContent returns the content of filePath at commit.
+func (b *Backend) getItemContent(ctx context.Context, repoPath, filePath, commit string) (string, error) {
+	if !strings.HasPrefix(filePath, "/") {
+		filePath = "/" + filePath
+	}
+	itemPath := fmt.Sprintf("%s/items?path=%s&versionDescriptor.version=%s&versionDescriptor.versionType=commit",
+		repoPath, url.QueryEscape(filePath), url.QueryEscape(commit))
+
+	resp, err := b.doRequestWithAccept(ctx, http.MethodGet, itemPath, nil, "text/plain")
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s at %s: %w", filePath, commit, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", b.parseError(resp)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file content: %w", err)
+	}
+	return string(content), nil
+}
+
+// repoAPIPath returns the git repository API path for the PR's repo.
+func (b *Backend) repoAPIPath(pr *provider.PRInfo) string {
+	return fmt.Sprintf("/%s/%s/_apis/git/repositories/%s",
+		url.PathEscape(b.resolveOrg(pr)), url.PathEscape(b.resolveProject(pr)), url.PathEscape(b.resolveRepo(pr)))
+}
@@ -0,0 +1,108 @@
+package ado
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// policyNames maps branch policy type display names to what a PR waiting
+// on them is missing. Unlisted types are reported by their display name.
+var policyNames = map[string]string{
+	"Minimum number of reviewers": "required reviewers",
+	"Required reviewers":          "required reviewers",
+	"Work item linking":           "work item link missing",
+	"Comment requirements":        "unresolved comments",
+	"Status":                      "status check",
+}
+
+// getPolicyChecks returns the PR's branch policy evaluations, other than
+// build policies (already covered by the builds query) and policies that
+// are disabled or do not apply to the PR. It also returns the definition
+// IDs of the builds that blocking build policies require.
+func (b *Backend) getPolicyChecks(ctx context.Context, org, project, prID string) ([]provider.PolicyCheck, map[int]bool, error) {
+	projectID, err := b.getProjectID(ctx, org, project)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	artifactID := fmt.Sprintf("vstfs:///CodeReview/CodeReviewId/%s/%s", projectID, prID)
+	path := fmt.Sprintf("/%s/%s/_apis/policy/evaluations?artifactId=%s",
+		url.PathEscape(org), url.PathEscape(project), url.QueryEscape(artifactID))
+
+	resp, err := b.doRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get policy evaluations: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, b.parseError(resp)
+	}
+
+	var evals adoPolicyEvaluationList
+	if err := json.NewDecoder(resp.Body).Decode(&evals); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode policy evaluations: %w", err)
+	}
+
+	var checks []provider.PolicyCheck
+	requiredBuilds := make(map[int]bool)
+	for _, e := range evals.Value {
+		typeName := e.Configuration.Type.DisplayName
+		if !e.Configuration.IsEnabled || e.Status == "notApplicable" {
+			continue
+		}
+		if typeName == "Build" {
+			if e.Configuration.IsBlocking {
+				requiredBuilds[e.Configuration.Settings.BuildDefinitionID] = true
+			}
+			continue
+		}
+		name, ok := policyNames[typeName]
+		if !ok {
+			name = strings.ToLower(typeName)
+		}
+		checks = append(checks, provider.PolicyCheck{
+			Name:     name,
+			Status:   e.Status,
+			Blocking: e.Configuration.IsBlocking,
+		})
+	}
+	return checks, requiredBuilds, nil
+}
+
+// getProjectID resolves a project name to the GUID that policy artifact
+// IDs use. Results are cached for the backend's lifetime.
+func (b *Backend) getProjectID(ctx context.Context, org, project string) (string, error) {
+	key := org + "/" + project
+	if id, ok := b.projectIDs.Load(key); ok {
+		return id.(string), nil
+	}
+
+	resp, err := b.doRequest(ctx, http.MethodGet, fmt.Sprintf("/%s/_apis/projects/%s", url.PathEscape(org), url.PathEscape(project)), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get project: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", b.parseError(resp)
+	}
+
+	var p struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		return "", fmt.Errorf("failed to decode project: %w", err)
+	}
+	if p.ID == "" {
+		return "", fmt.Errorf("project %q has no ID", project)
+	}
+	b.projectIDs.Store(key, p.ID)
+	return p.ID, nil
+}
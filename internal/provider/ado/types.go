@@ -9,29 +9,51 @@ type adoPullRequest struct {
 	Description   string      `json:"description"`
 	Status        string      `json:"status"`
 	MergeStatus   string      `json:"mergeStatus"`
+	IsDraft       bool        `json:"isDraft"`
 	SourceRefName string      `json:"sourceRefName"`
 	TargetRefName string      `json:"targetRefName"`
 	CreatedBy     adoIdentity `json:"createdBy"`
 	URL           string      `json:"url"`
+	Labels        []adoLabel  `json:"labels"`
 	Repository    struct {
 		ID   string `json:"id"`
 		Name string `json:"name"`
 	} `json:"repository"`
+	// ForkSource is set when the source branch is in a fork.
+	ForkSource *struct {
+		Repository struct {
+			ID string `json:"id"`
+		} `json:"repository"`
+	} `json:"forkSource"`
 	Links struct {
 		Web struct {
 			Href string `json:"href"`
 		} `json:"web"`
 	} `json:"_links"`
+
+	LastMergeSourceCommit struct {
+		CommitID string `json:"commitId"`
+	} `json:"lastMergeSourceCommit"`
+	// LastMergeCommit is the commit the PR merged as, once completed.
+	LastMergeCommit struct {
+		CommitID string `json:"commitId"`
+	} `json:"lastMergeCommit"`
+}
+
+// adoLabel is a tag on a pull request.
+type adoLabel struct {
+	Name string `json:"name"`
 }
 
 // adoThread represents a comment thread on a pull request.
 type adoThread struct {
-	ID            int               `json:"id"`
-	Status        any               `json:"status"`
-	ThreadContext *adoThreadContext `json:"threadContext,omitempty"`
-	Comments      []adoComment      `json:"comments"`
-	PublishedDate time.Time         `json:"publishedDate"`
-	Properties    map[string]any    `json:"properties,omitempty"`
+	ID              int                 `json:"id"`
+	Status          any                 `json:"status"`
+	ThreadContext   *adoThreadContext   `json:"threadContext,omitempty"`
+	PRThreadContext *adoPRThreadContext `json:"pullRequestThreadContext,omitempty"`
+	Comments        []adoComment        `json:"comments"`
+	PublishedDate   time.Time           `json:"publishedDate"`
+	Properties      map[string]any      `json:"properties,omitempty"`
 }
 
 // adoComment represents a single comment within a thread.
@@ -53,6 +75,102 @@ type adoThreadContext struct {
 	RightFileEnd   *adoLineOffset `json:"rightFileEnd,omitempty"`
 }
 
+// adoPRThreadContext ties an inline thread to the PR iterations it was
+// made against and the file change it belongs to.
+type adoPRThreadContext struct {
+	ChangeTrackingID int                  `json:"changeTrackingId"`
+	IterationContext *adoIterationContext `json:"iterationContext,omitempty"`
+}
+
+// adoIterationContext names the pair of iterations a thread's diff view
+// compared; the second is the one whose code was commented on.
+type adoIterationContext struct {
+	FirstComparingIteration  int `json:"firstComparingIteration"`
+	SecondComparingIteration int `json:"secondComparingIteration"`
+}
+
+// adoIteration is one push to a pull request's source branch.
+type adoIteration struct {
+	ID              int          `json:"id"`
+	SourceRefCommit adoCommitRef `json:"sourceRefCommit"`
+	CommonRefCommit adoCommitRef `json:"commonRefCommit"`
+}
+
+// adoCommitRef identifies a commit.
+type adoCommitRef struct {
+	CommitID string `json:"commitId"`
+}
+
+// adoCommit is a commit as the Git commits API returns it.
+type adoCommit struct {
+	CommitID string `json:"commitId"`
+	Author   struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"author"`
+}
+
+// adoWikiPage is a wiki page as the Wiki pages API takes and returns it.
+type adoWikiPage struct {
+	Path      string `json:"path,omitempty"`
+	Content   string `json:"content"`
+	RemoteURL string `json:"remoteUrl,omitempty"`
+}
+
+// adoRepository is a Git repository as the Repositories API returns it.
+type adoRepository struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	RemoteURL string `json:"remoteUrl"`
+}
+
+// adoWiqlResult is the response of a WIQL query: the IDs of the matching
+// work items.
+type adoWiqlResult struct {
+	WorkItems []struct {
+		ID int `json:"id"`
+	} `json:"workItems"`
+}
+
+// adoWorkItemList is the envelope for the work items batch API response.
+type adoWorkItemList struct {
+	Value []adoWorkItem `json:"value"`
+}
+
+// adoWorkItemFields are the work item fields issue triage reads.
+type adoWorkItemFields struct {
+	Title       string      `json:"System.Title"`
+	Description string      `json:"System.Description"`
+	ReproSteps  string      `json:"Microsoft.VSTS.TCM.ReproSteps"`
+	Tags        string      `json:"System.Tags"`
+	CreatedDate time.Time   `json:"System.CreatedDate"`
+	CreatedBy   adoIdentity `json:"System.CreatedBy"`
+}
+
+// adoIterationList is the envelope for the PR iterations API response.
+type adoIterationList struct {
+	Value []adoIteration `json:"value"`
+}
+
+// adoIterationChanges is one page of the files changed by a PR iteration.
+type adoIterationChanges struct {
+	ChangeEntries []adoIterationChange `json:"changeEntries"`
+	NextSkip      int                  `json:"nextSkip"`
+	NextTop       int                  `json:"nextTop"`
+}
+
+// adoIterationChange is one file changed by a PR iteration.
+type adoIterationChange struct {
+	ChangeTrackingID int    `json:"changeTrackingId"`
+	ChangeType       string `json:"changeType"`
+	OriginalPath     string `json:"originalPath,omitempty"`
+	Item             struct {
+		Path          string `json:"path"`
+		GitObjectType string `json:"gitObjectType,omitempty"`
+		IsFolder      bool   `json:"isFolder,omitempty"`
+	} `json:"item"`
+}
+
 // adoLineOffset specifies a line and column offset in a file.
 type adoLineOffset struct {
 	Line   int `json:"line"`
@@ -127,6 +245,27 @@ type adoBuildList struct {
 	Count int        `json:"count"`
 }
 
+// adoPolicyEvaluation is one branch policy's evaluation on a pull request.
+type adoPolicyEvaluation struct {
+	Status        string `json:"status"` // queued, running, approved, rejected, notApplicable, broken
+	Configuration struct {
+		IsEnabled  bool `json:"isEnabled"`
+		IsBlocking bool `json:"isBlocking"`
+		Type       struct {
+			ID          string `json:"id"`
+			DisplayName string `json:"displayName"`
+		} `json:"type"`
+		Settings struct {
+			BuildDefinitionID int `json:"buildDefinitionId"` // build policies
+		} `json:"settings"`
+	} `json:"configuration"`
+}
+
+// adoPolicyEvaluationList is the envelope for the policy evaluations API response.
+type adoPolicyEvaluationList struct {
+	Value []adoPolicyEvaluation `json:"value"`
+}
+
 // adoConnectionData is the response from the connectiondata endpoint.
 type adoConnectionData struct {
 	AuthenticatedUser adoIdentity `json:"authenticatedUser"`
@@ -141,16 +280,35 @@ type adoWorkItemPatchOp struct {
 
 // adoWorkItem represents a work item response.
 type adoWorkItem struct {
-	ID  int    `json:"id"`
-	URL string `json:"url"`
+	ID     int               `json:"id"`
+	URL    string            `json:"url"`
+	Fields adoWorkItemFields `json:"fields"`
+	Links  struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"_links"`
 }
 
 // adoPullRequestCreate is the request body for creating a new pull request.
 type adoPullRequestCreate struct {
-	SourceRefName string `json:"sourceRefName"`
-	TargetRefName string `json:"targetRefName"`
-	Title         string `json:"title"`
-	Description   string `json:"description"`
+	SourceRefName     string                `json:"sourceRefName"`
+	TargetRefName     string                `json:"targetRefName"`
+	Title             string                `json:"title"`
+	Description       string                `json:"description"`
+	WorkItemRefs      []adoResourceRef      `json:"workItemRefs,omitempty"`
+	CompletionOptions *adoCompletionOptions `json:"completionOptions,omitempty"`
+	Labels            []adoLabel            `json:"labels,omitempty"`
+}
+
+// adoResourceRef references another resource, such as a work item, by ID.
+type adoResourceRef struct {
+	ID string `json:"id"`
+}
+
+// adoCompletionOptions controls what happens when a pull request completes.
+type adoCompletionOptions struct {
+	TransitionWorkItems bool `json:"transitionWorkItems,omitempty"`
 }
 
 // adoPullRequestList is the envelope for the pull requests list API response.
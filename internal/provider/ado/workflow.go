@@ -3,10 +3,12 @@ package ado
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/alanmeadows/otto/internal/provider"
 )
@@ -108,6 +110,31 @@ func (b *Backend) workflowAddressBot(ctx context.Context, pr *provider.PRInfo) e
 	return nil
 }
 
+// CheckAuth verifies the credentials by reading the authenticated identity.
+// ADO does not say when a PAT or Entra session expires, so the expiry is
+// always zero.
+func (b *Backend) CheckAuth(ctx context.Context) (time.Time, error) {
+	if _, err := b.auth.GetAuthHeader(ctx); err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", provider.ErrAuthFailed, err)
+	}
+	path := fmt.Sprintf("/%s/_apis/connectiondata", url.PathEscape(b.organization))
+	resp, err := b.doRequest(ctx, http.MethodGet, path, nil)
+	if errors.Is(err, ErrAuthExpired) {
+		return time.Time{}, fmt.Errorf("%w: %w", provider.ErrAuthFailed, err)
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return time.Time{}, fmt.Errorf("%w: %w", provider.ErrAuthFailed, b.parseError(resp))
+	case resp.StatusCode != http.StatusOK:
+		return time.Time{}, b.parseError(resp)
+	}
+	return time.Time{}, nil
+}
+
 // getCurrentUser retrieves the authenticated user's identity.
 func (b *Backend) getCurrentUser(ctx context.Context, org string) (*adoIdentity, error) {
 	path := fmt.Sprintf("/%s/_apis/connectiondata", url.PathEscape(org))
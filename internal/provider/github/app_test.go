@@ -0,0 +1,73 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppBackendRefreshesInstallationTokens(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	issued := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v3/app/installations/7/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		require.True(t, ok)
+		parts := strings.Split(jwt, ".")
+		require.Len(t, parts, 3)
+		sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig))
+		claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		assert.Contains(t, string(claims), `"iss":"42"`)
+
+		issued++
+		// Inside the refresh window, so every request needs a new token.
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{
+			"token":      fmt.Sprintf("ghs_%d", issued),
+			"expires_at": time.Now().Add(appTokenRefresh - time.Minute).UTC(),
+		})
+	})
+	var auth []string
+	mux.HandleFunc("GET /api/v3/repos/o/r", func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"name":"r"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	b, err := NewAppBackend("o", "r", 42, 7, keyPEM)
+	require.NoError(t, err)
+	require.NoError(t, b.SetBaseURL(server.URL))
+
+	for range 2 {
+		_, _, err = b.client.Repositories.Get(t.Context(), "o", "r")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"Bearer ghs_1", "Bearer ghs_2"}, auth)
+}
+
+func TestNewAppBackendRejectsBadKey(t *testing.T) {
+	_, err := NewAppBackend("o", "r", 42, 7, []byte("not a key"))
+	assert.ErrorContains(t, err, "not PEM-encoded")
+}
@@ -2,12 +2,14 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -18,6 +20,8 @@ import (
 	"github.com/shurcooL/githubv4"
 	"golang.org/x/oauth2"
 
+	"github.com/alanmeadows/otto/internal/httpcache"
+	"github.com/alanmeadows/otto/internal/logdistill"
 	"github.com/alanmeadows/otto/internal/provider"
 )
 
@@ -35,13 +39,19 @@ type Backend struct {
 	owner     string
 	repo      string
 	token     string
-	baseURL   string // override for testing
+	app       *appTokenSource    // GitHub App credentials; nil = token auth
+	tokens    oauth2.TokenSource // refreshing installation tokens from app
+	baseURL   string             // override for testing
+	transport http.RoundTripper  // nil = http.DefaultTransport
+	checks    provider.CheckFilter
 }
 
 // NewBackend creates a new GitHub backend for the given owner/repo.
-// Uses go-github-ratelimit middleware for automatic rate limit handling.
+// Uses go-github-ratelimit middleware for automatic rate limit handling,
+// over conditional requests that let unchanged resources come back as a
+// 304 that does not count against the rate limit.
 func NewBackend(owner, repo, token string) *Backend {
-	rateLimiter := github_ratelimit.NewClient(nil)
+	rateLimiter := github_ratelimit.NewClient(httpcache.New(nil))
 	client := gh.NewClient(rateLimiter).WithAuthToken(token)
 	return &Backend{
 		client: client,
@@ -51,6 +61,82 @@ func NewBackend(owner, repo, token string) *Backend {
 	}
 }
 
+// NewAppBackend creates a GitHub backend that authenticates as an
+// installation of a GitHub App, from the app's ID, the installation's ID,
+// and the app's PEM-encoded private key. Installation tokens last an hour
+// and are replaced shortly before they expire.
+func NewAppBackend(owner, repo string, appID, installationID int64, privateKey []byte) (*Backend, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub App %d: %w", appID, err)
+	}
+	app := &appTokenSource{
+		appID:          appID,
+		installationID: installationID,
+		key:            key,
+		apiURL:         "https://api.github.com/",
+		now:            time.Now,
+	}
+	b := &Backend{
+		owner:  owner,
+		repo:   repo,
+		app:    app,
+		tokens: oauth2.ReuseTokenSourceWithExpiry(nil, app, appTokenRefresh),
+	}
+	b.client = b.newClient(httpcache.New(nil))
+	return b, nil
+}
+
+// newClient returns a REST client sending requests through rt, with the
+// backend's credentials.
+func (b *Backend) newClient(rt http.RoundTripper) *gh.Client {
+	if b.tokens != nil {
+		return gh.NewClient(github_ratelimit.NewClient(&oauth2.Transport{Source: b.tokens, Base: rt}))
+	}
+	return gh.NewClient(github_ratelimit.NewClient(rt)).WithAuthToken(b.token)
+}
+
+// SetRepository sets the default owner and repository, used where a PR
+// does not name its own (e.g., when creating one).
+func (b *Backend) SetRepository(owner, repo string) {
+	b.owner = owner
+	b.repo = repo
+}
+
+// SetTransport sends the backend's requests, including log downloads,
+// through rt, e.g. to record or replay them. rt replaces the default
+// transport and its conditional request cache. GitHub App token exchanges
+// bypass rt, so installation tokens never reach a recording. Call it
+// before SetBaseURL.
+func (b *Backend) SetTransport(rt http.RoundTripper) {
+	b.transport = rt
+	b.client = b.newClient(rt)
+}
+
+// SetCheckFilter restricts the checks GetPipelineStatus reports. Required
+// checks are those branch protection or rulesets require for the PR's
+// target branch.
+func (b *Backend) SetCheckFilter(f provider.CheckFilter) {
+	b.checks = f
+}
+
+// SetBaseURL points the REST and GraphQL clients at a GitHub Enterprise-style
+// API root (REST under /api/v3/, GraphQL at /api/graphql), e.g. for
+// `otto mock-provider` or a GHES instance.
+func (b *Backend) SetBaseURL(baseURL string) error {
+	base := strings.TrimSuffix(baseURL, "/") + "/"
+	client, err := b.client.WithEnterpriseURLs(base, base)
+	if err != nil {
+		return fmt.Errorf("setting GitHub base URL: %w", err)
+	}
+	b.client = client
+	b.baseURL = strings.TrimSuffix(baseURL, "/")
+	if b.app != nil {
+		b.app.apiURL = client.BaseURL.String()
+	}
+	return nil
+}
+
 // Name returns "github".
 func (b *Backend) Name() string {
 	return "github"
@@ -105,6 +191,19 @@ func (b *Backend) GetPipelineStatus(ctx context.Context, pr *provider.PRInfo) (*
 		Builds: make([]provider.BuildInfo, 0),
 	}
 
+	filter := b.checks
+	var required map[string]bool
+	if filter.RequiredOnly {
+		required, err = b.getRequiredChecks(ctx, owner, repo, prNum)
+		if err != nil {
+			// Which checks are required is unknown, so none are dropped
+			// for being optional.
+			slog.Warn("failed to get required checks", "pr", pr.ID, "error", err)
+			filter.RequiredOnly = false
+		}
+	}
+	filtered := 0
+
 	// Query check runs (with pagination).
 	checkOpts := &gh.ListCheckRunsOptions{
 		ListOptions: gh.ListOptions{PerPage: 100},
@@ -117,11 +216,16 @@ func (b *Backend) GetPipelineStatus(ctx context.Context, pr *provider.PRInfo) (*
 		}
 		for _, cr := range checkResult.CheckRuns {
 			bi := provider.BuildInfo{
-				ID:     strconv.FormatInt(cr.GetID(), 10),
-				Name:   cr.GetName(),
-				Status: cr.GetStatus(),
-				Result: cr.GetConclusion(),
-				URL:    cr.GetHTMLURL(),
+				ID:       strconv.FormatInt(cr.GetID(), 10),
+				Name:     cr.GetName(),
+				Status:   cr.GetStatus(),
+				Result:   cr.GetConclusion(),
+				URL:      cr.GetHTMLURL(),
+				Required: required[cr.GetName()],
+			}
+			if !filter.Keep(bi) {
+				filtered++
+				continue
 			}
 			status.Builds = append(status.Builds, bi)
 			b.updateOverallState(status, cr.GetStatus(), cr.GetConclusion())
@@ -139,11 +243,16 @@ func (b *Backend) GetPipelineStatus(ctx context.Context, pr *provider.PRInfo) (*
 	} else {
 		for _, s := range combined.Statuses {
 			bi := provider.BuildInfo{
-				ID:     strconv.FormatInt(s.GetID(), 10),
-				Name:   s.GetContext(),
-				Status: "completed",
-				Result: s.GetState(), // "success", "failure", "error", "pending"
-				URL:    s.GetTargetURL(),
+				ID:       strconv.FormatInt(s.GetID(), 10),
+				Name:     s.GetContext(),
+				Status:   "completed",
+				Result:   s.GetState(), // "success", "failure", "error", "pending"
+				URL:      s.GetTargetURL(),
+				Required: required[s.GetContext()],
+			}
+			if !filter.Keep(bi) {
+				filtered++
+				continue
 			}
 			status.Builds = append(status.Builds, bi)
 
@@ -158,13 +267,144 @@ func (b *Backend) GetPipelineStatus(ctx context.Context, pr *provider.PRInfo) (*
 		}
 	}
 
-	if len(status.Builds) == 0 {
+	// With no checks left after filtering, none stand in the way; with none
+	// at all, they haven't started.
+	if len(status.Builds) == 0 && filtered == 0 {
 		status.State = "pending"
 	}
 
+	// With a merge queue, the PR's own checks aren't the whole story: the
+	// queue tests it merged with the PRs ahead of it.
+	if err := b.addMergeQueueStatus(ctx, status, filter, owner, repo, prNum, ghPR.GetBase().GetRef(), headSHA); err != nil {
+		slog.Warn("failed to get merge queue status", "pr", pr.ID, "error", err)
+	}
+
 	return status, nil
 }
 
+// addMergeQueueStatus adds the PR's merge queue entry to status, with the
+// queue's workflow runs for the PR's current head as builds. GitHub drops
+// a PR from the queue when those runs fail; the failed runs still fail the
+// pipeline, so otto fixes the PR, until a push moves its head. Nothing is
+// added when the target branch has no merge queue. Queue runs count as
+// required, since the queue won't merge the PR without them.
+func (b *Backend) addMergeQueueStatus(ctx context.Context, status *provider.PipelineStatus, filter provider.CheckFilter, owner, repo string, prNum int, base, headSHA string) error {
+	var query struct {
+		Repository struct {
+			PullRequest struct {
+				IsMergeQueueEnabled bool
+				MergeQueueEntry     *struct {
+					Position int
+					State    string
+				}
+			} `graphql:"pullRequest(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	vars := map[string]any{
+		"owner":  githubv4.String(owner),
+		"name":   githubv4.String(repo),
+		"number": githubv4.Int(prNum),
+	}
+	if err := b.getGraphQLClient(ctx).Query(ctx, &query, vars); err != nil {
+		return fmt.Errorf("failed to query merge queue entry: %w", err)
+	}
+	pr := query.Repository.PullRequest
+	if !pr.IsMergeQueueEnabled {
+		return nil
+	}
+
+	// Queue runs are on a branch named for the target, the PR, and its head.
+	runs, _, err := b.client.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, &gh.ListWorkflowRunsOptions{
+		Branch:      fmt.Sprintf("gh-readonly-queue/%s/pr-%d-%s", base, prNum, headSHA),
+		Event:       "merge_group",
+		ListOptions: gh.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list merge queue runs: %w", err)
+	}
+	failed := false
+	seen := make(map[int64]bool)
+	for _, run := range runs.WorkflowRuns {
+		// Newest first; a PR queued again reruns its workflows.
+		if seen[run.GetWorkflowID()] {
+			continue
+		}
+		seen[run.GetWorkflowID()] = true
+		bi := provider.BuildInfo{
+			ID:       strconv.FormatInt(run.GetID(), 10),
+			Name:     "merge queue: " + run.GetName(),
+			Status:   run.GetStatus(),
+			Result:   run.GetConclusion(),
+			URL:      run.GetHTMLURL(),
+			Required: true,
+		}
+		if !filter.Keep(bi) {
+			continue
+		}
+		status.Builds = append(status.Builds, bi)
+		b.updateOverallState(status, run.GetStatus(), run.GetConclusion())
+		switch run.GetConclusion() {
+		case "failure", "timed_out", "cancelled", "action_required":
+			failed = true
+		}
+	}
+
+	switch {
+	case pr.MergeQueueEntry != nil:
+		status.MergeQueue = &provider.MergeQueueStatus{
+			Position: pr.MergeQueueEntry.Position,
+			State:    strings.ToLower(pr.MergeQueueEntry.State),
+		}
+	case failed:
+		status.MergeQueue = &provider.MergeQueueStatus{State: "failed"}
+	}
+	return nil
+}
+
+// GetDiff assembles the PR's unified diff from the per-file patches of the
+// pull request files API. GitHub omits the patch for binary and very large
+// files; those are listed without hunks.
+func (b *Backend) GetDiff(ctx context.Context, pr *provider.PRInfo) (string, error) {
+	owner, repo := b.resolveOwnerRepo(pr)
+	prNum, err := strconv.Atoi(pr.ID)
+	if err != nil {
+		return "", fmt.Errorf("invalid PR number: %s", pr.ID)
+	}
+
+	var buf strings.Builder
+	opts := &gh.ListOptions{PerPage: 100}
+	for {
+		files, resp, err := b.client.PullRequests.ListFiles(ctx, owner, repo, prNum, opts)
+		if err != nil {
+			return "", fmt.Errorf("failed to list PR files: %w", err)
+		}
+		for _, f := range files {
+			oldPath, newPath := f.GetFilename(), f.GetFilename()
+			if f.GetPreviousFilename() != "" {
+				oldPath = f.GetPreviousFilename()
+			}
+			fmt.Fprintf(&buf, "diff --git a/%s b/%s\n", oldPath, newPath)
+			if f.GetPatch() == "" {
+				fmt.Fprintf(&buf, "(%s, no patch available)\n", f.GetStatus())
+				continue
+			}
+			from, to := "a/"+oldPath, "b/"+newPath
+			switch f.GetStatus() {
+			case "added":
+				from = "/dev/null"
+			case "removed":
+				to = "/dev/null"
+			}
+			fmt.Fprintf(&buf, "--- %s\n+++ %s\n%s\n", from, to, strings.TrimSuffix(f.GetPatch(), "\n"))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return buf.String(), nil
+}
+
 // GetComments retrieves all comments on a pull request.
 // Fetches both issue comments (general) and review comments (inline).
 func (b *Backend) GetComments(ctx context.Context, pr *provider.PRInfo) ([]provider.Comment, error) {
@@ -231,9 +471,162 @@ func (b *Backend) GetComments(ctx context.Context, pr *provider.PRInfo) ([]provi
 		reviewOpts.Page = resp.NextPage
 	}
 
+	// Resolution state lives on review threads, which only GraphQL exposes.
+	threads, err := b.getReviewThreads(ctx, owner, repo, prNum)
+	if err != nil {
+		slog.Warn("failed to get review thread state", "pr", pr.ID, "error", err)
+	}
+	for i := range comments {
+		if c := &comments[i]; c.FilePath != "" {
+			root, _ := strconv.ParseInt(c.ThreadID, 10, 64)
+			c.IsResolved = threads[root].IsResolved
+		}
+	}
+
+	// Review summaries carry what bots such as Copilot say about a review
+	// as a whole, e.g. that it produced no comments. They have no thread to
+	// reply to, so they are reported as resolved.
+	listOpts := &gh.ListOptions{PerPage: 100}
+	for {
+		reviews, resp, err := b.client.PullRequests.ListReviews(ctx, owner, repo, prNum, listOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list reviews: %w", err)
+		}
+		for _, r := range reviews {
+			if r.GetBody() == "" {
+				continue
+			}
+			comments = append(comments, provider.Comment{
+				ID:          strconv.FormatInt(r.GetID(), 10),
+				ThreadID:    strconv.FormatInt(r.GetID(), 10),
+				Author:      r.GetUser().GetLogin(),
+				Body:        r.GetBody(),
+				CommentType: "review",
+				IsResolved:  true,
+				CreatedAt:   r.GetSubmittedAt().Time,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		listOpts.Page = resp.NextPage
+	}
+
 	return comments, nil
 }
 
+// getRequiredChecks returns the names of the checks and commit statuses on
+// the PR's head commit that are required for it to merge.
+func (b *Backend) getRequiredChecks(ctx context.Context, owner, repo string, prNum int) (map[string]bool, error) {
+	var query struct {
+		Repository struct {
+			PullRequest struct {
+				Commits struct {
+					Nodes []struct {
+						Commit struct {
+							StatusCheckRollup *struct {
+								Contexts struct {
+									Nodes []struct {
+										CheckRun struct {
+											Name       string
+											IsRequired bool `graphql:"isRequired(pullRequestNumber: $number)"`
+										} `graphql:"... on CheckRun"`
+										StatusContext struct {
+											Context    string
+											IsRequired bool `graphql:"isRequired(pullRequestNumber: $number)"`
+										} `graphql:"... on StatusContext"`
+									}
+								} `graphql:"contexts(first: 100)"`
+							}
+						}
+					}
+				} `graphql:"commits(last: 1)"`
+			} `graphql:"pullRequest(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	vars := map[string]any{
+		"owner":  githubv4.String(owner),
+		"name":   githubv4.String(repo),
+		"number": githubv4.Int(prNum),
+	}
+	if err := b.getGraphQLClient(ctx).Query(ctx, &query, vars); err != nil {
+		return nil, fmt.Errorf("failed to query required checks: %w", err)
+	}
+
+	required := make(map[string]bool)
+	for _, c := range query.Repository.PullRequest.Commits.Nodes {
+		if c.Commit.StatusCheckRollup == nil {
+			continue
+		}
+		for _, n := range c.Commit.StatusCheckRollup.Contexts.Nodes {
+			// Each node is one of the two; the other's fields stay empty.
+			if n.CheckRun.Name != "" && n.CheckRun.IsRequired {
+				required[n.CheckRun.Name] = true
+			}
+			if n.StatusContext.Context != "" && n.StatusContext.IsRequired {
+				required[n.StatusContext.Context] = true
+			}
+		}
+	}
+	return required, nil
+}
+
+// reviewThread is a pull request review thread as GraphQL reports it.
+type reviewThread struct {
+	ID         string // node ID, as resolveReviewThread takes it
+	IsResolved bool
+}
+
+// getReviewThreads returns the PR's review threads keyed by the database ID
+// of each thread's first comment, which is the ThreadID GetComments reports.
+func (b *Backend) getReviewThreads(ctx context.Context, owner, repo string, prNum int) (map[int64]reviewThread, error) {
+	var query struct {
+		Repository struct {
+			PullRequest struct {
+				ReviewThreads struct {
+					Nodes []struct {
+						ID         githubv4.ID
+						IsResolved bool
+						Comments   struct {
+							Nodes []struct {
+								DatabaseID int64 `graphql:"databaseId"`
+							}
+						} `graphql:"comments(first: 1)"`
+					}
+					PageInfo struct {
+						HasNextPage bool
+						EndCursor   githubv4.String
+					}
+				} `graphql:"reviewThreads(first: 100, after: $cursor)"`
+			} `graphql:"pullRequest(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	vars := map[string]any{
+		"owner":  githubv4.String(owner),
+		"name":   githubv4.String(repo),
+		"number": githubv4.Int(prNum),
+		"cursor": (*githubv4.String)(nil),
+	}
+
+	threads := make(map[int64]reviewThread)
+	for {
+		if err := b.getGraphQLClient(ctx).Query(ctx, &query, vars); err != nil {
+			return nil, fmt.Errorf("failed to query review threads: %w", err)
+		}
+		page := query.Repository.PullRequest.ReviewThreads
+		for _, t := range page.Nodes {
+			if len(t.Comments.Nodes) == 0 {
+				continue
+			}
+			threads[t.Comments.Nodes[0].DatabaseID] = reviewThread{ID: fmt.Sprint(t.ID), IsResolved: t.IsResolved}
+		}
+		if !page.PageInfo.HasNextPage {
+			return threads, nil
+		}
+		vars["cursor"] = githubv4.NewString(page.PageInfo.EndCursor)
+	}
+}
+
 // PostComment posts a general comment on a pull request.
 func (b *Backend) PostComment(ctx context.Context, pr *provider.PRInfo, body string) error {
 	owner, repo := b.resolveOwnerRepo(pr)
@@ -313,13 +706,31 @@ func (b *Backend) ReplyToComment(ctx context.Context, pr *provider.PRInfo, threa
 }
 
 // ResolveComment resolves a review thread using the GitHub GraphQL API.
-// threadID must be the thread's node ID (e.g., "PRRT_...").
+// threadID is either the thread's node ID (e.g., "PRRT_...") or the ID of
+// its first comment, as GetComments reports it, which is looked up.
 // REST API cannot resolve threads — GraphQL is required.
 func (b *Backend) ResolveComment(ctx context.Context, pr *provider.PRInfo, threadID string, resolution provider.CommentResolution) error {
 	if resolution == provider.ResolutionUnknown {
 		return fmt.Errorf("invalid comment resolution: %d", resolution)
 	}
 
+	if root, err := strconv.ParseInt(threadID, 10, 64); err == nil {
+		owner, repo := b.resolveOwnerRepo(pr)
+		prNum, err := strconv.Atoi(pr.ID)
+		if err != nil {
+			return fmt.Errorf("invalid PR number: %s", pr.ID)
+		}
+		threads, err := b.getReviewThreads(ctx, owner, repo, prNum)
+		if err != nil {
+			return err
+		}
+		t, ok := threads[root]
+		if !ok {
+			return fmt.Errorf("comment %s does not start a review thread", threadID)
+		}
+		threadID = t.ID
+	}
+
 	gql := b.getGraphQLClient(ctx)
 
 	var mutation struct {
@@ -342,7 +753,9 @@ func (b *Backend) ResolveComment(ctx context.Context, pr *provider.PRInfo, threa
 }
 
 // GetBuildLogs retrieves and distills build logs for a specific workflow run,
-// focusing on failed jobs and their error output.
+// focusing on failed jobs and their error output. A run still in progress
+// includes the jobs that have failed so far, and a running job counts once
+// one of its steps has failed.
 func (b *Backend) GetBuildLogs(ctx context.Context, pr *provider.PRInfo, buildID string) (string, error) {
 	owner, repo := b.resolveOwnerRepo(pr)
 
@@ -351,32 +764,19 @@ func (b *Backend) GetBuildLogs(ctx context.Context, pr *provider.PRInfo, buildID
 		return "", fmt.Errorf("invalid build/run ID: %s", buildID)
 	}
 
-	// List all jobs for the workflow run (with pagination).
-	var allJobs []*gh.WorkflowJob
-	jobOpts := &gh.ListWorkflowJobsOptions{
-		Filter:      "latest",
-		ListOptions: gh.ListOptions{PerPage: 100},
-	}
-	for {
-		jobs, resp, err := b.client.Actions.ListWorkflowJobs(ctx, owner, repo, runID, jobOpts)
-		if err != nil {
-			return "", fmt.Errorf("failed to list workflow jobs: %w", err)
-		}
-		allJobs = append(allJobs, jobs.Jobs...)
-		if resp.NextPage == 0 {
-			break
-		}
-		jobOpts.Page = resp.NextPage
+	allJobs, err := b.listWorkflowJobs(ctx, owner, repo, runID)
+	if err != nil {
+		return "", err
 	}
 
 	var errorSummary strings.Builder
 
 	for _, job := range allJobs {
-		if job.GetConclusion() != "failure" {
+		if !jobFailed(job) {
 			continue
 		}
 
-		errorSummary.WriteString(fmt.Sprintf("=== Failed Job: %s ===\n", job.GetName()))
+		errorSummary.WriteString(fmt.Sprintf("%sJob: %s ===\n", provider.FailedLogSection, job.GetName()))
 
 		// List failed steps.
 		for _, step := range job.Steps {
@@ -385,7 +785,11 @@ func (b *Backend) GetBuildLogs(ctx context.Context, pr *provider.PRInfo, buildID
 			}
 		}
 
-		// Download per-job log.
+		// Download per-job log. A running job's log isn't available
+		// until it ends, so its failed steps are all there is.
+		if job.GetStatus() != "" && job.GetStatus() != "completed" {
+			continue
+		}
 		logURL, _, err := b.client.Actions.GetWorkflowJobLogs(ctx, owner, repo, job.GetID(), 2)
 		if err != nil {
 			slog.Warn("failed to get job log URL", "jobName", job.GetName(), "error", err)
@@ -398,7 +802,7 @@ func (b *Backend) GetBuildLogs(ctx context.Context, pr *provider.PRInfo, buildID
 			continue
 		}
 
-		// Strip ANSI codes and extract error context.
+		// Strip ANSI codes and distill failures and error context.
 		cleaned := stripANSI(logText)
 		distilled := extractErrorContext(cleaned)
 		errorSummary.WriteString(distilled)
@@ -413,6 +817,52 @@ func (b *Backend) GetBuildLogs(ctx context.Context, pr *provider.PRInfo, buildID
 	return result, nil
 }
 
+// listWorkflowJobs lists the latest jobs of the workflow run with the given
+// ID. Check runs of GitHub Actions carry their job's ID rather than the
+// run's, so an ID that is no run is looked up as a job, which is then the
+// only one listed.
+func (b *Backend) listWorkflowJobs(ctx context.Context, owner, repo string, id int64) ([]*gh.WorkflowJob, error) {
+	var allJobs []*gh.WorkflowJob
+	jobOpts := &gh.ListWorkflowJobsOptions{
+		Filter:      "latest",
+		ListOptions: gh.ListOptions{PerPage: 100},
+	}
+	for {
+		jobs, resp, err := b.client.Actions.ListWorkflowJobs(ctx, owner, repo, id, jobOpts)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound && len(allJobs) == 0 {
+				job, _, jobErr := b.client.Actions.GetWorkflowJobByID(ctx, owner, repo, id)
+				if jobErr == nil {
+					return []*gh.WorkflowJob{job}, nil
+				}
+			}
+			return nil, fmt.Errorf("failed to list workflow jobs: %w", err)
+		}
+		allJobs = append(allJobs, jobs.Jobs...)
+		if resp.NextPage == 0 {
+			return allJobs, nil
+		}
+		jobOpts.Page = resp.NextPage
+	}
+}
+
+// jobFailed reports whether a job failed, or, while it is still running,
+// whether any of its steps has.
+func jobFailed(job *gh.WorkflowJob) bool {
+	if job.GetConclusion() == "failure" {
+		return true
+	}
+	if job.GetStatus() == "completed" {
+		return false
+	}
+	for _, step := range job.Steps {
+		if step.GetConclusion() == "failure" {
+			return true
+		}
+	}
+	return false
+}
+
 // RunWorkflow returns ErrUnsupported for all workflow actions.
 // GitHub does not have equivalents for ADO-specific workflow operations:
 // - AutoComplete: GitHub has auto-merge but it works differently
@@ -422,9 +872,46 @@ func (b *Backend) RunWorkflow(ctx context.Context, pr *provider.PRInfo, action p
 	return provider.ErrUnsupported
 }
 
-// CreatePR returns ErrUnsupported — GitHub PR creation is not yet implemented.
+// CreatePR opens a pull request in the backend's repository (see
+// SetRepository). Issues in params.WorkItems are listed at the end of the
+// body, with a closing keyword when they should close on merge.
 func (b *Backend) CreatePR(ctx context.Context, params provider.CreatePRParams) (*provider.PRInfo, error) {
-	return nil, provider.ErrUnsupported
+	if b.owner == "" || b.repo == "" {
+		return nil, fmt.Errorf("repository not set on backend")
+	}
+
+	body := params.Description
+	if len(params.WorkItems) > 0 {
+		keyword := "Refs"
+		if params.ResolveWorkItems {
+			keyword = "Closes"
+		}
+		var refs []string
+		for _, id := range params.WorkItems {
+			refs = append(refs, fmt.Sprintf("%s #%s", keyword, id))
+		}
+		body = strings.TrimRight(body, "\n") + "\n\n" + strings.Join(refs, "\n")
+	}
+
+	ghPR, _, err := b.client.PullRequests.Create(ctx, b.owner, b.repo, &gh.NewPullRequest{
+		Title: gh.Ptr(params.Title),
+		Head:  gh.Ptr(params.SourceBranch),
+		Base:  gh.Ptr(params.TargetBranch),
+		Body:  gh.Ptr(body),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+	pr := b.mapPR(ghPR, b.owner, b.repo)
+	pr.WorkItems = params.WorkItems
+	if len(params.Labels) > 0 {
+		// The PR exists either way; a label that can't be added is not
+		// worth failing the submit over.
+		if _, _, err := b.client.Issues.AddLabelsToIssue(ctx, b.owner, b.repo, ghPR.GetNumber(), params.Labels); err != nil {
+			slog.Warn("failed to label PR", "prID", pr.ID, "labels", params.Labels, "error", err)
+		}
+	}
+	return pr, nil
 }
 
 // FindExistingPR returns ErrUnsupported — GitHub PR search is not yet implemented.
@@ -432,10 +919,297 @@ func (b *Backend) FindExistingPR(ctx context.Context, sourceBranch string) (*pro
 	return nil, provider.ErrUnsupported
 }
 
+// ListPRs searches open pull requests by author and repository, then fetches
+// each match for its branches. A search needs an author or a repository:
+// without either it would span all of GitHub.
+func (b *Backend) ListPRs(ctx context.Context, filter provider.PRFilter) ([]*provider.PRInfo, error) {
+	query := "is:pr is:open"
+	if filter.Author != "" {
+		query += " author:" + filter.Author
+	}
+	if filter.Label != "" {
+		query += fmt.Sprintf(" label:%q", filter.Label)
+	}
+	if filter.Repo != "" {
+		query += " repo:" + filter.Repo
+	} else if filter.Author == "" {
+		return nil, fmt.Errorf("listing GitHub PRs needs an author or a repository")
+	}
+
+	var prs []*provider.PRInfo
+	opts := &gh.SearchOptions{ListOptions: gh.ListOptions{PerPage: 100}}
+	for {
+		result, resp, err := b.client.Search.Issues(ctx, query, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search PRs: %w", err)
+		}
+		for _, issue := range result.Issues {
+			parsed, err := b.parsePRIdentifier(issue.GetHTMLURL())
+			if err != nil {
+				slog.Debug("skipping unparseable search result", "url", issue.GetHTMLURL(), "error", err)
+				continue
+			}
+			ghPR, _, err := b.client.PullRequests.Get(ctx, parsed.Owner, parsed.Repo, parsed.Number)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get PR %s: %w", issue.GetHTMLURL(), err)
+			}
+			if pr := b.mapPR(ghPR, parsed.Owner, parsed.Repo); filter.Matches(pr) {
+				prs = append(prs, pr)
+			}
+		}
+		if resp.NextPage == 0 {
+			return prs, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// MarkReady publishes a draft PR. The REST API cannot change a PR's draft
+// state, so this uses the GraphQL markPullRequestReadyForReview mutation,
+// which needs the PR's node ID.
+func (b *Backend) MarkReady(ctx context.Context, pr *provider.PRInfo) error {
+	owner, repo := b.resolveOwnerRepo(pr)
+	prNum, err := strconv.Atoi(pr.ID)
+	if err != nil {
+		return fmt.Errorf("invalid PR number: %s", pr.ID)
+	}
+	ghPR, _, err := b.client.PullRequests.Get(ctx, owner, repo, prNum)
+	if err != nil {
+		return fmt.Errorf("failed to get PR node ID: %w", err)
+	}
+
+	var mutation struct {
+		MarkPullRequestReadyForReview struct {
+			PullRequest struct {
+				IsDraft bool
+			}
+		} `graphql:"markPullRequestReadyForReview(input: $input)"`
+	}
+	input := githubv4.MarkPullRequestReadyForReviewInput{
+		PullRequestID: githubv4.ID(ghPR.GetNodeID()),
+	}
+	if err := b.getGraphQLClient(ctx).Mutate(ctx, &mutation, input, nil); err != nil {
+		return fmt.Errorf("failed to mark PR ready for review: %w", err)
+	}
+	return nil
+}
+
+// reviewEvents maps verdicts to the review events GitHub accepts.
+var reviewEvents = map[provider.ReviewVerdict]string{
+	provider.ReviewComment:        "COMMENT",
+	provider.ReviewApprove:        "APPROVE",
+	provider.ReviewRequestChanges: "REQUEST_CHANGES",
+}
+
+// SubmitReview submits a pull request review with the verdict's event and
+// body as its summary. GitHub requires a body for COMMENT and
+// REQUEST_CHANGES reviews.
+func (b *Backend) SubmitReview(ctx context.Context, pr *provider.PRInfo, verdict provider.ReviewVerdict, body string) error {
+	owner, repo := b.resolveOwnerRepo(pr)
+	prNum, err := strconv.Atoi(pr.ID)
+	if err != nil {
+		return fmt.Errorf("invalid PR number: %s", pr.ID)
+	}
+
+	review := &gh.PullRequestReviewRequest{Event: gh.Ptr(reviewEvents[verdict])}
+	if body != "" {
+		review.Body = gh.Ptr(body)
+	}
+	if _, _, err := b.client.PullRequests.CreateReview(ctx, owner, repo, prNum, review); err != nil {
+		return fmt.Errorf("failed to submit review: %w", err)
+	}
+	return nil
+}
+
+// GetFileAtIteration returns ErrUnsupported — GitHub has no PR iterations.
+func (b *Backend) GetFileAtIteration(ctx context.Context, pr *provider.PRInfo, iteration int, filePath string) (string, error) {
+	return "", provider.ErrUnsupported
+}
+
 func (b *Backend) RetryBuild(ctx context.Context, pr *provider.PRInfo, buildID string) error {
 	return provider.ErrUnsupported
 }
 
+// CancelBuild cancels the workflow run a build belongs to. buildID may be
+// the run's ID or, as GetPipelineStatus reports check runs, a job's.
+// Checks from other CI systems can't be cancelled.
+func (b *Backend) CancelBuild(ctx context.Context, pr *provider.PRInfo, buildID string) error {
+	owner, repo := b.resolveOwnerRepo(pr)
+	id, err := strconv.ParseInt(buildID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid build/run ID: %s", buildID)
+	}
+
+	runID := id
+	job, resp, err := b.client.Actions.GetWorkflowJobByID(ctx, owner, repo, id)
+	switch {
+	case err == nil:
+		runID = job.GetRunID()
+	case resp == nil || resp.StatusCode != http.StatusNotFound:
+		return fmt.Errorf("failed to look up job %s: %w", buildID, err)
+	}
+
+	resp, err = b.client.Actions.CancelWorkflowRunByID(ctx, owner, repo, runID)
+	var accepted *gh.AcceptedError
+	if err != nil && !errors.As(err, &accepted) {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return provider.ErrUnsupported
+		}
+		return fmt.Errorf("failed to cancel workflow run %d: %w", runID, err)
+	}
+	return nil
+}
+
+// CommitAuthor returns the author email of a commit in the PR's repository.
+func (b *Backend) CommitAuthor(ctx context.Context, pr *provider.PRInfo, commit string) (string, error) {
+	owner, repo := b.resolveOwnerRepo(pr)
+	c, _, err := b.client.Git.GetCommit(ctx, owner, repo, commit)
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit %s: %w", commit, err)
+	}
+	return c.GetAuthor().GetEmail(), nil
+}
+
+// PublishReleaseNotes creates the release for notes.Tag, or updates its
+// name and body if it exists.
+func (b *Backend) PublishReleaseNotes(ctx context.Context, notes provider.ReleaseNotes) (string, error) {
+	title := notes.Title
+	if title == "" {
+		title = notes.Tag
+	}
+	release := &gh.RepositoryRelease{TagName: gh.Ptr(notes.Tag), Name: gh.Ptr(title), Body: gh.Ptr(notes.Body)}
+
+	existing, resp, err := b.client.Repositories.GetReleaseByTag(ctx, b.owner, b.repo, notes.Tag)
+	switch {
+	case err == nil:
+		updated, _, err := b.client.Repositories.EditRelease(ctx, b.owner, b.repo, existing.GetID(), release)
+		if err != nil {
+			return "", fmt.Errorf("failed to update release %s: %w", notes.Tag, err)
+		}
+		return updated.GetHTMLURL(), nil
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		created, _, err := b.client.Repositories.CreateRelease(ctx, b.owner, b.repo, release)
+		if err != nil {
+			return "", fmt.Errorf("failed to create release %s: %w", notes.Tag, err)
+		}
+		return created.GetHTMLURL(), nil
+	default:
+		return "", fmt.Errorf("failed to get release %s: %w", notes.Tag, err)
+	}
+}
+
+// CreateRepository creates the private repository name, "owner/repo" or a
+// bare name for the authenticated user's, and returns its clone URL.
+func (b *Backend) CreateRepository(ctx context.Context, name string) (string, error) {
+	org, repo, ok := strings.Cut(name, "/")
+	if !ok {
+		org, repo = "", name
+	}
+	// Repositories of the authenticated user are created without an
+	// organization. An app installation can't look itself up, and only
+	// creates in organizations anyway.
+	if org != "" {
+		if user, _, err := b.client.Users.Get(ctx, ""); err == nil && strings.EqualFold(user.GetLogin(), org) {
+			org = ""
+		}
+	}
+	created, _, err := b.client.Repositories.Create(ctx, org, &gh.Repository{Name: gh.Ptr(repo), Private: gh.Ptr(true)})
+	if err != nil {
+		return "", fmt.Errorf("failed to create repository %s: %w", name, err)
+	}
+	return created.GetCloneURL(), nil
+}
+
+// maxIssuePages bounds how many pages of 100 issues ListIssues reads.
+const maxIssuePages = 5
+
+// ListIssues returns the open issues of filter.Repo (default: the
+// backend's repository) created since filter.Since, oldest first. Pull
+// requests, which GitHub lists as issues too, are left out.
+func (b *Backend) ListIssues(ctx context.Context, filter provider.IssueFilter) ([]*provider.Issue, error) {
+	owner, repo := b.owner, b.repo
+	if o, r, ok := strings.Cut(filter.Repo, "/"); ok {
+		owner, repo = o, r
+	}
+	// Since filters on update time, which is never before creation, so
+	// issues created since are all listed; older ones are dropped below.
+	opts := &gh.IssueListByRepoOptions{
+		State:       "open",
+		Sort:        "created",
+		Direction:   "asc",
+		Since:       filter.Since,
+		ListOptions: gh.ListOptions{PerPage: 100},
+	}
+	var issues []*provider.Issue
+	for page := 0; page < maxIssuePages; page++ {
+		list, resp, err := b.client.Issues.ListByRepo(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+		for _, is := range list {
+			if is.IsPullRequest() || is.GetCreatedAt().Before(filter.Since) {
+				continue
+			}
+			var labels []string
+			for _, l := range is.Labels {
+				labels = append(labels, l.GetName())
+			}
+			issues = append(issues, &provider.Issue{
+				ID:      strconv.Itoa(is.GetNumber()),
+				Repo:    owner + "/" + repo,
+				Title:   is.GetTitle(),
+				Body:    is.GetBody(),
+				URL:     is.GetHTMLURL(),
+				Author:  is.GetUser().GetLogin(),
+				Labels:  labels,
+				Created: is.GetCreatedAt().Time,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.ListOptions.Page = resp.NextPage
+	}
+	return issues, nil
+}
+
+// LabelIssue adds labels to issue.
+func (b *Backend) LabelIssue(ctx context.Context, issue *provider.Issue, labels []string) error {
+	owner, repo, number, err := b.issueRef(issue)
+	if err != nil {
+		return err
+	}
+	if _, _, err := b.client.Issues.AddLabelsToIssue(ctx, owner, repo, number, labels); err != nil {
+		return fmt.Errorf("failed to label issue #%d: %w", number, err)
+	}
+	return nil
+}
+
+// CommentOnIssue posts a comment on issue.
+func (b *Backend) CommentOnIssue(ctx context.Context, issue *provider.Issue, body string) error {
+	owner, repo, number, err := b.issueRef(issue)
+	if err != nil {
+		return err
+	}
+	if _, _, err := b.client.Issues.CreateComment(ctx, owner, repo, number, &gh.IssueComment{Body: gh.Ptr(body)}); err != nil {
+		return fmt.Errorf("failed to comment on issue #%d: %w", number, err)
+	}
+	return nil
+}
+
+// issueRef returns the owner, repository, and number of issue.
+func (b *Backend) issueRef(issue *provider.Issue) (string, string, int, error) {
+	number, err := strconv.Atoi(issue.ID)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid issue number %q", issue.ID)
+	}
+	owner, repo := b.owner, b.repo
+	if o, r, ok := strings.Cut(issue.Repo, "/"); ok {
+		owner, repo = o, r
+	}
+	return owner, repo, number, nil
+}
+
 // --- Internal helpers ---
 
 // parsePRIdentifier extracts owner, repo, and PR number from a string.
@@ -479,8 +1253,10 @@ func (b *Backend) parsePRIdentifier(id string) (*prIdentifier, error) {
 // mapPR converts a GitHub PullRequest to provider.PRInfo.
 func (b *Backend) mapPR(pr *gh.PullRequest, owner, repo string) *provider.PRInfo {
 	status := "active"
+	var mergeCommit string // until merged, merge_commit_sha is a test merge
 	if pr.GetMerged() {
 		status = "completed"
+		mergeCommit = pr.GetMergeCommitSHA()
 	} else if pr.GetState() == "closed" {
 		status = "abandoned"
 	}
@@ -497,7 +1273,28 @@ func (b *Backend) mapPR(pr *gh.PullRequest, owner, repo string) *provider.PRInfo
 		RepoID:       repo,
 		Project:      "", // GitHub doesn't use project for routing.
 		Organization: owner,
+		IsDraft:      pr.GetDraft(),
+		Fork:         pr.GetHead().GetRepo().GetFullName() != pr.GetBase().GetRepo().GetFullName(),
+		HeadCommit:   pr.GetHead().GetSHA(),
+		MergeCommit:  mergeCommit,
+		WorkItems:    closedIssues(pr.GetBody()),
+	}
+}
+
+// closingKeyword matches GitHub's closing keywords ("Fixes #12") in a PR
+// body, which link the issue to the PR and close it on merge.
+var closingKeyword = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+#(\d+)\b`)
+
+// closedIssues returns the numbers of the same-repository issues a PR body
+// closes, in order of first mention.
+func closedIssues(body string) []string {
+	var ids []string
+	for _, m := range closingKeyword.FindAllStringSubmatch(body, -1) {
+		if !slices.Contains(ids, m[1]) {
+			ids = append(ids, m[1])
+		}
 	}
+	return ids
 }
 
 // resolveOwnerRepo returns the owner and repo for API calls, preferring
@@ -526,12 +1323,55 @@ func (b *Backend) updateOverallState(status *provider.PipelineStatus, checkStatu
 	}
 }
 
+// CheckAuth verifies the credentials against the rate limit endpoint,
+// which any token can read without spending quota. Fine-grained and
+// expiring classic tokens report their expiry in a response header; a
+// GitHub App's installation tokens are renewed on their own, so theirs is
+// not reported. Without a token there is nothing to check.
+func (b *Backend) CheckAuth(ctx context.Context) (time.Time, error) {
+	if b.token == "" && b.tokens == nil {
+		return time.Time{}, nil
+	}
+	if b.tokens != nil {
+		if _, err := b.tokens.Token(); err != nil {
+			return time.Time{}, fmt.Errorf("%w: %w", provider.ErrAuthFailed, err)
+		}
+	}
+	_, resp, err := b.client.RateLimit.Get(ctx)
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			return time.Time{}, fmt.Errorf("%w: %w", provider.ErrAuthFailed, err)
+		}
+		return time.Time{}, err
+	}
+	// e.g. "2026-11-01 12:00:00 UTC"
+	if v := resp.Header.Get("GitHub-Authentication-Token-Expiration"); v != "" && b.tokens == nil {
+		if t, err := time.Parse("2006-01-02 15:04:05 MST", v); err == nil {
+			return t, nil
+		}
+		if t, err := time.Parse("2006-01-02 15:04:05 -0700", v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, nil
+}
+
 // getGraphQLClient returns (and lazily creates) the GitHub GraphQL client.
 // Thread-safe via sync.Once.
 func (b *Backend) getGraphQLClient(ctx context.Context) *githubv4.Client {
 	b.gqlOnce.Do(func() {
-		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: b.token})
+		ts := b.tokens
+		if ts == nil {
+			ts = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: b.token})
+		}
+		if b.transport != nil {
+			ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: b.transport})
+		}
 		httpClient := oauth2.NewClient(ctx, ts)
+		if b.baseURL != "" {
+			b.gqlClient = githubv4.NewEnterpriseClient(b.baseURL+"/api/graphql", httpClient)
+			return
+		}
 		b.gqlClient = githubv4.NewClient(httpClient)
 	})
 	return b.gqlClient
@@ -546,7 +1386,7 @@ func (b *Backend) downloadLog(ctx context.Context, logURL string) (string, error
 		return "", fmt.Errorf("failed to create log request: %w", err)
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: b.transport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to download log: %w", err)
@@ -571,60 +1411,10 @@ func stripANSI(s string) string {
 	return ansiPattern.ReplaceAllString(s, "")
 }
 
-// extractErrorContext extracts context windows around error markers in build logs.
-// Matches GitHub Actions "##[error]" markers for consistent behavior.
+// extractErrorContext distills a job log into a summary of recognized
+// failures followed by the log lines around them.
 func extractErrorContext(log string) string {
-	lines := strings.Split(log, "\n")
-	const contextWindow = 5
-
-	var errorIndices []int
-	for i, line := range lines {
-		if strings.Contains(line, "##[error]") {
-			errorIndices = append(errorIndices, i)
-		}
-	}
-
-	if len(errorIndices) == 0 {
-		// No error markers found; return last 50 lines as fallback.
-		start := len(lines) - 50
-		if start < 0 {
-			start = 0
-		}
-		return strings.Join(lines[start:], "\n")
-	}
-
-	// Collect unique lines within context windows around errors.
-	included := make(map[int]bool)
-	for _, idx := range errorIndices {
-		start := idx - contextWindow
-		if start < 0 {
-			start = 0
-		}
-		end := idx + contextWindow + 1
-		if end > len(lines) {
-			end = len(lines)
-		}
-		for i := start; i < end; i++ {
-			included[i] = true
-		}
-	}
-
-	var result strings.Builder
-	prevIncluded := false
-	for i, line := range lines {
-		if included[i] {
-			if !prevIncluded && i > 0 {
-				result.WriteString("...\n")
-			}
-			result.WriteString(line)
-			result.WriteString("\n")
-			prevIncluded = true
-		} else {
-			prevIncluded = false
-		}
-	}
-
-	return result.String()
+	return logdistill.Distill(log).String()
 }
 
 // Verify Backend implements PRBackend at compile time.
@@ -3,10 +3,12 @@ package github
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	gh "github.com/google/go-github/v82/github"
 	"github.com/stretchr/testify/assert"
@@ -140,6 +142,36 @@ func TestGetPR(t *testing.T) {
 	assert.Equal(t, "main", pr.TargetBranch)
 	assert.Equal(t, "testuser", pr.Author)
 	assert.Equal(t, "https://github.com/testowner/testrepo/pull/42", pr.URL)
+	assert.Equal(t, "abc123", pr.HeadCommit)
+}
+
+func TestListPRs(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/search/issues", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "is:pr is:open author:@me", r.URL.Query().Get("q"))
+		fmt.Fprint(w, `{"total_count":2,"items":[
+			{"html_url":"https://github.com/testowner/testrepo/pull/1"},
+			{"html_url":"https://github.com/testowner/other/pull/2"}]}`)
+	})
+	for path, branch := range map[string]string{"testrepo/pulls/1": "me/fix", "other/pulls/2": "feature"} {
+		mux.HandleFunc("GET /api/v3/repos/testowner/"+path, func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(gh.PullRequest{
+				Number: gh.Ptr(1),
+				State:  gh.Ptr("open"),
+				Head:   &gh.PullRequestBranch{Ref: gh.Ptr(branch)},
+			})
+		})
+	}
+	backend, _ := newTestBackend(t, mux)
+
+	prs, err := backend.ListPRs(t.Context(), provider.PRFilter{Author: "@me", BranchPrefix: "me/"})
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, "me/fix", prs[0].SourceBranch)
+	assert.Equal(t, "testrepo", prs[0].RepoID)
+
+	_, err = backend.ListPRs(t.Context(), provider.PRFilter{BranchPrefix: "me/"})
+	assert.ErrorContains(t, err, "needs an author or a repository")
 }
 
 func TestGetPR_Merged(t *testing.T) {
@@ -340,6 +372,142 @@ func TestGetPipelineStatus_NoBuilds(t *testing.T) {
 	assert.Equal(t, "pending", status.State)
 }
 
+func TestGetPipelineStatus_MergeQueue(t *testing.T) {
+	newMux := func(entry string, runs []*gh.WorkflowRun) *http.ServeMux {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(gh.PullRequest{
+				Number: gh.Ptr(1),
+				Head:   &gh.PullRequestBranch{SHA: gh.Ptr("abc123")},
+				Base:   &gh.PullRequestBranch{Ref: gh.Ptr("main")},
+			})
+		})
+		mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/commits/abc123/check-runs", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(gh.ListCheckRunsResults{Total: gh.Ptr(1), CheckRuns: []*gh.CheckRun{
+				{ID: gh.Ptr(int64(1)), Name: gh.Ptr("CI"), Status: gh.Ptr("completed"), Conclusion: gh.Ptr("success")},
+			}})
+		})
+		mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/commits/abc123/status", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(gh.CombinedStatus{State: gh.Ptr("success"), Statuses: []*gh.RepoStatus{}})
+		})
+		mux.HandleFunc("POST /api/graphql", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"data":{"repository":{"pullRequest":{"isMergeQueueEnabled":true,"mergeQueueEntry":%s}}}}`, entry)
+		})
+		mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/actions/runs", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "gh-readonly-queue/main/pr-1-abc123", r.URL.Query().Get("branch"))
+			assert.Equal(t, "merge_group", r.URL.Query().Get("event"))
+			json.NewEncoder(w).Encode(gh.WorkflowRuns{TotalCount: gh.Ptr(len(runs)), WorkflowRuns: runs})
+		})
+		return mux
+	}
+
+	t.Run("queued", func(t *testing.T) {
+		backend, _ := newTestBackend(t, newMux(`{"position":2,"state":"AWAITING_CHECKS"}`, []*gh.WorkflowRun{
+			{ID: gh.Ptr(int64(900)), WorkflowID: gh.Ptr(int64(7)), Name: gh.Ptr("CI"), Status: gh.Ptr("in_progress")},
+		}))
+		status, err := backend.GetPipelineStatus(t.Context(), &provider.PRInfo{ID: "1"})
+		require.NoError(t, err)
+		assert.Equal(t, "inProgress", status.State)
+		require.Len(t, status.Builds, 2)
+		assert.Equal(t, "merge queue: CI", status.Builds[1].Name)
+		assert.Equal(t, &provider.MergeQueueStatus{Position: 2, State: "awaiting_checks"}, status.MergeQueue)
+		assert.Equal(t, "#2, awaiting checks", status.MergeQueue.String())
+	})
+
+	t.Run("dropped after failing", func(t *testing.T) {
+		backend, _ := newTestBackend(t, newMux(`null`, []*gh.WorkflowRun{
+			{ID: gh.Ptr(int64(902)), WorkflowID: gh.Ptr(int64(7)), Name: gh.Ptr("CI"), Status: gh.Ptr("completed"), Conclusion: gh.Ptr("failure")},
+			{ID: gh.Ptr(int64(901)), WorkflowID: gh.Ptr(int64(7)), Name: gh.Ptr("CI"), Status: gh.Ptr("completed"), Conclusion: gh.Ptr("success")},
+		}))
+		status, err := backend.GetPipelineStatus(t.Context(), &provider.PRInfo{ID: "1"})
+		require.NoError(t, err)
+		assert.Equal(t, "failed", status.State)
+		require.Len(t, status.Builds, 2, "only the newest run per workflow")
+		assert.Equal(t, "902", status.Builds[1].ID)
+		assert.Equal(t, &provider.MergeQueueStatus{State: "failed"}, status.MergeQueue)
+	})
+}
+
+func TestGetPipelineStatus_CheckFilter(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gh.PullRequest{
+			Number: gh.Ptr(1),
+			Head:   &gh.PullRequestBranch{SHA: gh.Ptr("abc123")},
+			Base:   &gh.PullRequestBranch{Ref: gh.Ptr("main")},
+		})
+	})
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/commits/abc123/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gh.ListCheckRunsResults{Total: gh.Ptr(3), CheckRuns: []*gh.CheckRun{
+			{ID: gh.Ptr(int64(1)), Name: gh.Ptr("CI / build"), Status: gh.Ptr("completed"), Conclusion: gh.Ptr("success")},
+			{ID: gh.Ptr(int64(2)), Name: gh.Ptr("CI / nightly"), Status: gh.Ptr("completed"), Conclusion: gh.Ptr("failure")},
+			{ID: gh.Ptr(int64(3)), Name: gh.Ptr("coverage"), Status: gh.Ptr("completed"), Conclusion: gh.Ptr("failure")},
+		}})
+	})
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/commits/abc123/status", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gh.CombinedStatus{State: gh.Ptr("success"), Statuses: []*gh.RepoStatus{
+			{ID: gh.Ptr(int64(4)), Context: gh.Ptr("legacy/ci"), State: gh.Ptr("success")},
+		}})
+	})
+	mux.HandleFunc("POST /api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "isRequired") {
+			http.Error(w, "not found", http.StatusNotFound) // no merge queue
+			return
+		}
+		fmt.Fprint(w, `{"data":{"repository":{"pullRequest":{"commits":{"nodes":[{"commit":{"statusCheckRollup":{"contexts":{"nodes":[
+			{"name":"CI / build","isRequired":true},
+			{"name":"CI / nightly","isRequired":true},
+			{"name":"coverage","isRequired":false},
+			{"context":"legacy/ci","isRequired":true}]}}}}]}}}}}`)
+	})
+	backend, _ := newTestBackend(t, mux)
+
+	backend.SetCheckFilter(provider.CheckFilter{RequiredOnly: true})
+	status, err := backend.GetPipelineStatus(t.Context(), &provider.PRInfo{ID: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "failed", status.State)
+	names := func() []string {
+		var out []string
+		for _, b := range status.Builds {
+			out = append(out, b.Name)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"CI / build", "CI / nightly", "legacy/ci"}, names())
+
+	backend.SetCheckFilter(provider.CheckFilter{RequiredOnly: true, Exclude: []string{"*nightly"}})
+	status, err = backend.GetPipelineStatus(t.Context(), &provider.PRInfo{ID: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", status.State)
+	assert.Equal(t, []string{"CI / build", "legacy/ci"}, names())
+
+	backend.SetCheckFilter(provider.CheckFilter{Include: []string{"CI / *"}})
+	status, err = backend.GetPipelineStatus(t.Context(), &provider.PRInfo{ID: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "failed", status.State)
+	assert.Equal(t, []string{"CI / build", "CI / nightly"}, names())
+}
+
+func TestGetDiff(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/pulls/42/files", func(w http.ResponseWriter, r *http.Request) {
+		files := []*gh.CommitFile{
+			{Filename: gh.Ptr("main.go"), Status: gh.Ptr("modified"), Patch: gh.Ptr("@@ -1 +1 @@\n-old\n+new")},
+			{Filename: gh.Ptr("new.go"), Status: gh.Ptr("added"), Patch: gh.Ptr("@@ -0,0 +1 @@\n+package new")},
+			{Filename: gh.Ptr("logo.png"), Status: gh.Ptr("added")},
+		}
+		json.NewEncoder(w).Encode(files)
+	})
+	b, _ := newTestBackend(t, mux)
+
+	diff, err := b.GetDiff(t.Context(), &provider.PRInfo{ID: "42"})
+	require.NoError(t, err)
+	assert.Equal(t, "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new\n"+
+		"diff --git a/new.go b/new.go\n--- /dev/null\n+++ b/new.go\n@@ -0,0 +1 @@\n+package new\n"+
+		"diff --git a/logo.png b/logo.png\n(added, no patch available)\n", diff)
+}
+
 func TestGetComments(t *testing.T) {
 	mux := http.NewServeMux()
 
@@ -371,13 +539,29 @@ func TestGetComments(t *testing.T) {
 		json.NewEncoder(w).Encode(comments)
 	})
 
+	// Review summaries; empty bodies are skipped.
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/pulls/5/reviews", func(w http.ResponseWriter, r *http.Request) {
+		reviews := []*gh.PullRequestReview{
+			{ID: gh.Ptr(int64(401)), Body: gh.Ptr("Copilot reviewed 1 out of 1 changed files in this pull request and generated no comments."), User: &gh.User{Login: gh.Ptr("copilot-pull-request-reviewer[bot]")}},
+			{ID: gh.Ptr(int64(402)), Body: gh.Ptr(""), User: &gh.User{Login: gh.Ptr("bob")}},
+		}
+		json.NewEncoder(w).Encode(reviews)
+	})
+
+	// Thread resolution state.
+	mux.HandleFunc("POST /api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"repository":{"pullRequest":{"reviewThreads":{
+			"nodes":[{"id":"PRRT_301","isResolved":true,"comments":{"nodes":[{"databaseId":301}]}}],
+			"pageInfo":{"hasNextPage":false,"endCursor":""}}}}}}`)
+	})
+
 	backend, _ := newTestBackend(t, mux)
 	prInfo := &provider.PRInfo{ID: "5"}
 
 	comments, err := backend.GetComments(t.Context(), prInfo)
 	require.NoError(t, err)
 
-	assert.Len(t, comments, 2)
+	assert.Len(t, comments, 3)
 
 	// Issue comment.
 	assert.Equal(t, "201", comments[0].ID)
@@ -391,6 +575,43 @@ func TestGetComments(t *testing.T) {
 	assert.Equal(t, "bob", comments[1].Author)
 	assert.Equal(t, "main.go", comments[1].FilePath)
 	assert.Equal(t, 10, comments[1].Line)
+	assert.True(t, comments[1].IsResolved)
+
+	// Review summary.
+	assert.Equal(t, "401", comments[2].ID)
+	assert.Equal(t, "review", comments[2].CommentType)
+	assert.True(t, comments[2].IsResolved)
+}
+
+func TestResolveComment_ByCommentID(t *testing.T) {
+	var resolved string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string `json:"query"`
+			Variables struct {
+				Input struct {
+					ThreadID string `json:"threadId"`
+				} `json:"input"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if strings.Contains(req.Query, "resolveReviewThread") {
+			resolved = req.Variables.Input.ThreadID
+			fmt.Fprint(w, `{"data":{"resolveReviewThread":{"thread":{"isResolved":true}}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"repository":{"pullRequest":{"reviewThreads":{
+			"nodes":[{"id":"PRRT_abc","isResolved":false,"comments":{"nodes":[{"databaseId":301}]}}],
+			"pageInfo":{"hasNextPage":false,"endCursor":""}}}}}}`)
+	})
+	backend, _ := newTestBackend(t, mux)
+
+	require.NoError(t, backend.ResolveComment(t.Context(), &provider.PRInfo{ID: "5"}, "301", provider.ResolutionFixed))
+	assert.Equal(t, "PRRT_abc", resolved)
+
+	err := backend.ResolveComment(t.Context(), &provider.PRInfo{ID: "5"}, "999", provider.ResolutionFixed)
+	assert.ErrorContains(t, err, "does not start a review thread")
 }
 
 func TestPostComment(t *testing.T) {
@@ -588,6 +809,191 @@ func TestGetBuildLogs_NoFailedJobs(t *testing.T) {
 	assert.Equal(t, "No failed jobs found in workflow run.", result)
 }
 
+func TestGetBuildLogs_RunningJob(t *testing.T) {
+	mux := http.NewServeMux()
+
+	// Check runs carry job IDs, which are no workflow run.
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/actions/runs/2001/jobs", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/actions/jobs/2001", func(w http.ResponseWriter, r *http.Request) {
+		job := gh.WorkflowJob{
+			ID:     gh.Ptr(int64(2001)),
+			RunID:  gh.Ptr(int64(1000)),
+			Name:   gh.Ptr("Build"),
+			Status: gh.Ptr("in_progress"),
+			Steps: []*gh.TaskStep{
+				{Name: gh.Ptr("Compile"), Conclusion: gh.Ptr("failure")},
+				{Name: gh.Ptr("Test"), Status: gh.Ptr("in_progress")},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job)
+	})
+
+	backend, _ := newTestBackend(t, mux)
+	result, err := backend.GetBuildLogs(t.Context(), &provider.PRInfo{ID: "5"}, "2001")
+	require.NoError(t, err)
+	assert.Contains(t, result, provider.FailedLogSection+"Job: Build")
+	assert.Contains(t, result, "Failed Step: Compile")
+}
+
+func TestCancelBuild(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/actions/jobs/2001", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(gh.WorkflowJob{ID: gh.Ptr(int64(2001)), RunID: gh.Ptr(int64(1000))})
+	})
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/actions/jobs/77", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+	var canceled []string
+	mux.HandleFunc("POST /api/v3/repos/testowner/testrepo/actions/runs/{id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "1000" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		canceled = append(canceled, r.PathValue("id"))
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	backend, _ := newTestBackend(t, mux)
+	require.NoError(t, backend.CancelBuild(t.Context(), &provider.PRInfo{ID: "5"}, "2001"))
+	assert.Equal(t, []string{"1000"}, canceled, "a job's run is canceled")
+
+	// Neither a job nor a run: a check from another CI system.
+	err := backend.CancelBuild(t.Context(), &provider.PRInfo{ID: "5"}, "77")
+	assert.ErrorIs(t, err, provider.ErrUnsupported)
+}
+
+func TestCommitAuthor(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/git/commits/abc123", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(gh.Commit{SHA: gh.Ptr("abc123"), Author: &gh.CommitAuthor{Email: gh.Ptr("dev@example.com")}})
+	})
+
+	backend, _ := newTestBackend(t, mux)
+	email, err := backend.CommitAuthor(t.Context(), &provider.PRInfo{ID: "5"}, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "dev@example.com", email)
+}
+
+func TestPublishReleaseNotes(t *testing.T) {
+	var created, edited map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/releases/tags/v1.3.0", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("POST /api/v3/repos/testowner/testrepo/releases", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&created)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(gh.RepositoryRelease{ID: gh.Ptr(int64(1)), HTMLURL: gh.Ptr("https://github.com/testowner/testrepo/releases/tag/v1.3.0")})
+	})
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/releases/tags/v1.2.0", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(gh.RepositoryRelease{ID: gh.Ptr(int64(7))})
+	})
+	mux.HandleFunc("PATCH /api/v3/repos/testowner/testrepo/releases/7", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&edited)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(gh.RepositoryRelease{ID: gh.Ptr(int64(7)), HTMLURL: gh.Ptr("https://github.com/testowner/testrepo/releases/tag/v1.2.0")})
+	})
+
+	backend, _ := newTestBackend(t, mux)
+	url, err := backend.PublishReleaseNotes(t.Context(), provider.ReleaseNotes{Tag: "v1.3.0", Body: "## Features"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/testowner/testrepo/releases/tag/v1.3.0", url)
+	assert.Equal(t, "v1.3.0", created["tag_name"])
+	assert.Equal(t, "v1.3.0", created["name"], "name defaults to the tag")
+	assert.Equal(t, "## Features", created["body"])
+
+	url, err = backend.PublishReleaseNotes(t.Context(), provider.ReleaseNotes{Tag: "v1.2.0", Title: "Spring", Body: "## Fixes"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/testowner/testrepo/releases/tag/v1.2.0", url)
+	assert.Equal(t, "Spring", edited["name"])
+	assert.Equal(t, "## Fixes", edited["body"])
+}
+
+func TestCreateRepository(t *testing.T) {
+	var userRepo, orgRepo map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(gh.User{Login: gh.Ptr("me")})
+	})
+	mux.HandleFunc("POST /api/v3/user/repos", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&userRepo)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(gh.Repository{CloneURL: gh.Ptr("https://github.com/me/tool.git")})
+	})
+	mux.HandleFunc("POST /api/v3/orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&orgRepo)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(gh.Repository{CloneURL: gh.Ptr("https://github.com/acme/billing.git")})
+	})
+
+	backend, _ := newTestBackend(t, mux)
+	remote, err := backend.CreateRepository(t.Context(), "acme/billing")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/acme/billing.git", remote)
+	assert.Equal(t, "billing", orgRepo["name"])
+	assert.Equal(t, true, orgRepo["private"])
+
+	// The authenticated user's own repositories go to /user/repos.
+	remote, err = backend.CreateRepository(t.Context(), "me/tool")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/me/tool.git", remote)
+	assert.Equal(t, "tool", userRepo["name"])
+}
+
+func TestIssues(t *testing.T) {
+	since := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	var labels []string
+	var comment gh.IssueComment
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/issues", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "open", r.URL.Query().Get("state"))
+		assert.Equal(t, "2026-05-01T00:00:00Z", r.URL.Query().Get("since"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]*gh.Issue{
+			{Number: gh.Ptr(1), Title: gh.Ptr("old, recently updated"), CreatedAt: &gh.Timestamp{Time: since.Add(-time.Hour)}},
+			{Number: gh.Ptr(2), Title: gh.Ptr("a PR"), CreatedAt: &gh.Timestamp{Time: since.Add(time.Hour)}, PullRequestLinks: &gh.PullRequestLinks{}},
+			{
+				Number: gh.Ptr(3), Title: gh.Ptr("Crash on start"), Body: gh.Ptr("It crashes."),
+				HTMLURL: gh.Ptr("https://github.com/testowner/testrepo/issues/3"), User: &gh.User{Login: gh.Ptr("dev")},
+				Labels: []*gh.Label{{Name: gh.Ptr("bug")}}, CreatedAt: &gh.Timestamp{Time: since.Add(time.Hour)},
+			},
+		})
+	})
+	mux.HandleFunc("POST /api/v3/repos/testowner/testrepo/issues/3/labels", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&labels)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	})
+	mux.HandleFunc("POST /api/v3/repos/testowner/testrepo/issues/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&comment)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	})
+
+	backend, _ := newTestBackend(t, mux)
+	issues, err := backend.ListIssues(t.Context(), provider.IssueFilter{Since: since})
+	require.NoError(t, err)
+	require.Len(t, issues, 1, "older issues and PRs are left out")
+	issue := issues[0]
+	assert.Equal(t, "3", issue.ID)
+	assert.Equal(t, "testowner/testrepo", issue.Repo)
+	assert.Equal(t, "It crashes.", issue.Body)
+	assert.Equal(t, "dev", issue.Author)
+	assert.Equal(t, []string{"bug"}, issue.Labels)
+
+	require.NoError(t, backend.LabelIssue(t.Context(), issue, []string{"crash"}))
+	assert.Equal(t, []string{"crash"}, labels)
+	require.NoError(t, backend.CommentOnIssue(t.Context(), issue, "Thanks!"))
+	assert.Equal(t, "Thanks!", comment.GetBody())
+}
+
 func TestGetBuildLogs_InvalidBuildID(t *testing.T) {
 	backend := &Backend{owner: "o", repo: "r"}
 	_, err := backend.GetBuildLogs(t.Context(), &provider.PRInfo{ID: "5"}, "not-a-number")
@@ -629,6 +1035,78 @@ func TestExtractErrorContext_NoErrors(t *testing.T) {
 	assert.NotContains(t, result, "line 49: normal output")
 }
 
+func TestMarkReady(t *testing.T) {
+	var query string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/pulls/42", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gh.PullRequest{Number: gh.Ptr(42), NodeID: gh.Ptr("PR_node42"), Draft: gh.Ptr(true)})
+	})
+	mux.HandleFunc("POST /api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		query = req.Query
+		assert.Equal(t, map[string]any{"pullRequestId": "PR_node42"}, req.Variables["input"])
+		fmt.Fprint(w, `{"data":{"markPullRequestReadyForReview":{"pullRequest":{"isDraft":false}}}}`)
+	})
+	backend, _ := newTestBackend(t, mux)
+
+	require.NoError(t, backend.MarkReady(t.Context(), &provider.PRInfo{ID: "42"}))
+	assert.Contains(t, query, "markPullRequestReadyForReview")
+}
+
+func TestCreatePR(t *testing.T) {
+	var req gh.NewPullRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v3/repos/testowner/testrepo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		json.NewEncoder(w).Encode(gh.PullRequest{Number: gh.Ptr(43), Title: req.Title, Body: req.Body})
+	})
+	var labels []string
+	mux.HandleFunc("POST /api/v3/repos/testowner/testrepo/issues/43/labels", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&labels))
+		json.NewEncoder(w).Encode([]*gh.Label{})
+	})
+	backend, _ := newTestBackend(t, mux)
+
+	pr, err := backend.CreatePR(t.Context(), provider.CreatePRParams{
+		Title:            "Add retries",
+		Description:      "Retries transient failures.\n",
+		SourceBranch:     "12-retries",
+		TargetBranch:     "main",
+		WorkItems:        []string{"12"},
+		ResolveWorkItems: true,
+		Labels:           []string{"area/api", "docs"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "43", pr.ID)
+	assert.Equal(t, "12-retries", req.GetHead())
+	assert.Equal(t, "Retries transient failures.\n\nCloses #12", req.GetBody())
+	assert.Equal(t, []string{"12"}, pr.WorkItems)
+	assert.Equal(t, []string{"area/api", "docs"}, labels)
+}
+
+func TestClosedIssues(t *testing.T) {
+	assert.Equal(t, []string{"12", "7"}, closedIssues("Fixes #12, resolves: #7 and closes #12.\nSee #9."))
+	assert.Empty(t, closedIssues("Related to #3"))
+}
+
+func TestSubmitReview(t *testing.T) {
+	var review gh.PullRequestReviewRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v3/repos/testowner/testrepo/pulls/42/reviews", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&review))
+		json.NewEncoder(w).Encode(gh.PullRequestReview{ID: gh.Ptr(int64(1))})
+	})
+	backend, _ := newTestBackend(t, mux)
+
+	require.NoError(t, backend.SubmitReview(t.Context(), &provider.PRInfo{ID: "42"}, provider.ReviewApprove, "LGTM"))
+	assert.Equal(t, "APPROVE", review.GetEvent())
+	assert.Equal(t, "LGTM", review.GetBody())
+}
+
 func TestMapPR_StatusMapping(t *testing.T) {
 	b := &Backend{}
 
@@ -653,13 +1131,34 @@ func TestMapPR_StatusMapping(t *testing.T) {
 				Head:    &gh.PullRequestBranch{Ref: gh.Ptr("b")},
 				Base:    &gh.PullRequestBranch{Ref: gh.Ptr("main")},
 				User:    &gh.User{Login: gh.Ptr("u")},
+
+				MergeCommitSHA: gh.Ptr("m3rg3"),
 			}
 			result := b.mapPR(pr, "o", "r")
 			assert.Equal(t, tt.wantStatus, result.Status)
+			if tt.merged {
+				assert.Equal(t, "m3rg3", result.MergeCommit)
+			} else {
+				assert.Empty(t, result.MergeCommit, "an unmerged PR's merge commit is a test merge")
+			}
 		})
 	}
 }
 
+func TestMapPR_Fork(t *testing.T) {
+	b := &Backend{}
+	repo := func(name string) *gh.Repository { return &gh.Repository{FullName: gh.Ptr(name)} }
+	pr := &gh.PullRequest{
+		Number: gh.Ptr(1),
+		Head:   &gh.PullRequestBranch{Ref: gh.Ptr("b"), Repo: repo("o/r")},
+		Base:   &gh.PullRequestBranch{Ref: gh.Ptr("main"), Repo: repo("o/r")},
+	}
+	assert.False(t, b.mapPR(pr, "o", "r").Fork)
+
+	pr.Head.Repo = repo("contributor/r")
+	assert.True(t, b.mapPR(pr, "o", "r").Fork)
+}
+
 func TestResolveComment_InvalidResolution(t *testing.T) {
 	backend := &Backend{token: "test"}
 	err := backend.ResolveComment(t.Context(), &provider.PRInfo{ID: "1"}, "PRRT_123", provider.ResolutionUnknown)
@@ -689,6 +1188,9 @@ func TestGetComments_Pagination(t *testing.T) {
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode([]*gh.PullRequestComment{})
 	})
+	mux.HandleFunc("GET /api/v3/repos/testowner/testrepo/pulls/5/reviews", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]*gh.PullRequestReview{})
+	})
 
 	backend, _ := newTestBackend(t, mux)
 	comments, err := backend.GetComments(t.Context(), &provider.PRInfo{ID: "5"})
@@ -723,6 +1225,25 @@ func TestResolveOwnerRepo(t *testing.T) {
 	assert.Equal(t, "pr-repo", repo)
 }
 
+func TestCheckAuth(t *testing.T) {
+	status := http.StatusOK
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/rate_limit", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("GitHub-Authentication-Token-Expiration", "2026-11-01 12:00:00 UTC")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"resources":{}}`))
+	})
+	backend, _ := newTestBackend(t, mux)
+
+	expiresAt, err := backend.CheckAuth(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC), expiresAt.UTC())
+
+	status = http.StatusUnauthorized
+	_, err = backend.CheckAuth(t.Context())
+	assert.ErrorIs(t, err, provider.ErrAuthFailed)
+}
+
 // Compile-time interface check.
 func TestBackendImplementsPRBackend(t *testing.T) {
 	var _ provider.PRBackend = (*Backend)(nil)
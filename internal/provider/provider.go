@@ -3,12 +3,114 @@ package provider
 import (
 	"context"
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 )
 
 // ErrUnsupported is returned when a backend doesn't support a given operation.
 var ErrUnsupported = errors.New("operation not supported by this backend")
 
+// ErrAuthFailed is returned by CheckAuth when the provider rejects the
+// backend's credentials.
+var ErrAuthFailed = errors.New("authentication failed")
+
+// AuthChecker is implemented by backends that can validate their
+// credentials on their own, without a pull request to act on.
+type AuthChecker interface {
+	// CheckAuth verifies the credentials, returning an error wrapping
+	// ErrAuthFailed if the provider rejects them. It reports when they
+	// expire, if the provider says; otherwise the time is zero.
+	CheckAuth(ctx context.Context) (time.Time, error)
+}
+
+// CommitAuthorLookup is implemented by backends that can tell who authored
+// a commit on a pull request's branch.
+type CommitAuthorLookup interface {
+	// CommitAuthor returns the author email of commit, a SHA on pr's
+	// source branch.
+	CommitAuthor(ctx context.Context, pr *PRInfo, commit string) (string, error)
+}
+
+// ReleasePublisher is implemented by backends that can publish release
+// notes: GitHub as a release, ADO as a wiki page.
+type ReleasePublisher interface {
+	// PublishReleaseNotes creates or updates the release or page for
+	// notes.Tag and returns its web URL.
+	PublishReleaseNotes(ctx context.Context, notes ReleaseNotes) (string, error)
+}
+
+// ReleaseNotes are the notes of one release of the backend's repository.
+type ReleaseNotes struct {
+	// Tag is the release's tag, e.g. "v1.3.0". GitHub publishes the notes
+	// as the release for it, creating the tag if it doesn't exist.
+	Tag string
+	// Title is the release's name; the tag if empty.
+	Title string
+	// Body is the notes' markdown.
+	Body string
+	// Wiki is the ADO wiki to publish to; the project wiki
+	// ("<project>.wiki") if empty.
+	Wiki string
+	// WikiPath is the ADO wiki page to write; "/Release Notes/<Tag>" if
+	// empty.
+	WikiPath string
+}
+
+// RepoCreator is implemented by backends that can create repositories,
+// for scaffolding a new project.
+type RepoCreator interface {
+	// CreateRepository creates an empty private repository and returns its
+	// clone URL. name is "owner/repo" on GitHub (a bare name is the
+	// authenticated user's) and the repository name on ADO, which creates
+	// it in the backend's project.
+	CreateRepository(ctx context.Context, name string) (string, error)
+}
+
+// IssueTracker is implemented by backends that can triage their
+// repository's issues: GitHub issues, or ADO bugs.
+type IssueTracker interface {
+	// ListIssues returns the open issues matching filter, oldest first.
+	ListIssues(ctx context.Context, filter IssueFilter) ([]*Issue, error)
+
+	// LabelIssue adds labels (ADO: tags) to issue.
+	LabelIssue(ctx context.Context, issue *Issue, labels []string) error
+
+	// CommentOnIssue posts a comment on issue.
+	CommentOnIssue(ctx context.Context, issue *Issue, body string) error
+}
+
+// IssueFilter selects open issues for ListIssues. Empty fields match
+// everything.
+type IssueFilter struct {
+	// Repo is the repository: "owner/repo" on GitHub. ADO bugs belong to
+	// the project, so Repo is ignored there.
+	Repo string
+	// Since keeps issues created at or after it.
+	Since time.Time
+}
+
+// Issue is a GitHub issue or an ADO bug.
+type Issue struct {
+	// ID is the issue number (GitHub) or work item ID (ADO).
+	ID string
+	// Repo is the issue's repository, "owner/repo" on GitHub, or its
+	// project on ADO.
+	Repo    string
+	Title   string
+	Body    string
+	URL     string
+	Author  string
+	Labels  []string
+	Created time.Time
+}
+
+// FailedLogSection begins each failed task or job in the logs returned by
+// GetBuildLogs, so callers can tell whether a build still running has
+// failed anything yet.
+const FailedLogSection = "=== Failed "
+
 // AIFooter is the standard footer appended to AI-generated PR comments.
 const AIFooter = "\n\n---\n*This response was generated by AI.*"
 
@@ -34,8 +136,13 @@ type PRBackend interface {
 	GetPipelineStatus(ctx context.Context, pr *PRInfo) (*PipelineStatus, error)
 
 	// GetBuildLogs retrieves and distills build logs for a specific build, focusing on errors.
+	// For a build still running, it covers what has failed so far.
 	GetBuildLogs(ctx context.Context, pr *PRInfo, buildID string) (string, error)
 
+	// GetDiff returns the pull request's changes as a unified diff against
+	// its target branch, without needing a local checkout.
+	GetDiff(ctx context.Context, pr *PRInfo) (string, error)
+
 	// GetComments retrieves all comments/threads on a pull request.
 	GetComments(ctx context.Context, pr *PRInfo) ([]Comment, error)
 
@@ -61,8 +168,25 @@ type PRBackend interface {
 	// Returns nil, nil if no matching PR is found.
 	FindExistingPR(ctx context.Context, sourceBranch string) (*PRInfo, error)
 
+	// ListPRs returns the open pull requests matching filter.
+	ListPRs(ctx context.Context, filter PRFilter) ([]*PRInfo, error)
+
+	// MarkReady takes a draft pull request out of draft, publishing it for review.
+	MarkReady(ctx context.Context, pr *PRInfo) error
+
+	// GetFileAtIteration returns the content of filePath as of the given PR
+	// iteration, the snapshot an iteration-scoped comment refers to.
+	GetFileAtIteration(ctx context.Context, pr *PRInfo, iteration int, filePath string) (string, error)
+
 	// RetryBuild retries a failed build by its ID.
 	RetryBuild(ctx context.Context, pr *PRInfo, buildID string) error
+
+	// CancelBuild cancels a running build by its ID.
+	CancelBuild(ctx context.Context, pr *PRInfo, buildID string) error
+
+	// SubmitReview records a review of someone else's pull request: the
+	// verdict plus an optional summary body.
+	SubmitReview(ctx context.Context, pr *PRInfo, verdict ReviewVerdict, body string) error
 }
 
 // PRInfo contains metadata about a pull request.
@@ -91,6 +215,20 @@ type PRInfo struct {
 	Project string
 	// Organization is the organization name (used by ADO for API routing).
 	Organization string
+	// IsDraft is true while the pull request is a draft, not yet ready for review.
+	IsDraft bool
+	// Fork is true when the source branch lives in another repository than
+	// the target, such as a contributor's fork.
+	Fork bool
+	// HeadCommit is the latest commit on the source branch, when the
+	// provider reports it.
+	HeadCommit string
+	// MergeCommit is the commit the pull request merged as on its target
+	// branch (a merge, squash, or its last rebased commit), once merged.
+	MergeCommit string
+	// WorkItems lists the IDs of the work items (ADO) or issues (GitHub)
+	// linked to the pull request, when the provider reports them.
+	WorkItems []string
 }
 
 // CreatePRParams contains the parameters needed to create a new pull request.
@@ -103,6 +241,37 @@ type CreatePRParams struct {
 	SourceBranch string
 	// TargetBranch is the branch being merged into (e.g., "main").
 	TargetBranch string
+	// WorkItems lists work item (ADO) or issue (GitHub) IDs to link to the
+	// new pull request.
+	WorkItems []string
+	// ResolveWorkItems resolves the linked work items or issues when the
+	// pull request merges.
+	ResolveWorkItems bool
+	// Labels are labels (GitHub) or tags (ADO) to add to the new pull
+	// request.
+	Labels []string
+}
+
+// PRFilter selects open pull requests for ListPRs. Empty fields match
+// everything.
+type PRFilter struct {
+	// Author is the PR creator: a login on GitHub, a unique or display name
+	// on ADO, or "@me" for the authenticated user.
+	Author string
+	// BranchPrefix keeps PRs whose source branch, without refs/heads/,
+	// starts with it (e.g., "users/alanmeadows/").
+	BranchPrefix string
+	// Label keeps PRs carrying this label (ADO: tag).
+	Label string
+	// Repo limits the search to one repository: "owner/repo" on GitHub, the
+	// repository name on ADO.
+	Repo string
+}
+
+// Matches reports whether pr satisfies the filter's branch prefix. Author
+// and Repo are applied by the provider's search.
+func (f PRFilter) Matches(pr *PRInfo) bool {
+	return strings.HasPrefix(strings.TrimPrefix(pr.SourceBranch, "refs/heads/"), f.BranchPrefix)
 }
 
 // PipelineStatus represents the CI/CD pipeline status for a pull request.
@@ -111,6 +280,61 @@ type PipelineStatus struct {
 	State string
 	// Builds contains information about individual builds associated with the PR.
 	Builds []BuildInfo
+	// Policies lists the PR's non-build merge requirements (e.g., ADO
+	// required reviewers, linked work items, comment resolution). They do
+	// not affect State.
+	Policies []PolicyCheck
+	// MergeQueue is the PR's place in its target branch's merge queue, on
+	// providers that have them; nil when the PR is not queued and no queue
+	// run failed for its current head.
+	MergeQueue *MergeQueueStatus
+}
+
+// MergeQueueStatus describes a pull request's merge queue entry. The
+// queue's own check runs appear in PipelineStatus.Builds, and their
+// failures fail the pipeline like any other build.
+type MergeQueueStatus struct {
+	// Position is the PR's 1-based place in the queue, or 0 once the queue
+	// has dropped it.
+	Position int
+	// State is the entry's state: "queued", "awaiting_checks", "mergeable",
+	// "unmergeable", "locked", or "failed" when the queue's checks failed
+	// for the PR's current head and the queue dropped it.
+	State string
+}
+
+// String describes the entry for display, e.g. "#2, awaiting checks".
+func (q *MergeQueueStatus) String() string {
+	if q == nil {
+		return ""
+	}
+	state := strings.ReplaceAll(q.State, "_", " ")
+	if q.Position == 0 {
+		return state
+	}
+	return fmt.Sprintf("#%d, %s", q.Position, state)
+}
+
+// PolicyCheck is the evaluation of one merge requirement on a pull request.
+type PolicyCheck struct {
+	// Name describes what the requirement asks for (e.g., "work item link missing").
+	Name string
+	// Status is the evaluation state: "approved", "rejected", "running", or "queued".
+	Status string
+	// Blocking is true when the requirement must pass before the PR can complete.
+	Blocking bool
+}
+
+// PendingPolicies returns the names of blocking policies that have not
+// passed yet.
+func (s *PipelineStatus) PendingPolicies() []string {
+	var pending []string
+	for _, p := range s.Policies {
+		if p.Blocking && p.Status != "approved" {
+			pending = append(pending, p.Name)
+		}
+	}
+	return pending
 }
 
 // BuildInfo contains metadata about a single CI/CD build.
@@ -125,6 +349,48 @@ type BuildInfo struct {
 	Result string
 	// URL is the web URL to view the build.
 	URL string
+	// Required is true when the provider requires the build to pass before
+	// the PR can merge (a GitHub required check, a blocking ADO build
+	// policy). Backends only look this up when a CheckFilter needs it.
+	Required bool
+}
+
+// CheckFilter narrows the builds a backend's GetPipelineStatus reports,
+// and so the ones that decide its State, e.g. to keep optional or nightly
+// checks from triggering fixes. The zero value keeps every build.
+type CheckFilter struct {
+	// RequiredOnly keeps only builds the provider requires for merging.
+	RequiredOnly bool
+	// Include keeps only builds whose names match one of these patterns;
+	// empty keeps all. In patterns, * matches any run of characters
+	// (including "/") and ? any one character.
+	Include []string
+	// Exclude drops builds whose names match one of these patterns.
+	Exclude []string
+}
+
+// Keep reports whether the filter keeps b.
+func (f CheckFilter) Keep(b BuildInfo) bool {
+	if f.RequiredOnly && !b.Required {
+		return false
+	}
+	if len(f.Include) > 0 && !matchAnyPattern(f.Include, b.Name) {
+		return false
+	}
+	return !matchAnyPattern(f.Exclude, b.Name)
+}
+
+// matchAnyPattern reports whether name matches one of patterns.
+func matchAnyPattern(patterns []string, name string) bool {
+	for _, p := range patterns {
+		expr := regexp.QuoteMeta(p)
+		expr = strings.ReplaceAll(expr, `\*`, ".*")
+		expr = strings.ReplaceAll(expr, `\?`, ".")
+		if ok, _ := regexp.MatchString("^"+expr+"$", name); ok {
+			return true
+		}
+	}
+	return false
 }
 
 // Comment represents a comment or thread on a pull request.
@@ -137,7 +403,8 @@ type Comment struct {
 	Author string
 	// Body is the comment text content.
 	Body string
-	// CommentType indicates the type of comment ("text" for user comments, "system" for auto-generated).
+	// CommentType indicates the type of comment ("text" for user comments, "system" for auto-generated,
+	// "review" for a GitHub review's summary body, which has no thread and is reported resolved).
 	CommentType string
 	// IsResolved indicates whether the comment thread has been resolved.
 	IsResolved bool
@@ -145,6 +412,12 @@ type Comment struct {
 	FilePath string
 	// Line is the line number for inline comments (0 for general comments).
 	Line int
+	// Iteration is the PR iteration (push) whose code the comment was made
+	// on; Line refers to the file as of that iteration. 0 when unknown.
+	Iteration int
+	// ChangeTrackingID identifies the commented file's change across
+	// iterations, so it can be followed through renames. 0 when unknown.
+	ChangeTrackingID int
 	// CreatedAt is the timestamp when the comment was created.
 	CreatedAt time.Time
 }
@@ -175,6 +448,20 @@ const (
 	ResolutionByDesign
 )
 
+// String returns a lowercase name for the resolution.
+func (r CommentResolution) String() string {
+	switch r {
+	case ResolutionFixed:
+		return "fixed"
+	case ResolutionWontFix:
+		return "wontfix"
+	case ResolutionByDesign:
+		return "bydesign"
+	default:
+		return "unknown"
+	}
+}
+
 // WorkflowAction represents a workflow operation that can be performed on a pull request.
 type WorkflowAction int
 
@@ -188,3 +475,27 @@ const (
 	// WorkflowAddressBot identifies and addresses bot comments (e.g., MerlinBot) on the PR.
 	WorkflowAddressBot
 )
+
+// ReviewVerdict is the outcome of a review submitted with SubmitReview.
+type ReviewVerdict int
+
+const (
+	// ReviewComment leaves feedback without approving or blocking.
+	ReviewComment ReviewVerdict = iota
+	// ReviewApprove approves the pull request.
+	ReviewApprove
+	// ReviewRequestChanges asks the author for changes before merging.
+	ReviewRequestChanges
+)
+
+// String returns a lowercase name for the verdict.
+func (v ReviewVerdict) String() string {
+	switch v {
+	case ReviewApprove:
+		return "approve"
+	case ReviewRequestChanges:
+		return "request-changes"
+	default:
+		return "comment"
+	}
+}
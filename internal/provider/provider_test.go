@@ -0,0 +1,19 @@
+package provider_test
+
+import (
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckFilterKeep(t *testing.T) {
+	build := provider.BuildInfo{Name: "CI / unit tests"}
+	assert.True(t, provider.CheckFilter{}.Keep(build))
+	assert.False(t, provider.CheckFilter{RequiredOnly: true}.Keep(build))
+	assert.True(t, provider.CheckFilter{Include: []string{"CI*"}}.Keep(build), "* matches across /")
+	assert.False(t, provider.CheckFilter{Include: []string{"CI"}}.Keep(build), "patterns match the whole name")
+	assert.False(t, provider.CheckFilter{Exclude: []string{"* unit tests"}}.Keep(build))
+	assert.True(t, provider.CheckFilter{Exclude: []string{"C? / lint"}}.Keep(build))
+	assert.True(t, provider.CheckFilter{Include: []string{"[CI]*"}}.Keep(provider.BuildInfo{Name: "[CI] build"}), "other characters are literal")
+}
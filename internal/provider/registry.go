@@ -18,6 +18,11 @@ func (r *Registry) Register(b PRBackend) {
 	r.backends = append(r.backends, b)
 }
 
+// Backends returns the registered backends, in registration order.
+func (r *Registry) Backends() []PRBackend {
+	return r.backends
+}
+
 // Detect iterates registered backends and returns the first one whose
 // MatchesURL method returns true for the given URL.
 func (r *Registry) Detect(url string) (PRBackend, error) {
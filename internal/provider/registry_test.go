@@ -50,9 +50,27 @@ func (m *mockBackend) CreatePR(ctx context.Context, params provider.CreatePRPara
 func (m *mockBackend) FindExistingPR(ctx context.Context, sourceBranch string) (*provider.PRInfo, error) {
 	return nil, provider.ErrUnsupported
 }
+func (m *mockBackend) ListPRs(ctx context.Context, filter provider.PRFilter) ([]*provider.PRInfo, error) {
+	return nil, provider.ErrUnsupported
+}
+func (m *mockBackend) MarkReady(ctx context.Context, pr *provider.PRInfo) error {
+	return provider.ErrUnsupported
+}
+func (m *mockBackend) GetDiff(ctx context.Context, pr *provider.PRInfo) (string, error) {
+	return "", nil
+}
+func (m *mockBackend) GetFileAtIteration(ctx context.Context, pr *provider.PRInfo, iteration int, filePath string) (string, error) {
+	return "", provider.ErrUnsupported
+}
 func (m *mockBackend) RetryBuild(ctx context.Context, pr *provider.PRInfo, buildID string) error {
 	return nil
 }
+func (m *mockBackend) CancelBuild(ctx context.Context, pr *provider.PRInfo, buildID string) error {
+	return nil
+}
+func (m *mockBackend) SubmitReview(ctx context.Context, pr *provider.PRInfo, verdict provider.ReviewVerdict, body string) error {
+	return nil
+}
 
 func TestDetect(t *testing.T) {
 	reg := provider.NewRegistry()
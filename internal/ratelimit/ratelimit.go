@@ -0,0 +1,195 @@
+// Package ratelimit schedules provider API requests against shared
+// budgets. Each request is counted against a key (a provider, optionally
+// narrowed to an organization) and against the global "*" key; requests
+// wait when a key has used its configured hourly budget, when the provider
+// reports its quota down to the configured reserve, or when a 429 asked
+// for a pause. One Budgeter serves every backend in a process, so a pause
+// holds back all requests for the key instead of each retrying on its own.
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+)
+
+// Global is the key whose budget applies to every request.
+const Global = "*"
+
+// defaultRetryAfter is the pause after a 429 that does not say how long
+// to wait.
+const defaultRetryAfter = time.Minute
+
+// Budgeter tracks request budgets and provider quotas per key.
+type Budgeter struct {
+	limits map[string]config.RateLimitConfig
+	now    func() time.Time
+
+	mu     sync.Mutex
+	states map[string]*state
+}
+
+type state struct {
+	sent         []time.Time // requests in the last hour, when an hourly budget applies
+	remaining    int         // provider-reported requests left; -1 = unknown
+	reset        time.Time   // when the provider's quota resets
+	blockedUntil time.Time   // set by a 429
+}
+
+// New returns a Budgeter enforcing limits, keyed by "*", a provider name
+// ("github", "ado"), or "ado/<organization>".
+func New(limits map[string]config.RateLimitConfig) *Budgeter {
+	return &Budgeter{
+		limits: limits,
+		now:    time.Now,
+		states: make(map[string]*state),
+	}
+}
+
+// limitFor returns the limit for key, falling back from "ado/org" to "ado".
+func (b *Budgeter) limitFor(key string) config.RateLimitConfig {
+	if l, ok := b.limits[key]; ok {
+		return l
+	}
+	if provider, _, ok := strings.Cut(key, "/"); ok {
+		return b.limits[provider]
+	}
+	return config.RateLimitConfig{}
+}
+
+func (b *Budgeter) state(key string) *state {
+	st, ok := b.states[key]
+	if !ok {
+		st = &state{remaining: -1}
+		b.states[key] = st
+	}
+	return st
+}
+
+// Wait blocks until a request for key fits every budget, then counts it.
+func (b *Budgeter) Wait(ctx context.Context, key string) error {
+	for {
+		d := b.reserve(key)
+		if d <= 0 {
+			return nil
+		}
+		if d >= time.Second {
+			slog.Info("pausing provider requests to stay within rate limits", "key", key, "wait", d.Round(time.Second))
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve counts a request for key and returns 0, or returns how long to
+// wait before asking again.
+func (b *Budgeter) reserve(key string) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+
+	var wait time.Duration
+	later := func(t time.Time) {
+		if d := t.Sub(now); d > wait {
+			wait = d
+		}
+	}
+	keys := []string{key, Global}
+	for _, k := range keys {
+		st, limit := b.state(k), b.limitFor(k)
+		later(st.blockedUntil)
+		if st.remaining >= 0 && st.remaining <= limit.Reserve && st.reset.After(now) {
+			later(st.reset)
+		}
+		if limit.RequestsPerHour > 0 {
+			i := 0
+			for i < len(st.sent) && now.Sub(st.sent[i]) >= time.Hour {
+				i++
+			}
+			st.sent = st.sent[i:]
+			if len(st.sent) >= limit.RequestsPerHour {
+				later(st.sent[0].Add(time.Hour))
+			}
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	for _, k := range keys {
+		st := b.state(k)
+		if b.limitFor(k).RequestsPerHour > 0 {
+			st.sent = append(st.sent, now)
+		}
+		if st.remaining > 0 {
+			st.remaining--
+		}
+	}
+	return 0
+}
+
+// Observe updates key's quota from a response's X-RateLimit-Remaining and
+// X-RateLimit-Reset headers (sent by GitHub, and by ADO when it starts
+// delaying requests), and pauses the key after a 429.
+func (b *Budgeter) Observe(key string, resp *http.Response) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	st := b.state(key)
+
+	if v, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		st.remaining = v
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			st.reset = time.Unix(reset, 0)
+		}
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		pause := defaultRetryAfter
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			pause = time.Duration(secs) * time.Second
+		}
+		if until := now.Add(pause); until.After(st.blockedUntil) {
+			st.blockedUntil = until
+		}
+	}
+}
+
+// Transport returns an http.RoundTripper that waits on b before sending
+// each request through base (http.DefaultTransport if nil) and observes
+// the response. key names the budget a request counts against.
+func (b *Budgeter) Transport(base http.RoundTripper, key func(*http.Request) string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, budget: b, key: key}
+}
+
+type transport struct {
+	base   http.RoundTripper
+	budget *Budgeter
+	key    func(*http.Request) string
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := t.key(req)
+	if err := t.budget.Wait(req.Context(), key); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.budget.Observe(key, resp)
+	return resp, nil
+}
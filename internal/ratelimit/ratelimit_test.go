@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBudgeter(limits map[string]config.RateLimitConfig) (*Budgeter, *time.Time) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := New(limits)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestHourlyBudget(t *testing.T) {
+	b, now := newTestBudgeter(map[string]config.RateLimitConfig{
+		"ado":         {RequestsPerHour: 2},
+		"ado/contoso": {RequestsPerHour: 1},
+	})
+
+	assert.Zero(t, b.reserve("ado/fabrikam"))
+	*now = now.Add(10 * time.Minute)
+	assert.Zero(t, b.reserve("ado/fabrikam"))
+	assert.Equal(t, 50*time.Minute, b.reserve("ado/fabrikam"), "falls back to the provider budget")
+
+	assert.Zero(t, b.reserve("ado/contoso"), "organizations have their own budget")
+	assert.Equal(t, time.Hour, b.reserve("ado/contoso"))
+	assert.Zero(t, b.reserve("github"), "unlimited")
+
+	*now = now.Add(50 * time.Minute)
+	assert.Zero(t, b.reserve("ado/fabrikam"), "the oldest request left the window")
+}
+
+func TestGlobalBudget(t *testing.T) {
+	b, _ := newTestBudgeter(map[string]config.RateLimitConfig{Global: {RequestsPerHour: 1}})
+	assert.Zero(t, b.reserve("github"))
+	assert.Equal(t, time.Hour, b.reserve("ado/contoso"))
+}
+
+func TestReserve(t *testing.T) {
+	b, now := newTestBudgeter(map[string]config.RateLimitConfig{"github": {Reserve: 1}})
+	reset := now.Add(20 * time.Minute)
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	resp.Header.Set("X-RateLimit-Remaining", "2")
+	resp.Header.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	b.Observe("github", resp)
+
+	assert.Zero(t, b.reserve("github"))
+	assert.Equal(t, 20*time.Minute, b.reserve("github"), "quota down to the reserve")
+	*now = reset
+	assert.Zero(t, b.reserve("github"))
+}
+
+func TestRetryAfter(t *testing.T) {
+	b, now := newTestBudgeter(nil)
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "30")
+	b.Observe("ado/contoso", resp)
+	assert.Equal(t, 30*time.Second, b.reserve("ado/contoso"))
+	assert.Zero(t, b.reserve("ado/fabrikam"))
+
+	resp.Header.Del("Retry-After")
+	b.Observe("github", resp)
+	assert.Equal(t, defaultRetryAfter, b.reserve("github"))
+
+	*now = now.Add(time.Minute)
+	assert.Zero(t, b.reserve("github"))
+	assert.Zero(t, b.reserve("ado/contoso"))
+}
+
+func TestWaitCanceled(t *testing.T) {
+	b, _ := newTestBudgeter(map[string]config.RateLimitConfig{"github": {RequestsPerHour: 1}})
+	ctx := t.Context()
+	assert.NoError(t, b.Wait(ctx, "github"))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, b.Wait(canceled, "github"), context.Canceled)
+}
@@ -0,0 +1,202 @@
+package repo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/store"
+)
+
+// maxSlugLen bounds the length of a name derived from a description, so
+// branch and worktree names stay readable.
+const maxSlugLen = 40
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// BranchSlug derives a branch name from a description: lowercase words
+// joined by hyphens, cut at a word boundary after maxSlugLen characters.
+// "Fix the login redirect loop!" becomes "fix-the-login-redirect-loop".
+func BranchSlug(description string) string {
+	words := strings.Fields(nonSlugChars.ReplaceAllString(strings.ToLower(description), " "))
+	var slug string
+	for _, w := range words {
+		next := w
+		if slug != "" {
+			next = slug + "-" + w
+		}
+		if len(next) > maxSlugLen && slug != "" {
+			break
+		}
+		slug = next
+	}
+	if len(slug) > maxSlugLen {
+		slug = slug[:maxSlugLen]
+	}
+	return slug
+}
+
+// BranchRecord is a branch created by `otto branch new`: where its
+// worktree is, what it is for, and the spec it implements, if any.
+type BranchRecord struct {
+	Repo string `json:"repo"`
+	// Name is the branch's logical name, the {{.Name}} of the repo's
+	// branch template, and the name of its worktree.
+	Name        string    `json:"name"`
+	Branch      string    `json:"branch"`
+	WorkDir     string    `json:"work_dir"`
+	Description string    `json:"description"`
+	Spec        string    `json:"spec,omitempty"`
+	Created     time.Time `json:"created"`
+}
+
+// NewBranchOptions adjusts NewBranch.
+type NewBranchOptions struct {
+	Name string // default: BranchSlug of the description
+	Base string // default: the primary checkout's HEAD
+	// Spec, when set, is the slug of a spec to start for the branch, at
+	// .otto/specs/<slug>.md in its work directory.
+	Spec string
+}
+
+var specSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// NewBranch creates a branch for the work described by description with
+// repo's strategy — a worktree under WorktreeRoot for the worktree
+// strategy — and records it. With opts.Spec it also starts a spec stub
+// for the work, which the record links to.
+func NewBranch(repo config.RepoConfig, description string, opts NewBranchOptions) (*BranchRecord, error) {
+	name := opts.Name
+	if name == "" {
+		name = BranchSlug(description)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("cannot derive a branch name from %q; give one with --name", description)
+	}
+	if opts.Spec != "" && !specSlugPattern.MatchString(opts.Spec) {
+		return nil, fmt.Errorf("invalid spec slug %q: use lowercase letters, digits, and '-'", opts.Spec)
+	}
+	branch, err := renderBranchName(repo.BranchTemplate, name)
+	if err != nil {
+		return nil, err
+	}
+
+	workDir, err := NewStrategy(repo).CreateBranch(opts.Base, name)
+	if err != nil {
+		return nil, err
+	}
+	rec := &BranchRecord{
+		Repo:        repo.Name,
+		Name:        name,
+		Branch:      branch,
+		WorkDir:     workDir,
+		Description: description,
+		Spec:        opts.Spec,
+		Created:     time.Now().UTC(),
+	}
+	if rec.Spec != "" {
+		if err := writeSpecStub(rec); err != nil {
+			return nil, err
+		}
+	}
+	if err := RecordBranch(rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// SpecPath returns the spec file linked to rec, or "" if it has none.
+func (rec *BranchRecord) SpecPath() string {
+	if rec.Spec == "" {
+		return ""
+	}
+	return filepath.Join(rec.WorkDir, ".otto", "specs", rec.Spec+".md")
+}
+
+// writeSpecStub starts rec's spec from its description, leaving an
+// existing spec of that slug alone.
+func writeSpecStub(rec *BranchRecord) error {
+	path := rec.SpecPath()
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating specs directory: %w", err)
+	}
+	stub := fmt.Sprintf("# %s\n\nBranch: `%s`\n\n## Goal\n\n%s\n\n## Tasks\n\n- [ ] \n", rec.Spec, rec.Branch, rec.Description)
+	if err := os.WriteFile(path, []byte(stub), 0644); err != nil {
+		return fmt.Errorf("writing spec: %w", err)
+	}
+	return nil
+}
+
+// BranchesPath returns the file branch records are kept in.
+func BranchesPath() string {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		dataDir = filepath.Join(os.TempDir(), "otto")
+	}
+	return filepath.Join(dataDir, "branches.json")
+}
+
+// RecordBranch adds rec to the branch records, replacing any earlier
+// record of the same repo and name.
+func RecordBranch(rec *BranchRecord) error {
+	path := BranchesPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating data directory: %w", err)
+	}
+	return store.WithLock(path, store.DefaultLockTimeout, func() error {
+		recs, err := readBranchRecords(path)
+		if err != nil {
+			return err
+		}
+		kept := recs[:0]
+		for _, r := range recs {
+			if r.Repo != rec.Repo || r.Name != rec.Name {
+				kept = append(kept, r)
+			}
+		}
+		data, err := json.MarshalIndent(append(kept, *rec), "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling branch records: %w", err)
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return fmt.Errorf("writing branch records: %w", err)
+		}
+		return os.Rename(tmp, path)
+	})
+}
+
+// ListBranchRecords returns the recorded branches, oldest first.
+func ListBranchRecords() ([]BranchRecord, error) {
+	path := BranchesPath()
+	var recs []BranchRecord
+	err := store.WithReadLock(path, store.DefaultLockTimeout, func() error {
+		var err error
+		recs, err = readBranchRecords(path)
+		return err
+	})
+	return recs, err
+}
+
+func readBranchRecords(path string) ([]BranchRecord, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading branch records: %w", err)
+	}
+	var recs []BranchRecord
+	if err := json.Unmarshal(data, &recs); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return recs, nil
+}
@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranchSlug(t *testing.T) {
+	tests := []struct {
+		description string
+		expected    string
+	}{
+		{"Fix the login redirect loop!", "fix-the-login-redirect-loop"},
+		{"  Add OAuth2 support (GitHub) ", "add-oauth2-support-github"},
+		{"Make the dashboard remember collapsed panels across reloads", "make-the-dashboard-remember-collapsed"},
+		{"Pneumonoultramicroscopicsilicovolcanoconiosis", "pneumonoultramicroscopicsilicovolcanocon"},
+		{"!!!", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Equal(t, tt.expected, BranchSlug(tt.description))
+		})
+	}
+}
+
+func TestNewBranch(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := t.TempDir()
+	primaryDir := filepath.Join(dir, "primary")
+	require.NoError(t, os.MkdirAll(primaryDir, 0755))
+	initGitRepo(t, primaryDir)
+
+	rc := config.RepoConfig{
+		Name:           "test-repo",
+		PrimaryDir:     primaryDir,
+		GitStrategy:    config.GitStrategyWorktree,
+		BranchTemplate: "feature/{{.Name}}",
+	}
+
+	rec, err := NewBranch(rc, "Add dark mode", NewBranchOptions{Spec: "dark-mode"})
+	require.NoError(t, err)
+	assert.Equal(t, "add-dark-mode", rec.Name)
+	assert.Equal(t, "feature/add-dark-mode", rec.Branch)
+	assert.Equal(t, filepath.Join(dir, "worktrees", "add-dark-mode"), rec.WorkDir)
+
+	branch, err := getCurrentBranch(rec.WorkDir)
+	require.NoError(t, err)
+	assert.Equal(t, "feature/add-dark-mode", branch)
+
+	spec, err := os.ReadFile(rec.SpecPath())
+	require.NoError(t, err)
+	assert.Contains(t, string(spec), "Add dark mode")
+
+	recs, err := ListBranchRecords()
+	require.NoError(t, err)
+	require.Len(t, recs, 1)
+	assert.Equal(t, "dark-mode", recs[0].Spec)
+	assert.Equal(t, rec.WorkDir, recs[0].WorkDir)
+
+	_, err = NewBranch(rc, "Other", NewBranchOptions{Spec: "Not A Slug"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxCheckOutput bounds the output kept per check. The tail is kept, since
+// build and test tools summarize failures at the end.
+const maxCheckOutput = 8 << 10
+
+// CheckResult is the outcome of one of a repo's validation commands
+// (repos[].checks) run in a working directory.
+type CheckResult struct {
+	Command  string
+	Passed   bool
+	Output   string
+	Duration time.Duration
+}
+
+// RunChecks runs each command through the shell in dir and reports the
+// results in order. Every command runs even if an earlier one fails, so
+// all failures are reported at once.
+func RunChecks(ctx context.Context, dir string, commands []string) []CheckResult {
+	results := make([]CheckResult, 0, len(commands))
+	for _, command := range commands {
+		start := time.Now()
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if len(out) > maxCheckOutput {
+			out = append([]byte("[truncated] ...\n"), out[len(out)-maxCheckOutput:]...)
+		}
+		output := string(out)
+		if err != nil {
+			output = strings.TrimRight(output, "\n") + "\n" + err.Error()
+		}
+		result := CheckResult{Command: command, Passed: err == nil, Output: output, Duration: time.Since(start)}
+		slog.Info("repo check finished", "command", command, "passed", result.Passed, "duration", result.Duration.Round(time.Millisecond))
+		results = append(results, result)
+	}
+	return results
+}
+
+// FailedChecks returns the results that did not pass.
+func FailedChecks(results []CheckResult) []CheckResult {
+	var failed []CheckResult
+	for _, r := range results {
+		if !r.Passed {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// SummarizeChecks renders results on one line, e.g.
+// "1/2 passed (failed: go test ./...)".
+func SummarizeChecks(results []CheckResult) string {
+	failed := FailedChecks(results)
+	s := fmt.Sprintf("%d/%d passed", len(results)-len(failed), len(results))
+	if len(failed) > 0 {
+		cmds := make([]string, len(failed))
+		for i, r := range failed {
+			cmds[i] = r.Command
+		}
+		s += fmt.Sprintf(" (failed: %s)", strings.Join(cmds, ", "))
+	}
+	return s
+}
+
+// FormatCheckFailures renders the failed checks' output as markdown, one
+// section per command.
+func FormatCheckFailures(results []CheckResult) string {
+	var b strings.Builder
+	for _, r := range FailedChecks(results) {
+		fmt.Fprintf(&b, "#### `%s`\n\n```\n%s\n```\n\n", r.Command, strings.TrimSpace(r.Output))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// makeTarget matches a Makefile rule's target name.
+var makeTarget = regexp.MustCompile(`(?m)^([A-Za-z][\w-]*)\s*:([^=]|$)`)
+
+// DiscoverChecks guesses a repo's build, lint, and test commands from the
+// build files in dir: Makefile targets when there are any, otherwise the
+// language's own tools. It returns nil when it finds nothing it knows.
+func DiscoverChecks(dir string) []string {
+	if data, err := os.ReadFile(filepath.Join(dir, "Makefile")); err == nil {
+		targets := make(map[string]bool)
+		for _, m := range makeTarget.FindAllStringSubmatch(string(data), -1) {
+			targets[m[1]] = true
+		}
+		var checks []string
+		for _, t := range []string{"build", "lint", "test"} {
+			if targets[t] {
+				checks = append(checks, "make "+t)
+			}
+		}
+		if len(checks) > 0 {
+			return checks
+		}
+	}
+
+	switch {
+	case fileExists(dir, "go.mod"):
+		return []string{"go build ./...", "go vet ./...", "go test ./..."}
+	case fileExists(dir, "Cargo.toml"):
+		return []string{"cargo build", "cargo test"}
+	case fileExists(dir, "package.json"):
+		return nodeChecks(dir)
+	case fileExists(dir, "pyproject.toml"), fileExists(dir, "setup.py"):
+		return []string{"python -m pytest"}
+	case fileExists(dir, "pom.xml"):
+		return []string{"mvn -B verify"}
+	case fileExists(dir, "build.gradle"), fileExists(dir, "build.gradle.kts"):
+		return []string{"./gradlew build"}
+	}
+	return nil
+}
+
+// nodeChecks returns the package.json scripts among build, lint, and
+// test, run with the package manager the lock file says.
+func nodeChecks(dir string) []string {
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil || json.Unmarshal(data, &pkg) != nil {
+		return nil
+	}
+	runner := "npm run"
+	switch {
+	case fileExists(dir, "pnpm-lock.yaml"):
+		runner = "pnpm run"
+	case fileExists(dir, "yarn.lock"):
+		runner = "yarn run"
+	}
+	var checks []string
+	for _, s := range []string{"build", "lint", "test"} {
+		if _, ok := pkg.Scripts[s]; ok {
+			checks = append(checks, runner+" "+s)
+		}
+	}
+	return checks
+}
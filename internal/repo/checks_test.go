@@ -0,0 +1,47 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunChecks(t *testing.T) {
+	results := RunChecks(context.Background(), t.TempDir(), []string{"echo ok", "echo broken >&2; exit 3", "true"})
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Passed)
+	assert.Contains(t, results[0].Output, "ok")
+	assert.False(t, results[1].Passed)
+	assert.Contains(t, results[1].Output, "broken")
+	assert.Contains(t, results[1].Output, "exit status 3")
+	assert.True(t, results[2].Passed, "later checks still run")
+
+	require.Len(t, FailedChecks(results), 1)
+	assert.Equal(t, "2/3 passed (failed: echo broken >&2; exit 3)", SummarizeChecks(results))
+	assert.Contains(t, FormatCheckFailures(results), "#### `echo broken >&2; exit 3`\n\n```\nbroken\nexit status 3\n```")
+}
+
+func TestDiscoverChecks(t *testing.T) {
+	write := func(dir, name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	goRepo := t.TempDir()
+	write(goRepo, "go.mod", "module example.com/x\n")
+	assert.Equal(t, []string{"go build ./...", "go vet ./...", "go test ./..."}, DiscoverChecks(goRepo))
+
+	// Makefile targets win over the language's defaults.
+	write(goRepo, "Makefile", "VERSION := 1\nbuild: deps\n\tgo build\ntest:\n\tgo test ./...\nrelease:\n")
+	assert.Equal(t, []string{"make build", "make test"}, DiscoverChecks(goRepo))
+
+	nodeRepo := t.TempDir()
+	write(nodeRepo, "package.json", `{"scripts": {"test": "vitest", "lint": "eslint .", "start": "node ."}}`)
+	write(nodeRepo, "pnpm-lock.yaml", "")
+	assert.Equal(t, []string{"pnpm run lint", "pnpm run test"}, DiscoverChecks(nodeRepo))
+
+	assert.Nil(t, DiscoverChecks(t.TempDir()))
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/alanmeadows/otto/internal/config"
@@ -62,9 +63,7 @@ func MapPRToWorkDir(cfg *config.Config, repoURL, branchName string) (workDir str
 		}
 
 		// Fetch remote refs so the branch is available locally
-		fetchCmd := exec.Command("git", "fetch", "origin", shortBranch)
-		fetchCmd.Dir = repo.PrimaryDir
-		_ = fetchCmd.Run() // best effort — branch may already be fetched
+		_ = fetchBranch(repo, repo.PrimaryDir, shortBranch) // best effort — branch may already be fetched
 
 		// Check out an existing remote branch into a worktree
 		workDir, err = checkoutWorktree(repo, name, shortBranch)
@@ -80,9 +79,7 @@ func MapPRToWorkDir(cfg *config.Config, repoURL, branchName string) (workDir str
 
 	case config.GitStrategyBranch:
 		// Fetch and checkout the existing PR branch
-		fetchCmd := exec.Command("git", "fetch", "origin", shortBranch)
-		fetchCmd.Dir = repo.PrimaryDir
-		_ = fetchCmd.Run() // best effort
+		_ = fetchBranch(repo, repo.PrimaryDir, shortBranch) // best effort
 
 		checkoutCmd := exec.Command("git", "checkout", shortBranch)
 		checkoutCmd.Dir = repo.PrimaryDir
@@ -121,31 +118,81 @@ func checkoutWorktree(repo *config.RepoConfig, name, branchName string) (string,
 	workDir := filepath.Join(worktreeDir, name)
 
 	// First try: checkout existing local branch directly
-	cmd := exec.Command("git", "worktree", "add", workDir, branchName)
-	cmd.Dir = repo.PrimaryDir
-	if out, err := cmd.CombinedOutput(); err != nil {
+	if out, err := addWorktree(repo, workDir, branchName); err != nil {
 		// Second try: create a local branch tracking the remote ref
 		// Using -b ensures we get a proper branch (not detached HEAD).
-		cmd2 := exec.Command("git", "worktree", "add", "-b", branchName, workDir, "origin/"+branchName)
-		cmd2.Dir = repo.PrimaryDir
-		if out2, err2 := cmd2.CombinedOutput(); err2 != nil {
+		if out2, err2 := addWorktree(repo, workDir, "-b", branchName, "origin/"+branchName); err2 != nil {
 			return "", fmt.Errorf("git worktree add: %s / %s: %w",
-				strings.TrimSpace(string(out)), strings.TrimSpace(string(out2)), err2)
+				strings.TrimSpace(out), strings.TrimSpace(out2), err2)
 		}
 	}
 
 	return workDir, nil
 }
 
+// fetchBranch fetches branch from origin into the repository at dir,
+// limited to repo.FetchDepth commits when set. The refspec is explicit so
+// origin/<branch> is updated even in single-branch (e.g. shallow) clones.
+func fetchBranch(repo *config.RepoConfig, dir, branch string) error {
+	args := []string{"fetch"}
+	if repo.FetchDepth > 0 {
+		args = append(args, "--depth", strconv.Itoa(repo.FetchDepth))
+	}
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", branch, branch)
+	cmd := exec.Command("git", append(args, "origin", refspec)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git fetch %s: %s: %w", branch, strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// addWorktree runs "git worktree add" in repo's primary checkout with args,
+// which end with the commit-ish to check out, and path inserted before it.
+// When repo.SparsePaths is set the worktree is created empty, restricted to
+// those directories, and only then populated, so the full tree is never
+// written to disk. It returns git's output for error reporting.
+func addWorktree(repo *config.RepoConfig, path string, args ...string) (string, error) {
+	addArgs := []string{"worktree", "add"}
+	if len(repo.SparsePaths) > 0 {
+		addArgs = append(addArgs, "--no-checkout")
+	}
+	addArgs = append(addArgs, args[:len(args)-1]...)
+	addArgs = append(addArgs, path, args[len(args)-1])
+	cmd := exec.Command("git", addArgs...)
+	cmd.Dir = repo.PrimaryDir
+	if out, err := cmd.CombinedOutput(); err != nil || len(repo.SparsePaths) == 0 {
+		return string(out), err
+	}
+
+	for _, sparseArgs := range [][]string{
+		append([]string{"sparse-checkout", "set", "--cone"}, repo.SparsePaths...),
+		{"reset", "--hard", "--quiet"},
+	} {
+		cmd := exec.Command("git", sparseArgs...)
+		cmd.Dir = path
+		if out, err := cmd.CombinedOutput(); err != nil {
+			rmCmd := exec.Command("git", "worktree", "remove", "--force", path)
+			rmCmd.Dir = repo.PrimaryDir
+			_ = rmCmd.Run()
+			return string(out), fmt.Errorf("git %s: %w", sparseArgs[0], err)
+		}
+	}
+	return "", nil
+}
+
 // MapPRToCleanWorkDir creates a fresh temporary worktree for PR fix work.
 // Unlike MapPRToWorkDir, this always creates a clean checkout in /tmp using
 // detached HEAD, ensuring no pre-existing dirty state can leak into commits.
+// With pr.worktree_pool enabled, the branch's pooled worktree is reset and
+// reused instead; a fresh one is still created if it is in use.
 //
 // Returns:
-//   - workDir: path to the clean temporary worktree
+//   - workDir: path to the clean worktree
 //   - mergeBack: callback to update the user's existing worktree after push
 //     (best-effort; returns error if merge fails)
-//   - cleanup: callback to remove the temporary worktree (always call via defer)
+//   - cleanup: callback to remove the temporary worktree, or return the
+//     pooled one to the pool (always call via defer)
 //   - err: any error during setup
 func MapPRToCleanWorkDir(cfg *config.Config, repoURL, branchName string) (workDir string, mergeBack func() error, cleanup func(), err error) {
 	shortBranch := strings.TrimPrefix(branchName, "refs/heads/")
@@ -158,34 +205,21 @@ func MapPRToCleanWorkDir(cfg *config.Config, repoURL, branchName string) (workDi
 	}
 
 	// Fetch the branch so we have the latest remote state.
-	fetchCmd := exec.Command("git", "fetch", "origin", shortBranch)
-	fetchCmd.Dir = repo.PrimaryDir
-	_ = fetchCmd.Run() // best effort
+	_ = fetchBranch(repo, repo.PrimaryDir, shortBranch) // best effort
 
-	// Create a temp directory for the worktree.
-	tmpDir, err := os.MkdirTemp("", "otto-fix-*")
-	if err != nil {
-		return "", nil, nil, fmt.Errorf("creating temp dir: %w", err)
+	if cfg.PR.WorktreePool.Enabled {
+		workDir, cleanup, err = NewPool(cfg.PR.WorktreePool).Acquire(repo, shortBranch)
+		if err != nil {
+			slog.Info("worktree pool unavailable, using a temporary worktree", "branch", shortBranch, "reason", err)
+		}
 	}
-	// git worktree add expects the target directory not to exist.
-	os.Remove(tmpDir)
-
-	// Create a detached HEAD worktree at the branch tip.
-	// Detached HEAD avoids conflicting with the same branch being checked out
-	// in the user's existing worktree.
-	cmd := exec.Command("git", "worktree", "add", "--detach", tmpDir, "origin/"+shortBranch)
-	cmd.Dir = repo.PrimaryDir
-	if out, cmdErr := cmd.CombinedOutput(); cmdErr != nil {
-		os.RemoveAll(tmpDir)
-		return "", nil, nil, fmt.Errorf("git worktree add --detach: %s: %w",
-			strings.TrimSpace(string(out)), cmdErr)
+	if workDir == "" {
+		workDir, cleanup, err = tempWorktree(repo, shortBranch)
+		if err != nil {
+			return "", nil, nil, err
+		}
 	}
 
-	slog.Info("created clean temporary worktree for PR fix",
-		"branch", shortBranch,
-		"tmpDir", tmpDir,
-	)
-
 	// Identify the user's existing worktree/checkout for merge-back.
 	userWorkDir := findUserWorkDir(repo, shortBranch)
 	if userWorkDir != "" {
@@ -217,7 +251,33 @@ func MapPRToCleanWorkDir(cfg *config.Config, repoURL, branchName string) (workDi
 		return nil
 	}
 
-	// cleanup removes the temporary worktree.
+	return workDir, mergeBack, cleanup, nil
+}
+
+// tempWorktree creates a detached worktree at origin/<branch> in a new
+// temporary directory, with a cleanup callback that removes it.
+func tempWorktree(repo *config.RepoConfig, shortBranch string) (workDir string, cleanup func(), err error) {
+	tmpDir, err := os.MkdirTemp("", "otto-fix-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating temp dir: %w", err)
+	}
+	// git worktree add expects the target directory not to exist.
+	os.Remove(tmpDir)
+
+	// Create a detached HEAD worktree at the branch tip.
+	// Detached HEAD avoids conflicting with the same branch being checked out
+	// in the user's existing worktree.
+	if out, cmdErr := addWorktree(repo, tmpDir, "--detach", "origin/"+shortBranch); cmdErr != nil {
+		os.RemoveAll(tmpDir)
+		return "", nil, fmt.Errorf("git worktree add --detach: %s: %w",
+			strings.TrimSpace(out), cmdErr)
+	}
+
+	slog.Info("created clean temporary worktree for PR fix",
+		"branch", shortBranch,
+		"tmpDir", tmpDir,
+	)
+
 	primaryDir := repo.PrimaryDir
 	cleanup = func() {
 		rmCmd := exec.Command("git", "worktree", "remove", tmpDir, "--force")
@@ -225,8 +285,7 @@ func MapPRToCleanWorkDir(cfg *config.Config, repoURL, branchName string) (workDi
 		_ = rmCmd.Run()
 		os.RemoveAll(tmpDir) // belt and suspenders
 	}
-
-	return tmpDir, mergeBack, cleanup, nil
+	return tmpDir, cleanup, nil
 }
 
 // findUserWorkDir locates the user's existing checkout for a branch,
@@ -238,11 +297,7 @@ func findUserWorkDir(repo *config.RepoConfig, shortBranch string) string {
 		if err != nil {
 			name = shortBranch
 		}
-		wtDir := repo.WorktreeDir
-		if wtDir == "" {
-			wtDir = filepath.Join(filepath.Dir(repo.PrimaryDir), "worktrees")
-		}
-		return verifyDirOnBranch(filepath.Join(wtDir, name), shortBranch)
+		return verifyDirOnBranch(filepath.Join(WorktreeRoot(*repo), name), shortBranch)
 
 	case config.GitStrategyBranch, config.GitStrategyHandsOff:
 		return verifyDirOnBranch(repo.PrimaryDir, shortBranch)
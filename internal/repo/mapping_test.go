@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/alanmeadows/otto/internal/config"
@@ -220,3 +221,56 @@ func TestMapPRToWorkDir_RepoNotFound(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "finding repo")
 }
+
+func TestMapPRToCleanWorkDir_ShallowSparse(t *testing.T) {
+	dir := t.TempDir()
+	bareDir := initBareRemote(t, dir)
+
+	// Push a PR branch with two commits touching two directories.
+	seedDir := filepath.Join(dir, "seed")
+	require.NoError(t, os.MkdirAll(filepath.Join(seedDir, "src"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(seedDir, "docs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(seedDir, "src", "main.go"), []byte("package main\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(seedDir, "docs", "big.md"), []byte("docs\n"), 0644))
+	for _, args := range [][]string{
+		{"checkout", "-b", "otto/sparse"},
+		{"add", "."},
+		{"commit", "-m", "one"},
+		{"commit", "--allow-empty", "-m", "two"},
+		{"push", bareDir, "otto/sparse"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = seedDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, string(out))
+	}
+
+	// A single-branch, depth-1 clone stands in for a large repo's primary checkout.
+	primaryDir := filepath.Join(dir, "primary")
+	out, err := exec.Command("git", "clone", "--depth", "1", "file://"+bareDir, primaryDir).CombinedOutput()
+	require.NoError(t, err, "clone: %s", string(out))
+
+	cfg := &config.Config{
+		Repos: []config.RepoConfig{{
+			Name:        "test-repo",
+			PrimaryDir:  primaryDir,
+			GitStrategy: config.GitStrategyBranch,
+			FetchDepth:  1,
+			SparsePaths: []string{"src"},
+		}},
+	}
+
+	workDir, _, cleanup, err := MapPRToCleanWorkDir(cfg, "file://"+bareDir, "refs/heads/otto/sparse")
+	require.NoError(t, err)
+	defer cleanup()
+
+	assert.FileExists(t, filepath.Join(workDir, "README"), "top-level files are always checked out")
+	assert.FileExists(t, filepath.Join(workDir, "src", "main.go"))
+	assert.NoDirExists(t, filepath.Join(workDir, "docs"))
+
+	count := exec.Command("git", "rev-list", "--count", "HEAD")
+	count.Dir = workDir
+	out, err = count.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "1", strings.TrimSpace(string(out)), "only the fetched depth is present")
+}
@@ -0,0 +1,333 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/store"
+)
+
+// poolLeaseTimeout is how long a worktree may stay checked out of the pool
+// before it is presumed abandoned (e.g. the daemon died mid-fix) and handed
+// out again. It is well above the longest per-operation deadline.
+const poolLeaseTimeout = 2 * time.Hour
+
+// errPoolBusy is returned by Acquire when the branch's worktree is already
+// leased; callers fall back to a throwaway worktree.
+var errPoolBusy = errors.New("pooled worktree in use")
+
+// Pool caches detached worktrees per repository and branch, so repeated fix
+// operations on a PR reuse one checkout (and its ignored build outputs)
+// instead of creating and deleting a worktree each time. Worktrees idle for
+// longer than MaxIdle are removed, and least recently used ones are removed
+// while the pool exceeds MaxBytes.
+type Pool struct {
+	Dir      string
+	MaxBytes int64 // 0 = no size bound
+	MaxIdle  time.Duration
+}
+
+// poolEntry records one pooled worktree.
+type poolEntry struct {
+	RepoDir  string    `json:"repo_dir"` // primary checkout that owns the worktree
+	Branch   string    `json:"branch"`
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"last_used"`
+	LeasedAt time.Time `json:"leased_at,omitzero"` // zero when not checked out
+}
+
+func (e *poolEntry) leased(now time.Time) bool {
+	return !e.LeasedAt.IsZero() && now.Sub(e.LeasedAt) < poolLeaseTimeout
+}
+
+// poolIndex is the on-disk map of "<repo>/<branch>" keys to entries.
+type poolIndex struct {
+	Entries map[string]*poolEntry `json:"entries"`
+}
+
+// PoolDir returns the default pool location under the otto data directory.
+func PoolDir() string {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		dataDir = filepath.Join(os.TempDir(), "otto")
+	}
+	return filepath.Join(dataDir, "worktree-pool")
+}
+
+// NewPool returns a pool in the default location bounded by cfg.
+func NewPool(cfg config.WorktreePoolConfig) *Pool {
+	return &Pool{Dir: PoolDir(), MaxBytes: cfg.MaxBytes(), MaxIdle: cfg.ParseMaxIdle()}
+}
+
+func (p *Pool) indexPath() string { return filepath.Join(p.Dir, "index.json") }
+
+var unsafePoolChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func poolKey(repo *config.RepoConfig, branch string) string {
+	return repo.Name + "/" + branch
+}
+
+func (p *Pool) entryPath(repo *config.RepoConfig, branch string) string {
+	return filepath.Join(p.Dir, unsafePoolChars.ReplaceAllString(repo.Name, "_"), unsafePoolChars.ReplaceAllString(branch, "_"))
+}
+
+// Acquire leases the pooled worktree for branch, creating it if needed, and
+// resets it to origin/<branch> with a detached HEAD and no tracked or
+// untracked changes. Ignored files (build outputs, dependency caches) are
+// kept. The caller fetches origin/<branch> first and must call release when
+// done; release returns the worktree to the pool and collects stale ones.
+func (p *Pool) Acquire(repo *config.RepoConfig, branch string) (workDir string, release func(), err error) {
+	key := poolKey(repo, branch)
+	path := p.entryPath(repo, branch)
+	now := time.Now().UTC()
+
+	var reuse bool
+	err = store.WithLock(p.indexPath(), store.DefaultLockTimeout, func() error {
+		idx, err := p.readIndex()
+		if err != nil {
+			return err
+		}
+		e := idx.Entries[key]
+		if e != nil && e.leased(now) {
+			return errPoolBusy
+		}
+		if e == nil {
+			e = &poolEntry{RepoDir: repo.PrimaryDir, Branch: branch, Path: path}
+			idx.Entries[key] = e
+		} else {
+			reuse = true
+		}
+		e.LeasedAt = now
+		return p.writeIndex(idx)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	if reuse {
+		if err := resetPooledWorktree(path, branch); err != nil {
+			slog.Warn("pooled worktree unusable, recreating", "path", path, "error", err)
+			reuse = false
+		}
+	}
+	if !reuse {
+		removePooledWorktree(repo.PrimaryDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			p.forget(key)
+			return "", nil, fmt.Errorf("creating worktree pool dir: %w", err)
+		}
+		if out, cmdErr := addWorktree(repo, path, "--detach", "origin/"+branch); cmdErr != nil {
+			p.forget(key)
+			return "", nil, fmt.Errorf("git worktree add --detach: %s: %w", strings.TrimSpace(out), cmdErr)
+		}
+	}
+
+	slog.Info("leased pooled worktree", "branch", branch, "path", path, "reused", reuse)
+	release = func() {
+		if err := p.release(key, dirSize(path)); err != nil {
+			slog.Warn("failed to return worktree to pool", "path", path, "error", err)
+		}
+	}
+	return path, release, nil
+}
+
+// Discard removes the pooled worktree for branch, if any, e.g. once its PR
+// is closed. A worktree that is currently leased is left in place; the idle
+// limit collects it later.
+func (p *Pool) Discard(repo *config.RepoConfig, branch string) error {
+	key := poolKey(repo, branch)
+	var discarded *poolEntry
+	err := p.update(func(idx *poolIndex) {
+		if e := idx.Entries[key]; e != nil && !e.leased(time.Now().UTC()) {
+			discarded = e
+			delete(idx.Entries, key)
+		}
+	})
+	if discarded != nil {
+		slog.Info("removing pooled worktree", "branch", branch, "path", discarded.Path)
+		removePooledWorktree(discarded.RepoDir, discarded.Path)
+	}
+	return err
+}
+
+// Prune removes idle and over-budget worktrees from the pool.
+func (p *Pool) Prune() error {
+	return p.update(func(idx *poolIndex) {})
+}
+
+func (p *Pool) release(key string, size int64) error {
+	return p.update(func(idx *poolIndex) {
+		if e := idx.Entries[key]; e != nil {
+			e.Size = size
+			e.LastUsed = time.Now().UTC()
+			e.LeasedAt = time.Time{}
+		}
+	})
+}
+
+// forget drops key from the index after its worktree could not be created.
+func (p *Pool) forget(key string) {
+	_ = store.WithLock(p.indexPath(), store.DefaultLockTimeout, func() error {
+		idx, err := p.readIndex()
+		if err != nil {
+			return err
+		}
+		delete(idx.Entries, key)
+		return p.writeIndex(idx)
+	})
+}
+
+// update applies fn to the index, then evicts stale entries. Evicted
+// worktrees are removed after the index lock is released, since git may
+// take a while.
+func (p *Pool) update(fn func(idx *poolIndex)) error {
+	var evicted []*poolEntry
+	err := store.WithLock(p.indexPath(), store.DefaultLockTimeout, func() error {
+		idx, err := p.readIndex()
+		if err != nil {
+			return err
+		}
+		fn(idx)
+		evicted = p.evict(idx, time.Now().UTC())
+		return p.writeIndex(idx)
+	})
+	for _, e := range evicted {
+		slog.Info("removing pooled worktree", "branch", e.Branch, "path", e.Path, "lastUsed", e.LastUsed)
+		removePooledWorktree(e.RepoDir, e.Path)
+	}
+	return err
+}
+
+// evict drops entries idle longer than MaxIdle, then least recently used
+// entries until the pool fits in MaxBytes, and returns them. Leased
+// entries are never evicted. Callers hold the index lock.
+func (p *Pool) evict(idx *poolIndex, now time.Time) []*poolEntry {
+	var evicted []*poolEntry
+	var idle []string
+	var total int64
+	for k, e := range idx.Entries {
+		if e.leased(now) {
+			total += e.Size
+			continue
+		}
+		if p.MaxIdle > 0 && now.Sub(e.LastUsed) > p.MaxIdle {
+			evicted = append(evicted, e)
+			delete(idx.Entries, k)
+			continue
+		}
+		idle = append(idle, k)
+		total += e.Size
+	}
+	if p.MaxBytes <= 0 || total <= p.MaxBytes {
+		return evicted
+	}
+
+	sort.Slice(idle, func(i, j int) bool {
+		return idx.Entries[idle[i]].LastUsed.Before(idx.Entries[idle[j]].LastUsed)
+	})
+	for _, k := range idle {
+		if total <= p.MaxBytes {
+			break
+		}
+		e := idx.Entries[k]
+		evicted = append(evicted, e)
+		delete(idx.Entries, k)
+		total -= e.Size
+	}
+	return evicted
+}
+
+func (p *Pool) readIndex() (*poolIndex, error) {
+	idx := &poolIndex{Entries: make(map[string]*poolEntry)}
+	data, err := os.ReadFile(p.indexPath())
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading worktree pool index: %w", err)
+	}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("parsing worktree pool index: %w", err)
+	}
+	if idx.Entries == nil {
+		idx.Entries = make(map[string]*poolEntry)
+	}
+	return idx, nil
+}
+
+func (p *Pool) writeIndex(idx *poolIndex) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling worktree pool index: %w", err)
+	}
+	tmp := p.indexPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing worktree pool index: %w", err)
+	}
+	return os.Rename(tmp, p.indexPath())
+}
+
+// resetPooledWorktree discards whatever a previous operation left in the
+// worktree at path (an interrupted rebase, local commits, edits, untracked
+// files) and moves it to origin/<branch>.
+func resetPooledWorktree(path, branch string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	for _, args := range [][]string{{"rebase", "--abort"}, {"merge", "--abort"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = path
+		_ = cmd.Run() // nothing in progress is the common case
+	}
+	for _, args := range [][]string{
+		{"checkout", "--force", "--detach", "origin/" + branch},
+		{"clean", "-fd"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = path
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s: %s: %w", args[0], strings.TrimSpace(string(out)), err)
+		}
+	}
+	return nil
+}
+
+// removePooledWorktree unregisters and deletes the worktree at path.
+func removePooledWorktree(repoDir, path string) {
+	rmCmd := exec.Command("git", "worktree", "remove", "--force", path)
+	rmCmd.Dir = repoDir
+	_ = rmCmd.Run()
+	os.RemoveAll(path) // belt and suspenders
+	pruneCmd := exec.Command("git", "worktree", "prune")
+	pruneCmd.Dir = repoDir
+	_ = pruneCmd.Run()
+}
+
+// dirSize returns the total size of regular files under dir.
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
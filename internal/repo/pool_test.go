@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_AcquireReusesAndResets(t *testing.T) {
+	dir := t.TempDir()
+	bareDir := initBareRemote(t, dir)
+	primaryDir := filepath.Join(dir, "primary")
+	out, err := exec.Command("git", "clone", bareDir, primaryDir).CombinedOutput()
+	require.NoError(t, err, "clone: %s", string(out))
+	for _, args := range [][]string{{"branch", "otto/pooled"}, {"push", "origin", "otto/pooled"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = primaryDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, string(out))
+	}
+
+	repo := &config.RepoConfig{Name: "test-repo", PrimaryDir: primaryDir}
+	pool := &Pool{Dir: filepath.Join(dir, "pool"), MaxIdle: time.Hour}
+
+	workDir, release, err := pool.Acquire(repo, "otto/pooled")
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(workDir, "README"))
+
+	_, _, err = pool.Acquire(repo, "otto/pooled")
+	assert.ErrorIs(t, err, errPoolBusy, "a leased worktree is not handed out twice")
+
+	// Leave the kind of state a fix attempt leaves behind.
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "README"), []byte("edited"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "scratch.txt"), []byte("x"), 0644))
+	release()
+
+	again, release, err := pool.Acquire(repo, "otto/pooled")
+	require.NoError(t, err)
+	assert.Equal(t, workDir, again)
+	data, err := os.ReadFile(filepath.Join(again, "README"))
+	require.NoError(t, err)
+	assert.Equal(t, "init", string(data))
+	assert.NoFileExists(t, filepath.Join(again, "scratch.txt"))
+
+	require.NoError(t, pool.Discard(repo, "otto/pooled"))
+	assert.DirExists(t, workDir, "a leased worktree is not discarded")
+	release()
+	require.NoError(t, pool.Discard(repo, "otto/pooled"))
+	assert.NoDirExists(t, workDir)
+}
+
+func TestPool_Evict(t *testing.T) {
+	now := time.Now().UTC()
+	pool := &Pool{MaxBytes: 100, MaxIdle: time.Hour}
+	idx := &poolIndex{Entries: map[string]*poolEntry{
+		"r/stale":  {Branch: "stale", Size: 10, LastUsed: now.Add(-2 * time.Hour)},
+		"r/leased": {Branch: "leased", Size: 40, LastUsed: now.Add(-3 * time.Hour), LeasedAt: now},
+		"r/older":  {Branch: "older", Size: 30, LastUsed: now.Add(-30 * time.Minute)},
+		"r/newer":  {Branch: "newer", Size: 50, LastUsed: now.Add(-time.Minute)},
+	}}
+
+	evicted := pool.evict(idx, now)
+
+	var branches []string
+	for _, e := range evicted {
+		branches = append(branches, e.Branch)
+	}
+	assert.ElementsMatch(t, []string{"stale", "older"}, branches)
+	assert.Contains(t, idx.Entries, "r/leased", "leased worktrees are never evicted")
+	assert.Contains(t, idx.Entries, "r/newer")
+}
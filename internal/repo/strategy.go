@@ -31,6 +31,9 @@ type BranchInfo struct {
 // TemplateData provides data for branch name templating.
 type TemplateData struct {
 	Name string
+	// User is the local part of the git user's email, for conventions
+	// like "users/{{.User}}/{{.Name}}".
+	User string
 }
 
 // NewStrategy creates the appropriate strategy for a repo config.
@@ -57,7 +60,7 @@ func renderBranchName(tmpl, name string) (string, error) {
 		return "", fmt.Errorf("parsing branch template: %w", err)
 	}
 	var buf bytes.Buffer
-	if err := t.Execute(&buf, TemplateData{Name: name}); err != nil {
+	if err := t.Execute(&buf, TemplateData{Name: name, User: BranchUser()}); err != nil {
 		return "", fmt.Errorf("executing branch template: %w", err)
 	}
 	return buf.String(), nil
@@ -72,7 +75,8 @@ func ReverseBranchTemplate(tmpl, fullBranch string) (string, error) {
 
 	// Replace {{.Name}} or {{.name}} with a marker to split on
 	const marker = "\x00CAPTURE\x00"
-	pattern := strings.Replace(tmpl, "{{.Name}}", marker, 1)
+	pattern := strings.ReplaceAll(tmpl, "{{.User}}", BranchUser())
+	pattern = strings.Replace(pattern, "{{.Name}}", marker, 1)
 	pattern = strings.Replace(pattern, "{{.name}}", marker, 1)
 
 	parts := strings.SplitN(pattern, marker, 2)
@@ -102,6 +106,30 @@ func ReverseBranchTemplate(tmpl, fullBranch string) (string, error) {
 	return name, nil
 }
 
+// BranchUser returns the local part of the git user's email, lowercased,
+// or the login name when git has no email configured.
+func BranchUser() string {
+	user := os.Getenv("USER")
+	if user == "" {
+		user = os.Getenv("USERNAME")
+	}
+	if out, err := exec.Command("git", "config", "user.email").Output(); err == nil {
+		if local, _, _ := strings.Cut(strings.TrimSpace(string(out)), "@"); local != "" {
+			user = local
+		}
+	}
+	return strings.ToLower(user)
+}
+
+// WorktreeRoot returns the directory a repo's worktrees are created in:
+// its WorktreeDir, or "worktrees" beside its primary checkout.
+func WorktreeRoot(repo config.RepoConfig) string {
+	if repo.WorktreeDir != "" {
+		return repo.WorktreeDir
+	}
+	return filepath.Join(filepath.Dir(repo.PrimaryDir), "worktrees")
+}
+
 // --- WorktreeStrategy ---
 
 // WorktreeStrategy manages branches using git worktrees.
@@ -115,11 +143,7 @@ func (s *WorktreeStrategy) CreateBranch(baseBranch, name string) (string, error)
 		return "", err
 	}
 
-	worktreeDir := s.repo.WorktreeDir
-	if worktreeDir == "" {
-		worktreeDir = filepath.Join(filepath.Dir(s.repo.PrimaryDir), "worktrees")
-	}
-	workDir := filepath.Join(worktreeDir, name)
+	workDir := filepath.Join(WorktreeRoot(s.repo), name)
 
 	args := []string{"worktree", "add", workDir, "-b", branchName}
 	if baseBranch != "" {
@@ -136,11 +160,7 @@ func (s *WorktreeStrategy) CreateBranch(baseBranch, name string) (string, error)
 }
 
 func (s *WorktreeStrategy) SwitchTo(name string) (string, error) {
-	worktreeDir := s.repo.WorktreeDir
-	if worktreeDir == "" {
-		worktreeDir = filepath.Join(filepath.Dir(s.repo.PrimaryDir), "worktrees")
-	}
-	workDir := filepath.Join(worktreeDir, name)
+	workDir := filepath.Join(WorktreeRoot(s.repo), name)
 
 	if _, err := os.Stat(workDir); err != nil {
 		return "", fmt.Errorf("worktree %q does not exist: %w", workDir, err)
@@ -150,11 +170,7 @@ func (s *WorktreeStrategy) SwitchTo(name string) (string, error) {
 }
 
 func (s *WorktreeStrategy) Remove(name string, force bool) error {
-	worktreeDir := s.repo.WorktreeDir
-	if worktreeDir == "" {
-		worktreeDir = filepath.Join(filepath.Dir(s.repo.PrimaryDir), "worktrees")
-	}
-	workDir := filepath.Join(worktreeDir, name)
+	workDir := filepath.Join(WorktreeRoot(s.repo), name)
 
 	args := []string{"worktree", "remove", workDir}
 	if force {
@@ -199,7 +215,8 @@ func parseWorktreeList(output string) []BranchInfo {
 			continue
 		}
 		if strings.HasPrefix(line, "worktree ") {
-			current.WorkDir = strings.TrimPrefix(line, "worktree ")
+			// Git for Windows prints paths with forward slashes.
+			current.WorkDir = filepath.FromSlash(strings.TrimPrefix(line, "worktree "))
 		}
 		if strings.HasPrefix(line, "branch ") {
 			ref := strings.TrimPrefix(line, "branch ")
@@ -1,9 +1,17 @@
 package repo
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
 )
 
 // DirtyCheck checks if a working directory has uncommitted changes.
@@ -17,3 +25,176 @@ func DirtyCheck(workDir string) (bool, error) {
 	}
 	return strings.TrimSpace(string(out)) != "", nil
 }
+
+// GitState is the git state of a working directory.
+type GitState struct {
+	Branch   string `json:"branch"` // "(detached)" for a detached HEAD
+	Upstream string `json:"upstream,omitempty"`
+	Ahead    int    `json:"ahead"`  // commits not on the upstream
+	Behind   int    `json:"behind"` // upstream commits not in the branch
+	Dirty    bool   `json:"dirty"`  // modified, staged, or untracked files
+	// Error says why the state couldn't be read (not a git checkout, or
+	// git timed out); the other fields are then empty.
+	Error string `json:"error,omitempty"`
+}
+
+// ReadGitState reads dir's branch, its distance from its upstream, and
+// whether it has uncommitted changes, with one git status.
+func ReadGitState(ctx context.Context, dir string) GitState {
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain=v2", "--branch")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if ctx.Err() != nil {
+		return GitState{Error: "git status timed out"}
+	}
+	if err != nil {
+		return GitState{Error: fmt.Sprintf("git status: %v", err)}
+	}
+	return parseGitState(string(out))
+}
+
+// parseGitState parses the output of git status --porcelain=v2 --branch.
+func parseGitState(output string) GitState {
+	var st GitState
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "# branch.head "):
+			st.Branch = strings.TrimPrefix(line, "# branch.head ")
+		case strings.HasPrefix(line, "# branch.upstream "):
+			st.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			// "# branch.ab +<ahead> -<behind>"
+			fields := strings.Fields(strings.TrimPrefix(line, "# branch.ab "))
+			if len(fields) == 2 {
+				st.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				st.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+			}
+		case strings.HasPrefix(line, "# "):
+		default:
+			st.Dirty = true
+		}
+	}
+	return st
+}
+
+// Worktree is a working directory of a configured repo.
+type Worktree struct {
+	Repo string `json:"repo"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Spec is the spec linked to the worktree by otto branch new.
+	Spec string `json:"spec,omitempty"`
+	GitState
+}
+
+// maxStatusJobs bounds how many git status commands ListWorktrees runs
+// at once.
+const maxStatusJobs = 8
+
+// ListWorktrees lists the working directories of repos — the directories
+// under each repo's WorktreeRoot, its primary checkout when it has no
+// WorktreeDir, and worktrees created by otto branch new — and reads their
+// git state concurrently, giving each directory up to timeout.
+func ListWorktrees(ctx context.Context, repos []config.RepoConfig, timeout time.Duration) []Worktree {
+	var wts []Worktree
+	for _, rc := range repos {
+		if rc.WorktreeDir == "" {
+			wts = append(wts, Worktree{Repo: rc.Name, Name: rc.Name, Path: rc.PrimaryDir})
+			if rc.GitStrategy != config.GitStrategyWorktree {
+				continue
+			}
+		}
+		root := WorktreeRoot(rc)
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				wts = append(wts, Worktree{Repo: rc.Name, Name: e.Name(), Path: filepath.Join(root, e.Name())})
+			}
+		}
+	}
+	wts = withBranchRecords(wts)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxStatusJobs)
+	for i := range wts {
+		wg.Add(1)
+		go func(wt *Worktree) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			dirCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			wt.GitState = ReadGitState(dirCtx, wt.Path)
+		}(&wts[i])
+	}
+	wg.Wait()
+	return wts
+}
+
+// withBranchRecords links worktrees created by otto branch new to their
+// specs, and adds those outside a configured worktree dir.
+func withBranchRecords(wts []Worktree) []Worktree {
+	recs, err := ListBranchRecords()
+	if err != nil {
+		return wts
+	}
+	for _, rec := range recs {
+		listed := false
+		for i := range wts {
+			if wts[i].Path == rec.WorkDir {
+				wts[i].Spec = rec.Spec
+				listed = true
+			}
+		}
+		if _, err := os.Stat(rec.WorkDir); listed || err != nil {
+			continue
+		}
+		wts = append(wts, Worktree{Repo: rec.Repo, Name: rec.Name, Path: rec.WorkDir, Spec: rec.Spec})
+	}
+	return wts
+}
+
+// FindPRWorktree returns the local checkout of branch, a PR's source
+// branch, in the configured repo whose remote matches repoURL — where the
+// repo's strategy puts it, or any of the repo's git worktrees that has it
+// checked out — with its git state. It returns nil when the branch isn't
+// checked out locally.
+func FindPRWorktree(ctx context.Context, cfg *config.Config, repoURL, branch string) *Worktree {
+	rc, err := NewManager("").FindByRemoteURL(cfg, repoURL)
+	if err != nil {
+		return nil
+	}
+	shortBranch := strings.TrimPrefix(branch, "refs/heads/")
+	dir := findUserWorkDir(rc, shortBranch)
+	if dir == "" {
+		cmd := exec.CommandContext(ctx, "git", "worktree", "list", "--porcelain")
+		cmd.Dir = rc.PrimaryDir
+		out, err := cmd.Output()
+		if err != nil {
+			return nil
+		}
+		for _, b := range parseWorktreeList(string(out)) {
+			if b.Name == shortBranch {
+				dir = b.WorkDir
+				break
+			}
+		}
+	}
+	if dir == "" {
+		return nil
+	}
+
+	wt := &Worktree{Repo: rc.Name, Name: filepath.Base(dir), Path: dir, GitState: ReadGitState(ctx, dir)}
+	if recs, err := ListBranchRecords(); err == nil {
+		for _, rec := range recs {
+			if rec.WorkDir == dir {
+				wt.Spec = rec.Spec
+			}
+		}
+	}
+	return wt
+}
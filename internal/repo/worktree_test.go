@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitState(t *testing.T) {
+	st := parseGitState(`# branch.oid 1234abcd
+# branch.head otto/feature
+# branch.upstream origin/otto/feature
+# branch.ab +2 -1
+1 .M N... 100644 100644 100644 aaaa bbbb main.go
+`)
+	assert.Equal(t, GitState{Branch: "otto/feature", Upstream: "origin/otto/feature", Ahead: 2, Behind: 1, Dirty: true}, st)
+
+	st = parseGitState("# branch.oid 1234abcd\n# branch.head (detached)\n")
+	assert.Equal(t, GitState{Branch: "(detached)"}, st)
+}
+
+func TestListWorktrees(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := t.TempDir()
+	primaryDir := filepath.Join(dir, "primary")
+	worktreeDir := filepath.Join(dir, "worktrees")
+	require.NoError(t, os.MkdirAll(primaryDir, 0755))
+	initGitRepo(t, primaryDir)
+
+	rc := config.RepoConfig{
+		Name:           "test-repo",
+		PrimaryDir:     primaryDir,
+		WorktreeDir:    worktreeDir,
+		GitStrategy:    config.GitStrategyWorktree,
+		BranchTemplate: "otto/{{.Name}}",
+	}
+	workDir, err := NewStrategy(rc).CreateBranch("", "feature-1")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "new.txt"), []byte("x"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(worktreeDir, "not-git"), 0755))
+
+	wts := ListWorktrees(context.Background(), []config.RepoConfig{rc}, 5*time.Second)
+	require.Len(t, wts, 2)
+	assert.Equal(t, "feature-1", wts[0].Name)
+	assert.Equal(t, "otto/feature-1", wts[0].Branch)
+	assert.True(t, wts[0].Dirty)
+	assert.Empty(t, wts[0].Error)
+	assert.Equal(t, "not-git", wts[1].Name)
+	assert.NotEmpty(t, wts[1].Error)
+}
+
+func TestFindPRWorktree(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := t.TempDir()
+	primaryDir := filepath.Join(dir, "primary")
+	require.NoError(t, os.MkdirAll(primaryDir, 0755))
+	initGitRepo(t, primaryDir)
+	out, err := exec.Command("git", "-C", primaryDir, "remote", "add", "origin", "https://github.com/acme/widget.git").CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	cfg := &config.Config{Repos: []config.RepoConfig{{
+		Name:           "widget",
+		PrimaryDir:     primaryDir,
+		GitStrategy:    config.GitStrategyWorktree,
+		BranchTemplate: "otto/{{.Name}}",
+	}}}
+	prURL := "https://github.com/acme/widget/pull/42"
+	assert.Nil(t, FindPRWorktree(context.Background(), cfg, prURL, "otto/fix-login"))
+
+	workDir, err := NewStrategy(cfg.Repos[0]).CreateBranch("", "fix-login")
+	require.NoError(t, err)
+	wt := FindPRWorktree(context.Background(), cfg, prURL, "refs/heads/otto/fix-login")
+	require.NotNil(t, wt)
+	assert.Equal(t, workDir, wt.Path)
+	assert.Equal(t, "otto/fix-login", wt.Branch)
+	assert.False(t, wt.Dirty)
+
+	// A worktree outside the strategy's location is found through git.
+	elsewhere := filepath.Join(dir, "elsewhere")
+	out, err = exec.Command("git", "-C", primaryDir, "worktree", "add", elsewhere, "-b", "hotfix").CombinedOutput()
+	require.NoError(t, err, string(out))
+	wt = FindPRWorktree(context.Background(), cfg, prURL, "hotfix")
+	require.NotNil(t, wt)
+	assert.Equal(t, elsewhere, wt.Path)
+}
@@ -0,0 +1,158 @@
+// Package scaffold is the registry of project templates that otto new
+// scaffolds projects from. A template is markdown telling the LLM what to
+// create; otto ships a few, and a repo's .otto/templates/<name>.md or
+// ~/.config/otto/templates/<name>.md adds templates or replaces built-in
+// ones of the same name.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/alanmeadows/otto/internal/config"
+)
+
+//go:embed templates/*.md
+var builtinFS embed.FS
+
+// Builtin is the Source of templates shipped with otto.
+const Builtin = "built-in"
+
+// Template is a project template.
+type Template struct {
+	Name string `json:"name"`
+	// Description is the template's first "# " heading.
+	Description string `json:"description"`
+	// Source is Builtin, or the file the template was read from.
+	Source string `json:"source"`
+	Body   string `json:"-"`
+}
+
+// Project is what a template is rendered with.
+type Project struct {
+	Kind        string // e.g. "service"
+	Name        string
+	Description string
+	// Module is the Go module path (or equivalent) of the project, from
+	// its repository's remote and directory.
+	Module string
+	// Path is the project's directory in its repository; "." for the root.
+	Path string
+}
+
+// Load returns the template called name. Overrides are checked in order —
+// the repo's .otto/templates/<name>.md, then
+// ~/.config/otto/templates/<name>.md — before the built-in template.
+func Load(name string) (*Template, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid template name %q", name)
+	}
+	for _, dir := range overrideDirs() {
+		p := filepath.Join(dir, name+".md")
+		if data, err := os.ReadFile(p); err == nil {
+			return newTemplate(name, p, string(data)), nil
+		}
+	}
+	data, err := builtinFS.ReadFile(path.Join("templates", name+".md"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown template %q (see otto new templates)", name)
+	}
+	return newTemplate(name, Builtin, string(data)), nil
+}
+
+// List returns every available template, by name.
+func List() ([]*Template, error) {
+	names := make(map[string]bool)
+	entries, err := fs.ReadDir(builtinFS, "templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		names[strings.TrimSuffix(e.Name(), ".md")] = true
+	}
+	for _, dir := range overrideDirs() {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*.md"))
+		for _, m := range matches {
+			names[strings.TrimSuffix(filepath.Base(m), ".md")] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	templates := make([]*Template, 0, len(sorted))
+	for _, name := range sorted {
+		t, err := Load(name)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// Render executes the template for p.
+func (t *Template) Render(p Project) (string, error) {
+	tmpl, err := template.New(t.Name).Parse(t.Body)
+	if err != nil {
+		return "", fmt.Errorf("parsing template %s (%s): %w", t.Name, t.Source, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, p); err != nil {
+		return "", fmt.Errorf("executing template %s: %w", t.Name, err)
+	}
+	return buf.String(), nil
+}
+
+// ModulePath derives a module path from a repository's remote URL and the
+// project's directory in it, e.g. "github.com/acme/tools/billing" from
+// "git@github.com:acme/tools.git" and "billing".
+func ModulePath(remote, dir string) string {
+	m := strings.TrimSuffix(strings.TrimSpace(remote), ".git")
+	if _, rest, ok := strings.Cut(m, "://"); ok {
+		m = rest
+	} else {
+		m = strings.Replace(m, ":", "/", 1) // scp-like: git@host:owner/repo
+	}
+	if host, _, _ := strings.Cut(m, "/"); strings.Contains(host, "@") {
+		m = m[strings.Index(m, "@")+1:] // user info
+	}
+	if dir != "" && dir != "." {
+		m += "/" + strings.Trim(filepath.ToSlash(dir), "/")
+	}
+	return m
+}
+
+func newTemplate(name, source, body string) *Template {
+	t := &Template{Name: name, Source: source, Body: body}
+	for _, line := range strings.Split(body, "\n") {
+		if heading, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+			t.Description = strings.TrimSpace(heading)
+			break
+		}
+	}
+	return t
+}
+
+// overrideDirs returns the directories searched for templates, most
+// specific first, as for prompt overrides.
+func overrideDirs() []string {
+	var dirs []string
+	if repoRoot := config.RepoRoot(); repoRoot != "" {
+		dirs = append(dirs, filepath.Join(repoRoot, ".otto", "templates"))
+	}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, "otto", "templates"))
+	}
+	return dirs
+}
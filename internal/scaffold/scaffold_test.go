@@ -0,0 +1,62 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBuiltin(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	tmpl, err := Load("go-service")
+	require.NoError(t, err)
+	assert.Equal(t, Builtin, tmpl.Source)
+	assert.Contains(t, tmpl.Description, "Go HTTP service")
+
+	out, err := tmpl.Render(Project{Kind: "service", Name: "billing", Description: "charges customers", Module: "github.com/acme/billing", Path: "."})
+	require.NoError(t, err)
+	assert.Contains(t, out, "named billing: charges customers.")
+	assert.Contains(t, out, "module `github.com/acme/billing`")
+	assert.Contains(t, out, "cmd/billing/main.go")
+
+	_, err = Load("nope")
+	assert.ErrorContains(t, err, "unknown template")
+	_, err = Load("../scaffold")
+	assert.ErrorContains(t, err, "invalid template name")
+}
+
+func TestUserTemplates(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	dir := filepath.Join(configDir, "otto", "templates")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rust-cli.md"), []byte("# Rust CLI\n\nMake {{.Name}}.\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go-service.md"), []byte("# Our Go service\n"), 0644))
+
+	templates, err := List()
+	require.NoError(t, err)
+	byName := make(map[string]*Template)
+	for _, tmpl := range templates {
+		byName[tmpl.Name] = tmpl
+	}
+	require.Contains(t, byName, "rust-cli")
+	assert.Equal(t, "Rust CLI", byName["rust-cli"].Description)
+	assert.Equal(t, "Our Go service", byName["go-service"].Description, "a user template replaces the built-in one")
+	assert.Equal(t, Builtin, byName["go-library"].Source)
+}
+
+func TestModulePath(t *testing.T) {
+	for _, tc := range []struct {
+		remote, dir, want string
+	}{
+		{"https://github.com/acme/tools.git", ".", "github.com/acme/tools"},
+		{"git@github.com:acme/tools.git", "billing", "github.com/acme/tools/billing"},
+		{"https://acme@dev.azure.com/acme/Platform/_git/tools", "services/billing/", "dev.azure.com/acme/Platform/_git/tools/services/billing"},
+	} {
+		assert.Equal(t, tc.want, ModulePath(tc.remote, tc.dir), tc.remote)
+	}
+}
@@ -0,0 +1,104 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// ageFile keeps secrets as one JSON object encrypted with the age CLI to
+// an identity generated on first use. The identity stays next to the file,
+// readable only by the user, so the file protects secrets from being
+// copied or committed along with the config rather than from the user's
+// own account.
+type ageFile struct {
+	path     string // encrypted secrets
+	identity string // age identity (private key)
+}
+
+func newAgeFile(dir string) backend {
+	return &ageFile{
+		path:     filepath.Join(dir, "secrets.age"),
+		identity: filepath.Join(dir, "secrets.key"),
+	}
+}
+
+func (f *ageFile) load() (map[string]string, error) {
+	if _, err := os.Stat(f.path); errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	out, err := runCommand("", "age", "--decrypt", "--identity", f.identity, f.path)
+	if err != nil {
+		return nil, needAge(err)
+	}
+	m := map[string]string{}
+	if err := json.Unmarshal([]byte(out), &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", f.path, err)
+	}
+	return m, nil
+}
+
+func (f *ageFile) save(m map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return err
+	}
+	if _, err := os.Stat(f.identity); errors.Is(err, os.ErrNotExist) {
+		if _, err := runCommand("", "age-keygen", "--output", f.identity); err != nil {
+			return needAge(err)
+		}
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if _, err := runCommand(string(data), "age", "--encrypt", "--identity", f.identity, "--output", tmp); err != nil {
+		os.Remove(tmp)
+		return needAge(err)
+	}
+	return os.Rename(tmp, f.path)
+}
+
+// needAge explains a missing age CLI.
+func needAge(err error) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("the file backend needs age (https://age-encryption.org) on PATH: %w", err)
+	}
+	return err
+}
+
+func (f *ageFile) get(name string) (string, error) {
+	m, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := m[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (f *ageFile) set(name, value string) error {
+	m, err := f.load()
+	if err != nil {
+		return err
+	}
+	m[name] = value
+	return f.save(m)
+}
+
+func (f *ageFile) remove(name string) error {
+	m, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[name]; !ok {
+		return ErrNotFound
+	}
+	delete(m, name)
+	return f.save(m)
+}
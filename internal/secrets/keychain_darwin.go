@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// keychain stores secrets in the login keychain with the security CLI.
+type keychain struct{}
+
+func newKeychain() backend { return keychain{} }
+
+func keychainAvailable() bool {
+	_, err := exec.LookPath("security")
+	return err == nil
+}
+
+func (keychain) get(name string) (string, error) {
+	out, err := runCommand("", "security", "find-generic-password", "-s", service, "-a", name, "-w")
+	if err != nil && strings.Contains(err.Error(), "could not be found") {
+		return "", ErrNotFound
+	}
+	return out, err
+}
+
+// set passes the value on stdin rather than argv, where any local process
+// could read it: given -w last with no value, security prompts for the
+// password, then again to confirm it.
+func (keychain) set(name, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return errors.New("the macOS keychain can't store a value with line breaks")
+	}
+	_, err := runCommand(value+"\n"+value+"\n", "security", "add-generic-password", "-U", "-s", service, "-a", name, "-w")
+	return err
+}
+
+func (keychain) remove(name string) error {
+	_, err := runCommand("", "security", "delete-generic-password", "-s", service, "-a", name)
+	if err != nil && strings.Contains(err.Error(), "could not be found") {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// keychain stores secrets with the Secret Service (GNOME Keyring, KWallet)
+// via secret-tool, or failing that in the kernel's persistent user keyring
+// via keyctl, which does not survive a reboot.
+type keychain struct {
+	keyctl bool
+}
+
+func newKeychain() backend {
+	_, err := exec.LookPath("secret-tool")
+	return keychain{keyctl: err != nil}
+}
+
+func keychainAvailable() bool {
+	for _, tool := range []string{"secret-tool", "keyctl"} {
+		if _, err := exec.LookPath(tool); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (k keychain) get(name string) (string, error) {
+	if k.keyctl {
+		id, err := k.keyID(name)
+		if err != nil {
+			return "", err
+		}
+		return runCommand("", "keyctl", "pipe", id)
+	}
+	out, err := runCommand("", "secret-tool", "lookup", "service", service, "account", name)
+	if err != nil || out == "" {
+		// secret-tool exits non-zero, without a message, when nothing matches.
+		return "", ErrNotFound
+	}
+	return out, nil
+}
+
+func (k keychain) set(name, value string) error {
+	if k.keyctl {
+		_, err := runCommand(value, "keyctl", "padd", "user", service+":"+name, "@u")
+		return err
+	}
+	_, err := runCommand(value, "secret-tool", "store", "--label", service+" "+name, "service", service, "account", name)
+	return err
+}
+
+func (k keychain) remove(name string) error {
+	if k.keyctl {
+		id, err := k.keyID(name)
+		if err != nil {
+			return err
+		}
+		_, err = runCommand("", "keyctl", "unlink", id, "@u")
+		return err
+	}
+	_, err := runCommand("", "secret-tool", "clear", "service", service, "account", name)
+	return err
+}
+
+// keyID finds the kernel keyring ID of name's key.
+func (keychain) keyID(name string) (string, error) {
+	id, err := runCommand("", "keyctl", "search", "@u", "user", service+":"+name)
+	if err != nil {
+		if strings.Contains(err.Error(), "not available") {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return strings.TrimSpace(id), nil
+}
@@ -0,0 +1,18 @@
+//go:build !darwin && !linux && !windows
+
+package secrets
+
+import "errors"
+
+// keychain is unavailable on this platform; secrets go to the file.
+type keychain struct{}
+
+var errNoKeychain = errors.New("no OS keychain on this platform")
+
+func newKeychain() backend { return keychain{} }
+
+func keychainAvailable() bool { return false }
+
+func (keychain) get(string) (string, error) { return "", errNoKeychain }
+func (keychain) set(string, string) error   { return errNoKeychain }
+func (keychain) remove(string) error        { return errNoKeychain }
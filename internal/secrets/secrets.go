@@ -0,0 +1,206 @@
+// Package secrets keeps credentials such as PATs and API tokens out of the
+// plaintext config files: in the OS keychain (the macOS keychain, the
+// Secret Service or kernel keyring on Linux, the Windows Credential
+// Manager), or in an age-encrypted file.
+//
+// Secrets are named by the config key they fill, e.g.
+// "pr.providers.github.token". An index of the stored names and the
+// backend holding each (never the values) lets config loading skip the
+// keychain entirely when nothing is stored.
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Backend names.
+const (
+	BackendKeychain = "keychain"
+	BackendFile     = "file"
+)
+
+// ErrNotFound is returned for a name with no stored secret.
+var ErrNotFound = errors.New("secret not found")
+
+// backend stores secret values.
+type backend interface {
+	get(name string) (string, error)
+	set(name, value string) error
+	remove(name string) error
+}
+
+// Store reads and writes secrets, recording in an index which backend
+// holds each one.
+type Store struct {
+	dir      string // config directory holding the index and the file backend
+	backend  string // backend for new secrets
+	backends map[string]backend
+}
+
+// Dir returns otto's config directory, where the index and the encrypted
+// file live.
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "otto"), nil
+}
+
+// Open returns the store. name picks the backend new secrets go to:
+// "keychain", "file", or "" for the keychain where one is available and
+// the encrypted file otherwise.
+func Open(name string) (*Store, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, fmt.Errorf("finding config directory: %w", err)
+	}
+	s := &Store{
+		dir: dir,
+		backends: map[string]backend{
+			BackendKeychain: newKeychain(),
+			BackendFile:     newAgeFile(dir),
+		},
+	}
+	switch name {
+	case "":
+		s.backend = BackendFile
+		if keychainAvailable() {
+			s.backend = BackendKeychain
+		}
+	case BackendKeychain:
+		if !keychainAvailable() {
+			return nil, errors.New("no OS keychain available; use the file backend")
+		}
+		s.backend = name
+	case BackendFile:
+		s.backend = name
+	default:
+		return nil, fmt.Errorf("unknown secrets backend %q (want keychain or file)", name)
+	}
+	return s, nil
+}
+
+// Backend returns the backend new secrets are stored in.
+func (s *Store) Backend() string {
+	return s.backend
+}
+
+func (s *Store) indexPath() string {
+	return filepath.Join(s.dir, "secrets.json")
+}
+
+// index maps each stored name to its backend.
+func (s *Store) index() (map[string]string, error) {
+	data, err := os.ReadFile(s.indexPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading secrets index: %w", err)
+	}
+	idx := map[string]string{}
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parsing secrets index: %w", err)
+	}
+	return idx, nil
+}
+
+func (s *Store) writeIndex(idx map[string]string) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	if err := os.WriteFile(s.indexPath(), data, 0600); err != nil {
+		return fmt.Errorf("writing secrets index: %w", err)
+	}
+	return nil
+}
+
+// List returns the stored names and the backend holding each.
+func (s *Store) List() (map[string]string, error) {
+	return s.index()
+}
+
+// Get returns the secret stored under name.
+func (s *Store) Get(name string) (string, error) {
+	idx, err := s.index()
+	if err != nil {
+		return "", err
+	}
+	b, ok := s.backends[idx[name]]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+	value, err := b.get(name)
+	if err != nil {
+		return "", fmt.Errorf("reading %s from %s: %w", name, idx[name], err)
+	}
+	return value, nil
+}
+
+// Set stores value under name in the store's backend, replacing any
+// earlier value wherever it was kept.
+func (s *Store) Set(name, value string) error {
+	idx, err := s.index()
+	if err != nil {
+		return err
+	}
+	if err := s.backends[s.backend].set(name, value); err != nil {
+		return fmt.Errorf("storing %s in %s: %w", name, s.backend, err)
+	}
+	if prev, ok := idx[name]; ok && prev != s.backend {
+		if b, ok := s.backends[prev]; ok {
+			_ = b.remove(name)
+		}
+	}
+	idx[name] = s.backend
+	return s.writeIndex(idx)
+}
+
+// Remove deletes the secret stored under name.
+func (s *Store) Remove(name string) error {
+	idx, err := s.index()
+	if err != nil {
+		return err
+	}
+	b, ok := s.backends[idx[name]]
+	if !ok {
+		return fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+	if err := b.remove(name); err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("removing %s from %s: %w", name, idx[name], err)
+	}
+	delete(idx, name)
+	return s.writeIndex(idx)
+}
+
+// service is the keychain service (or label prefix) otto's secrets are
+// filed under.
+const service = "otto"
+
+// runCommand runs a helper CLI with stdin and returns its trimmed stdout.
+// It is a hook for testing.
+var runCommand = func(stdin, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAge stands in for the age CLIs, "encrypting" by copying.
+func fakeAge(t *testing.T) {
+	orig := runCommand
+	t.Cleanup(func() { runCommand = orig })
+	runCommand = func(stdin, name string, args ...string) (string, error) {
+		switch {
+		case name == "age-keygen":
+			return "", os.WriteFile(args[len(args)-1], []byte("AGE-SECRET-KEY-1TEST"), 0600)
+		case name == "age" && slices.Contains(args, "--encrypt"):
+			return "", os.WriteFile(args[len(args)-1], []byte(stdin), 0600)
+		case name == "age" && slices.Contains(args, "--decrypt"):
+			data, err := os.ReadFile(args[len(args)-1])
+			return string(data), err
+		}
+		return "", errors.New("unexpected command " + name)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	fakeAge(t)
+
+	s, err := Open(BackendFile)
+	require.NoError(t, err)
+
+	_, err = s.Get("jira.token")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, s.Set("jira.token", "j1"))
+	require.NoError(t, s.Set("pr.providers.github.token", "g1"))
+	require.NoError(t, s.Set("jira.token", "j2"))
+
+	got, err := s.Get("jira.token")
+	require.NoError(t, err)
+	assert.Equal(t, "j2", got)
+
+	stored, err := s.List()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"jira.token": BackendFile, "pr.providers.github.token": BackendFile}, stored)
+
+	require.NoError(t, s.Remove("jira.token"))
+	_, err = s.Get("jira.token")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, s.Remove("jira.token"), ErrNotFound)
+
+	dir, err := Dir()
+	require.NoError(t, err)
+	index, err := os.ReadFile(dir + "/secrets.json")
+	require.NoError(t, err)
+	assert.NotContains(t, string(index), "g1", "the index holds names only")
+}
+
+func TestOpenUnknownBackend(t *testing.T) {
+	_, err := Open("vault")
+	assert.ErrorContains(t, err, "unknown secrets backend")
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/alanmeadows/otto/internal/llm"
+)
+
+// prActivity holds the latest LLM step of each PR the daemon is working on,
+// keyed by PR file name, so the dashboard can show live progress.
+var prActivity sync.Map
+
+// trackActivity returns ctx wired to record the progress of the PR's LLM
+// sessions, and a func that clears the record once the work is done.
+func trackActivity(ctx context.Context, providerName, id string) (context.Context, func()) {
+	key := prFilename(providerName, id)
+	ctx = llm.WithProgress(ctx, func(p llm.Progress) {
+		prActivity.Store(key, p.Text)
+	})
+	return ctx, func() { prActivity.Delete(key) }
+}
+
+// currentActivity returns the PR's latest LLM step, or "" when nothing is
+// working on it.
+func currentActivity(providerName, id string) string {
+	if v, ok := prActivity.Load(prFilename(providerName, id)); ok {
+		return v.(string)
+	}
+	return ""
+}
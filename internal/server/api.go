@@ -6,6 +6,8 @@ import (
 	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/alanmeadows/otto/internal/store"
 )
 
 // StatusResponse is the JSON response for GET /status.
@@ -13,6 +15,14 @@ type StatusResponse struct {
 	Status  string `json:"status"`
 	Uptime  string `json:"uptime"`
 	PRCount int    `json:"pr_count"`
+	// Auth is the last credential check per provider.
+	Auth []AuthHealth `json:"auth,omitempty"`
+	// LastPoll is when the monitor loop last finished polling PRs.
+	LastPoll time.Time `json:"last_poll,omitzero"`
+	// CopilotServer is the address of the Copilot server the daemon uses.
+	CopilotServer string `json:"copilot_server,omitempty"`
+	// Locks counts the daemon's file lock activity since it started.
+	Locks store.LockStats `json:"locks"`
 }
 
 func handleStatus(w http.ResponseWriter, r *http.Request) {
@@ -26,6 +36,14 @@ func handleStatus(w http.ResponseWriter, r *http.Request) {
 		Status:  "running",
 		Uptime:  time.Since(serverStartTime).Round(time.Second).String(),
 		PRCount: count,
+		Locks:   store.Stats(),
+	}
+	resp.Auth, _ = LoadAuthHealth()
+	if ns := lastPollTime.Load(); ns != 0 {
+		resp.LastPoll = time.Unix(0, ns)
+	}
+	if cfg := liveConfig.Load(); cfg != nil {
+		resp.CopilotServer = cfg.Dashboard.CopilotServer
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -134,23 +152,39 @@ func handleFixPR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, err := FindPR(id)
+	// The fix runs on the monitor loop's next poll, which this triggers.
+	// The caller polls PR status to track progress.
+	pr, err := RequestFix(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), http.StatusConflict)
 		return
 	}
 
-	// Respond immediately — fix runs asynchronously.
-	// The caller polls PR status to track progress.
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(map[string]string{
 		"status": "accepted",
 		"pr_id":  pr.ID,
 	})
+}
 
-	// TODO: Queue the fix for async execution by the monitoring loop (Phase 8.3).
-	slog.Info("fix requested via API", "prID", pr.ID)
+// handlePausePR returns a handler for POST /prs/{id}/pause (paused) and
+// POST /prs/{id}/resume.
+func handlePausePR(paused bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if id == "" {
+			http.Error(w, "PR ID required", http.StatusBadRequest)
+			return
+		}
+		pr, err := SetPRPaused(id, paused)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(pr)
+	}
 }
 
 func handlePoll(w http.ResponseWriter, r *http.Request) {
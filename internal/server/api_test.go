@@ -36,6 +36,21 @@ func TestHandleStatus(t *testing.T) {
 	assert.Equal(t, "running", resp.Status)
 	assert.NotEmpty(t, resp.Uptime)
 	assert.Equal(t, 0, resp.PRCount)
+	assert.True(t, resp.LastPoll.IsZero(), "no poll has run yet")
+}
+
+func TestHandleStatus_LastPoll(t *testing.T) {
+	setupAPITest(t)
+	polled := time.Now().Add(-time.Minute)
+	lastPollTime.Store(polled.UnixNano())
+	t.Cleanup(func() { lastPollTime.Store(0) })
+
+	rec := httptest.NewRecorder()
+	handleStatus(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	var resp StatusResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.True(t, resp.LastPoll.Equal(polled), "got %v, want %v", resp.LastPoll, polled)
 }
 
 func TestHandleListPRs_Empty(t *testing.T) {
@@ -122,3 +137,34 @@ func TestHandleAddPR_InvalidJSON(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 }
+
+func TestHandleFixAndPausePR(t *testing.T) {
+	setupAPITest(t)
+	pr := &PRDocument{ID: "789", Provider: "github", Status: "failed", PipelineState: "failed", FixAttempts: 5, MaxFixAttempts: 5}
+	require.NoError(t, SavePR(pr))
+
+	mux := http.NewServeMux()
+	registerRoutes(mux)
+	post := func(path string) int {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, post("/prs/789/pause"))
+	assert.Equal(t, http.StatusConflict, post("/prs/789/fix"), "a paused PR is not fixed")
+	loaded, err := LoadPR("github", "789")
+	require.NoError(t, err)
+	assert.True(t, loaded.Paused)
+	assert.Equal(t, "paused", loaded.WaitingOn)
+
+	assert.Equal(t, http.StatusOK, post("/prs/789/resume"))
+	assert.Equal(t, http.StatusAccepted, post("/prs/789/fix"))
+	loaded, err = LoadPR("github", "789")
+	require.NoError(t, err)
+	assert.False(t, loaded.Paused)
+	assert.Equal(t, "watching", loaded.Status)
+	assert.Equal(t, 6, loaded.MaxFixAttempts, "an exhausted PR gets one more attempt")
+
+	assert.Equal(t, http.StatusNotFound, post("/prs/000/pause"))
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// AuthHealth is the result of the last credential check for a provider.
+type AuthHealth struct {
+	Provider  string    `json:"provider"`
+	OK        bool      `json:"ok"`
+	Error     string    `json:"error,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"` // zero when the provider does not say
+	CheckedAt time.Time `json:"checked_at"`
+
+	warnedExpiry time.Time // expiry already notified about
+}
+
+// authMonitor validates each provider's credentials between poll cycles
+// so an expiring or broken credential is reported before a poll trips
+// over it. Polling of a provider whose credentials are rejected pauses
+// until a check passes again.
+type authMonitor struct {
+	mu     sync.Mutex
+	health map[string]*AuthHealth
+}
+
+// authState is the daemon's auth monitor.
+var authState = newAuthMonitor()
+
+func newAuthMonitor() *authMonitor {
+	return &authMonitor{health: make(map[string]*AuthHealth)}
+}
+
+// AuthHealthPath returns where the last auth check results are kept.
+func AuthHealthPath() string {
+	return filepath.Join(filepath.Dir(PRDir()), "auth.json")
+}
+
+// LoadAuthHealth returns the daemon's last auth check results, by provider.
+func LoadAuthHealth() ([]AuthHealth, error) {
+	data, err := os.ReadFile(AuthHealthPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var health []AuthHealth
+	if err := json.Unmarshal(data, &health); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", AuthHealthPath(), err)
+	}
+	return health, nil
+}
+
+// check validates the credentials of every backend that can check them.
+// Errors other than a rejected credential (e.g. the network being down)
+// leave the previous result in place.
+func (m *authMonitor) check(ctx context.Context, reg *provider.Registry, cfg *config.Config) {
+	for _, backend := range reg.Backends() {
+		checker, ok := backend.(provider.AuthChecker)
+		if !ok {
+			// Nothing to check with; let the next poll find out whether
+			// a pause from a failed request still holds.
+			if _, paused := m.paused(backend.Name()); paused {
+				m.pass(ctx, cfg, backend.Name(), time.Time{})
+			}
+			continue
+		}
+		expiresAt, err := checker.CheckAuth(ctx)
+		switch {
+		case errors.Is(err, provider.ErrAuthFailed):
+			m.fail(ctx, cfg, backend.Name(), err)
+		case err != nil:
+			slog.Warn("could not check provider credentials", "provider", backend.Name(), "error", err)
+		default:
+			m.pass(ctx, cfg, backend.Name(), expiresAt)
+		}
+	}
+	m.save()
+}
+
+// pass records a successful check, announcing recovered credentials and
+// ones about to expire.
+func (m *authMonitor) pass(ctx context.Context, cfg *config.Config, name string, expiresAt time.Time) {
+	m.mu.Lock()
+	h := m.entry(name)
+	recovered := !h.OK && !h.CheckedAt.IsZero()
+	h.OK, h.Error, h.ExpiresAt, h.CheckedAt = true, "", expiresAt, time.Now()
+	warn := !expiresAt.IsZero() && time.Until(expiresAt) < cfg.Server.AuthExpiryWarningDuration() && !h.warnedExpiry.Equal(expiresAt)
+	if warn {
+		h.warnedExpiry = expiresAt
+	}
+	m.mu.Unlock()
+
+	if recovered {
+		slog.Info("provider credentials valid again, resuming polling", "provider", name)
+		notifyAuth(ctx, cfg, NotificationPayload{Event: EventAuthRestored, Title: name, Status: "polling resumed"})
+	}
+	if warn {
+		slog.Warn("provider credentials expire soon", "provider", name, "expiresAt", expiresAt)
+		notifyAuth(ctx, cfg, NotificationPayload{
+			Event:  EventAuthExpiring,
+			Title:  name,
+			Status: "expires " + expiresAt.Local().Format("2006-01-02 15:04 MST"),
+			Extra:  map[string]string{"Expires In": time.Until(expiresAt).Round(time.Minute).String()},
+		})
+	}
+}
+
+// fail records rejected credentials, pausing the provider and announcing
+// it the first time.
+func (m *authMonitor) fail(ctx context.Context, cfg *config.Config, name string, err error) {
+	m.mu.Lock()
+	h := m.entry(name)
+	newlyBroken := h.OK || h.CheckedAt.IsZero()
+	h.OK, h.Error, h.CheckedAt = false, err.Error(), time.Now()
+	m.mu.Unlock()
+	m.save()
+
+	if newlyBroken {
+		slog.Error("provider credentials rejected, pausing polling until they are fixed", "provider", name, "error", err)
+		notifyAuth(ctx, cfg, NotificationPayload{
+			Event:  EventAuthFailed,
+			Title:  name,
+			Status: "polling paused",
+			Error:  authHint(cfg.PR.ProviderType(name), err),
+		})
+	}
+}
+
+// paused reports whether polling of a provider is paused, and why.
+func (m *authMonitor) paused(name string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.health[name]
+	if !ok || h.OK {
+		return "", false
+	}
+	return h.Error, true
+}
+
+func (m *authMonitor) entry(name string) *AuthHealth {
+	h, ok := m.health[name]
+	if !ok {
+		h = &AuthHealth{Provider: name}
+		m.health[name] = h
+	}
+	return h
+}
+
+// save writes the current results for otto server status.
+func (m *authMonitor) save() {
+	m.mu.Lock()
+	health := make([]AuthHealth, 0, len(m.health))
+	for _, h := range m.health {
+		health = append(health, *h)
+	}
+	m.mu.Unlock()
+	sort.Slice(health, func(i, j int) bool { return health[i].Provider < health[j].Provider })
+
+	data, err := json.MarshalIndent(health, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(AuthHealthPath()), 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(AuthHealthPath(), data, 0644)
+	}
+	if err != nil {
+		slog.Warn("failed to save auth health", "error", err)
+	}
+}
+
+// authHint explains how to fix rejected credentials of a provider type.
+func authHint(kind string, err error) string {
+	switch kind {
+	case "ado":
+		return err.Error() + ". Run 'az login' or update the configured PAT or service principal."
+	case "github":
+		return err.Error() + ". Update pr.providers.github.token (or GITHUB_TOKEN) or the GitHub App settings."
+	}
+	return err.Error()
+}
+
+func notifyAuth(ctx context.Context, cfg *config.Config, payload NotificationPayload) {
+	if err := Notify(ctx, &cfg.Notifications, payload); err != nil {
+		slog.Warn("failed to send auth notification", "event", string(payload.Event), "error", err)
+	}
+}
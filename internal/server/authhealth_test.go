@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// authCheckBackend is a backend whose credential check is scripted.
+type authCheckBackend struct {
+	provider.PRBackend
+	expiresAt time.Time
+	err       error
+}
+
+func (b *authCheckBackend) Name() string { return "github" }
+
+func (b *authCheckBackend) CheckAuth(context.Context) (time.Time, error) {
+	return b.expiresAt, b.err
+}
+
+func TestAuthMonitor(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	var mu sync.Mutex
+	var cards []string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		cards = append(cards, string(body))
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+	sent := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		out := cards
+		cards = nil
+		return out
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Notifications.TeamsWebhookURL = webhook.URL
+	backend := &authCheckBackend{}
+	reg := provider.NewRegistry()
+	reg.Register(backend)
+	m := newAuthMonitor()
+
+	// Healthy, expiry far off: nothing to say.
+	backend.expiresAt = time.Now().Add(30 * 24 * time.Hour)
+	m.check(t.Context(), reg, &cfg)
+	_, paused := m.paused("github")
+	assert.False(t, paused)
+	assert.Empty(t, sent())
+
+	// Expiry inside the warning window: one notification per expiry.
+	backend.expiresAt = time.Now().Add(24 * time.Hour)
+	m.check(t.Context(), reg, &cfg)
+	m.check(t.Context(), reg, &cfg)
+	got := sent()
+	require.Len(t, got, 1)
+	assert.Contains(t, got[0], "Credentials Expiring")
+
+	// Rejected: paused, announced once, and visible to otto server status.
+	backend.err = errors.Join(provider.ErrAuthFailed, errors.New("HTTP 401"))
+	m.check(t.Context(), reg, &cfg)
+	m.check(t.Context(), reg, &cfg)
+	reason, paused := m.paused("github")
+	assert.True(t, paused)
+	assert.Contains(t, reason, "HTTP 401")
+	got = sent()
+	require.Len(t, got, 1)
+	assert.Contains(t, got[0], "Credentials Rejected")
+	health, err := LoadAuthHealth()
+	require.NoError(t, err)
+	require.Len(t, health, 1)
+	assert.False(t, health[0].OK)
+
+	// A network error says nothing about the credentials.
+	backend.err = errors.New("connection refused")
+	m.check(t.Context(), reg, &cfg)
+	_, paused = m.paused("github")
+	assert.True(t, paused)
+
+	// Fixed: polling resumes.
+	backend.err = nil
+	m.check(t.Context(), reg, &cfg)
+	_, paused = m.paused("github")
+	assert.False(t, paused)
+	got = sent()
+	require.Len(t, got, 1)
+	assert.Contains(t, got[0], "Credentials Restored")
+}
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/policy"
+	"github.com/alanmeadows/otto/internal/prompts"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/alanmeadows/otto/internal/repo"
+	"github.com/alanmeadows/otto/internal/store"
+)
+
+// Batch is one change applied across several repos by `otto batch
+// apply`, each through its own branch and PR.
+type Batch struct {
+	Name    string      `json:"name"`
+	Title   string      `json:"title"`
+	Change  string      `json:"change"` // the prompt describing the change
+	Created time.Time   `json:"created"`
+	Repos   []BatchRepo `json:"repos"`
+}
+
+// BatchRepo is a batch's change to one repo.
+type BatchRepo struct {
+	Repo     string `json:"repo"`
+	Branch   string `json:"branch,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	PRID     string `json:"pr_id,omitempty"`
+	PRURL    string `json:"pr_url,omitempty"`
+	// Result says why the repo has no PR: "no changes", or the error that
+	// stopped it.
+	Result string `json:"result,omitempty"`
+}
+
+// batchNamePattern is what batch names may look like, since they name the
+// batch's file and branch.
+var batchNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// ValidateBatchName checks that name can name a batch.
+func ValidateBatchName(name string) error {
+	if !batchNamePattern.MatchString(name) || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid batch name %q: use lowercase letters, digits, '.', '_', and '-'", name)
+	}
+	return nil
+}
+
+// BatchBranch returns the branch a batch's change is pushed to in each repo.
+func BatchBranch(name string) string {
+	return "otto/batch/" + name
+}
+
+// BatchDir returns the directory batches are recorded in.
+func BatchDir() string {
+	return filepath.Join(filepath.Dir(PRDir()), "batches")
+}
+
+func batchPath(name string) string {
+	return filepath.Join(BatchDir(), name+".json")
+}
+
+// ApplyBatchRepo makes b's change to rc: it has the LLM make the change
+// in a throwaway worktree off target (default: the repo's default
+// branch), runs the repo's checks, pushes the result to BatchBranch, and
+// opens and tracks a PR for it. The outcome is returned for the batch's
+// record; a repo the LLM changed nothing in gets no PR. Failing checks
+// don't stop the PR; they are reported in its description, and the
+// daemon takes the PR's pipeline from there.
+func ApplyBatchRepo(ctx context.Context, cfg *config.Config, client llm.Client, rc *config.RepoConfig, b *Batch, target string) BatchRepo {
+	r := BatchRepo{Repo: rc.Name}
+	remote, err := gitOutput(ctx, rc.PrimaryDir, "remote", "get-url", "origin")
+	if err != nil {
+		r.Result = err.Error()
+		return r
+	}
+	remote = strings.TrimSpace(remote)
+
+	cb, err := applyBatchChange(ctx, cfg, client, rc, remote, b, target)
+	if err != nil {
+		r.Result = err.Error()
+		return r
+	}
+	if cb == nil {
+		r.Result = "no changes"
+		return r
+	}
+	r.Branch = cb.Branch
+
+	backend, err := RepoBackend(cfg, remote)
+	if err != nil {
+		r.Result = err.Error()
+		return r
+	}
+	r.Provider = backend.Name()
+	info, err := backend.CreatePR(ctx, provider.CreatePRParams{
+		Title:        b.Title,
+		Description:  batchPRDescription(b, cb),
+		SourceBranch: cb.Branch,
+		TargetBranch: cb.Target,
+		Labels:       policy.Labels(cfg.PR.Labels, cb.Files),
+	})
+	if err != nil {
+		r.Result = fmt.Sprintf("creating PR: %v", err)
+		return r
+	}
+	r.PRID, r.PRURL = info.ID, info.URL
+
+	pr := NewTrackedPR(info, r.Provider, cfg)
+	AnalyzeNewPR(ctx, cfg, backend, info, pr, nil)
+	if err := SavePR(pr); err != nil {
+		r.Result = fmt.Sprintf("tracking PR #%s: %v", info.ID, err)
+	}
+	return r
+}
+
+// applyBatchChange has the LLM make b's change to rc and pushes it,
+// returning nil when nothing changed.
+func applyBatchChange(ctx context.Context, cfg *config.Config, client llm.Client, rc *config.RepoConfig, remote string, b *Batch, target string) (*ChangeBranch, error) {
+	prompt, err := prompts.Execute("batch-change.md", map[string]string{
+		"Batch":  b.Name,
+		"Repo":   rc.Name,
+		"Change": b.Change,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("building batch prompt: %w", err)
+	}
+	return pushLLMChange(ctx, cfg, client, rc, remote, target, BatchBranch(b.Name), b.Title,
+		fmt.Sprintf("Batch %s: %s", b.Name, rc.Name), prompt)
+}
+
+// pushLLMChange has the LLM carry out prompt in a throwaway worktree of rc
+// off target, runs rc's checks on the result, and pushes it to branch as
+// one commit. It returns nil when the LLM changed nothing. Failing checks
+// don't stop the push; they are reported in the returned branch.
+func pushLLMChange(ctx context.Context, cfg *config.Config, client llm.Client, rc *config.RepoConfig, remote, target, branch, message, title, prompt string) (*ChangeBranch, error) {
+	var checks []repo.CheckResult
+	cb, err := PushChangeBranch(ctx, cfg, remote, target, branch, message, func(workDir string) error {
+		session, err := client.CreateSession(ctx, title, workDir)
+		if err != nil {
+			return fmt.Errorf("creating session: %w", err)
+		}
+		defer client.DeleteSession(ctx, session.ID)
+
+		if _, err := client.SendPrompt(ctx, session.ID, withRepoInstructions(prompt, workDir)); err != nil {
+			return fmt.Errorf("LLM prompt failed: %w", err)
+		}
+		checks = repo.RunChecks(ctx, workDir, rc.Checks)
+		return nil
+	})
+	if err != nil || cb == nil {
+		return nil, err
+	}
+	if failed := repo.FailedChecks(checks); len(failed) > 0 {
+		cb.Failed = true
+		cb.Output = repo.FormatCheckFailures(failed)
+	}
+	return cb, nil
+}
+
+// batchPRDescription describes the PR for b's change to one repo.
+func batchPRDescription(b *Batch, cb *ChangeBranch) string {
+	repos := make([]string, len(b.Repos))
+	for i, r := range b.Repos {
+		repos[i] = r.Repo
+	}
+	var s strings.Builder
+	fmt.Fprintf(&s, "Part of the batch `%s`, which makes the same change in %s.\n\n", b.Name, strings.Join(repos, ", "))
+	fmt.Fprintf(&s, "## Change\n\n%s\n\n", strings.TrimSpace(b.Change))
+	fmt.Fprintf(&s, "## Files\n\n```\n%s\n```\n", cb.DiffStat)
+	if cb.Failed {
+		fmt.Fprintf(&s, "\n## Failing checks\n\nThe repo's checks failed after the change:\n\n%s\n", strings.TrimSpace(cb.Output))
+	}
+	return s.String()
+}
+
+// SaveBatch records b, replacing any earlier record of it.
+func SaveBatch(b *Batch) error {
+	if err := os.MkdirAll(BatchDir(), 0755); err != nil {
+		return fmt.Errorf("creating batch directory: %w", err)
+	}
+	path := batchPath(b.Name)
+	return store.WithLock(path, store.DefaultLockTimeout, func() error {
+		data, err := json.MarshalIndent(b, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling batch: %w", err)
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return fmt.Errorf("writing batch: %w", err)
+		}
+		return os.Rename(tmp, path)
+	})
+}
+
+// LoadBatch reads the batch named name.
+func LoadBatch(name string) (*Batch, error) {
+	if err := ValidateBatchName(name); err != nil {
+		return nil, err
+	}
+	var b *Batch
+	err := store.WithReadLock(batchPath(name), store.DefaultLockTimeout, func() error {
+		var err error
+		b, err = readBatch(batchPath(name))
+		return err
+	})
+	return b, err
+}
+
+// ListBatches returns every recorded batch, newest first.
+func ListBatches() ([]*Batch, error) {
+	paths, err := filepath.Glob(filepath.Join(BatchDir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	batches := make([]*Batch, 0, len(paths))
+	for _, path := range paths {
+		b, err := readBatch(path)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, b)
+	}
+	sort.Slice(batches, func(i, j int) bool {
+		return batches[i].Created.After(batches[j].Created)
+	})
+	return batches, nil
+}
+
+func readBatch(path string) (*Batch, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("batch %q not found", strings.TrimSuffix(filepath.Base(path), ".json"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading batch: %w", err)
+	}
+	var b Batch
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parsing batch %s: %w", path, err)
+	}
+	return &b, nil
+}
+
+// BatchPRs returns the PR documents of b's repos, in order, from tracked
+// PRs and those archived when reaped; an entry is nil for a repo without
+// a PR, or whose PR otto no longer knows.
+func BatchPRs(b *Batch) ([]*PRDocument, error) {
+	prs, err := reportPRs()
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]*PRDocument, len(b.Repos))
+	for i, r := range b.Repos {
+		if r.PRID == "" {
+			continue
+		}
+		for _, p := range prs {
+			if p.pr.Provider == r.Provider && p.pr.ID == r.PRID {
+				docs[i] = p.pr
+				break
+			}
+		}
+	}
+	return docs, nil
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+)
+
+func TestApplyBatchChange(t *testing.T) {
+	repoDir := newRepoWithRemote(t)
+	origin := filepath.Join(filepath.Dir(repoDir), "origin.git")
+	mainBranch := strings.TrimSpace(gitT(t, repoDir, "rev-parse", "--abbrev-ref", "HEAD"))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "VERSION"), []byte("v1\n"), 0644))
+	gitT(t, repoDir, "add", "-A")
+	gitT(t, repoDir, "commit", "-q", "-m", "add version")
+	gitT(t, repoDir, "push", "-q", "origin", "HEAD")
+
+	cfg := &config.Config{
+		Repos: []config.RepoConfig{{Name: "app", PrimaryDir: repoDir, GitStrategy: config.GitStrategyBranch}},
+		Git:   config.GitConfig{AuthorName: "otto", AuthorEmail: "otto@example.com"},
+	}
+	rc := &cfg.Repos[0]
+	b := &Batch{Name: "bump", Title: "chore: bump version", Change: "Bump VERSION to v2.",
+		Repos: []BatchRepo{{Repo: "app"}, {Repo: "api"}}}
+	client := llm.NewMockClient()
+
+	// The mock LLM edits nothing, so nothing is pushed.
+	cb, err := applyBatchChange(context.Background(), cfg, client, rc, origin, b, mainBranch)
+	require.NoError(t, err)
+	assert.Nil(t, cb)
+	require.Len(t, client.PromptHistory, 1)
+	assert.Contains(t, client.PromptHistory[0].Prompt, "Bump VERSION to v2.")
+	assert.Contains(t, client.PromptHistory[0].Prompt, `batch "bump"`)
+
+	// Checks run on the LLM's changes; here a check stands in for the
+	// edit, and its failure is reported rather than stopping the push.
+	rc.Checks = []string{"echo v2 > VERSION; echo 'lint failed'; exit 1"}
+	cb, err = applyBatchChange(context.Background(), cfg, client, rc, origin, b, mainBranch)
+	require.NoError(t, err)
+	require.NotNil(t, cb)
+	assert.Equal(t, "otto/batch/bump", cb.Branch)
+	assert.Equal(t, []string{"VERSION"}, cb.Files)
+	assert.True(t, cb.Failed)
+	assert.Contains(t, cb.Output, "lint failed")
+
+	gitT(t, repoDir, "fetch", "-q", "origin")
+	assert.Equal(t, "v2\n", gitT(t, repoDir, "show", "origin/otto/batch/bump:VERSION"))
+
+	desc := batchPRDescription(b, cb)
+	assert.Contains(t, desc, "app, api")
+	assert.Contains(t, desc, "## Failing checks")
+}
+
+func TestBatchStore(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	batches, err := ListBatches()
+	require.NoError(t, err)
+	assert.Empty(t, batches)
+
+	old := &Batch{Name: "old", Created: time.Now().Add(-time.Hour)}
+	recent := &Batch{Name: "recent", Created: time.Now(), Repos: []BatchRepo{
+		{Repo: "app", Provider: "github", PRID: "7"},
+		{Repo: "api", Result: "no changes"},
+	}}
+	require.NoError(t, SaveBatch(old))
+	require.NoError(t, SaveBatch(recent))
+
+	batches, err = ListBatches()
+	require.NoError(t, err)
+	require.Len(t, batches, 2)
+	assert.Equal(t, "recent", batches[0].Name)
+
+	got, err := LoadBatch("recent")
+	require.NoError(t, err)
+	assert.Equal(t, recent.Repos, got.Repos)
+	_, err = LoadBatch("missing")
+	assert.ErrorContains(t, err, "not found")
+	_, err = LoadBatch("../prs")
+	assert.ErrorContains(t, err, "invalid batch name")
+
+	// The status lookup finds the batch's PRs among the tracked ones.
+	require.NoError(t, SavePR(&PRDocument{ID: "7", Provider: "github", Title: "chore: x", Status: "watching"}))
+	docs, err := BatchPRs(got)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	if assert.NotNil(t, docs[0]) {
+		assert.Equal(t, "watching", docs[0].Status)
+	}
+	assert.Nil(t, docs[1])
+}
@@ -0,0 +1,359 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/prompts"
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// defaultBotPrompt evaluates a bot's comments when its handler names no
+// prompt of its own.
+const defaultBotPrompt = "bot-evaluate.md"
+
+// merlinBot is the built-in handler enabled by providers.ado.merlinbot.
+var merlinBot = config.BotHandler{
+	Name:              "merlinbot",
+	Provider:          "ado",
+	Authors:           []string{"Merlin"},
+	NoFeedbackPhrases: []string{"There is no AI feedback on this pull request"},
+	Prompt:            "merlinbot-evaluate.md",
+}
+
+// builtinBots are the handlers otto ships, each enabled by a flag in its
+// provider's config. GitHub's AI reviewers put their verdict in review
+// summaries and their findings in inline threads, so only inline comments
+// are evaluated.
+var builtinBots = []struct {
+	handler config.BotHandler
+	enabled func(config.ProviderConfig) bool
+}{
+	{merlinBot, func(p config.ProviderConfig) bool { return p.MerlinBot }},
+	{config.BotHandler{
+		Name:              "copilot",
+		Provider:          "github",
+		Authors:           []string{"copilot"},
+		NoFeedbackPhrases: []string{"generated no comments", "generated no new comments"},
+		InlineOnly:        true,
+	}, func(p config.ProviderConfig) bool { return p.CopilotReview }},
+	{config.BotHandler{
+		Name:              "coderabbit",
+		Provider:          "github",
+		Authors:           []string{"coderabbitai"},
+		NoFeedbackPhrases: []string{"No actionable comments were generated"},
+		InlineOnly:        true,
+	}, func(p config.ProviderConfig) bool { return p.CodeRabbit }},
+}
+
+// botHandlers returns the bot handlers that apply to pr: the configured
+// pr.bots for its provider (a named ADO instance also gets those for ado),
+// plus the enabled built-in handlers they do not replace.
+func botHandlers(pr *PRDocument, cfg *config.Config) []config.BotHandler {
+	kind := cfg.PR.ProviderType(pr.Provider)
+	var handlers []config.BotHandler
+	for _, h := range cfg.PR.Bots {
+		if h.Provider == "" || h.Provider == pr.Provider || h.Provider == kind {
+			handlers = append(handlers, h)
+		}
+	}
+	for _, b := range builtinBots {
+		if b.handler.Provider != kind || !b.enabled(cfg.PR.Providers[pr.Provider]) {
+			continue
+		}
+		replaced := slices.ContainsFunc(cfg.PR.Bots, func(h config.BotHandler) bool { return h.Name == b.handler.Name })
+		if !replaced {
+			handlers = append(handlers, b.handler)
+		}
+	}
+	return handlers
+}
+
+// pendingBots returns the handlers in handlers that have not yet finished
+// with pr.
+func pendingBots(pr *PRDocument, handlers []config.BotHandler) []config.BotHandler {
+	var pending []config.BotHandler
+	for _, h := range handlers {
+		if !slices.Contains(pr.BotsDone, h.Name) {
+			pending = append(pending, h)
+		}
+	}
+	return pending
+}
+
+// matchesBot reports whether author is the bot h describes.
+func matchesBot(h config.BotHandler, author string) bool {
+	author = strings.ToLower(author)
+	for _, a := range h.Authors {
+		if a != "" && strings.Contains(author, strings.ToLower(a)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBotAuthor reports whether author is one of handlers' bots. MerlinBot
+// counts even when its handler is off, so its comments are never answered
+// as if a person wrote them.
+func isBotAuthor(handlers []config.BotHandler, author string) bool {
+	if matchesBot(merlinBot, author) {
+		return true
+	}
+	for _, h := range handlers {
+		if matchesBot(h, author) {
+			return true
+		}
+	}
+	return false
+}
+
+// botComments returns h's comments regardless of resolution status.
+func botComments(h config.BotHandler, comments []provider.Comment) []provider.Comment {
+	var bot []provider.Comment
+	for _, c := range comments {
+		if matchesBot(h, c.Author) {
+			bot = append(bot, c)
+		}
+	}
+	return bot
+}
+
+// isNoFeedback reports whether c is h's way of saying it found nothing.
+func isNoFeedback(h config.BotHandler, c provider.Comment) bool {
+	for _, phrase := range h.NoFeedbackPhrases {
+		if phrase != "" && strings.Contains(c.Body, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// botEvaluation represents a parsed evaluation of a single bot comment.
+type botEvaluation struct {
+	ThreadID string
+	Decision string // FIX, WONT_FIX, BY_DESIGN
+	Reason   string
+	Action   string
+}
+
+// parseBotEvaluation parses the LLM's structured evaluation output.
+func parseBotEvaluation(content string) []botEvaluation {
+	var evals []botEvaluation
+
+	threadRe := regexp.MustCompile(`THREAD\s+(\S+):\s*(FIX|WONT_FIX|BY_DESIGN)`)
+	reasonRe := regexp.MustCompile(`REASON:\s*(.+)`)
+	actionRe := regexp.MustCompile(`ACTION:\s*(.+)`)
+
+	lines := strings.Split(content, "\n")
+	var current *botEvaluation
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+
+		if m := threadRe.FindStringSubmatch(line); m != nil {
+			if current != nil {
+				evals = append(evals, *current)
+			}
+			current = &botEvaluation{
+				ThreadID: m[1],
+				Decision: m[2],
+			}
+			continue
+		}
+
+		if current != nil {
+			if m := reasonRe.FindStringSubmatch(line); m != nil {
+				current.Reason = m[1]
+			}
+			if m := actionRe.FindStringSubmatch(line); m != nil {
+				current.Action = m[1]
+			}
+		}
+	}
+
+	if current != nil {
+		evals = append(evals, *current)
+	}
+
+	return evals
+}
+
+// handleBotReview processes one bot's comments on a PR. It detects the
+// bot's "no feedback" comment, evaluates real feedback via LLM, and replies
+// to and resolves threads per the handler's resolution policy. h.Name is
+// added to pr.BotsDone once the bot has been fully handled.
+// It reuses workDir and commits without pushing (caller is responsible
+// for pushing). Returns true if code changes were committed.
+func handleBotReview(ctx context.Context, pr *PRDocument, h config.BotHandler, comments []provider.Comment, backend provider.PRBackend, client llm.Client, cfg *config.Config, workDir string) (bool, error) {
+	// Guard with a deadline (pr.merlinbot_timeout) so a stuck LLM session cannot block indefinitely.
+	ctx, cancel := context.WithTimeout(ctx, cfg.PR.ParseMerlinBotTimeout())
+	defer cancel()
+
+	prInfo := &provider.PRInfo{
+		ID:           pr.ID,
+		URL:          pr.URL,
+		RepoID:       pr.Repo,
+		SourceBranch: pr.Branch,
+		TargetBranch: pr.Target,
+	}
+	done := func() {
+		if !slices.Contains(pr.BotsDone, h.Name) {
+			pr.BotsDone = append(pr.BotsDone, h.Name)
+		}
+	}
+	resolve := func(threadID string, resolution provider.CommentResolution) {
+		if h.Resolution == config.BotResolveNone || (h.Resolution == config.BotResolveFixed && resolution != provider.ResolutionFixed) {
+			return
+		}
+		if err := backend.ResolveComment(ctx, prInfo, threadID, resolution); err != nil {
+			slog.Warn("failed to resolve bot thread", "prID", pr.ID, "bot", h.Name, "threadID", threadID, "error", err)
+		}
+	}
+	reply := func(threadID, body string) {
+		if err := backend.ReplyToComment(ctx, prInfo, threadID, body+aiFooter(cfg)); err != nil {
+			slog.Warn("failed to reply to bot thread", "prID", pr.ID, "bot", h.Name, "threadID", threadID, "error", err)
+		}
+	}
+
+	// Find ALL of the bot's comments, including resolved ones — bots such
+	// as MerlinBot auto-close their "no feedback" threads immediately.
+	allBotComments := botComments(h, comments)
+	if len(allBotComments) == 0 {
+		// Log unique authors to help diagnose matching issues.
+		authorSet := make(map[string]bool)
+		for _, c := range comments {
+			authorSet[c.Author] = true
+		}
+		var authors []string
+		for a := range authorSet {
+			authors = append(authors, a)
+		}
+		slog.Info("no bot comments found yet", "prID", pr.ID, "bot", h.Name, "totalComments", len(comments), "authors", strings.Join(authors, ", "))
+		return false, nil
+	}
+
+	slog.Info("found bot comments", "prID", pr.ID, "bot", h.Name, "count", len(allBotComments))
+
+	// Split the bot's findings from its "nothing to flag" notices.
+	noFeedback := false
+	var findings, unresolvedBot []provider.Comment
+	for _, c := range allBotComments {
+		switch {
+		case isNoFeedback(h, c):
+			noFeedback = true
+		case h.InlineOnly && c.FilePath == "":
+		default:
+			findings = append(findings, c)
+			if !c.IsResolved {
+				unresolvedBot = append(unresolvedBot, c)
+			}
+		}
+	}
+
+	if len(unresolvedBot) == 0 {
+		if !noFeedback && len(findings) == 0 {
+			// Only summary posts so far; the review itself is still coming.
+			slog.Info("no bot findings yet", "prID", pr.ID, "bot", h.Name)
+			return false, nil
+		}
+		if noFeedback {
+			slog.Info("bot reports no feedback", "prID", pr.ID, "bot", h.Name)
+			// Close any still-open "no feedback" threads.
+			for _, bc := range allBotComments {
+				if !bc.IsResolved && isNoFeedback(h, bc) {
+					resolve(bc.ThreadID, provider.ResolutionByDesign)
+				}
+			}
+		} else {
+			slog.Info("all bot comments already resolved", "prID", pr.ID, "bot", h.Name)
+		}
+		done()
+		return false, nil
+	}
+
+	// Evaluate unresolved bot comments via LLM.
+	slog.Info("evaluating bot comments via LLM", "prID", pr.ID, "bot", h.Name, "count", len(unresolvedBot))
+
+	// Build comment summary for the prompt.
+	var commentSummary strings.Builder
+	for _, c := range unresolvedBot {
+		commentSummary.WriteString(fmt.Sprintf("THREAD %s [%s:%d]:\n%s\n\n", c.ThreadID, c.FilePath, c.Line, c.Body))
+	}
+
+	promptName := h.Prompt
+	if promptName == "" {
+		promptName = defaultBotPrompt
+	}
+	templateData := map[string]string{
+		"Bot":      h.Name,
+		"Comments": commentSummary.String(),
+		"Diff":     fetchPRDiff(ctx, backend, prInfo),
+	}
+	prompt, err := prompts.Execute(promptName, templateData)
+	if err != nil {
+		return false, fmt.Errorf("building %s evaluation prompt: %w", h.Name, err)
+	}
+
+	client = recordTranscript(client, pr)
+	session, err := client.CreateSession(ctx, fmt.Sprintf("Bot Review PR#%s %s", pr.ID, h.Name), workDir)
+	if err != nil {
+		return false, fmt.Errorf("creating %s session: %w", h.Name, err)
+	}
+	defer client.DeleteSession(ctx, session.ID)
+
+	resp, err := client.SendPrompt(ctx, session.ID, withRepoInstructions(prompt, workDir))
+	if err != nil {
+		return false, fmt.Errorf("%s evaluation failed: %w", h.Name, err)
+	}
+	recordModel(pr, resp)
+
+	// Parse evaluations and take action.
+	evaluations := parseBotEvaluation(resp.Content)
+	fixCount := 0
+
+	for _, eval := range evaluations {
+		switch eval.Decision {
+		case "FIX":
+			fixCount++
+			fixPrompt := fmt.Sprintf("Fix the issue identified by %s in thread %s:\n\n%s\n\nAction: %s",
+				h.Name, eval.ThreadID, eval.Reason, eval.Action)
+			if _, err := client.SendPrompt(ctx, session.ID, fixPrompt); err != nil {
+				slog.Warn("failed to apply bot fix", "prID", pr.ID, "bot", h.Name, "threadID", eval.ThreadID, "error", err)
+				continue
+			}
+			reply(eval.ThreadID, fmt.Sprintf("Fixed: %s", eval.Action))
+			resolve(eval.ThreadID, provider.ResolutionFixed)
+
+		case "WONT_FIX":
+			reply(eval.ThreadID, eval.Reason)
+			resolve(eval.ThreadID, provider.ResolutionWontFix)
+
+		case "BY_DESIGN":
+			reply(eval.ThreadID, eval.Reason)
+			resolve(eval.ThreadID, provider.ResolutionByDesign)
+		}
+	}
+
+	// Commit if fixes were applied (push is batched by the caller).
+	committed := false
+	if fixCount > 0 {
+		commitMsg := fmt.Sprintf("address %d %s comment(s)", fixCount, h.Name)
+		commitHash, err := gitCommit(ctx, cfg, workDir, commitMsg)
+		if err != nil {
+			slog.Warn("failed to commit bot fixes", "prID", pr.ID, "bot", h.Name, "error", err)
+		} else {
+			committed = true
+			slog.Info("committed bot fixes", "prID", pr.ID, "bot", h.Name, "commit", commitHash, "fixCount", fixCount)
+		}
+	}
+
+	done()
+	slog.Info("bot handling complete", "prID", pr.ID, "bot", h.Name, "evaluated", len(evaluations), "fixed", fixCount)
+	return committed, nil
+}
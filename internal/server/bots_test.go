@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// threadBackend records thread replies and resolutions; other calls panic.
+type threadBackend struct {
+	provider.PRBackend
+	resolved map[string]provider.CommentResolution
+}
+
+func (b *threadBackend) ReplyToComment(ctx context.Context, pr *provider.PRInfo, threadID string, body string) error {
+	return nil
+}
+
+func (b *threadBackend) ResolveComment(ctx context.Context, pr *provider.PRInfo, threadID string, resolution provider.CommentResolution) error {
+	if b.resolved == nil {
+		b.resolved = make(map[string]provider.CommentResolution)
+	}
+	b.resolved[threadID] = resolution
+	return nil
+}
+
+func TestBotHandlers(t *testing.T) {
+	rabbit := config.BotHandler{Name: "coderabbit", Provider: "github", Authors: []string{"coderabbitai"}}
+	sonar := config.BotHandler{Name: "sonar", Authors: []string{"sonarcloud"}}
+	cfg := &config.Config{}
+	cfg.PR.Providers = map[string]config.ProviderConfig{"ado": {MerlinBot: true}}
+	cfg.PR.Bots = []config.BotHandler{rabbit, sonar}
+
+	assert.Equal(t, []config.BotHandler{sonar, merlinBot}, botHandlers(&PRDocument{Provider: "ado"}, cfg))
+	assert.Equal(t, []config.BotHandler{rabbit, sonar}, botHandlers(&PRDocument{Provider: "github"}, cfg))
+
+	cfg.PR.Providers["ado-contoso"] = config.ProviderConfig{Type: "ado", Organization: "contoso", MerlinBot: true}
+	assert.Equal(t, []config.BotHandler{sonar, merlinBot}, botHandlers(&PRDocument{Provider: "ado-contoso"}, cfg), "named ADO instances get the ADO handlers")
+
+	custom := config.BotHandler{Name: "merlinbot", Authors: []string{"Merlin"}, Resolution: config.BotResolveNone}
+	cfg.PR.Bots = []config.BotHandler{custom}
+	assert.Equal(t, []config.BotHandler{custom}, botHandlers(&PRDocument{Provider: "ado"}, cfg), "a configured merlinbot replaces the built-in one")
+
+	pr := &PRDocument{Provider: "github", BotsDone: []string{"coderabbit"}}
+	cfg.PR.Bots = []config.BotHandler{rabbit, sonar}
+	assert.Equal(t, []config.BotHandler{sonar}, pendingBots(pr, botHandlers(pr, cfg)))
+
+	cfg.PR.Bots = nil
+	cfg.PR.Providers["github"] = config.ProviderConfig{CopilotReview: true, CodeRabbit: true}
+	var names []string
+	for _, h := range botHandlers(&PRDocument{Provider: "github"}, cfg) {
+		names = append(names, h.Name)
+	}
+	assert.Equal(t, []string{"copilot", "coderabbit"}, names)
+}
+
+func TestIsBotAuthor(t *testing.T) {
+	handlers := []config.BotHandler{{Name: "coderabbit", Authors: []string{"CodeRabbitAI"}}}
+	assert.True(t, isBotAuthor(handlers, "coderabbitai[bot]"))
+	assert.True(t, isBotAuthor(nil, "MerlinBot"), "MerlinBot is always a bot")
+	assert.False(t, isBotAuthor(handlers, "octocat"))
+}
+
+func TestParseBotEvaluation(t *testing.T) {
+	evals := parseBotEvaluation(`THREAD 12: FIX
+REASON: nil map write
+ACTION: initialize the map in New
+
+THREAD 13: BY_DESIGN
+REASON: the lock is held by the caller`)
+	assert.Equal(t, []botEvaluation{
+		{ThreadID: "12", Decision: "FIX", Reason: "nil map write", Action: "initialize the map in New"},
+		{ThreadID: "13", Decision: "BY_DESIGN", Reason: "the lock is held by the caller"},
+	}, evals)
+}
+
+func TestHandleBotReview_NoFeedback(t *testing.T) {
+	comments := []provider.Comment{
+		{ThreadID: "1", Author: "octocat", Body: "Looks good"},
+		{ThreadID: "2", Author: "sonarcloud[bot]", Body: "Quality Gate passed. No issues found."},
+	}
+	h := config.BotHandler{Name: "sonar", Authors: []string{"sonarcloud"}, NoFeedbackPhrases: []string{"No issues found"}}
+
+	backend := &threadBackend{}
+	pr := &PRDocument{ID: "7"}
+	committed, err := handleBotReview(context.Background(), pr, h, comments, backend, nil, &config.Config{}, "")
+	require.NoError(t, err)
+	assert.False(t, committed)
+	assert.Equal(t, []string{"sonar"}, pr.BotsDone)
+	assert.Equal(t, map[string]provider.CommentResolution{"2": provider.ResolutionByDesign}, backend.resolved)
+
+	// Under resolution "none" the thread is left for a human.
+	h.Resolution = config.BotResolveNone
+	backend = &threadBackend{}
+	pr = &PRDocument{ID: "7"}
+	_, err = handleBotReview(context.Background(), pr, h, comments, backend, nil, &config.Config{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sonar"}, pr.BotsDone)
+	assert.Empty(t, backend.resolved)
+}
+
+func TestHandleBotReview_NotYetCommented(t *testing.T) {
+	pr := &PRDocument{ID: "7"}
+	h := config.BotHandler{Name: "sonar", Authors: []string{"sonarcloud"}}
+	committed, err := handleBotReview(context.Background(), pr, h, []provider.Comment{{Author: "octocat"}}, &threadBackend{}, nil, &config.Config{}, "")
+	require.NoError(t, err)
+	assert.False(t, committed)
+	assert.Empty(t, pr.BotsDone, "a bot that has not reviewed yet is still pending")
+}
+
+func TestHandleBotReview_InlineOnly(t *testing.T) {
+	h := config.BotHandler{Name: "coderabbit", Authors: []string{"coderabbitai"}, NoFeedbackPhrases: []string{"No actionable comments were generated"}, InlineOnly: true}
+	walkthrough := provider.Comment{ThreadID: "10", Author: "coderabbitai[bot]", Body: "## Walkthrough\nAdds retries.", CommentType: "review", IsResolved: true}
+
+	// A walkthrough alone does not mean the review is finished.
+	pr := &PRDocument{ID: "7"}
+	_, err := handleBotReview(context.Background(), pr, h, []provider.Comment{walkthrough}, &threadBackend{}, nil, &config.Config{}, "")
+	require.NoError(t, err)
+	assert.Empty(t, pr.BotsDone)
+
+	summary := provider.Comment{ThreadID: "11", Author: "coderabbitai[bot]", Body: "**Actionable comments posted: 0**\nNo actionable comments were generated in the recent review.", CommentType: "review", IsResolved: true}
+	backend := &threadBackend{}
+	_, err = handleBotReview(context.Background(), pr, h, []provider.Comment{walkthrough, summary}, backend, nil, &config.Config{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"coderabbit"}, pr.BotsDone)
+	assert.Empty(t, backend.resolved, "review summaries have no thread to resolve")
+}
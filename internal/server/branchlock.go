@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// ErrBranchBusy is returned when another otto process (the daemon or a CLI
+// command) is already running a git operation on the PR's branch.
+var ErrBranchBusy = errors.New("another otto operation is in progress on this PR's branch")
+
+// branchLockWait is how long an operation waits for the branch lock before
+// giving up with ErrBranchBusy. Fixes and conflict resolution take minutes,
+// so the later caller backs off rather than queueing behind them; the
+// daemon retries on its next poll.
+var branchLockWait = 10 * time.Second
+
+// branchLockDir holds one lock file per PR.
+func branchLockDir() string {
+	return filepath.Join(filepath.Dir(PRDir()), "locks")
+}
+
+func branchLockPath(pr *PRDocument) string {
+	name := unsafeFilenameChars.ReplaceAllString(pr.Provider+"-"+pr.ID, "_")
+	return filepath.Join(branchLockDir(), name+".lock")
+}
+
+// lockBranch takes the file lock that serializes otto's git operations
+// (fixes, conflict resolution, comment and MerlinBot fixes) on pr's branch
+// across the daemon and CLI processes, so their pushes cannot clobber each
+// other. op names the operation for the busy error seen by the other side.
+// The returned function releases the lock.
+func lockBranch(ctx context.Context, pr *PRDocument, op string) (unlock func(), err error) {
+	path := branchLockPath(pr)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating lock directory: %w", err)
+	}
+	fileLock := flock.New(path)
+
+	waitCtx, cancel := context.WithTimeout(ctx, branchLockWait)
+	defer cancel()
+	locked, err := fileLock.TryLockContext(waitCtx, 250*time.Millisecond)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("acquiring branch lock: %w", err)
+	}
+	if !locked {
+		holder, _ := os.ReadFile(path)
+		if h := strings.TrimSpace(string(holder)); h != "" {
+			return nil, fmt.Errorf("%w (%s)", ErrBranchBusy, h)
+		}
+		return nil, ErrBranchBusy
+	}
+
+	// Record the holder for anyone who finds the branch busy. The lock is
+	// on the file itself, so its content is only informational.
+	holder := fmt.Sprintf("%s by pid %d since %s", op, os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	if err := os.WriteFile(path, []byte(holder+"\n"), 0644); err != nil {
+		slog.Debug("failed to record branch lock holder", "path", path, "error", err)
+	}
+	slog.Debug("acquired branch lock", "prID", pr.ID, "op", op)
+
+	return func() {
+		_ = os.Truncate(path, 0)
+		if err := fileLock.Unlock(); err != nil {
+			slog.Warn("failed to release branch lock", "prID", pr.ID, "error", err)
+		}
+	}, nil
+}
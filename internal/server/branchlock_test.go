@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockBranch(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	ctx := context.Background()
+	pr := &PRDocument{ID: "42", Provider: "ado"}
+
+	unlock, err := lockBranch(ctx, pr, "fix")
+	require.NoError(t, err)
+
+	// Cancelling the caller's context ends the wait early.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = lockBranch(cancelled, pr, "conflict resolution")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// A different PR is independent.
+	other, err := lockBranch(ctx, &PRDocument{ID: "43", Provider: "ado"}, "fix")
+	require.NoError(t, err)
+	other()
+
+	unlock()
+	unlock, err = lockBranch(ctx, pr, "conflict resolution")
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestLockBranch_Busy(t *testing.T) {
+	defer func(d time.Duration) { branchLockWait = d }(branchLockWait)
+	branchLockWait = 300 * time.Millisecond
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	pr := &PRDocument{ID: "42", Provider: "ado"}
+
+	unlock, err := lockBranch(context.Background(), pr, "fix")
+	require.NoError(t, err)
+	defer unlock()
+
+	_, err = lockBranch(context.Background(), pr, "conflict resolution")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrBranchBusy))
+	assert.Contains(t, err.Error(), "fix by pid")
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// cancelDoomedBuilds cancels running builds that have already failed a
+// task or job (pr.cancel_doomed_builds), so a compile error two minutes
+// into an hour-long pipeline doesn't wait out the other 58. Once a
+// canceled build completes the pipeline reports failed, and the next poll
+// fixes it from the failed task's logs as usual. It returns the names of
+// the builds canceled.
+func cancelDoomedBuilds(ctx context.Context, pr *PRDocument, backend provider.PRBackend, prInfo *provider.PRInfo, status *provider.PipelineStatus) []string {
+	var canceled []string
+	for _, build := range status.Builds {
+		// ADO reports "inProgress", GitHub "in_progress"; a build already
+		// cancelling is left alone.
+		if build.Status != "inProgress" && build.Status != "in_progress" {
+			continue
+		}
+		logs, err := backend.GetBuildLogs(ctx, prInfo, build.ID)
+		if err != nil {
+			slog.Warn("failed to get logs of running build", "prID", pr.ID, "buildID", build.ID, "error", err)
+			continue
+		}
+		if !strings.Contains(logs, provider.FailedLogSection) {
+			continue
+		}
+		if err := backend.CancelBuild(ctx, prInfo, build.ID); err != nil {
+			slog.Warn("failed to cancel doomed build", "prID", pr.ID, "buildID", build.ID, "error", err)
+			continue
+		}
+		slog.Info("canceled build that already failed", "prID", pr.ID, "buildID", build.ID, "buildName", build.Name)
+		canceled = append(canceled, build.Name)
+	}
+	if len(canceled) > 0 {
+		logPREvent(pr, PREvent{Kind: PREventBuildCanceled, Build: strings.Join(canceled, ", "), Detail: "failed while still running"})
+	}
+	return canceled
+}
+
+// cancelSupersededBuilds cancels the builds in pipeline that were queued or
+// running for the commit a fix was just pushed on top of: the push starts
+// fresh ones, and the old ones would only hold agents. The snapshot is from
+// before the fix, so builds that have finished since fail to cancel, which
+// is only logged.
+func cancelSupersededBuilds(ctx context.Context, pr *PRDocument, backend provider.PRBackend, prInfo *provider.PRInfo, pipeline []provider.BuildInfo, commitHash string) {
+	var canceled []string
+	for _, build := range pipeline {
+		if build.Status == "" || build.Status == "completed" || build.Status == "cancelling" {
+			continue
+		}
+		if err := backend.CancelBuild(ctx, prInfo, build.ID); err != nil {
+			slog.Debug("failed to cancel superseded build", "prID", pr.ID, "buildID", build.ID, "error", err)
+			continue
+		}
+		canceled = append(canceled, build.Name)
+	}
+	if len(canceled) > 0 {
+		slog.Info("canceled builds superseded by fix", "prID", pr.ID, "commit", commitHash, "builds", canceled)
+		logPREvent(pr, PREvent{Kind: PREventBuildCanceled, Build: strings.Join(canceled, ", "), Detail: "superseded by " + commitHash, Commit: commitHash})
+	}
+}
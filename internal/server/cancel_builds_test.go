@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type doomedBackend struct {
+	provider.PRBackend
+	logs     map[string]string
+	canceled []string
+}
+
+func (b *doomedBackend) GetBuildLogs(ctx context.Context, pr *provider.PRInfo, buildID string) (string, error) {
+	return b.logs[buildID], nil
+}
+
+func (b *doomedBackend) CancelBuild(ctx context.Context, pr *provider.PRInfo, buildID string) error {
+	b.canceled = append(b.canceled, buildID)
+	return nil
+}
+
+func TestCancelDoomedBuilds(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	pr := &PRDocument{ID: "1", Provider: "ado"}
+	backend := &doomedBackend{logs: map[string]string{
+		"10": provider.FailedLogSection + "Task: Build ===\nerror CS1002: ; expected\n",
+		"11": "No failed tasks found in build timeline.",
+		"12": provider.FailedLogSection + "Task: Test ===\n",
+	}}
+	status := &provider.PipelineStatus{State: "inProgress", Builds: []provider.BuildInfo{
+		{ID: "10", Name: "ci", Status: "inProgress"},
+		{ID: "11", Name: "e2e", Status: "inProgress"},
+		{ID: "12", Name: "perf", Status: "cancelling"},
+	}}
+
+	canceled := cancelDoomedBuilds(context.Background(), pr, backend, &provider.PRInfo{ID: "1"}, status)
+	assert.Equal(t, []string{"ci"}, canceled)
+	assert.Equal(t, []string{"10"}, backend.canceled, "only running builds with a failed task")
+
+	events, err := ReadPREvents("ado", "1")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, PREventBuildCanceled, events[0].Kind)
+	assert.Equal(t, "ci", events[0].Build)
+}
+
+func TestCancelSupersededBuilds(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	pr := &PRDocument{ID: "1", Provider: "github"}
+	backend := &doomedBackend{}
+	pipeline := []provider.BuildInfo{
+		{ID: "10", Name: "build", Status: "completed", Result: "failure"},
+		{ID: "11", Name: "e2e", Status: "in_progress"},
+		{ID: "12", Name: "deploy", Status: "queued"},
+	}
+
+	cancelSupersededBuilds(context.Background(), pr, backend, &provider.PRInfo{ID: "1"}, pipeline, "abc123")
+	assert.Equal(t, []string{"11", "12"}, backend.canceled)
+
+	events, err := ReadPREvents("github", "1")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "e2e, deploy", events[0].Build)
+	assert.Equal(t, "superseded by abc123", events[0].Detail)
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// fixCheckpoint is the progress of a FixPR interrupted by a daemon
+// shutdown: the Phase 1 diagnosis and whatever the Phase 2 session had
+// changed in the worktree so far. The next attempt on the same failed
+// builds resumes from it instead of analyzing the logs again.
+type fixCheckpoint struct {
+	Provider     string          `json:"provider"`
+	PRID         string          `json:"pr_id"`
+	Attempt      int             `json:"attempt"`
+	FailedBuilds []string        `json:"failed_builds"`
+	Diagnosis    string          `json:"diagnosis"`
+	Category     FailureCategory `json:"category"`
+	Signature    string          `json:"signature,omitempty"` // of the failure (see recheckInfra)
+	Model        string          `json:"model,omitempty"`
+	Head         string          `json:"head,omitempty"`  // commit the patch applies to
+	Patch        string          `json:"patch,omitempty"` // uncommitted Phase 2 changes
+	SavedAt      time.Time       `json:"saved_at"`
+}
+
+// fixCheckpointDir returns where interrupted fixes are checkpointed.
+func fixCheckpointDir() string {
+	return filepath.Join(filepath.Dir(PRDir()), "checkpoints")
+}
+
+func fixCheckpointPath(providerName, id string) string {
+	return filepath.Join(fixCheckpointDir(), unsafeFilenameChars.ReplaceAllString(providerName+"-"+id, "_")+".json")
+}
+
+// saveFixCheckpoint records cp along with the uncommitted changes in
+// workDir. It runs while the daemon shuts down, so it uses its own short
+// deadline rather than the cancelled fix context.
+func saveFixCheckpoint(cp *fixCheckpoint, workDir string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	head, err := gitOutput(ctx, workDir, "rev-parse", "HEAD")
+	if err == nil {
+		cp.Head = strings.TrimSpace(head)
+		// Stage everything so new files are part of the patch.
+		if err = runGit(ctx, workDir, "add", "-A"); err == nil {
+			cp.Patch, err = gitOutput(ctx, workDir, "diff", "--cached", "--binary", "HEAD")
+		}
+	}
+	if err != nil {
+		// The diagnosis alone still saves the analysis.
+		slog.Warn("could not capture in-progress fix changes", "prID", cp.PRID, "error", err)
+		cp.Head, cp.Patch = "", ""
+	}
+	cp.SavedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(fixCheckpointDir(), 0700); err != nil {
+		return fmt.Errorf("creating checkpoint directory: %w", err)
+	}
+	return os.WriteFile(fixCheckpointPath(cp.Provider, cp.PRID), data, 0600)
+}
+
+// loadFixCheckpoint reads the checkpoint at path.
+func loadFixCheckpoint(path string) (*fixCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cp fixCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &cp, nil
+}
+
+// takeFixCheckpoint returns pr's checkpoint if it was saved by this same
+// attempt on the same failed builds, and removes it either way: a
+// checkpoint is resumed once, and a stale one is of no further use.
+func takeFixCheckpoint(pr *PRDocument, failedBuilds []string) *fixCheckpoint {
+	path := fixCheckpointPath(pr.Provider, pr.ID)
+	cp, err := loadFixCheckpoint(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	_ = os.Remove(path)
+	if err != nil {
+		slog.Warn("discarding unreadable fix checkpoint", "prID", pr.ID, "error", err)
+		return nil
+	}
+	if cp.Attempt != pr.FixAttempts+1 || !sameBuilds(cp.FailedBuilds, failedBuilds) {
+		slog.Info("discarding stale fix checkpoint", "prID", pr.ID, "attempt", cp.Attempt)
+		return nil
+	}
+	return cp
+}
+
+// sameBuilds reports whether a and b name the same builds, in any order.
+func sameBuilds(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// applyFixCheckpoint restores the checkpoint's uncommitted changes in
+// workDir, if it still holds the commit they were made on.
+func applyFixCheckpoint(ctx context.Context, cp *fixCheckpoint, workDir string) error {
+	if cp.Patch == "" {
+		return nil
+	}
+	head, err := gitOutput(ctx, workDir, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(head) != cp.Head {
+		return fmt.Errorf("branch moved from %s since the checkpoint", cp.Head)
+	}
+	cmd := exec.CommandContext(ctx, "git", "apply", "--binary")
+	cmd.Dir = workDir
+	cmd.Stdin = strings.NewReader(cp.Patch)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git apply: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// resumeNote tells the Phase 2 session about the changes restored from an
+// interrupted session.
+const resumeNote = `
+
+Note: an earlier fix session for this diagnosis was interrupted. Its partial changes are already applied in the working tree; review them, keep what is right, and complete the fix.`
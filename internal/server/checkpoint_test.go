@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixCheckpoint(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	workDir := newRepoWithRemote(t)
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "fix.go"), []byte("package fix\n"), 0644))
+
+	pr := &PRDocument{ID: "7", Provider: "ado", FixAttempts: 1}
+	require.NoError(t, SavePR(pr))
+	require.NoError(t, saveFixCheckpoint(&fixCheckpoint{
+		Provider:     "ado",
+		PRID:         "7",
+		Attempt:      2,
+		FailedBuilds: []string{"b2", "b1"},
+		Diagnosis:    "missing import",
+		Category:     CategoryCompile,
+		Signature:    "5f3a9c01",
+	}, workDir))
+
+	report := &RecoveryReport{}
+	recoverFixCheckpoints(report)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, RecoveryFixCheckpoint, report.Issues[0].Kind)
+	assert.Equal(t, "7", report.Issues[0].Subject)
+
+	// A fresh worktree of the same commit gets the changes back.
+	fresh := t.TempDir()
+	gitT(t, workDir, "worktree", "add", "-q", "--detach", fresh, "HEAD")
+	cp := takeFixCheckpoint(pr, []string{"b1", "b2"})
+	require.NotNil(t, cp)
+	assert.Equal(t, "missing import", cp.Diagnosis)
+	assert.Equal(t, CategoryCompile, cp.Category)
+	assert.Equal(t, "5f3a9c01", cp.Signature)
+	require.NoError(t, applyFixCheckpoint(context.Background(), cp, fresh))
+	data, err := os.ReadFile(filepath.Join(fresh, "fix.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package fix\n", string(data))
+
+	assert.Nil(t, takeFixCheckpoint(pr, []string{"b1", "b2"}), "a checkpoint is resumed once")
+}
+
+func TestTakeFixCheckpointStale(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	pr := &PRDocument{ID: "7", Provider: "ado"}
+	require.NoError(t, saveFixCheckpoint(&fixCheckpoint{Provider: "ado", PRID: "7", Attempt: 1, FailedBuilds: []string{"b1"}}, t.TempDir()))
+
+	assert.Nil(t, takeFixCheckpoint(pr, []string{"b3"}), "the builds failing now are different")
+	_, err := os.Stat(fixCheckpointPath("ado", "7"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// Checkpoints of PRs no longer tracked are removed at boot.
+	require.NoError(t, saveFixCheckpoint(&fixCheckpoint{Provider: "ado", PRID: "7", Attempt: 1}, t.TempDir()))
+	report := &RecoveryReport{}
+	recoverFixCheckpoints(report)
+	require.Len(t, report.Issues, 1)
+	assert.True(t, report.Issues[0].Repaired)
+	_, err = os.Stat(fixCheckpointPath("ado", "7"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/logdistill"
+	"github.com/alanmeadows/otto/internal/store"
+)
+
+// ClassificationStats tracks, for one repository, how FixPR analysis has
+// classified failures and how its infrastructure calls held up: an
+// infrastructure failure is confirmed when the retried builds go green,
+// and wrong when the same failure comes back after the retry.
+type ClassificationStats struct {
+	Repo string `json:"repo"`
+	// Categories counts analyses by the category the LLM chose.
+	Categories map[string]int `json:"categories"`
+	// InfraConfirmed counts infrastructure retries after which the
+	// pipeline passed.
+	InfraConfirmed int `json:"infra_confirmed"`
+	// InfraRecurred counts failures retried as infrastructure that came
+	// back unchanged; InfraReclassified counts those the LLM called
+	// infrastructure again and otto fixed as code instead.
+	InfraRecurred     int       `json:"infra_recurred"`
+	InfraReclassified int       `json:"infra_reclassified"`
+	Updated           time.Time `json:"updated"`
+}
+
+// InfraAccuracy returns the share of infrastructure classifications with a
+// known outcome that were right, and false if none has an outcome yet.
+func (s ClassificationStats) InfraAccuracy() (float64, bool) {
+	n := s.InfraConfirmed + s.InfraRecurred
+	if n == 0 {
+		return 0, false
+	}
+	return float64(s.InfraConfirmed) / float64(n), true
+}
+
+// reclassifiedNote is appended to the fix prompt of a failure reclassified
+// as CODE, whose diagnosis still calls it an infrastructure failure.
+const reclassifiedNote = `
+
+## Note: Reclassified as a Code Failure
+
+The diagnosis above attributes this failure to infrastructure, but the failed builds were already retried for that reason and failed again the same way. Treat the failure as caused by this PR's code (or its tests) and fix it.`
+
+func classificationPath() string {
+	return filepath.Join(filepath.Dir(PRDir()), "classification.json")
+}
+
+// failureSignature identifies a failure across pipeline runs: the builds
+// that failed and the distinct errors distilled from their logs.
+func failureSignature(builds []string, logs string) string {
+	parts := slices.Clone(builds)
+	for _, f := range logdistill.Distill(logs).Findings {
+		parts = append(parts, f.Fingerprint())
+	}
+	sort.Strings(parts)
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// recheckInfra checks an analyzed failure against those pr had retried as
+// infrastructure failures. One that came back unchanged was not an
+// infrastructure failure after all: its tests are no longer counted as
+// flaky, and if the LLM calls it infrastructure again it is reclassified
+// as CODE so otto fixes it instead of retrying forever. It returns the
+// category to act on, the failure's signature, and whether it was
+// reclassified.
+func recheckInfra(pr *PRDocument, category FailureCategory, builds []string, logs string) (FailureCategory, string, bool) {
+	sig := failureSignature(builds, logs)
+	recordClassification(pr.Repo, func(s *ClassificationStats) { s.Categories[string(category)]++ })
+	if !slices.Contains(pr.InfraFailures, sig) {
+		return category, sig, false
+	}
+
+	if tests, _ := failingTests(logs); len(tests) > 0 {
+		if err := unrecordFlakyTests(pr.Repo, tests); err != nil {
+			slog.Warn("failed to update flaky test database", "prID", pr.ID, "error", err)
+		}
+	}
+	reclassified := category == CategoryInfra
+	recordClassification(pr.Repo, func(s *ClassificationStats) {
+		s.InfraRecurred++
+		if reclassified {
+			s.InfraReclassified++
+		}
+	})
+	err := savePRChange(pr, func(p *PRDocument) {
+		p.InfraFailures = slices.DeleteFunc(slices.Clone(p.InfraFailures), func(s string) bool { return s == sig })
+	})
+	if err != nil {
+		slog.Warn("failed to save PR infra failures", "prID", pr.ID, "error", err)
+	}
+	if !reclassified {
+		return category, sig, false
+	}
+	slog.Info("infrastructure failure recurred after retry, reclassifying as code", "prID", pr.ID, "builds", builds)
+	return CategoryCode, sig, true
+}
+
+// confirmInfraFailures records that pr's infrastructure retries were right:
+// its pipeline passed after them.
+func confirmInfraFailures(pr *PRDocument) {
+	if len(pr.InfraFailures) == 0 {
+		return
+	}
+	n := len(pr.InfraFailures)
+	recordClassification(pr.Repo, func(s *ClassificationStats) { s.InfraConfirmed += n })
+	pr.InfraFailures = nil
+}
+
+// recordClassification applies fn to repo's stats, best effort.
+func recordClassification(repo string, fn func(s *ClassificationStats)) {
+	path := classificationPath()
+	err := store.WithLock(path, store.DefaultLockTimeout, func() error {
+		db, err := readClassificationDB(path)
+		if err != nil {
+			return err
+		}
+		s, ok := db[repo]
+		if !ok {
+			s = &ClassificationStats{Repo: repo}
+			db[repo] = s
+		}
+		if s.Categories == nil {
+			s.Categories = make(map[string]int)
+		}
+		fn(s)
+		s.Updated = time.Now().UTC()
+		data, err := json.MarshalIndent(db, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling classification stats: %w", err)
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return fmt.Errorf("writing classification stats: %w", err)
+		}
+		return os.Rename(tmp, path)
+	})
+	if err != nil {
+		slog.Warn("failed to record classification stats", "repo", repo, "error", err)
+	}
+}
+
+// ListClassificationStats returns the classification stats of repo, or of
+// every repository when repo is empty, sorted by repository.
+func ListClassificationStats(repo string) ([]ClassificationStats, error) {
+	var stats []ClassificationStats
+	path := classificationPath()
+	err := store.WithReadLock(path, store.DefaultLockTimeout, func() error {
+		db, err := readClassificationDB(path)
+		for name, s := range db {
+			if repo == "" || name == repo {
+				stats = append(stats, *s)
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Repo < stats[j].Repo })
+	return stats, nil
+}
+
+// readClassificationDB loads the stats keyed by repository. A missing file
+// is empty.
+func readClassificationDB(path string) (map[string]*ClassificationStats, error) {
+	db := make(map[string]*ClassificationStats)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading classification stats: %w", err)
+	}
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, fmt.Errorf("parsing classification stats %s: %w", path, err)
+	}
+	return db, nil
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureSignature(t *testing.T) {
+	a := failureSignature([]string{"CI", "Lint"}, "--- FAIL: TestRetry (1.20s)\npkg/a.go:3:1: undefined: X")
+	b := failureSignature([]string{"Lint", "CI"}, "pkg/a.go:3:1: undefined: X\n--- FAIL: TestRetry (0.40s)")
+	assert.Equal(t, a, b, "order and timings don't matter")
+	assert.NotEqual(t, a, failureSignature([]string{"CI"}, "--- FAIL: TestRetry (1.20s)\npkg/a.go:3:1: undefined: X"))
+	assert.NotEqual(t, a, failureSignature([]string{"CI", "Lint"}, "--- FAIL: TestOther (1.20s)\npkg/a.go:3:1: undefined: X"))
+}
+
+func TestRecheckInfra(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	pr := &PRDocument{ID: "101", Provider: "ado", Repo: "org/svc"}
+	require.NoError(t, SavePR(pr))
+
+	logs := "--- FAIL: TestRetry (1.20s)\n    retry_test.go:40: connection reset"
+	tests, _ := failingTests(logs)
+	require.NoError(t, recordFlakyTests("org/svc", "101", tests))
+
+	// A first infrastructure failure is retried as one.
+	category, sig, reclassified := recheckInfra(pr, CategoryInfra, []string{"CI"}, logs)
+	assert.Equal(t, CategoryInfra, category)
+	assert.False(t, reclassified)
+	require.NoError(t, savePRChange(pr, func(p *PRDocument) { p.InfraFailures = []string{sig} }))
+
+	// The same failure after the retry is fixed as code.
+	category, _, reclassified = recheckInfra(pr, CategoryInfra, []string{"CI"}, logs)
+	assert.Equal(t, CategoryCode, category)
+	assert.True(t, reclassified)
+	assert.Empty(t, pr.InfraFailures)
+	saved, err := LoadPR("ado", "101")
+	require.NoError(t, err)
+	assert.Empty(t, saved.InfraFailures)
+	flaky, err := ListFlakyTests("org/svc")
+	require.NoError(t, err)
+	assert.Empty(t, flaky, "a failure that recurs is not flaky")
+
+	// Another failure is not.
+	pr.InfraFailures = []string{sig}
+	category, _, reclassified = recheckInfra(pr, CategoryInfra, []string{"CI"}, "--- FAIL: TestOther (0.00s)")
+	assert.Equal(t, CategoryInfra, category)
+	assert.False(t, reclassified)
+
+	confirmInfraFailures(pr)
+	assert.Empty(t, pr.InfraFailures)
+
+	stats, err := ListClassificationStats("org/svc")
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, map[string]int{string(CategoryInfra): 3}, stats[0].Categories)
+	assert.Equal(t, 1, stats[0].InfraRecurred)
+	assert.Equal(t, 1, stats[0].InfraReclassified)
+	assert.Equal(t, 1, stats[0].InfraConfirmed)
+	accuracy, ok := stats[0].InfraAccuracy()
+	assert.True(t, ok)
+	assert.InDelta(t, 0.5, accuracy, 0.001)
+
+	none, err := ListClassificationStats("other-repo")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/audit"
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/alanmeadows/otto/internal/repo"
+)
+
+// prSessionPrefixes are the title prefixes of the LLM sessions otto opens
+// for a PR; each is followed by the PR ID.
+var prSessionPrefixes = []string{
+	"Comment Response PR#",
+	"PR Fix Analysis #",
+	"PR Fix #",
+	"Conflict Resolution #",
+	"Bot Review PR#",
+	"MerlinBot PR#",
+}
+
+// isPRSessionTitle reports whether title names a session otto opened for PR
+// id. The ID must be followed by the end of the title or a space, so PR 12
+// does not match sessions for PR 123.
+func isPRSessionTitle(title, id string) bool {
+	for _, prefix := range prSessionPrefixes {
+		rest, ok := strings.CutPrefix(title, prefix+id)
+		if ok && (rest == "" || rest[0] == ' ') {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanupClosedPR releases what otto holds for a PR that was just merged or
+// abandoned: its pooled worktree, any LLM sessions an interrupted operation
+// left on the server, and, for merged PRs with pr.delete_branch_on_merge,
+// the source branch on origin. A source branch in a fork (info.Fork) is not
+// origin's to delete and is left alone. Every step is best effort; failures
+// are logged and never keep the PR from being marked closed.
+func cleanupClosedPR(ctx context.Context, pr *PRDocument, info *provider.PRInfo, cfg *config.Config, client llm.Client) {
+	repoCfg, err := repo.NewManager("").FindByRemoteURL(cfg, pr.URL)
+	if err != nil {
+		slog.Debug("no local repo for closed PR, skipping cleanup", "prID", pr.ID, "error", err)
+		return
+	}
+	branch := strings.TrimPrefix(pr.Branch, "refs/heads/")
+
+	if cfg.PR.WorktreePool.Enabled {
+		if err := repo.NewPool(cfg.PR.WorktreePool).Discard(repoCfg, branch); err != nil {
+			slog.Warn("failed to discard pooled worktree", "prID", pr.ID, "error", err)
+		}
+	}
+
+	if pruner, ok := client.(llm.SessionPruner); ok {
+		n, err := pruner.PruneSessions(ctx, repoCfg.PrimaryDir, func(title string) bool {
+			return isPRSessionTitle(title, pr.ID)
+		})
+		if err != nil {
+			slog.Warn("failed to delete lingering LLM sessions", "prID", pr.ID, "error", err)
+		}
+		if n > 0 {
+			slog.Info("deleted lingering LLM sessions", "prID", pr.ID, "count", n)
+		}
+	}
+
+	if pr.Status == "merged" && cfg.PR.DeleteBranchOnMerge && branch != "" {
+		if info.Fork {
+			slog.Info("merged branch is in a fork, not deleting it", "prID", pr.ID, "branch", branch)
+			return
+		}
+		if err := deleteRemoteBranch(ctx, repoCfg.PrimaryDir, branch); err != nil {
+			slog.Warn("failed to delete merged branch", "prID", pr.ID, "branch", branch, "error", err)
+			return
+		}
+		slog.Info("deleted merged branch", "prID", pr.ID, "branch", branch)
+		audit.Log(audit.Entry{
+			Action:   audit.ActionBranchDeleted,
+			Provider: pr.Provider,
+			PRID:     pr.ID,
+			PRURL:    pr.URL,
+			Branch:   pr.Branch,
+		})
+	}
+}
+
+// deleteRemoteBranch deletes branch from origin. A branch that is already
+// gone (e.g. the provider deleted it on completion) is not an error.
+func deleteRemoteBranch(ctx context.Context, repoDir, branch string) error {
+	cmd := exec.CommandContext(ctx, "git", "push", "origin", "--delete", branch)
+	cmd.Dir = repoDir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(out), "remote ref does not exist") {
+			return nil
+		}
+		return fmt.Errorf("git push --delete: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
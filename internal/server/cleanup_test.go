@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPRSessionTitle(t *testing.T) {
+	assert.True(t, isPRSessionTitle("PR Fix #12 attempt 2", "12"))
+	assert.True(t, isPRSessionTitle("PR Fix Analysis #12", "12"))
+	assert.True(t, isPRSessionTitle("Comment Response PR#12", "12"))
+	assert.True(t, isPRSessionTitle("Conflict Resolution #12", "12"))
+	assert.True(t, isPRSessionTitle("Bot Review PR#12 coderabbit", "12"))
+	assert.False(t, isPRSessionTitle("PR Fix #123 attempt 1", "12"))
+	assert.False(t, isPRSessionTitle("Review PR #12", "12"))
+}
+
+func TestDeleteRemoteBranch(t *testing.T) {
+	repoDir := newRepoWithRemote(t)
+	origin := filepath.Join(filepath.Dir(repoDir), "origin.git")
+	gitT(t, repoDir, "push", "-q", "origin", "HEAD:refs/heads/feature/done")
+
+	require.NoError(t, deleteRemoteBranch(context.Background(), repoDir, "feature/done"))
+	assert.Empty(t, gitT(t, origin, "branch", "--list", "feature/done"))
+
+	// Already deleted, e.g. by the provider on completion.
+	require.NoError(t, deleteRemoteBranch(context.Background(), repoDir, "feature/done"))
+}
+
+func TestCleanupClosedPRLeavesForkBranches(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	repoDir := newRepoWithRemote(t)
+	origin := filepath.Join(filepath.Dir(repoDir), "origin.git")
+	gitT(t, repoDir, "push", "-q", "origin", "HEAD:refs/heads/feature/done")
+	gitT(t, repoDir, "config", "remote.origin.pushurl", origin)
+	gitT(t, repoDir, "remote", "set-url", "origin", "https://github.com/acme/svc.git")
+
+	cfg := &config.Config{Repos: []config.RepoConfig{{Name: "svc", PrimaryDir: repoDir}}}
+	cfg.PR.DeleteBranchOnMerge = true
+	pr := &PRDocument{ID: "5", Provider: "github", URL: "https://github.com/acme/svc/pull/5", Branch: "feature/done", Status: "merged"}
+
+	// A same-named branch on origin is not the fork's.
+	cleanupClosedPR(context.Background(), pr, &provider.PRInfo{Fork: true}, cfg, nil)
+	assert.NotEmpty(t, gitT(t, origin, "branch", "--list", "feature/done"))
+
+	cleanupClosedPR(context.Background(), pr, &provider.PRInfo{}, cfg, nil)
+	assert.Empty(t, gitT(t, origin, "branch", "--list", "feature/done"))
+}
@@ -2,14 +2,16 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/contextpack"
 	"github.com/alanmeadows/otto/internal/llm"
 	"github.com/alanmeadows/otto/internal/prompts"
 	"github.com/alanmeadows/otto/internal/provider"
@@ -37,11 +39,37 @@ func aiFooter(cfg *config.Config) string {
 func evaluateComment(ctx context.Context, pr *PRDocument, comment provider.Comment, backend provider.PRBackend, client llm.Client, cfg *config.Config, workDir string) (bool, error) {
 	slog.Info("evaluating comment", "prID", pr.ID, "commentID", comment.ID, "author", comment.Author)
 
-	// Read code context around the commented line.
+	prInfo := &provider.PRInfo{
+		ID:           pr.ID,
+		URL:          pr.URL,
+		RepoID:       pr.Repo,
+		SourceBranch: pr.Branch,
+		TargetBranch: pr.Target,
+	}
+
+	// Read code context around the commented line. A comment made on an
+	// earlier iteration is anchored to that snapshot of the file, so read
+	// the context from the snapshot and show what has changed since.
 	codeContext := readCodeContext(workDir, comment.FilePath, comment.Line, 10)
+	var iterationDiff string
+	if comment.Iteration > 0 && comment.FilePath != "" && comment.Line > 0 {
+		snapshot, err := backend.GetFileAtIteration(ctx, prInfo, comment.Iteration, comment.FilePath)
+		if err != nil {
+			if !errors.Is(err, provider.ErrUnsupported) {
+				slog.Warn("failed to fetch file at comment iteration", "prID", pr.ID, "iteration", comment.Iteration, "file", comment.FilePath, "error", err)
+			}
+		} else {
+			codeContext = formatCodeContext(snapshot, comment.Line, 10)
+			iterationDiff, err = diffSinceSnapshot(ctx, workDir, comment.FilePath, snapshot)
+			if err != nil {
+				slog.Warn("failed to diff comment iteration against working tree", "prID", pr.ID, "file", comment.FilePath, "error", err)
+			}
+		}
+	}
 
 	// Build the prompt using the pr-comment-respond template.
 	templateData := map[string]string{
+		"pr_diff":        fetchPRDiff(ctx, backend, prInfo),
 		"pr_title":       pr.Title,
 		"pr_description": "",
 		"comment_author": comment.Author,
@@ -49,6 +77,18 @@ func evaluateComment(ctx context.Context, pr *PRDocument, comment provider.Comme
 		"comment_line":   fmt.Sprintf("%d", comment.Line),
 		"comment_body":   comment.Body,
 		"code_context":   codeContext,
+		"iteration_diff": iterationDiff,
+		"repo_context": repoContext(ctx, cfg, pr.URL, workDir, contextpack.Request{
+			Query: comment.Body,
+			Files: []contextpack.FileRef{{Path: comment.FilePath, Line: comment.Line, Reason: "commented on"}},
+		}),
+	}
+	if iterationDiff != "" {
+		templateData["comment_iteration"] = fmt.Sprintf("%d", comment.Iteration)
+	}
+	suggestOnly := cfg != nil && cfg.PR.SuggestCommentFixes
+	if suggestOnly {
+		templateData["suggest_only"] = "true"
 	}
 
 	prompt, err := prompts.Execute("pr-comment-respond.md", templateData)
@@ -63,20 +103,13 @@ func evaluateComment(ctx context.Context, pr *PRDocument, comment provider.Comme
 	}
 	defer client.DeleteSession(ctx, session.ID)
 
-	resp, err := client.SendPrompt(ctx, session.ID, prompt)
+	resp, err := client.SendPrompt(ctx, session.ID, withRepoInstructions(prompt, workDir))
 	if err != nil {
 		return false, fmt.Errorf("sending prompt: %w", err)
 	}
 
 	// Parse the response — expect JSON with decision/reply.
 	content := resp.Content
-	prInfo := &provider.PRInfo{
-		ID:           pr.ID,
-		URL:          pr.URL,
-		RepoID:       pr.Repo,
-		SourceBranch: pr.Branch,
-		TargetBranch: pr.Target,
-	}
 
 	// Parse JSON response using the generic ParseJSONResponse.
 	commentResp, err := llm.ParseJSONResponse[CommentResponse](ctx, client, session.ID, content)
@@ -101,9 +134,25 @@ func evaluateComment(ctx context.Context, pr *PRDocument, comment provider.Comme
 	// Resolve the thread based on decision.
 	switch strings.ToUpper(commentResp.Decision) {
 	case "AGREE":
-		// The LLM should have made code changes in the session.
-		// Commit locally (push is batched by the caller).
-		commitHash, err := gitCommit(ctx, workDir, fmt.Sprintf("address review comment on %s:%d", comment.FilePath, comment.Line))
+		// The LLM should have made code changes in the session. Offer them
+		// as a suggestion, leaving the thread for the author to apply it,
+		// or commit them locally (push is batched by the caller).
+		if suggestOnly {
+			// Only GitHub renders suggestion blocks; ADO gets a patch.
+			suggestion, err := suggestedChange(ctx, workDir, comment.FilePath, comment.Line, pr.Provider == "github")
+			if err != nil {
+				slog.Warn("failed to build suggested change", "error", err, "threadID", comment.ThreadID)
+			} else if suggestion == "" {
+				slog.Warn("no changes to suggest for AGREE decision", "threadID", comment.ThreadID)
+			} else if err := backend.ReplyToComment(ctx, prInfo, comment.ThreadID, suggestion+aiFooter(cfg)); err != nil {
+				slog.Warn("failed to reply to comment", "error", err, "threadID", comment.ThreadID)
+			}
+			if err := discardChanges(ctx, workDir); err != nil {
+				slog.Warn("failed to discard suggested change", "error", err, "prID", pr.ID)
+			}
+			break
+		}
+		commitHash, err := gitCommit(ctx, cfg, workDir, fmt.Sprintf("address review comment on %s:%d", comment.FilePath, comment.Line))
 		if err != nil {
 			slog.Warn("no changes to commit for AGREE decision", "error", err)
 		} else {
@@ -130,23 +179,45 @@ func evaluateComment(ctx context.Context, pr *PRDocument, comment provider.Comme
 		slog.Warn("unknown comment decision", "decision", commentResp.Decision)
 	}
 
-	// Update PR document with comment history.
-	pr.Body += fmt.Sprintf("\n\n### Comment by %s on %s:%d - %s\n- **Decision**: %s\n- **Reply**: %s\n",
-		comment.Author, comment.FilePath, comment.Line,
-		time.Now().UTC().Format(time.RFC3339),
-		commentResp.Decision, commentResp.Reply)
-
-	// Track the comment as seen using composite key (threadID:commentID).
-	pr.SeenCommentIDs = append(pr.SeenCommentIDs, fmt.Sprintf("%s:%s", comment.ThreadID, comment.ID))
-
-	// Persist updated PR document.
-	if err := SavePR(pr); err != nil {
+	// Record the comment in the PR's history, and track it as seen using
+	// composite key (threadID:commentID).
+	logPREvent(pr, PREvent{
+		Kind:     PREventComment,
+		Author:   comment.Author,
+		File:     comment.FilePath,
+		Line:     comment.Line,
+		Decision: commentResp.Decision,
+		Reply:    commentResp.Reply,
+	})
+	seen := fmt.Sprintf("%s:%s", comment.ThreadID, comment.ID)
+	err = savePRChange(pr, func(p *PRDocument) {
+		p.SeenCommentIDs = append(p.SeenCommentIDs, seen)
+	})
+	if err != nil {
 		slog.Warn("failed to save PR document after comment evaluation", "error", err)
 	}
 
 	return committed, nil
 }
 
+// maxPromptDiffBytes bounds the PR diff included in an LLM prompt; the
+// worktree is still there for anything the truncated diff leaves out.
+const maxPromptDiffBytes = 60000
+
+// fetchPRDiff returns the PR's diff from the provider for use in a prompt,
+// truncated to maxPromptDiffBytes. It is empty when the diff is unavailable.
+func fetchPRDiff(ctx context.Context, backend provider.PRBackend, pr *provider.PRInfo) string {
+	diff, err := backend.GetDiff(ctx, pr)
+	if err != nil {
+		slog.Warn("failed to fetch PR diff", "prID", pr.ID, "error", err)
+		return ""
+	}
+	if len(diff) > maxPromptDiffBytes {
+		diff = diff[:maxPromptDiffBytes] + "\n... (diff truncated)\n"
+	}
+	return diff
+}
+
 // readCodeContext reads surrounding lines from a file in the work directory.
 func readCodeContext(workDir, filePath string, line, radius int) string {
 	if filePath == "" || line <= 0 {
@@ -157,7 +228,12 @@ func readCodeContext(workDir, filePath string, line, radius int) string {
 	if err != nil {
 		return ""
 	}
-	lines := strings.Split(string(data), "\n")
+	return formatCodeContext(string(data), line, radius)
+}
+
+// formatCodeContext returns the lines within radius of line, numbered.
+func formatCodeContext(content string, line, radius int) string {
+	lines := strings.Split(content, "\n")
 	start := line - radius - 1
 	if start < 0 {
 		start = 0
@@ -172,3 +248,45 @@ func readCodeContext(workDir, filePath string, line, radius int) string {
 	}
 	return buf.String()
 }
+
+// diffSinceSnapshot returns a unified diff from snapshot, an earlier version
+// of filePath, to its current content in workDir. It is empty when the file
+// has not changed.
+func diffSinceSnapshot(ctx context.Context, workDir, filePath, snapshot string) (string, error) {
+	tmp, err := os.CreateTemp("", "otto-snapshot-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(snapshot); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	current := filepath.Join(workDir, filePath)
+	if _, err := os.Stat(current); err != nil {
+		// Deleted or renamed since; diff against nothing.
+		current = os.DevNull
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "diff", "--no-index", "--no-color", "--", tmp.Name(), current)
+	out, err := cmd.Output()
+	if err != nil {
+		// Exit status 1 means the files differ.
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return "", fmt.Errorf("git diff --no-index: %w", err)
+		}
+	}
+
+	// Drop git's header, which names the temp file, and keep the hunks.
+	diff := string(out)
+	idx := strings.Index(diff, "@@")
+	if idx < 0 {
+		return "", nil
+	}
+	return diff[idx:], nil
+}
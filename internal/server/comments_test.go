@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -96,3 +97,18 @@ func TestReadCodeContext_ZeroLine(t *testing.T) {
 	// It should not panic.
 	_ = result
 }
+
+func TestDiffSinceSnapshot(t *testing.T) {
+	workDir := t.TempDir()
+	makeTestFile(t, workDir, "main.go", 5)
+
+	diff, err := diffSinceSnapshot(context.Background(), workDir, "main.go", "Line 1 content\nLine 2 content\nLine 3 content\nLine 4 content\nLine 5 content")
+	require.NoError(t, err)
+	assert.Empty(t, diff, "unchanged file has no diff")
+
+	diff, err = diffSinceSnapshot(context.Background(), workDir, "main.go", "Line 1 content\nold line\nLine 3 content\nLine 4 content\nLine 5 content")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(diff, "@@"), "temp file header is dropped")
+	assert.Contains(t, diff, "-old line")
+	assert.Contains(t, diff, "+Line 2 content")
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/repo"
+)
+
+// ConflictPreview describes what ResolveConflicts would do for a PR,
+// determined by a trial rebase in a throwaway worktree.
+type ConflictPreview struct {
+	Target     string   // target branch, without refs/heads/
+	Commits    []string // branch commits to replay, "hash subject", oldest last
+	DiffStat   string   // what the branch changes relative to the target
+	Clean      bool     // the rebase applies without conflicts
+	StoppedAt  string   // "hash subject" of the first commit that conflicts
+	Conflicted []string // files conflicting at StoppedAt
+}
+
+// PreviewConflicts rebases pr's branch onto its target in a clean detached
+// worktree, records where it conflicts, and throws the result away. Nothing
+// is pushed and the user's checkouts are not touched.
+func PreviewConflicts(ctx context.Context, pr *PRDocument, cfg *config.Config) (*ConflictPreview, error) {
+	if pr.Target == "" {
+		return nil, fmt.Errorf("PR #%s has no target branch recorded", pr.ID)
+	}
+	target := strings.TrimPrefix(pr.Target, "refs/heads/")
+	preview := &ConflictPreview{Target: target}
+
+	workDir, _, cleanup, err := repo.MapPRToCleanWorkDir(cfg, pr.URL, pr.Branch)
+	if err != nil {
+		return nil, fmt.Errorf("mapping PR to clean workdir: %w", err)
+	}
+	defer cleanup()
+
+	git := func(args ...string) (string, error) {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = workDir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("git %s: %s: %w", args[0], strings.TrimSpace(string(out)), err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", target, target)
+	if _, err := git("fetch", "origin", refspec); err != nil {
+		return nil, err
+	}
+	commits, err := git("log", "--format=%h %s", "origin/"+target+"..HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if commits != "" {
+		preview.Commits = strings.Split(commits, "\n")
+	}
+	if preview.DiffStat, err = git("diff", "--stat", "origin/"+target+"...HEAD"); err != nil {
+		return nil, err
+	}
+
+	rebaseArgs := append(gitIdentityArgs(cfg), "rebase", "origin/"+target)
+	if _, rebaseErr := git(rebaseArgs...); rebaseErr == nil {
+		preview.Clean = true
+		return preview, nil
+	}
+	defer func() { _, _ = git("rebase", "--abort") }()
+
+	preview.StoppedAt, _ = git("log", "-1", "--format=%h %s", "REBASE_HEAD")
+	files, err := git("diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+	if files == "" {
+		return nil, fmt.Errorf("rebase onto origin/%s failed without conflicted files", target)
+	}
+	preview.Conflicted = strings.Split(files, "\n")
+	return preview, nil
+}
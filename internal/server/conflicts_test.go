@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewConflicts(t *testing.T) {
+	repoDir := newRepoWithRemote(t)
+	origin := filepath.Join(filepath.Dir(repoDir), "origin.git")
+	mainBranch := strings.TrimSpace(gitT(t, repoDir, "rev-parse", "--abbrev-ref", "HEAD"))
+
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0644))
+		gitT(t, repoDir, "add", "-A")
+	}
+	write("app.go", "package app\n\nconst Version = 1\n")
+	gitT(t, repoDir, "commit", "-q", "-m", "add app")
+	gitT(t, repoDir, "push", "-q", "origin", "HEAD")
+
+	gitT(t, repoDir, "checkout", "-q", "-b", "feature")
+	write("app.go", "package app\n\nconst Version = 2\n")
+	gitT(t, repoDir, "commit", "-q", "-m", "bump version")
+	write("notes.txt", "notes\n")
+	gitT(t, repoDir, "commit", "-q", "-m", "add notes")
+	gitT(t, repoDir, "push", "-q", "origin", "feature")
+
+	gitT(t, repoDir, "checkout", "-q", mainBranch)
+	write("app.go", "package app\n\nconst Version = 3\n")
+	gitT(t, repoDir, "commit", "-q", "-m", "release 3")
+	gitT(t, repoDir, "push", "-q", "origin", "HEAD")
+
+	cfg := &config.Config{
+		Repos: []config.RepoConfig{{Name: "app", PrimaryDir: repoDir, GitStrategy: config.GitStrategyBranch}},
+		Git:   config.GitConfig{AuthorName: "otto", AuthorEmail: "otto@example.com"},
+	}
+	pr := &PRDocument{ID: "5", URL: origin, Branch: "refs/heads/feature", Target: "refs/heads/" + mainBranch}
+
+	preview, err := PreviewConflicts(context.Background(), pr, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, mainBranch, preview.Target)
+	require.Len(t, preview.Commits, 2)
+	assert.Contains(t, preview.Commits[0], "add notes")
+	assert.False(t, preview.Clean)
+	assert.Contains(t, preview.StoppedAt, "bump version")
+	assert.Equal(t, []string{"app.go"}, preview.Conflicted)
+
+	// The preview ran in a throwaway worktree.
+	assert.Equal(t, mainBranch, strings.TrimSpace(gitT(t, repoDir, "rev-parse", "--abbrev-ref", "HEAD")))
+	assert.Contains(t, gitT(t, repoDir, "ls-remote", "origin", "feature"), strings.TrimSpace(gitT(t, repoDir, "rev-parse", "feature")))
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/alanmeadows/otto/internal/codesearch"
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/contextpack"
+	"github.com/alanmeadows/otto/internal/repo"
+)
+
+// maxRelatedSnippets is the number of search hits put in a review prompt.
+const maxRelatedSnippets = 6
+
+// repoContext returns a context pack for req from workDir, a checkout of
+// the PR at prURL, as markdown for a prompt's repo_context — or "" when
+// packs are disabled or fail to build. Repos with a search index get a
+// pack even with packs disabled, for the index's snippets.
+func repoContext(ctx context.Context, cfg *config.Config, prURL, workDir string, req contextpack.Request) string {
+	if cfg == nil || workDir == "" {
+		return ""
+	}
+	ix := searchIndex(cfg, prURL)
+	if !cfg.PR.ContextPack.Enabled && ix == nil {
+		return ""
+	}
+	b := contextpack.New(cfg.PR.ContextPack, cfg.Models)
+	b.Index = ix
+	req.MaxBytes = cfg.PR.ContextPack.MaxBytes()
+	pack, err := b.Build(ctx, workDir, req)
+	if err != nil {
+		slog.Warn("failed to build context pack", "workDir", workDir, "error", err)
+		return ""
+	}
+	return pack.Markdown()
+}
+
+// relatedCode searches the index of the PR at prURL's repo for the
+// snippets closest to query, as markdown for a prompt. It returns "" for
+// repos without an index.
+func relatedCode(ctx context.Context, cfg *config.Config, prURL, workDir, query string) string {
+	ix := searchIndex(cfg, prURL)
+	if ix == nil || workDir == "" {
+		return ""
+	}
+	hits, err := ix.Search(ctx, workDir, query, maxRelatedSnippets)
+	if err != nil {
+		slog.Warn("code search failed", "workDir", workDir, "error", err)
+		return ""
+	}
+	return codesearch.Markdown(hits)
+}
+
+// searchIndex returns the search index of the repo the PR at prURL
+// belongs to, or nil if it has none. Prompts search the index as it
+// stands, without waiting on an update: updateSearchIndexes keeps it
+// current in the background, and while it runs the index is left to it.
+func searchIndex(cfg *config.Config, prURL string) *codesearch.Index {
+	if indexing.Load() {
+		return nil
+	}
+	rc, err := repo.NewManager("").FindByRemoteURL(cfg, prURL)
+	if err != nil || !rc.SearchIndex {
+		return nil
+	}
+	ix, err := codesearch.Open(cfg, *rc)
+	if err != nil {
+		slog.Warn("search index unavailable", "repo", rc.Name, "error", err)
+		return nil
+	}
+	return ix
+}
+
+// indexing is set while updateSearchIndexes runs.
+var indexing atomic.Bool
+
+// updateSearchIndexes brings the search indexes of the repos that have one
+// up to date with their primary checkouts, in the background. A first
+// index of a large repo takes a while; polls carry on meanwhile, and an
+// update still running when the next poll starts is left to finish.
+func updateSearchIndexes(ctx context.Context, cfg *config.Config) {
+	var repos []config.RepoConfig
+	for _, rc := range cfg.Repos {
+		if rc.SearchIndex && rc.PrimaryDir != "" {
+			repos = append(repos, rc)
+		}
+	}
+	if len(repos) == 0 || !indexing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer indexing.Store(false)
+		for _, rc := range repos {
+			ix, err := codesearch.Open(cfg, rc)
+			if err != nil {
+				slog.Warn("search index unavailable", "repo", rc.Name, "error", err)
+				continue
+			}
+			stats, err := ix.Update(ctx, rc.PrimaryDir)
+			if err != nil {
+				slog.Warn("failed to update search index", "repo", rc.Name, "error", err)
+				continue
+			}
+			if stats.Embedded > 0 {
+				slog.Info("updated search index", "repo", rc.Name, "files", stats.Files, "embeddedChunks", stats.Embedded)
+			}
+		}
+	}()
+}
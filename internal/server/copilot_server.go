@@ -111,3 +111,26 @@ func isPortOpen(port int) bool {
 	conn.Close()
 	return true
 }
+
+// CopilotServerAddr returns the Copilot server address otto uses: the
+// user-managed override if set, otherwise the otto-managed default.
+func CopilotServerAddr(override string) string {
+	if override != "" {
+		return override
+	}
+	return fmt.Sprintf("localhost:%d", copilotDefaultPort)
+}
+
+// CopilotServerReachable reports whether a Copilot server accepts
+// connections at addr (host:port, optionally with a scheme).
+func CopilotServerReachable(addr string) bool {
+	if _, rest, ok := strings.Cut(addr, "://"); ok {
+		addr = rest
+	}
+	conn, err := net.DialTimeout("tcp", strings.TrimSuffix(addr, "/"), 2*time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -18,34 +18,25 @@ import (
 
 	"github.com/alanmeadows/otto/internal/config"
 	"github.com/alanmeadows/otto/internal/store"
-	"github.com/alanmeadows/otto/internal/tunnel"
 )
 
 // PIDFilePath returns the path to the daemon PID file.
 func PIDFilePath() string {
-	dataDir := os.Getenv("XDG_DATA_HOME")
-	if dataDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil || home == "" {
-			slog.Error("cannot determine home directory; set $HOME or $XDG_DATA_HOME", "error", err)
-			os.Exit(1)
-		}
-		dataDir = filepath.Join(home, ".local", "share")
+	dataDir, err := config.DataDir()
+	if err != nil {
+		slog.Error("cannot determine the otto data directory", "error", err)
+		os.Exit(1)
 	}
-	return filepath.Join(dataDir, "otto", "ottod.pid")
+	return filepath.Join(dataDir, "ottod.pid")
 }
 
 // LogFilePath returns the path to the daemon log file.
 func LogFilePath() string {
-	dataDir := os.Getenv("XDG_DATA_HOME")
-	if dataDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil || home == "" {
-			return ""
-		}
-		dataDir = filepath.Join(home, ".local", "share")
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return ""
 	}
-	return filepath.Join(dataDir, "otto", "logs", "ottod.log")
+	return filepath.Join(dataDir, "logs", "ottod.log")
 }
 
 // StartDaemon forks the current process as a daemon.
@@ -105,12 +96,7 @@ func forkDaemon(port int, logDir string) (*forkResult, error) {
 
 	// Create log directory.
 	if logDir == "" {
-		dataDir := os.Getenv("XDG_DATA_HOME")
-		if dataDir == "" {
-			home, _ := os.UserHomeDir()
-			dataDir = filepath.Join(home, ".local", "share")
-		}
-		logDir = filepath.Join(dataDir, "otto", "logs")
+		logDir = filepath.Dir(LogFilePath())
 	}
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return nil, fmt.Errorf("creating log directory: %w", err)
@@ -184,12 +170,10 @@ func (r *forkResult) printStatus() {
 
 	// If tunnel is enabled, poll the dashboard API for the tunnel URL.
 	if r.dashboardEnabled && r.tunnelEnabled {
-		if !tunnel.IsBgtaskInstalled() {
-			fmt.Printf("tunnel: skipped (bgtask not installed — go install github.com/philsphicas/bgtask/cmd/bgtask@latest)\n")
-		} else if !(&tunnel.Manager{}).IsInstalled() {
-			fmt.Printf("tunnel: skipped (devtunnel not installed. Install: curl -sL https://aka.ms/DevTunnelCliInstall | bash)\n")
-		} else if tunnelURL := pollTunnelURL(r.dashPort); tunnelURL != "" {
+		if tunnelURL, hint := pollTunnelURL(r.dashPort); tunnelURL != "" {
 			fmt.Printf("tunnel: %s\n", tunnelURL)
+		} else if hint != "" {
+			fmt.Printf("tunnel: skipped (%s)\n", hint)
 		} else {
 			fmt.Printf("tunnel: starting (check 'otto server logs' if it doesn't come up)\n")
 		}
@@ -237,15 +221,16 @@ func PollTunnelURLBrief(dashPort int) string {
 }
 
 // pollTunnelURL polls the dashboard's tunnel status API until the tunnel URL
-// is available or the timeout expires. Localhost requests bypass dashboard auth.
-func pollTunnelURL(dashPort int) string {
+// is available, the tunnel reports why it can't start, or the timeout
+// expires. Localhost requests bypass dashboard auth.
+func pollTunnelURL(dashPort int) (url, hint string) {
 	client := &http.Client{Timeout: 2 * time.Second}
-	url := fmt.Sprintf("http://localhost:%d/api/tunnel/status", dashPort)
+	statusURL := fmt.Sprintf("http://localhost:%d/api/tunnel/status", dashPort)
 
 	// Give the forked server time to start listening.
 	time.Sleep(1 * time.Second)
 	for i := 0; i < 28; i++ { // 1s initial + 28 × 500ms = 15s max
-		resp, err := client.Get(url)
+		resp, err := client.Get(statusURL)
 		if err != nil {
 			time.Sleep(500 * time.Millisecond)
 			continue
@@ -254,6 +239,7 @@ func pollTunnelURL(dashPort int) string {
 			Running  bool   `json:"running"`
 			URL      string `json:"url"`
 			KeyedURL string `json:"keyed_url"`
+			Hint     string `json:"hint"`
 		}
 		if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
 			resp.Body.Close()
@@ -262,23 +248,37 @@ func pollTunnelURL(dashPort int) string {
 		}
 		resp.Body.Close()
 		if status.KeyedURL != "" {
-			return status.KeyedURL
+			return status.KeyedURL, ""
 		}
 		if status.URL != "" {
-			return status.URL
+			return status.URL, ""
+		}
+		hint = status.Hint
+		if hint != "" && !status.Running {
+			return "", hint
 		}
 		time.Sleep(500 * time.Millisecond)
 	}
-	return ""
+	return "", hint
 }
 
 func runForeground(port int, logDir string) error {
-	// Load config.
+	// Load config. An invalid config fails startup rather than running
+	// with settings the user did not ask for.
+	for _, path := range config.Paths() {
+		issues, err := config.CheckFile(path)
+		if err != nil {
+			continue
+		}
+		for _, is := range issues {
+			if is.Warning {
+				slog.Warn("config: " + is.String())
+			}
+		}
+	}
 	cfg, err := config.Load()
 	if err != nil {
-		slog.Warn("failed to load config, using defaults", "error", err)
-		defaultCfg := config.DefaultConfig()
-		cfg = &defaultCfg
+		return fmt.Errorf("invalid config (check it with 'otto config validate'): %w", err)
 	}
 
 	// Apply runtime flags from environment (set by CLI before fork).
@@ -325,7 +325,8 @@ func runForeground(port int, logDir string) error {
 	return RunServer(ctx, port, cfg)
 }
 
-// StopDaemon sends SIGTERM to the running daemon and waits for exit.
+// StopDaemon asks the running daemon to shut down (SIGTERM; on Windows it
+// is terminated) and waits for it to exit.
 func StopDaemon() error {
 	running, pid, _, err := DaemonStatus()
 	if err != nil {
@@ -335,19 +336,19 @@ func StopDaemon() error {
 		return fmt.Errorf("daemon is not running")
 	}
 
-	// Send SIGTERM.
+	// Ask it to shut down.
 	proc, err := os.FindProcess(pid)
 	if err != nil {
 		return fmt.Errorf("finding process: %w", err)
 	}
 
-	if err := proc.Signal(syscall.SIGTERM); err != nil {
+	if err := terminateProcess(proc); err != nil {
 		// Check if process is already gone.
 		if errors.Is(err, syscall.ESRCH) || errors.Is(err, os.ErrProcessDone) {
 			removePIDFile()
 			return nil
 		}
-		return fmt.Errorf("sending SIGTERM: %w", err)
+		return fmt.Errorf("stopping daemon: %w", err)
 	}
 
 	// Wait for exit with timeout.
@@ -359,11 +360,11 @@ func StopDaemon() error {
 		select {
 		case <-deadline:
 			// Force kill.
-			_ = proc.Signal(syscall.SIGKILL)
+			_ = proc.Kill()
 			removePIDFile()
 			return fmt.Errorf("daemon did not stop gracefully, sent SIGKILL")
 		case <-ticker.C:
-			if err := proc.Signal(syscall.Signal(0)); err != nil {
+			if !store.ProcessAlive(pid) {
 				// Process is gone.
 				removePIDFile()
 				return nil
@@ -372,6 +373,22 @@ func StopDaemon() error {
 	}
 }
 
+// ReloadDaemon signals the running daemon to reload its config.
+func ReloadDaemon() error {
+	running, pid, _, err := DaemonStatus()
+	if err != nil {
+		return err
+	}
+	if !running {
+		return fmt.Errorf("daemon is not running")
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("finding process: %w", err)
+	}
+	return signalReload(proc)
+}
+
 // DaemonStatus checks whether the daemon is running.
 // Returns: running bool, pid int, uptime duration, error.
 func DaemonStatus() (bool, int, time.Duration, error) {
@@ -390,13 +407,7 @@ func DaemonStatus() (bool, int, time.Duration, error) {
 	}
 
 	// Check if process is alive.
-	proc, err := os.FindProcess(pid)
-	if err != nil {
-		removePIDFile()
-		return false, 0, 0, nil
-	}
-
-	if err := proc.Signal(syscall.Signal(0)); err != nil {
+	if !store.ProcessAlive(pid) {
 		// Process is not running — stale PID file.
 		removePIDFile()
 		return false, 0, 0, nil
@@ -431,61 +442,6 @@ func removePIDFile() {
 	_ = os.Remove(PIDFilePath())
 }
 
-// InstallSystemdService writes a systemd user unit file and enables the service.
-func InstallSystemdService() error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("getting home dir: %w", err)
-	}
-
-	unitDir := filepath.Join(home, ".config", "systemd", "user")
-	if err := os.MkdirAll(unitDir, 0755); err != nil {
-		return fmt.Errorf("creating systemd directory: %w", err)
-	}
-
-	// Find the otto binary path.
-	execPath, err := os.Executable()
-	if err != nil {
-		return fmt.Errorf("finding executable path: %w", err)
-	}
-
-	unit := fmt.Sprintf(`[Unit]
-Description=Otto Daemon
-After=network.target
-
-[Service]
-Type=simple
-ExecStart=%s server start --foreground
-Restart=on-failure
-RestartSec=5s
-TimeoutStopSec=30
-Environment=HOME=%s
-
-[Install]
-WantedBy=default.target
-`, execPath, home)
-
-	unitPath := filepath.Join(unitDir, "otto.service")
-	if err := os.WriteFile(unitPath, []byte(unit), 0644); err != nil {
-		return fmt.Errorf("writing unit file: %w", err)
-	}
-
-	// Reload systemd and enable.
-	reloadCmd := exec.Command("systemctl", "--user", "daemon-reload")
-	if out, err := reloadCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("daemon-reload: %s: %w", string(out), err)
-	}
-
-	enableCmd := exec.Command("systemctl", "--user", "enable", "otto")
-	if out, err := enableCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("enabling service: %s: %w", string(out), err)
-	}
-
-	fmt.Printf("installed otto.service at %s\n", unitPath)
-	fmt.Println("service enabled. Start with: systemctl --user start otto")
-	return nil
-}
-
 // RestartDaemon stops the running daemon and starts it again via a bgtask
 // so the restart survives the current process exiting.
 func RestartDaemon() error {
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/eval"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/prompts"
+)
+
+// EvalCase runs an eval case through the prompt and parsing the daemon
+// uses for the same input and returns what otto concluded: the failure
+// category for a failure case, the decision for a comment case. Comment
+// cases may edit files in workDir, so it should be a scratch directory.
+func EvalCase(ctx context.Context, client llm.Client, c eval.Case, workDir string) (string, error) {
+	var (
+		prompt string
+		err    error
+	)
+	switch c.Kind {
+	case eval.KindFailure:
+		prompt, err = prompts.Execute("pr-fix-analysis.md", map[string]string{
+			"pr_id":      "eval",
+			"pr_title":   c.Title,
+			"build_logs": c.Logs,
+		})
+	case eval.KindComment:
+		prompt, err = prompts.Execute("pr-comment-respond.md", map[string]string{
+			"pr_title":       c.Title,
+			"pr_description": c.Description,
+			"comment_author": c.Comment.Author,
+			"comment_file":   c.Comment.File,
+			"comment_line":   fmt.Sprintf("%d", c.Comment.Line),
+			"comment_body":   c.Comment.Body,
+			"comment_thread": c.Comment.Thread,
+			"code_context":   c.Comment.CodeContext,
+		})
+	default:
+		return "", fmt.Errorf("unknown eval case kind %q", c.Kind)
+	}
+	if err != nil {
+		return "", fmt.Errorf("building prompt: %w", err)
+	}
+
+	session, err := client.CreateSession(ctx, "Eval "+c.Name, workDir)
+	if err != nil {
+		return "", fmt.Errorf("creating session: %w", err)
+	}
+	defer client.DeleteSession(ctx, session.ID)
+
+	resp, err := client.SendPrompt(ctx, session.ID, prompt)
+	if err != nil {
+		return "", fmt.Errorf("sending prompt: %w", err)
+	}
+	if c.Kind == eval.KindFailure {
+		return string(classifyFailure(resp.Content, c.Logs)), nil
+	}
+	commentResp, err := llm.ParseJSONResponse[CommentResponse](ctx, client, session.ID, resp.Content)
+	if err != nil {
+		return "", fmt.Errorf("parsing response: %w", err)
+	}
+	return strings.ToUpper(commentResp.Decision), nil
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/eval"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvalCase(t *testing.T) {
+	ctx := context.Background()
+	failure := eval.Case{Name: "f", Kind: eval.KindFailure, Title: "Rename Put", Logs: "sync.go:42:9: u.Put undefined", Expected: "compile"}
+	comment := eval.Case{Name: "c", Kind: eval.KindComment, Comment: &eval.Comment{Author: "r", Body: "Use a mutex here."}, Expected: "BY_DESIGN"}
+
+	client := llm.NewMockClient()
+	client.DefaultResult = "CLASSIFICATION: COMPILE\n\nu.Put was renamed."
+	got, err := EvalCase(ctx, client, failure, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, "compile", got)
+	assert.Contains(t, client.PromptHistory[0].Prompt, "u.Put undefined")
+
+	client.DefaultResult = `{"decision": "by_design", "reply": "Only written during init."}`
+	got, err = EvalCase(ctx, client, comment, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, "BY_DESIGN", got)
+	assert.Contains(t, client.PromptHistory[1].Prompt, "Use a mutex here.")
+	assert.Empty(t, client.Sessions, "sessions are deleted")
+}
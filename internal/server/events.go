@@ -0,0 +1,249 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventStreamVersion is the version of the event schema served at
+// /v1/events. Fields may be added within a version; renaming or removing
+// one, or changing its meaning, needs a new version.
+const EventStreamVersion = 1
+
+// Event types published on the stream.
+const (
+	StreamPRAdded         = "pr.added"
+	StreamPRRemoved       = "pr.removed"
+	StreamPRStatusChanged = "pr.status_changed"
+	StreamPRFixStarted    = "pr.fix_started"
+	StreamPRFixFinished   = "pr.fix_finished"
+)
+
+const (
+	// eventBacklog is how many recent events are kept for clients that
+	// reconnect with Last-Event-ID.
+	eventBacklog = 256
+	// eventSubscriberBuffer is how far a client may fall behind before it
+	// is disconnected; it can reconnect and catch up from the backlog.
+	eventSubscriberBuffer = 64
+	// eventHeartbeat is how often an idle stream gets a comment line, so
+	// proxies and clients can tell it is alive.
+	eventHeartbeat = 15 * time.Second
+)
+
+// StreamEvent is one event on the daemon's event stream.
+type StreamEvent struct {
+	Version int            `json:"v"`
+	ID      uint64         `json:"id"`
+	Type    string         `json:"type"`
+	Time    time.Time      `json:"time"`
+	PR      *EventPR       `json:"pr,omitempty"`
+	Data    map[string]any `json:"data,omitempty"`
+}
+
+// EventPR identifies the PR an event is about.
+type EventPR struct {
+	ID       string `json:"id"`
+	Provider string `json:"provider"`
+	Title    string `json:"title,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Repo     string `json:"repo,omitempty"`
+}
+
+// eventBus fans events out to stream subscribers and keeps a backlog for
+// reconnects.
+type eventBus struct {
+	mu      sync.Mutex
+	nextID  uint64
+	backlog []StreamEvent
+	subs    map[chan StreamEvent]struct{}
+}
+
+// eventStream is the daemon's event bus. IDs start at the boot time in
+// microseconds, so they keep increasing across restarts.
+var eventStream = newEventBus()
+
+func newEventBus() *eventBus {
+	return &eventBus{
+		nextID: uint64(time.Now().UnixMicro()),
+		subs:   make(map[chan StreamEvent]struct{}),
+	}
+}
+
+// publishEvent sends an event about pr (which may be nil) to every stream
+// subscriber.
+func publishEvent(typ string, pr *PRDocument, data map[string]any) {
+	eventStream.publish(typ, pr, data)
+}
+
+func (b *eventBus) publish(typ string, pr *PRDocument, data map[string]any) {
+	e := StreamEvent{Version: EventStreamVersion, Type: typ, Time: time.Now().UTC(), Data: data}
+	if pr != nil {
+		e.PR = &EventPR{ID: pr.ID, Provider: pr.Provider, Title: pr.Title, URL: pr.URL, Repo: pr.Repo}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	e.ID = b.nextID
+	b.backlog = append(b.backlog, e)
+	if len(b.backlog) > eventBacklog {
+		b.backlog = b.backlog[len(b.backlog)-eventBacklog:]
+	}
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			// Too far behind: drop it so it reconnects and replays.
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe returns the backlog events after since and a channel of new
+// events. The channel is closed if the subscriber falls behind. cancel
+// unsubscribes.
+func (b *eventBus) subscribe(since uint64) (replay []StreamEvent, ch chan StreamEvent, cancel func()) {
+	ch = make(chan StreamEvent, eventSubscriberBuffer)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, e := range b.backlog {
+		if e.ID > since {
+			replay = append(replay, e)
+		}
+	}
+	b.subs[ch] = struct{}{}
+	return replay, ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// eventFilter selects the events a subscriber wants.
+type eventFilter struct {
+	types []string        // exact types or "prefix.*"; empty = all
+	prs   map[string]bool // PR IDs; empty = all
+}
+
+// parseEventFilter reads the types and pr query parameters, each a
+// comma-separated list.
+func parseEventFilter(r *http.Request) eventFilter {
+	var f eventFilter
+	q := r.URL.Query()
+	f.types = splitList(q.Get("types"))
+	for _, id := range splitList(q.Get("pr")) {
+		if f.prs == nil {
+			f.prs = make(map[string]bool)
+		}
+		f.prs[id] = true
+	}
+	return f
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (f eventFilter) match(e StreamEvent) bool {
+	if len(f.prs) > 0 && (e.PR == nil || !f.prs[e.PR.ID]) {
+		return false
+	}
+	if len(f.types) == 0 {
+		return true
+	}
+	for _, t := range f.types {
+		if t == e.Type || t == "*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(t, "*"); ok && strings.HasPrefix(e.Type, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleEvents serves the event stream as Server-Sent Events. Each event's
+// SSE id is its ID, so a client that reconnects with Last-Event-ID (or
+// ?since=) gets the events it missed from the backlog.
+func handleEvents(w http.ResponseWriter, r *http.Request) {
+	filter := parseEventFilter(r)
+	since, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+	if v := r.URL.Query().Get("since"); v != "" {
+		since, _ = strconv.ParseUint(v, 10, 64)
+	}
+
+	rc := http.NewResponseController(w)
+	// The API server's write timeout would cut the stream off.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		slog.Debug("event stream: cannot clear write deadline", "error", err)
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Otto-Event-Version", strconv.Itoa(EventStreamVersion))
+	w.WriteHeader(http.StatusOK)
+
+	replay, ch, cancel := eventStream.subscribe(since)
+	defer cancel()
+
+	write := func(e StreamEvent) error {
+		if !filter.match(e) {
+			return nil
+		}
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+		return err
+	}
+
+	fmt.Fprint(w, "retry: 3000\n\n")
+	for _, e := range replay {
+		if err := write(e); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := write(e); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventFilter(t *testing.T) {
+	pr := &EventPR{ID: "7", Provider: "github"}
+	status := StreamEvent{Type: StreamPRStatusChanged, PR: pr}
+	fix := StreamEvent{Type: StreamPRFixStarted, PR: pr}
+
+	filter := func(query string) eventFilter {
+		return parseEventFilter(httptest.NewRequest("GET", "/v1/events?"+query, nil))
+	}
+	assert.True(t, filter("").match(status))
+	assert.True(t, filter("types=pr.status_changed").match(status))
+	assert.False(t, filter("types=pr.status_changed").match(fix))
+	assert.True(t, filter("types=pr.fix_*").match(fix))
+	assert.True(t, filter("types=pr.*").match(status))
+	assert.True(t, filter("pr=3,7").match(status))
+	assert.False(t, filter("pr=3").match(status))
+	assert.False(t, filter("pr=7").match(StreamEvent{Type: "daemon.ready"}))
+}
+
+func TestEventBusBacklogAndSlowSubscriber(t *testing.T) {
+	bus := newEventBus()
+	bus.publish(StreamPRAdded, &PRDocument{ID: "1"}, nil)
+	bus.publish(StreamPRAdded, &PRDocument{ID: "2"}, nil)
+
+	replay, _, cancel := bus.subscribe(0)
+	cancel()
+	require.Len(t, replay, 2)
+	assert.Greater(t, replay[1].ID, replay[0].ID)
+	assert.Equal(t, EventStreamVersion, replay[0].Version)
+
+	replay, _, cancel = bus.subscribe(replay[0].ID)
+	cancel()
+	require.Len(t, replay, 1)
+	assert.Equal(t, "2", replay[0].PR.ID)
+
+	// A subscriber that stops reading is dropped rather than blocking.
+	_, ch, cancel := bus.subscribe(^uint64(0))
+	defer cancel()
+	for i := 0; i <= eventSubscriberBuffer; i++ {
+		bus.publish(StreamPRStatusChanged, nil, nil)
+	}
+	n := 0
+	for range ch {
+		n++
+	}
+	assert.Equal(t, eventSubscriberBuffer, n)
+}
+
+func TestHandleEvents(t *testing.T) {
+	setupAPITest(t)
+	mux := http.NewServeMux()
+	registerRoutes(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// Skip events other tests left in the backlog.
+	eventStream.mu.Lock()
+	since := eventStream.nextID
+	eventStream.mu.Unlock()
+	url := fmt.Sprintf("%s/v1/events?types=pr.status_changed&since=%d", srv.URL, since)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// Saving a new PR publishes pr.added, filtered out here; changing its
+	// status publishes pr.status_changed.
+	pr := &PRDocument{ID: "42", Provider: "github", Title: "Add widgets", Status: "watching"}
+	require.NoError(t, SavePR(pr))
+	pr.Status = "green"
+	require.NoError(t, SavePR(pr))
+
+	sc := bufio.NewScanner(resp.Body)
+	var eventType string
+	for sc.Scan() {
+		line := sc.Text()
+		if v, ok := strings.CutPrefix(line, "event: "); ok {
+			eventType = v
+		}
+		if v, ok := strings.CutPrefix(line, "data: "); ok {
+			var e StreamEvent
+			require.NoError(t, json.Unmarshal([]byte(v), &e))
+			assert.Equal(t, StreamPRStatusChanged, eventType)
+			assert.Equal(t, "42", e.PR.ID)
+			assert.Equal(t, "watching", e.Data["from"])
+			assert.Equal(t, "green", e.Data["to"])
+			return
+		}
+	}
+	t.Fatal("stream ended without an event")
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+)
+
+// FailureCategory classifies a CI failure for fix budgeting.
+type FailureCategory string
+
+const (
+	CategoryInfra   FailureCategory = "infra"   // not caused by the PR; retried, never fixed
+	CategoryCompile FailureCategory = "compile" // build/compile/type errors
+	CategoryTest    FailureCategory = "test"    // failing tests
+	CategoryLint    FailureCategory = "lint"    // lint/format/static analysis violations
+	CategoryCode    FailureCategory = "code"    // code failure that fits no narrower category
+)
+
+// Infra retries back off exponentially from infraRetryBaseBackoff, capped
+// at infraRetryMaxBackoff, so a broken agent pool isn't hammered every poll.
+const (
+	infraRetryBaseBackoff = 2 * time.Minute
+	infraRetryMaxBackoff  = time.Hour
+)
+
+// classifyFailure combines the Phase 1 CLASSIFICATION line with structured
+// parsing of the raw build logs. An explicit LLM category wins; a generic
+// CODE classification (or none) is refined from the logs.
+func classifyFailure(diagnosis, logs string) FailureCategory {
+	if isInfraFailure(diagnosis) {
+		return CategoryInfra
+	}
+	if cat := classificationMarker(diagnosis); cat != "" && cat != CategoryCode {
+		return cat
+	}
+	return categorizeLogs(logs)
+}
+
+// classificationMarker returns the category named on the diagnosis's
+// CLASSIFICATION: line, or "" if there is none.
+func classificationMarker(diagnosis string) FailureCategory {
+	for _, line := range strings.Split(diagnosis, "\n") {
+		line = strings.NewReplacer("*", "", "`", "", "#", "").Replace(strings.TrimSpace(line))
+		upper := strings.ToUpper(strings.TrimSpace(line))
+		if !strings.HasPrefix(upper, "CLASSIFICATION:") {
+			continue
+		}
+		value := upper[len("CLASSIFICATION:"):]
+		switch {
+		case strings.Contains(value, "INFRASTRUCTURE"):
+			return CategoryInfra
+		case strings.Contains(value, "COMPILE"), strings.Contains(value, "BUILD"):
+			return CategoryCompile
+		case strings.Contains(value, "TEST"):
+			return CategoryTest
+		case strings.Contains(value, "LINT"), strings.Contains(value, "FORMAT"):
+			return CategoryLint
+		default:
+			return CategoryCode
+		}
+	}
+	return ""
+}
+
+// Log signatures per category, checked in priority order: compile errors
+// usually cascade into test failures, so they win when both appear.
+var logSignatures = []struct {
+	category FailureCategory
+	patterns []*regexp.Regexp
+}{
+	{CategoryCompile, []*regexp.Regexp{
+		regexp.MustCompile(`\berror (CS|TS|BC)\d{4}\b`), // C#, TypeScript, VB
+		regexp.MustCompile(`\berror\[E\d{4}\]`),         // Rust
+		regexp.MustCompile(`\.go:\d+:\d+: (undefined|cannot use|syntax error|missing return|too many|not enough|declared and not used)`),
+		regexp.MustCompile(`(?i)\bcannot find symbol\b`),                  // Java
+		regexp.MustCompile(`\.(c|cc|cpp|h|hpp):\d+:\d+: (fatal )?error:`), // gcc/clang
+		regexp.MustCompile(`(?i)\bcompilation (failed|terminated)\b`),
+	}},
+	{CategoryTest, []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^\s*--- FAIL: `),              // go test
+		regexp.MustCompile(`(?m)^FAIL\s+\S+\s+[\d.]+s`),       // go test package summary
+		regexp.MustCompile(`(?m)^FAILED \S+::`),               // pytest
+		regexp.MustCompile(`\bFailed!\s+-\s+Failed:\s+[1-9]`), // dotnet test
+		regexp.MustCompile(`(?i)\bTests?:\s+\d+ failed\b`),    // jest
+		regexp.MustCompile(`(?i)\b[1-9]\d* (tests? )?failed\b`),
+	}},
+	// Lint tools are matched by the findings they print, not by name:
+	// install and setup steps mention them in passing. Build logs prefix
+	// lines with timestamps, so patterns don't anchor at line start.
+	{CategoryLint, []*regexp.Regexp{
+		regexp.MustCompile(`(?m)\.go:\d+:\d+: .+ \([a-z][a-z0-9-]*\)\r?$`), // golangci-lint
+		regexp.MustCompile(`(?m)\s\d+:\d+\s+error {2}.+ {2}[@\w/-]+\r?$`),  // eslint
+		regexp.MustCompile(`\x{2716} \d+ problems? \(\d+ errors?`),         // eslint summary
+		regexp.MustCompile(`\.pyi?:\d+:\d+: [A-Z]{1,3}\d{3,4}\b`),          // flake8, ruff, pylint
+		regexp.MustCompile(`\.rb:\d+:\d+: [CWEF]: `),                       // rubocop
+		regexp.MustCompile(`\b(error|warning) (SA|IDE)\d{4}\b`),            // StyleCop, .NET analyzers
+		regexp.MustCompile(`\bSC\d{4}\b`),                                  // shellcheck
+		regexp.MustCompile(`\.md:\d+(:\d+)? MD\d{3}\b`),                    // markdownlint
+		regexp.MustCompile(`\bERROR: \S+\.tsx?:\d+:\d+ - `),                // tslint
+		regexp.MustCompile(`\bwould reformat \S+\.pyi?\b`),                 // black
+		regexp.MustCompile(`\[-Wclang-format-violations\]`),                // clang-format
+		regexp.MustCompile(`\berror WHITESPACE: `),                         // dotnet format
+		regexp.MustCompile(`(?i)\bcode style (issues|violations)\b`),       // prettier
+	}},
+}
+
+// categorizeLogs picks a category from raw build log text, defaulting to
+// CategoryCode when no known signature matches.
+func categorizeLogs(logs string) FailureCategory {
+	for _, sig := range logSignatures {
+		for _, re := range sig.patterns {
+			if re.MatchString(logs) {
+				return sig.category
+			}
+		}
+	}
+	return CategoryCode
+}
+
+// categoryBudgetExhausted reports whether pr has used up the configured fix
+// budget for cat, returning the budget for messaging.
+func categoryBudgetExhausted(pr *PRDocument, cfg *config.Config, cat FailureCategory) (bool, int) {
+	budget, ok := cfg.PR.FixBudgets[string(cat)]
+	if !ok || budget < 0 {
+		return false, 0
+	}
+	return pr.FixAttemptsByCategory[string(cat)] >= budget, budget
+}
+
+// infraRetryBackoff returns the wait before the next infra retry after
+// the given number of prior retries.
+func infraRetryBackoff(retries int) time.Duration {
+	d := infraRetryBaseBackoff
+	for i := 0; i < retries && d < infraRetryMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > infraRetryMaxBackoff {
+		d = infraRetryMaxBackoff
+	}
+	return d
+}
+
+// infraRetryPending reports whether pr is still backing off from an infra retry.
+func infraRetryPending(pr *PRDocument, now time.Time) bool {
+	if pr.NextInfraRetry == "" {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339, pr.NextInfraRetry)
+	return err == nil && now.Before(t)
+}
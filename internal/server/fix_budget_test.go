@@ -0,0 +1,101 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name      string
+		diagnosis string
+		logs      string
+		want      FailureCategory
+	}{
+		{"infra marker", "CLASSIFICATION: INFRASTRUCTURE\nagent pool offline", "--- FAIL: TestX", CategoryInfra},
+		{"compile marker", "**CLASSIFICATION: COMPILE**\nundefined symbol", "", CategoryCompile},
+		{"test marker", "CLASSIFICATION: TEST", "", CategoryTest},
+		{"lint marker", "CLASSIFICATION: LINT", "", CategoryLint},
+		{"code marker refined from logs", "CLASSIFICATION: CODE", "main.go:12:3: undefined: foo", CategoryCompile},
+		{"no marker refined from logs", "the tests broke", "--- FAIL: TestRetry (0.00s)", CategoryTest},
+		{"code marker unknown logs", "CLASSIFICATION: CODE", "something went wrong", CategoryCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyFailure(tt.diagnosis, tt.logs))
+		})
+	}
+}
+
+func TestCategorizeLogs(t *testing.T) {
+	tests := []struct {
+		name string
+		logs string
+		want FailureCategory
+	}{
+		{"csharp compile", "Foo.cs(10,5): error CS0103: The name 'x' does not exist", CategoryCompile},
+		{"go compile wins over test", "pkg/a.go:3:2: undefined: Bar\nFAIL example.com/pkg 0.01s", CategoryCompile},
+		{"go test", "--- FAIL: TestParse (0.00s)\nFAIL\nFAIL example.com/pkg 0.012s", CategoryTest},
+		{"pytest", "FAILED tests/test_api.py::test_get - AssertionError", CategoryTest},
+		{"dotnet test", "Failed!  - Failed:     2, Passed:   40", CategoryTest},
+		{"golangci-lint", "cmd/main.go:12:2: Error return value of `f.Close` is not checked (errcheck)\n1 issues:", CategoryLint},
+		{"eslint", "/src/app.js\n  3:7  error  'x' is assigned a value but never used  no-unused-vars\n\n\u2716 1 problem (1 error, 0 warnings)", CategoryLint},
+		{"eslint on ADO", "2024-05-01T10:00:00.1Z   3:7  error  Unexpected console statement  no-console", CategoryLint},
+		{"black", "would reformat api/views.py\nOh no! 1 file would be reformatted.", CategoryLint},
+		{"flake8", "api/views.py:1:1: F401 'os' imported but unused", CategoryLint},
+		{"prettier", "[warn] Code style issues found in 2 files. Run Prettier to fix.", CategoryLint},
+		{"lint tool mentioned", "npm warn deprecated eslint@8.57.0: This version is no longer supported\n##[error]Bash exited with code '1'.", CategoryCode},
+		{"golangci-lint setup", "Installing golangci-lint v1.59.1\n##[error]The process failed with exit code 2", CategoryCode},
+		{"unknown", "##[error]Bash exited with code '1'.", CategoryCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, categorizeLogs(tt.logs))
+		})
+	}
+}
+
+func TestCategoryBudgetExhausted(t *testing.T) {
+	cfg := &config.Config{PR: config.PRConfig{FixBudgets: map[string]int{"compile": 3, "lint": 0, "test": -1}}}
+	pr := &PRDocument{FixAttemptsByCategory: map[string]int{"compile": 2, "test": 10}}
+
+	exhausted, budget := categoryBudgetExhausted(pr, cfg, CategoryCompile)
+	assert.False(t, exhausted)
+	assert.Equal(t, 3, budget)
+
+	pr.FixAttemptsByCategory["compile"] = 3
+	exhausted, _ = categoryBudgetExhausted(pr, cfg, CategoryCompile)
+	assert.True(t, exhausted)
+
+	exhausted, _ = categoryBudgetExhausted(pr, cfg, CategoryLint)
+	assert.True(t, exhausted, "a zero budget disables fixes for the category")
+
+	exhausted, _ = categoryBudgetExhausted(pr, cfg, CategoryTest)
+	assert.False(t, exhausted, "a negative budget means no per-category cap")
+
+	exhausted, _ = categoryBudgetExhausted(pr, cfg, CategoryCode)
+	assert.False(t, exhausted, "categories without a budget are only bounded by max_fix_attempts")
+}
+
+func TestInfraRetryBackoff(t *testing.T) {
+	assert.Equal(t, 2*time.Minute, infraRetryBackoff(0))
+	assert.Equal(t, 4*time.Minute, infraRetryBackoff(1))
+	assert.Equal(t, 32*time.Minute, infraRetryBackoff(4))
+	assert.Equal(t, time.Hour, infraRetryBackoff(5))
+	assert.Equal(t, time.Hour, infraRetryBackoff(100))
+}
+
+func TestInfraRetryPending(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	pr := &PRDocument{}
+	assert.False(t, infraRetryPending(pr, now))
+
+	pr.NextInfraRetry = now.Add(time.Minute).Format(time.RFC3339)
+	assert.True(t, infraRetryPending(pr, now))
+
+	pr.NextInfraRetry = now.Add(-time.Minute).Format(time.RFC3339)
+	assert.False(t, infraRetryPending(pr, now))
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// failedBuild is a failed build and its distilled logs.
+type failedBuild struct {
+	ID   string
+	Name string
+	Logs string
+}
+
+// fixEachBuild is FixPR's per-build mode (pr.fix_per_build): each failed
+// build gets an analysis and fix session of its own, so one noisy pipeline
+// can't drown out another's error, and each fix is committed as it lands.
+// The commits are pushed together, superseding the builds in pipeline, and
+// the pass counts as one attempt.
+// Builds that failed for infrastructure reasons are retried if nothing was
+// pushed. An interrupted pass is not checkpointed; the next starts over.
+func fixEachBuild(ctx context.Context, pr *PRDocument, backend provider.PRBackend, client llm.Client, cfg *config.Config, prInfo *provider.PRInfo, workDir string, mergeBack func() error, builds []failedBuild, pipeline []provider.BuildInfo, failedIDs []string) error {
+	attempt := pr.FixAttempts + 1
+	var events []PREvent
+	var diagnoses, checks, infraIDs, infraFailures []string
+	var lastCommit string
+	var exhausted FailureCategory
+	var exhaustedBudget int
+
+	for _, b := range builds {
+		logs := fmt.Sprintf("=== Build: %s ===\n%s\n\n", b.Name, b.Logs)
+		diagnosis, category, err := analyzeFailure(ctx, pr, client, cfg, workDir, logs, 1)
+		if err != nil {
+			return fmt.Errorf("analyzing %s: %w", b.Name, err)
+		}
+		category, signature, reclassified := recheckInfra(pr, category, []string{b.Name}, b.Logs)
+		if reclassified {
+			diagnosis += reclassifiedNote
+		}
+		diagnoses = append(diagnoses, fmt.Sprintf("## %s\n\n%s", b.Name, diagnosis))
+		if cfg.PR.PostDiagnosisComments {
+			comment := diagnosisComment(category, []string{b.Name}, diagnosis) + aiFooter(cfg)
+			if err := backend.PostComment(ctx, prInfo, comment); err != nil {
+				slog.Warn("failed to post diagnosis comment", "prID", pr.ID, "error", err)
+			}
+		}
+
+		if category == CategoryInfra {
+			slog.Info("build failed for infrastructure reasons, not fixing it", "prID", pr.ID, "build", b.Name)
+			if tests, _ := failingTests(b.Logs); len(tests) > 0 {
+				if err := recordFlakyTests(pr.Repo, pr.ID, tests); err != nil {
+					slog.Warn("failed to record flaky tests", "prID", pr.ID, "error", err)
+				}
+			}
+			infraIDs = append(infraIDs, b.ID)
+			infraFailures = append(infraFailures, signature)
+			continue
+		}
+		if spent, budget := categoryBudgetExhausted(pr, cfg, category); spent {
+			slog.Warn("fix budget exhausted for failure category, skipping build", "prID", pr.ID, "build", b.Name, "category", category, "budget", budget)
+			exhausted, exhaustedBudget = category, budget
+			continue
+		}
+
+		slog.Info("PR fix Phase 2: applying fix for build", "prID", pr.ID, "build", b.Name, "category", category)
+		commitHash, checkResults, err := applyFix(ctx, pr, client, cfg, workDir,
+			fmt.Sprintf("PR Fix #%s attempt %d: %s", pr.ID, attempt, b.Name), diagnosis, "",
+			fmt.Sprintf("fix %s failure (attempt %d)", b.Name, attempt))
+		if err != nil {
+			return fmt.Errorf("fixing %s: %w", b.Name, err)
+		}
+		if len(checkResults) > 0 {
+			checks = append(checks, b.Name+": "+pr.LastChecks)
+		}
+		if commitHash != "" {
+			lastCommit = commitHash
+		}
+		events = append(events, fixAttemptEvent(pr, category, b.Name, commitHash, checkResults))
+	}
+	pr.LastDiagnosis = strings.Join(diagnoses, "\n\n")
+	if len(checks) > 0 {
+		pr.LastChecks = strings.Join(checks, "; ")
+	}
+
+	if len(events) == 0 {
+		if len(infraIDs) > 0 {
+			return retryInfraFailure(ctx, pr, backend, prInfo, infraIDs, infraFailures, "Infrastructure failure detected")
+		}
+		return failFixBudget(ctx, pr, backend, prInfo, cfg, exhausted, exhaustedBudget)
+	}
+	if lastCommit != "" {
+		if err := pushFix(ctx, pr, cfg, workDir, mergeBack, fmt.Sprintf("fix CI failures (attempt %d)", attempt), lastCommit); err != nil {
+			return err
+		}
+		cancelSupersededBuilds(ctx, pr, backend, prInfo, pipeline, lastCommit)
+	} else {
+		failedIDs = nil
+	}
+	if err := finishFixAttempt(ctx, pr, backend, prInfo, cfg, failedIDs, events); err != nil {
+		return err
+	}
+	// A push reruns every build; without one, the infra failures need a
+	// retry of their own.
+	if lastCommit == "" && len(infraIDs) > 0 && pr.Status != "failed" {
+		return retryInfraFailure(ctx, pr, backend, prInfo, infraIDs, infraFailures, "Infrastructure failure detected")
+	}
+	return nil
+}
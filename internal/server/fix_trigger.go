@@ -0,0 +1,98 @@
+package server
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// buildFailed reports whether build failed in a way FixPR fixes.
+func buildFailed(build provider.BuildInfo) bool {
+	switch build.Result {
+	case "failed", "failure", "partiallySucceeded", "canceled":
+		return true
+	}
+	return false
+}
+
+// failedBuildIDs returns the IDs of the failed builds in status.
+func failedBuildIDs(status *provider.PipelineStatus) []string {
+	var ids []string
+	for _, build := range status.Builds {
+		if buildFailed(build) {
+			ids = append(ids, build.ID)
+		}
+	}
+	return ids
+}
+
+// hasNewFailures reports whether any of the failed builds ids is one no
+// pushed fix on pr has taken on yet. A pipeline that reruns slowly still
+// reports the failures a fix was pushed for; fixing them again would spend
+// an attempt on a failure that may already be fixed.
+func hasNewFailures(pr *PRDocument, ids []string) bool {
+	for _, id := range ids {
+		if !slices.Contains(pr.AttemptedBuilds, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// addAttemptedBuilds adds ids to attempted, skipping those already listed.
+func addAttemptedBuilds(attempted, ids []string) []string {
+	for _, id := range ids {
+		if !slices.Contains(attempted, id) {
+			attempted = append(attempted, id)
+		}
+	}
+	return attempted
+}
+
+// pruneAttemptedBuilds drops the attempted builds that status no longer
+// reports as failed: superseded by a newer run, or rerun under the same ID,
+// which ADO does when a build is retried from its UI.
+func pruneAttemptedBuilds(attempted []string, status *provider.PipelineStatus) []string {
+	failed := failedBuildIDs(status)
+	var kept []string
+	for _, id := range attempted {
+		if slices.Contains(failed, id) {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
+
+// fixBackoffPending reports whether pr's fixes are still waiting out the
+// backoff after its last fix attempt or the cooldown after someone else
+// pushed to its branch (pr.fix_backoff).
+func fixBackoffPending(pr *PRDocument, now time.Time) bool {
+	if pr.NextFixAttempt == "" {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339, pr.NextFixAttempt)
+	return err == nil && now.Before(t)
+}
+
+// holdFixesForHumanPush holds off fixes on pr for the human push cooldown,
+// since someone else pushed to its branch and may still be at work on it.
+func holdFixesForHumanPush(pr *PRDocument, cfg *config.Config, now time.Time) {
+	cooldown := cfg.PR.FixBackoff.ParseHumanPushCooldown()
+	if cooldown == 0 {
+		return
+	}
+	pr.NextFixAttempt = laterRFC3339(pr.NextFixAttempt, now.Add(cooldown))
+	slog.Info("someone else pushed to the branch, holding fixes", "prID", pr.ID, "until", pr.NextFixAttempt)
+}
+
+// laterRFC3339 returns the later of the RFC3339 time cur (which may be
+// empty) and t, formatted as RFC3339.
+func laterRFC3339(cur string, t time.Time) string {
+	if c, err := time.Parse(time.RFC3339, cur); err == nil && c.After(t) {
+		return cur
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixTrigger(t *testing.T) {
+	status := &provider.PipelineStatus{State: "failed", Builds: []provider.BuildInfo{
+		{ID: "10", Status: "completed", Result: "failed"},
+		{ID: "11", Status: "completed", Result: "succeeded"},
+		{ID: "12", Status: "completed", Result: "canceled"},
+	}}
+	assert.Equal(t, []string{"10", "12"}, failedBuildIDs(status))
+
+	pr := &PRDocument{}
+	assert.True(t, hasNewFailures(pr, []string{"10", "12"}))
+
+	// A fix was pushed for build 10; the rerun hasn't started yet.
+	pr.AttemptedBuilds = addAttemptedBuilds(pr.AttemptedBuilds, []string{"10", "10"})
+	assert.Equal(t, []string{"10"}, pr.AttemptedBuilds)
+	assert.False(t, hasNewFailures(pr, []string{"10"}))
+	assert.True(t, hasNewFailures(pr, []string{"10", "12"}), "build 12 failed since")
+
+	// Build 10 rerun under its own ID (ADO) is forgotten, as is one
+	// superseded by a newer build.
+	pr.AttemptedBuilds = []string{"10", "12", "9"}
+	status.Builds[0] = provider.BuildInfo{ID: "10", Status: "inProgress"}
+	assert.Equal(t, []string{"12"}, pruneAttemptedBuilds(pr.AttemptedBuilds, status))
+}
+
+func TestFixBackoff(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	defaults := config.DefaultConfig()
+	cfg := &defaults
+	pr := &PRDocument{ID: "3", Provider: "ado", Status: "fixing", FixAttempts: 1, MaxFixAttempts: 5}
+	require.NoError(t, SavePR(pr))
+	now := time.Now()
+	assert.False(t, fixBackoffPending(pr, now))
+
+	// A second attempt waits twice the base delay before a third.
+	require.NoError(t, finishFixAttempt(context.Background(), pr, nil, nil, cfg, nil, nil))
+	saved, err := LoadPR("ado", "3")
+	require.NoError(t, err)
+	next, err := time.Parse(time.RFC3339, saved.NextFixAttempt)
+	require.NoError(t, err)
+	assert.WithinDuration(t, now.Add(10*time.Minute), next, 5*time.Second)
+	assert.True(t, fixBackoffPending(saved, now))
+	assert.False(t, fixBackoffPending(saved, now.Add(11*time.Minute)))
+
+	// Someone else's push holds fixes for the cooldown, which outlasts
+	// the backoff here.
+	holdFixesForHumanPush(saved, cfg, now)
+	assert.True(t, fixBackoffPending(saved, now.Add(29*time.Minute)))
+	assert.False(t, fixBackoffPending(saved, now.Add(31*time.Minute)))
+
+	// A shorter hold doesn't cut a longer one short.
+	cfg.PR.FixBackoff.HumanPushCooldown = "1m"
+	holdFixesForHumanPush(saved, cfg, now)
+	assert.True(t, fixBackoffPending(saved, now.Add(29*time.Minute)))
+
+	cfg.PR.FixBackoff.HumanPushCooldown = "0s"
+	pr.NextFixAttempt = ""
+	holdFixesForHumanPush(pr, cfg, now)
+	assert.Empty(t, pr.NextFixAttempt, "cooldown disabled")
+}
+
+func TestPushAndAuditRecordsPushedCommit(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	repoDir := newRepoWithRemote(t)
+	branch := strings.TrimSpace(gitT(t, repoDir, "rev-parse", "--abbrev-ref", "HEAD"))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "fix.txt"), []byte("fixed\n"), 0644))
+	gitT(t, repoDir, "add", "-A")
+	gitT(t, repoDir, "commit", "-q", "-m", "fix")
+
+	pr := &PRDocument{ID: "4", Provider: "ado", Branch: "refs/heads/" + branch, Status: "watching"}
+	require.NoError(t, SavePR(pr))
+	require.NoError(t, pushAndAudit(context.Background(), pr, &config.Config{}, repoDir, "fix", nil))
+
+	head := strings.TrimSpace(gitT(t, repoDir, "rev-parse", "HEAD"))
+	assert.Equal(t, head, pr.PushedCommit)
+	saved, err := LoadPR("ado", "4")
+	require.NoError(t, err)
+	assert.Equal(t, head, saved.PushedCommit)
+}
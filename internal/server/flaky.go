@@ -0,0 +1,209 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/logdistill"
+	"github.com/alanmeadows/otto/internal/store"
+)
+
+// FlakyTest is a test failure that FixPR analysis classified as an
+// infrastructure/flaky failure rather than a code problem.
+type FlakyTest struct {
+	Fingerprint string    `json:"fingerprint"`
+	Repo        string    `json:"repo"`
+	Test        string    `json:"test"`
+	Tool        string    `json:"tool,omitempty"`
+	File        string    `json:"file,omitempty"`
+	Occurrences int       `json:"occurrences"`  // times analysis classified it flaky
+	AutoRetries int       `json:"auto_retries"` // times retried without analysis
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	LastPR      string    `json:"last_pr,omitempty"`
+}
+
+// flakyDir holds one flaky-test database per repository.
+func flakyDir() string {
+	return filepath.Join(filepath.Dir(PRDir()), "flaky")
+}
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func flakyPath(repo string) string {
+	name := unsafeFilenameChars.ReplaceAllString(repo, "_")
+	if name == "" {
+		name = "_"
+	}
+	return filepath.Join(flakyDir(), name+".json")
+}
+
+// failingTests distills build logs and returns the failing tests found in
+// them, and whether the logs also show compile, link, or package failures.
+// Logs that already went through GetBuildLogs keep the raw failure lines in
+// their context section, so distilling them again recovers the tests.
+func failingTests(logs string) (tests []*logdistill.Finding, otherFailures bool) {
+	for _, f := range logdistill.Distill(logs).Findings {
+		switch f.Kind {
+		case logdistill.KindTest:
+			tests = append(tests, f)
+		case logdistill.KindCompile, logdistill.KindLink, logdistill.KindPackage:
+			otherFailures = true
+		}
+	}
+	return tests, otherFailures
+}
+
+// knownFlakyTests returns the database entries for tests if every one of
+// them is a known flaky test in repo, and nil otherwise.
+func knownFlakyTests(repo string, tests []*logdistill.Finding) ([]FlakyTest, error) {
+	if len(tests) == 0 {
+		return nil, nil
+	}
+	var known []FlakyTest
+	err := store.WithReadLock(flakyPath(repo), store.DefaultLockTimeout, func() error {
+		db, err := readFlakyDB(flakyPath(repo))
+		if err != nil {
+			return err
+		}
+		for _, t := range tests {
+			entry, ok := db[t.Fingerprint()]
+			if !ok {
+				known = nil
+				return nil
+			}
+			known = append(known, *entry)
+		}
+		return nil
+	})
+	return known, err
+}
+
+// recordFlakyTests adds tests to repo's flaky database, or bumps their
+// occurrence counts if already present.
+func recordFlakyTests(repo, prID string, tests []*logdistill.Finding) error {
+	now := time.Now().UTC()
+	return updateFlakyDB(repo, func(db map[string]*FlakyTest) {
+		for _, t := range tests {
+			fp := t.Fingerprint()
+			entry, ok := db[fp]
+			if !ok {
+				entry = &FlakyTest{Fingerprint: fp, Repo: repo, Test: t.Test, Tool: t.Tool, File: t.File, FirstSeen: now}
+				db[fp] = entry
+			}
+			entry.Occurrences++
+			entry.LastSeen = now
+			entry.LastPR = prID
+		}
+	})
+}
+
+// unrecordFlakyTests takes back one occurrence of each of tests, e.g. once
+// a failure classified as flaky turns out to be real, and drops tests left
+// with none.
+func unrecordFlakyTests(repo string, tests []*logdistill.Finding) error {
+	return updateFlakyDB(repo, func(db map[string]*FlakyTest) {
+		for _, t := range tests {
+			if entry, ok := db[t.Fingerprint()]; ok {
+				if entry.Occurrences--; entry.Occurrences <= 0 {
+					delete(db, t.Fingerprint())
+				}
+			}
+		}
+	})
+}
+
+// noteFlakyRetry counts an automatic retry against each of the given tests.
+func noteFlakyRetry(repo, prID string, tests []FlakyTest) error {
+	now := time.Now().UTC()
+	return updateFlakyDB(repo, func(db map[string]*FlakyTest) {
+		for _, t := range tests {
+			if entry, ok := db[t.Fingerprint]; ok {
+				entry.AutoRetries++
+				entry.LastSeen = now
+				entry.LastPR = prID
+			}
+		}
+	})
+}
+
+// ListFlakyTests returns the flaky tests recorded for repo, or for every
+// repository when repo is empty, most frequent offenders first.
+func ListFlakyTests(repo string) ([]FlakyTest, error) {
+	paths := []string{flakyPath(repo)}
+	if repo == "" {
+		var err error
+		paths, err = filepath.Glob(filepath.Join(flakyDir(), "*.json"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var tests []FlakyTest
+	for _, path := range paths {
+		err := store.WithReadLock(path, store.DefaultLockTimeout, func() error {
+			db, err := readFlakyDB(path)
+			for _, entry := range db {
+				tests = append(tests, *entry)
+			}
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(tests, func(i, j int) bool {
+		a, b := tests[i], tests[j]
+		if ta, tb := a.Occurrences+a.AutoRetries, b.Occurrences+b.AutoRetries; ta != tb {
+			return ta > tb
+		}
+		if !a.LastSeen.Equal(b.LastSeen) {
+			return a.LastSeen.After(b.LastSeen)
+		}
+		return strings.Compare(a.Test, b.Test) < 0
+	})
+	return tests, nil
+}
+
+func updateFlakyDB(repo string, fn func(db map[string]*FlakyTest)) error {
+	path := flakyPath(repo)
+	return store.WithLock(path, store.DefaultLockTimeout, func() error {
+		db, err := readFlakyDB(path)
+		if err != nil {
+			return err
+		}
+		fn(db)
+		data, err := json.MarshalIndent(db, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling flaky test database: %w", err)
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return fmt.Errorf("writing flaky test database: %w", err)
+		}
+		return os.Rename(tmp, path)
+	})
+}
+
+// readFlakyDB loads a flaky-test database keyed by fingerprint. A missing
+// file is an empty database.
+func readFlakyDB(path string) (map[string]*FlakyTest, error) {
+	db := make(map[string]*FlakyTest)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading flaky test database: %w", err)
+	}
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, fmt.Errorf("parsing flaky test database %s: %w", path, err)
+	}
+	return db, nil
+}
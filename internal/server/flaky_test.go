@@ -0,0 +1,61 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailingTests(t *testing.T) {
+	tests, other := failingTests("=== Build: CI ===\n--- FAIL: TestRetry (1.20s)\n    retry_test.go:40: timed out\nFAIL\n##[error]Process completed with exit code 1.")
+	require.Len(t, tests, 1)
+	assert.Equal(t, "TestRetry", tests[0].Test)
+	assert.False(t, other)
+
+	tests, other = failingTests("--- FAIL: TestRetry (1.20s)\npkg/a.go:3:1: undefined: X")
+	assert.Len(t, tests, 1)
+	assert.True(t, other, "compile errors are not flaky")
+}
+
+func TestFlakyDatabase(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	run1, _ := failingTests("--- FAIL: TestRetry (1.20s)\n    retry_test.go:40: took 1203ms")
+	run2, _ := failingTests("--- FAIL: TestRetry (0.80s)\n    retry_test.go:40: took 804ms\n--- FAIL: TestOther (0.00s)")
+
+	known, err := knownFlakyTests("org/svc", run1)
+	require.NoError(t, err)
+	assert.Nil(t, known, "empty database knows nothing")
+
+	require.NoError(t, recordFlakyTests("org/svc", "101", run1))
+	known, err = knownFlakyTests("org/svc", run1)
+	require.NoError(t, err)
+	require.Len(t, known, 1)
+	assert.Equal(t, "TestRetry", known[0].Test)
+
+	known, err = knownFlakyTests("org/svc", run2)
+	require.NoError(t, err)
+	assert.Nil(t, known, "an unknown test in the run blocks the shortcut")
+
+	known, err = knownFlakyTests("other-repo", run1)
+	require.NoError(t, err)
+	assert.Nil(t, known, "databases are per repo")
+
+	known, _ = knownFlakyTests("org/svc", run1)
+	require.NoError(t, noteFlakyRetry("org/svc", "102", known))
+	require.NoError(t, recordFlakyTests("other-repo", "7", run2))
+
+	all, err := ListFlakyTests("")
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, "TestRetry", all[0].Test)
+	assert.Equal(t, "org/svc", all[0].Repo)
+	assert.Equal(t, 1, all[0].Occurrences)
+	assert.Equal(t, 1, all[0].AutoRetries)
+	assert.Equal(t, "102", all[0].LastPR)
+
+	mine, err := ListFlakyTests("org/svc")
+	require.NoError(t, err)
+	assert.Len(t, mine, 1)
+}
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/store"
+)
+
+// PREventKind identifies a kind of entry in a PR's history.
+type PREventKind string
+
+const (
+	PREventStatus          PREventKind = "status"
+	PREventFixAttempt      PREventKind = "fix_attempt"
+	PREventInfraRetry      PREventKind = "infra_retry"
+	PREventBudgetExhausted PREventKind = "budget_exhausted"
+	PREventComment         PREventKind = "comment"
+	PREventPolicyHold      PREventKind = "policy_hold"
+	PREventPolicyApproved  PREventKind = "policy_approved"
+	PREventFixRequested    PREventKind = "fix_requested"
+	PREventBuildCanceled   PREventKind = "build_canceled"
+	PREventHumanPush       PREventKind = "human_push"
+	PREventScheduled       PREventKind = "scheduled"
+)
+
+// PREventKinds lists every kind, in the order `otto pr log --stats` shows them.
+var PREventKinds = []PREventKind{
+	PREventStatus, PREventFixAttempt, PREventInfraRetry, PREventBudgetExhausted,
+	PREventComment, PREventPolicyHold, PREventPolicyApproved, PREventFixRequested,
+	PREventBuildCanceled, PREventHumanPush, PREventScheduled,
+}
+
+// PREvent is one entry in a PR's history, which otto keeps in a JSONL
+// file beside the PR document. Fields other than Time and Kind are set as
+// the kind calls for.
+type PREvent struct {
+	Time time.Time   `json:"time"`
+	Kind PREventKind `json:"kind"`
+
+	// Status changes.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
+	// Fix attempts, retries, and fix budgets.
+	Attempt        int    `json:"attempt,omitempty"`
+	Category       string `json:"category,omitempty"`
+	Build          string `json:"build,omitempty"` // build a per-build fix targeted, or builds canceled
+	Model          string `json:"model,omitempty"`
+	Commit         string `json:"commit,omitempty"`
+	Checks         string `json:"checks,omitempty"`
+	Builds         int    `json:"builds,omitempty"` // builds requeued
+	NextRetry      string `json:"next_retry,omitempty"`
+	Budget         int    `json:"budget,omitempty"`
+	MaxFixAttempts int    `json:"max_fix_attempts,omitempty"`
+
+	// Review comments.
+	Author   string `json:"author,omitempty"` // also who made a human push
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Decision string `json:"decision,omitempty"`
+	Reply    string `json:"reply,omitempty"`
+
+	// Policy holds.
+	Violations []string `json:"violations,omitempty"`
+
+	// Detail is the trigger of a retry, the change a policy hold stopped,
+	// the hold an approval released, why builds were canceled, or how
+	// otto stepped back from a human push.
+	Detail string `json:"detail,omitempty"`
+	// Output is text shown verbatim under the entry, such as the output of
+	// failing repo checks.
+	Output string `json:"output,omitempty"`
+}
+
+// prEventsPath returns the path of a PR's history file. It sits beside the
+// PR document, which ListPRs tells apart by its .md suffix.
+func prEventsPath(providerName, id string) string {
+	return filepath.Join(PRDir(), fmt.Sprintf("%s__%s.events.jsonl", providerName, id))
+}
+
+// RecordPREvent appends e to the PR's history. Time defaults to now.
+func RecordPREvent(providerName, id string, e PREvent) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshaling PR event: %w", err)
+	}
+	line = append(line, '\n')
+
+	path := prEventsPath(providerName, id)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating PR directory: %w", err)
+	}
+	return store.WithLock(path, store.DefaultLockTimeout, func() error {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("opening PR history: %w", err)
+		}
+		defer f.Close()
+		if _, err := f.Write(line); err != nil {
+			return fmt.Errorf("writing PR history: %w", err)
+		}
+		return nil
+	})
+}
+
+// logPREvent records e and logs (rather than returns) any failure: a lost
+// history entry must not fail the work it describes.
+func logPREvent(pr *PRDocument, e PREvent) {
+	if err := RecordPREvent(pr.Provider, pr.ID, e); err != nil {
+		slog.Warn("failed to record PR event", "prID", pr.ID, "kind", e.Kind, "error", err)
+	}
+}
+
+// ReadPREvents returns a PR's history, oldest first. A PR with no history
+// file has no events and no error.
+func ReadPREvents(providerName, id string) ([]PREvent, error) {
+	return readPREventsFile(prEventsPath(providerName, id))
+}
+
+// readPREventsFile reads the history file at path.
+func readPREventsFile(path string) ([]PREvent, error) {
+	var events []PREvent
+	err := store.WithReadLock(path, store.DefaultLockTimeout, func() error {
+		f, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return fmt.Errorf("opening PR history: %w", err)
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			var e PREvent
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				continue // skip a torn or foreign line rather than fail the read
+			}
+			events = append(events, e)
+		}
+		return scanner.Err()
+	})
+	return events, err
+}
+
+// RenderPRLog renders the PR's activity log: its document body (the PR
+// description, and history from before events were kept) followed by the
+// events, as markdown.
+func RenderPRLog(pr *PRDocument, events []PREvent) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(pr.Body, "\n"))
+	for _, e := range events {
+		b.WriteString("\n\n")
+		b.WriteString(strings.TrimRight(renderPREvent(pr, e), "\n"))
+	}
+	b.WriteString("\n")
+	return strings.TrimLeft(b.String(), "\n")
+}
+
+// renderPREvent renders one entry of pr's history as a markdown section.
+func renderPREvent(pr *PRDocument, e PREvent) string {
+	var b strings.Builder
+	when := e.Time.UTC().Format(time.RFC3339)
+	field := func(name, value string) {
+		fmt.Fprintf(&b, "- **%s**: %s\n", name, value)
+	}
+
+	switch e.Kind {
+	case PREventStatus:
+		fmt.Fprintf(&b, "### Status - %s\n", when)
+		field("Change", e.From+" → "+e.To)
+	case PREventFixAttempt:
+		fmt.Fprintf(&b, "### Attempt %d - %s\n", e.Attempt, when)
+		field("Trigger", "Pipeline failure")
+		if e.Build != "" {
+			field("Build", e.Build)
+		}
+		field("Category", e.Category)
+		field("Model", e.Model)
+		if e.Checks != "" {
+			field("Checks", e.Checks)
+		}
+		if e.Commit != "" {
+			field("Commit", e.Commit)
+		} else {
+			field("Commit", "none (blocked by failing checks)")
+		}
+	case PREventInfraRetry:
+		fmt.Fprintf(&b, "### Infra Retry - %s\n", when)
+		field("Trigger", e.Detail)
+		field("Builds requeued", fmt.Sprint(e.Builds))
+		field("Next retry no sooner than", e.NextRetry)
+	case PREventBudgetExhausted:
+		fmt.Fprintf(&b, "### Budget Exhausted - %s\n", when)
+		field("Category", e.Category)
+		field("Budget", fmt.Sprint(e.Budget))
+	case PREventComment:
+		fmt.Fprintf(&b, "### Comment by %s on %s:%d - %s\n", e.Author, e.File, e.Line, when)
+		field("Decision", e.Decision)
+		field("Reply", e.Reply)
+	case PREventPolicyHold:
+		fmt.Fprintf(&b, "### Policy Hold - %s\n", when)
+		field("Change", e.Detail)
+		b.WriteString("- **Violations**:\n")
+		for _, v := range e.Violations {
+			fmt.Fprintf(&b, "  - %s\n", v)
+		}
+		if e.Commit != "" {
+			field("Commit", e.Commit)
+		}
+		field("Approve", "`otto pr approve "+pr.ID+"`")
+	case PREventPolicyApproved:
+		fmt.Fprintf(&b, "### Policy Approved - %s\n", when)
+		field("Held for", e.Detail)
+		if e.Commit != "" {
+			field("Commit", e.Commit)
+		}
+	case PREventFixRequested:
+		fmt.Fprintf(&b, "### Fix Requested - %s\n", when)
+		field("Max fix attempts", fmt.Sprint(e.MaxFixAttempts))
+	case PREventBuildCanceled:
+		fmt.Fprintf(&b, "### Builds Canceled - %s\n", when)
+		field("Builds", e.Build)
+		field("Reason", e.Detail)
+	case PREventHumanPush:
+		fmt.Fprintf(&b, "### Human Activity Detected - %s\n", when)
+		field("Commit", e.Commit)
+		if e.Author != "" {
+			field("Author", e.Author)
+		}
+		if e.Detail != "" {
+			field("Action", e.Detail)
+		}
+	case PREventScheduled:
+		fmt.Fprintf(&b, "### Scheduled Task - %s\n", when)
+		field("Task", e.Detail)
+		if e.Commit != "" {
+			field("Commit", e.Commit)
+		}
+	default:
+		fmt.Fprintf(&b, "### %s - %s\n", e.Kind, when)
+		if e.Detail != "" {
+			field("Detail", e.Detail)
+		}
+	}
+	if e.Output != "" {
+		b.WriteString("\n" + strings.TrimRight(e.Output, "\n") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPREventsRoundTrip(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	events, err := ReadPREvents("ado", "1")
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	at := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	require.NoError(t, RecordPREvent("ado", "1", PREvent{Time: at, Kind: PREventFixAttempt, Attempt: 1, Category: "compile", Model: "m", Commit: "abc123"}))
+	require.NoError(t, RecordPREvent("ado", "1", PREvent{Kind: PREventComment, Author: "alice", File: "main.go", Line: 3, Decision: "AGREE", Reply: "done"}))
+
+	events, err = ReadPREvents("ado", "1")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, at, events[0].Time)
+	assert.Equal(t, "abc123", events[0].Commit)
+	assert.Equal(t, PREventComment, events[1].Kind)
+	assert.False(t, events[1].Time.IsZero(), "time defaults to now")
+}
+
+func TestRenderPRLog(t *testing.T) {
+	pr := &PRDocument{ID: "7", Body: "# Title\n\nDescription\n"}
+	at := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	got := RenderPRLog(pr, []PREvent{
+		{Time: at, Kind: PREventFixAttempt, Attempt: 2, Category: "test", Model: "m", Checks: "0/1 passed", Output: "go test: FAIL"},
+		{Time: at, Kind: PREventPolicyHold, Detail: "fix (1 files, +1 -0)", Violations: []string{"a", "b"}},
+	})
+	assert.Equal(t, `# Title
+
+Description
+
+### Attempt 2 - 2026-03-04T05:06:07Z
+- **Trigger**: Pipeline failure
+- **Category**: test
+- **Model**: m
+- **Checks**: 0/1 passed
+- **Commit**: none (blocked by failing checks)
+
+go test: FAIL
+
+### Policy Hold - 2026-03-04T05:06:07Z
+- **Change**: fix (1 files, +1 -0)
+- **Violations**:
+  - a
+  - b
+- **Approve**: `+"`otto pr approve 7`"+`
+`, got)
+}
+
+func TestPRStatusChangesRecorded(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	require.NoError(t, SavePR(&PRDocument{ID: "3", Provider: "github", Status: "watching"}))
+
+	_, err := UpdatePR("github", "3", func(p *PRDocument) error {
+		p.Status = "green"
+		return nil
+	})
+	require.NoError(t, err)
+	_, err = UpdatePR("github", "3", func(p *PRDocument) error {
+		p.LastChecked = "now" // no status change, no event
+		return nil
+	})
+	require.NoError(t, err)
+
+	events, err := ReadPREvents("github", "3")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, PREventStatus, events[0].Kind)
+	assert.Equal(t, "watching", events[0].From)
+	assert.Equal(t, "green", events[0].To)
+
+	require.NoError(t, DeletePR("github", "3"))
+	_, err = os.Stat(prEventsPath("github", "3"))
+	assert.True(t, os.IsNotExist(err), "history is removed with the PR")
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// humanPush reports whether commit, a new head of pr's branch, was pushed
+// by someone other than otto, and returns its author's email if the
+// backend can tell. The last commit otto pushed is otto's, as is a commit
+// authored under its git identity (git.author_email), which covers pushes
+// otto made but didn't get to record.
+func humanPush(ctx context.Context, pr *PRDocument, backend provider.PRBackend, prInfo *provider.PRInfo, cfg *config.Config, commit string) (string, bool) {
+	if commit == pr.PushedCommit {
+		return "", false
+	}
+	lookup, ok := commitAuthorLookup(backend)
+	if !ok {
+		return "", true
+	}
+	author, err := lookup.CommitAuthor(ctx, prInfo, commit)
+	if err != nil {
+		slog.Debug("failed to look up head commit author", "prID", pr.ID, "commit", commit, "error", err)
+		return "", true
+	}
+	if author != "" && cfg.Git.AuthorEmail != "" && strings.EqualFold(author, cfg.Git.AuthorEmail) {
+		return author, false
+	}
+	return author, true
+}
+
+// commitAuthorLookup finds the backend under any wrappers that can look up
+// commit authors.
+func commitAuthorLookup(backend provider.PRBackend) (provider.CommitAuthorLookup, bool) {
+	for {
+		if lookup, ok := backend.(provider.CommitAuthorLookup); ok {
+			return lookup, true
+		}
+		u, ok := backend.(interface{ Unwrap() provider.PRBackend })
+		if !ok {
+			return nil, false
+		}
+		backend = u.Unwrap()
+	}
+}
+
+// handleHumanPush steps back from pr after someone else pushed commit to
+// its branch, as pr.on_human_push says: fixes wait out the human push
+// cooldown, and may start their attempts over, or the PR is paused until
+// resumed. HumanActivity notes the push until the daemon takes the PR up
+// again.
+func handleHumanPush(pr *PRDocument, cfg *config.Config, commit, author string, now time.Time) error {
+	action := cfg.PR.OnHumanPush
+	if action == "" {
+		action = config.HumanPushCooldown
+	}
+	who := author
+	if who == "" {
+		who = "someone else"
+	}
+	activity := fmt.Sprintf("%s pushed %s at %s", who, shortCommit(commit), now.UTC().Format(time.RFC3339))
+
+	if action == config.HumanPushReset {
+		pr.NextFixAttempt = ""
+	}
+	holdFixesForHumanPush(pr, cfg, now)
+	next := pr.NextFixAttempt
+	err := savePRChange(pr, func(p *PRDocument) {
+		p.HumanActivity = activity
+		p.NextFixAttempt = next
+		switch action {
+		case config.HumanPushReset:
+			p.FixAttempts = 0
+			p.FixAttemptsByCategory = nil
+			p.AttemptedBuilds = nil
+			if p.Status == "failed" {
+				p.Status = "watching"
+			}
+		case config.HumanPushPause:
+			p.Paused = true
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	var details []string
+	switch action {
+	case config.HumanPushPause:
+		details = append(details, "paused until resumed")
+	case config.HumanPushReset:
+		details = append(details, "fix attempts reset")
+	}
+	if action != config.HumanPushPause && next != "" {
+		details = append(details, "fixes held until "+next)
+	}
+	logPREvent(pr, PREvent{Kind: PREventHumanPush, Commit: commit, Author: author, Detail: strings.Join(details, "; ")})
+	slog.Info("human activity detected", "prID", pr.ID, "commit", commit, "author", author, "action", action)
+	return nil
+}
+
+// shortCommit abbreviates a commit SHA for display.
+func shortCommit(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alanmeadows/otto/internal/audit"
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// authorBackend is a backend that reports every commit's author as author.
+type authorBackend struct {
+	provider.PRBackend
+	author string
+}
+
+func (b *authorBackend) CommitAuthor(context.Context, *provider.PRInfo, string) (string, error) {
+	return b.author, nil
+}
+
+func TestHumanPush(t *testing.T) {
+	defaults := config.DefaultConfig()
+	cfg := &defaults
+	cfg.Git.AuthorEmail = "otto@example.com"
+	pr := &PRDocument{ID: "1", PushedCommit: "aaa"}
+	backend := audit.WrapBackend(&authorBackend{author: "dev@example.com"})
+
+	_, human := humanPush(context.Background(), pr, backend, &provider.PRInfo{}, cfg, "aaa")
+	assert.False(t, human, "otto's own push")
+
+	author, human := humanPush(context.Background(), pr, backend, &provider.PRInfo{}, cfg, "bbb")
+	assert.True(t, human)
+	assert.Equal(t, "dev@example.com", author)
+
+	// A commit under otto's identity is otto's, even if not recorded.
+	backend = audit.WrapBackend(&authorBackend{author: "Otto@Example.com"})
+	_, human = humanPush(context.Background(), pr, backend, &provider.PRInfo{}, cfg, "bbb")
+	assert.False(t, human)
+
+	// Without git.author_email, otto commits as the user; only the SHA tells.
+	cfg.Git.AuthorEmail = ""
+	_, human = humanPush(context.Background(), pr, backend, &provider.PRInfo{}, cfg, "bbb")
+	assert.True(t, human)
+}
+
+func TestHandleHumanPush(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	defaults := config.DefaultConfig()
+	cfg := &defaults
+	now := time.Now()
+
+	newPR := func(id string) *PRDocument {
+		pr := &PRDocument{
+			ID: id, Provider: "ado", Status: "failed", PipelineState: "failed",
+			FixAttempts: 3, MaxFixAttempts: 3, FixAttemptsByCategory: map[string]int{"test": 3},
+			AttemptedBuilds: []string{"42"}, MerlinBotDone: true, FeedbackDone: true,
+		}
+		require.NoError(t, SavePR(pr))
+		return pr
+	}
+
+	// cooldown (default): fixes wait, attempts are kept.
+	pr := newPR("1")
+	require.NoError(t, handleHumanPush(pr, cfg, "0123456789abcdef", "dev@example.com", now))
+	saved, err := LoadPR("ado", "1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, saved.FixAttempts)
+	assert.True(t, fixBackoffPending(saved, now.Add(29*time.Minute)))
+	assert.Contains(t, saved.HumanActivity, "dev@example.com pushed 01234567")
+	saved.Status = "watching"
+	assert.Contains(t, saved.ComputeWaitingOn(), "human activity detected")
+
+	// reset: the fix attempts start over.
+	cfg.PR.OnHumanPush = config.HumanPushReset
+	pr = newPR("2")
+	require.NoError(t, handleHumanPush(pr, cfg, "0123456789abcdef", "", now))
+	saved, err = LoadPR("ado", "2")
+	require.NoError(t, err)
+	assert.Equal(t, 0, saved.FixAttempts)
+	assert.Empty(t, saved.FixAttemptsByCategory)
+	assert.Empty(t, saved.AttemptedBuilds)
+	assert.Equal(t, "watching", saved.Status)
+	assert.Contains(t, saved.HumanActivity, "someone else pushed")
+
+	// pause: the PR is paused until resumed, which clears the note.
+	cfg.PR.OnHumanPush = config.HumanPushPause
+	pr = newPR("3")
+	require.NoError(t, handleHumanPush(pr, cfg, "0123456789abcdef", "dev@example.com", now))
+	saved, err = LoadPR("ado", "3")
+	require.NoError(t, err)
+	assert.True(t, saved.Paused)
+	assert.Equal(t, "human activity detected", saved.ComputeWaitingOn())
+	resumed, err := SetPRPaused("3", false)
+	require.NoError(t, err)
+	assert.Empty(t, resumed.HumanActivity)
+
+	events, err := ReadPREvents("ado", "3")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, PREventHumanPush, events[0].Kind)
+	assert.Equal(t, "paused until resumed", events[0].Detail)
+	assert.Contains(t, renderPREvent(saved, events[0]), "### Human Activity Detected")
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxInstructionsBytes bounds the repo instructions appended to a prompt.
+const maxInstructionsBytes = 16000
+
+// repoInstructions returns the repo's .otto/instructions.md from workDir —
+// coding conventions, test commands, review rules — or "" if it has none.
+func repoInstructions(workDir string) string {
+	if workDir == "" {
+		return ""
+	}
+	path := filepath.Join(workDir, ".otto", "instructions.md")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("failed to read repo instructions", "path", path, "error", err)
+		}
+		return ""
+	}
+	instructions := strings.TrimSpace(string(data))
+	if len(instructions) > maxInstructionsBytes {
+		instructions = instructions[:maxInstructionsBytes] + "\n... (instructions truncated)"
+	}
+	return instructions
+}
+
+// withRepoInstructions appends the repo's instructions from workDir to
+// prompt so the LLM's output follows the repo's norms.
+func withRepoInstructions(prompt, workDir string) string {
+	instructions := repoInstructions(workDir)
+	if instructions == "" {
+		return prompt
+	}
+	return prompt + "\n\n## Repository Instructions\n\nThis repository's maintainers ask that all changes and replies follow these instructions:\n\n" + instructions + "\n"
+}
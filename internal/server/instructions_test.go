@@ -0,0 +1,23 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRepoInstructions(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, "Fix it.", withRepoInstructions("Fix it.", dir), "no instructions file leaves the prompt alone")
+	assert.Equal(t, "Fix it.", withRepoInstructions("Fix it.", ""))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".otto"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".otto", "instructions.md"), []byte("Run `make test` before committing.\n"), 0o644))
+
+	prompt := withRepoInstructions("Fix it.", dir)
+	assert.Contains(t, prompt, "Fix it.\n\n## Repository Instructions")
+	assert.Contains(t, prompt, "Run `make test` before committing.")
+}
@@ -0,0 +1,373 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/prompts"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/alanmeadows/otto/internal/provider/ado"
+	"github.com/alanmeadows/otto/internal/store"
+)
+
+// maxDuplicateCandidates bounds the open issues a triage compares a new
+// issue against.
+const maxDuplicateCandidates = 200
+
+// IssueTriage records the triage of one issue: what the LLM made of it and
+// what otto did about it.
+type IssueTriage struct {
+	Provider    string    `json:"provider"`
+	Repo        string    `json:"repo"`
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Kind        string    `json:"kind"`                   // bug, feature, question, or other
+	Labels      []string  `json:"labels,omitempty"`       // labels applied
+	DuplicateOf string    `json:"duplicate_of,omitempty"` // ID of the open issue this one duplicates
+	Response    string    `json:"response,omitempty"`     // drafted reply to the reporter
+	Responded   bool      `json:"responded,omitempty"`    // whether Response was posted
+	SpecSlug    string    `json:"spec_slug,omitempty"`    // suggested spec name for feature work
+	Triaged     time.Time `json:"triaged"`
+}
+
+// issueTriageResult is the issue-triage.md response.
+type issueTriageResult struct {
+	Kind        string   `json:"kind"`
+	Labels      []string `json:"labels"`
+	DuplicateOf string   `json:"duplicate_of"`
+	Response    string   `json:"response"`
+	SpecSlug    string   `json:"spec_slug"`
+}
+
+// issueDB is the issue triage record: per watched repository, the creation
+// time of the newest issue seen, and the triages done, by issueKey.
+type issueDB struct {
+	Watermarks map[string]time.Time    `json:"watermarks"`
+	Triaged    map[string]*IssueTriage `json:"triaged"`
+}
+
+// issuesPath is where the issue triage record is kept.
+func issuesPath() string {
+	return filepath.Join(filepath.Dir(PRDir()), "issues.json")
+}
+
+// issueKey identifies an issue in the triage record.
+func issueKey(providerName, repo, id string) string {
+	return providerName + "/" + repo + "#" + id
+}
+
+// triageIssues triages the issues opened in the repositories of the
+// issues.watch rules since the last poll, and returns how many it
+// triaged. The first poll of a repository only records where to start, so
+// enabling triage does not sweep up an existing backlog.
+func triageIssues(ctx context.Context, reg *provider.Registry, client llm.Client, cfg *config.Config) (int, error) {
+	triaged := 0
+	for i, rule := range cfg.Issues.Watch {
+		providerName := rule.Provider
+		if providerName == "" {
+			providerName = cfg.PR.DefaultProvider
+		}
+		if providerName == "" {
+			providerName = "ado"
+		}
+		if _, paused := authState.paused(providerName); paused {
+			continue
+		}
+		backend, err := reg.Get(providerName)
+		if err != nil {
+			slog.Warn("skipping issue watch rule", "rule", i, "provider", providerName, "error", err)
+			continue
+		}
+		tracker, ok := backend.(provider.IssueTracker)
+		if !ok {
+			slog.Warn("skipping issue watch rule: provider has no issue tracker", "rule", i, "provider", providerName)
+			continue
+		}
+
+		n, err := triageRepoIssues(ctx, tracker, client, cfg, providerName, rule.Repo)
+		triaged += n
+		if err != nil {
+			if errors.Is(err, ado.ErrAuthExpired) {
+				authState.fail(ctx, cfg, providerName, err)
+				return triaged, err
+			}
+			slog.Warn("failed to triage issues", "rule", i, "provider", providerName, "repo", rule.Repo, "error", err)
+		}
+	}
+	return triaged, nil
+}
+
+// triageRepoIssues triages the new issues of one watched repository.
+func triageRepoIssues(ctx context.Context, tracker provider.IssueTracker, client llm.Client, cfg *config.Config, providerName, repo string) (int, error) {
+	watermarkKey := providerName + "/" + repo
+	db, err := loadIssueDB()
+	if err != nil {
+		return 0, err
+	}
+	since, ok := db.Watermarks[watermarkKey]
+	if !ok {
+		slog.Info("starting issue triage", "provider", providerName, "repo", repo)
+		return 0, setIssueWatermark(watermarkKey, time.Now().UTC())
+	}
+
+	fresh, err := tracker.ListIssues(ctx, provider.IssueFilter{Repo: repo, Since: since})
+	if err != nil || len(fresh) == 0 {
+		return 0, err
+	}
+	// Every open issue is a duplicate candidate; the listing is capped, so
+	// the new ones are added in case they fell past it.
+	open, err := tracker.ListIssues(ctx, provider.IssueFilter{Repo: repo})
+	if err != nil {
+		return 0, err
+	}
+	for _, issue := range fresh {
+		if !slices.ContainsFunc(open, func(o *provider.Issue) bool { return o.ID == issue.ID }) {
+			open = append(open, issue)
+		}
+	}
+
+	triaged := 0
+	for _, issue := range fresh {
+		if issue.Created.Before(since) || db.Triaged[issueKey(providerName, issue.Repo, issue.ID)] != nil {
+			continue
+		}
+		t, err := triageIssue(ctx, tracker, client, cfg, issue, open)
+		if err != nil {
+			slog.Warn("failed to triage issue", "issueID", issue.ID, "title", issue.Title, "error", err)
+			continue
+		}
+		t.Provider = providerName
+		if err := saveIssueTriage(watermarkKey, t, issue.Created); err != nil {
+			return triaged, err
+		}
+		triaged++
+	}
+	return triaged, nil
+}
+
+// triageIssue runs the issue-triage.md prompt on issue, with the other
+// open issues as duplicate candidates, then labels the issue and, with
+// issues.respond, posts the drafted reply.
+func triageIssue(ctx context.Context, tracker provider.IssueTracker, client llm.Client, cfg *config.Config, issue *provider.Issue, open []*provider.Issue) (*IssueTriage, error) {
+	var candidates []string
+	for _, o := range open {
+		if o.ID == issue.ID || !o.Created.Before(issue.Created) {
+			continue
+		}
+		candidates = append(candidates, fmt.Sprintf("- #%s: %s", o.ID, o.Title))
+	}
+	if len(candidates) > maxDuplicateCandidates {
+		candidates = candidates[len(candidates)-maxDuplicateCandidates:]
+	}
+	if len(candidates) == 0 {
+		candidates = []string{"(none)"}
+	}
+	labels := "(none; return an empty list)"
+	if len(cfg.Issues.Labels) > 0 {
+		labels = "- " + strings.Join(cfg.Issues.Labels, "\n- ")
+	}
+	author := issue.Author
+	if author == "" {
+		author = "an unknown user"
+	}
+	prompt, err := prompts.Execute("issue-triage.md", map[string]string{
+		"Repo":       issue.Repo,
+		"ID":         issue.ID,
+		"Title":      issue.Title,
+		"Author":     author,
+		"Body":       issue.Body,
+		"OpenIssues": strings.Join(candidates, "\n"),
+		"Labels":     labels,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("building triage prompt: %w", err)
+	}
+
+	session, err := client.CreateSession(ctx, fmt.Sprintf("Issue Triage #%s", issue.ID), "")
+	if err != nil {
+		return nil, fmt.Errorf("creating triage session: %w", err)
+	}
+	defer client.DeleteSession(ctx, session.ID)
+
+	resp, err := client.SendPrompt(ctx, session.ID, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("triage prompt failed: %w", err)
+	}
+	result, err := llm.ParseJSONResponse[issueTriageResult](ctx, client, session.ID, resp.Content)
+	if err != nil {
+		return nil, fmt.Errorf("parsing triage response: %w", err)
+	}
+
+	t := &IssueTriage{
+		Repo:     issue.Repo,
+		ID:       issue.ID,
+		Title:    issue.Title,
+		URL:      issue.URL,
+		Kind:     result.Kind,
+		Response: strings.TrimSpace(result.Response),
+		SpecSlug: result.SpecSlug,
+		Triaged:  time.Now().UTC(),
+	}
+	// Only a duplicate of an issue that was offered counts.
+	if dup := strings.TrimPrefix(result.DuplicateOf, "#"); dup != "" && dup != issue.ID &&
+		slices.ContainsFunc(open, func(o *provider.Issue) bool { return o.ID == dup }) {
+		t.DuplicateOf = dup
+	}
+
+	var apply []string
+	for _, l := range result.Labels {
+		if i := slices.IndexFunc(cfg.Issues.Labels, func(allowed string) bool { return strings.EqualFold(allowed, l) }); i >= 0 &&
+			!slices.Contains(apply, cfg.Issues.Labels[i]) {
+			apply = append(apply, cfg.Issues.Labels[i])
+		}
+	}
+	if t.DuplicateOf != "" && cfg.Issues.DuplicateLabel != "" && !slices.Contains(apply, cfg.Issues.DuplicateLabel) {
+		apply = append(apply, cfg.Issues.DuplicateLabel)
+	}
+	if len(apply) > 0 {
+		if err := tracker.LabelIssue(ctx, issue, apply); err != nil {
+			return nil, err
+		}
+		t.Labels = apply
+	}
+
+	if cfg.Issues.Respond && t.Response != "" {
+		body := t.Response
+		if !cfg.PR.DisableAIFooter {
+			body += provider.AIFooter
+		}
+		if err := tracker.CommentOnIssue(ctx, issue, body); err != nil {
+			return nil, err
+		}
+		t.Responded = true
+	}
+	slog.Info("triaged issue", "issueID", issue.ID, "title", issue.Title, "kind", t.Kind,
+		"labels", t.Labels, "duplicateOf", t.DuplicateOf, "specSlug", t.SpecSlug)
+	return t, nil
+}
+
+// ListIssueTriages returns the recorded issue triages, most recent first.
+func ListIssueTriages() ([]*IssueTriage, error) {
+	db, err := loadIssueDB()
+	if err != nil {
+		return nil, err
+	}
+	triages := make([]*IssueTriage, 0, len(db.Triaged))
+	for _, t := range db.Triaged {
+		triages = append(triages, t)
+	}
+	sort.Slice(triages, func(i, j int) bool {
+		return triages[i].Triaged.After(triages[j].Triaged)
+	})
+	return triages, nil
+}
+
+// FindIssueTriage returns the triage of the issue with the given ID, or
+// "<repo>#<id>" when the ID alone is ambiguous.
+func FindIssueTriage(ref string) (*IssueTriage, error) {
+	triages, err := ListIssueTriages()
+	if err != nil {
+		return nil, err
+	}
+	repo, id, scoped := strings.Cut(strings.TrimPrefix(ref, "#"), "#")
+	if !scoped {
+		repo, id = "", repo
+	}
+	var found []*IssueTriage
+	for _, t := range triages {
+		if t.ID == id && (repo == "" || t.Repo == repo) {
+			found = append(found, t)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no triaged issue %s", ref)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("issue %s was triaged in several repositories; use <repo>#%s", ref, id)
+	}
+}
+
+// setIssueWatermark records when triage of a repository starts.
+func setIssueWatermark(key string, at time.Time) error {
+	return updateIssueDB(func(db *issueDB) {
+		db.Watermarks[key] = at
+	})
+}
+
+// saveIssueTriage records t and moves the repository's watermark up to
+// created, the triaged issue's creation time.
+func saveIssueTriage(watermarkKey string, t *IssueTriage, created time.Time) error {
+	return updateIssueDB(func(db *issueDB) {
+		db.Triaged[issueKey(t.Provider, t.Repo, t.ID)] = t
+		if created.After(db.Watermarks[watermarkKey]) {
+			db.Watermarks[watermarkKey] = created
+		}
+	})
+}
+
+// loadIssueDB reads the issue triage record under its lock.
+func loadIssueDB() (*issueDB, error) {
+	var db *issueDB
+	err := store.WithReadLock(issuesPath(), store.DefaultLockTimeout, func() error {
+		var err error
+		db, err = readIssueDB()
+		return err
+	})
+	return db, err
+}
+
+// updateIssueDB applies update to the issue triage record under its lock.
+func updateIssueDB(update func(*issueDB)) error {
+	path := issuesPath()
+	return store.WithLock(path, store.DefaultLockTimeout, func() error {
+		db, err := readIssueDB()
+		if err != nil {
+			return err
+		}
+		update(db)
+		data, err := json.MarshalIndent(db, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling issue triage: %w", err)
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return fmt.Errorf("writing issue triage: %w", err)
+		}
+		return os.Rename(tmp, path)
+	})
+}
+
+// readIssueDB loads the issue triage record. A missing file is empty.
+func readIssueDB() (*issueDB, error) {
+	db := &issueDB{}
+	data, err := os.ReadFile(issuesPath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading issue triage: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, db); err != nil {
+			return nil, fmt.Errorf("parsing issue triage: %w", err)
+		}
+	}
+	if db.Watermarks == nil {
+		db.Watermarks = make(map[string]time.Time)
+	}
+	if db.Triaged == nil {
+		db.Triaged = make(map[string]*IssueTriage)
+	}
+	return db, nil
+}
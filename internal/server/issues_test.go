@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// issueTracker serves a fixed set of open issues and records labels and
+// comments.
+type issueTracker struct {
+	issues   []*provider.Issue
+	labels   map[string][]string
+	comments map[string]string
+}
+
+func (tr *issueTracker) ListIssues(_ context.Context, filter provider.IssueFilter) ([]*provider.Issue, error) {
+	var list []*provider.Issue
+	for _, is := range tr.issues {
+		if !is.Created.Before(filter.Since) {
+			list = append(list, is)
+		}
+	}
+	return list, nil
+}
+
+func (tr *issueTracker) LabelIssue(_ context.Context, issue *provider.Issue, labels []string) error {
+	tr.labels[issue.ID] = labels
+	return nil
+}
+
+func (tr *issueTracker) CommentOnIssue(_ context.Context, issue *provider.Issue, body string) error {
+	tr.comments[issue.ID] = body
+	return nil
+}
+
+func TestTriageRepoIssues(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	defaults := config.DefaultConfig()
+	cfg := &defaults
+	cfg.Issues.Labels = []string{"bug", "ui"}
+	cfg.Issues.Respond = true
+	cfg.PR.DisableAIFooter = true
+
+	now := time.Now().UTC()
+	tracker := &issueTracker{
+		issues: []*provider.Issue{
+			{ID: "1", Repo: "o/r", Title: "Crash on start", Created: now.Add(-48 * time.Hour)},
+		},
+		labels:   make(map[string][]string),
+		comments: make(map[string]string),
+	}
+	client := llm.NewMockClient()
+	client.DefaultResult = `{"kind": "bug", "labels": ["Bug", "security"], "duplicate_of": "#1",
+		"response": "Looks like #1.", "spec_slug": ""}`
+
+	// The first poll only starts the clock: the existing issue is left alone.
+	n, err := triageRepoIssues(context.Background(), tracker, client, cfg, "github", "o/r")
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Empty(t, client.PromptHistory)
+
+	tracker.issues = append(tracker.issues, &provider.Issue{ID: "2", Repo: "o/r", Title: "App crashes when started", Created: time.Now().UTC()})
+	n, err = triageRepoIssues(context.Background(), tracker, client, cfg, "github", "o/r")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, client.PromptHistory, 1)
+	assert.Contains(t, client.PromptHistory[0].Prompt, "- #1: Crash on start")
+	assert.Equal(t, []string{"bug", "duplicate"}, tracker.labels["2"], "only allowed labels, plus the duplicate label")
+	assert.Equal(t, "Looks like #1.", tracker.comments["2"])
+
+	// Triaged issues are not triaged again.
+	n, err = triageRepoIssues(context.Background(), tracker, client, cfg, "github", "o/r")
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	triage, err := FindIssueTriage("2")
+	require.NoError(t, err)
+	assert.Equal(t, "github", triage.Provider)
+	assert.Equal(t, "1", triage.DuplicateOf)
+	assert.True(t, triage.Responded)
+	_, err = FindIssueTriage("o/r#1")
+	assert.Error(t, err)
+
+	// Without issues.respond the reply is only drafted.
+	cfg.Issues.Respond = false
+	client.DefaultResult = `{"kind": "feature", "labels": [], "response": "Thanks!", "spec_slug": "dark-mode"}`
+	tracker.issues = append(tracker.issues, &provider.Issue{ID: "3", Repo: "o/r", Title: "Dark mode", Created: time.Now().UTC()})
+	_, err = triageRepoIssues(context.Background(), tracker, client, cfg, "github", "o/r")
+	require.NoError(t, err)
+	assert.NotContains(t, tracker.comments, "3")
+	triage, err = FindIssueTriage("o/r#3")
+	require.NoError(t, err)
+	assert.Equal(t, "dark-mode", triage.SpecSlug)
+	assert.Equal(t, "Thanks!", triage.Response)
+	assert.False(t, triage.Responded)
+}
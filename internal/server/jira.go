@@ -0,0 +1,32 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/jira"
+)
+
+// transitionJiraIssues moves the Jira issues named in the PR's title or
+// branch to status. Failures are logged; they never hold up the PR.
+func transitionJiraIssues(ctx context.Context, cfg *config.Config, pr *PRDocument, status string) {
+	if !cfg.Jira.Enabled() || status == "" {
+		return
+	}
+	keys := jira.FindKeys(cfg.Jira.Projects, pr.Title, pr.Branch)
+	if len(keys) == 0 {
+		return
+	}
+	client := jira.New(cfg.Jira)
+	for _, key := range keys {
+		moved, err := client.Transition(ctx, key, status)
+		if err != nil {
+			slog.Warn("failed to transition Jira issue", "prID", pr.ID, "issue", key, "status", status, "error", err)
+			continue
+		}
+		if moved {
+			slog.Info("transitioned Jira issue", "prID", pr.ID, "issue", key, "status", status)
+		}
+	}
+}
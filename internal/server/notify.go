@@ -8,6 +8,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/alanmeadows/otto/internal/config"
@@ -25,6 +26,28 @@ const (
 	EventPRFailed       NotificationEvent = "pr_failed"
 	EventSpecComplete   NotificationEvent = "spec_complete"
 	EventCommentHandled NotificationEvent = "comment_handled"
+
+	// EventDaemonRecovered reports what the boot-time recovery audit found.
+	EventDaemonRecovered NotificationEvent = "daemon_recovered"
+
+	// EventPRTracked reports a PR otto started tracking on add or submit,
+	// with its risk analysis.
+	EventPRTracked NotificationEvent = "pr_tracked"
+
+	// EventPRNeedsApproval reports an automated push held by the change policy.
+	EventPRNeedsApproval NotificationEvent = "pr_needs_approval"
+
+	// EventPRSecretDetected reports an automated push aborted by the secret scan.
+	EventPRSecretDetected NotificationEvent = "pr_secret_detected"
+
+	// Scheduled task results (schedules).
+	EventTaskCompleted NotificationEvent = "task_completed"
+	EventTaskFailed    NotificationEvent = "task_failed"
+
+	// Provider credential health, from the periodic auth check.
+	EventAuthExpiring NotificationEvent = "auth_expiring"
+	EventAuthFailed   NotificationEvent = "auth_failed"
+	EventAuthRestored NotificationEvent = "auth_restored"
 )
 
 // NotificationPayload carries details about a notification event.
@@ -61,6 +84,16 @@ func Notify(ctx context.Context, cfg *config.NotificationsConfig, payload Notifi
 		}
 	}
 
+	if err := deliverNotification(ctx, cfg, payload); err != nil {
+		// Keep the notification for redelivery on the next daemon boot.
+		enqueueNotification(payload)
+		return err
+	}
+	return nil
+}
+
+// deliverNotification posts a single notification to the webhook.
+func deliverNotification(ctx context.Context, cfg *config.NotificationsConfig, payload NotificationPayload) error {
 	card := buildAdaptiveCard(payload)
 
 	body, err := json.Marshal(card)
@@ -109,6 +142,24 @@ func buildAdaptiveCard(payload NotificationPayload) map[string]any {
 		headerText = "📋 Spec Complete"
 	case EventCommentHandled:
 		headerText = "💬 Comment Handled"
+	case EventDaemonRecovered:
+		headerText = "🔁 Daemon Recovered"
+	case EventPRTracked:
+		headerText = "🔎 PR Tracked"
+	case EventPRNeedsApproval:
+		headerText = "✋ PR Change Needs Approval"
+	case EventPRSecretDetected:
+		headerText = "🔐 Secret Blocked in PR Change"
+	case EventAuthExpiring:
+		headerText = "⏳ Credentials Expiring"
+	case EventAuthFailed:
+		headerText = "🔑 Credentials Rejected"
+	case EventAuthRestored:
+		headerText = "🔓 Credentials Restored"
+	case EventTaskCompleted:
+		headerText = "🗓️ Scheduled Task Completed"
+	case EventTaskFailed:
+		headerText = "❌ Scheduled Task Failed"
 	}
 
 	// Build facts.
@@ -125,8 +176,14 @@ func buildAdaptiveCard(payload NotificationPayload) map[string]any {
 			"value": fmt.Sprintf("%d / %d", payload.FixAttempts, payload.MaxAttempts),
 		})
 	}
-	for k, v := range payload.Extra {
-		facts = append(facts, map[string]any{"title": k, "value": v})
+	// Sort extra keys so cards render consistently.
+	extraKeys := make([]string, 0, len(payload.Extra))
+	for k := range payload.Extra {
+		extraKeys = append(extraKeys, k)
+	}
+	sort.Strings(extraKeys)
+	for _, k := range extraKeys {
+		facts = append(facts, map[string]any{"title": k, "value": payload.Extra[k]})
 	}
 
 	// Build card body.
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/store"
+)
+
+// maxOutboxEntries bounds the notification outbox; the oldest entries are
+// dropped first so a long webhook outage can't grow it without limit.
+const maxOutboxEntries = 100
+
+// notifyOutboxPath returns the location of undelivered notifications.
+func notifyOutboxPath() string {
+	return filepath.Join(filepath.Dir(PRDir()), "notify_outbox.jsonl")
+}
+
+// enqueueNotification appends a notification that failed to send to the
+// outbox. Failures are logged, never returned.
+func enqueueNotification(payload NotificationPayload) {
+	path := notifyOutboxPath()
+	err := store.WithLock(path, store.DefaultLockTimeout, func() error {
+		entries, err := readOutbox(path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, payload)
+		if len(entries) > maxOutboxEntries {
+			entries = entries[len(entries)-maxOutboxEntries:]
+		}
+		return writeOutbox(path, entries)
+	})
+	if err != nil {
+		slog.Warn("failed to queue undelivered notification", "event", string(payload.Event), "error", err)
+	}
+}
+
+// flushNotificationOutbox retries every queued notification, keeping the
+// ones that still fail. It returns how many were sent and how many remain.
+func flushNotificationOutbox(ctx context.Context, cfg *config.NotificationsConfig) (sent, remaining int, err error) {
+	path := notifyOutboxPath()
+	err = store.WithLock(path, store.DefaultLockTimeout, func() error {
+		entries, err := readOutbox(path)
+		if err != nil || len(entries) == 0 {
+			return err
+		}
+		if cfg.TeamsWebhookURL == "" {
+			// Nowhere to send them; drop rather than hold forever.
+			slog.Info("dropping queued notifications: no webhook configured", "count", len(entries))
+			return writeOutbox(path, nil)
+		}
+		var kept []NotificationPayload
+		for _, p := range entries {
+			if err := deliverNotification(ctx, cfg, p); err != nil {
+				slog.Debug("queued notification still undeliverable", "event", string(p.Event), "error", err)
+				kept = append(kept, p)
+				continue
+			}
+			sent++
+		}
+		remaining = len(kept)
+		return writeOutbox(path, kept)
+	})
+	return sent, remaining, err
+}
+
+// readOutbox loads queued notifications. A missing file is an empty outbox.
+func readOutbox(path string) ([]NotificationPayload, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening notification outbox: %w", err)
+	}
+	defer f.Close()
+
+	var entries []NotificationPayload
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var p NotificationPayload
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			continue
+		}
+		entries = append(entries, p)
+	}
+	return entries, scanner.Err()
+}
+
+// writeOutbox replaces the outbox contents, removing the file when empty.
+func writeOutbox(path string, entries []NotificationPayload) error {
+	if len(entries) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("clearing notification outbox: %w", err)
+		}
+		return nil
+	}
+	var buf []byte
+	for _, p := range entries {
+		line, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("marshaling queued notification: %w", err)
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+	if err := os.WriteFile(path, buf, 0600); err != nil {
+		return fmt.Errorf("writing notification outbox: %w", err)
+	}
+	return nil
+}
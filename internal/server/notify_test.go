@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"github.com/alanmeadows/otto/internal/config"
@@ -218,6 +219,7 @@ func TestBuildAdaptiveCard_NoURL(t *testing.T) {
 }
 
 func TestNotify_WebhookErrorResponse(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadGateway)
 		_, _ = w.Write([]byte("upstream connect error"))
@@ -238,6 +240,7 @@ func TestNotify_WebhookErrorResponse(t *testing.T) {
 }
 
 func TestNotify_CancelledContext(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
@@ -257,3 +260,42 @@ func TestNotify_CancelledContext(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "context canceled")
 }
+
+func TestNotify_FailedDeliveryIsQueuedAndFlushed(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	var up atomic.Bool
+	var delivered atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		delivered.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cfg := &config.NotificationsConfig{TeamsWebhookURL: srv.URL}
+	require.Error(t, Notify(t.Context(), cfg, NotificationPayload{Event: EventPRGreen, Title: "one"}))
+	require.Error(t, Notify(t.Context(), cfg, NotificationPayload{Event: EventPRFailed, Title: "two"}))
+
+	queued, err := readOutbox(notifyOutboxPath())
+	require.NoError(t, err)
+	require.Len(t, queued, 2)
+	assert.Equal(t, "one", queued[0].Title)
+
+	// Still down: nothing is lost.
+	sent, remaining, err := flushNotificationOutbox(t.Context(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Equal(t, 2, remaining)
+
+	up.Store(true)
+	sent, remaining, err = flushNotificationOutbox(t.Context(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, 0, remaining)
+	assert.Equal(t, int32(2), delivered.Load())
+	assert.NoFileExists(t, notifyOutboxPath())
+}
@@ -0,0 +1,228 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/audit"
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/policy"
+	"github.com/alanmeadows/otto/internal/repo"
+)
+
+// ErrPolicyHold is returned when a push is held for breaking pr.policy.
+var ErrPolicyHold = errors.New("push held by change policy")
+
+// ErrSecretDetected is returned when a push would add a credential.
+var ErrSecretDetected = errors.New("push aborted: change contains a possible secret")
+
+// scanPushForSecrets aborts a push whose added lines in scope look like
+// credentials. LLM fixes and conflict resolutions can copy tokens from build
+// logs or environment dumps into code, so everything otto writes is
+// scanned; there is no approval override, the commit has to be fixed.
+func scanPushForSecrets(ctx context.Context, pr *PRDocument, cfg *config.Config, workDir, detail string, scope pushScope) error {
+	findings, err := policy.ScanSecrets(ctx, workDir, scope.base, scope.paths...)
+	if err != nil {
+		// Fail closed: an unscanned change is not pushed.
+		return fmt.Errorf("scanning for secrets: %w", err)
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	locations := make([]string, len(findings))
+	for i, f := range findings {
+		locations[i] = f.String()
+	}
+	summary := strings.Join(locations, "; ")
+	slog.Error("automated push aborted: possible secret in change", "prID", pr.ID, "findings", summary)
+
+	audit.Log(audit.Entry{
+		Action:   audit.ActionPushBlocked,
+		Provider: pr.Provider,
+		PRID:     pr.ID,
+		PRURL:    pr.URL,
+		Branch:   pr.Branch,
+		Commit:   gitHeadShort(ctx, workDir),
+		Detail:   "secret scan (" + detail + "): " + summary,
+	})
+	if cfg != nil {
+		if err := Notify(ctx, &cfg.Notifications, NotificationPayload{
+			Event:  EventPRSecretDetected,
+			Title:  pr.Title,
+			URL:    pr.URL,
+			Status: "push aborted",
+			Error:  summary,
+		}); err != nil {
+			slog.Warn("failed to send secret detection notification", "prID", pr.ID, "error", err)
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrSecretDetected, summary)
+}
+
+// pushScope is the part of a push otto wrote: the diff from base to HEAD,
+// limited to paths when any are given.
+type pushScope struct {
+	base  string
+	paths []string
+}
+
+// branchScope is the scope of a regular push of pr: everything since the
+// remote tip of its branch.
+func branchScope(pr *PRDocument) pushScope {
+	return pushScope{base: "origin/" + strings.TrimPrefix(pr.Branch, "refs/heads/")}
+}
+
+// enforcePolicy checks scope of the commits about to be pushed for pr
+// against cfg.PR.Policy. A push that breaks the policy is recorded as a
+// hold on the PR, which stops further automated fixes; the held commit is
+// kept so "otto pr approve" can have exactly it pushed (see
+// pushApprovedHold).
+func enforcePolicy(ctx context.Context, pr *PRDocument, cfg *config.Config, workDir, detail string, scope pushScope) error {
+	if cfg == nil || !cfg.PR.Policy.Enabled() {
+		return nil
+	}
+
+	change, err := policy.Diff(ctx, workDir, scope.base, scope.paths...)
+	if err != nil {
+		// Fail closed: an unverifiable change is not pushed.
+		return fmt.Errorf("checking change policy: %w", err)
+	}
+	violations := policy.Check(cfg.PR.Policy, change)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	reasons := make([]string, len(violations))
+	for i, v := range violations {
+		reasons[i] = v.String()
+	}
+	hold := strings.Join(reasons, "; ")
+	slog.Warn("automated push held by change policy", "prID", pr.ID, "violations", hold)
+
+	head, err := gitOutput(ctx, workDir, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("resolving held commit: %w", err)
+	}
+	head = strings.TrimSpace(head)
+	tip, err := gitOutput(ctx, workDir, "rev-parse", branchScope(pr).base)
+	if err != nil {
+		return fmt.Errorf("resolving branch tip: %w", err)
+	}
+	// Pin the held commit: the worktree it was made in may be gone by the
+	// time it is approved.
+	if _, err := gitOutput(ctx, workDir, "update-ref", heldRef(pr), head); err != nil {
+		slog.Warn("failed to pin held commit", "prID", pr.ID, "commit", head, "error", err)
+	}
+	err = savePRChange(pr, func(p *PRDocument) {
+		p.PolicyHold = hold
+		p.PolicyHoldCommit = head
+		p.PolicyHoldBase = strings.TrimSpace(tip)
+		p.PolicyApproved = false
+	})
+	if err != nil {
+		slog.Error("failed to save policy hold", "prID", pr.ID, "error", err)
+	}
+	logPREvent(pr, PREvent{
+		Kind:       PREventPolicyHold,
+		Detail:     fmt.Sprintf("%s (%d files, +%d -%d)", detail, len(change.Files), change.Insertions, change.Deletions),
+		Commit:     shortCommit(head),
+		Violations: reasons,
+	})
+
+	audit.Log(audit.Entry{
+		Action:   audit.ActionPushBlocked,
+		Provider: pr.Provider,
+		PRID:     pr.ID,
+		PRURL:    pr.URL,
+		Branch:   pr.Branch,
+		Commit:   gitHeadShort(ctx, workDir),
+		Detail:   pr.PolicyHold,
+		Stats:    &audit.DiffStats{Files: len(change.Files), Insertions: change.Insertions, Deletions: change.Deletions},
+	})
+	if err := Notify(ctx, &cfg.Notifications, NotificationPayload{
+		Event:  EventPRNeedsApproval,
+		Title:  pr.Title,
+		URL:    pr.URL,
+		Status: "held",
+		Error:  pr.PolicyHold,
+	}); err != nil {
+		slog.Warn("failed to send policy hold notification", "prID", pr.ID, "error", err)
+	}
+
+	return fmt.Errorf("%w: %s", ErrPolicyHold, pr.PolicyHold)
+}
+
+// heldRef is the ref that keeps pr's held commit reachable until it is
+// pushed or dropped.
+func heldRef(pr *PRDocument) string {
+	return "refs/otto/held/" + pr.Provider + "/" + pr.ID
+}
+
+// pushApprovedHold pushes the commit held for pr once it has been approved,
+// from the primary checkout of pr's repo (see pushHeldCommit).
+func pushApprovedHold(ctx context.Context, pr *PRDocument, cfg *config.Config) error {
+	if !pr.PolicyApproved || pr.PolicyHoldCommit == "" {
+		return nil
+	}
+	rc, err := repo.NewManager("").FindByRemoteURL(cfg, pr.URL)
+	if err != nil {
+		return fmt.Errorf("finding repo for %s: %w", pr.URL, err)
+	}
+	return pushHeldCommit(ctx, pr, rc.PrimaryDir)
+}
+
+// pushHeldCommit pushes exactly pr's approved held commit from repoDir, and
+// only while the branch is still at the tip the commit was to replace;
+// anything otto or a human committed since is not part of the approval. A
+// branch that moved drops the held commit, and otto's next fix is checked
+// afresh.
+func pushHeldCommit(ctx context.Context, pr *PRDocument, repoDir string) error {
+	commit, base := pr.PolicyHoldCommit, pr.PolicyHoldBase
+	branch := strings.TrimPrefix(pr.Branch, "refs/heads/")
+	action := audit.ActionCommitPushed
+	if _, err := gitOutput(ctx, repoDir, "merge-base", "--is-ancestor", base, commit); err != nil {
+		action = audit.ActionForcePushed
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "push",
+		"--force-with-lease=refs/heads/"+branch+":"+base, "origin", commit+":refs/heads/"+branch)
+	cmd.Dir = repoDir
+	out, pushErr := cmd.CombinedOutput()
+
+	if _, err := gitOutput(ctx, repoDir, "update-ref", "-d", heldRef(pr)); err != nil {
+		slog.Warn("failed to unpin held commit", "prID", pr.ID, "error", err)
+	}
+	if err := savePRChange(pr, func(p *PRDocument) {
+		p.PolicyApproved = false
+		p.PolicyHoldCommit = ""
+		p.PolicyHoldBase = ""
+		if pushErr == nil {
+			// A head otto pushed isn't someone else's work (see human_push.go).
+			p.PushedCommit = commit
+			p.HumanActivity = ""
+		}
+	}); err != nil {
+		slog.Warn("failed to save pushed commit", "prID", pr.ID, "error", err)
+	}
+	if pushErr != nil {
+		return fmt.Errorf("pushing approved commit %s, dropped: %s: %w", shortCommit(commit), strings.TrimSpace(string(out)), pushErr)
+	}
+
+	slog.Info("pushed approved commit", "prID", pr.ID, "commit", shortCommit(commit))
+	audit.Log(audit.Entry{
+		Action:   action,
+		Provider: pr.Provider,
+		PRID:     pr.ID,
+		PRURL:    pr.URL,
+		Branch:   pr.Branch,
+		Commit:   shortCommit(commit),
+		Detail:   "approved held commit",
+	})
+	return nil
+}
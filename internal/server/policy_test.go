@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnforcePolicy(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	repoDir := newRepoWithRemote(t)
+	branch := strings.TrimSpace(gitT(t, repoDir, "rev-parse", "--abbrev-ref", "HEAD"))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "deploy", "prod"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "deploy", "prod", "values.yaml"), []byte("replicas: 3\n"), 0644))
+	gitT(t, repoDir, "add", "-A")
+	gitT(t, repoDir, "commit", "-q", "-m", "fix")
+
+	ctx := context.Background()
+	pr := &PRDocument{ID: "7", Provider: "ado", Branch: "refs/heads/" + branch, Status: "watching"}
+	require.NoError(t, SavePR(pr))
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.NoError(t, enforcePolicy(ctx, pr, &config.Config{}, repoDir, "fix", branchScope(pr)))
+		assert.Empty(t, pr.PolicyHold)
+	})
+
+	cfg := &config.Config{}
+	cfg.PR.Policy.ProtectedPaths = []string{"deploy/prod/**"}
+
+	t.Run("protected path holds push", func(t *testing.T) {
+		err := enforcePolicy(ctx, pr, cfg, repoDir, "fix", branchScope(pr))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrPolicyHold))
+		assert.Contains(t, pr.PolicyHold, "deploy/prod/values.yaml")
+		events, err := ReadPREvents("ado", "7")
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, PREventPolicyHold, events[0].Kind)
+		assert.Contains(t, RenderPRLog(pr, events), "otto pr approve 7")
+		assert.Equal(t, "approval", pr.ComputeWaitingOn())
+
+		saved, err := LoadPR("ado", "7")
+		require.NoError(t, err)
+		assert.Equal(t, pr.PolicyHold, saved.PolicyHold)
+	})
+
+	t.Run("scope limits the check", func(t *testing.T) {
+		scope := branchScope(pr)
+		scope.paths = []string{"src/resolved.go"}
+		assert.NoError(t, enforcePolicy(ctx, pr, cfg, repoDir, "rebase", scope))
+	})
+
+	head := strings.TrimSpace(gitT(t, repoDir, "rev-parse", "HEAD"))
+	t.Run("held commit is pinned", func(t *testing.T) {
+		assert.Equal(t, head, pr.PolicyHoldCommit)
+		assert.Equal(t, strings.TrimSpace(gitT(t, repoDir, "rev-parse", "origin/"+branch)), pr.PolicyHoldBase)
+		assert.Equal(t, head, strings.TrimSpace(gitT(t, repoDir, "rev-parse", heldRef(pr))))
+	})
+
+	t.Run("approval pushes exactly the held commit", func(t *testing.T) {
+		// Later work in the worktree is not part of the approval.
+		gitT(t, repoDir, "commit", "-q", "--allow-empty", "-m", "later")
+		pr.PolicyHold = ""
+		pr.PolicyApproved = true
+		require.NoError(t, pushHeldCommit(ctx, pr, repoDir))
+
+		remote := gitT(t, repoDir, "ls-remote", "origin", "refs/heads/"+branch)
+		assert.True(t, strings.HasPrefix(remote, head), remote)
+		assert.Equal(t, head, pr.PushedCommit)
+		assert.False(t, pr.PolicyApproved)
+		assert.Empty(t, pr.PolicyHoldCommit)
+		_, err := gitOutput(ctx, repoDir, "rev-parse", "--verify", heldRef(pr))
+		assert.Error(t, err, "held commit unpinned")
+	})
+
+	t.Run("moved branch drops the held commit", func(t *testing.T) {
+		gitT(t, repoDir, "fetch", "-q", "origin")
+		gitT(t, repoDir, "reset", "-q", "--hard", "origin/"+branch)
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, "deploy", "prod", "values.yaml"), []byte("replicas: 5\n"), 0644))
+		gitT(t, repoDir, "commit", "-q", "-am", "scale")
+		require.Error(t, enforcePolicy(ctx, pr, cfg, repoDir, "fix", branchScope(pr)))
+		held := pr.PolicyHoldCommit
+
+		gitT(t, repoDir, "commit", "-q", "--allow-empty", "-m", "human")
+		gitT(t, repoDir, "push", "-q", "origin", "HEAD:refs/heads/"+branch)
+		pr.PolicyApproved = true
+		require.Error(t, pushHeldCommit(ctx, pr, repoDir))
+		remote := gitT(t, repoDir, "ls-remote", "origin", "refs/heads/"+branch)
+		assert.False(t, strings.HasPrefix(remote, held))
+		assert.False(t, pr.PolicyApproved)
+		assert.Empty(t, pr.PolicyHoldCommit)
+	})
+}
+
+func TestScanPushForSecrets(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	repoDir := newRepoWithRemote(t)
+	branch := strings.TrimSpace(gitT(t, repoDir, "rev-parse", "--abbrev-ref", "HEAD"))
+	pr := &PRDocument{ID: "8", Provider: "ado", Branch: "refs/heads/" + branch}
+	ctx := context.Background()
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main\n"), 0644))
+	gitT(t, repoDir, "add", "-A")
+	gitT(t, repoDir, "commit", "-q", "-m", "clean")
+	assert.NoError(t, scanPushForSecrets(ctx, pr, &config.Config{}, repoDir, "fix", branchScope(pr)))
+
+	leak := "package main\n\nconst key = \"AKIA" + "IOSFODNN7EXAMPLE\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "main.go"), []byte(leak), 0644))
+	gitT(t, repoDir, "commit", "-q", "-am", "leak")
+	err := scanPushForSecrets(ctx, pr, &config.Config{}, repoDir, "fix", branchScope(pr))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrSecretDetected))
+	assert.Contains(t, err.Error(), "main.go:3: AWS access key ID")
+	assert.NotContains(t, err.Error(), "IOSFODNN7EXAMPLE")
+
+	// The conflicts an LLM-assisted rebase resolved are scanned before the
+	// force push; the rest of the rebase is not otto's change.
+	resolved := &pushScope{base: "origin/" + branch, paths: []string{"main.go"}}
+	err = pushAndAudit(ctx, pr, &config.Config{}, repoDir, "LLM-assisted rebase", resolved)
+	assert.True(t, errors.Is(err, ErrSecretDetected))
+	resolved.paths = []string{"README.md"}
+	assert.NoError(t, pushAndAudit(ctx, pr, &config.Config{}, repoDir, "LLM-assisted rebase", resolved))
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// SetPRPaused pauses or resumes monitoring of a tracked PR. A paused PR
+// keeps its state but is skipped by the monitor loop, so otto makes no
+// fixes, replies, or rebases on it until it is resumed.
+func SetPRPaused(id string, paused bool) (*PRDocument, error) {
+	pr, err := FindPR(id)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Paused == paused {
+		return pr, nil
+	}
+	pr, err = UpdatePR(pr.Provider, pr.ID, func(p *PRDocument) error {
+		p.Paused = paused
+		if !paused {
+			// Resuming takes the PR up again after a human push paused it.
+			p.HumanActivity = ""
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("PR monitoring paused state changed", "prID", pr.ID, "paused", paused)
+	if !paused {
+		TriggerPoll()
+	}
+	return pr, nil
+}
+
+// RequestFix asks the monitor loop to fix a PR's failing pipeline on its
+// next poll, which it triggers. A PR that has used up its fix attempts is
+// granted one more, and any infra retry or fix backoff is skipped, as is the
+// check that its failed builds haven't been attempted already.
+func RequestFix(id string) (*PRDocument, error) {
+	pr, err := FindPR(id)
+	if err != nil {
+		return nil, err
+	}
+	// The checks run on the document as saved under its lock, so a fix
+	// that starts meanwhile is not requested twice.
+	pr, err = UpdatePR(pr.Provider, pr.ID, func(p *PRDocument) error {
+		switch {
+		case p.Status == "merged" || p.Status == "abandoned":
+			return fmt.Errorf("PR %s is %s", p.ID, p.Status)
+		case p.Status == "fixing":
+			return fmt.Errorf("PR %s is already being fixed", p.ID)
+		case p.Paused:
+			return fmt.Errorf("PR %s is paused; resume it first", p.ID)
+		case p.PipelineState != "failed":
+			return fmt.Errorf("PR %s has no failing pipeline to fix", p.ID)
+		}
+
+		if p.FixAttempts >= p.MaxFixAttempts {
+			p.MaxFixAttempts = p.FixAttempts + 1
+		}
+		p.Status = "watching"
+		p.NextInfraRetry = ""
+		p.NextFixAttempt = ""
+		p.AttemptedBuilds = nil
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logPREvent(pr, PREvent{Kind: PREventFixRequested, MaxFixAttempts: pr.MaxFixAttempts})
+	slog.Info("fix requested", "prID", pr.ID, "fixAttempts", pr.FixAttempts, "maxFixAttempts", pr.MaxFixAttempts)
+	TriggerPoll()
+	return pr, nil
+}
@@ -5,21 +5,28 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
+	"reflect"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/alanmeadows/otto/internal/audit"
 	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/contextpack"
 	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/logcache"
 	"github.com/alanmeadows/otto/internal/prompts"
 	"github.com/alanmeadows/otto/internal/provider"
 	"github.com/alanmeadows/otto/internal/provider/ado"
-	ghbackend "github.com/alanmeadows/otto/internal/provider/github"
 	"github.com/alanmeadows/otto/internal/repo"
 	"github.com/alanmeadows/otto/internal/store"
+	"github.com/alanmeadows/otto/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // PRDocument represents a tracked pull request with its lifecycle state.
@@ -40,11 +47,81 @@ type PRDocument struct {
 	Body           string   `yaml:"-" json:"-"` // markdown body (fix history, etc.)
 
 	// Stage tracking fields — give visibility into what the PR is waiting on.
-	MerlinBotDone bool   `yaml:"merlinbot_done" json:"merlinbot_done"` // true once MerlinBot comments are addressed
-	FeedbackDone  bool   `yaml:"feedback_done" json:"feedback_done"`  // true once all review comments are resolved
-	PipelineState string `yaml:"pipeline_state" json:"pipeline_state"` // pending, running, succeeded, failed, unknown
-	HasConflicts  bool   `yaml:"has_conflicts" json:"has_conflicts"`  // true when ADO reports merge conflicts
-	WaitingOn     string `yaml:"waiting_on" json:"waiting_on"`     // human-readable: "merlinbot", "pipelines", "feedback", "all clear"
+	MerlinBotDone bool     `yaml:"merlinbot_done" json:"merlinbot_done"` // true once every bot reviewer's comments are addressed (see bots.go)
+	BotsDone      []string `yaml:"bots_done" json:"bots_done,omitempty"` // names of the bot handlers that have finished
+	FeedbackDone  bool     `yaml:"feedback_done" json:"feedback_done"`   // true once all review comments are resolved
+	PipelineState string   `yaml:"pipeline_state" json:"pipeline_state"` // pending, running, succeeded, failed, unknown
+	HasConflicts  bool     `yaml:"has_conflicts" json:"has_conflicts"`   // true when ADO reports merge conflicts
+	IsDraft       bool     `yaml:"draft" json:"draft,omitempty"`         // true while the PR is a draft
+	WaitingOn     string   `yaml:"waiting_on" json:"waiting_on"`         // human-readable: "bot reviews", "pipelines", "feedback", "all clear"
+
+	// Blocking branch policies (required reviewers, work item links, ...)
+	// that have not passed yet, as reported with the pipeline status.
+	PendingPolicies []string `yaml:"pending_policies" json:"pending_policies,omitempty"`
+
+	// Place in the target branch's merge queue (GitHub), e.g. "#2,
+	// awaiting checks", or "failed" when the queue's checks failed.
+	MergeQueue string `yaml:"merge_queue" json:"merge_queue,omitempty"`
+
+	// Summary of the risk analysis run when the PR was added or submitted
+	// (see risk.go); the full report is in the body.
+	Risk string `yaml:"risk" json:"risk,omitempty"`
+
+	// Work items (ADO) or issues (GitHub) linked to the PR.
+	WorkItems []string `yaml:"work_items" json:"work_items,omitempty"`
+
+	// Fix budgeting by failure category (see fix_budget.go).
+	FixAttemptsByCategory map[string]int `yaml:"fix_attempts_by_category" json:"fix_attempts_by_category,omitempty"`
+	InfraRetries          int            `yaml:"infra_retries" json:"infra_retries"`
+	NextInfraRetry        string         `yaml:"next_infra_retry" json:"next_infra_retry,omitempty"` // RFC3339; infra retries wait until then
+	// Signatures of the failures retried as infrastructure failures since
+	// the pipeline last passed (see classification.go). One that comes
+	// back is reclassified as a code failure.
+	InfraFailures []string `yaml:"infra_failures" json:"infra_failures,omitempty"`
+
+	// IDs of failed builds a pushed fix has already taken on (see
+	// fix_trigger.go). A failed pipeline is fixed again only once a build
+	// fails that isn't listed.
+	AttemptedBuilds []string `yaml:"attempted_builds" json:"attempted_builds,omitempty"`
+
+	// Fix backoff (pr.fix_backoff): fixes wait until NextFixAttempt
+	// (RFC3339). HeadCommit is the branch head seen by the last poll and
+	// PushedCommit the last commit otto pushed; a new head that otto
+	// didn't push means someone else is working on the branch.
+	NextFixAttempt string `yaml:"next_fix_attempt" json:"next_fix_attempt,omitempty"`
+	HeadCommit     string `yaml:"head_commit" json:"head_commit,omitempty"`
+	PushedCommit   string `yaml:"pushed_commit" json:"pushed_commit,omitempty"`
+
+	// HumanActivity describes the latest push by someone other than otto
+	// (see human_push.go), until otto pushes again or the hold it caused
+	// ends.
+	HumanActivity string `yaml:"human_activity" json:"human_activity,omitempty"`
+
+	// Model that served the most recent LLM step; differs from
+	// models.primary when the fallback chain kicked in.
+	LastModel string `yaml:"last_model" json:"last_model,omitempty"`
+
+	// Outcome of the repo's checks (repos[].checks) on the most recent fix.
+	LastChecks string `yaml:"last_checks" json:"last_checks,omitempty"`
+
+	// Change policy (pr.policy): PolicyHold describes a push that was held
+	// for breaking it, PolicyHoldCommit the held commit, and PolicyHoldBase
+	// the branch tip it was to replace. PolicyApproved has the daemon push
+	// exactly that commit.
+	PolicyHold       string `yaml:"policy_hold" json:"policy_hold,omitempty"`
+	PolicyHoldCommit string `yaml:"policy_hold_commit" json:"policy_hold_commit,omitempty"`
+	PolicyHoldBase   string `yaml:"policy_hold_base" json:"policy_hold_base,omitempty"`
+	PolicyApproved   bool   `yaml:"policy_approved" json:"policy_approved,omitempty"`
+
+	// Paused PRs are left out of polling until resumed (see pr_control.go).
+	Paused bool `yaml:"paused" json:"paused,omitempty"`
+
+	// Phase 1 diagnosis of the most recent build fix, for the dashboard.
+	LastDiagnosis string `yaml:"last_diagnosis" json:"-"`
+
+	// Latest step of an LLM session working on the PR right now (see
+	// activity.go); in memory only, so it is never saved.
+	Activity string `yaml:"-" json:"activity,omitempty"`
 }
 
 // ComputeWaitingOn derives the WaitingOn string from the stage tracking fields.
@@ -55,16 +132,31 @@ func (pr *PRDocument) ComputeWaitingOn() string {
 	if pr.Status == "abandoned" {
 		return "abandoned"
 	}
+	if pr.Paused {
+		if pr.HumanActivity != "" {
+			return "human activity detected"
+		}
+		return "paused"
+	}
 	if pr.Status == "failed" {
 		return "manual intervention"
 	}
 	if pr.Status == "fixing" {
 		return "fix in progress"
 	}
+	if pr.PolicyHold != "" {
+		return "approval"
+	}
 
 	var waiting []string
+	if pr.HumanActivity != "" {
+		waiting = append(waiting, "human activity detected")
+	}
+	if pr.IsDraft {
+		waiting = append(waiting, "draft")
+	}
 	if !pr.MerlinBotDone {
-		waiting = append(waiting, "merlinbot")
+		waiting = append(waiting, "bot reviews")
 	}
 	if !pr.FeedbackDone {
 		waiting = append(waiting, "feedback")
@@ -79,6 +171,12 @@ func (pr *PRDocument) ComputeWaitingOn() string {
 	if pr.HasConflicts {
 		waiting = append(waiting, "merge conflicts")
 	}
+	for _, p := range pr.PendingPolicies {
+		waiting = append(waiting, "branch policy: "+p)
+	}
+	if pr.MergeQueue != "" {
+		waiting = append(waiting, "merge queue ("+pr.MergeQueue+")")
+	}
 
 	if len(waiting) == 0 {
 		return "all clear"
@@ -88,16 +186,12 @@ func (pr *PRDocument) ComputeWaitingOn() string {
 
 // PRDir returns the global PR storage directory.
 func PRDir() string {
-	dataDir := os.Getenv("XDG_DATA_HOME")
-	if dataDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil || home == "" {
-			slog.Error("cannot determine home directory; set $HOME or $XDG_DATA_HOME", "error", err)
-			os.Exit(1)
-		}
-		dataDir = filepath.Join(home, ".local", "share")
+	dataDir, err := config.DataDir()
+	if err != nil {
+		slog.Error("cannot determine the otto data directory", "error", err)
+		os.Exit(1)
 	}
-	return filepath.Join(dataDir, "otto", "prs")
+	return filepath.Join(dataDir, "prs")
 }
 
 // prFilename generates a filename for a PR document.
@@ -112,7 +206,16 @@ func prPath(providerName, id string) string {
 
 // LoadPR loads a PR document from disk.
 func LoadPR(providerName, id string) (*PRDocument, error) {
-	path := prPath(providerName, id)
+	pr, err := readPRDocument(prPath(providerName, id))
+	if err != nil {
+		return nil, err
+	}
+	pr.Activity = currentActivity(providerName, id)
+	return pr, nil
+}
+
+// readPRDocument parses the PR document at path.
+func readPRDocument(path string) (*PRDocument, error) {
 	doc, err := store.ReadDocument(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading PR document: %w", err)
@@ -134,17 +237,115 @@ func LoadPR(providerName, id string) (*PRDocument, error) {
 	pr.LastChecked = store.GetString(doc.Frontmatter, "last_checked")
 	pr.FixAttempts = store.GetInt(doc.Frontmatter, "fix_attempts")
 	pr.MaxFixAttempts = store.GetInt(doc.Frontmatter, "max_fix_attempts")
+	pr.FixAttemptsByCategory = store.GetIntMap(doc.Frontmatter, "fix_attempts_by_category")
+	pr.InfraRetries = store.GetInt(doc.Frontmatter, "infra_retries")
+	pr.NextInfraRetry = store.GetString(doc.Frontmatter, "next_infra_retry")
+	pr.InfraFailures = store.GetStringSlice(doc.Frontmatter, "infra_failures")
+	pr.AttemptedBuilds = store.GetStringSlice(doc.Frontmatter, "attempted_builds")
+	pr.NextFixAttempt = store.GetString(doc.Frontmatter, "next_fix_attempt")
+	pr.HeadCommit = store.GetString(doc.Frontmatter, "head_commit")
+	pr.PushedCommit = store.GetString(doc.Frontmatter, "pushed_commit")
+	pr.HumanActivity = store.GetString(doc.Frontmatter, "human_activity")
+	pr.LastModel = store.GetString(doc.Frontmatter, "last_model")
+	pr.LastChecks = store.GetString(doc.Frontmatter, "last_checks")
+	pr.PolicyHold = store.GetString(doc.Frontmatter, "policy_hold")
+	pr.PolicyHoldCommit = store.GetString(doc.Frontmatter, "policy_hold_commit")
+	pr.PolicyHoldBase = store.GetString(doc.Frontmatter, "policy_hold_base")
+	pr.PolicyApproved = store.GetBool(doc.Frontmatter, "policy_approved")
+	pr.Paused = store.GetBool(doc.Frontmatter, "paused")
+	pr.LastDiagnosis = store.GetString(doc.Frontmatter, "last_diagnosis")
 	pr.SeenCommentIDs = store.GetStringSlice(doc.Frontmatter, "seen_comment_ids")
 	pr.MerlinBotDone = store.GetBool(doc.Frontmatter, "merlinbot_done")
+	pr.BotsDone = store.GetStringSlice(doc.Frontmatter, "bots_done")
 	pr.FeedbackDone = store.GetBool(doc.Frontmatter, "feedback_done")
 	pr.PipelineState = store.GetString(doc.Frontmatter, "pipeline_state")
+	pr.IsDraft = store.GetBool(doc.Frontmatter, "draft")
+	pr.PendingPolicies = store.GetStringSlice(doc.Frontmatter, "pending_policies")
+	pr.MergeQueue = store.GetString(doc.Frontmatter, "merge_queue")
+	pr.Risk = store.GetString(doc.Frontmatter, "risk")
+	pr.WorkItems = store.GetStringSlice(doc.Frontmatter, "work_items")
 	pr.WaitingOn = store.GetString(doc.Frontmatter, "waiting_on")
 
 	return pr, nil
 }
 
-// SavePR saves a PR document to disk.
+// SavePR writes pr to disk as-is, replacing any saved copy. It is for
+// documents built from scratch, such as a newly tracked PR; changes to a
+// tracked PR go through UpdatePR so concurrent writers don't lose each
+// other's updates.
 func SavePR(pr *PRDocument) error {
+	path := prPath(pr.Provider, pr.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating PR directory: %w", err)
+	}
+	var prevStatus string
+	existed := false
+	err := store.WithLock(path, store.DefaultLockTimeout, func() error {
+		if prev, err := store.ReadDocument(path); err == nil {
+			existed = true
+			prevStatus = store.GetString(prev.Frontmatter, "status")
+		}
+		return writePR(path, pr)
+	})
+	if err != nil {
+		return err
+	}
+	switch {
+	case !existed:
+		publishEvent(StreamPRAdded, pr, map[string]any{"status": pr.Status})
+	case prevStatus != pr.Status:
+		publishEvent(StreamPRStatusChanged, pr, map[string]any{"from": prevStatus, "to": pr.Status})
+		logPREvent(pr, PREvent{Kind: PREventStatus, From: prevStatus, To: pr.Status})
+	}
+	return nil
+}
+
+// UpdatePR loads the saved document of a tracked PR, applies fn to it, and
+// writes the result, holding the document's lock throughout so that no
+// other writer's change lands in between and is lost. Nothing is written
+// if fn returns an error, which UpdatePR returns as is. It returns the
+// document as saved.
+func UpdatePR(providerName, id string, fn func(*PRDocument) error) (*PRDocument, error) {
+	path := prPath(providerName, id)
+	var pr *PRDocument
+	var prevStatus string
+	err := store.WithLock(path, store.DefaultLockTimeout, func() error {
+		cur, err := LoadPR(providerName, id)
+		if err != nil {
+			return err
+		}
+		prevStatus = cur.Status
+		if err := fn(cur); err != nil {
+			return err
+		}
+		pr = cur
+		return writePR(path, cur)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if pr.Status != prevStatus {
+		publishEvent(StreamPRStatusChanged, pr, map[string]any{"from": prevStatus, "to": pr.Status})
+		logPREvent(pr, PREvent{Kind: PREventStatus, From: prevStatus, To: pr.Status})
+	}
+	return pr, nil
+}
+
+// savePRChange applies change to pr and, through UpdatePR, to its saved
+// document. Fields the change doesn't touch keep their saved values, so
+// updates other writers made since pr was loaded (a pause, an approval, a
+// comment evaluation) survive. change runs once on each copy.
+func savePRChange(pr *PRDocument, change func(*PRDocument)) error {
+	change(pr)
+	_, err := UpdatePR(pr.Provider, pr.ID, func(cur *PRDocument) error {
+		change(cur)
+		return nil
+	})
+	return err
+}
+
+// writePR writes pr to path. The caller holds the lock on path.
+func writePR(path string, pr *PRDocument) error {
 	pr.WaitingOn = pr.ComputeWaitingOn()
 
 	fm := map[string]any{
@@ -162,23 +363,44 @@ func SavePR(pr *PRDocument) error {
 		"max_fix_attempts": pr.MaxFixAttempts,
 		"seen_comment_ids": pr.SeenCommentIDs,
 		"merlinbot_done":   pr.MerlinBotDone,
+		"bots_done":        pr.BotsDone,
 		"feedback_done":    pr.FeedbackDone,
 		"pipeline_state":   pr.PipelineState,
+		"draft":            pr.IsDraft,
+		"pending_policies": pr.PendingPolicies,
+		"merge_queue":      pr.MergeQueue,
+		"risk":             pr.Risk,
+		"work_items":       pr.WorkItems,
 		"waiting_on":       pr.WaitingOn,
+
+		"fix_attempts_by_category": pr.FixAttemptsByCategory,
+		"infra_retries":            pr.InfraRetries,
+		"next_infra_retry":         pr.NextInfraRetry,
+		"infra_failures":           pr.InfraFailures,
+		"attempted_builds":         pr.AttemptedBuilds,
+		"next_fix_attempt":         pr.NextFixAttempt,
+		"head_commit":              pr.HeadCommit,
+		"pushed_commit":            pr.PushedCommit,
+		"human_activity":           pr.HumanActivity,
+
+		"last_model": pr.LastModel,
+
+		"last_checks": pr.LastChecks,
+
+		"policy_hold":        pr.PolicyHold,
+		"policy_hold_commit": pr.PolicyHoldCommit,
+		"policy_hold_base":   pr.PolicyHoldBase,
+		"policy_approved":    pr.PolicyApproved,
+
+		"paused":         pr.Paused,
+		"last_diagnosis": pr.LastDiagnosis,
 	}
 
 	doc := &store.Document{
 		Frontmatter: fm,
 		Body:        pr.Body,
 	}
-
-	path := prPath(pr.Provider, pr.ID)
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("creating PR directory: %w", err)
-	}
-	return store.WithLock(path, 5*time.Second, func() error {
-		return store.WriteDocument(path, doc)
-	})
+	return store.WriteDocument(path, doc)
 }
 
 // ListPRs returns all PR documents from the global PR directory.
@@ -213,15 +435,63 @@ func ListPRs() ([]*PRDocument, error) {
 	return prs, nil
 }
 
-// DeletePR removes a PR document from disk.
+// PRsByBranch returns the tracked PRs keyed by source branch, without the
+// "refs/heads/" prefix ADO reports branches with.
+func PRsByBranch() (map[string]*PRDocument, error) {
+	prs, err := ListPRs()
+	if err != nil {
+		return nil, err
+	}
+	byBranch := make(map[string]*PRDocument, len(prs))
+	for _, pr := range prs {
+		byBranch[strings.TrimPrefix(pr.Branch, "refs/heads/")] = pr
+	}
+	return byBranch, nil
+}
+
+// DeletePR removes a PR document, and its history, from disk.
 func DeletePR(providerName, id string) error {
 	path := prPath(providerName, id)
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("removing PR document: %w", err)
 	}
+	if err := os.Remove(prEventsPath(providerName, id)); err != nil && !os.IsNotExist(err) {
+		slog.Warn("failed to remove PR history", "prID", id, "error", err)
+	}
+	if err == nil {
+		publishEvent(StreamPRRemoved, &PRDocument{ID: id, Provider: providerName}, nil)
+	}
 	return nil
 }
 
+// NewTrackedPR returns the document for a PR otto starts tracking.
+func NewTrackedPR(info *provider.PRInfo, providerName string, cfg *config.Config) *PRDocument {
+	maxAttempts := 5
+	if cfg != nil && cfg.PR.MaxFixAttempts > 0 {
+		maxAttempts = cfg.PR.MaxFixAttempts
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	return &PRDocument{
+		ID:             info.ID,
+		Title:          info.Title,
+		Provider:       providerName,
+		Repo:           info.RepoID,
+		Branch:         info.SourceBranch,
+		Target:         info.TargetBranch,
+		Status:         "watching",
+		URL:            info.URL,
+		Created:        now,
+		LastChecked:    now,
+		MaxFixAttempts: maxAttempts,
+		PipelineState:  "pending",
+		IsDraft:        info.IsDraft,
+		WorkItems:      info.WorkItems,
+		Body:           fmt.Sprintf("# %s\n\n%s\n", info.Title, info.Description),
+	}
+}
+
 // FindPR finds a single PR by ID across all providers.
 // If multiple PRs match, returns an error.
 func FindPR(id string) (*PRDocument, error) {
@@ -247,10 +517,14 @@ func FindPR(id string) (*PRDocument, error) {
 	}
 }
 
-// PRDetailResponse wraps PRDocument with the body included in JSON.
+// PRDetailResponse wraps PRDocument with the body and latest diagnosis
+// included in JSON.
 type PRDetailResponse struct {
 	*PRDocument
-	Body string `json:"body"`
+	Body      string `json:"body"`
+	Diagnosis string `json:"diagnosis,omitempty"`
+	// Worktree is the local checkout of the PR's branch, if any.
+	Worktree *repo.Worktree `json:"worktree,omitempty"`
 }
 
 // FindPRDetail returns a PR by ID with body included for the dashboard detail view.
@@ -259,9 +533,23 @@ func FindPRDetail(id string) (*PRDetailResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &PRDetailResponse{PRDocument: pr, Body: pr.Body}, nil
+	return &PRDetailResponse{PRDocument: pr, Body: pr.Body, Diagnosis: pr.LastDiagnosis}, nil
+}
+
+// prWorktreeTimeout bounds reading a PR's local checkout for its status.
+const prWorktreeTimeout = 5 * time.Second
+
+// PRWorktree returns the local checkout of pr's branch in its configured
+// repo, with its git state, or nil when the branch isn't checked out.
+func PRWorktree(ctx context.Context, cfg *config.Config, pr *PRDocument) *repo.Worktree {
+	ctx, cancel := context.WithTimeout(ctx, prWorktreeTimeout)
+	defer cancel()
+	return repo.FindPRWorktree(ctx, cfg, pr.URL, pr.Branch)
 }
 
+// ErrMultiplePRs is returned by InferPR when more than one PR is tracked.
+var ErrMultiplePRs = errors.New("multiple PRs tracked, specify an ID")
+
 // InferPR returns the single tracked PR if only one exists, or errors with guidance.
 func InferPR() (*PRDocument, error) {
 	prs, err := ListPRs()
@@ -274,32 +562,170 @@ func InferPR() (*PRDocument, error) {
 	case 1:
 		return prs[0], nil
 	default:
-		return nil, fmt.Errorf("multiple PRs tracked, specify an ID")
+		return nil, ErrMultiplePRs
 	}
 }
 
+// retryInfraFailure queues fresh builds for a failure that is not the PR's
+// fault and schedules the next infra retry. The signatures of failures
+// classified as infrastructure are remembered, to catch them coming back.
+func retryInfraFailure(ctx context.Context, pr *PRDocument, backend provider.PRBackend, prInfo *provider.PRInfo, failedBuildIDs, failures []string, trigger string) error {
+	slog.Info("infrastructure failure detected, retrying builds instead of code fix", "prID", pr.ID, "builds", len(failedBuildIDs), "infraRetries", pr.InfraRetries, "trigger", trigger)
+
+	var retryErrors []string
+	for _, bid := range failedBuildIDs {
+		retryCtx, retrySpan := telemetry.Start(ctx, "provider.RetryBuild",
+			attribute.String("provider", backend.Name()),
+			attribute.String("build.id", bid),
+		)
+		err := backend.RetryBuild(retryCtx, prInfo, bid)
+		telemetry.End(retrySpan, err)
+		if err != nil {
+			slog.Warn("failed to retry build", "buildID", bid, "error", err)
+			retryErrors = append(retryErrors, fmt.Sprintf("build %s: %v", bid, err))
+		}
+	}
+
+	// Infrastructure retries do NOT count against fix attempts; they are
+	// unlimited but back off exponentially between rounds.
+	now := time.Now().UTC()
+	backoff := infraRetryBackoff(pr.InfraRetries)
+	err := savePRChange(pr, func(p *PRDocument) {
+		p.LastModel = pr.LastModel
+		p.LastDiagnosis = pr.LastDiagnosis
+		p.InfraRetries++
+		p.NextInfraRetry = now.Add(backoff).Format(time.RFC3339)
+		for _, sig := range failures {
+			if !slices.Contains(p.InfraFailures, sig) {
+				p.InfraFailures = append(p.InfraFailures, sig)
+			}
+		}
+		p.Status = "watching"
+		p.PipelineState = "inProgress"
+		p.LastChecked = now.Format(time.RFC3339)
+	})
+	if err != nil {
+		return fmt.Errorf("saving PR after infra retry: %w", err)
+	}
+	logPREvent(pr, PREvent{Kind: PREventInfraRetry, Detail: trigger, Builds: len(failedBuildIDs), NextRetry: pr.NextInfraRetry})
+
+	if len(retryErrors) > 0 {
+		return fmt.Errorf("some build retries failed: %s", strings.Join(retryErrors, "; "))
+	}
+	return nil
+}
+
+// diagnosisComment renders a FixPR Phase 1 diagnosis as a PR comment, with
+// the full analysis collapsed under a summary of what failed.
+func diagnosisComment(category FailureCategory, failedBuilds []string, diagnosis string) string {
+	var b strings.Builder
+	b.WriteString("**Otto build failure diagnosis**\n\n")
+	fmt.Fprintf(&b, "- **Classification**: %s\n", category)
+	if len(failedBuilds) > 0 {
+		fmt.Fprintf(&b, "- **Failed checks**: %s\n", strings.Join(failedBuilds, ", "))
+	}
+	fmt.Fprintf(&b, "\n<details>\n<summary>Diagnosis</summary>\n\n%s\n\n</details>", strings.TrimSpace(diagnosis))
+	return b.String()
+}
+
+// analyzeFailure is FixPR Phase 1: it asks the LLM for a diagnosis of the
+// failed builds' logs and classifies the failure.
+func analyzeFailure(ctx context.Context, pr *PRDocument, client llm.Client, cfg *config.Config, workDir, logs string, failedBuilds int) (string, FailureCategory, error) {
+	slog.Info("PR fix Phase 1: analyzing build logs", "prID", pr.ID)
+	analysisCtx, analysisSpan := telemetry.Start(ctx, "fix.analysis", attribute.Int("fix.failed_builds", failedBuilds))
+	analysisSession, err := client.CreateSession(analysisCtx, fmt.Sprintf("PR Fix Analysis #%s", pr.ID), workDir)
+	if err != nil {
+		telemetry.End(analysisSpan, err)
+		return "", "", fmt.Errorf("creating analysis session: %w", err)
+	}
+	defer client.DeleteSession(ctx, analysisSession.ID)
+
+	analysisPrompt, err := prompts.Execute("pr-fix-analysis.md", map[string]string{
+		"pr_id":        pr.ID,
+		"pr_title":     pr.Title,
+		"build_logs":   logs,
+		"repo_context": repoContext(ctx, cfg, pr.URL, workDir, contextpack.Request{Query: logs}),
+	})
+	if err != nil {
+		telemetry.End(analysisSpan, err)
+		return "", "", fmt.Errorf("building analysis prompt: %w", err)
+	}
+
+	analysisResp, err := client.SendPrompt(analysisCtx, analysisSession.ID, withRepoInstructions(analysisPrompt, workDir))
+	if err != nil {
+		telemetry.End(analysisSpan, err)
+		return "", "", fmt.Errorf("Phase 1 analysis failed: %w", err)
+	}
+
+	diagnosis := analysisResp.Content
+	recordModel(pr, analysisResp)
+	category := classifyFailure(diagnosis, logs)
+	analysisSpan.SetAttributes(
+		attribute.Bool("fix.infra_failure", category == CategoryInfra),
+		attribute.String("fix.category", string(category)),
+	)
+	telemetry.End(analysisSpan, nil)
+	slog.Info("PR fix Phase 1 complete", "diagnosisLength", len(diagnosis), "category", category)
+	return diagnosis, category, nil
+}
+
 // FixPR attempts to fix a failing PR using a two-phase LLM approach.
 // Phase 1: Analyze build logs to produce a structured diagnosis.
 // Phase 2: Apply fixes based on the diagnosis.
 func FixPR(ctx context.Context, pr *PRDocument, backend provider.PRBackend, client llm.Client, cfg *config.Config) (retErr error) {
-	// Guard the entire fix operation with a 15-minute deadline so a stuck LLM
-	// session cannot block the monitoring loop indefinitely.
-	ctx, cancel := context.WithTimeout(ctx, 15*time.Minute)
+	// Record the LLM sessions for `otto pr transcript`.
+	client = recordTranscript(client, pr)
+
+	// Guard the entire fix operation with a deadline (pr.fix_timeout) so a
+	// stuck LLM session cannot block the monitoring loop indefinitely.
+	// shutdown is cancelled only when the daemon itself stops.
+	shutdown := ctx
+	ctx, cancel := context.WithTimeout(ctx, cfg.PR.ParseFixTimeout())
 	defer cancel()
 
+	ctx, span := telemetry.Start(ctx, "otto.FixPR",
+		attribute.String("pr.id", pr.ID),
+		attribute.String("pr.provider", pr.Provider),
+		attribute.Int("fix.attempt", pr.FixAttempts+1),
+	)
+	defer func() { telemetry.End(span, retErr) }()
+
+	backend = audit.WrapBackend(logcache.WrapBackend(backend, logcache.New(cfg.PR.LogCacheBytes())))
+
+	// Serialize with other otto processes working on this branch. Taken
+	// before any state changes, so a busy branch costs no fix attempt.
+	unlock, err := lockBranch(ctx, pr, "fix")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	slog.Info("starting PR fix", "prID", pr.ID, "attempt", pr.FixAttempts+1)
 
 	// Set status to "fixing" to prevent concurrent fix attempts.
-	pr.Status = "fixing"
-	if err := SavePR(pr); err != nil {
+	if err := savePRChange(pr, func(p *PRDocument) { p.Status = "fixing" }); err != nil {
 		return fmt.Errorf("setting fix status: %w", err)
 	}
+	attempt := pr.FixAttempts + 1
+	publishEvent(StreamPRFixStarted, pr, map[string]any{"attempt": attempt})
+	defer func() {
+		data := map[string]any{"attempt": attempt, "ok": retErr == nil}
+		if retErr != nil {
+			data["error"] = retErr.Error()
+		}
+		publishEvent(StreamPRFixFinished, pr, data)
+	}()
 
 	// On error, roll status back to "watching" so the monitoring loop retries.
 	defer func() {
 		if retErr != nil && pr.Status == "fixing" {
-			pr.Status = "watching"
-			if saveErr := SavePR(pr); saveErr != nil {
+			saveErr := savePRChange(pr, func(p *PRDocument) {
+				p.Status = "watching"
+				p.LastModel = pr.LastModel
+				p.LastDiagnosis = pr.LastDiagnosis
+				p.LastChecks = pr.LastChecks
+			})
+			if saveErr != nil {
 				slog.Error("failed to rollback PR status from fixing", "prID", pr.ID, "error", saveErr)
 			}
 		}
@@ -313,6 +739,21 @@ func FixPR(ctx context.Context, pr *PRDocument, backend provider.PRBackend, clie
 	}
 	defer cleanup()
 
+	// A shutdown once the logs are analyzed checkpoints the diagnosis and
+	// the changes so far, so the next start resumes the fix rather than
+	// analyzing again. Deferred after cleanup, so it runs first.
+	var checkpoint *fixCheckpoint
+	defer func() {
+		if retErr == nil || checkpoint == nil || shutdown.Err() == nil {
+			return
+		}
+		if err := saveFixCheckpoint(checkpoint, workDir); err != nil {
+			slog.Error("failed to checkpoint interrupted fix", "prID", pr.ID, "error", err)
+			return
+		}
+		slog.Info("checkpointed interrupted fix for resumption", "prID", pr.ID, "attempt", checkpoint.Attempt)
+	}()
+
 	// Get pipeline status to find failed builds.
 	prInfo := &provider.PRInfo{
 		ID:           pr.ID,
@@ -322,167 +763,315 @@ func FixPR(ctx context.Context, pr *PRDocument, backend provider.PRBackend, clie
 		TargetBranch: pr.Target,
 	}
 
-	status, err := backend.GetPipelineStatus(ctx, prInfo)
+	statusCtx, statusSpan := telemetry.Start(ctx, "provider.GetPipelineStatus", attribute.String("provider", backend.Name()))
+	status, err := backend.GetPipelineStatus(statusCtx, prInfo)
+	telemetry.End(statusSpan, err)
 	if err != nil {
 		return fmt.Errorf("getting pipeline status: %w", err)
 	}
 
 	// Collect build logs from failed builds.
 	var logSummary strings.Builder
-	var failedBuildIDs []string
+	var failedBuildIDs, failedBuildNames []string
+	var failed []failedBuild
 	for _, build := range status.Builds {
 		slog.Info("build result", "prID", pr.ID, "buildName", build.Name, "buildID", build.ID, "result", build.Result)
-		switch build.Result {
-		case "failed", "failure", "partiallySucceeded", "canceled":
-			failedBuildIDs = append(failedBuildIDs, build.ID)
-		default:
+		if !buildFailed(build) {
 			continue
 		}
-		logs, err := backend.GetBuildLogs(ctx, prInfo, build.ID)
+		failedBuildIDs = append(failedBuildIDs, build.ID)
+		failedBuildNames = append(failedBuildNames, build.Name)
+		logsCtx, logsSpan := telemetry.Start(ctx, "provider.GetBuildLogs",
+			attribute.String("provider", backend.Name()),
+			attribute.String("build.id", build.ID),
+			attribute.String("build.name", build.Name),
+		)
+		logs, err := backend.GetBuildLogs(logsCtx, prInfo, build.ID)
+		telemetry.End(logsSpan, err)
 		if err != nil {
 			slog.Warn("failed to get build logs", "buildID", build.ID, "error", err)
 			continue
 		}
 		logSummary.WriteString(fmt.Sprintf("=== Build: %s ===\n%s\n\n", build.Name, logs))
+		failed = append(failed, failedBuild{ID: build.ID, Name: build.Name, Logs: logs})
 	}
 
 	if logSummary.Len() == 0 {
 		return fmt.Errorf("no failed build logs found to analyze")
 	}
 
-	// Phase 1: Analyze logs.
-	slog.Info("PR fix Phase 1: analyzing build logs", "prID", pr.ID)
-	analysisSession, err := client.CreateSession(ctx, fmt.Sprintf("PR Fix Analysis #%s", pr.ID), workDir)
-	if err != nil {
-		return fmt.Errorf("creating analysis session: %w", err)
+	// Failures that are all known flaky tests skip analysis and are retried
+	// straight away, up to pr.max_flaky_retries times in a row.
+	tests, otherFailures := failingTests(logSummary.String())
+	if len(tests) > 0 && !otherFailures && pr.InfraRetries < cfg.PR.MaxFlakyRetries {
+		known, err := knownFlakyTests(pr.Repo, tests)
+		if err != nil {
+			slog.Warn("failed to read flaky test database", "prID", pr.ID, "error", err)
+		}
+		if known != nil {
+			names := make([]string, len(known))
+			for i, t := range known {
+				names[i] = t.Test
+			}
+			slog.Info("failures match known flaky tests, retrying without analysis", "prID", pr.ID, "tests", names)
+			if err := noteFlakyRetry(pr.Repo, pr.ID, known); err != nil {
+				slog.Warn("failed to update flaky test database", "prID", pr.ID, "error", err)
+			}
+			return retryInfraFailure(ctx, pr, backend, prInfo, failedBuildIDs, nil, "Known flaky tests: "+strings.Join(names, ", "))
+		}
 	}
-	defer client.DeleteSession(ctx, analysisSession.ID)
-
-	analysisPrompt := fmt.Sprintf(`You are analyzing CI/CD build failure logs for PR #%s: "%s".
-
-## Classification (CRITICAL — must be the FIRST line of your response)
 
-Determine the root cause category and write EXACTLY one of these on the very first line:
-
-CLASSIFICATION: INFRASTRUCTURE
-CLASSIFICATION: CODE
-
-Use INFRASTRUCTURE when the failure is NOT caused by code in this PR, including:
-- Agent/pool unavailability, VM allocation failures, container image pull errors
-- Network timeouts, DNS resolution failures, service connection errors
-- "No agent found", "Job cancelled", resource quota exceeded
-- Transient test failures unrelated to PR changes (flaky tests)
-- Pipeline YAML parsing/configuration errors in shared templates
-- Artifact download failures, NuGet/npm registry errors
-- Any failure that would likely succeed on a simple retry
-
-Use CODE when the failure IS caused by code changes in this PR:
-- Compilation errors, syntax errors, type errors
-- Test failures caused by logic bugs in changed files
-- Linting/formatting violations in changed files
-- Missing imports, undefined variables, broken references
-
-## Analysis
-
-Then provide a structured failure summary:
-1. Which tests/checks failed
-2. The exact error messages
-3. File and line locations where errors originate
-4. Root cause analysis
-
-## Build Logs
-
-%s
-
-## Output
-
-Provide a concise, structured diagnosis that another LLM can use to fix the code (if CODE) or that explains the infra issue (if INFRASTRUCTURE). Focus on actionable information only.`, pr.ID, pr.Title, logSummary.String())
-
-	analysisResp, err := client.SendPrompt(ctx, analysisSession.ID, analysisPrompt)
-	if err != nil {
-		return fmt.Errorf("Phase 1 analysis failed: %w", err)
+	// Per-build mode gives each failed build an analysis and fix of its own.
+	if cfg.PR.FixPerBuild && len(failed) > 1 {
+		return fixEachBuild(ctx, pr, backend, client, cfg, prInfo, workDir, mergeBack, failed, status.Builds, failedBuildIDs)
 	}
 
-	diagnosis := analysisResp.Content
-	slog.Info("PR fix Phase 1 complete", "diagnosisLength", len(diagnosis))
+	// Phase 1: Analyze logs, unless a fix interrupted by a shutdown got
+	// that far on these same builds.
+	var diagnosis, signature string
+	var category FailureCategory
+	resumed := takeFixCheckpoint(pr, failedBuildIDs)
+	if resumed != nil {
+		slog.Info("resuming interrupted PR fix from its checkpoint", "prID", pr.ID, "category", resumed.Category, "savedAt", resumed.SavedAt)
+		diagnosis, category, signature = resumed.Diagnosis, resumed.Category, resumed.Signature
+		if resumed.Model != "" {
+			pr.LastModel = resumed.Model
+		}
+	} else {
+		diagnosis, category, err = analyzeFailure(ctx, pr, client, cfg, workDir, logSummary.String(), len(failedBuildIDs))
+		if err != nil {
+			return err
+		}
+		var reclassified bool
+		if category, signature, reclassified = recheckInfra(pr, category, failedBuildNames, logSummary.String()); reclassified {
+			diagnosis += reclassifiedNote
+		}
+		if cfg.PR.PostDiagnosisComments {
+			comment := diagnosisComment(category, failedBuildNames, diagnosis) + aiFooter(cfg)
+			if err := backend.PostComment(ctx, prInfo, comment); err != nil {
+				slog.Warn("failed to post diagnosis comment", "prID", pr.ID, "error", err)
+			}
+		}
+	}
+	checkpoint = &fixCheckpoint{
+		Provider:     pr.Provider,
+		PRID:         pr.ID,
+		Attempt:      pr.FixAttempts + 1,
+		FailedBuilds: failedBuildIDs,
+		Diagnosis:    diagnosis,
+		Category:     category,
+		Signature:    signature,
+		Model:        pr.LastModel,
+	}
+	pr.LastDiagnosis = diagnosis
 
 	// Check if the LLM classified this as an infrastructure failure.
-	if isInfraFailure(diagnosis) {
-		slog.Info("infrastructure failure detected, retrying builds instead of code fix", "prID", pr.ID, "builds", len(failedBuildIDs))
-
-		var retryErrors []string
-		for _, bid := range failedBuildIDs {
-			if err := backend.RetryBuild(ctx, prInfo, bid); err != nil {
-				slog.Warn("failed to retry build", "buildID", bid, "error", err)
-				retryErrors = append(retryErrors, fmt.Sprintf("build %s: %v", bid, err))
+	if category == CategoryInfra {
+		// Remember the failing tests so the next identical failure can skip
+		// analysis.
+		if len(tests) > 0 {
+			if err := recordFlakyTests(pr.Repo, pr.ID, tests); err != nil {
+				slog.Warn("failed to record flaky tests", "prID", pr.ID, "error", err)
 			}
 		}
+		// Checkpoints saved before signatures were recorded have none.
+		var failures []string
+		if signature != "" {
+			failures = []string{signature}
+		}
+		return retryInfraFailure(ctx, pr, backend, prInfo, failedBuildIDs, failures, "Infrastructure failure detected")
+	}
 
-		// Infrastructure retries do NOT count against fix attempts.
-		pr.Status = "watching"
-		pr.PipelineState = "inProgress"
-		pr.LastChecked = time.Now().UTC().Format(time.RFC3339)
-		pr.Body += fmt.Sprintf("\n\n### Infra Retry - %s\n- **Trigger**: Infrastructure failure detected\n- **Builds requeued**: %d\n",
-			pr.LastChecked, len(failedBuildIDs))
+	// Stop early if this category's fix budget is spent, rather than burning
+	// the remaining attempts on a kind of failure otto isn't fixing.
+	if exhausted, budget := categoryBudgetExhausted(pr, cfg, category); exhausted {
+		return failFixBudget(ctx, pr, backend, prInfo, cfg, category, budget)
+	}
 
-		if err := SavePR(pr); err != nil {
-			return fmt.Errorf("saving PR after infra retry: %w", err)
+	// Phase 2: Fix code, picking up the changes of an interrupted session.
+	var note string
+	if resumed != nil && resumed.Patch != "" {
+		if err := applyFixCheckpoint(ctx, resumed, workDir); err != nil {
+			slog.Warn("could not restore the interrupted fix's changes, fixing afresh", "prID", pr.ID, "error", err)
+		} else {
+			note = resumeNote
 		}
-
-		if len(retryErrors) > 0 {
-			return fmt.Errorf("some build retries failed: %s", strings.Join(retryErrors, "; "))
+	}
+	slog.Info("PR fix Phase 2: applying fixes", "prID", pr.ID, "category", category, "resumed", resumed != nil)
+	commitMsg := fmt.Sprintf("fix CI failures (attempt %d)", pr.FixAttempts+1)
+	commitHash, checkResults, err := applyFix(ctx, pr, client, cfg, workDir,
+		fmt.Sprintf("PR Fix #%s attempt %d", pr.ID, pr.FixAttempts+1), diagnosis, note, commitMsg)
+	if err != nil {
+		return err
+	}
+	if commitHash != "" {
+		if err := pushFix(ctx, pr, cfg, workDir, mergeBack, commitMsg, commitHash); err != nil {
+			return err
 		}
-		return nil
+		cancelSupersededBuilds(ctx, pr, backend, prInfo, status.Builds, commitHash)
+	} else {
+		// Nothing was pushed, so no new run will replace these builds.
+		failedBuildIDs = nil
 	}
 
-	// Phase 2: Fix code.
-	slog.Info("PR fix Phase 2: applying fixes", "prID", pr.ID)
-	fixSession, err := client.CreateSession(ctx, fmt.Sprintf("PR Fix #%s attempt %d", pr.ID, pr.FixAttempts+1), workDir)
+	return finishFixAttempt(ctx, pr, backend, prInfo, cfg, failedBuildIDs, []PREvent{fixAttemptEvent(pr, category, "", commitHash, checkResults)})
+}
+
+// failFixBudget marks pr failed because category's fix budget is spent.
+func failFixBudget(ctx context.Context, pr *PRDocument, backend provider.PRBackend, prInfo *provider.PRInfo, cfg *config.Config, category FailureCategory, budget int) error {
+	slog.Warn("fix budget exhausted for failure category", "prID", pr.ID, "category", category, "budget", budget)
+	now := time.Now().UTC().Format(time.RFC3339)
+	err := savePRChange(pr, func(p *PRDocument) {
+		p.Status = "failed"
+		p.LastChecked = now
+		p.LastModel = pr.LastModel
+		p.LastDiagnosis = pr.LastDiagnosis
+	})
+	logPREvent(pr, PREvent{Kind: PREventBudgetExhausted, Category: string(category), Budget: budget})
+	_ = backend.PostComment(ctx, prInfo, fmt.Sprintf("Exhausted the fix budget (%d) for %s failures on this PR. Manual intervention required.", budget, category)+aiFooter(cfg))
+	if err := Notify(ctx, &cfg.Notifications, NotificationPayload{
+		Event:       EventPRFailed,
+		Title:       pr.Title,
+		URL:         pr.URL,
+		Status:      "failed",
+		FixAttempts: pr.FixAttempts,
+		MaxAttempts: pr.MaxFixAttempts,
+		Error:       fmt.Sprintf("Exhausted %s fix budget", category),
+		Extra:       map[string]string{"Category": string(category)},
+	}); err != nil {
+		slog.Warn("failed to send PR failed notification", "prID", pr.ID, "error", err)
+	}
+	return err
+}
+
+// applyFix is FixPR Phase 2: a fix session named session applies the fix
+// for diagnosis in workDir (note is appended to the prompt), the repo's
+// checks validate it (giving the LLM another pass at anything they catch),
+// and it is committed with commitMsg unless checks still fail. It returns
+// the commit, empty when failing checks blocked it, and the checks'
+// results.
+func applyFix(ctx context.Context, pr *PRDocument, client llm.Client, cfg *config.Config, workDir, session, diagnosis, note, commitMsg string) (string, []repo.CheckResult, error) {
+	fixCtx, fixSpan := telemetry.Start(ctx, "fix.apply")
+	fixSession, err := client.CreateSession(fixCtx, session, workDir)
 	if err != nil {
-		return fmt.Errorf("creating fix session: %w", err)
+		telemetry.End(fixSpan, err)
+		return "", nil, fmt.Errorf("creating fix session: %w", err)
 	}
 	defer client.DeleteSession(ctx, fixSession.ID)
 
-	fixPrompt := fmt.Sprintf(`You are fixing CI/CD failures for PR #%s: "%s".
-
-## Failure Diagnosis
-
-%s
+	fixPrompt, err := prompts.Execute("pr-fix.md", map[string]string{
+		"pr_id":        pr.ID,
+		"pr_title":     pr.Title,
+		"diagnosis":    diagnosis,
+		"repo_context": repoContext(ctx, cfg, pr.URL, workDir, contextpack.Request{Query: diagnosis}),
+	})
+	if err != nil {
+		telemetry.End(fixSpan, err)
+		return "", nil, fmt.Errorf("building fix prompt: %w", err)
+	}
 
-## Instructions
+	fixResp, err := client.SendPrompt(fixCtx, fixSession.ID, withRepoInstructions(fixPrompt+note, workDir))
+	telemetry.End(fixSpan, err)
+	if err != nil {
+		return "", nil, fmt.Errorf("Phase 2 fix failed: %w", err)
+	}
+	recordModel(pr, fixResp)
 
-1. Read the relevant source files mentioned in the diagnosis
-2. Fix the identified issues
-3. Do NOT introduce unnecessary changes — fix only what's broken
-4. Make sure your fixes are correct and complete`, pr.ID, pr.Title, diagnosis)
+	var checkResults []repo.CheckResult
+	if checks := repoChecks(cfg, pr.URL); len(checks) > 0 {
+		checkResults, err = validateFix(ctx, pr, client, fixSession.ID, workDir, checks, cfg.PR.MaxValidationLoops)
+		if err != nil {
+			return "", nil, err
+		}
+		pr.LastChecks = repo.SummarizeChecks(checkResults)
+	}
 
-	_, err = client.SendPrompt(ctx, fixSession.ID, fixPrompt)
+	// Checks that still fail block the commit; the attempt still counts.
+	if len(repo.FailedChecks(checkResults)) > 0 {
+		slog.Warn("PR fix not committed: repo checks failing", "prID", pr.ID, "checks", pr.LastChecks)
+		return "", checkResults, nil
+	}
+	commitHash, err := gitCommit(ctx, cfg, workDir, commitMsg)
 	if err != nil {
-		return fmt.Errorf("Phase 2 fix failed: %w", err)
+		return "", nil, fmt.Errorf("committing fix: %w", err)
 	}
+	return commitHash, checkResults, nil
+}
 
-	// Commit and push.
-	commitMsg := fmt.Sprintf("fix CI failures (attempt %d)", pr.FixAttempts+1)
-	commitHash, err := gitCommitAndPush(ctx, workDir, pr.Branch, commitMsg)
-	if err != nil {
+// pushFix pushes the fix commits in workDir and merges them back to the
+// user's worktree.
+func pushFix(ctx context.Context, pr *PRDocument, cfg *config.Config, workDir string, mergeBack func() error, commitMsg, commitHash string) error {
+	if err := pushAndAudit(ctx, pr, cfg, workDir, commitMsg, nil); err != nil {
 		return fmt.Errorf("committing fix: %w", err)
 	}
-
 	slog.Info("PR fix committed and pushed", "prID", pr.ID, "commit", commitHash)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("git.commit", commitHash))
 
 	// Merge pushed changes back to the user's local worktree (best effort).
 	if err := mergeBack(); err != nil {
 		slog.Warn("failed to merge back to user worktree", "prID", pr.ID, "error", err)
 	}
+	return nil
+}
 
-	// Update PR document.
-	pr.FixAttempts++
-	pr.LastChecked = time.Now().UTC().Format(time.RFC3339)
-	pr.Body += fmt.Sprintf("\n\n### Attempt %d - %s\n- **Trigger**: Pipeline failure\n- **Commit**: %s\n",
-		pr.FixAttempts, pr.LastChecked, commitHash)
+// fixAttemptEvent describes a fix of a category of failure, in build when
+// the fix targeted one build.
+func fixAttemptEvent(pr *PRDocument, category FailureCategory, build, commitHash string, checkResults []repo.CheckResult) PREvent {
+	e := PREvent{Kind: PREventFixAttempt, Category: string(category), Build: build, Model: pr.LastModel, Commit: commitHash}
+	if len(checkResults) > 0 {
+		e.Checks = repo.SummarizeChecks(checkResults)
+	}
+	if commitHash == "" {
+		e.Output = repo.FormatCheckFailures(checkResults)
+	}
+	return e
+}
+
+// finishFixAttempt counts a fix attempt made of the fixes in events, one
+// per category fixed, and records them, listing buildIDs as attempted (see
+// fix_trigger.go). A PR out of attempts is marked failed, with a comment and
+// notification.
+func finishFixAttempt(ctx context.Context, pr *PRDocument, backend provider.PRBackend, prInfo *provider.PRInfo, cfg *config.Config, buildIDs []string, events []PREvent) error {
+	// The attempt is counted against the saved document, which a fix
+	// request may have granted more attempts.
+	now := time.Now().UTC().Format(time.RFC3339)
+	attempted := func(p *PRDocument) {
+		p.FixAttempts++
+		if p.FixAttemptsByCategory == nil {
+			p.FixAttemptsByCategory = make(map[string]int)
+		}
+		for _, e := range events {
+			p.FixAttemptsByCategory[e.Category]++
+		}
+		p.AttemptedBuilds = addAttemptedBuilds(p.AttemptedBuilds, buildIDs)
+		if delay := cfg.PR.FixBackoff.Delay(p.FixAttempts); delay > 0 {
+			p.NextFixAttempt = laterRFC3339(p.NextFixAttempt, time.Now().Add(delay))
+		}
+		p.LastChecked = now
+		p.LastModel = pr.LastModel
+		p.LastDiagnosis = pr.LastDiagnosis
+		p.LastChecks = pr.LastChecks
+		if p.FixAttempts >= p.MaxFixAttempts {
+			p.Status = "failed"
+		} else {
+			p.Status = "watching"
+		}
+	}
+	attempted(pr)
+	saved, err := UpdatePR(pr.Provider, pr.ID, func(p *PRDocument) error {
+		attempted(p)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("saving fix attempt: %w", err)
+	}
+	pr.Status, pr.MaxFixAttempts = saved.Status, saved.MaxFixAttempts
+	for _, e := range events {
+		e.Attempt = saved.FixAttempts
+		logPREvent(pr, e)
+	}
 
-	if pr.FixAttempts >= pr.MaxFixAttempts {
-		pr.Status = "failed"
+	if pr.Status == "failed" {
 		_ = backend.PostComment(ctx, prInfo, fmt.Sprintf("Exhausted %d fix attempts for this PR. Manual intervention required.", pr.MaxFixAttempts)+aiFooter(cfg))
 		// Notification ownership: FixPR is the sole owner of EventPRFailed notifications.
 		// pollSinglePR must NOT send duplicate failure notifications.
@@ -497,21 +1086,36 @@ Provide a concise, structured diagnosis that another LLM can use to fix the code
 		}); err != nil {
 			slog.Warn("failed to send PR failed notification", "prID", pr.ID, "error", err)
 		}
-	} else {
-		pr.Status = "watching"
 	}
-
-	return SavePR(pr)
+	return nil
 }
 
 // ResolveConflicts attempts to rebase the PR's source branch onto the target
 // branch to resolve merge conflicts. If the rebase encounters conflicts that
 // git cannot auto-resolve, it uses the LLM to manually resolve them.
-func ResolveConflicts(ctx context.Context, pr *PRDocument, backend provider.PRBackend, client llm.Client, cfg *config.Config) error {
-	// Guard with a 10-minute deadline so a stuck LLM session cannot block indefinitely.
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+func ResolveConflicts(ctx context.Context, pr *PRDocument, backend provider.PRBackend, client llm.Client, cfg *config.Config) (retErr error) {
+	// Record the LLM sessions for `otto pr transcript`.
+	client = recordTranscript(client, pr)
+
+	// Guard with a deadline (pr.conflict_timeout) so a stuck LLM session cannot block indefinitely.
+	ctx, cancel := context.WithTimeout(ctx, cfg.PR.ParseConflictTimeout())
 	defer cancel()
 
+	ctx, span := telemetry.Start(ctx, "otto.ResolveConflicts",
+		attribute.String("pr.id", pr.ID),
+		attribute.String("pr.provider", pr.Provider),
+		attribute.String("git.target", pr.Target),
+	)
+	defer func() { telemetry.End(span, retErr) }()
+
+	backend = audit.WrapBackend(backend)
+
+	unlock, err := lockBranch(ctx, pr, "conflict resolution")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	slog.Info("starting conflict resolution", "prID", pr.ID, "source", pr.Branch, "target", pr.Target)
 
 	workDir, cleanup, err := repo.MapPRToWorkDir(cfg, pr.URL, pr.Branch)
@@ -533,6 +1137,15 @@ func ResolveConflicts(ctx context.Context, pr *PRDocument, backend provider.PRBa
 	targetRef := pr.Target
 	targetRef = strings.TrimPrefix(targetRef, "refs/heads/")
 
+	// A branch that already contains the target has nothing to rebase.
+	upToDateCmd := exec.CommandContext(ctx, "git", "merge-base", "--is-ancestor", "origin/"+targetRef, "HEAD")
+	upToDateCmd.Dir = workDir
+	if upToDateCmd.Run() == nil {
+		slog.Info("branch already up to date with target, nothing to rebase", "prID", pr.ID, "target", targetRef)
+		pr.HasConflicts = false
+		return nil
+	}
+
 	// Capture what this branch changed relative to the target, so the LLM
 	// understands the intent of the branch when resolving conflicts.
 	branchSummaryCmd := exec.CommandContext(ctx, "git", "log", "--oneline",
@@ -549,22 +1162,24 @@ func ResolveConflicts(ctx context.Context, pr *PRDocument, backend provider.PRBa
 	branchDiffStat := strings.TrimSpace(string(branchDiffOut))
 
 	// Attempt a rebase onto the target branch.
-	rebaseCmd := exec.CommandContext(ctx, "git", "rebase", "origin/"+targetRef)
+	rebaseCtx, rebaseSpan := telemetry.Start(ctx, "git.rebase")
+	rebaseArgs := append(gitIdentityArgs(cfg), "rebase", "origin/"+targetRef)
+	rebaseCmd := exec.CommandContext(rebaseCtx, "git", rebaseArgs...)
 	rebaseCmd.Dir = workDir
 	rebaseOut, rebaseErr := rebaseCmd.CombinedOutput()
+	rebaseSpan.SetAttributes(attribute.Bool("git.rebase_clean", rebaseErr == nil))
+	rebaseSpan.End()
 
 	if rebaseErr == nil {
 		// Clean rebase — just push.
 		slog.Info("rebase succeeded cleanly, pushing", "prID", pr.ID)
-		pushCmd := exec.CommandContext(ctx, "git", "push", "--force-with-lease", "origin", "HEAD:"+pr.Branch)
-		pushCmd.Dir = workDir
-		if out, err := pushCmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("git push after rebase: %s: %w", string(out), err)
+		if err := pushAndAudit(ctx, pr, cfg, workDir, "rebase onto "+targetRef, &pushScope{base: "origin/" + targetRef}); err != nil {
+			return fmt.Errorf("git push after rebase: %w", err)
 		}
 
 		pr.HasConflicts = false
 		slog.Info("merge conflicts resolved via rebase", "prID", pr.ID)
-		return SavePR(pr)
+		return nil
 	}
 
 	// Rebase failed — there are conflicts git couldn't auto-resolve.
@@ -590,8 +1205,12 @@ func ResolveConflicts(ctx context.Context, pr *PRDocument, backend provider.PRBa
 		return fmt.Errorf("rebase failed but no conflicted files found")
 	}
 
-	resolveSession, err := client.CreateSession(ctx, fmt.Sprintf("Conflict Resolution #%s", pr.ID), workDir)
+	resolveCtx, resolveSpan := telemetry.Start(ctx, "conflicts.resolve",
+		attribute.Int("git.conflicted_files", len(strings.Split(conflictedFiles, "\n"))),
+	)
+	resolveSession, err := client.CreateSession(resolveCtx, fmt.Sprintf("Conflict Resolution #%s", pr.ID), workDir)
 	if err != nil {
+		telemetry.End(resolveSpan, err)
 		abortCmd := exec.CommandContext(ctx, "git", "rebase", "--abort")
 		abortCmd.Dir = workDir
 		_ = abortCmd.Run()
@@ -630,13 +1249,15 @@ These are the commits on this branch — they represent the intent and purpose o
 
 Do NOT introduce unnecessary changes beyond resolving the conflicts.`, pr.ID, pr.Title, pr.Branch, targetRef, branchContext, branchDiffStat, conflictedFiles)
 
-	_, err = client.SendPrompt(ctx, resolveSession.ID, resolvePrompt)
+	resolveResp, err := client.SendPrompt(resolveCtx, resolveSession.ID, withRepoInstructions(resolvePrompt, workDir))
+	telemetry.End(resolveSpan, err)
 	if err != nil {
 		abortCmd := exec.CommandContext(ctx, "git", "rebase", "--abort")
 		abortCmd.Dir = workDir
 		_ = abortCmd.Run()
 		return fmt.Errorf("LLM conflict resolution failed: %w", err)
 	}
+	recordModel(pr, resolveResp)
 
 	// Verify the rebase completed (no more REBASE_HEAD).
 	rebaseHeadCmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "REBASE_HEAD")
@@ -649,16 +1270,24 @@ Do NOT introduce unnecessary changes beyond resolving the conflicts.`, pr.ID, pr
 		return fmt.Errorf("LLM did not complete rebase, conflicts may be too complex")
 	}
 
-	// Push the rebased branch.
-	pushCmd := exec.CommandContext(ctx, "git", "push", "--force-with-lease", "origin", "HEAD:"+pr.Branch)
-	pushCmd.Dir = workDir
-	if out, err := pushCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git push after conflict resolution: %s: %w", string(out), err)
+	// Push the rebased branch. The conflicts the LLM resolved are otto's own
+	// edits and are checked like any other automated change.
+	resolved := &pushScope{base: "origin/" + targetRef, paths: strings.Split(conflictedFiles, "\n")}
+	if err := pushAndAudit(ctx, pr, cfg, workDir, "LLM-assisted rebase onto "+targetRef, resolved); err != nil {
+		return fmt.Errorf("git push after conflict resolution: %w", err)
 	}
 
 	pr.HasConflicts = false
 	slog.Info("merge conflicts resolved via LLM-assisted rebase", "prID", pr.ID)
-	return SavePR(pr)
+	return savePRChange(pr, func(p *PRDocument) { p.LastModel = pr.LastModel })
+}
+
+// recordModel notes which model served resp on pr, so fallbacks from the
+// primary model are visible in the PR document.
+func recordModel(pr *PRDocument, resp *llm.PromptResponse) {
+	if resp != nil && resp.Model != "" {
+		pr.LastModel = resp.Model
+	}
 }
 
 // isInfraFailure checks whether the Phase 1 diagnosis classifies the failure
@@ -708,9 +1337,13 @@ func isInfraFailure(diagnosis string) bool {
 	return false
 }
 
-// gitCommit stages all changes and commits locally without pushing.
+// gitCommit stages all changes and commits locally without pushing, as the
+// identity configured in cfg.Git.
 // Returns the short commit hash or an error if there are no changes.
-func gitCommit(ctx context.Context, workDir, message string) (string, error) {
+func gitCommit(ctx context.Context, cfg *config.Config, workDir, message string) (hash string, retErr error) {
+	ctx, span := telemetry.Start(ctx, "git.commit")
+	defer func() { telemetry.End(span, retErr) }()
+
 	// Check for changes.
 	statusCmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
 	statusCmd.Dir = workDir
@@ -730,7 +1363,8 @@ func gitCommit(ctx context.Context, workDir, message string) (string, error) {
 	}
 
 	// Commit.
-	commitCmd := exec.CommandContext(ctx, "git", "commit", "-m", message)
+	commitArgs := append(gitIdentityArgs(cfg), "commit", "-m", message)
+	commitCmd := exec.CommandContext(ctx, "git", commitArgs...)
 	commitCmd.Dir = workDir
 	if out, err := commitCmd.CombinedOutput(); err != nil {
 		return "", fmt.Errorf("git commit: %s: %w", string(out), err)
@@ -746,8 +1380,34 @@ func gitCommit(ctx context.Context, workDir, message string) (string, error) {
 	return strings.TrimSpace(string(hashOut))[:8], nil
 }
 
+// gitIdentityArgs returns the "git -c" options that make a commit or rebase
+// use the author identity and signing settings in cfg.Git.
+func gitIdentityArgs(cfg *config.Config) []string {
+	if cfg == nil {
+		return nil
+	}
+	g := cfg.Git
+	var args []string
+	if g.AuthorName != "" {
+		args = append(args, "-c", "user.name="+g.AuthorName)
+	}
+	if g.AuthorEmail != "" {
+		args = append(args, "-c", "user.email="+g.AuthorEmail)
+	}
+	if g.SigningKey != "" {
+		args = append(args, "-c", "commit.gpgsign=true", "-c", "user.signingkey="+g.SigningKey)
+		if g.SigningFormat != "" {
+			args = append(args, "-c", "gpg.format="+g.SigningFormat)
+		}
+	}
+	return args
+}
+
 // gitPush pushes local commits to the remote branch.
-func gitPush(ctx context.Context, workDir, branch string) error {
+func gitPush(ctx context.Context, workDir, branch string) (retErr error) {
+	ctx, span := telemetry.Start(ctx, "git.push", attribute.String("git.branch", branch))
+	defer func() { telemetry.End(span, retErr) }()
+
 	shortBranch := strings.TrimPrefix(branch, "refs/heads/")
 	refspec := fmt.Sprintf("HEAD:refs/heads/%s", shortBranch)
 	pushCmd := exec.CommandContext(ctx, "git", "push", "origin", refspec)
@@ -758,16 +1418,101 @@ func gitPush(ctx context.Context, workDir, branch string) error {
 	return nil
 }
 
-// gitCommitAndPush stages all changes, commits, and pushes to the given branch.
-func gitCommitAndPush(ctx context.Context, workDir, branch, message string) (string, error) {
-	hash, err := gitCommit(ctx, workDir, message)
+// gitForcePush pushes a rewritten (rebased) branch with --force-with-lease.
+func gitForcePush(ctx context.Context, workDir, branch string) (retErr error) {
+	ctx, span := telemetry.Start(ctx, "git.push",
+		attribute.String("git.branch", branch),
+		attribute.Bool("git.force", true),
+	)
+	defer func() { telemetry.End(span, retErr) }()
+
+	pushCmd := exec.CommandContext(ctx, "git", "push", "--force-with-lease", "origin", "HEAD:"+branch)
+	pushCmd.Dir = workDir
+	if out, err := pushCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w", string(out), err)
+	}
+	return nil
+}
+
+// pushAndAudit pushes the PR branch and records the push, with diff stats
+// relative to the previous remote tip, in the audit log. Stats are captured
+// before pushing since the push moves the remote-tracking ref. rebase is nil
+// for regular pushes; for a rebased branch, force-pushed with lease, it is
+// the part of the rebase otto wrote.
+func pushAndAudit(ctx context.Context, pr *PRDocument, cfg *config.Config, workDir, detail string, rebase *pushScope) error {
+	// A rebase rewrites history onto the target branch, so its diff against
+	// the old tip is not otto's own change; only the conflicts it resolved
+	// are, and a clean rebase has none.
+	scope, check := branchScope(pr), true
+	if rebase != nil {
+		scope, check = *rebase, len(rebase.paths) > 0
+	}
+	if check {
+		if err := scanPushForSecrets(ctx, pr, cfg, workDir, detail, scope); err != nil {
+			return err
+		}
+		if err := enforcePolicy(ctx, pr, cfg, workDir, detail, scope); err != nil {
+			return err
+		}
+	}
+
+	stats := diffStatAgainstRemote(ctx, workDir, pr.Branch)
+
+	var err error
+	action := audit.ActionCommitPushed
+	if rebase != nil {
+		action = audit.ActionForcePushed
+		err = gitForcePush(ctx, workDir, pr.Branch)
+	} else {
+		err = gitPush(ctx, workDir, pr.Branch)
+	}
 	if err != nil {
-		return "", err
+		return err
+	}
+	// A head otto pushed isn't someone else's work (see human_push.go).
+	head, _ := gitOutput(ctx, workDir, "rev-parse", "HEAD")
+	if err := savePRChange(pr, func(p *PRDocument) {
+		p.PushedCommit = strings.TrimSpace(head)
+		p.HumanActivity = ""
+	}); err != nil {
+		slog.Warn("failed to save pushed commit", "prID", pr.ID, "error", err)
+	}
+
+	audit.Log(audit.Entry{
+		Action:   action,
+		Provider: pr.Provider,
+		PRID:     pr.ID,
+		PRURL:    pr.URL,
+		Branch:   pr.Branch,
+		Commit:   gitHeadShort(ctx, workDir),
+		Detail:   detail,
+		Stats:    stats,
+	})
+	return nil
+}
+
+// diffStatAgainstRemote returns the diff stats between origin/<branch> and
+// HEAD, or nil if they cannot be determined.
+func diffStatAgainstRemote(ctx context.Context, workDir, branch string) *audit.DiffStats {
+	shortBranch := strings.TrimPrefix(branch, "refs/heads/")
+	cmd := exec.CommandContext(ctx, "git", "diff", "--shortstat", "origin/"+shortBranch, "HEAD")
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
 	}
-	if err := gitPush(ctx, workDir, branch); err != nil {
-		return "", err
+	return audit.ParseShortStat(string(out))
+}
+
+// gitHeadShort returns the abbreviated HEAD commit hash, or "" on error.
+func gitHeadShort(ctx context.Context, workDir string) string {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--short=8", "HEAD")
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
 	}
-	return hash, nil
+	return strings.TrimSpace(string(out))
 }
 
 // RunMonitorLoop runs the PR monitoring loop that polls for PR status changes.
@@ -776,53 +1521,96 @@ func RunMonitorLoop(ctx context.Context, cfg *config.Config, client llm.Client)
 	pollInterval := cfg.Server.ParsePollInterval()
 	slog.Info("starting PR monitoring loop", "interval", pollInterval)
 
-	// Build provider registry from config.
-	reg := buildMonitorRegistry(cfg)
-
-	// Reset any PRs stuck in "fixing" from a previous crash/restart.
-	resetStuckPRs()
+	// Build provider registry from config, recording its traffic per poll
+	// cycle if server.record_cycles is set.
+	var recorder *cycleRecorder
+	var wrap func(http.RoundTripper) http.RoundTripper
+	if cfg.Server.RecordCycles > 0 {
+		recorder = newCycleRecorder(cfg.Server.RecordCycles)
+		wrap = recorder.rec.Wrap
+	}
+	reg := buildRegistry(cfg, wrap, false)
+	poll := func() {
+		recorder.start()
+		defer recorder.stop()
+		pollAllPRs(ctx, reg, client, cfg)
+		lastPollTime.Store(time.Now().UnixNano())
+	}
 
-	// Run immediately on startup, then on ticker.
-	pollAllPRs(ctx, reg, client, cfg)
+	// Check credentials, then poll immediately on startup, then on ticker.
+	authState.check(ctx, reg, cfg)
+	poll()
 
 	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
+	authTicker := time.NewTicker(cfg.Server.AuthCheckIntervalDuration())
+	defer authTicker.Stop()
+	scheduleTicker := time.NewTicker(scheduleCheckInterval)
+	defer scheduleTicker.Stop()
+
+	// Config changes are applied here, between polls, so no poll sees a
+	// half-updated config. The caller stops the client it passed in; a
+	// client started for new models is stopped here.
+	go watchConfig(ctx, configWatchInterval)
+	initialClient := client
+	defer func() {
+		if client != initialClient {
+			stopLLMClient(client)
+		}
+	}()
 
 	for {
 		select {
 		case <-ctx.Done():
 			slog.Info("monitoring loop stopped")
 			return nil
+		case <-configReload:
+			next, err := reloadConfig(cfg, config.Load)
+			if err != nil {
+				slog.Error("config reload failed, keeping the current config", "error", err)
+				continue
+			}
+			if !reflect.DeepEqual(next.Models, cfg.Models) {
+				newClient, err := startLLMClient(ctx, next.Models, cfg.Dashboard.CopilotServer)
+				if err != nil {
+					slog.Error("LLM client unavailable for the new models, keeping the current ones", "error", err)
+					next.Models = cfg.Models
+				} else {
+					if client != initialClient {
+						stopLLMClient(client)
+					}
+					client = newClient
+				}
+			}
+			rebuild := !reflect.DeepEqual(next.PR.Providers, cfg.PR.Providers) || !reflect.DeepEqual(next.PR.RateLimits, cfg.PR.RateLimits)
+			cfg = next
+			liveConfig.Store(cfg)
+			if rebuild {
+				reg = buildRegistry(cfg, wrap, false)
+				authState.check(ctx, reg, cfg)
+			}
+			if d := cfg.Server.ParsePollInterval(); d != pollInterval {
+				pollInterval = d
+				ticker.Reset(pollInterval)
+			}
+			authTicker.Reset(cfg.Server.AuthCheckIntervalDuration())
+			store.DefaultLockTimeout = cfg.Server.LockTimeoutDuration()
+			slog.Info("config reloaded", "poll_interval", pollInterval, "model", cfg.Models.Primary, "providersChanged", rebuild)
+		case <-authTicker.C:
+			authState.check(ctx, reg, cfg)
+		case now := <-scheduleTicker.C:
+			runDueTasks(ctx, reg, client, cfg, now)
 		case <-ticker.C:
-			pollAllPRs(ctx, reg, client, cfg)
+			poll()
 		case <-pollTrigger:
 			slog.Info("immediate poll triggered")
-			pollAllPRs(ctx, reg, client, cfg)
+			poll()
 			// Reset ticker so we don't poll again too soon.
 			ticker.Reset(pollInterval)
 		}
 	}
 }
 
-// resetStuckPRs resets any PRs left in "fixing" status from a previous
-// server crash or restart back to "watching" so the monitor loop picks them up.
-func resetStuckPRs() {
-	prs, err := ListPRs()
-	if err != nil {
-		slog.Warn("failed to list PRs for stuck-state reset", "error", err)
-		return
-	}
-	for _, pr := range prs {
-		if pr.Status == "fixing" {
-			slog.Info("resetting stuck PR from fixing to watching", "prID", pr.ID, "title", pr.Title)
-			pr.Status = "watching"
-			if err := SavePR(pr); err != nil {
-				slog.Error("failed to reset stuck PR", "prID", pr.ID, "error", err)
-			}
-		}
-	}
-}
-
 // reapTerminalPRs removes PRs in terminal states (merged, abandoned) whose
 // last_checked timestamp is more than 24 hours ago. This keeps the PR list
 // tidy without immediately losing visibility after a merge/abandon.
@@ -845,6 +1633,9 @@ func reapTerminalPRs(prs []*PRDocument) {
 			}
 			if now.Sub(t) >= reapAge {
 				slog.Info("reaping terminal PR", "prID", pr.ID, "title", pr.Title, "status", pr.Status, "age", now.Sub(t).Round(time.Minute))
+				if err := archivePR(pr.Provider, pr.ID); err != nil {
+					slog.Warn("failed to archive reaped PR for reports", "prID", pr.ID, "error", err)
+				}
 				if err := DeletePR(pr.Provider, pr.ID); err != nil {
 					slog.Error("failed to reap PR", "prID", pr.ID, "error", err)
 				}
@@ -853,25 +1644,29 @@ func reapTerminalPRs(prs []*PRDocument) {
 	}
 }
 
-// buildMonitorRegistry creates a provider registry from config (server-side).
-func buildMonitorRegistry(cfg *config.Config) *provider.Registry {
-	reg := provider.NewRegistry()
-	if cfg.PR.Providers != nil {
-		if adoCfg, ok := cfg.PR.Providers["ado"]; ok {
-			auth := ado.NewAuthProvider(adoCfg.PAT)
-			adoBackend := ado.NewBackend(adoCfg.Organization, adoCfg.Project, auth)
-			reg.Register(adoBackend)
+// pollAllPRs processes all tracked PRs in a single poll cycle.
+func pollAllPRs(ctx context.Context, reg *provider.Registry, client llm.Client, cfg *config.Config) {
+	// Pick up new PRs matched by pr.watch rules before listing.
+	if len(cfg.PR.Watch) > 0 {
+		if _, err := discoverWatchedPRs(ctx, reg, cfg); err != nil {
+			slog.Error("authentication expired while discovering watched PRs. Run 'az login' to refresh", "error", err)
 		}
-		if ghCfg, ok := cfg.PR.Providers["github"]; ok {
-			ghBack := ghbackend.NewBackend("", "", ghCfg.Token)
-			reg.Register(ghBack)
+	}
+
+	// Review other people's PRs matched by pr.reviewer.watch rules.
+	if cfg.PR.Reviewer.Enabled {
+		if _, err := reviewIncomingPRs(ctx, reg, client, cfg); err != nil {
+			slog.Error("failed to review incoming PRs", "error", err)
+		}
+	}
+
+	// Triage issues opened in the repositories of issues.watch rules.
+	if cfg.Issues.Enabled {
+		if _, err := triageIssues(ctx, reg, client, cfg); err != nil {
+			slog.Error("failed to triage issues", "error", err)
 		}
 	}
-	return reg
-}
 
-// pollAllPRs processes all tracked PRs in a single poll cycle.
-func pollAllPRs(ctx context.Context, reg *provider.Registry, client llm.Client, cfg *config.Config) {
 	prs, err := ListPRs()
 	if err != nil {
 		slog.Error("failed to list PRs", "error", err)
@@ -880,8 +1675,20 @@ func pollAllPRs(ctx context.Context, reg *provider.Registry, client llm.Client,
 
 	// Reap terminal PRs (merged/abandoned) older than 24 hours.
 	reapTerminalPRs(prs)
+	pruneArchivedPRs(time.Now())
+
+	// Drop pooled worktrees for branches otto has stopped working on.
+	if cfg.PR.WorktreePool.Enabled {
+		if err := repo.NewPool(cfg.PR.WorktreePool).Prune(); err != nil {
+			slog.Warn("failed to prune worktree pool", "error", err)
+		}
+	}
+
+	// Index what has landed in the repos' primary checkouts.
+	updateSearchIndexes(ctx, cfg)
 
 	watchCount := 0
+	paused := make(map[string]int)
 	for _, pr := range prs {
 		// Bail early if the server is shutting down.
 		if ctx.Err() != nil {
@@ -892,18 +1699,32 @@ func pollAllPRs(ctx context.Context, reg *provider.Registry, client llm.Client,
 		case "merged", "abandoned", "fixing":
 			continue
 		}
+		if pr.Paused {
+			slog.Debug("skipping paused PR", "prID", pr.ID)
+			continue
+		}
+		// Leave a provider's PRs alone while its credentials are rejected.
+		if _, ok := authState.paused(pr.Provider); ok {
+			paused[pr.Provider]++
+			continue
+		}
 		watchCount++
 
 		slog.Info("polling PR", "prID", pr.ID, "title", pr.Title, "status", pr.Status, "waitingOn", pr.ComputeWaitingOn())
 		if err := pollSinglePR(ctx, pr, reg, client, cfg); err != nil {
-			// If auth is broken, skip remaining PRs — they'll all fail the same way.
+			// If auth is broken, pause the provider — its remaining PRs
+			// would all fail the same way.
 			if errors.Is(err, ado.ErrAuthExpired) {
-				slog.Error("authentication expired, skipping remaining PRs. Run 'az login' to refresh", "prID", pr.ID)
-				break
+				authState.fail(ctx, cfg, pr.Provider, err)
+				continue
 			}
 			slog.Error("failed to poll PR", "prID", pr.ID, "error", err)
 		}
 	}
+	for name, n := range paused {
+		reason, _ := authState.paused(name)
+		slog.Warn("polling paused, provider credentials rejected", "provider", name, "skippedPRs", n, "error", reason)
+	}
 
 	if watchCount == 0 {
 		slog.Debug("no active PRs to poll", "total", len(prs))
@@ -923,6 +1744,35 @@ func pollSinglePR(ctx context.Context, pr *PRDocument, reg *provider.Registry, c
 	if err != nil {
 		return fmt.Errorf("getting backend for %s: %w", pr.Provider, err)
 	}
+	backend = audit.WrapBackend(logcache.WrapBackend(backend, logcache.New(cfg.PR.LogCacheBytes())))
+
+	ctx, untrack := trackActivity(ctx, pr.Provider, pr.ID)
+	defer untrack()
+
+	// saveChanges saves the fields the poll keeps up to date. Everything
+	// else is left as saved: FixPR, evaluateComment, and the CLI save their
+	// own changes, some of them while this poll runs.
+	saveChanges := func() error {
+		return savePRChange(pr, func(p *PRDocument) {
+			p.Title = pr.Title
+			p.IsDraft = pr.IsDraft
+			p.Status = pr.Status
+			p.PipelineState = pr.PipelineState
+			p.PendingPolicies = pr.PendingPolicies
+			p.MergeQueue = pr.MergeQueue
+			p.InfraRetries = pr.InfraRetries
+			p.NextInfraRetry = pr.NextInfraRetry
+			p.InfraFailures = pr.InfraFailures
+			p.AttemptedBuilds = pr.AttemptedBuilds
+			p.NextFixAttempt = pr.NextFixAttempt
+			p.HeadCommit = pr.HeadCommit
+			p.HumanActivity = pr.HumanActivity
+			p.MerlinBotDone = pr.MerlinBotDone
+			p.BotsDone = pr.BotsDone
+			p.FeedbackDone = pr.FeedbackDone
+			p.LastChecked = pr.LastChecked
+		})
+	}
 
 	prInfo := &provider.PRInfo{
 		ID:           pr.ID,
@@ -945,29 +1795,58 @@ func pollSinglePR(ctx context.Context, pr *PRDocument, reg *provider.Registry, c
 			slog.Info("PR title updated", "prID", pr.ID, "old", pr.Title, "new", latestPR.Title)
 			pr.Title = latestPR.Title
 		}
+		if latestPR.IsDraft != pr.IsDraft {
+			slog.Info("PR draft state changed", "prID", pr.ID, "draft", latestPR.IsDraft)
+			pr.IsDraft = latestPR.IsDraft
+		}
+		if latestPR.HeadCommit != "" && latestPR.HeadCommit != pr.HeadCommit {
+			if pr.HeadCommit != "" {
+				if author, human := humanPush(ctx, pr, backend, prInfo, cfg, latestPR.HeadCommit); human {
+					if err := handleHumanPush(pr, cfg, latestPR.HeadCommit, author, time.Now()); err != nil {
+						slog.Error("failed to save human activity", "prID", pr.ID, "error", err)
+					}
+				}
+			}
+			pr.HeadCommit = latestPR.HeadCommit
+			if pr.Paused {
+				pr.LastChecked = time.Now().UTC().Format(time.RFC3339)
+				return saveChanges()
+			}
+		} else if pr.HumanActivity != "" && !fixBackoffPending(pr, time.Now()) {
+			pr.HumanActivity = ""
+		}
 
 		switch latestPR.Status {
 		case "completed":
 			slog.Info("PR has been merged", "prID", pr.ID)
 			pr.Status = "merged"
+			transitionJiraIssues(ctx, cfg, pr, cfg.Jira.DoneStatus)
 			pr.LastChecked = time.Now().UTC().Format(time.RFC3339)
-			return SavePR(pr)
+			cleanupClosedPR(ctx, pr, latestPR, cfg, client)
+			return saveChanges()
 		case "abandoned":
 			slog.Info("PR has been abandoned", "prID", pr.ID)
 			pr.Status = "abandoned"
 			pr.LastChecked = time.Now().UTC().Format(time.RFC3339)
-			return SavePR(pr)
+			cleanupClosedPR(ctx, pr, latestPR, cfg, client)
+			return saveChanges()
 		}
 
 		// Check for merge conflicts.
 		if latestPR.MergeStatus == "conflicts" {
-			if !pr.HasConflicts {
+			if draftHold(pr, cfg) {
+				slog.Info("PR has merge conflicts, but is a draft; leaving them", "prID", pr.ID)
+			} else if !pr.HasConflicts {
 				slog.Warn("PR has merge conflicts, attempting rebase", "prID", pr.ID)
 				pr.HasConflicts = true
-				if err := SavePR(pr); err != nil {
+				if err := saveChanges(); err != nil {
 					slog.Error("failed to save conflict state", "prID", pr.ID, "error", err)
 				}
-				if err := ResolveConflicts(ctx, pr, backend, client, cfg); err != nil {
+				if err := ResolveConflicts(ctx, pr, backend, client, cfg); errors.Is(err, ErrBranchBusy) {
+					// Not attempted; try again next poll.
+					slog.Info("branch busy, deferring conflict resolution", "prID", pr.ID, "error", err)
+					pr.HasConflicts = false
+				} else if err != nil {
 					slog.Error("failed to resolve merge conflicts", "prID", pr.ID, "error", err)
 				}
 			} else {
@@ -981,6 +1860,11 @@ func pollSinglePR(ctx context.Context, pr *PRDocument, reg *provider.Registry, c
 		}
 	}
 
+	// A held commit approved since the last poll is pushed as is.
+	if err := pushApprovedHold(ctx, pr, cfg); err != nil {
+		slog.Error("failed to push approved commit", "prID", pr.ID, "error", err)
+	}
+
 	// 1. Check pipeline status.
 	status, err := backend.GetPipelineStatus(ctx, prInfo)
 	if err != nil {
@@ -991,11 +1875,19 @@ func pollSinglePR(ctx context.Context, pr *PRDocument, reg *provider.Registry, c
 		pr.PipelineState = "unknown"
 	} else {
 		pr.PipelineState = status.State
-		slog.Info("pipeline status", "prID", pr.ID, "state", status.State)
+		pr.PendingPolicies = status.PendingPolicies()
+		pr.MergeQueue = status.MergeQueue.String()
+		pr.AttemptedBuilds = pruneAttemptedBuilds(pr.AttemptedBuilds, status)
+		slog.Info("pipeline status", "prID", pr.ID, "state", status.State, "pendingPolicies", pr.PendingPolicies, "mergeQueue", pr.MergeQueue)
 
 		switch status.State {
 		case "succeeded":
 			// Notify once when transitioning to green.
+			// A green build ends any infra retry backoff, and shows the
+			// failures retried were infrastructure failures.
+			pr.InfraRetries = 0
+			pr.NextInfraRetry = ""
+			confirmInfraFailures(pr)
 			if pr.Status != "green" {
 				pr.Status = "green"
 				if err := Notify(ctx, &cfg.Notifications, NotificationPayload{
@@ -1006,13 +1898,24 @@ func pollSinglePR(ctx context.Context, pr *PRDocument, reg *provider.Registry, c
 				}); err != nil {
 					slog.Warn("failed to send PR green notification", "prID", pr.ID, "error", err)
 				}
+				transitionJiraIssues(ctx, cfg, pr, cfg.Jira.InReviewStatus)
 			}
 			// Fall through to check comments and MerlinBot.
 
 		case "failed":
 			pr.Status = "watching"
 			slog.Info("pipeline failed, attempting fix", "prID", pr.ID)
-			if pr.FixAttempts < pr.MaxFixAttempts {
+			if infraRetryPending(pr, time.Now()) {
+				slog.Info("infra retry backoff in effect, skipping fix", "prID", pr.ID, "nextRetry", pr.NextInfraRetry)
+			} else if pr.PolicyHold != "" {
+				slog.Info("automated changes held for approval, skipping fix", "prID", pr.ID, "hold", pr.PolicyHold)
+			} else if draftHold(pr, cfg) {
+				slog.Info("PR is a draft, skipping fix", "prID", pr.ID)
+			} else if fixBackoffPending(pr, time.Now()) {
+				slog.Info("fix backoff in effect, skipping fix", "prID", pr.ID, "nextFix", pr.NextFixAttempt)
+			} else if ids := failedBuildIDs(status); len(ids) > 0 && !hasNewFailures(pr, ids) {
+				slog.Info("failed builds already attempted, waiting for a new run", "prID", pr.ID, "builds", ids)
+			} else if pr.FixAttempts < pr.MaxFixAttempts {
 				if fixErr := FixPR(ctx, pr, backend, client, cfg); fixErr != nil {
 					slog.Error("fix attempt failed", "prID", pr.ID, "error", fixErr)
 				}
@@ -1027,10 +1930,14 @@ func pollSinglePR(ctx context.Context, pr *PRDocument, reg *provider.Registry, c
 				// Pipeline was green but now running again (new push).
 				pr.Status = "watching"
 			}
+			if cfg.PR.CancelDoomedBuilds && status.State == "inProgress" && !infraRetryPending(pr, time.Now()) && !fixBackoffPending(pr, time.Now()) &&
+				pr.PolicyHold == "" && !draftHold(pr, cfg) && pr.FixAttempts < pr.MaxFixAttempts {
+				cancelDoomedBuilds(ctx, pr, backend, prInfo, status)
+			}
 		}
 	}
 
-	// 2. Check for new comments and 3. MerlinBot — share a single worktree
+	// 2. Check for new comments and 3. bot reviews — share a single worktree
 	// and batch all commits into one push to avoid triggering N pipeline runs.
 	newCommentCount := 0
 	unresolvedCount := 0
@@ -1043,16 +1950,17 @@ func pollSinglePR(ctx context.Context, pr *PRDocument, reg *provider.Registry, c
 		slog.Warn("failed to get comments", "prID", pr.ID, "error", err)
 	}
 
-	// Determine if we need a worktree for comment/MerlinBot work.
+	// Determine if we need a worktree for comment/bot work.
+	bots := botHandlers(pr, cfg)
 	hasNewComments := false
-	needsMerlinBot := false
+	needsBots := false
 	if comments != nil {
 		seenSet := make(map[string]bool)
 		for _, id := range pr.SeenCommentIDs {
 			seenSet[id] = true
 		}
 		for _, comment := range comments {
-			if isMerlinBotAuthor(comment.Author) || comment.CommentType == "system" {
+			if isBotAuthor(bots, comment.Author) || comment.CommentType == "system" {
 				continue
 			}
 			if !comment.IsResolved {
@@ -1064,19 +1972,33 @@ func pollSinglePR(ctx context.Context, pr *PRDocument, reg *provider.Registry, c
 			}
 		}
 	}
-	if !pr.MerlinBotDone {
-		if pr.Provider == "ado" {
-			if adoCfg, ok := cfg.PR.Providers["ado"]; ok && adoCfg.MerlinBot && comments != nil {
-				needsMerlinBot = true
-			}
-		}
+	if comments != nil && len(pendingBots(pr, bots)) > 0 {
+		needsBots = true
+	}
+
+	// Drafts get no replies or fixes; their comments stay unseen until the
+	// PR is published.
+	if draftHold(pr, cfg) && (hasNewComments || needsBots) {
+		slog.Info("PR is a draft, skipping comment/bot processing", "prID", pr.ID)
+		hasNewComments, needsBots = false, false
 	}
 
-	// Create ONE shared worktree for both comment responses and MerlinBot.
-	if hasNewComments || needsMerlinBot {
+	// Create ONE shared worktree for both comment responses and bot reviews.
+	// Comments stay unseen while the branch is busy, so they are picked up
+	// on the next poll.
+	if hasNewComments || needsBots {
+		unlock, lockErr := lockBranch(ctx, pr, "comment/bot fixes")
+		if lockErr != nil {
+			slog.Info("skipping comment/bot processing this cycle", "prID", pr.ID, "error", lockErr)
+			hasNewComments, needsBots = false, false
+		} else {
+			defer unlock()
+		}
+	}
+	if hasNewComments || needsBots {
 		workDir, mergeBack, cleanup, wdErr := repo.MapPRToCleanWorkDir(cfg, pr.URL, pr.Branch)
 		if wdErr != nil {
-			slog.Error("failed to create shared worktree for comment/MerlinBot processing", "prID", pr.ID, "error", wdErr)
+			slog.Error("failed to create shared worktree for comment/bot processing", "prID", pr.ID, "error", wdErr)
 		} else {
 			defer cleanup()
 
@@ -1087,7 +2009,7 @@ func pollSinglePR(ctx context.Context, pr *PRDocument, reg *provider.Registry, c
 					seenSet[id] = true
 				}
 				for _, comment := range comments {
-					if isMerlinBotAuthor(comment.Author) || comment.CommentType == "system" {
+					if isBotAuthor(bots, comment.Author) || comment.CommentType == "system" {
 						continue
 					}
 					commentKey := fmt.Sprintf("%s:%s", comment.ThreadID, comment.ID)
@@ -1107,23 +2029,25 @@ func pollSinglePR(ctx context.Context, pr *PRDocument, reg *provider.Registry, c
 				}
 			}
 
-			// 3. MerlinBot handling in the same shared worktree.
-			if needsMerlinBot {
-				committed, mbErr := handleMerlinBotDaemon(ctx, pr, comments, backend, client, cfg, workDir)
-				if mbErr != nil {
-					slog.Warn("MerlinBot handling failed", "prID", pr.ID, "error", mbErr)
-				}
-				if committed {
-					needsPush = true
+			// 3. Bot reviews in the same shared worktree.
+			if needsBots {
+				for _, h := range pendingBots(pr, bots) {
+					committed, botErr := handleBotReview(ctx, pr, h, comments, backend, client, cfg, workDir)
+					if botErr != nil {
+						slog.Warn("bot review handling failed", "prID", pr.ID, "bot", h.Name, "error", botErr)
+					}
+					if committed {
+						needsPush = true
+					}
 				}
 			}
 
-			// Single consolidated push for all comment + MerlinBot commits.
+			// Single consolidated push for all comment + bot commits.
 			if needsPush {
-				if pushErr := gitPush(ctx, workDir, pr.Branch); pushErr != nil {
-					slog.Error("failed to push batched comment/MerlinBot fixes", "prID", pr.ID, "error", pushErr)
+				if pushErr := pushAndAudit(ctx, pr, cfg, workDir, "review comment / bot fixes", nil); pushErr != nil {
+					slog.Error("failed to push batched comment/bot fixes", "prID", pr.ID, "error", pushErr)
 				} else {
-					slog.Info("pushed batched comment/MerlinBot fixes", "prID", pr.ID)
+					slog.Info("pushed batched comment/bot fixes", "prID", pr.ID)
 					if mbErr := mergeBack(); mbErr != nil {
 						slog.Warn("failed to merge back to user worktree", "prID", pr.ID, "error", mbErr)
 					}
@@ -1132,30 +2056,13 @@ func pollSinglePR(ctx context.Context, pr *PRDocument, reg *provider.Registry, c
 		}
 	}
 
-	// Track feedback resolution state (also handles the case where no worktree was needed).
-	if !pr.MerlinBotDone && !needsMerlinBot {
-		if pr.Provider != "ado" {
-			pr.MerlinBotDone = true
-		} else if adoCfg, ok := cfg.PR.Providers["ado"]; !ok || !adoCfg.MerlinBot {
-			pr.MerlinBotDone = true
-			slog.Debug("MerlinBot not configured, marking done", "prID", pr.ID)
-		}
-	}
+	// Track feedback resolution state (also handles the case where no worktree
+	// was needed). Bots configured later reopen the stage.
+	pr.MerlinBotDone = len(pendingBots(pr, bots)) == 0
 
 	if comments != nil {
 		pr.FeedbackDone = unresolvedCount == 0
 		slog.Info("comment status", "prID", pr.ID, "new", newCommentCount, "unresolved", unresolvedCount, "feedbackDone", pr.FeedbackDone)
-
-		// Reload PR from disk to get updates from evaluateComment.
-		reloaded, loadErr := LoadPR(pr.Provider, pr.ID)
-		if loadErr != nil {
-			return fmt.Errorf("failed to reload PR after comment processing: %w", loadErr)
-		}
-		// Preserve stage fields we just computed (evaluateComment doesn't update these).
-		reloaded.MerlinBotDone = pr.MerlinBotDone
-		reloaded.FeedbackDone = pr.FeedbackDone
-		reloaded.PipelineState = pr.PipelineState
-		pr = reloaded
 	}
 
 	// 4. Notify if comments were handled.
@@ -1171,220 +2078,11 @@ func pollSinglePR(ctx context.Context, pr *PRDocument, reg *provider.Registry, c
 	}
 
 	pr.LastChecked = time.Now().UTC().Format(time.RFC3339)
-	return SavePR(pr)
-}
-
-// isMerlinBotAuthor returns true if the comment author is MerlinBot.
-func isMerlinBotAuthor(author string) bool {
-	return strings.Contains(author, "MerlinBot") || strings.Contains(author, "Merlin")
+	return saveChanges()
 }
 
-// filterAllMerlinBotComments returns all MerlinBot comments regardless of resolution status.
-func filterAllMerlinBotComments(comments []provider.Comment) []provider.Comment {
-	var bot []provider.Comment
-	for _, c := range comments {
-		if isMerlinBotAuthor(c.Author) {
-			bot = append(bot, c)
-		}
-	}
-	return bot
-}
-
-// merlinBotEvaluation represents a parsed evaluation of a single MerlinBot comment.
-type merlinBotEvaluation struct {
-	ThreadID string
-	Decision string // FIX, WONT_FIX, BY_DESIGN
-	Reason   string
-	Action   string
-}
-
-// parseMerlinBotEvaluation parses the LLM's structured evaluation output.
-func parseMerlinBotEvaluation(content string) []merlinBotEvaluation {
-	var evals []merlinBotEvaluation
-
-	threadRe := regexp.MustCompile(`THREAD\s+(\S+):\s*(FIX|WONT_FIX|BY_DESIGN)`)
-	reasonRe := regexp.MustCompile(`REASON:\s*(.+)`)
-	actionRe := regexp.MustCompile(`ACTION:\s*(.+)`)
-
-	lines := strings.Split(content, "\n")
-	var current *merlinBotEvaluation
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		if m := threadRe.FindStringSubmatch(line); m != nil {
-			if current != nil {
-				evals = append(evals, *current)
-			}
-			current = &merlinBotEvaluation{
-				ThreadID: m[1],
-				Decision: m[2],
-			}
-			continue
-		}
-
-		if current != nil {
-			if m := reasonRe.FindStringSubmatch(line); m != nil {
-				current.Reason = m[1]
-			}
-			if m := actionRe.FindStringSubmatch(line); m != nil {
-				current.Action = m[1]
-			}
-		}
-	}
-
-	if current != nil {
-		evals = append(evals, *current)
-	}
-
-	return evals
-}
-
-// handleMerlinBotDaemon processes MerlinBot comments on an ADO PR.
-// It detects "no AI feedback", evaluates real feedback via LLM, and resolves threads.
-// Sets pr.MerlinBotDone = true when MerlinBot has been fully handled.
-// When workDir is provided, it reuses that worktree and commits without pushing
-// (caller is responsible for pushing). Returns true if code changes were committed.
-func handleMerlinBotDaemon(ctx context.Context, pr *PRDocument, comments []provider.Comment, backend provider.PRBackend, client llm.Client, cfg *config.Config, workDir string) (bool, error) {
-	prInfo := &provider.PRInfo{
-		ID:           pr.ID,
-		URL:          pr.URL,
-		RepoID:       pr.Repo,
-		SourceBranch: pr.Branch,
-		TargetBranch: pr.Target,
-	}
-
-	// Find ALL MerlinBot comments (including resolved — MerlinBot auto-closes
-	// "no AI feedback" threads immediately).
-	allBotComments := filterAllMerlinBotComments(comments)
-	if len(allBotComments) == 0 {
-		// Log unique authors to help diagnose matching issues.
-		authorSet := make(map[string]bool)
-		for _, c := range comments {
-			authorSet[c.Author] = true
-		}
-		var authors []string
-		for a := range authorSet {
-			authors = append(authors, a)
-		}
-		slog.Info("no MerlinBot comments found yet", "prID", pr.ID, "totalComments", len(comments), "authors", strings.Join(authors, ", "))
-		return false, nil
-	}
-
-	slog.Info("found MerlinBot comments", "prID", pr.ID, "count", len(allBotComments))
-
-	// Short-circuit: "no AI feedback" — MerlinBot has nothing to say.
-	for _, c := range allBotComments {
-		if strings.Contains(c.Body, "There is no AI feedback on this pull request") {
-			slog.Info("MerlinBot reports no AI feedback", "prID", pr.ID)
-			// Close any still-open "no feedback" threads.
-			for _, bc := range allBotComments {
-				if !bc.IsResolved && strings.Contains(bc.Body, "There is no AI feedback on this pull request") {
-					if err := backend.ResolveComment(ctx, prInfo, bc.ThreadID, provider.ResolutionByDesign); err != nil {
-						slog.Warn("failed to close no-feedback thread", "prID", pr.ID, "threadID", bc.ThreadID, "error", err)
-					}
-				}
-			}
-			pr.MerlinBotDone = true
-			return false, nil
-		}
-	}
-
-	// Filter to only unresolved MerlinBot comments.
-	var unresolvedBot []provider.Comment
-	for _, c := range allBotComments {
-		if !c.IsResolved {
-			unresolvedBot = append(unresolvedBot, c)
-		}
-	}
-
-	if len(unresolvedBot) == 0 {
-		slog.Info("all MerlinBot comments already resolved", "prID", pr.ID)
-		pr.MerlinBotDone = true
-		return false, nil
-	}
-
-	// Evaluate unresolved MerlinBot comments via LLM.
-	slog.Info("evaluating MerlinBot comments via LLM", "prID", pr.ID, "count", len(unresolvedBot))
-
-	// Build comment summary for the prompt.
-	var commentSummary strings.Builder
-	for _, c := range unresolvedBot {
-		commentSummary.WriteString(fmt.Sprintf("THREAD %s [%s:%d]:\n%s\n\n", c.ThreadID, c.FilePath, c.Line, c.Body))
-	}
-
-	templateData := map[string]string{
-		"Comments": commentSummary.String(),
-	}
-	prompt, err := prompts.Execute("merlinbot-evaluate.md", templateData)
-	if err != nil {
-		return false, fmt.Errorf("building MerlinBot evaluation prompt: %w", err)
-	}
-
-	session, err := client.CreateSession(ctx, fmt.Sprintf("MerlinBot PR#%s", pr.ID), workDir)
-	if err != nil {
-		return false, fmt.Errorf("creating MerlinBot session: %w", err)
-	}
-	defer client.DeleteSession(ctx, session.ID)
-
-	resp, err := client.SendPrompt(ctx, session.ID, prompt)
-	if err != nil {
-		return false, fmt.Errorf("MerlinBot evaluation failed: %w", err)
-	}
-
-	// Parse evaluations and take action.
-	evaluations := parseMerlinBotEvaluation(resp.Content)
-	fixCount := 0
-
-	for _, eval := range evaluations {
-		switch eval.Decision {
-		case "FIX":
-			fixCount++
-			fixPrompt := fmt.Sprintf("Fix the issue identified by MerlinBot in thread %s:\n\n%s\n\nAction: %s",
-				eval.ThreadID, eval.Reason, eval.Action)
-			if _, err := client.SendPrompt(ctx, session.ID, fixPrompt); err != nil {
-				slog.Warn("failed to apply MerlinBot fix", "prID", pr.ID, "threadID", eval.ThreadID, "error", err)
-				continue
-			}
-			if err := backend.ReplyToComment(ctx, prInfo, eval.ThreadID, fmt.Sprintf("Fixed: %s", eval.Action)+aiFooter(cfg)); err != nil {
-				slog.Warn("failed to reply to MerlinBot thread", "prID", pr.ID, "threadID", eval.ThreadID, "error", err)
-			}
-			if err := backend.ResolveComment(ctx, prInfo, eval.ThreadID, provider.ResolutionFixed); err != nil {
-				slog.Warn("failed to resolve MerlinBot thread", "prID", pr.ID, "threadID", eval.ThreadID, "error", err)
-			}
-
-		case "WONT_FIX":
-			if err := backend.ReplyToComment(ctx, prInfo, eval.ThreadID, eval.Reason+aiFooter(cfg)); err != nil {
-				slog.Warn("failed to reply to MerlinBot thread", "prID", pr.ID, "threadID", eval.ThreadID, "error", err)
-			}
-			if err := backend.ResolveComment(ctx, prInfo, eval.ThreadID, provider.ResolutionWontFix); err != nil {
-				slog.Warn("failed to resolve MerlinBot thread", "prID", pr.ID, "threadID", eval.ThreadID, "error", err)
-			}
-
-		case "BY_DESIGN":
-			if err := backend.ReplyToComment(ctx, prInfo, eval.ThreadID, eval.Reason+aiFooter(cfg)); err != nil {
-				slog.Warn("failed to reply to MerlinBot thread", "prID", pr.ID, "threadID", eval.ThreadID, "error", err)
-			}
-			if err := backend.ResolveComment(ctx, prInfo, eval.ThreadID, provider.ResolutionByDesign); err != nil {
-				slog.Warn("failed to resolve MerlinBot thread", "prID", pr.ID, "threadID", eval.ThreadID, "error", err)
-			}
-		}
-	}
-
-	// Commit if fixes were applied (push is batched by the caller).
-	committed := false
-	if fixCount > 0 {
-		commitMsg := fmt.Sprintf("address %d MerlinBot comment(s)", fixCount)
-		commitHash, err := gitCommit(ctx, workDir, commitMsg)
-		if err != nil {
-			slog.Warn("failed to commit MerlinBot fixes", "prID", pr.ID, "error", err)
-		} else {
-			committed = true
-			slog.Info("committed MerlinBot fixes", "prID", pr.ID, "commit", commitHash, "fixCount", fixCount)
-		}
-	}
-
-	pr.MerlinBotDone = true
-	slog.Info("MerlinBot handling complete", "prID", pr.ID, "evaluated", len(evaluations), "fixed", fixCount)
-	return committed, nil
+// draftHold reports whether the daemon leaves pr's code and comments alone
+// because it is a draft and pr.work_on_drafts is off.
+func draftHold(pr *PRDocument, cfg *config.Config) bool {
+	return pr.IsDraft && !cfg.PR.WorkOnDrafts
 }
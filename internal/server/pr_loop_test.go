@@ -1,10 +1,17 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/alanmeadows/otto/internal/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSavePRAndLoadPR(t *testing.T) {
@@ -26,6 +33,18 @@ func TestSavePRAndLoadPR(t *testing.T) {
 		MaxFixAttempts: 5,
 		SeenCommentIDs: []string{"c1", "c2"},
 		Body:           "# Test PR\n\nSome body content.",
+
+		FixAttemptsByCategory: map[string]int{"compile": 1, "test": 1},
+		InfraRetries:          2,
+		NextInfraRetry:        "2026-01-02T00:08:00Z",
+
+		LastModel: "gpt-5.2-codex",
+
+		LastChecks: "1/2 passed (failed: go test ./...)",
+
+		IsDraft:         true,
+		PendingPolicies: []string{"work item link missing"},
+		BotsDone:        []string{"merlinbot"},
 	}
 
 	err := SavePR(pr)
@@ -46,9 +65,96 @@ func TestSavePRAndLoadPR(t *testing.T) {
 	assert.Equal(t, pr.FixAttempts, loaded.FixAttempts)
 	assert.Equal(t, pr.MaxFixAttempts, loaded.MaxFixAttempts)
 	assert.Equal(t, pr.SeenCommentIDs, loaded.SeenCommentIDs)
+	assert.Equal(t, pr.FixAttemptsByCategory, loaded.FixAttemptsByCategory)
+	assert.Equal(t, pr.InfraRetries, loaded.InfraRetries)
+	assert.Equal(t, pr.NextInfraRetry, loaded.NextInfraRetry)
+	assert.Equal(t, pr.LastModel, loaded.LastModel)
+	assert.Equal(t, pr.LastChecks, loaded.LastChecks)
+	assert.True(t, loaded.IsDraft)
+	assert.Equal(t, pr.PendingPolicies, loaded.PendingPolicies)
+	assert.Equal(t, pr.BotsDone, loaded.BotsDone)
+	assert.Contains(t, loaded.WaitingOn, "bot reviews")
+	assert.Contains(t, loaded.WaitingOn, "branch policy: work item link missing")
 	assert.Contains(t, loaded.Body, "Test PR")
 }
 
+func TestUpdatePR(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	require.NoError(t, SavePR(&PRDocument{ID: "9", Provider: "ado", Status: "watching", MaxFixAttempts: 5}))
+
+	// Concurrent writers each see the others' changes.
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := UpdatePR("ado", "9", func(p *PRDocument) error {
+				p.FixAttempts++
+				p.Body += "x"
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	saved, err := LoadPR("ado", "9")
+	require.NoError(t, err)
+	assert.Equal(t, 10, saved.FixAttempts)
+	assert.Equal(t, strings.Repeat("x", 10), strings.TrimSpace(saved.Body))
+
+	// A failing update writes nothing.
+	_, err = UpdatePR("ado", "9", func(p *PRDocument) error {
+		p.Status = "failed"
+		return errors.New("no")
+	})
+	assert.EqualError(t, err, "no")
+	saved, err = LoadPR("ado", "9")
+	require.NoError(t, err)
+	assert.Equal(t, "watching", saved.Status)
+
+	// Untracked PRs are not created.
+	_, err = UpdatePR("ado", "10", func(*PRDocument) error { return nil })
+	assert.Error(t, err)
+}
+
+func TestSavePRChangeKeepsOtherWriters(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	pr := &PRDocument{ID: "9", Provider: "ado", Status: "watching", MaxFixAttempts: 5}
+	require.NoError(t, SavePR(pr))
+
+	// The CLI pauses the PR while the daemon holds an older copy.
+	_, err := SetPRPaused("9", true)
+	require.NoError(t, err)
+
+	require.NoError(t, savePRChange(pr, func(p *PRDocument) { p.Status = "green" }))
+	assert.Equal(t, "green", pr.Status)
+
+	saved, err := LoadPR("ado", "9")
+	require.NoError(t, err)
+	assert.Equal(t, "green", saved.Status)
+	assert.True(t, saved.Paused)
+}
+
+func TestDraftHold(t *testing.T) {
+	pr := &PRDocument{Status: "watching", IsDraft: true, MerlinBotDone: true, FeedbackDone: true, PipelineState: "succeeded"}
+	cfg := &config.Config{}
+
+	assert.True(t, draftHold(pr, cfg))
+	assert.Equal(t, "draft", pr.ComputeWaitingOn())
+
+	cfg.PR.WorkOnDrafts = true
+	assert.False(t, draftHold(pr, cfg))
+
+	pr.IsDraft = false
+	cfg.PR.WorkOnDrafts = false
+	assert.False(t, draftHold(pr, cfg))
+	assert.Equal(t, "all clear", pr.ComputeWaitingOn())
+
+	pr.MergeQueue = "#2, awaiting checks"
+	assert.Equal(t, "merge queue (#2, awaiting checks)", pr.ComputeWaitingOn())
+}
+
 func TestListPRs(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Setenv("XDG_DATA_HOME", tmpDir)
@@ -204,3 +310,74 @@ func TestPRFilename(t *testing.T) {
 	path := prPath("github", "123")
 	assert.Equal(t, name, filepath.Base(path))
 }
+
+func TestDiagnosisComment(t *testing.T) {
+	comment := diagnosisComment(CategoryTest, []string{"CI", "Lint"}, "CLASSIFICATION: TEST\n\nTestFoo asserts the old default.\n")
+	assert.Contains(t, comment, "- **Classification**: test")
+	assert.Contains(t, comment, "- **Failed checks**: CI, Lint")
+	assert.Contains(t, comment, "<details>\n<summary>Diagnosis</summary>\n\nCLASSIFICATION: TEST\n\nTestFoo asserts the old default.\n\n</details>")
+}
+
+func TestGitIdentityArgs(t *testing.T) {
+	assert.Empty(t, gitIdentityArgs(nil))
+	assert.Empty(t, gitIdentityArgs(&config.Config{}))
+
+	cfg := &config.Config{Git: config.GitConfig{
+		AuthorName:    "otto-bot",
+		AuthorEmail:   "otto@example.com",
+		SigningKey:    "~/.ssh/otto.pub",
+		SigningFormat: "ssh",
+	}}
+	assert.Equal(t, []string{
+		"-c", "user.name=otto-bot",
+		"-c", "user.email=otto@example.com",
+		"-c", "commit.gpgsign=true",
+		"-c", "user.signingkey=~/.ssh/otto.pub",
+		"-c", "gpg.format=ssh",
+	}, gitIdentityArgs(cfg))
+}
+
+func TestGitCommit_UsesConfiguredIdentity(t *testing.T) {
+	repoDir := newRepoWithRemote(t)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "fix.txt"), []byte("fixed\n"), 0644))
+
+	cfg := &config.Config{Git: config.GitConfig{AuthorName: "otto-bot", AuthorEmail: "otto@example.com"}}
+	hash, err := gitCommit(context.Background(), cfg, repoDir, "fix CI failures (attempt 1)")
+	require.NoError(t, err)
+	assert.Len(t, hash, 8)
+
+	out := gitT(t, repoDir, "log", "-1", "--format=%an <%ae>|%cn <%ce>")
+	assert.Equal(t, "otto-bot <otto@example.com>|otto-bot <otto@example.com>", strings.TrimSpace(out))
+}
+
+func TestFinishFixAttemptPerBuild(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	pr := &PRDocument{ID: "9", Provider: "github", Status: "fixing", FixAttempts: 1, MaxFixAttempts: 5}
+	require.NoError(t, SavePR(pr))
+
+	events := []PREvent{
+		fixAttemptEvent(pr, CategoryTest, "unit", "abc123", nil),
+		fixAttemptEvent(pr, CategoryLint, "lint", "def456", nil),
+	}
+	require.NoError(t, finishFixAttempt(context.Background(), pr, nil, nil, &config.Config{}, []string{"7", "8"}, events))
+
+	saved, err := LoadPR("github", "9")
+	require.NoError(t, err)
+	assert.Equal(t, 2, saved.FixAttempts, "one attempt for the pass")
+	assert.Equal(t, map[string]int{"test": 1, "lint": 1}, saved.FixAttemptsByCategory)
+	assert.Equal(t, "watching", saved.Status)
+	assert.Equal(t, []string{"7", "8"}, saved.AttemptedBuilds)
+
+	recorded, err := ReadPREvents("github", "9")
+	require.NoError(t, err)
+	var fixes []PREvent
+	for _, e := range recorded {
+		if e.Kind == PREventFixAttempt {
+			fixes = append(fixes, e)
+		}
+	}
+	require.Len(t, fixes, 2)
+	assert.Equal(t, 2, fixes[0].Attempt)
+	assert.Equal(t, 2, fixes[1].Attempt)
+	assert.Equal(t, "lint", fixes[1].Build)
+}
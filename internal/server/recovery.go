@@ -0,0 +1,397 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/store"
+	"github.com/gofrs/flock"
+)
+
+// Recovery issue kinds reported by the boot-time recovery audit.
+const (
+	RecoveryStuckPR          = "stuck_pr"
+	RecoveryOrphanedWorktree = "orphaned_worktree"
+	RecoveryRebaseInProgress = "rebase_in_progress"
+	RecoveryUnpushedCommits  = "unpushed_commits"
+	RecoveryQueuedNotify     = "queued_notifications"
+	RecoveryStaleLock        = "stale_lock"
+	RecoveryFixCheckpoint    = "fix_checkpoint"
+)
+
+// tempWorktreePrefixes are the directory name prefixes of the throwaway
+// worktrees otto creates for fixes and reviews.
+var tempWorktreePrefixes = []string{"otto-fix-", "otto-review-"}
+
+// RecoveryIssue is one thing the recovery audit found.
+type RecoveryIssue struct {
+	Kind     string `json:"kind"`
+	Subject  string `json:"subject"` // PR ID, worktree path, or lock file
+	Detail   string `json:"detail,omitempty"`
+	Repaired bool   `json:"repaired"` // true if fixed automatically; false needs attention
+}
+
+// RecoveryReport is the result of a boot-time recovery audit.
+type RecoveryReport struct {
+	Time   time.Time       `json:"time"`
+	Issues []RecoveryIssue `json:"issues"`
+}
+
+// NeedsAttention returns the issues that were not repaired automatically.
+func (r *RecoveryReport) NeedsAttention() []RecoveryIssue {
+	var out []RecoveryIssue
+	for _, is := range r.Issues {
+		if !is.Repaired {
+			out = append(out, is)
+		}
+	}
+	return out
+}
+
+func (r *RecoveryReport) add(kind, subject, detail string, repaired bool) {
+	r.Issues = append(r.Issues, RecoveryIssue{Kind: kind, Subject: subject, Detail: detail, Repaired: repaired})
+}
+
+// RecoveryReportPath returns where the last recovery report is stored.
+func RecoveryReportPath() string {
+	return filepath.Join(filepath.Dir(PRDir()), "recovery.json")
+}
+
+// LoadRecoveryReport reads the report written by the last daemon boot.
+// Returns nil, nil if no audit has run yet.
+func LoadRecoveryReport() (*RecoveryReport, error) {
+	data, err := os.ReadFile(RecoveryReportPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading recovery report: %w", err)
+	}
+	var r RecoveryReport
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parsing recovery report: %w", err)
+	}
+	return &r, nil
+}
+
+// RunRecoveryAudit inspects state left behind by a previous daemon run,
+// repairs what is safe to repair, and records the rest. The report is
+// persisted for `otto server status --recovery` and, when anything was
+// found, summarized in a boot notification.
+func RunRecoveryAudit(ctx context.Context, cfg *config.Config) *RecoveryReport {
+	report := &RecoveryReport{Time: time.Now().UTC()}
+
+	recoverStuckPRs(report)
+	recoverFixCheckpoints(report)
+	for _, r := range cfg.Repos {
+		if r.PrimaryDir != "" {
+			recoverWorktrees(ctx, report, config.ExpandHome(r.PrimaryDir), orphanMinAge(cfg))
+		}
+	}
+	recoverStaleLocks(report, PRDir())
+	recoverQueuedNotifications(ctx, report, cfg)
+
+	if err := saveRecoveryReport(report); err != nil {
+		slog.Warn("failed to save recovery report", "error", err)
+	}
+
+	attention := report.NeedsAttention()
+	slog.Info("recovery audit complete", "issues", len(report.Issues), "needsAttention", len(attention))
+	for _, is := range attention {
+		slog.Warn("recovery issue needs attention", "kind", is.Kind, "subject", is.Subject, "detail", is.Detail)
+	}
+
+	if len(report.Issues) > 0 {
+		if err := Notify(ctx, &cfg.Notifications, recoveryNotification(report)); err != nil {
+			slog.Warn("failed to send recovery notification", "error", err)
+		}
+	}
+	return report
+}
+
+// recoverStuckPRs resets PRs left in "fixing" by a crash or restart back
+// to "watching" so the monitor loop picks them up again.
+func recoverStuckPRs(report *RecoveryReport) {
+	prs, err := ListPRs()
+	if err != nil {
+		slog.Warn("failed to list PRs for stuck-state reset", "error", err)
+		return
+	}
+	for _, pr := range prs {
+		if pr.Status != "fixing" {
+			continue
+		}
+		slog.Info("resetting stuck PR from fixing to watching", "prID", pr.ID, "title", pr.Title)
+		_, err := UpdatePR(pr.Provider, pr.ID, func(p *PRDocument) error {
+			if p.Status == "fixing" {
+				p.Status = "watching"
+			}
+			return nil
+		})
+		if err != nil {
+			slog.Error("failed to reset stuck PR", "prID", pr.ID, "error", err)
+			report.add(RecoveryStuckPR, pr.ID, fmt.Sprintf("could not reset from fixing: %v", err), false)
+			continue
+		}
+		report.add(RecoveryStuckPR, pr.ID, "reset from fixing to watching", true)
+	}
+}
+
+// recoverFixCheckpoints reports fixes checkpointed at shutdown, which the
+// next attempt resumes, and removes those of PRs no longer tracked.
+func recoverFixCheckpoints(report *RecoveryReport) {
+	paths, _ := filepath.Glob(filepath.Join(fixCheckpointDir(), "*.json"))
+	for _, path := range paths {
+		cp, err := loadFixCheckpoint(path)
+		if err == nil {
+			_, err = LoadPR(cp.Provider, cp.PRID)
+		}
+		if err != nil {
+			if rmErr := os.Remove(path); rmErr == nil {
+				report.add(RecoveryFixCheckpoint, path, "removed checkpoint of an untracked PR", true)
+			}
+			continue
+		}
+		report.add(RecoveryFixCheckpoint, cp.PRID, fmt.Sprintf("fix attempt %d was interrupted; it resumes from its diagnosis", cp.Attempt), true)
+	}
+}
+
+// orphanMinAge is how old a temporary worktree must be before the audit
+// treats it as orphaned; younger ones may belong to a running `otto pr fix`.
+func orphanMinAge(cfg *config.Config) time.Duration {
+	d := cfg.PR.ParseFixTimeout()
+	if c := cfg.PR.ParseConflictTimeout(); c > d {
+		d = c
+	}
+	return d
+}
+
+// worktreeEntry is one record from `git worktree list --porcelain`.
+type worktreeEntry struct {
+	Path     string
+	Prunable bool
+}
+
+// listWorktrees returns the worktrees registered in repoDir.
+func listWorktrees(ctx context.Context, repoDir string) ([]worktreeEntry, error) {
+	cmd := exec.CommandContext(ctx, "git", "worktree", "list", "--porcelain")
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git worktree list: %w", err)
+	}
+	var entries []worktreeEntry
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			// Git for Windows prints paths with forward slashes.
+			entries = append(entries, worktreeEntry{Path: filepath.FromSlash(strings.TrimPrefix(line, "worktree "))})
+		case strings.HasPrefix(line, "prunable") && len(entries) > 0:
+			entries[len(entries)-1].Prunable = true
+		}
+	}
+	return entries, nil
+}
+
+// isTempWorktree reports whether path is one of otto's throwaway worktrees.
+func isTempWorktree(path string) bool {
+	base := filepath.Base(path)
+	for _, p := range tempWorktreePrefixes {
+		if strings.HasPrefix(base, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// recoverWorktrees finds otto temp worktrees left behind in repoDir.
+// Worktrees with an in-progress rebase have it aborted (nothing from a
+// half-finished rebase was pushed). Worktrees holding commits that exist
+// on no remote are kept and reported; everything else is removed.
+func recoverWorktrees(ctx context.Context, report *RecoveryReport, repoDir string, minAge time.Duration) {
+	entries, err := listWorktrees(ctx, repoDir)
+	if err != nil {
+		slog.Debug("skipping worktree recovery", "repo", repoDir, "error", err)
+		return
+	}
+
+	pruneNeeded := false
+	for _, wt := range entries {
+		if !isTempWorktree(wt.Path) {
+			continue
+		}
+		if wt.Prunable {
+			pruneNeeded = true
+			report.add(RecoveryOrphanedWorktree, wt.Path, "directory missing; pruned stale registration", true)
+			continue
+		}
+		info, err := os.Stat(wt.Path)
+		if err != nil || time.Since(info.ModTime()) < minAge {
+			continue
+		}
+
+		if rebaseInProgress(ctx, wt.Path) {
+			if err := runGit(ctx, wt.Path, "rebase", "--abort"); err != nil {
+				report.add(RecoveryRebaseInProgress, wt.Path, fmt.Sprintf("rebase --abort failed: %v", err), false)
+				continue
+			}
+			report.add(RecoveryRebaseInProgress, wt.Path, "aborted interrupted rebase", true)
+		}
+
+		if commits := unpushedCommits(ctx, wt.Path); len(commits) > 0 {
+			report.add(RecoveryUnpushedCommits, wt.Path,
+				fmt.Sprintf("%d local fix commit(s) never pushed: %s", len(commits), strings.Join(commits, ", ")), false)
+			continue
+		}
+
+		if err := runGit(ctx, repoDir, "worktree", "remove", "--force", wt.Path); err != nil {
+			report.add(RecoveryOrphanedWorktree, wt.Path, fmt.Sprintf("remove failed: %v", err), false)
+			continue
+		}
+		report.add(RecoveryOrphanedWorktree, wt.Path, "removed", true)
+	}
+
+	if pruneNeeded {
+		if err := runGit(ctx, repoDir, "worktree", "prune"); err != nil {
+			slog.Warn("git worktree prune failed", "repo", repoDir, "error", err)
+		}
+	}
+}
+
+// rebaseInProgress reports whether the worktree has an unfinished rebase.
+func rebaseInProgress(ctx context.Context, workDir string) bool {
+	for _, name := range []string{"rebase-merge", "rebase-apply"} {
+		cmd := exec.CommandContext(ctx, "git", "rev-parse", "--git-path", name)
+		cmd.Dir = workDir
+		out, err := cmd.Output()
+		if err != nil {
+			continue
+		}
+		p := filepath.FromSlash(strings.TrimSpace(string(out)))
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(workDir, p)
+		}
+		if _, err := os.Stat(p); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// unpushedCommits returns short hashes of HEAD commits not on any remote.
+func unpushedCommits(ctx context.Context, workDir string) []string {
+	cmd := exec.CommandContext(ctx, "git", "log", "--format=%h", "HEAD", "--not", "--remotes")
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(out))
+}
+
+// runGit runs a git command in dir, folding output into the error.
+func runGit(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// recoverStaleLocks inspects the lock files of the PR documents in prDir.
+// A lock still held is reported with its holder. A free lock whose file
+// still names a holder was left by a process that exited mid-write; the
+// record is cleared so it doesn't name the wrong process later. Lock files
+// are never removed: a process that opened one a moment ago would go on
+// locking a file no one else sees.
+func recoverStaleLocks(report *RecoveryReport, prDir string) {
+	locks, _ := filepath.Glob(filepath.Join(prDir, "*.lock"))
+	for _, path := range locks {
+		lock := flock.New(path)
+		locked, err := lock.TryLock()
+		if err != nil {
+			continue
+		}
+		if !locked {
+			holder := "another process"
+			if owner := store.LockOwner(path); owner != "" {
+				holder = owner
+			}
+			report.add(RecoveryStaleLock, path, "held by "+holder, false)
+			continue
+		}
+		if owner := store.LockOwner(path); owner != "" {
+			if err := os.Truncate(path, 0); err == nil {
+				report.add(RecoveryStaleLock, path, "cleared owner record left by "+owner, true)
+			}
+		}
+		_ = lock.Unlock()
+	}
+}
+
+// recoverQueuedNotifications redelivers notifications that failed to send
+// before the restart.
+func recoverQueuedNotifications(ctx context.Context, report *RecoveryReport, cfg *config.Config) {
+	sent, remaining, err := flushNotificationOutbox(ctx, &cfg.Notifications)
+	if err != nil {
+		report.add(RecoveryQueuedNotify, notifyOutboxPath(), fmt.Sprintf("flush failed: %v", err), false)
+		return
+	}
+	if sent > 0 {
+		report.add(RecoveryQueuedNotify, notifyOutboxPath(), fmt.Sprintf("delivered %d queued notification(s)", sent), true)
+	}
+	if remaining > 0 {
+		report.add(RecoveryQueuedNotify, notifyOutboxPath(), fmt.Sprintf("%d notification(s) still undeliverable", remaining), false)
+	}
+}
+
+// saveRecoveryReport writes the report for later inspection.
+func saveRecoveryReport(report *RecoveryReport) error {
+	path := RecoveryReportPath()
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling recovery report: %w", err)
+	}
+	return store.WithLock(path, store.DefaultLockTimeout, func() error {
+		return os.WriteFile(path, data, 0600)
+	})
+}
+
+// recoveryNotification summarizes a report as a boot notification.
+func recoveryNotification(report *RecoveryReport) NotificationPayload {
+	attention := report.NeedsAttention()
+	status := "recovered"
+	if len(attention) > 0 {
+		status = "needs attention"
+	}
+	extra := map[string]string{
+		"Repaired":        strconv.Itoa(len(report.Issues) - len(attention)),
+		"Needs Attention": strconv.Itoa(len(attention)),
+	}
+	var errText string
+	if len(attention) > 0 {
+		var lines []string
+		for _, is := range attention {
+			lines = append(lines, fmt.Sprintf("%s: %s (%s)", is.Kind, is.Subject, is.Detail))
+		}
+		errText = strings.Join(lines, "; ") + " — run `otto server status --recovery` for details"
+	}
+	return NotificationPayload{
+		Event:  EventDaemonRecovered,
+		Title:  "otto daemon restarted",
+		Status: status,
+		Error:  errText,
+		Extra:  extra,
+	}
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gitT runs git in dir, failing the test on error.
+func gitT(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return string(out)
+}
+
+// newRepoWithRemote creates a clone of a bare origin with one pushed commit.
+func newRepoWithRemote(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	origin := filepath.Join(root, "origin.git")
+	gitT(t, root, "init", "-q", "--bare", origin)
+	clone := filepath.Join(root, "clone")
+	gitT(t, root, "clone", "-q", origin, clone)
+	gitT(t, clone, "commit", "-q", "--allow-empty", "-m", "init")
+	gitT(t, clone, "push", "-q", "origin", "HEAD")
+	return clone
+}
+
+func TestRecoverStuckPRs(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	require.NoError(t, SavePR(&PRDocument{ID: "1", Provider: "ado", Status: "fixing"}))
+	require.NoError(t, SavePR(&PRDocument{ID: "2", Provider: "ado", Status: "watching"}))
+
+	report := &RecoveryReport{}
+	recoverStuckPRs(report)
+
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, RecoveryStuckPR, report.Issues[0].Kind)
+	assert.Equal(t, "1", report.Issues[0].Subject)
+	assert.True(t, report.Issues[0].Repaired)
+
+	pr, err := LoadPR("ado", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "watching", pr.Status)
+}
+
+func TestRecoverWorktrees(t *testing.T) {
+	repoDir := newRepoWithRemote(t)
+	tmp := t.TempDir()
+
+	clean := filepath.Join(tmp, "otto-fix-clean")
+	gitT(t, repoDir, "worktree", "add", "-q", "--detach", clean, "HEAD")
+
+	dirty := filepath.Join(tmp, "otto-fix-dirty")
+	gitT(t, repoDir, "worktree", "add", "-q", "--detach", dirty, "HEAD")
+	gitT(t, dirty, "commit", "-q", "--allow-empty", "-m", "fix CI failures (attempt 1)")
+
+	missing := filepath.Join(tmp, "otto-fix-missing")
+	gitT(t, repoDir, "worktree", "add", "-q", "--detach", missing, "HEAD")
+	require.NoError(t, os.RemoveAll(missing))
+
+	user := filepath.Join(tmp, "user-worktree")
+	gitT(t, repoDir, "worktree", "add", "-q", "--detach", user, "HEAD")
+
+	report := &RecoveryReport{}
+	recoverWorktrees(context.Background(), report, repoDir, 0)
+
+	bySubject := map[string]RecoveryIssue{}
+	for _, is := range report.Issues {
+		bySubject[is.Subject] = is
+	}
+	require.Contains(t, bySubject, clean)
+	assert.True(t, bySubject[clean].Repaired)
+	assert.NoDirExists(t, clean)
+
+	require.Contains(t, bySubject, dirty)
+	assert.Equal(t, RecoveryUnpushedCommits, bySubject[dirty].Kind)
+	assert.False(t, bySubject[dirty].Repaired)
+	assert.DirExists(t, dirty)
+
+	require.Contains(t, bySubject, missing)
+	assert.True(t, bySubject[missing].Repaired)
+
+	assert.NotContains(t, bySubject, user, "non-otto worktrees are left alone")
+	assert.DirExists(t, user)
+
+	list := gitT(t, repoDir, "worktree", "list")
+	assert.NotContains(t, list, "otto-fix-missing")
+}
+
+func TestRecoverStaleLocks(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "live.md"), nil, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "live.md.lock"), nil, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crashed.md.lock"), []byte("pid 1 on host since 2026-01-01T00:00:00Z\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gone.md.lock"), nil, 0600))
+	// Lock files elsewhere, e.g. in pooled checkouts, are not otto's.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "pool"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pool", "yarn.lock"), []byte("deps"), 0600))
+
+	report := &RecoveryReport{}
+	recoverStaleLocks(report, dir)
+
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, filepath.Join(dir, "crashed.md.lock"), report.Issues[0].Subject)
+	assert.True(t, report.Issues[0].Repaired)
+	data, err := os.ReadFile(filepath.Join(dir, "crashed.md.lock"))
+	require.NoError(t, err)
+	assert.Empty(t, data, "owner record cleared, file kept")
+	assert.FileExists(t, filepath.Join(dir, "gone.md.lock"), "lock files are never removed")
+	assert.FileExists(t, filepath.Join(dir, "live.md.lock"))
+	assert.FileExists(t, filepath.Join(dir, "pool", "yarn.lock"))
+}
+
+func TestRunRecoveryAudit_SavesReport(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	require.NoError(t, SavePR(&PRDocument{ID: "7", Provider: "github", Status: "fixing"}))
+
+	report := RunRecoveryAudit(context.Background(), &config.Config{})
+	require.NotEmpty(t, report.Issues)
+
+	loaded, err := LoadRecoveryReport()
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, len(report.Issues), len(loaded.Issues))
+	assert.Empty(t, loaded.NeedsAttention())
+}
+
+func TestRecoveryNotification(t *testing.T) {
+	report := &RecoveryReport{}
+	report.add(RecoveryStuckPR, "1", "reset", true)
+	report.add(RecoveryUnpushedCommits, "/tmp/otto-fix-1", "1 local fix commit(s) never pushed: abc123", false)
+
+	n := recoveryNotification(report)
+	assert.Equal(t, EventDaemonRecovered, n.Event)
+	assert.Equal(t, "needs attention", n.Status)
+	assert.Equal(t, "1", n.Extra["Repaired"])
+	assert.Equal(t, "1", n.Extra["Needs Attention"])
+	assert.Contains(t, n.Error, "otto-fix-1")
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// releaseDescriptionLimit caps each PR's description in release notes
+// input, so a release of many PRs stays within the LLM's context.
+const releaseDescriptionLimit = 2000
+
+// ReleasePR is a merged PR going into release notes.
+type ReleasePR struct {
+	PR     *PRDocument
+	Merged time.Time
+}
+
+// MergedPRs returns the tracked and archived PRs of repo (on providerName)
+// merged between since and until, oldest first. An empty repo matches
+// every repository.
+func MergedPRs(providerName, repo string, since, until time.Time) ([]ReleasePR, error) {
+	prs, err := reportPRs()
+	if err != nil {
+		return nil, err
+	}
+	return mergedPRs(prs, providerName, repo, since, until), nil
+}
+
+func mergedPRs(prs []reportPR, providerName, repo string, since, until time.Time) []ReleasePR {
+	var out []ReleasePR
+	for _, p := range prs {
+		if p.pr.Status != "merged" || p.pr.Provider != providerName || (repo != "" && !strings.EqualFold(p.pr.Repo, repo)) {
+			continue
+		}
+		// The merge is the last status change to merged; PRs merged before
+		// otto kept history were last checked when it saw the merge.
+		var merged time.Time
+		for _, e := range p.events {
+			if e.Kind == PREventStatus && e.To == "merged" && e.Time.After(merged) {
+				merged = e.Time
+			}
+		}
+		if merged.IsZero() {
+			t, err := time.Parse(time.RFC3339, p.pr.LastChecked)
+			if err != nil {
+				continue
+			}
+			merged = t
+		}
+		if merged.Before(since) || !merged.Before(until) {
+			continue
+		}
+		out = append(out, ReleasePR{PR: p.pr, Merged: merged})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Merged.Before(out[j].Merged) })
+	return out
+}
+
+// Description returns the PR's description: its document's body without
+// the title heading and otto's risk analysis.
+func (pr *PRDocument) Description() string {
+	body := strings.TrimSpace(pr.Body)
+	if first, rest, _ := strings.Cut(body, "\n"); strings.HasPrefix(first, "# ") {
+		body = rest
+	}
+	body, _, _ = strings.Cut(body, "## Risk analysis")
+	return strings.TrimSpace(body)
+}
+
+// ReleaseNotesInput renders prs for the release-notes prompt: each PR's
+// title, link, linked work items, and description.
+func ReleaseNotesInput(prs []ReleasePR) string {
+	var b strings.Builder
+	for _, p := range prs {
+		fmt.Fprintf(&b, "### #%s: %s\n\n", p.PR.ID, p.PR.Title)
+		fmt.Fprintf(&b, "- URL: %s\n", p.PR.URL)
+		fmt.Fprintf(&b, "- Merged: %s\n", p.Merged.UTC().Format("2006-01-02"))
+		if len(p.PR.WorkItems) > 0 {
+			fmt.Fprintf(&b, "- Work items: #%s\n", strings.Join(p.PR.WorkItems, ", #"))
+		}
+		if desc := p.PR.Description(); desc != "" {
+			if len(desc) > releaseDescriptionLimit {
+				desc = desc[:releaseDescriptionLimit] + "\n... (truncated)"
+			}
+			fmt.Fprintf(&b, "\n%s\n", desc)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// PlainReleaseNotes lists prs as release notes without grouping them, for
+// when the LLM can't write them.
+func PlainReleaseNotes(prs []ReleasePR) string {
+	var b strings.Builder
+	b.WriteString("## Changes\n\n")
+	if len(prs) == 0 {
+		b.WriteString("No merged pull requests.\n")
+	}
+	for _, p := range prs {
+		fmt.Fprintf(&b, "- %s ([#%s](%s))", p.PR.Title, p.PR.ID, p.PR.URL)
+		if len(p.PR.WorkItems) > 0 {
+			fmt.Fprintf(&b, " — #%s", strings.Join(p.PR.WorkItems, ", #"))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergedPRs(t *testing.T) {
+	t0 := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return t0.Add(time.Duration(h) * time.Hour) }
+	merged := func(h int) []PREvent {
+		return []PREvent{{Time: at(h), Kind: PREventStatus, From: "green", To: "merged"}}
+	}
+
+	prs := []reportPR{
+		{pr: &PRDocument{ID: "1", Provider: "ado", Repo: "otto", Status: "merged"}, events: merged(20)},
+		{pr: &PRDocument{ID: "2", Provider: "ado", Repo: "Otto", Status: "merged"}, events: merged(10)},
+		// Merged before history: the merge is when otto last checked it.
+		{pr: &PRDocument{ID: "3", Provider: "ado", Repo: "otto", Status: "merged", LastChecked: at(30).Format(time.RFC3339)}},
+		{pr: &PRDocument{ID: "4", Provider: "ado", Repo: "otto", Status: "merged"}, events: merged(-5)},
+		{pr: &PRDocument{ID: "5", Provider: "ado", Repo: "otto", Status: "abandoned"}},
+		{pr: &PRDocument{ID: "6", Provider: "ado", Repo: "other", Status: "merged"}, events: merged(20)},
+		{pr: &PRDocument{ID: "7", Provider: "github", Repo: "otto", Status: "merged"}, events: merged(20)},
+	}
+
+	got := mergedPRs(prs, "ado", "otto", at(0), at(100))
+	require.Len(t, got, 3)
+	assert.Equal(t, "2", got[0].PR.ID)
+	assert.Equal(t, "1", got[1].PR.ID)
+	assert.Equal(t, "3", got[2].PR.ID)
+	assert.Equal(t, at(30), got[2].Merged)
+
+	assert.Len(t, mergedPRs(prs, "ado", "", at(0), at(100)), 4, "no repo matches every repository")
+}
+
+func TestReleaseNotesInput(t *testing.T) {
+	p := ReleasePR{
+		PR: &PRDocument{
+			ID: "42", Title: "Add retries", URL: "https://example.com/pr/42", WorkItems: []string{"7", "8"},
+			Body: "# Add retries\n\nRetries 429s.\n\n## Risk analysis\n\n- **Risk**: low\n",
+		},
+		Merged: time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, "Retries 429s.", p.PR.Description())
+
+	in := ReleaseNotesInput([]ReleasePR{p})
+	assert.Contains(t, in, "### #42: Add retries")
+	assert.Contains(t, in, "- Work items: #7, #8")
+	assert.Contains(t, in, "- Merged: 2026-05-02")
+	assert.NotContains(t, in, "Risk analysis")
+
+	assert.Equal(t, "## Changes\n\n- Add retries ([#42](https://example.com/pr/42)) — #7, #8\n", PlainReleaseNotes([]ReleasePR{p}))
+	assert.Contains(t, PlainReleaseNotes(nil), "No merged pull requests.")
+}
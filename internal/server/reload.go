@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/secrets"
+)
+
+// configWatchInterval is how often the daemon checks its config files for
+// changes.
+const configWatchInterval = 10 * time.Second
+
+// configReload signals the monitor loop to reload the config before its
+// next poll.
+var configReload = make(chan struct{}, 1)
+
+// liveConfig is the daemon's current config, for entry points outside the
+// monitor loop (the dashboard's add-PR action).
+var liveConfig atomic.Pointer[config.Config]
+
+// TriggerReload sends a non-blocking signal to the monitor loop to reload
+// the config.
+func TriggerReload() {
+	select {
+	case configReload <- struct{}{}:
+	default:
+		// Already pending.
+	}
+}
+
+// watchConfig triggers a reload on SIGHUP and whenever a config file or
+// the stored secrets change.
+func watchConfig(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	paths := config.Paths()
+	if dir, err := secrets.Dir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "secrets.json"))
+	}
+	last := configStamp(paths)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			slog.Info("SIGHUP received, reloading config")
+			TriggerReload()
+		case <-ticker.C:
+			if stamp := configStamp(paths); stamp != last {
+				last = stamp
+				slog.Info("config changed on disk, reloading")
+				TriggerReload()
+			}
+		}
+	}
+}
+
+// configStamp fingerprints files by size and modification time; missing
+// files count too, so creating one is a change.
+func configStamp(paths []string) string {
+	var b strings.Builder
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&b, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+		} else {
+			fmt.Fprintf(&b, "%s:-;", path)
+		}
+	}
+	return b.String()
+}
+
+// reloadConfig loads the config again and returns a copy of cfg with the
+// sections that take effect without a restart replaced: models, pr, repos,
+// notifications, git, jira, and the server's poll and auth check timing.
+// Ports, the dashboard, and telemetry keep their startup values.
+func reloadConfig(cfg *config.Config, load func(...string) (*config.Config, error)) (*config.Config, error) {
+	fresh, err := load()
+	if err != nil {
+		return nil, err
+	}
+	next := *cfg
+	next.Models = fresh.Models
+	next.PR = fresh.PR
+	next.Repos = fresh.Repos
+	next.Notifications = fresh.Notifications
+	next.Git = fresh.Git
+	next.Jira = fresh.Jira
+	next.Server.PollInterval = fresh.Server.PollInterval
+	next.Server.AuthCheckInterval = fresh.Server.AuthCheckInterval
+	next.Server.AuthExpiryWarning = fresh.Server.AuthExpiryWarning
+	return &next, nil
+}
+
+// startLLMClient creates and starts the LLM client for models. It is a
+// hook for testing.
+var startLLMClient = func(ctx context.Context, models config.ModelsConfig, copilotURL string) (llm.Client, error) {
+	client, err := llm.NewFallbackClient(models, copilotURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Start(ctx); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// stopLLMClient stops client if it can be stopped.
+func stopLLMClient(client llm.Client) {
+	if s, ok := client.(interface{ Stop() error }); ok {
+		if err := s.Stop(); err != nil {
+			slog.Warn("failed to stop LLM client", "error", err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Server.Port = 5000
+	cfg.Server.NoPRMonitoring = true
+	cfg.Dashboard.CopilotServer = "http://localhost:9999"
+
+	fresh := config.DefaultConfig()
+	fresh.Server.PollInterval = "1m"
+	fresh.Server.Port = 6000
+	fresh.Models.Primary = "other-model"
+	fresh.PR.Providers["github"] = config.ProviderConfig{Token: "t"}
+	fresh.Notifications.TeamsWebhookURL = "https://example.com/hook"
+
+	next, err := reloadConfig(&cfg, func(...string) (*config.Config, error) { return &fresh, nil })
+	require.NoError(t, err)
+	assert.Equal(t, "1m", next.Server.PollInterval)
+	assert.Equal(t, "other-model", next.Models.Primary)
+	assert.Equal(t, "t", next.PR.Providers["github"].Token)
+	assert.Equal(t, "https://example.com/hook", next.Notifications.TeamsWebhookURL)
+
+	// Startup and runtime-only settings stay.
+	assert.Equal(t, 5000, next.Server.Port)
+	assert.True(t, next.Server.NoPRMonitoring)
+	assert.Equal(t, "http://localhost:9999", next.Dashboard.CopilotServer)
+	assert.NotEqual(t, "other-model", cfg.Models.Primary, "the running config is not modified")
+}
+
+func TestConfigStamp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "otto.jsonc")
+	paths := []string{path}
+
+	missing := configStamp(paths)
+	require.NoError(t, os.WriteFile(path, []byte(`{}`), 0644))
+	created := configStamp(paths)
+	assert.NotEqual(t, missing, created)
+	assert.Equal(t, created, configStamp(paths))
+
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+	assert.NotEqual(t, created, configStamp(paths))
+}
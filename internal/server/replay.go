@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/cassette"
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+)
+
+// With server.record_cycles, each poll cycle's provider API traffic is
+// recorded, above the conditional request cache so replays see full
+// responses rather than 304s, to a cassette together with the tracked PR documents as they
+// stood when the cycle began, so `otto pr replay` can rerun the cycle
+// offline.
+
+// errReplayLLM stops LLM work during a replay; only provider traffic is
+// recorded.
+var errReplayLLM = errors.New("LLM calls are disabled during replay")
+
+// CassetteDir returns the directory of recorded poll cycles.
+func CassetteDir() string {
+	return filepath.Join(filepath.Dir(PRDir()), "cassettes")
+}
+
+// cycleSnapshot is the cassette metadata for a poll cycle.
+type cycleSnapshot struct {
+	PRs map[string]string `json:"prs"` // PR document file name -> contents
+}
+
+// cycleRecorder records each poll cycle to its own cassette and keeps the
+// newest keep of them. A nil recorder records nothing.
+type cycleRecorder struct {
+	rec  *cassette.Recorder
+	keep int
+}
+
+func newCycleRecorder(keep int) *cycleRecorder {
+	return &cycleRecorder{rec: cassette.NewRecorder(), keep: keep}
+}
+
+// start begins recording a cycle. Failures are logged; the cycle runs
+// either way.
+func (c *cycleRecorder) start() {
+	if c == nil {
+		return
+	}
+	snap, err := snapshotPRs()
+	if err != nil {
+		slog.Warn("failed to snapshot PRs for recording", "error", err)
+		return
+	}
+	path := filepath.Join(CassetteDir(), time.Now().UTC().Format("20060102T150405Z")+".jsonl")
+	if err := c.rec.Start(path, snap); err != nil {
+		slog.Warn("failed to start recording poll cycle", "error", err)
+		return
+	}
+	if err := pruneCassettes(c.keep); err != nil {
+		slog.Warn("failed to prune recorded poll cycles", "error", err)
+	}
+}
+
+func (c *cycleRecorder) stop() {
+	if c == nil {
+		return
+	}
+	if err := c.rec.Stop(); err != nil {
+		slog.Warn("failed to finish recording poll cycle", "error", err)
+	}
+}
+
+// snapshotPRs reads the tracked PR documents.
+func snapshotPRs() (cycleSnapshot, error) {
+	snap := cycleSnapshot{PRs: make(map[string]string)}
+	entries, err := os.ReadDir(PRDir())
+	if os.IsNotExist(err) {
+		return snap, nil
+	}
+	if err != nil {
+		return snap, err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(PRDir(), e.Name()))
+		if err != nil {
+			return snap, err
+		}
+		snap.PRs[e.Name()] = string(data)
+	}
+	return snap, nil
+}
+
+// ListCassettes returns the recorded poll cycles, newest first.
+func ListCassettes() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(CassetteDir(), "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+	slices.Reverse(paths)
+	return paths, nil
+}
+
+// pruneCassettes deletes all but the newest keep recorded cycles.
+func pruneCassettes(keep int) error {
+	paths, err := ListCassettes()
+	if err != nil || len(paths) <= keep {
+		return err
+	}
+	for _, p := range paths[keep:] {
+		if err := os.Remove(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplayResult summarizes a replayed poll cycle.
+type ReplayResult struct {
+	RecordedAt time.Time
+	Recorded   int           // recorded requests
+	Replayed   int           // requests answered from the cassette
+	PRs        []*PRDocument // PR documents after the cycle
+}
+
+// ReplayCycle reruns a recorded poll cycle, answering every provider
+// request from the cassette at path. It runs against a scratch copy of the
+// PR documents recorded with the cycle (XDG_DATA_HOME points at it until
+// ReplayCycle returns) with no repositories, worktree pool, notifications,
+// Jira, or LLM, so nothing outside the scratch directory changes; fixes,
+// conflict resolution, and comment handling fail where they would need a
+// worktree or a model, and the log shows how far they got.
+func ReplayCycle(ctx context.Context, cfg *config.Config, path string) (*ReplayResult, error) {
+	player, err := cassette.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap cycleSnapshot
+	if len(player.Header.Meta) > 0 {
+		if err := json.Unmarshal(player.Header.Meta, &snap); err != nil {
+			return nil, fmt.Errorf("parsing cassette PR snapshot: %w", err)
+		}
+	}
+
+	dataDir, err := os.MkdirTemp("", "otto-replay-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dataDir)
+	prevDataHome, hadDataHome := os.LookupEnv("XDG_DATA_HOME")
+	if err := os.Setenv("XDG_DATA_HOME", dataDir); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if hadDataHome {
+			os.Setenv("XDG_DATA_HOME", prevDataHome)
+		} else {
+			os.Unsetenv("XDG_DATA_HOME")
+		}
+	}()
+	if err := os.MkdirAll(PRDir(), 0755); err != nil {
+		return nil, err
+	}
+	for name, doc := range snap.PRs {
+		if err := os.WriteFile(filepath.Join(PRDir(), filepath.Base(name)), []byte(doc), 0644); err != nil {
+			return nil, fmt.Errorf("restoring PR snapshot: %w", err)
+		}
+	}
+
+	replayCfg := *cfg
+	replayCfg.Repos = nil
+	replayCfg.Notifications = config.NotificationsConfig{}
+	replayCfg.Jira = config.JiraConfig{}
+	replayCfg.PR.WorktreePool.Enabled = false
+	client := llm.NewMockClient()
+	client.CreateErr = errReplayLLM
+
+	replay := func(http.RoundTripper) http.RoundTripper { return player }
+	pollAllPRs(ctx, buildRegistry(&replayCfg, replay, true), client, &replayCfg)
+
+	prs, err := ListPRs()
+	if err != nil {
+		return nil, err
+	}
+	return &ReplayResult{
+		RecordedAt: player.Header.RecordedAt,
+		Recorded:   player.Len(),
+		Replayed:   player.Replays(),
+		PRs:        prs,
+	}, nil
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplayCycle(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	ctx := context.Background()
+
+	requests := 0
+	ado := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pullrequests/42") {
+			json.NewEncoder(w).Encode(map[string]any{"pullRequestId": 42, "title": "Add retries", "status": "completed"})
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	cfg := config.DefaultConfig()
+	cfg.PR.Providers["ado"] = config.ProviderConfig{Organization: "org", Project: "proj", BaseURL: ado.URL}
+	cfg.Server.RecordCycles = 1
+
+	pr := &PRDocument{
+		ID: "42", Provider: "ado", Title: "Add retries", Status: "watching",
+		URL: "https://dev.azure.com/org/proj/_git/repo/pullrequest/42", Branch: "refs/heads/retries", Target: "refs/heads/main",
+	}
+	require.NoError(t, SavePR(pr))
+
+	recorder := newCycleRecorder(cfg.Server.RecordCycles)
+	reg := buildRegistry(&cfg, recorder.rec.Wrap, true)
+	recorder.start()
+	pollAllPRs(ctx, reg, llm.NewMockClient(), &cfg)
+	recorder.stop()
+	ado.Close()
+	require.Positive(t, requests)
+
+	merged, err := LoadPR("ado", "42")
+	require.NoError(t, err)
+	assert.Equal(t, "merged", merged.Status)
+
+	cassettes, err := ListCassettes()
+	require.NoError(t, err)
+	require.Len(t, cassettes, 1)
+
+	// The replay starts from the recorded "watching" document and reaches
+	// the same result with the provider offline.
+	result, err := ReplayCycle(ctx, &cfg, cassettes[0])
+	require.NoError(t, err)
+	assert.Equal(t, requests, result.Recorded)
+	assert.Equal(t, result.Recorded, result.Replayed)
+	require.Len(t, result.PRs, 1)
+	assert.Equal(t, "merged", result.PRs[0].Status)
+	assert.Equal(t, dataHome, os.Getenv("XDG_DATA_HOME"))
+}
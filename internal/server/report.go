@@ -0,0 +1,252 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// archiveRetention is how long reaped PRs are kept for `otto report`.
+const archiveRetention = 180 * 24 * time.Hour
+
+// ArchiveDir returns the directory where reaped PRs' documents and
+// histories are kept for reports.
+func ArchiveDir() string {
+	return filepath.Join(filepath.Dir(PRDir()), "history")
+}
+
+// archivePR copies a PR's document and history into ArchiveDir, so reports
+// still cover it once it is reaped.
+func archivePR(providerName, id string) error {
+	dir := ArchiveDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating archive directory: %w", err)
+	}
+	for _, src := range []string{prPath(providerName, id), prEventsPath(providerName, id)} {
+		data, err := os.ReadFile(src)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(src)), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneArchivedPRs deletes archived files older than archiveRetention.
+func pruneArchivedPRs(now time.Time) {
+	entries, err := os.ReadDir(ArchiveDir())
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || now.Sub(info.ModTime()) < archiveRetention {
+			continue
+		}
+		if err := os.Remove(filepath.Join(ArchiveDir(), entry.Name())); err != nil {
+			slog.Warn("failed to prune archived PR", "file", entry.Name(), "error", err)
+		}
+	}
+}
+
+// Report holds team metrics over a period, built from tracked and
+// archived PRs and their histories.
+type Report struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+
+	PRsOpened    int `json:"prs_opened"`
+	PRsMerged    int `json:"prs_merged"`
+	PRsAbandoned int `json:"prs_abandoned"`
+
+	// PRsGreen counts PRs that first went green in the period, and
+	// MeanTimeToGreen is the mean time from their creation to then.
+	PRsGreen        int           `json:"prs_green"`
+	MeanTimeToGreen time.Duration `json:"mean_time_to_green"`
+
+	// A fix attempt succeeded if the PR's next pipeline outcome was
+	// green, and failed if it was another failure. Attempts still
+	// waiting on a pipeline count toward neither.
+	FixAttempts     int            `json:"fix_attempts"`
+	FixesSucceeded  int            `json:"fixes_succeeded"`
+	FixesFailed     int            `json:"fixes_failed"`
+	FixesByCategory map[string]int `json:"fixes_by_category"`
+	InfraRetries    int            `json:"infra_retries"`
+
+	CommentsHandled  int            `json:"comments_handled"`
+	CommentsResolved int            `json:"comments_resolved"` // resolved by otto: agreed, by design, or won't fix
+	CommentDecisions map[string]int `json:"comment_decisions"`
+}
+
+// FixSuccessRate returns the share of decided fix attempts that turned the
+// pipeline green, or -1 if none were decided.
+func (r *Report) FixSuccessRate() float64 {
+	decided := r.FixesSucceeded + r.FixesFailed
+	if decided == 0 {
+		return -1
+	}
+	return float64(r.FixesSucceeded) / float64(decided)
+}
+
+// InfraFailureShare returns the share of pipeline failures otto handled
+// that were infrastructure failures (retried) rather than code failures
+// (fixed), or -1 if there were none.
+func (r *Report) InfraFailureShare() float64 {
+	total := r.InfraRetries + r.FixAttempts
+	if total == 0 {
+		return -1
+	}
+	return float64(r.InfraRetries) / float64(total)
+}
+
+// reportPR is a PR and its history, as read for a report.
+type reportPR struct {
+	pr     *PRDocument
+	events []PREvent
+}
+
+// BuildReport aggregates the metrics of events between since and until
+// across tracked PRs and those archived when reaped.
+func BuildReport(since, until time.Time) (*Report, error) {
+	prs, err := reportPRs()
+	if err != nil {
+		return nil, err
+	}
+	return buildReport(prs, since, until), nil
+}
+
+// reportPRs reads every tracked and archived PR with its history. A PR in
+// both (tracked again after being reaped) is read from the tracked copy.
+func reportPRs() ([]reportPR, error) {
+	var out []reportPR
+	seen := make(map[string]bool)
+	for _, dir := range []string{PRDir(), ArchiveDir()} {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasSuffix(name, ".md") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			pr, err := readPRDocument(filepath.Join(dir, name))
+			if err != nil {
+				slog.Warn("skipping unreadable PR document", "file", name, "error", err)
+				continue
+			}
+			events, err := readPREventsFile(filepath.Join(dir, strings.TrimSuffix(name, ".md")+".events.jsonl"))
+			if err != nil {
+				slog.Warn("skipping unreadable PR history", "file", name, "error", err)
+			}
+			out = append(out, reportPR{pr: pr, events: events})
+		}
+	}
+	return out, nil
+}
+
+func buildReport(prs []reportPR, since, until time.Time) *Report {
+	r := &Report{
+		Since:            since,
+		Until:            until,
+		FixesByCategory:  make(map[string]int),
+		CommentDecisions: make(map[string]int),
+	}
+	in := func(t time.Time) bool { return !t.Before(since) && t.Before(until) }
+
+	var toGreen time.Duration
+	for _, p := range prs {
+		created, createdErr := time.Parse(time.RFC3339, p.pr.Created)
+		if createdErr == nil && in(created) {
+			r.PRsOpened++
+		}
+
+		wentGreen := false
+		var pending []PREvent // fix attempts awaiting a pipeline outcome
+		decide := func(ok bool) {
+			for _, a := range pending {
+				if !in(a.Time) {
+					continue
+				}
+				if ok {
+					r.FixesSucceeded++
+				} else {
+					r.FixesFailed++
+				}
+			}
+			pending = nil
+		}
+
+		events := append([]PREvent(nil), p.events...)
+		sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+		for _, e := range events {
+			switch e.Kind {
+			case PREventStatus:
+				switch e.To {
+				case "green", "merged":
+					decide(true)
+					if !wentGreen {
+						wentGreen = true
+						if in(e.Time) && createdErr == nil && e.Time.After(created) {
+							r.PRsGreen++
+							toGreen += e.Time.Sub(created)
+						}
+					}
+				case "failed":
+					decide(false)
+				}
+				if in(e.Time) {
+					switch e.To {
+					case "merged":
+						r.PRsMerged++
+					case "abandoned":
+						r.PRsAbandoned++
+					}
+				}
+			case PREventFixAttempt:
+				// A new attempt means the previous one didn't turn the
+				// pipeline green. Per-build fixes share their attempt's
+				// number.
+				if len(pending) > 0 && pending[len(pending)-1].Attempt != e.Attempt {
+					decide(false)
+				}
+				pending = append(pending, e)
+				if in(e.Time) {
+					r.FixAttempts++
+					r.FixesByCategory[e.Category]++
+				}
+			case PREventInfraRetry:
+				if in(e.Time) {
+					r.InfraRetries++
+				}
+			case PREventComment:
+				if in(e.Time) {
+					r.CommentsHandled++
+					decision := strings.ToUpper(e.Decision)
+					r.CommentDecisions[decision]++
+					switch decision {
+					case "AGREE", "BY_DESIGN", "WONT_FIX":
+						r.CommentsResolved++
+					}
+				}
+			}
+		}
+	}
+	if r.PRsGreen > 0 {
+		r.MeanTimeToGreen = toGreen / time.Duration(r.PRsGreen)
+	}
+	return r
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildReport(t *testing.T) {
+	t0 := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return t0.Add(time.Duration(h) * time.Hour) }
+	since, until := at(0), at(100)
+
+	prs := []reportPR{
+		{
+			// Fails, one fix fails, the second goes green, then merges.
+			pr: &PRDocument{ID: "1", Created: at(1).Format(time.RFC3339)},
+			events: []PREvent{
+				{Time: at(2), Kind: PREventStatus, From: "watching", To: "fixing"},
+				{Time: at(3), Kind: PREventFixAttempt, Attempt: 1, Category: "compile"},
+				{Time: at(4), Kind: PREventFixAttempt, Attempt: 2, Category: "test", Build: "unit"},
+				{Time: at(4), Kind: PREventFixAttempt, Attempt: 2, Category: "lint", Build: "lint"},
+				{Time: at(5), Kind: PREventInfraRetry},
+				{Time: at(7), Kind: PREventStatus, From: "watching", To: "green"},
+				{Time: at(8), Kind: PREventComment, Decision: "AGREE"},
+				{Time: at(9), Kind: PREventComment, Decision: "DISAGREE"},
+				{Time: at(10), Kind: PREventStatus, From: "green", To: "merged"},
+			},
+		},
+		{
+			// A fix still awaiting its pipeline, then abandoned.
+			pr: &PRDocument{ID: "2", Created: at(20).Format(time.RFC3339)},
+			events: []PREvent{
+				{Time: at(21), Kind: PREventFixAttempt, Attempt: 1, Category: "lint"},
+				{Time: at(22), Kind: PREventStatus, From: "fixing", To: "abandoned"},
+			},
+		},
+		{
+			// Entirely before the window.
+			pr: &PRDocument{ID: "3", Created: at(-50).Format(time.RFC3339)},
+			events: []PREvent{
+				{Time: at(-49), Kind: PREventFixAttempt, Attempt: 1, Category: "test"},
+				{Time: at(-48), Kind: PREventStatus, From: "watching", To: "green"},
+			},
+		},
+	}
+
+	r := buildReport(prs, since, until)
+	assert.Equal(t, 2, r.PRsOpened)
+	assert.Equal(t, 1, r.PRsMerged)
+	assert.Equal(t, 1, r.PRsAbandoned)
+	assert.Equal(t, 1, r.PRsGreen)
+	assert.Equal(t, 6*time.Hour, r.MeanTimeToGreen)
+
+	assert.Equal(t, 4, r.FixAttempts)
+	assert.Equal(t, 2, r.FixesSucceeded, "both per-build fixes of attempt 2")
+	assert.Equal(t, 1, r.FixesFailed)
+	assert.Equal(t, map[string]int{"compile": 1, "test": 1, "lint": 2}, r.FixesByCategory)
+	assert.InDelta(t, 2.0/3, r.FixSuccessRate(), 1e-9)
+	assert.Equal(t, 1, r.InfraRetries)
+	assert.InDelta(t, 0.2, r.InfraFailureShare(), 1e-9)
+
+	assert.Equal(t, 2, r.CommentsHandled)
+	assert.Equal(t, 1, r.CommentsResolved)
+	assert.Equal(t, map[string]int{"AGREE": 1, "DISAGREE": 1}, r.CommentDecisions)
+
+	empty := buildReport(nil, since, until)
+	assert.Equal(t, -1.0, empty.FixSuccessRate())
+	assert.Equal(t, -1.0, empty.InfraFailureShare())
+}
+
+func TestReportIncludesArchivedPRs(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	created := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	require.NoError(t, SavePR(&PRDocument{ID: "1", Provider: "github", Status: "watching", Created: created}))
+	require.NoError(t, SavePR(&PRDocument{ID: "2", Provider: "github", Status: "watching", Created: created}))
+	_, err := UpdatePR("github", "2", func(p *PRDocument) error {
+		p.Status = "merged"
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Reap PR 2 as the poll loop would.
+	require.NoError(t, archivePR("github", "2"))
+	require.NoError(t, DeletePR("github", "2"))
+
+	prs, err := reportPRs()
+	require.NoError(t, err)
+	require.Len(t, prs, 2)
+
+	r, err := BuildReport(time.Now().Add(-24*time.Hour), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.PRsOpened)
+	assert.Equal(t, 1, r.PRsMerged)
+
+	// Archives past retention are pruned.
+	pruneArchivedPRs(time.Now().Add(archiveRetention + time.Hour))
+	prs, err = reportPRs()
+	require.NoError(t, err)
+	assert.Len(t, prs, 1)
+}
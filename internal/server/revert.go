@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/repo"
+)
+
+// Revert is a pushed branch reverting a merged PR.
+type Revert struct {
+	Branch   string
+	Target   string
+	Commits  []string // the reverted commits, newest first, as "<short sha> <subject>"
+	DiffStat string
+}
+
+// RevertBranch returns the branch otto reverts PR id on.
+func RevertBranch(id string) string {
+	return "revert/pr-" + id
+}
+
+// FindMergedPR finds a merged PR by ID among tracked PRs and those archived
+// when reaped.
+func FindMergedPR(id string) (*PRDocument, error) {
+	prs, err := reportPRs()
+	if err != nil {
+		return nil, err
+	}
+	var matches []*PRDocument
+	for _, p := range prs {
+		if p.pr.ID == id {
+			matches = append(matches, p.pr)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("PR %s not found", id)
+	case 1:
+		if matches[0].Status != "merged" {
+			return nil, fmt.Errorf("PR %s is %s, not merged", id, matches[0].Status)
+		}
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("ambiguous PR ID %s, found in multiple providers; specify provider", id)
+	}
+}
+
+// PrepareRevert pushes RevertBranch(pr.ID), off the latest of pr's target
+// branch, with a commit reverting each of commits: the commit pr merged
+// as, or each of its rebased commits. A merge commit is reverted against
+// its first parent. The work happens in a throwaway worktree; a revert
+// that conflicts with later changes is aborted and nothing is pushed.
+func PrepareRevert(ctx context.Context, pr *PRDocument, cfg *config.Config, commits []string) (*Revert, error) {
+	if pr.Target == "" {
+		return nil, fmt.Errorf("PR #%s has no target branch recorded", pr.ID)
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("PR #%s has no merge commit to revert", pr.ID)
+	}
+	target := strings.TrimPrefix(pr.Target, "refs/heads/")
+	rev := &Revert{Branch: RevertBranch(pr.ID), Target: target}
+
+	workDir, _, cleanup, err := repo.MapPRToCleanWorkDir(cfg, pr.URL, target)
+	if err != nil {
+		return nil, fmt.Errorf("mapping PR to clean workdir: %w", err)
+	}
+	defer cleanup()
+
+	git := func(args ...string) (string, error) {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = workDir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("git %s: %s: %w", args[0], strings.TrimSpace(string(out)), err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", target, target)
+	if _, err := git("fetch", "origin", refspec); err != nil {
+		return nil, err
+	}
+	if _, err := git("checkout", "-q", "--detach", "origin/"+target); err != nil {
+		return nil, err
+	}
+
+	// Newest first, so each revert applies on top of the ones after it.
+	sorted, err := git(append([]string{"rev-list", "--no-walk"}, commits...)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, sha := range strings.Split(sorted, "\n") {
+		parents, err := git("rev-list", "--parents", "-n", "1", sha)
+		if err != nil {
+			return nil, err
+		}
+		args := append(gitIdentityArgs(cfg), "revert", "--no-edit")
+		if len(strings.Fields(parents)) > 2 {
+			args = append(args, "-m", "1")
+		}
+		if _, err := git(append(args, sha)...); err != nil {
+			_, _ = git("revert", "--abort")
+			return nil, fmt.Errorf("reverting %.8s conflicts with later changes on %s; revert it by hand: %w", sha, target, err)
+		}
+		subject, _ := git("log", "-1", "--format=%h %s", sha)
+		rev.Commits = append(rev.Commits, subject)
+	}
+
+	if rev.DiffStat, err = git("diff", "--stat", "origin/"+target+"..HEAD"); err != nil {
+		return nil, err
+	}
+	if err := gitPush(ctx, workDir, rev.Branch); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alanmeadows/otto/internal/config"
+)
+
+func TestPrepareRevert(t *testing.T) {
+	repoDir := newRepoWithRemote(t)
+	origin := filepath.Join(filepath.Dir(repoDir), "origin.git")
+	mainBranch := strings.TrimSpace(gitT(t, repoDir, "rev-parse", "--abbrev-ref", "HEAD"))
+
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0644))
+		gitT(t, repoDir, "add", "-A")
+	}
+	write("app.go", "package app\n\nconst Version = 1\n")
+	gitT(t, repoDir, "commit", "-q", "-m", "add app")
+
+	// The PR merges with a merge commit; another change lands after it.
+	gitT(t, repoDir, "checkout", "-q", "-b", "feature")
+	write("app.go", "package app\n\nconst Version = 2\n")
+	gitT(t, repoDir, "commit", "-q", "-m", "bump version")
+	gitT(t, repoDir, "checkout", "-q", mainBranch)
+	gitT(t, repoDir, "merge", "-q", "--no-ff", "-m", "Merge PR 5", "feature")
+	merge := strings.TrimSpace(gitT(t, repoDir, "rev-parse", "HEAD"))
+	write("notes.txt", "notes\n")
+	gitT(t, repoDir, "commit", "-q", "-m", "add notes")
+	gitT(t, repoDir, "push", "-q", "origin", "HEAD")
+
+	cfg := &config.Config{
+		Repos: []config.RepoConfig{{Name: "app", PrimaryDir: repoDir, GitStrategy: config.GitStrategyBranch}},
+		Git:   config.GitConfig{AuthorName: "otto", AuthorEmail: "otto@example.com"},
+	}
+	pr := &PRDocument{ID: "5", URL: origin, Branch: "refs/heads/feature", Target: "refs/heads/" + mainBranch}
+
+	rev, err := PrepareRevert(context.Background(), pr, cfg, []string{merge})
+	require.NoError(t, err)
+	assert.Equal(t, "revert/pr-5", rev.Branch)
+	assert.Equal(t, mainBranch, rev.Target)
+	require.Len(t, rev.Commits, 1)
+	assert.Contains(t, rev.Commits[0], "Merge PR 5")
+	assert.Contains(t, rev.DiffStat, "app.go")
+
+	// The branch reverts the PR, keeps later changes, and left the
+	// checkout alone.
+	gitT(t, repoDir, "fetch", "-q", "origin")
+	assert.Equal(t, "package app\n\nconst Version = 1\n", gitT(t, repoDir, "show", "origin/revert/pr-5:app.go"))
+	assert.Equal(t, "notes\n", gitT(t, repoDir, "show", "origin/revert/pr-5:notes.txt"))
+	assert.Contains(t, gitT(t, repoDir, "log", "-1", "--format=%an %s", "origin/revert/pr-5"), `otto Revert "Merge PR 5"`)
+	assert.Equal(t, mainBranch, strings.TrimSpace(gitT(t, repoDir, "rev-parse", "--abbrev-ref", "HEAD")))
+
+	_, err = PrepareRevert(context.Background(), pr, cfg, nil)
+	assert.Error(t, err)
+}
+
+func TestFindMergedPR(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	require.NoError(t, SavePR(&PRDocument{ID: "1", Provider: "ado", Status: "merged"}))
+	require.NoError(t, SavePR(&PRDocument{ID: "2", Provider: "ado", Status: "watching"}))
+
+	// A merged PR reaped from tracking is found in the archive.
+	require.NoError(t, SavePR(&PRDocument{ID: "3", Provider: "ado", Status: "merged"}))
+	require.NoError(t, archivePR("ado", "3"))
+	require.NoError(t, DeletePR("ado", "3"))
+
+	pr, err := FindMergedPR("1")
+	require.NoError(t, err)
+	assert.Equal(t, "1", pr.ID)
+	pr, err = FindMergedPR("3")
+	require.NoError(t, err)
+	assert.Equal(t, "3", pr.ID)
+
+	_, err = FindMergedPR("2")
+	assert.ErrorContains(t, err, "not merged")
+	_, err = FindMergedPR("4")
+	assert.ErrorContains(t, err, "not found")
+}
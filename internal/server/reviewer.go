@@ -0,0 +1,305 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/audit"
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/prompts"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/alanmeadows/otto/internal/provider/ado"
+	"github.com/alanmeadows/otto/internal/repo"
+	"github.com/alanmeadows/otto/internal/store"
+)
+
+// ReviewFinding is one issue raised by a pr-review.md review.
+type ReviewFinding struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Body     string `json:"body"`
+}
+
+// reviewedPath records the PRs reviewer mode has already reviewed, so each
+// is reviewed once.
+func reviewedPath() string {
+	return filepath.Join(filepath.Dir(PRDir()), "reviewed.json")
+}
+
+// reviewIncomingPRs reviews the open PRs matched by pr.reviewer.watch rules
+// that otto neither tracks nor has reviewed before, and returns how many
+// it reviewed. Drafts are left until they are published.
+func reviewIncomingPRs(ctx context.Context, reg *provider.Registry, client llm.Client, cfg *config.Config) (int, error) {
+	reviewed := 0
+	for i, rule := range cfg.PR.Reviewer.Watch {
+		providerName := rule.Provider
+		if providerName == "" {
+			providerName = cfg.PR.DefaultProvider
+		}
+		if providerName == "" {
+			providerName = "ado"
+		}
+		if _, paused := authState.paused(providerName); paused {
+			continue
+		}
+		backend, err := reg.Get(providerName)
+		if err != nil {
+			slog.Warn("skipping reviewer watch rule", "rule", i, "provider", providerName, "error", err)
+			continue
+		}
+		backend = audit.WrapBackend(backend)
+
+		authors := rule.Authors
+		if len(authors) == 0 {
+			authors = []string{""}
+		}
+		for _, author := range authors {
+			prs, err := backend.ListPRs(ctx, provider.PRFilter{
+				Author:       author,
+				BranchPrefix: rule.BranchPrefix,
+				Label:        rule.Label,
+				Repo:         rule.Repo,
+			})
+			if err != nil {
+				if errors.Is(err, ado.ErrAuthExpired) {
+					authState.fail(ctx, cfg, providerName, err)
+					return reviewed, err
+				}
+				slog.Warn("failed to list PRs for reviewer watch rule", "rule", i, "author", author, "error", err)
+				continue
+			}
+			for _, info := range prs {
+				if info.IsDraft {
+					continue
+				}
+				if _, err := LoadPR(backend.Name(), info.ID); err == nil {
+					continue // otto's own PR
+				}
+				done, err := isReviewed(backend.Name(), info.ID)
+				if err != nil {
+					return reviewed, err
+				}
+				if done {
+					continue
+				}
+				if err := reviewIncomingPR(ctx, backend, client, cfg, info); err != nil {
+					slog.Warn("failed to review PR", "prID", info.ID, "title", info.Title, "error", err)
+					continue
+				}
+				if err := MarkReviewed(backend.Name(), info.ID); err != nil {
+					return reviewed, err
+				}
+				reviewed++
+			}
+		}
+	}
+	return reviewed, nil
+}
+
+// reviewIncomingPR reviews one PR in a clean worktree of its source branch
+// and submits the result.
+func reviewIncomingPR(ctx context.Context, backend provider.PRBackend, client llm.Client, cfg *config.Config, info *provider.PRInfo) error {
+	ctx, cancel := context.WithTimeout(ctx, cfg.PR.ParseFixTimeout())
+	defer cancel()
+
+	workDir, _, cleanup, err := repo.MapPRToCleanWorkDir(cfg, info.URL, info.SourceBranch)
+	if err != nil {
+		return fmt.Errorf("mapping PR to work dir: %w", err)
+	}
+	defer cleanup()
+
+	slog.Info("reviewing PR", "prID", info.ID, "title", info.Title, "author", info.Author)
+	findings, err := ReviewPR(ctx, client, cfg, info, workDir)
+	if err != nil {
+		return err
+	}
+	posted, verdict, err := SubmitReviewerReview(ctx, backend, info, findings, cfg.PR.Reviewer, cfg.PR.DisableAIFooter)
+	if err != nil {
+		return err
+	}
+	slog.Info("submitted review", "prID", info.ID, "findings", len(findings), "posted", posted, "verdict", verdict)
+	return nil
+}
+
+// ReviewPR runs the pr-review.md prompt against the PR checked out in
+// workDir and returns its findings.
+func ReviewPR(ctx context.Context, client llm.Client, cfg *config.Config, info *provider.PRInfo, workDir string) ([]ReviewFinding, error) {
+	data := map[string]string{
+		"pr_title":       info.Title,
+		"pr_description": info.Description,
+		"target_branch":  info.TargetBranch,
+		"guidance":       cfg.PR.Reviewer.Guidance,
+		"related_code":   relatedCode(ctx, cfg, info.URL, workDir, info.Title+"\n\n"+info.Description),
+	}
+	if summary, err := repo.AnalyzeCodebase(workDir); err != nil {
+		slog.Warn("codebase analysis failed, continuing without summary", "error", err)
+	} else if summary != nil {
+		data["codebase_summary"] = summary.String()
+	}
+	prompt, err := prompts.Execute("pr-review.md", data)
+	if err != nil {
+		return nil, fmt.Errorf("building review prompt: %w", err)
+	}
+
+	session, err := client.CreateSession(ctx, fmt.Sprintf("PR Review #%s", info.ID), workDir)
+	if err != nil {
+		return nil, fmt.Errorf("creating review session: %w", err)
+	}
+	defer client.DeleteSession(ctx, session.ID)
+
+	resp, err := client.SendPrompt(ctx, session.ID, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("review prompt failed: %w", err)
+	}
+	findings, err := llm.ParseJSONResponse[[]ReviewFinding](ctx, client, session.ID, resp.Content)
+	if err != nil {
+		return nil, fmt.Errorf("parsing review response: %w", err)
+	}
+	return findings, nil
+}
+
+// severityRank orders severities by config.ReviewSeverities, most serious
+// first. Unknown severities rank as warnings.
+func severityRank(severity string) int {
+	if i := slices.Index(config.ReviewSeverities, strings.ToLower(severity)); i >= 0 {
+		return i
+	}
+	return slices.Index(config.ReviewSeverities, "warning")
+}
+
+// ReviewVerdict returns the verdict rc calls for: request changes when a
+// finding reaches the blocking severity, approve when none does, each only
+// if enabled, and a plain comment otherwise.
+func ReviewVerdict(findings []ReviewFinding, rc config.ReviewerConfig) provider.ReviewVerdict {
+	blocking := rc.BlockingSeverity
+	if blocking == "" {
+		blocking = "error"
+	}
+	blocked := slices.ContainsFunc(findings, func(f ReviewFinding) bool {
+		return severityRank(f.Severity) <= severityRank(blocking)
+	})
+	switch {
+	case blocked && rc.RequestChanges:
+		return provider.ReviewRequestChanges
+	case !blocked && rc.Approve:
+		return provider.ReviewApprove
+	default:
+		return provider.ReviewComment
+	}
+}
+
+// SubmitReviewerReview posts the findings at or above rc.MinSeverity as
+// inline comments, then submits the verdict with a summary. It returns how
+// many comments were posted; a comment that fails to post is logged and
+// skipped.
+func SubmitReviewerReview(ctx context.Context, backend provider.PRBackend, info *provider.PRInfo, findings []ReviewFinding, rc config.ReviewerConfig, disableFooter bool) (int, provider.ReviewVerdict, error) {
+	minSeverity := rc.MinSeverity
+	if minSeverity == "" {
+		minSeverity = "warning"
+	}
+
+	posted := 0
+	for _, f := range findings {
+		if severityRank(f.Severity) > severityRank(minSeverity) {
+			continue
+		}
+		body := f.Body
+		if !disableFooter {
+			body += provider.AIFooter
+		}
+		inline := provider.InlineComment{FilePath: f.File, Line: f.Line, Body: body, Side: "right"}
+		if err := backend.PostInlineComment(ctx, info, inline); err != nil {
+			slog.Warn("failed to post review comment", "prID", info.ID, "file", f.File, "line", f.Line, "error", err)
+			continue
+		}
+		posted++
+	}
+
+	verdict := ReviewVerdict(findings, rc)
+	body := reviewSummary(findings, posted)
+	if !disableFooter {
+		body += provider.AIFooter
+	}
+	if err := backend.SubmitReview(ctx, info, verdict, body); err != nil {
+		return posted, verdict, fmt.Errorf("submitting review: %w", err)
+	}
+	return posted, verdict, nil
+}
+
+// reviewSummary describes a review's findings for the review body.
+func reviewSummary(findings []ReviewFinding, posted int) string {
+	if len(findings) == 0 {
+		return "**otto review**: no issues found."
+	}
+	counts := make([]int, len(config.ReviewSeverities))
+	for _, f := range findings {
+		counts[severityRank(f.Severity)]++
+	}
+	var parts []string
+	for i, n := range counts {
+		if n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, config.ReviewSeverities[i]))
+		}
+	}
+	return fmt.Sprintf("**otto review**: %s; %d posted as inline comments.", strings.Join(parts, ", "), posted)
+}
+
+// isReviewed reports whether the PR has already been reviewed in reviewer
+// mode.
+func isReviewed(providerName, prID string) (bool, error) {
+	var done bool
+	err := store.WithReadLock(reviewedPath(), store.DefaultLockTimeout, func() error {
+		db, err := readReviewedDB()
+		_, done = db[providerName+"/"+prID]
+		return err
+	})
+	return done, err
+}
+
+// MarkReviewed records that the PR has been reviewed in reviewer mode, so
+// the daemon does not review it again.
+func MarkReviewed(providerName, prID string) error {
+	path := reviewedPath()
+	return store.WithLock(path, store.DefaultLockTimeout, func() error {
+		db, err := readReviewedDB()
+		if err != nil {
+			return err
+		}
+		db[providerName+"/"+prID] = time.Now().UTC()
+		data, err := json.MarshalIndent(db, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling reviewed PRs: %w", err)
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return fmt.Errorf("writing reviewed PRs: %w", err)
+		}
+		return os.Rename(tmp, path)
+	})
+}
+
+// readReviewedDB loads the reviewed-PR record. A missing file is empty.
+func readReviewedDB() (map[string]time.Time, error) {
+	db := make(map[string]time.Time)
+	data, err := os.ReadFile(reviewedPath())
+	if os.IsNotExist(err) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading reviewed PRs: %w", err)
+	}
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, fmt.Errorf("parsing reviewed PRs: %w", err)
+	}
+	return db, nil
+}
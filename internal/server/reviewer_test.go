@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reviewBackend records inline comments and submitted reviews; other calls panic.
+type reviewBackend struct {
+	provider.PRBackend
+	inline  []provider.InlineComment
+	verdict provider.ReviewVerdict
+	body    string
+}
+
+func (b *reviewBackend) PostInlineComment(ctx context.Context, pr *provider.PRInfo, comment provider.InlineComment) error {
+	b.inline = append(b.inline, comment)
+	return nil
+}
+
+func (b *reviewBackend) SubmitReview(ctx context.Context, pr *provider.PRInfo, verdict provider.ReviewVerdict, body string) error {
+	b.verdict, b.body = verdict, body
+	return nil
+}
+
+func TestReviewVerdict(t *testing.T) {
+	warning := []ReviewFinding{{Severity: "warning"}}
+	bug := []ReviewFinding{{Severity: "nitpick"}, {Severity: "error"}}
+
+	rc := config.ReviewerConfig{}
+	assert.Equal(t, provider.ReviewComment, ReviewVerdict(bug, rc), "votes are opt-in")
+
+	rc = config.ReviewerConfig{Approve: true, RequestChanges: true}
+	assert.Equal(t, provider.ReviewApprove, ReviewVerdict(nil, rc))
+	assert.Equal(t, provider.ReviewApprove, ReviewVerdict(warning, rc), "warnings do not block by default")
+	assert.Equal(t, provider.ReviewRequestChanges, ReviewVerdict(bug, rc))
+
+	rc.BlockingSeverity = "warning"
+	assert.Equal(t, provider.ReviewRequestChanges, ReviewVerdict(warning, rc))
+	assert.Equal(t, provider.ReviewRequestChanges, ReviewVerdict([]ReviewFinding{{Severity: "odd"}}, rc), "unknown severities count as warnings")
+}
+
+func TestSubmitReviewerReview(t *testing.T) {
+	findings := []ReviewFinding{
+		{File: "main.go", Line: 10, Severity: "error", Body: "nil dereference"},
+		{File: "main.go", Line: 20, Severity: "nitpick", Body: "typo"},
+	}
+	backend := &reviewBackend{}
+	rc := config.ReviewerConfig{MinSeverity: "warning", RequestChanges: true}
+
+	posted, verdict, err := SubmitReviewerReview(context.Background(), backend, &provider.PRInfo{ID: "7"}, findings, rc, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, posted, "nitpicks are below min_severity")
+	assert.Equal(t, provider.ReviewRequestChanges, verdict)
+	assert.Equal(t, []provider.InlineComment{{FilePath: "main.go", Line: 10, Body: "nil dereference", Side: "right"}}, backend.inline)
+	assert.Equal(t, provider.ReviewRequestChanges, backend.verdict)
+	assert.Equal(t, "**otto review**: 1 error, 1 nitpick; 1 posted as inline comments.", backend.body)
+}
+
+func TestReviewedPRs(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	done, err := isReviewed("github", "42")
+	require.NoError(t, err)
+	assert.False(t, done)
+
+	require.NoError(t, MarkReviewed("github", "42"))
+	done, err = isReviewed("github", "42")
+	require.NoError(t, err)
+	assert.True(t, done)
+
+	done, err = isReviewed("ado", "42")
+	require.NoError(t, err)
+	assert.False(t, done)
+}
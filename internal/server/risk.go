@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/policy"
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// AnalyzeNewPR runs the risk analysis (pr.risk_analysis) of a PR otto
+// starts tracking on add or submit. pr.Risk gets the summary and pr's body
+// the full report, and a pr_tracked notification carries it; the caller
+// saves pr. report is the analysis when the caller has one already, as
+// submit does to put it in the PR description; otherwise the PR's diff is
+// fetched from backend. A PR whose diff can't be fetched is tracked
+// without an analysis.
+func AnalyzeNewPR(ctx context.Context, cfg *config.Config, backend provider.PRBackend, info *provider.PRInfo, pr *PRDocument, report *policy.RiskReport) *policy.RiskReport {
+	if cfg == nil || !cfg.PR.RiskAnalysis {
+		return nil
+	}
+	if report == nil {
+		diff, err := backend.GetDiff(ctx, info)
+		if err != nil {
+			slog.Warn("failed to fetch PR diff for risk analysis", "prID", pr.ID, "error", err)
+			return nil
+		}
+		report = policy.AnalyzeRisk(diff)
+	}
+
+	pr.Risk = report.Summary()
+	if md := report.Markdown(); !strings.Contains(pr.Body, md) {
+		pr.Body = strings.TrimRight(pr.Body, "\n") + "\n\n" + md
+	}
+	slog.Info("PR risk analysis", "prID", pr.ID, "risk", pr.Risk)
+
+	if err := Notify(ctx, &cfg.Notifications, riskNotification(pr, report)); err != nil {
+		slog.Warn("failed to send PR tracked notification", "prID", pr.ID, "error", err)
+	}
+	return report
+}
+
+// riskNotification is the pr_tracked notification for pr and its report.
+func riskNotification(pr *PRDocument, report *policy.RiskReport) NotificationPayload {
+	extra := map[string]string{
+		"Risk": report.Level(),
+		"Size": fmt.Sprintf("%s (+%d/-%d lines, %d files)", report.Size(), report.Additions, report.Deletions, report.Files),
+	}
+	if len(report.Areas) > 0 {
+		areas := make([]string, len(report.Areas))
+		for i, a := range report.Areas {
+			areas[i] = a.Dir
+		}
+		extra["Areas"] = strings.Join(areas, ", ")
+	}
+	if len(report.Risks) > 0 {
+		risks := make([]string, len(report.Risks))
+		for i, r := range report.Risks {
+			risks[i] = r.Kind + " (" + r.File + ")"
+		}
+		extra["Risky Changes"] = strings.Join(risks, ", ")
+	}
+	if len(report.Untested) > 0 {
+		extra["Untested Files"] = strings.Join(report.Untested, ", ")
+	}
+	return NotificationPayload{
+		Event:  EventPRTracked,
+		Title:  pr.Title,
+		URL:    pr.URL,
+		Status: pr.Status,
+		Extra:  extra,
+	}
+}
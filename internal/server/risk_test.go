@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/policy"
+	"github.com/alanmeadows/otto/internal/provider"
+)
+
+// diffBackend is a backend serving a fixed PR diff.
+type diffBackend struct {
+	provider.PRBackend
+	diff  string
+	calls int
+}
+
+func (b *diffBackend) GetDiff(context.Context, *provider.PRInfo) (string, error) {
+	b.calls++
+	return b.diff, nil
+}
+
+func TestAnalyzeNewPR(t *testing.T) {
+	defaults := config.DefaultConfig()
+	cfg := &defaults
+	backend := &diffBackend{diff: strings.Join([]string{
+		"diff --git a/db/migrations/1.sql b/db/migrations/1.sql",
+		"--- /dev/null",
+		"+++ b/db/migrations/1.sql",
+		"@@ -0,0 +1 @@",
+		"+DROP TABLE users;",
+	}, "\n")}
+
+	pr := &PRDocument{ID: "1", Title: "Drop users", Body: "# Drop users\n\nGone.\n"}
+	report := AnalyzeNewPR(context.Background(), cfg, backend, &provider.PRInfo{ID: "1"}, pr, nil)
+	if assert.NotNil(t, report) {
+		assert.Equal(t, report.Summary(), pr.Risk)
+		assert.Contains(t, pr.Risk, "database migration")
+		assert.True(t, strings.HasSuffix(pr.Body, report.Markdown()))
+	}
+
+	n := riskNotification(pr, report)
+	assert.Equal(t, EventPRTracked, n.Event)
+	assert.Equal(t, "database migration (db/migrations/1.sql)", n.Extra["Risky Changes"])
+
+	// A report the caller already has (submit's) is used as is, and not
+	// appended twice to a body that has it from the PR description.
+	given := policy.AnalyzeRisk("")
+	pr = &PRDocument{ID: "2", Body: "# x\n\n" + given.Markdown()}
+	AnalyzeNewPR(context.Background(), cfg, backend, &provider.PRInfo{ID: "2"}, pr, given)
+	assert.Equal(t, 1, backend.calls)
+	assert.Equal(t, 1, strings.Count(pr.Body, "## Risk analysis"))
+
+	cfg.PR.RiskAnalysis = false
+	pr = &PRDocument{ID: "3"}
+	assert.Nil(t, AnalyzeNewPR(context.Background(), cfg, backend, &provider.PRInfo{ID: "3"}, pr, nil))
+	assert.Empty(t, pr.Risk)
+}
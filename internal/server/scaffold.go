@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/prompts"
+	"github.com/alanmeadows/otto/internal/scaffold"
+)
+
+// NewProjectBranch returns the branch otto new pushes the project name to.
+func NewProjectBranch(name string) string {
+	return "otto/new/" + name
+}
+
+// ScaffoldProject has the LLM create project p in rc from tmpl, in a
+// throwaway worktree off target (default: the repo's default branch),
+// runs the repo's checks, and pushes the result to NewProjectBranch. It
+// returns nil when the LLM created nothing.
+func ScaffoldProject(ctx context.Context, cfg *config.Config, client llm.Client, rc *config.RepoConfig, tmpl *scaffold.Template, p scaffold.Project, target string) (*ChangeBranch, error) {
+	remote, err := gitOutput(ctx, rc.PrimaryDir, "remote", "get-url", "origin")
+	if err != nil {
+		return nil, err
+	}
+	remote = strings.TrimSpace(remote)
+	if p.Module == "" {
+		p.Module = scaffold.ModulePath(remote, p.Path)
+	}
+	body, err := tmpl.Render(p)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := prompts.Execute("new-project.md", map[string]string{
+		"Kind":     p.Kind,
+		"Name":     p.Name,
+		"Repo":     rc.Name,
+		"Path":     p.Path,
+		"Template": body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("building scaffold prompt: %w", err)
+	}
+	message := fmt.Sprintf("feat: scaffold %s %s from %s", p.Kind, p.Name, tmpl.Name)
+	return pushLLMChange(ctx, cfg, client, rc, remote, target, NewProjectBranch(p.Name), message,
+		fmt.Sprintf("New %s: %s", p.Kind, p.Name), prompt)
+}
+
+// NewProjectDescription describes the PR for a scaffolded project.
+func NewProjectDescription(tmpl *scaffold.Template, p scaffold.Project, cb *ChangeBranch) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Scaffolds the %s `%s` in `%s` from the `%s` template (%s).\n\n", p.Kind, p.Name, p.Path, tmpl.Name, tmpl.Description)
+	if p.Description != "" {
+		fmt.Fprintf(&s, "%s\n\n", p.Description)
+	}
+	fmt.Fprintf(&s, "## Files\n\n```\n%s\n```\n", cb.DiffStat)
+	if cb.Failed {
+		fmt.Fprintf(&s, "\n## Failing checks\n\nThe repo's checks failed on the new project:\n\n%s\n", strings.TrimSpace(cb.Output))
+	}
+	return s.String()
+}
+
+// InitRepository clones the empty repository at remote into dir and
+// pushes an initial commit, a README titled name, to branch, so the
+// scaffolded project can be opened as a PR against it.
+func InitRepository(ctx context.Context, cfg *config.Config, remote, dir, name, branch string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("%s already exists", dir)
+	}
+	if out, err := exec.CommandContext(ctx, "git", "clone", "-q", remote, dir).CombinedOutput(); err != nil {
+		return fmt.Errorf("git clone: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	if _, err := gitOutput(ctx, dir, "checkout", "-q", "-B", branch); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# "+name+"\n"), 0644); err != nil {
+		return err
+	}
+	if _, err := gitCommit(ctx, cfg, dir, "Initial commit"); err != nil {
+		return err
+	}
+	if _, err := gitOutput(ctx, dir, "push", "-q", "-u", "origin", branch); err != nil {
+		return err
+	}
+	// Later fetches from the clone look for the default branch here.
+	_, _ = gitOutput(ctx, dir, "remote", "set-head", "origin", branch)
+	return nil
+}
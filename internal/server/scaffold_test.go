@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/scaffold"
+)
+
+func TestScaffoldProject(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	origin := filepath.Join(base, "billing.git")
+	gitT(t, base, "init", "-q", "--bare", origin)
+	dir := filepath.Join(base, "billing")
+
+	cfg := &config.Config{Git: config.GitConfig{AuthorName: "otto", AuthorEmail: "otto@example.com"}}
+	require.NoError(t, InitRepository(ctx, cfg, origin, dir, "billing", "main"))
+	assert.Equal(t, "# billing\n", gitT(t, dir, "show", "origin/main:README.md"))
+	assert.ErrorContains(t, InitRepository(ctx, cfg, origin, dir, "billing", "main"), "already exists")
+
+	// The mock LLM writes nothing; a check stands in for its files.
+	cfg.Repos = []config.RepoConfig{{Name: "billing", PrimaryDir: dir, GitStrategy: config.GitStrategyBranch,
+		Checks: []string{"mkdir -p cmd/billing && echo 'package main' > cmd/billing/main.go"}}}
+	client := llm.NewMockClient()
+	tmpl, err := scaffold.Load("go-service")
+	require.NoError(t, err)
+	p := scaffold.Project{Kind: "service", Name: "billing", Path: "."}
+
+	cb, err := ScaffoldProject(ctx, cfg, client, &cfg.Repos[0], tmpl, p, "")
+	require.NoError(t, err)
+	require.NotNil(t, cb)
+	assert.Equal(t, "otto/new/billing", cb.Branch)
+	assert.Equal(t, "main", cb.Target, "the new repo's default branch")
+	assert.Equal(t, []string{"cmd/billing/main.go"}, cb.Files)
+	assert.False(t, cb.Failed)
+
+	require.Len(t, client.PromptHistory, 1)
+	prompt := client.PromptHistory[0].Prompt
+	assert.Contains(t, prompt, "new service named billing in the billing repository")
+	assert.Contains(t, prompt, "module `"+scaffold.ModulePath(origin, ".")+"`")
+	assert.Contains(t, NewProjectDescription(tmpl, p, cb), "`go-service` template")
+}
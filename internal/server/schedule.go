@@ -0,0 +1,533 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/audit"
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/cron"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/policy"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/alanmeadows/otto/internal/provider/ado"
+	ghbackend "github.com/alanmeadows/otto/internal/provider/github"
+	"github.com/alanmeadows/otto/internal/repo"
+	"github.com/alanmeadows/otto/internal/store"
+)
+
+// scheduleCheckInterval is how often the daemon looks for scheduled tasks
+// that have come due; schedules have minute resolution.
+const scheduleCheckInterval = time.Minute
+
+// TaskRun records a scheduled task's most recent run.
+type TaskRun struct {
+	// Since is when the task's schedule is next counted from: its last
+	// run, or when the daemon first saw the task.
+	Since    time.Time `json:"since"`
+	Started  time.Time `json:"started,omitempty"`
+	Finished time.Time `json:"finished,omitempty"`
+	Result   string    `json:"result,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	PRURL    string    `json:"pr_url,omitempty"`
+}
+
+// ScheduledTaskStatus is a configured task with its last run and next
+// due time, for `otto schedule list`.
+type ScheduledTaskStatus struct {
+	config.ScheduledTask
+	Last *TaskRun  `json:"last,omitempty"`
+	Next time.Time `json:"next,omitempty"`
+}
+
+// ChangeBranch is a pushed branch with the changes otto made for a
+// scheduled task, a batch, or a new project.
+type ChangeBranch struct {
+	Branch   string
+	Target   string
+	Files    []string
+	DiffStat string
+	// Output is a command task's output, or the failed checks of an LLM's
+	// change, kept when it failed.
+	Output string
+	Failed bool
+}
+
+// schedulePath records the scheduled tasks' runs.
+func schedulePath() string {
+	return filepath.Join(filepath.Dir(PRDir()), "schedule.json")
+}
+
+// runDueTasks runs the enabled tasks in cfg.Schedules whose schedule has
+// fired since their last run. A task is run once however many times it
+// fired while the daemon was down. Tasks the daemon has not seen before
+// are first counted from now, so adding one does not run it at once.
+func runDueTasks(ctx context.Context, reg *provider.Registry, client llm.Client, cfg *config.Config, now time.Time) {
+	if len(cfg.Schedules) == 0 {
+		return
+	}
+	runs, err := loadTaskRuns()
+	if err != nil {
+		slog.Error("failed to read scheduled task runs", "error", err)
+		return
+	}
+	for _, task := range dueTasks(cfg.Schedules, runs, now) {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, seen := runs[task.Name]; !seen {
+			if err := updateTaskRun(task.Name, func(r *TaskRun) { r.Since = now }); err != nil {
+				slog.Error("failed to record scheduled task", "task", task.Name, "error", err)
+			}
+			continue
+		}
+		runScheduledTask(ctx, reg, client, cfg, task, now)
+	}
+}
+
+// dueTasks returns the enabled tasks that have come due by now, and those
+// not yet in runs.
+func dueTasks(tasks []config.ScheduledTask, runs map[string]*TaskRun, now time.Time) []config.ScheduledTask {
+	var due []config.ScheduledTask
+	for _, task := range tasks {
+		if task.Disabled {
+			continue
+		}
+		run, seen := runs[task.Name]
+		if !seen {
+			due = append(due, task)
+			continue
+		}
+		sched, err := cron.Parse(task.Schedule)
+		if err != nil {
+			continue // rejected when the config was loaded
+		}
+		if next := sched.Next(run.Since.In(now.Location())); !next.IsZero() && !next.After(now) {
+			due = append(due, task)
+		}
+	}
+	return due
+}
+
+// runScheduledTask runs task, records the run, and notifies the result.
+func runScheduledTask(ctx context.Context, reg *provider.Registry, client llm.Client, cfg *config.Config, task config.ScheduledTask, now time.Time) {
+	// The run is counted before it starts, so a task that crashes the
+	// daemon is not retried on every restart.
+	if err := updateTaskRun(task.Name, func(r *TaskRun) {
+		*r = TaskRun{Since: now, Started: time.Now().UTC()}
+	}); err != nil {
+		slog.Error("failed to record scheduled task run", "task", task.Name, "error", err)
+		return
+	}
+
+	slog.Info("running scheduled task", "task", task.Name, "action", task.Action)
+	var result, prURL string
+	var err error
+	switch task.Action {
+	case config.TaskRebase:
+		result, err = runRebaseTask(ctx, reg, client, cfg, task)
+	case config.TaskCommand:
+		result, prURL, err = runCommandTask(ctx, cfg, task, now)
+	default:
+		err = fmt.Errorf("unknown action %q", task.Action)
+	}
+
+	if err := updateTaskRun(task.Name, func(r *TaskRun) {
+		r.Finished = time.Now().UTC()
+		r.Result = result
+		r.PRURL = prURL
+		if err != nil {
+			r.Error = err.Error()
+		}
+	}); err != nil {
+		slog.Error("failed to record scheduled task result", "task", task.Name, "error", err)
+	}
+
+	payload := NotificationPayload{
+		Event:  EventTaskCompleted,
+		Title:  "Scheduled task " + task.Name,
+		URL:    prURL,
+		Status: "completed",
+		Extra:  map[string]string{"Action": task.Action, "Schedule": task.Schedule},
+	}
+	if result != "" {
+		payload.Extra["Result"] = result
+	}
+	if err != nil {
+		slog.Error("scheduled task failed", "task", task.Name, "result", result, "error", err)
+		payload.Event = EventTaskFailed
+		payload.Status = "failed"
+		payload.Error = err.Error()
+	} else {
+		slog.Info("scheduled task finished", "task", task.Name, "result", result, "url", prURL)
+	}
+	if err := Notify(ctx, &cfg.Notifications, payload); err != nil {
+		slog.Warn("failed to send scheduled task notification", "task", task.Name, "error", err)
+	}
+}
+
+// runRebaseTask rebases the open PRs otto tracks (in task.Repo, if set)
+// onto their target branches. Conflicts are resolved as for a conflicted
+// PR; PRs already up to date are left alone.
+func runRebaseTask(ctx context.Context, reg *provider.Registry, client llm.Client, cfg *config.Config, task config.ScheduledTask) (string, error) {
+	prs, err := ListPRs()
+	if err != nil {
+		return "", err
+	}
+	mgr := repo.NewManager("")
+	rebased, current := 0, 0
+	var failed []string
+	for _, pr := range prs {
+		if ctx.Err() != nil {
+			break
+		}
+		switch pr.Status {
+		case "merged", "abandoned", "fixing":
+			continue
+		}
+		if pr.Paused {
+			continue
+		}
+		if _, paused := authState.paused(pr.Provider); paused {
+			continue
+		}
+		if task.Repo != "" {
+			if rc, err := mgr.FindByRemoteURL(cfg, pr.URL); err != nil || rc.Name != task.Repo {
+				continue
+			}
+		}
+		backend, err := reg.Get(pr.Provider)
+		if err != nil {
+			slog.Warn("scheduled rebase skipped PR", "task", task.Name, "prID", pr.ID, "error", err)
+			failed = append(failed, "#"+pr.ID)
+			continue
+		}
+
+		pushed := pr.PushedCommit
+		if err := ResolveConflicts(ctx, pr, backend, client, cfg); err != nil {
+			slog.Warn("scheduled rebase failed", "task", task.Name, "prID", pr.ID, "error", err)
+			failed = append(failed, "#"+pr.ID)
+			continue
+		}
+		if pr.PushedCommit == pushed {
+			current++
+			continue
+		}
+		rebased++
+		logPREvent(pr, PREvent{
+			Kind:   PREventScheduled,
+			Commit: pr.PushedCommit,
+			Detail: fmt.Sprintf("%s: rebased onto %s", task.Name, strings.TrimPrefix(pr.Target, "refs/heads/")),
+		})
+	}
+
+	result := fmt.Sprintf("%d rebased, %d up to date", rebased, current)
+	if len(failed) > 0 {
+		return result, fmt.Errorf("could not rebase %s", strings.Join(failed, ", "))
+	}
+	return result, nil
+}
+
+// runCommandTask runs the task's command on a fresh branch of its repo
+// and, if that changed anything, opens a PR that otto then tracks like
+// any other.
+func runCommandTask(ctx context.Context, cfg *config.Config, task config.ScheduledTask, now time.Time) (string, string, error) {
+	var rc *config.RepoConfig
+	for i := range cfg.Repos {
+		if cfg.Repos[i].Name == task.Repo {
+			rc = &cfg.Repos[i]
+		}
+	}
+	if rc == nil {
+		return "", "", fmt.Errorf("repo %q is not configured (see otto repo add)", task.Repo)
+	}
+	remote, err := gitOutput(ctx, rc.PrimaryDir, "remote", "get-url", "origin")
+	if err != nil {
+		return "", "", err
+	}
+	remote = strings.TrimSpace(remote)
+
+	branch, err := PrepareTaskBranch(ctx, cfg, remote, task, now)
+	if err != nil {
+		return "", "", err
+	}
+	if branch == nil {
+		return "no changes", "", nil
+	}
+
+	backend, err := RepoBackend(cfg, remote)
+	if err != nil {
+		return "", "", err
+	}
+	title := task.Title
+	if title == "" {
+		title = "chore: " + task.Name
+	}
+	info, err := backend.CreatePR(ctx, provider.CreatePRParams{
+		Title:        title,
+		Description:  taskPRDescription(task, branch),
+		SourceBranch: branch.Branch,
+		TargetBranch: branch.Target,
+		Labels:       policy.Labels(cfg.PR.Labels, branch.Files),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("creating PR: %w", err)
+	}
+
+	pr := NewTrackedPR(info, backend.Name(), cfg)
+	AnalyzeNewPR(ctx, cfg, backend, info, pr, nil)
+	if err := SavePR(pr); err != nil {
+		return "", info.URL, fmt.Errorf("tracking PR #%s: %w", info.ID, err)
+	}
+	logPREvent(pr, PREvent{Kind: PREventScheduled, Detail: task.Name + ": opened by `" + task.Command + "`"})
+
+	result := fmt.Sprintf("opened PR #%s (%d files)", info.ID, len(branch.Files))
+	if branch.Failed {
+		result += "; the command failed"
+	}
+	return result, info.URL, nil
+}
+
+// PrepareTaskBranch runs task.Command in a throwaway worktree of the repo
+// at remote, off the latest of the task's target branch, and pushes what
+// it changed to a new branch, otto/<name>-<date>. It returns nil when the
+// command changed nothing. A command that fails but still changes files
+// (a linter fixing what it can) has its changes pushed, with its output.
+func PrepareTaskBranch(ctx context.Context, cfg *config.Config, remote string, task config.ScheduledTask, now time.Time) (*ChangeBranch, error) {
+	var check repo.CheckResult
+	branch := fmt.Sprintf("otto/%s-%s", task.Name, now.Format("20060102-1504"))
+	message := fmt.Sprintf("chore: %s\n\nScheduled task: %s", task.Name, task.Command)
+	cb, err := PushChangeBranch(ctx, cfg, remote, task.Target, branch, message, func(workDir string) error {
+		check = repo.RunChecks(ctx, workDir, []string{task.Command})[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cb == nil {
+		if !check.Passed {
+			return nil, fmt.Errorf("command failed: %s", strings.TrimSpace(check.Output))
+		}
+		return nil, nil
+	}
+	if !check.Passed {
+		cb.Failed = true
+		cb.Output = check.Output
+	}
+	return cb, nil
+}
+
+// PushChangeBranch checks out the latest of target (default: the remote's
+// default branch) in a throwaway worktree of the repo at remote, lets
+// change edit it, and pushes what changed as one commit on branch. It
+// returns nil, pushing nothing, when change changed nothing, and an error
+// when change fails or its changes look like they contain a secret.
+func PushChangeBranch(ctx context.Context, cfg *config.Config, remote, target, branch, message string, change func(workDir string) error) (*ChangeBranch, error) {
+	if target == "" {
+		target = defaultBranch(ctx, cfg, remote)
+	}
+	cb := &ChangeBranch{Branch: branch, Target: strings.TrimPrefix(target, "refs/heads/")}
+
+	workDir, _, cleanup, err := repo.MapPRToCleanWorkDir(cfg, remote, cb.Target)
+	if err != nil {
+		return nil, fmt.Errorf("mapping repo to clean workdir: %w", err)
+	}
+	defer cleanup()
+
+	git := func(args ...string) (string, error) {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = workDir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("git %s: %s: %w", args[0], strings.TrimSpace(string(out)), err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", cb.Target, cb.Target)
+	if _, err := git("fetch", "origin", refspec); err != nil {
+		return nil, err
+	}
+	if _, err := git("checkout", "-q", "--detach", "origin/"+cb.Target); err != nil {
+		return nil, err
+	}
+
+	if err := change(workDir); err != nil {
+		return nil, err
+	}
+	if _, err := gitCommit(ctx, cfg, workDir, message); err != nil {
+		if strings.Contains(err.Error(), "no changes to commit") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	base := "origin/" + cb.Target
+	findings, err := policy.ScanSecrets(ctx, workDir, base)
+	if err != nil {
+		return nil, fmt.Errorf("scanning for secrets: %w", err)
+	}
+	if len(findings) > 0 {
+		locations := make([]string, len(findings))
+		for i, f := range findings {
+			locations[i] = f.String()
+		}
+		return nil, fmt.Errorf("possible secret in the changes, not pushed: %s", strings.Join(locations, "; "))
+	}
+	files, err := git("diff", "--name-only", base+"..HEAD")
+	if err != nil {
+		return nil, err
+	}
+	cb.Files = strings.Split(files, "\n")
+	if cb.DiffStat, err = git("diff", "--stat", base+"..HEAD"); err != nil {
+		return nil, err
+	}
+	if err := gitPush(ctx, workDir, cb.Branch); err != nil {
+		return nil, err
+	}
+	audit.Log(audit.Entry{
+		Action: audit.ActionCommitPushed,
+		Branch: cb.Branch,
+		Commit: gitHeadShort(ctx, workDir),
+		Detail: strings.SplitN(message, "\n", 2)[0],
+	})
+	return cb, nil
+}
+
+// defaultBranch returns the default branch of the configured repo at
+// remote, as its origin/HEAD says, or "main".
+func defaultBranch(ctx context.Context, cfg *config.Config, remote string) string {
+	rc, err := repo.NewManager("").FindByRemoteURL(cfg, remote)
+	if err != nil {
+		return "main"
+	}
+	head, err := gitOutput(ctx, rc.PrimaryDir, "symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	if err != nil {
+		return "main"
+	}
+	return strings.TrimPrefix(strings.TrimSpace(head), "origin/")
+}
+
+// taskPRDescription describes the PR a command task opens.
+func taskPRDescription(task config.ScheduledTask, tb *ChangeBranch) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Automated changes from otto's scheduled task `%s` (`%s`), which ran:\n\n", task.Name, task.Schedule)
+	fmt.Fprintf(&b, "```sh\n%s\n```\n\n", task.Command)
+	fmt.Fprintf(&b, "## Changes\n\n```\n%s\n```\n", tb.DiffStat)
+	if tb.Failed {
+		fmt.Fprintf(&b, "\n## Command output\n\nThe command failed; the changes it made before failing are included.\n\n```\n%s\n```\n", strings.TrimSpace(tb.Output))
+	}
+	return b.String()
+}
+
+// RepoBackend returns a backend for the repository at remote. It is built
+// apart from the daemon's registry, whose backends are shared by every
+// poll, so pointing it at the repository changes nothing else.
+func RepoBackend(cfg *config.Config, remote string) (provider.PRBackend, error) {
+	reg := buildRegistry(cfg, nil, false)
+	backend, err := reg.Detect(remote)
+	if err != nil {
+		name := cfg.PR.DefaultProvider
+		if name == "" {
+			name = "ado"
+		}
+		if backend, err = reg.Get(name); err != nil {
+			return nil, err
+		}
+	}
+	path := strings.TrimSuffix(remote, ".git")
+	parts := strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == ':' })
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("cannot tell the repository from remote %q", remote)
+	}
+	switch b := backend.(type) {
+	case *ado.Backend:
+		b.SetRepository(parts[len(parts)-1])
+	case *ghbackend.Backend:
+		b.SetRepository(parts[len(parts)-2], parts[len(parts)-1])
+	}
+	return audit.WrapBackend(backend), nil
+}
+
+// ListScheduledTasks returns the configured tasks with their last runs
+// and when each is next due.
+func ListScheduledTasks(cfg *config.Config) ([]ScheduledTaskStatus, error) {
+	runs, err := loadTaskRuns()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	statuses := make([]ScheduledTaskStatus, 0, len(cfg.Schedules))
+	for _, task := range cfg.Schedules {
+		s := ScheduledTaskStatus{ScheduledTask: task, Last: runs[task.Name]}
+		if sched, err := cron.Parse(task.Schedule); err == nil && !task.Disabled {
+			from := now
+			if s.Last != nil {
+				from = s.Last.Since.In(now.Location())
+			}
+			s.Next = sched.Next(from)
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// updateTaskRun applies update to the named task's run record.
+func updateTaskRun(name string, update func(*TaskRun)) error {
+	path := schedulePath()
+	return store.WithLock(path, store.DefaultLockTimeout, func() error {
+		runs, err := readTaskRuns()
+		if err != nil {
+			return err
+		}
+		run := runs[name]
+		if run == nil {
+			run = &TaskRun{}
+			runs[name] = run
+		}
+		update(run)
+		data, err := json.MarshalIndent(runs, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling scheduled task runs: %w", err)
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return fmt.Errorf("writing scheduled task runs: %w", err)
+		}
+		return os.Rename(tmp, path)
+	})
+}
+
+// loadTaskRuns reads the run records under their lock.
+func loadTaskRuns() (map[string]*TaskRun, error) {
+	var runs map[string]*TaskRun
+	err := store.WithReadLock(schedulePath(), store.DefaultLockTimeout, func() error {
+		var err error
+		runs, err = readTaskRuns()
+		return err
+	})
+	return runs, err
+}
+
+// readTaskRuns loads the run records. A missing file is empty.
+func readTaskRuns() (map[string]*TaskRun, error) {
+	runs := make(map[string]*TaskRun)
+	data, err := os.ReadFile(schedulePath())
+	if os.IsNotExist(err) {
+		return runs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading scheduled task runs: %w", err)
+	}
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("parsing scheduled task runs: %w", err)
+	}
+	return runs, nil
+}
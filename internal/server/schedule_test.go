@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alanmeadows/otto/internal/config"
+)
+
+func TestDueTasks(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	nightly := config.ScheduledTask{Name: "nightly", Schedule: "0 2 * * *", Action: config.TaskRebase}
+	tasks := []config.ScheduledTask{
+		nightly,
+		{Name: "off", Schedule: "* * * * *", Action: config.TaskRebase, Disabled: true},
+	}
+	day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	// A task never seen is due only to be recorded; runDueTasks starts its
+	// clock rather than running it.
+	runs, err := loadTaskRuns()
+	require.NoError(t, err)
+	assert.Equal(t, []config.ScheduledTask{nightly}, dueTasks(tasks, runs, day.Add(time.Hour)))
+	runDueTasks(context.Background(), nil, nil, &config.Config{Schedules: tasks}, day.Add(time.Hour))
+	runs, err = loadTaskRuns()
+	require.NoError(t, err)
+	require.Contains(t, runs, "nightly")
+	assert.True(t, runs["nightly"].Started.IsZero(), "not run")
+
+	assert.Empty(t, dueTasks(tasks, runs, day.Add(time.Hour+59*time.Minute)))
+	assert.Equal(t, []config.ScheduledTask{nightly}, dueTasks(tasks, runs, day.Add(2*time.Hour)))
+	// Missed runs while the daemon was down come due once.
+	assert.Len(t, dueTasks(tasks, runs, day.Add(72*time.Hour)), 1)
+
+	statuses, err := ListScheduledTasks(&config.Config{Schedules: tasks})
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, day.Add(2*time.Hour), statuses[0].Next.UTC())
+	assert.True(t, statuses[1].Next.IsZero(), "disabled tasks are not due")
+}
+
+func TestPrepareTaskBranch(t *testing.T) {
+	repoDir := newRepoWithRemote(t)
+	origin := filepath.Join(filepath.Dir(repoDir), "origin.git")
+	mainBranch := strings.TrimSpace(gitT(t, repoDir, "rev-parse", "--abbrev-ref", "HEAD"))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "app.go"), []byte("package app\n"), 0644))
+	gitT(t, repoDir, "add", "-A")
+	gitT(t, repoDir, "commit", "-q", "-m", "add app")
+	gitT(t, repoDir, "push", "-q", "origin", "HEAD")
+
+	cfg := &config.Config{
+		Repos: []config.RepoConfig{{Name: "app", PrimaryDir: repoDir, GitStrategy: config.GitStrategyBranch}},
+		Git:   config.GitConfig{AuthorName: "otto", AuthorEmail: "otto@example.com"},
+	}
+	now := time.Date(2026, 3, 4, 2, 0, 0, 0, time.UTC)
+	task := config.ScheduledTask{Name: "fmt", Action: config.TaskCommand, Repo: "app", Target: mainBranch,
+		Command: "printf '// formatted\\n' >> app.go"}
+
+	tb, err := PrepareTaskBranch(context.Background(), cfg, origin, task, now)
+	require.NoError(t, err)
+	require.NotNil(t, tb)
+	assert.Equal(t, "otto/fmt-20260304-0200", tb.Branch)
+	assert.Equal(t, mainBranch, tb.Target)
+	assert.Equal(t, []string{"app.go"}, tb.Files)
+	assert.False(t, tb.Failed)
+
+	gitT(t, repoDir, "fetch", "-q", "origin")
+	assert.Equal(t, "package app\n// formatted\n", gitT(t, repoDir, "show", "origin/otto/fmt-20260304-0200:app.go"))
+	assert.Contains(t, gitT(t, repoDir, "log", "-1", "--format=%an %s", "origin/otto/fmt-20260304-0200"), "otto chore: fmt")
+	assert.Contains(t, taskPRDescription(task, tb), "app.go")
+
+	// A command that changes nothing opens nothing; one that fails without
+	// changes is an error.
+	task.Command = "true"
+	tb, err = PrepareTaskBranch(context.Background(), cfg, origin, task, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, tb)
+	task.Command = "echo broken; exit 3"
+	_, err = PrepareTaskBranch(context.Background(), cfg, origin, task, now.Add(time.Hour))
+	assert.ErrorContains(t, err, "broken")
+}
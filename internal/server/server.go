@@ -7,17 +7,20 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/alanmeadows/otto/internal/config"
 	"github.com/alanmeadows/otto/internal/dashboard"
-	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/httpcache"
 	"github.com/alanmeadows/otto/internal/provider"
 	"github.com/alanmeadows/otto/internal/provider/ado"
 	ghbackend "github.com/alanmeadows/otto/internal/provider/github"
+	"github.com/alanmeadows/otto/internal/ratelimit"
 )
 
 // pollTrigger is a channel that signals the monitor loop to run an immediate
@@ -55,6 +58,7 @@ func RunServer(ctx context.Context, port int, cfg *config.Config) error {
 	}
 	// Set for downstream consumers.
 	cfg.Dashboard.CopilotServer = copilotURL
+	liveConfig.Store(cfg)
 
 	mux := http.NewServeMux()
 	registerRoutes(mux)
@@ -71,21 +75,27 @@ func RunServer(ctx context.Context, port int, cfg *config.Config) error {
 
 	var wg sync.WaitGroup
 
+	// Repair state left behind by a previous crash or restart (stuck PRs,
+	// orphaned worktrees, stale locks, undelivered notifications) before
+	// the monitoring loop touches any of it.
+	RunRecoveryAudit(ctx, cfg)
+
 	// Start the monitoring loop in background (unless disabled).
 	if cfg.Server.NoPRMonitoring {
 		slog.Info("PR monitoring disabled via --no-pr-monitoring")
 	} else {
-		slog.Info("starting PR monitoring", "model", cfg.Models.Primary, "interval", cfg.PR.Providers)
-		llmClient := llm.NewCopilotClientWithServer(cfg.Models.Primary, copilotURL)
-		if err := llmClient.Start(ctx); err != nil {
-			slog.Warn("Copilot LLM client not available, PR monitoring disabled", "error", err)
+		backendName := cfg.Models.BackendFor(config.RolePrimary)
+		slog.Info("starting PR monitoring", "model", cfg.Models.Primary, "backend", backendName, "interval", cfg.PR.Providers)
+		llmClient, err := startLLMClient(ctx, cfg.Models, copilotURL)
+		if err != nil {
+			slog.Warn("LLM client not available, PR monitoring disabled", "backend", backendName, "error", err)
 		} else {
 			interval := cfg.Server.ParsePollInterval()
 			slog.Info("PR monitoring started", "model", cfg.Models.Primary, "poll_interval", interval)
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				defer llmClient.Stop()
+				defer stopLLMClient(llmClient)
 				if err := RunMonitorLoop(ctx, cfg, llmClient); err != nil {
 					slog.Error("monitoring loop error", "error", err)
 				}
@@ -104,11 +114,28 @@ func RunServer(ctx context.Context, port int, cfg *config.Config) error {
 			defer wg.Done()
 			dashSrv := dashboard.NewServer(cfg)
 			dashSrv.ListPRsFn = func() (any, error) { return ListPRs() }
-			dashSrv.GetPRFn = func(id string) (any, error) { return FindPRDetail(id) }
+			dashSrv.GetPRFn = func(id string) (any, error) {
+				detail, err := FindPRDetail(id)
+				if err != nil {
+					return nil, err
+				}
+				detail.Worktree = PRWorktree(ctx, liveConfig.Load(), detail.PRDocument)
+				return detail, nil
+			}
 			dashSrv.AddPRFn = func(ctx context.Context, prURL string) (any, error) {
-				return addPRByURL(ctx, prURL, cfg)
+				return addPRByURL(ctx, prURL, liveConfig.Load())
 			}
 			dashSrv.RemovePRFn = func(id string) error { return RemovePR(id) }
+			dashSrv.FixPRFn = func(id string) (any, error) { return RequestFix(id) }
+			dashSrv.PausePRFn = func(id string, paused bool) (any, error) { return SetPRPaused(id, paused) }
+			dashSrv.BranchPRsFn = func() map[string]dashboard.WorktreePR {
+				prs, _ := PRsByBranch()
+				out := make(map[string]dashboard.WorktreePR, len(prs))
+				for branch, pr := range prs {
+					out[branch] = dashboard.WorktreePR{ID: pr.ID, URL: pr.URL, Status: pr.Status}
+				}
+				return out
+			}
 			dashSrv.SetRestartHandler(func() error { return RestartDaemon() })
 			dashSrv.SetUpgradeHandler(func() error {
 				return UpgradeDaemon(cfg.Server.UpgradeChannel, cfg.Server.SourceDir)
@@ -145,9 +172,13 @@ func RunServer(ctx context.Context, port int, cfg *config.Config) error {
 // serverStartTime records when the server started for uptime calculation.
 var serverStartTime time.Time
 
+// lastPollTime records when the monitor loop last finished polling PRs, in
+// Unix nanoseconds; zero until the first poll.
+var lastPollTime atomic.Int64
+
 // addPRByURL detects the provider from a PR URL, fetches metadata, and saves it.
 func addPRByURL(ctx context.Context, prURL string, cfg *config.Config) (any, error) {
-	reg := buildRegistryFromConfig(cfg)
+	reg := BuildRegistry(cfg)
 
 	backend, err := reg.Detect(prURL)
 	if err != nil {
@@ -178,6 +209,7 @@ func addPRByURL(ctx context.Context, prURL string, cfg *config.Config) (any, err
 		MaxFixAttempts: maxAttempts,
 		Body:           fmt.Sprintf("# %s\n\n%s\n", prInfo.Title, prInfo.Description),
 	}
+	AnalyzeNewPR(ctx, cfg, backend, prInfo, pr, nil)
 
 	if err := SavePR(pr); err != nil {
 		return nil, fmt.Errorf("saving PR: %w", err)
@@ -187,18 +219,65 @@ func addPRByURL(ctx context.Context, prURL string, cfg *config.Config) (any, err
 	return pr, nil
 }
 
-// buildRegistryFromConfig creates a provider registry from config (server-side).
-func buildRegistryFromConfig(cfg *config.Config) *provider.Registry {
+// BuildRegistry creates a provider registry from config. It is shared by the
+// daemon, the dashboard, and CLI commands so every entry point resolves
+// providers identically.
+func BuildRegistry(cfg *config.Config) *provider.Registry {
+	return buildRegistry(cfg, nil, false)
+}
+
+// buildRegistry is BuildRegistry with each backend's transport passed
+// through wrap, when non-nil, e.g. to record or replay its traffic. offline
+// skips credential lookups (the Azure and GitHub CLIs), for replaying.
+//
+// The backends share one pr.rate_limits budget, under their conditional
+// request caches.
+func buildRegistry(cfg *config.Config, wrap func(http.RoundTripper) http.RoundTripper, offline bool) *provider.Registry {
 	reg := provider.NewRegistry()
 
-	if cfg.PR.Providers != nil {
+	var limits map[string]config.RateLimitConfig
+	if cfg != nil {
+		limits = cfg.PR.RateLimits
+	}
+	budget := ratelimit.New(limits)
+	transport := func(key func(*http.Request) string) http.RoundTripper {
+		rt := http.RoundTripper(httpcache.New(budget.Transport(nil, key)))
+		if wrap != nil {
+			rt = wrap(rt)
+		}
+		return rt
+	}
+	githubTransport := func() http.RoundTripper {
+		return transport(func(*http.Request) string { return "github" })
+	}
+
+	if cfg != nil && cfg.PR.Providers != nil {
+		// Named ADO instances go first so each claims its organization's
+		// URLs before the catch-all "ado" backend, project-scoped ones
+		// before organization-wide ones.
+		for _, name := range adoInstances(cfg.PR) {
+			reg.Register(newADOBackend(name, cfg.PR.Providers[name], transport(adoRateLimitKey), offline))
+		}
 		if adoCfg, ok := cfg.PR.Providers["ado"]; ok {
-			auth := ado.NewAuthProvider(adoCfg.PAT)
-			adoBackend := ado.NewBackend(adoCfg.Organization, adoCfg.Project, auth)
-			reg.Register(adoBackend)
+			reg.Register(newADOBackend("ado", adoCfg, transport(adoRateLimitKey), offline))
 		}
 		if ghCfg, ok := cfg.PR.Providers["github"]; ok {
 			ghBack := ghbackend.NewBackend("", "", ghCfg.Token)
+			if ghCfg.AppID != 0 && !offline {
+				appBack, err := newGitHubAppBackend(ghCfg)
+				if err != nil {
+					slog.Warn("GitHub App auth unavailable, falling back to token auth", "error", err)
+				} else {
+					ghBack = appBack
+				}
+			}
+			ghBack.SetTransport(githubTransport())
+			ghBack.SetCheckFilter(checkFilter(ghCfg.CheckFilter))
+			if ghCfg.BaseURL != "" {
+				if err := ghBack.SetBaseURL(ghCfg.BaseURL); err != nil {
+					slog.Warn("ignoring invalid GitHub base_url", "baseURL", ghCfg.BaseURL, "error", err)
+				}
+			}
 			reg.Register(ghBack)
 		}
 	}
@@ -206,18 +285,95 @@ func buildRegistryFromConfig(cfg *config.Config) *provider.Registry {
 	// Fallback: GitHub via GITHUB_TOKEN or gh CLI.
 	if !reg.HasBackendFor("github.com") {
 		token := os.Getenv("GITHUB_TOKEN")
-		if token == "" {
+		if token == "" && !offline {
 			if out, err := exec.Command("gh", "auth", "token").Output(); err == nil {
 				token = strings.TrimSpace(string(out))
 			}
 		}
 		ghBack := ghbackend.NewBackend("", "", token)
+		ghBack.SetTransport(githubTransport())
 		reg.Register(ghBack)
 	}
 
 	return reg
 }
 
+// adoInstances returns the names of the named ADO instances in pr.providers,
+// in the order their backends should claim URLs.
+func adoInstances(pr config.PRConfig) []string {
+	var names []string
+	for name := range pr.Providers {
+		if name != "ado" && pr.ProviderType(name) == "ado" {
+			names = append(names, name)
+		}
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		scopedA, scopedB := pr.Providers[a].Project != "", pr.Providers[b].Project != ""
+		if scopedA != scopedB {
+			if scopedA {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	return names
+}
+
+// newADOBackend creates the ADO backend for the pr.providers entry name.
+func newADOBackend(name string, adoCfg config.ProviderConfig, rt http.RoundTripper, offline bool) *ado.Backend {
+	auth := ado.NewAuthProvider(adoCfg.PAT)
+	if adoCfg.ClientSecret != "" || adoCfg.ClientCertificatePath != "" || adoCfg.ManagedIdentity {
+		credAuth, err := ado.NewCredentialAuthProvider(ado.Credential{
+			TenantID:        adoCfg.TenantID,
+			ClientID:        adoCfg.ClientID,
+			ClientSecret:    adoCfg.ClientSecret,
+			CertificatePath: config.ExpandHome(adoCfg.ClientCertificatePath),
+			ManagedIdentity: adoCfg.ManagedIdentity,
+		}, adoCfg.PAT)
+		if err != nil {
+			slog.Warn("ADO credential auth unavailable, falling back to the Azure CLI", "provider", name, "error", err)
+		} else {
+			auth = credAuth
+		}
+	}
+	if offline {
+		auth = ado.NewPATAuthProvider("offline")
+	}
+	adoBackend := ado.NewBackend(adoCfg.Organization, adoCfg.Project, auth)
+	if name != "ado" {
+		adoBackend.SetInstance(name)
+	}
+	adoBackend.SetTransport(rt)
+	adoBackend.SetCheckFilter(checkFilter(adoCfg.CheckFilter))
+	if adoCfg.BaseURL != "" {
+		adoBackend.SetBaseURL(adoCfg.BaseURL)
+	}
+	return adoBackend
+}
+
+// checkFilter converts a provider's check_filter setting.
+func checkFilter(c config.CheckFilterConfig) provider.CheckFilter {
+	return provider.CheckFilter{RequiredOnly: c.RequiredOnly, Include: c.Include, Exclude: c.Exclude}
+}
+
+// newGitHubAppBackend creates a GitHub backend authenticating as the
+// configured GitHub App installation.
+func newGitHubAppBackend(ghCfg config.ProviderConfig) (*ghbackend.Backend, error) {
+	key, err := os.ReadFile(config.ExpandHome(ghCfg.PrivateKeyPath))
+	if err != nil {
+		return nil, fmt.Errorf("reading GitHub App private key: %w", err)
+	}
+	return ghbackend.NewAppBackend("", "", ghCfg.AppID, ghCfg.InstallationID, key)
+}
+
+// adoRateLimitKey budgets ADO requests per organization, the first
+// segment of every ADO REST path.
+func adoRateLimitKey(req *http.Request) string {
+	org, _, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	return "ado/" + org
+}
+
 // RemovePR finds a PR by ID and deletes it.
 func RemovePR(id string) error {
 	pr, err := FindPR(id)
@@ -233,5 +389,8 @@ func registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /prs", handleAddPR)
 	mux.HandleFunc("DELETE /prs/{id}", handleDeletePR)
 	mux.HandleFunc("POST /prs/{id}/fix", handleFixPR)
+	mux.HandleFunc("POST /prs/{id}/pause", handlePausePR(true))
+	mux.HandleFunc("POST /prs/{id}/resume", handlePausePR(false))
 	mux.HandleFunc("POST /poll", handlePoll)
+	mux.HandleFunc("GET /v1/events", handleEvents)
 }
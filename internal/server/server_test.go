@@ -0,0 +1,35 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRegistryADOInstances(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.PR.Providers["ado"] = config.ProviderConfig{Organization: "home", Project: "proj"}
+	cfg.PR.Providers["ado-contoso"] = config.ProviderConfig{Type: "ado", Organization: "contoso"}
+	cfg.PR.Providers["ado-contoso-platform"] = config.ProviderConfig{Type: "ado", Organization: "contoso", Project: "Platform"}
+
+	reg := buildRegistry(&cfg, nil, true)
+
+	for url, want := range map[string]string{
+		"https://dev.azure.com/contoso/Platform/_git/api/pullrequest/1": "ado-contoso-platform",
+		"https://dev.azure.com/contoso/Web/_git/site/pullrequest/2":     "ado-contoso",
+		"https://contoso.visualstudio.com/Web/_git/site/pullrequest/3":  "ado-contoso",
+		"https://dev.azure.com/home/proj/_git/repo/pullrequest/4":       "ado",
+		"https://dev.azure.com/elsewhere/proj/_git/repo/pullrequest/5":  "ado",
+		"https://github.com/owner/repo/pull/6":                          "github",
+	} {
+		backend, err := reg.Detect(url)
+		require.NoError(t, err, url)
+		assert.Equal(t, want, backend.Name(), url)
+	}
+
+	backend, err := reg.Get("ado-contoso")
+	require.NoError(t, err)
+	assert.Equal(t, "ado-contoso", backend.Name())
+}
@@ -0,0 +1,273 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// launchdLabel names the launchd agent on macOS.
+const launchdLabel = "com.github.alanmeadows.otto"
+
+// windowsTaskName names the Task Scheduler task on Windows.
+const windowsTaskName = "otto"
+
+// InstallService registers the daemon with the platform's service manager
+// so it starts at login and is restarted if it crashes: a systemd user
+// unit on Linux, a launchd agent on macOS. On Windows a scheduled task
+// starts it at login, without crash restarts.
+func InstallService() error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home dir: %w", err)
+	}
+
+	// Find the otto binary path.
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding executable path: %w", err)
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		return installSystemdService(home, execPath)
+	case "darwin":
+		return installLaunchdService(home, execPath)
+	case "windows":
+		return installScheduledTask(execPath)
+	default:
+		return fmt.Errorf("service install is not supported on %s; run 'otto server start' instead", runtime.GOOS)
+	}
+}
+
+// UninstallService stops the daemon's service and removes its unit file,
+// launchd agent, or scheduled task.
+func UninstallService() error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home dir: %w", err)
+	}
+
+	var path string
+	switch runtime.GOOS {
+	case "linux":
+		path = systemdUnitPath(home)
+		if out, err := exec.Command("systemctl", "--user", "disable", "--now", "otto").CombinedOutput(); err != nil {
+			return fmt.Errorf("disabling service: %s: %w", string(out), err)
+		}
+	case "darwin":
+		path = launchdPlistPath(home)
+		// Fails harmlessly if the agent is not loaded.
+		_ = exec.Command("launchctl", "bootout", launchdDomain(), path).Run()
+	case "windows":
+		return uninstallScheduledTask()
+	default:
+		return fmt.Errorf("service install is not supported on %s", runtime.GOOS)
+	}
+
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no otto service installed at %s", path)
+		}
+		return fmt.Errorf("removing %s: %w", path, err)
+	}
+	if runtime.GOOS == "linux" {
+		_ = exec.Command("systemctl", "--user", "daemon-reload").Run()
+	}
+	fmt.Printf("removed %s\n", path)
+	return nil
+}
+
+func systemdUnitPath(home string) string {
+	return filepath.Join(home, ".config", "systemd", "user", "otto.service")
+}
+
+func launchdPlistPath(home string) string {
+	return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist")
+}
+
+// launchdDomain is the launchd domain of the current user's GUI session.
+func launchdDomain() string {
+	return "gui/" + strconv.Itoa(os.Getuid())
+}
+
+// systemdUnit renders the systemd user unit. PATH is carried over from the
+// installing shell, since the daemon runs git, az, gh, and copilot.
+func systemdUnit(execPath, home, path string) string {
+	return fmt.Sprintf(`[Unit]
+Description=Otto Daemon
+After=network.target
+
+[Service]
+Type=simple
+ExecStart=%s server start --foreground
+Restart=on-failure
+RestartSec=5s
+TimeoutStopSec=30
+Environment=HOME=%s
+Environment=PATH=%s
+
+[Install]
+WantedBy=default.target
+`, execPath, home, path)
+}
+
+// installSystemdService writes a systemd user unit file and enables the service.
+func installSystemdService(home, execPath string) error {
+	unitPath := systemdUnitPath(home)
+	if err := os.MkdirAll(filepath.Dir(unitPath), 0755); err != nil {
+		return fmt.Errorf("creating systemd directory: %w", err)
+	}
+
+	unit := systemdUnit(execPath, home, os.Getenv("PATH"))
+	if err := os.WriteFile(unitPath, []byte(unit), 0644); err != nil {
+		return fmt.Errorf("writing unit file: %w", err)
+	}
+
+	// Reload systemd and enable.
+	reloadCmd := exec.Command("systemctl", "--user", "daemon-reload")
+	if out, err := reloadCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("daemon-reload: %s: %w", string(out), err)
+	}
+
+	enableCmd := exec.Command("systemctl", "--user", "enable", "otto")
+	if out, err := enableCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("enabling service: %s: %w", string(out), err)
+	}
+
+	fmt.Printf("installed otto.service at %s\n", unitPath)
+	fmt.Println("service enabled. Start with: systemctl --user start otto")
+	return nil
+}
+
+// windowsTaskCommand is the command line the scheduled task runs. The
+// daemon forks into the background with no console window.
+func windowsTaskCommand(execPath string) string {
+	return `"` + execPath + `" server start`
+}
+
+// installScheduledTask registers a task that starts the daemon at logon,
+// replacing one from an earlier install.
+func installScheduledTask(execPath string) error {
+	out, err := exec.Command("schtasks", "/Create", "/F", "/TN", windowsTaskName,
+		"/SC", "ONLOGON", "/RL", "LIMITED", "/TR", windowsTaskCommand(execPath)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("creating scheduled task: %s: %w", string(out), err)
+	}
+	fmt.Printf("installed scheduled task %q\n", windowsTaskName)
+	fmt.Println("the daemon will start at every login. Start it now with: otto server start")
+	return nil
+}
+
+// uninstallScheduledTask removes the logon task. A running daemon is left
+// alone; stop it with 'otto server stop'.
+func uninstallScheduledTask() error {
+	out, err := exec.Command("schtasks", "/Delete", "/F", "/TN", windowsTaskName).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("removing scheduled task %q: %s: %w", windowsTaskName, string(out), err)
+	}
+	fmt.Printf("removed scheduled task %q\n", windowsTaskName)
+	return nil
+}
+
+// launchdPlist renders the launchd agent: started at login, restarted
+// unless it exits cleanly (e.g. after 'otto server stop').
+func launchdPlist(execPath, home, path, logFile string) string {
+	esc := func(s string) string {
+		var b bytes.Buffer
+		_ = xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+		<string>server</string>
+		<string>start</string>
+		<string>--foreground</string>
+	</array>
+	<key>EnvironmentVariables</key>
+	<dict>
+		<key>HOME</key>
+		<string>%s</string>
+		<key>PATH</key>
+		<string>%s</string>
+	</dict>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>ThrottleInterval</key>
+	<integer>5</integer>
+	<key>StandardOutPath</key>
+	<string>%s</string>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`, launchdLabel, esc(execPath), esc(home), esc(path), esc(logFile), esc(logFile))
+}
+
+// installLaunchdService writes a launchd agent and loads it, which starts
+// the daemon.
+func installLaunchdService(home, execPath string) error {
+	plistPath := launchdPlistPath(home)
+	if err := os.MkdirAll(filepath.Dir(plistPath), 0755); err != nil {
+		return fmt.Errorf("creating LaunchAgents directory: %w", err)
+	}
+	logFile := LogFilePath()
+	if err := os.MkdirAll(filepath.Dir(logFile), 0755); err != nil {
+		return fmt.Errorf("creating log directory: %w", err)
+	}
+
+	plist := launchdPlist(execPath, home, os.Getenv("PATH"), logFile)
+	if err := os.WriteFile(plistPath, []byte(plist), 0644); err != nil {
+		return fmt.Errorf("writing plist: %w", err)
+	}
+
+	// Replace an agent loaded by an earlier install.
+	_ = exec.Command("launchctl", "bootout", launchdDomain(), plistPath).Run()
+	if out, err := exec.Command("launchctl", "bootstrap", launchdDomain(), plistPath).CombinedOutput(); err != nil {
+		return fmt.Errorf("loading agent: %s: %w", string(out), err)
+	}
+
+	fmt.Printf("installed %s at %s\n", launchdLabel, plistPath)
+	fmt.Println("agent loaded; the daemon is starting and will start at every login")
+	return nil
+}
+
+// DaemonHealth asks the running daemon's API for its status, confirming it
+// is serving requests and not just alive.
+func DaemonHealth(port int) (*StatusResponse, error) {
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://localhost:%d/status", port))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status endpoint returned HTTP %d", resp.StatusCode)
+	}
+	var status StatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("decoding status: %w", err)
+	}
+	return &status, nil
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemdUnit(t *testing.T) {
+	unit := systemdUnit("/usr/local/bin/otto", "/home/me", "/usr/local/bin:/usr/bin")
+	assert.Contains(t, unit, "ExecStart=/usr/local/bin/otto server start --foreground\n")
+	assert.Contains(t, unit, "Environment=HOME=/home/me\n")
+	assert.Contains(t, unit, "Environment=PATH=/usr/local/bin:/usr/bin\n")
+	assert.Contains(t, unit, "Restart=on-failure\n")
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	plist := launchdPlist("/Users/me/go/bin/otto", "/Users/me", "/opt/homebrew/bin:/usr/bin", "/Users/me/R&D/ottod.log")
+
+	// The plist must be well-formed XML, with the ampersand escaped.
+	dec := xml.NewDecoder(strings.NewReader(plist))
+	var texts []string
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			assert.ErrorContains(t, err, "EOF")
+			break
+		}
+		if cd, ok := tok.(xml.CharData); ok && strings.TrimSpace(string(cd)) != "" {
+			texts = append(texts, string(cd))
+		}
+	}
+	assert.Contains(t, texts, launchdLabel)
+	assert.Contains(t, texts, "/Users/me/go/bin/otto")
+	assert.Contains(t, texts, "/Users/me/R&D/ottod.log")
+	assert.Contains(t, texts, "--foreground")
+}
+
+func TestWindowsTaskCommand(t *testing.T) {
+	// The path is quoted so one with spaces survives schtasks /TR.
+	assert.Equal(t, `"C:\Program Files\otto\otto.exe" server start`, windowsTaskCommand(`C:\Program Files\otto\otto.exe`))
+}
+
+func TestDaemonHealth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/status", r.URL.Path)
+		w.Write([]byte(`{"status":"running","uptime":"1m0s","pr_count":3}`))
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+
+	status, err := DaemonHealth(port)
+	require.NoError(t, err)
+	assert.Equal(t, 3, status.PRCount)
+
+	srv.Close()
+	_, err = DaemonHealth(port)
+	assert.Error(t, err)
+}
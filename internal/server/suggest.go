@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// With pr.suggest_comment_fixes, a fix for a review comment is offered as
+// a suggestion in the reply instead of being committed and pushed.
+
+// maxSuggestedPatchBytes bounds a patch posted in a reply; larger fixes
+// are described instead of shown.
+const maxSuggestedPatchBytes = 30000
+
+// hunk is one change block of a zero-context ("git diff -U0") diff.
+type hunk struct {
+	oldStart, oldCount int
+	added              []string
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+\d+(?:,\d+)? @@`)
+
+// suggestedChange returns a reply offering the uncommitted changes in
+// workDir as a fix for a comment on filePath:line, or "" when there are
+// none. When inline is set and the change only touches the commented line
+// (or inserts lines right next to it), the reply is a GitHub suggestion
+// block the author can apply with one click; otherwise it is a patch.
+func suggestedChange(ctx context.Context, workDir, filePath string, line int, inline bool) (string, error) {
+	if err := runGit(ctx, workDir, "add", "-A"); err != nil {
+		return "", err
+	}
+	patch, err := gitOutput(ctx, workDir, "diff", "--cached", "--no-color")
+	if err != nil || patch == "" {
+		return "", err
+	}
+
+	if inline && filePath != "" && line > 0 {
+		names, err := gitOutput(ctx, workDir, "diff", "--cached", "--name-only")
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(names) == filePath {
+			zero, err := gitOutput(ctx, workDir, "diff", "--cached", "--no-color", "-U0", "--", filePath)
+			if err != nil {
+				return "", err
+			}
+			original, err := gitOutput(ctx, workDir, "show", "HEAD:"+filePath)
+			if err != nil {
+				return "", err
+			}
+			if lines, ok := suggestionLines(parseHunks(zero), line, splitLines(original)); ok {
+				return "Suggested change:\n\n```suggestion\n" + strings.Join(lines, "\n") + "\n```", nil
+			}
+		}
+	}
+
+	if len(patch) > maxSuggestedPatchBytes {
+		return "The suggested fix is too large to show here; it changes:\n\n" + diffStat(patch), nil
+	}
+	return "Suggested change (apply with `git apply`):\n\n```diff\n" + patch + "```", nil
+}
+
+// parseHunks parses the hunks of a zero-context diff.
+func parseHunks(diff string) []hunk {
+	var hunks []hunk
+	for _, l := range strings.Split(diff, "\n") {
+		if m := hunkHeader.FindStringSubmatch(l); m != nil {
+			h := hunk{oldCount: 1}
+			h.oldStart, _ = strconv.Atoi(m[1])
+			if m[2] != "" {
+				h.oldCount, _ = strconv.Atoi(m[2])
+			}
+			hunks = append(hunks, h)
+			continue
+		}
+		if len(hunks) > 0 && strings.HasPrefix(l, "+") && !strings.HasPrefix(l, "+++") {
+			last := &hunks[len(hunks)-1]
+			last.added = append(last.added, l[1:])
+		}
+	}
+	return hunks
+}
+
+// suggestionLines returns the lines that replace line (1-based) in a
+// suggestion block, if hunks amount to a change of that line alone:
+// replacing or deleting it, or inserting lines directly before or after
+// it. original is the file before the change.
+func suggestionLines(hunks []hunk, line int, original []string) ([]string, bool) {
+	if len(hunks) != 1 || line > len(original) {
+		return nil, false
+	}
+	h := hunks[0]
+	switch {
+	case h.oldCount == 1 && h.oldStart == line:
+		return h.added, true
+	case h.oldCount == 0 && h.oldStart == line:
+		return append([]string{original[line-1]}, h.added...), true
+	case h.oldCount == 0 && h.oldStart == line-1:
+		return append(h.added, original[line-1]), true
+	default:
+		return nil, false
+	}
+}
+
+// discardChanges drops every uncommitted change in workDir, staged or not.
+func discardChanges(ctx context.Context, workDir string) error {
+	if err := runGit(ctx, workDir, "reset", "-q", "--hard", "HEAD"); err != nil {
+		return err
+	}
+	return runGit(ctx, workDir, "clean", "-fdq")
+}
+
+// diffStat lists the files a patch touches, one per line.
+func diffStat(patch string) string {
+	var files []string
+	for _, l := range strings.Split(patch, "\n") {
+		if name, ok := strings.CutPrefix(l, "+++ b/"); ok {
+			files = append(files, "- `"+name+"`")
+		}
+	}
+	return strings.Join(files, "\n")
+}
+
+// gitOutput runs git in dir and returns its standard output.
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("git %s: %s: %w", args[0], strings.TrimSpace(string(exitErr.Stderr)), err)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
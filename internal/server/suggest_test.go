@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestionLines(t *testing.T) {
+	original := []string{"a", "b", "c"}
+	tests := []struct {
+		name  string
+		hunks []hunk
+		want  []string
+		ok    bool
+	}{
+		{"replace", []hunk{{oldStart: 2, oldCount: 1, added: []string{"B"}}}, []string{"B"}, true},
+		{"delete", []hunk{{oldStart: 2, oldCount: 1}}, nil, true},
+		{"insert after", []hunk{{oldStart: 2, added: []string{"b2"}}}, []string{"b", "b2"}, true},
+		{"insert before", []hunk{{oldStart: 1, added: []string{"a2"}}}, []string{"a2", "b"}, true},
+		{"other line", []hunk{{oldStart: 3, oldCount: 1, added: []string{"C"}}}, nil, false},
+		{"several lines", []hunk{{oldStart: 2, oldCount: 2, added: []string{"B", "C"}}}, nil, false},
+		{"several hunks", []hunk{{oldStart: 2, oldCount: 1}, {oldStart: 3, oldCount: 1}}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := suggestionLines(tt.hunks, 2, original)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSuggestedChange(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	gitT(t, dir, "init", "-q")
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	write("main.go", "package main\n\nvar x = 1\n")
+	gitT(t, dir, "add", "-A")
+	gitT(t, dir, "commit", "-q", "-m", "init")
+
+	write("main.go", "package main\n\nvar x = 2\n")
+	reply, err := suggestedChange(ctx, dir, "main.go", 3, true)
+	require.NoError(t, err)
+	assert.Equal(t, "Suggested change:\n\n```suggestion\nvar x = 2\n```", reply)
+
+	// ADO has no suggestion blocks, so the same change is a patch.
+	reply, err = suggestedChange(ctx, dir, "main.go", 3, false)
+	require.NoError(t, err)
+	assert.Contains(t, reply, "```diff\ndiff --git a/main.go b/main.go")
+	assert.Contains(t, reply, "-var x = 1\n+var x = 2\n")
+
+	// A change beyond the commented line is a patch too.
+	write("util.go", "package main\n")
+	reply, err = suggestedChange(ctx, dir, "main.go", 3, true)
+	require.NoError(t, err)
+	assert.Contains(t, reply, "b/util.go")
+
+	require.NoError(t, discardChanges(ctx, dir))
+	reply, err = suggestedChange(ctx, dir, "main.go", 3, true)
+	require.NoError(t, err)
+	assert.Empty(t, reply, "nothing left to suggest after discarding")
+}
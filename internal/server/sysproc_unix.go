@@ -2,8 +2,25 @@
 
 package server
 
-import "syscall"
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
 
 func daemonSysProcAttr() *syscall.SysProcAttr {
 	return &syscall.SysProcAttr{Setsid: true}
 }
+
+// terminateProcess asks proc to shut down gracefully.
+func terminateProcess(proc *os.Process) error {
+	return proc.Signal(syscall.SIGTERM)
+}
+
+// signalReload asks the daemon process to reload its config.
+func signalReload(proc *os.Process) error {
+	if err := proc.Signal(syscall.SIGHUP); err != nil {
+		return fmt.Errorf("sending SIGHUP: %w", err)
+	}
+	return nil
+}
@@ -2,8 +2,24 @@
 
 package server
 
-import "syscall"
+import (
+	"errors"
+	"os"
+	"syscall"
+)
 
 func daemonSysProcAttr() *syscall.SysProcAttr {
 	return &syscall.SysProcAttr{HideWindow: true}
 }
+
+// terminateProcess stops proc. Windows has no SIGTERM for a detached
+// process, so the daemon is ended outright; PR state is saved as it
+// changes and the recovery audit tidies up at the next start.
+func terminateProcess(proc *os.Process) error {
+	return proc.Kill()
+}
+
+// signalReload reports that reloading by signal is unavailable on Windows.
+func signalReload(*os.Process) error {
+	return errors.New("reload signals are not supported on Windows; the daemon reloads on its own within 10 seconds of a config change, or run 'otto server stop' and 'otto server start'")
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alanmeadows/otto/internal/llm"
+)
+
+// Transcript is one recorded LLM session of a PR operation.
+type Transcript struct {
+	Path    string
+	Title   string
+	Started time.Time
+}
+
+// transcriptDir holds the transcripts of one PR's LLM sessions.
+func transcriptDir(providerName, id string) string {
+	return filepath.Join(filepath.Dir(PRDir()), "transcripts", strings.TrimSuffix(prFilename(providerName, id), ".md"))
+}
+
+// recordTranscript wraps client so the sessions it creates for pr are
+// recorded in the PR's transcript directory.
+func recordTranscript(client llm.Client, pr *PRDocument) llm.Client {
+	return llm.NewTranscriptClient(client, transcriptDir(pr.Provider, pr.ID))
+}
+
+// ListTranscripts returns the recorded LLM sessions of pr, oldest first.
+func ListTranscripts(pr *PRDocument) ([]Transcript, error) {
+	dir := transcriptDir(pr.Provider, pr.ID)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading transcript directory: %w", err)
+	}
+
+	var transcripts []Transcript
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+			continue
+		}
+		t := Transcript{Path: filepath.Join(dir, e.Name())}
+		stamp, _, _ := strings.Cut(e.Name(), "-")
+		t.Started, _ = time.Parse("20060102T150405.000000000", stamp)
+		t.Title = transcriptTitle(t.Path)
+		transcripts = append(transcripts, t)
+	}
+	sort.Slice(transcripts, func(i, j int) bool { return transcripts[i].Path < transcripts[j].Path })
+	return transcripts, nil
+}
+
+// transcriptTitle returns the session title from a transcript's heading.
+func transcriptTitle(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	line, _ := bufio.NewReader(f).ReadString('\n')
+	return strings.TrimSpace(strings.TrimPrefix(line, "# "))
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTranscripts(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	pr := &PRDocument{ID: "42", Provider: "ado"}
+
+	transcripts, err := ListTranscripts(pr)
+	require.NoError(t, err)
+	assert.Empty(t, transcripts)
+
+	client := recordTranscript(llm.NewMockClient(), pr)
+	ctx := context.Background()
+	for _, title := range []string{"PR Fix Analysis #42", "PR Fix #42 attempt 1"} {
+		s, err := client.CreateSession(ctx, title, "")
+		require.NoError(t, err)
+		_, err = client.SendPrompt(ctx, s.ID, "go")
+		require.NoError(t, err)
+	}
+
+	transcripts, err = ListTranscripts(pr)
+	require.NoError(t, err)
+	require.Len(t, transcripts, 2)
+	assert.Equal(t, "PR Fix Analysis #42", transcripts[0].Title)
+	assert.Equal(t, "PR Fix #42 attempt 1", transcripts[1].Title)
+	assert.False(t, transcripts[0].Started.IsZero())
+}
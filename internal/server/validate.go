@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/repo"
+	"github.com/alanmeadows/otto/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// repoChecks returns the validation commands configured for the repo a PR
+// belongs to, or nil if the repo is unknown or has none.
+func repoChecks(cfg *config.Config, prURL string) []string {
+	r, err := repo.NewManager("").FindByRemoteURL(cfg, prURL)
+	if err != nil {
+		return nil
+	}
+	return r.Checks
+}
+
+// validationPrompt asks the LLM to fix the failures from a validation run.
+func validationPrompt(results []repo.CheckResult) string {
+	var b strings.Builder
+	b.WriteString("Your changes were validated by running the repository's checks in the working directory, and some failed.\n\n")
+	b.WriteString(repo.FormatCheckFailures(results))
+	b.WriteString(`
+
+## Instructions
+
+1. Fix the code so these checks pass
+2. Keep the fix for the original CI failure intact
+3. Do NOT introduce unnecessary changes — fix only what's broken`)
+	return b.String()
+}
+
+// validateFix runs the repo's checks against an LLM fix in workDir. While
+// checks fail, their output is sent back on the fix session for another
+// iteration, up to maxLoops times. It returns the results of the last run.
+func validateFix(ctx context.Context, pr *PRDocument, client llm.Client, sessionID, workDir string, checks []string, maxLoops int) ([]repo.CheckResult, error) {
+	ctx, span := telemetry.Start(ctx, "fix.validate", attribute.Int("validate.checks", len(checks)))
+	var retErr error
+	defer func() { telemetry.End(span, retErr) }()
+
+	for loop := 0; ; loop++ {
+		results := repo.RunChecks(ctx, workDir, checks)
+		failed := repo.FailedChecks(results)
+		if len(failed) == 0 {
+			slog.Info("fix passed validation", "prID", pr.ID, "loops", loop)
+			return results, nil
+		}
+		if loop >= maxLoops {
+			slog.Warn("fix still failing validation", "prID", pr.ID, "failed", len(failed), "loops", loop)
+			return results, nil
+		}
+
+		slog.Info("fix failed validation, asking LLM for another iteration", "prID", pr.ID, "failed", len(failed), "loop", loop+1)
+		resp, err := client.SendPrompt(ctx, sessionID, validationPrompt(results))
+		if err != nil {
+			retErr = fmt.Errorf("validation loop %d: %w", loop+1, err)
+			return results, retErr
+		}
+		recordModel(pr, resp)
+	}
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/llm"
+	"github.com/alanmeadows/otto/internal/repo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFix(t *testing.T) {
+	ctx := context.Background()
+	pr := &PRDocument{ID: "42"}
+
+	t.Run("iterates until checks pass", func(t *testing.T) {
+		client := llm.NewMockClient()
+		session, err := client.CreateSession(ctx, "fix", "")
+		require.NoError(t, err)
+		// Fails on the first run, passes once the marker exists.
+		check := "test -f fixed || { touch fixed; echo not yet; exit 1; }"
+
+		results, err := validateFix(ctx, pr, client, session.ID, t.TempDir(), []string{check}, 2)
+		require.NoError(t, err)
+		assert.Empty(t, repo.FailedChecks(results))
+		history := client.GetPromptHistory()
+		require.Len(t, history, 1)
+		assert.Contains(t, history[0].Prompt, "not yet")
+	})
+
+	t.Run("stops after max loops", func(t *testing.T) {
+		client := llm.NewMockClient()
+		session, err := client.CreateSession(ctx, "fix", "")
+		require.NoError(t, err)
+
+		results, err := validateFix(ctx, pr, client, session.ID, t.TempDir(), []string{"false"}, 2)
+		require.NoError(t, err)
+		assert.Len(t, repo.FailedChecks(results), 1)
+		assert.Len(t, client.GetPromptHistory(), 2)
+	})
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/alanmeadows/otto/internal/provider/ado"
+)
+
+// discoverWatchedPRs starts tracking the open PRs matched by pr.watch rules
+// that are not tracked yet, and returns how many it added. A PR removed
+// with otto pr remove is picked up again while a rule still matches it.
+func discoverWatchedPRs(ctx context.Context, reg *provider.Registry, cfg *config.Config) (int, error) {
+	added := 0
+	for i, rule := range cfg.PR.Watch {
+		providerName := rule.Provider
+		if providerName == "" {
+			providerName = cfg.PR.DefaultProvider
+		}
+		if providerName == "" {
+			providerName = "ado"
+		}
+		if _, paused := authState.paused(providerName); paused {
+			continue
+		}
+		backend, err := reg.Get(providerName)
+		if err != nil {
+			slog.Warn("skipping watch rule", "rule", i, "provider", providerName, "error", err)
+			continue
+		}
+
+		authors := rule.Authors
+		if len(authors) == 0 {
+			authors = []string{""}
+		}
+		for _, author := range authors {
+			prs, err := backend.ListPRs(ctx, provider.PRFilter{
+				Author:       author,
+				BranchPrefix: rule.BranchPrefix,
+				Label:        rule.Label,
+				Repo:         rule.Repo,
+			})
+			if err != nil {
+				if errors.Is(err, ado.ErrAuthExpired) {
+					authState.fail(ctx, cfg, providerName, err)
+					return added, err
+				}
+				slog.Warn("failed to list PRs for watch rule", "rule", i, "author", author, "error", err)
+				continue
+			}
+			for _, info := range prs {
+				if _, err := LoadPR(backend.Name(), info.ID); err == nil {
+					continue
+				}
+				if err := SavePR(NewTrackedPR(info, backend.Name(), cfg)); err != nil {
+					slog.Error("failed to track watched PR", "prID", info.ID, "error", err)
+					continue
+				}
+				slog.Info("tracking PR matched by watch rule", "prID", info.ID, "title", info.Title, "rule", i)
+				added++
+			}
+		}
+	}
+	return added, nil
+}
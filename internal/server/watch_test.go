@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/alanmeadows/otto/internal/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listingBackend serves ListPRs from a fixed set; other calls panic.
+type listingBackend struct {
+	provider.PRBackend
+	prs     []*provider.PRInfo
+	filters []provider.PRFilter
+}
+
+func (b *listingBackend) Name() string               { return "github" }
+func (b *listingBackend) MatchesURL(url string) bool { return false }
+func (b *listingBackend) ListPRs(ctx context.Context, filter provider.PRFilter) ([]*provider.PRInfo, error) {
+	b.filters = append(b.filters, filter)
+	var out []*provider.PRInfo
+	for _, pr := range b.prs {
+		if filter.Author == "" || pr.Author == filter.Author {
+			out = append(out, pr)
+		}
+	}
+	return out, nil
+}
+
+func TestDiscoverWatchedPRs(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	backend := &listingBackend{prs: []*provider.PRInfo{
+		{ID: "1", Author: "alice", Title: "one"},
+		{ID: "2", Author: "bob", Title: "two"},
+		{ID: "3", Author: "carol", Title: "three"},
+	}}
+	reg := provider.NewRegistry()
+	reg.Register(backend)
+	require.NoError(t, SavePR(&PRDocument{ID: "2", Provider: "github", Status: "green"}))
+
+	cfg := &config.Config{}
+	cfg.PR.Watch = []config.WatchRule{{Provider: "github", Repo: "org/repo", Authors: []string{"alice", "bob"}, Label: "otto"}}
+
+	added, err := discoverWatchedPRs(context.Background(), reg, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+	assert.Equal(t, []provider.PRFilter{
+		{Author: "alice", Label: "otto", Repo: "org/repo"},
+		{Author: "bob", Label: "otto", Repo: "org/repo"},
+	}, backend.filters)
+
+	pr, err := LoadPR("github", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "watching", pr.Status)
+	existing, err := LoadPR("github", "2")
+	require.NoError(t, err)
+	assert.Equal(t, "green", existing.Status, "already tracked PRs are left alone")
+	_, err = LoadPR("github", "3")
+	assert.Error(t, err)
+}
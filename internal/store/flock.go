@@ -1,61 +1,164 @@
 package store
 
 import (
-	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gofrs/flock"
 )
 
-// DefaultLockTimeout is the default timeout for acquiring a file lock.
-const DefaultLockTimeout = 5 * time.Second
+// DefaultLockTimeout is the default timeout for acquiring a file lock. The
+// daemon and CLI set it from server.lock_timeout.
+var DefaultLockTimeout = 15 * time.Second
+
+// ErrLockTimeout is returned (wrapped, with the lock's holder) when a lock
+// is not acquired within the timeout.
+var ErrLockTimeout = errors.New("timed out acquiring lock")
+
+// lockRetryInterval is how often a contended lock is retried.
+const lockRetryInterval = 100 * time.Millisecond
 
 // WithLock acquires an exclusive lock on path.lock, runs fn, then releases.
 func WithLock(path string, timeout time.Duration, fn func() error) error {
-	lockPath := path + ".lock"
-	// Ensure parent directory exists so the lock file can be created.
-	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
-		return fmt.Errorf("creating lock directory: %w", err)
+	fileLock, err := acquireLock(path+".lock", timeout, false)
+	if err != nil {
+		return err
 	}
-	fileLock := flock.New(lockPath)
+	defer releaseLock(fileLock, true)
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	return fn()
+}
 
-	locked, err := fileLock.TryLockContext(ctx, 100*time.Millisecond)
+// WithReadLock acquires a shared read lock on path.lock, runs fn, then releases.
+func WithReadLock(path string, timeout time.Duration, fn func() error) error {
+	fileLock, err := acquireLock(path+".lock", timeout, true)
 	if err != nil {
-		return fmt.Errorf("acquiring lock on %s: %w", lockPath, err)
-	}
-	if !locked {
-		return fmt.Errorf("timed out acquiring lock on %s", lockPath)
+		return err
 	}
-	defer fileLock.Unlock()
+	defer releaseLock(fileLock, false)
 
 	return fn()
 }
 
-// WithReadLock acquires a shared read lock on path.lock, runs fn, then releases.
-func WithReadLock(path string, timeout time.Duration, fn func() error) error {
-	lockPath := path + ".lock"
+// acquireLock takes the lock on lockPath, retrying until timeout. An
+// exclusive holder records itself in the lock file, overwriting any
+// earlier record, so a timeout can say who holds the lock. The kernel
+// releases a lock when its holder exits, so a lock that is still held is
+// never stale and is never broken.
+func acquireLock(lockPath string, timeout time.Duration, shared bool) (*flock.Flock, error) {
+	// Ensure parent directory exists so the lock file can be created.
 	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
-		return fmt.Errorf("creating lock directory: %w", err)
+		return nil, fmt.Errorf("creating lock directory: %w", err)
 	}
+	kind := "lock"
+	if shared {
+		kind = "read lock"
+	}
+
+	start := time.Now()
 	fileLock := flock.New(lockPath)
+	for {
+		var locked bool
+		var err error
+		if shared {
+			locked, err = fileLock.TryRLock()
+		} else {
+			locked, err = fileLock.TryLock()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("acquiring %s on %s: %w", kind, lockPath, err)
+		}
+		if locked {
+			lockStats.acquired(time.Since(start))
+			if !shared {
+				writeLockOwner(lockPath)
+			}
+			return fileLock, nil
+		}
+
+		if time.Since(start) >= timeout {
+			lockStats.timedOut()
+			// Readers don't record themselves, so the record may name an
+			// earlier writer; it is only a hint.
+			if owner := LockOwner(lockPath); owner != "" {
+				return nil, fmt.Errorf("%w on %s after %s (last held by %s)", ErrLockTimeout, lockPath, timeout, owner)
+			}
+			return nil, fmt.Errorf("%w on %s after %s", ErrLockTimeout, lockPath, timeout)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+// releaseLock clears the owner record of an exclusive lock and unlocks it.
+func releaseLock(fileLock *flock.Flock, exclusive bool) {
+	if exclusive {
+		_ = os.Truncate(fileLock.Path(), 0)
+	}
+	if err := fileLock.Unlock(); err != nil {
+		slog.Warn("failed to release lock", "path", fileLock.Path(), "error", err)
+	}
+}
 
-	locked, err := fileLock.TryRLockContext(ctx, 100*time.Millisecond)
+// writeLockOwner records this process as the holder of lockPath. The lock
+// is on the file itself, so its content is only informational. Windows
+// refuses the write while the lock is held, so there the record is absent.
+func writeLockOwner(lockPath string) {
+	host, _ := os.Hostname()
+	owner := fmt.Sprintf("pid %d on %s since %s", os.Getpid(), host, time.Now().UTC().Format(time.RFC3339))
+	if err := os.WriteFile(lockPath, []byte(owner+"\n"), 0644); err != nil {
+		slog.Debug("failed to record lock owner", "path", lockPath, "error", err)
+	}
+}
+
+// LockOwner returns the owner record in lockPath, or "" if it has none.
+func LockOwner(lockPath string) string {
+	data, err := os.ReadFile(lockPath)
 	if err != nil {
-		return fmt.Errorf("acquiring read lock on %s: %w", lockPath, err)
+		return ""
 	}
-	if !locked {
-		return fmt.Errorf("timed out acquiring read lock on %s", lockPath)
+	return strings.TrimSpace(string(data))
+}
+
+// LockStats counts this process's file lock activity.
+type LockStats struct {
+	Acquired  int64         `json:"acquired"`
+	Contended int64         `json:"contended"` // acquisitions that had to wait
+	Timeouts  int64         `json:"timeouts"`
+	MaxWait   time.Duration `json:"max_wait"`
+}
+
+type lockCounters struct {
+	mu sync.Mutex
+	s  LockStats
+}
+
+var lockStats lockCounters
+
+func (c *lockCounters) acquired(wait time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.s.Acquired++
+	if wait >= lockRetryInterval {
+		c.s.Contended++
 	}
-	defer fileLock.Unlock()
+	c.s.MaxWait = max(c.s.MaxWait, wait)
+}
 
-	return fn()
+func (c *lockCounters) timedOut() {
+	c.mu.Lock()
+	c.s.Timeouts++
+	c.mu.Unlock()
+}
+
+// Stats returns the lock counters of this process.
+func Stats() LockStats {
+	lockStats.mu.Lock()
+	defer lockStats.mu.Unlock()
+	return lockStats.s
 }
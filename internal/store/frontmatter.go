@@ -27,6 +27,33 @@ func GetInt(fm map[string]any, key string) int {
 	return 0
 }
 
+// GetIntMap returns a string-to-int map from frontmatter.
+func GetIntMap(fm map[string]any, key string) map[string]int {
+	v, ok := fm[key]
+	if !ok {
+		return nil
+	}
+	// The frontmatter decoder may produce either key type for nested maps.
+	m := make(map[string]any)
+	switch raw := v.(type) {
+	case map[string]any:
+		m = raw
+	case map[any]any:
+		for k, val := range raw {
+			if s, ok := k.(string); ok {
+				m[s] = val
+			}
+		}
+	default:
+		return nil
+	}
+	result := make(map[string]int, len(m))
+	for k := range m {
+		result[k] = GetInt(m, k)
+	}
+	return result
+}
+
 // GetBool returns a bool value from frontmatter.
 func GetBool(fm map[string]any, key string) bool {
 	if v, ok := fm[key]; ok {
@@ -0,0 +1,24 @@
+//go:build windows
+
+package store
+
+import "syscall"
+
+// stillActive is the exit code Windows reports for a running process.
+const stillActive = 259
+
+// ProcessAlive reports whether a process with the given PID is running.
+// Windows keeps exited processes openable while handles to them remain, so
+// the exit code is checked as well.
+func ProcessAlive(pid int) bool {
+	h, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(h) //nolint:errcheck
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
@@ -1,13 +1,16 @@
 package store
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/gofrs/flock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -39,6 +42,26 @@ func TestWriteAndReadDocumentWithFrontmatter(t *testing.T) {
 	assert.Contains(t, got.Body, "This is the body.")
 }
 
+func TestGetIntMapRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.md")
+
+	doc := &Document{
+		Frontmatter: map[string]any{
+			"counts": map[string]int{"compile": 2, "lint": 1},
+			"scalar": 3,
+		},
+	}
+	require.NoError(t, WriteDocument(path, doc))
+
+	got, err := ReadDocument(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{"compile": 2, "lint": 1}, GetIntMap(got.Frontmatter, "counts"))
+	assert.Nil(t, GetIntMap(got.Frontmatter, "scalar"))
+	assert.Nil(t, GetIntMap(got.Frontmatter, "missing"))
+}
+
 func TestWriteAndReadDocumentWithoutFrontmatter(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "plain.md")
@@ -210,11 +233,34 @@ func TestWithLockTimeout(t *testing.T) {
 		t.Fatal("callback should not have been called")
 		return nil
 	})
-	assert.Error(t, err, "expected timeout error when lock is held")
+	assert.ErrorIs(t, err, ErrLockTimeout, "expected timeout error when lock is held")
+	if runtime.GOOS != "windows" { // Windows can't read a locked file's owner record
+		assert.Contains(t, err.Error(), fmt.Sprintf("last held by pid %d", os.Getpid()))
+	}
 
 	close(release) // let the first goroutine release the lock
 }
 
+func TestWithLockKeepsLiveLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "livetest")
+
+	held := flock.New(path + ".lock")
+	locked, err := held.TryLock()
+	require.NoError(t, err)
+	require.True(t, locked)
+	defer held.Close()
+	writeLockOwner(path + ".lock")
+
+	before := Stats().Timeouts
+	err = WithLock(path, 1500*time.Millisecond, func() error {
+		t.Fatal("callback should not have been called")
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrLockTimeout)
+	assert.Equal(t, before+1, Stats().Timeouts)
+}
+
 // --- Frontmatter helpers ---
 
 func TestGetString(t *testing.T) {
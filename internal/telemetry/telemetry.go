@@ -0,0 +1,117 @@
+// Package telemetry wires OpenTelemetry tracing for otto's long-running,
+// multi-phase operations (PR fixes, conflict resolution, provider calls).
+//
+// Tracing is opt-in via the "telemetry" config section. When disabled, the
+// global no-op tracer provider is left in place so instrumented code paths
+// cost next to nothing.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies otto's tracer to OpenTelemetry backends.
+const instrumentationName = "github.com/alanmeadows/otto"
+
+// ShutdownFunc flushes pending spans and releases exporter resources.
+type ShutdownFunc func(context.Context) error
+
+// Setup installs a global tracer provider that exports spans via OTLP/HTTP.
+// If tracing is disabled, it returns a no-op shutdown function and leaves the
+// default no-op provider in place. The version string is recorded as the
+// service.version resource attribute.
+func Setup(ctx context.Context, cfg config.TelemetryConfig, version string) (ShutdownFunc, error) {
+	noop := func(context.Context) error { return nil }
+	if !cfg.Enabled {
+		return noop, nil
+	}
+
+	var opts []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.URLPath != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(cfg.URLPath))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return noop, fmt.Errorf("creating OTLP exporter: %w", err)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "otto"
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return noop, fmt.Errorf("building telemetry resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio(cfg.SampleRatio)))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	slog.Info("OpenTelemetry tracing enabled", "endpoint", cfg.Endpoint, "service", serviceName)
+	return tp.Shutdown, nil
+}
+
+// sampleRatio clamps the configured ratio into (0, 1], defaulting to 1.
+func sampleRatio(r float64) float64 {
+	if r <= 0 || r > 1 {
+		return 1
+	}
+	return r
+}
+
+// Tracer returns otto's tracer from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start begins a span as a child of any span already in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on the span (if non-nil), marks its status, and ends it.
+// Intended for use with a named error return:
+//
+//	ctx, span := telemetry.Start(ctx, "fix.analysis")
+//	defer func() { telemetry.End(span, retErr) }()
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
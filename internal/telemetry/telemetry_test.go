@@ -0,0 +1,47 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alanmeadows/otto/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup_DisabledIsNoop(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TelemetryConfig{Enabled: false}, "test")
+	require.NoError(t, err)
+	require.NotNil(t, shutdown)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSampleRatio(t *testing.T) {
+	assert.Equal(t, 1.0, sampleRatio(0))
+	assert.Equal(t, 1.0, sampleRatio(-0.5))
+	assert.Equal(t, 1.0, sampleRatio(2))
+	assert.Equal(t, 0.25, sampleRatio(0.25))
+}
+
+func TestEnd_RecordsError(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	defer tp.Shutdown(context.Background())
+
+	_, ok := tp.Tracer("test").Start(context.Background(), "ok")
+	End(ok, nil)
+	_, failed := tp.Tracer("test").Start(context.Background(), "failed")
+	End(failed, errors.New("boom"))
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "boom", spans[1].Status().Description)
+	require.Len(t, spans[1].Events(), 1)
+	assert.Equal(t, "exception", spans[1].Events()[0].Name)
+}
@@ -0,0 +1,139 @@
+package tunnel
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ngrokStartTimeout bounds how long Start waits for ngrok to report its URL.
+const ngrokStartTimeout = 30 * time.Second
+
+// ngrokProvider exposes the dashboard with an ngrok HTTP endpoint. The
+// agent runs as a child of otto and stops with it. Its auth token comes
+// from ngrok's own config (`ngrok config add-authtoken`) or NGROK_AUTHTOKEN.
+type ngrokProvider struct {
+	mu   sync.Mutex
+	cmd  *exec.Cmd
+	done chan struct{} // closed when cmd exits
+}
+
+func (*ngrokProvider) Name() string { return ProviderNgrok }
+
+// Missing checks for the ngrok agent.
+func (*ngrokProvider) Missing(Config) string {
+	if _, err := exec.LookPath("ngrok"); err != nil {
+		return "ngrok is not installed — see https://ngrok.com/download"
+	}
+	return ""
+}
+
+// Start runs `ngrok http` for port and waits for it to log its public URL.
+func (p *ngrokProvider) Start(ctx context.Context, cfg Config, port int) (string, error) {
+	p.stop()
+
+	args := []string{"http", strconv.Itoa(port), "--log", "stdout", "--log-format", "json"}
+	if cfg.Domain != "" {
+		args = append(args, "--domain", cfg.Domain)
+	}
+	cmd := exec.CommandContext(ctx, "ngrok", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("starting ngrok: %w", err)
+	}
+	done := make(chan struct{})
+	p.mu.Lock()
+	p.cmd, p.done = cmd, done
+	p.mu.Unlock()
+
+	urls := make(chan string, 1)
+	go func() {
+		scanNgrokLog(stdout, urls)
+		cmd.Wait() //nolint:errcheck
+		close(done)
+	}()
+
+	select {
+	case url := <-urls:
+		return url, nil
+	case <-done:
+		return "", errors.New("ngrok exited before reporting a URL — check `ngrok config check` and the auth token")
+	case <-time.After(ngrokStartTimeout):
+		p.stop()
+		return "", errors.New("timed out waiting for the ngrok URL")
+	}
+}
+
+// Stop stops the ngrok agent.
+func (p *ngrokProvider) Stop(Config) error {
+	p.stop()
+	return nil
+}
+
+// Connected reports whether the ngrok agent is still running.
+func (p *ngrokProvider) Connected(Config) bool {
+	p.mu.Lock()
+	done := p.done
+	p.mu.Unlock()
+	if done == nil {
+		return false
+	}
+	select {
+	case <-done:
+		return false
+	default:
+		return true
+	}
+}
+
+// stop kills the running agent, if any, and waits for it to exit.
+func (p *ngrokProvider) stop() {
+	p.mu.Lock()
+	cmd, done := p.cmd, p.done
+	p.cmd, p.done = nil, nil
+	p.mu.Unlock()
+	if cmd == nil {
+		return
+	}
+	if cmd.Process != nil {
+		cmd.Process.Kill() //nolint:errcheck
+	}
+	<-done
+}
+
+// scanNgrokLog reads ngrok's JSON log lines, sends the first public URL to
+// urls, and logs errors. It reads until r closes so the agent never blocks
+// on a full pipe.
+func scanNgrokLog(r io.Reader, urls chan<- string) {
+	sent := false
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		var line struct {
+			Lvl string `json:"lvl"`
+			Msg string `json:"msg"`
+			URL string `json:"url"`
+			Err string `json:"err"`
+		}
+		if json.Unmarshal(sc.Bytes(), &line) != nil {
+			continue
+		}
+		if line.Msg == "started tunnel" && line.URL != "" && !sent {
+			urls <- line.URL
+			sent = true
+		}
+		if line.Lvl == "eror" || line.Lvl == "crit" {
+			slog.Warn("ngrok", "msg", line.Msg, "error", line.Err)
+		}
+	}
+}
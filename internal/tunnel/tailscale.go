@@ -0,0 +1,148 @@
+package tunnel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tailscaleProvider exposes the dashboard with Tailscale Funnel. The funnel
+// is configured in tailscaled with --bg, so like a bgtask devtunnel it
+// outlives otto.
+type tailscaleProvider struct {
+	mu   sync.Mutex
+	port int
+}
+
+func (*tailscaleProvider) Name() string { return ProviderTailscale }
+
+// Missing checks for the tailscale CLI and a running, logged-in node.
+func (*tailscaleProvider) Missing(Config) string {
+	if _, err := exec.LookPath("tailscale"); err != nil {
+		return "tailscale is not installed — see https://tailscale.com/download"
+	}
+	if st, err := tailscaleStatus(); err != nil || st.BackendState != "Running" {
+		return "tailscale is not connected — run: tailscale up"
+	}
+	return ""
+}
+
+// Start serves port through the funnel on the node's HTTPS name.
+func (p *tailscaleProvider) Start(ctx context.Context, cfg Config, port int) (string, error) {
+	st, err := tailscaleStatus()
+	if err != nil {
+		return "", err
+	}
+	url := st.url()
+	if url == "" {
+		return "", errors.New("tailscale node has no DNS name — enable MagicDNS and HTTPS for the tailnet")
+	}
+	p.mu.Lock()
+	p.port = port
+	p.mu.Unlock()
+	if funnelServes(port) {
+		return url, nil
+	}
+	if err := tailscaleCmd(ctx, "funnel", "--bg", strconv.Itoa(port)); err != nil {
+		return "", fmt.Errorf("tailscale funnel: %w (is Funnel enabled in the tailnet policy?)", err)
+	}
+	return url, nil
+}
+
+// Stop turns the funnel off.
+func (*tailscaleProvider) Stop(Config) error {
+	return tailscaleCmd(context.Background(), "funnel", "--https=443", "off")
+}
+
+// tailscaleCmd runs a tailscale CLI command, returning its output as the
+// error if it fails.
+func tailscaleCmd(ctx context.Context, args ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "tailscale", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Connected reports whether the node is up and still funnels the port.
+func (p *tailscaleProvider) Connected(Config) bool {
+	p.mu.Lock()
+	port := p.port
+	p.mu.Unlock()
+	st, err := tailscaleStatus()
+	return err == nil && st.BackendState == "Running" && funnelServes(port)
+}
+
+// tailscaleNodeStatus is the part of `tailscale status --json` otto reads.
+type tailscaleNodeStatus struct {
+	BackendState string
+	Self         struct {
+		DNSName string
+	}
+}
+
+// url returns the node's public HTTPS URL.
+func (st tailscaleNodeStatus) url() string {
+	name := strings.TrimSuffix(st.Self.DNSName, ".")
+	if name == "" {
+		return ""
+	}
+	return "https://" + name
+}
+
+func tailscaleStatus() (tailscaleNodeStatus, error) {
+	var st tailscaleNodeStatus
+	out, err := exec.Command("tailscale", "status", "--json").Output()
+	if err != nil {
+		return st, fmt.Errorf("tailscale status: %w", err)
+	}
+	if err := json.Unmarshal(out, &st); err != nil {
+		return st, fmt.Errorf("parsing tailscale status: %w", err)
+	}
+	return st, nil
+}
+
+// funnelServes reports whether the funnel is on and proxies to port.
+func funnelServes(port int) bool {
+	out, err := exec.Command("tailscale", "funnel", "status", "--json").Output()
+	if err != nil {
+		return false
+	}
+	return funnelConfigServes(out, port)
+}
+
+// funnelConfigServes reports whether a serve config, as printed by
+// `tailscale funnel status --json`, funnels some host to port.
+func funnelConfigServes(data []byte, port int) bool {
+	var sc struct {
+		Web map[string]struct {
+			Handlers map[string]struct {
+				Proxy string
+			}
+		}
+		AllowFunnel map[string]bool
+	}
+	if json.Unmarshal(data, &sc) != nil {
+		return false
+	}
+	target := ":" + strconv.Itoa(port)
+	for hostPort, on := range sc.AllowFunnel {
+		if !on {
+			continue
+		}
+		for _, h := range sc.Web[hostPort].Handlers {
+			if strings.HasSuffix(strings.TrimSuffix(h.Proxy, "/"), target) {
+				return true
+			}
+		}
+	}
+	return false
+}
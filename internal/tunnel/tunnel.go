@@ -0,0 +1,264 @@
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Provider names.
+const (
+	ProviderDevtunnel = "devtunnel"
+	ProviderTailscale = "tailscale"
+	ProviderNgrok     = "ngrok"
+)
+
+// healthCheckInterval is how often a running tunnel is checked.
+const healthCheckInterval = 2 * time.Minute
+
+// Config controls tunnel creation and access.
+type Config struct {
+	Provider string // "devtunnel" (default), "tailscale", or "ngrok"
+	TunnelID string // devtunnel: persistent tunnel name; empty = ephemeral
+	Access   string // devtunnel: "anonymous", "tenant", or "" (authenticated, the default)
+	AllowOrg string // devtunnel: GitHub org to allow
+	Domain   string // ngrok: reserved domain; empty = a random one
+}
+
+// Provider hosts a public tunnel to a local port through one tunneling
+// service.
+type Provider interface {
+	// Name returns the provider's config name.
+	Name() string
+	// Missing returns a hint naming what must be installed or configured
+	// before the provider can start, or "" if it is ready.
+	Missing(cfg Config) string
+	// Start forwards port, attaching to a tunnel left running by an earlier
+	// otto when there is a healthy one, and returns the public URL.
+	Start(ctx context.Context, cfg Config, port int) (string, error)
+	// Stop tears the tunnel down.
+	Stop(cfg Config) error
+	// Connected reports whether the tunnel is still serving.
+	Connected(cfg Config) bool
+}
+
+// NewProvider returns the provider with the given name; empty means
+// devtunnel.
+func NewProvider(name string) (Provider, error) {
+	switch name {
+	case "", ProviderDevtunnel:
+		return devtunnelProvider{}, nil
+	case ProviderTailscale:
+		return &tailscaleProvider{}, nil
+	case ProviderNgrok:
+		return &ngrokProvider{}, nil
+	default:
+		return nil, fmt.Errorf("unknown tunnel provider %q (want devtunnel, tailscale, or ngrok)", name)
+	}
+}
+
+// Manager runs the dashboard's tunnel through its configured provider,
+// tracks its status, and restarts it when its health check fails.
+type Manager struct {
+	mu             sync.Mutex
+	provider       Provider
+	url            string
+	running        bool
+	port           int
+	statusHint     string // human-readable reason when tunnel is not active
+	onStatusChange func(running bool, url string)
+	config         Config
+}
+
+// NewManager returns a new devtunnel Manager with default (authenticated) config.
+func NewManager() *Manager {
+	return NewManagerWithConfig(Config{})
+}
+
+// NewManagerWithConfig returns a tunnel Manager with the given config. An
+// unknown provider leaves the manager unable to start, with a hint saying
+// why.
+func NewManagerWithConfig(cfg Config) *Manager {
+	m := &Manager{config: cfg}
+	p, err := NewProvider(cfg.Provider)
+	if err != nil {
+		m.statusHint = err.Error()
+	}
+	m.provider = p
+	return m
+}
+
+// SetStatusHandler registers a callback invoked whenever the tunnel status changes.
+func (m *Manager) SetStatusHandler(fn func(running bool, url string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onStatusChange = fn
+}
+
+// ProviderName returns the name of the manager's provider.
+func (m *Manager) ProviderName() string {
+	if m.provider == nil {
+		return m.config.Provider
+	}
+	return m.provider.Name()
+}
+
+// Missing returns a hint naming what the provider still needs before the
+// tunnel can start, or "" if it is ready.
+func (m *Manager) Missing() string {
+	m.mu.Lock()
+	cfg := m.config
+	m.mu.Unlock()
+	if m.provider == nil {
+		return m.StatusHint()
+	}
+	return m.provider.Missing(cfg)
+}
+
+// UpdateConfig replaces the tunnel configuration. Takes effect on next
+// Start(). The provider stays the one the manager was created with.
+func (m *Manager) UpdateConfig(cfg Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cfg.Provider = m.config.Provider
+	m.config = cfg
+}
+
+// Start brings the tunnel up on the given port. If the provider is missing
+// something it records a hint, logs it, and returns nil.
+func (m *Manager) Start(ctx context.Context, port int) error {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return nil
+	}
+	cfg := m.config
+	m.mu.Unlock()
+
+	if hint := m.Missing(); hint != "" {
+		slog.Warn("tunnel skipped", "provider", m.ProviderName(), "reason", hint)
+		m.setHint(hint)
+		return nil
+	}
+
+	url, err := m.provider.Start(ctx, cfg, port)
+	if err != nil {
+		m.setHint(err.Error())
+		return fmt.Errorf("starting %s tunnel: %w", m.provider.Name(), err)
+	}
+
+	m.mu.Lock()
+	m.running = true
+	m.url = url
+	m.port = port
+	m.statusHint = ""
+	cb := m.onStatusChange
+	m.mu.Unlock()
+
+	slog.Info("tunnel started", "provider", m.provider.Name(), "url", url)
+	if cb != nil {
+		cb(true, url)
+	}
+	go m.healthMonitor(ctx, port)
+	return nil
+}
+
+// Stop tears the tunnel down.
+func (m *Manager) Stop() error {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return nil
+	}
+	cfg := m.config
+	m.mu.Unlock()
+
+	if err := m.provider.Stop(cfg); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.running = false
+	m.url = ""
+	cb := m.onStatusChange
+	m.mu.Unlock()
+
+	slog.Info("tunnel stopped", "provider", m.provider.Name())
+	if cb != nil {
+		cb(false, "")
+	}
+	return nil
+}
+
+// Status returns whether the tunnel is running and its public URL.
+func (m *Manager) Status() (bool, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.running, m.url
+}
+
+// StatusHint returns a human-readable hint about why the tunnel is not active.
+func (m *Manager) StatusHint() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.statusHint
+}
+
+// URL returns the current tunnel URL, or empty string if not running.
+func (m *Manager) URL() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.url
+}
+
+func (m *Manager) setHint(hint string) {
+	m.mu.Lock()
+	m.statusHint = hint
+	m.mu.Unlock()
+}
+
+// healthMonitor periodically checks that the tunnel is still serving and
+// restarts it through the provider when it is not.
+func (m *Manager) healthMonitor(ctx context.Context, port int) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.mu.Lock()
+			running := m.running
+			cfg := m.config
+			m.mu.Unlock()
+			if !running {
+				return
+			}
+			if m.provider.Connected(cfg) {
+				continue
+			}
+
+			slog.Warn("tunnel health check failed, restarting", "provider", m.provider.Name())
+			url, err := m.provider.Start(ctx, cfg, port)
+			if err != nil {
+				slog.Error("failed to restart tunnel after health check failure", "provider", m.provider.Name(), "error", err)
+				m.setHint(err.Error())
+				continue
+			}
+
+			m.mu.Lock()
+			changed := url != m.url
+			m.url = url
+			m.statusHint = ""
+			cb := m.onStatusChange
+			m.mu.Unlock()
+			slog.Info("tunnel restarted by health monitor", "provider", m.provider.Name(), "url", url)
+			if changed && cb != nil {
+				cb(true, url)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvider struct {
+	missing  string
+	startErr error
+	stopped  bool
+}
+
+func (*fakeProvider) Name() string            { return "fake" }
+func (f *fakeProvider) Missing(Config) string { return f.missing }
+func (f *fakeProvider) Connected(Config) bool { return !f.stopped }
+func (f *fakeProvider) Stop(Config) error     { f.stopped = true; return nil }
+func (f *fakeProvider) Start(context.Context, Config, int) (string, error) {
+	if f.startErr != nil {
+		return "", f.startErr
+	}
+	f.stopped = false
+	return "https://otto.example.com", nil
+}
+
+func TestNewProvider(t *testing.T) {
+	for name, want := range map[string]string{"": ProviderDevtunnel, "devtunnel": ProviderDevtunnel, "tailscale": ProviderTailscale, "ngrok": ProviderNgrok} {
+		p, err := NewProvider(name)
+		require.NoError(t, err)
+		assert.Equal(t, want, p.Name())
+	}
+	_, err := NewProvider("frp")
+	assert.Error(t, err)
+
+	m := NewManagerWithConfig(Config{Provider: "frp"})
+	assert.Contains(t, m.Missing(), "unknown tunnel provider")
+}
+
+func TestManagerLifecycle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fake := &fakeProvider{missing: "fake is not installed"}
+	m := &Manager{provider: fake}
+	var events []string
+	m.SetStatusHandler(func(running bool, url string) {
+		events = append(events, url)
+	})
+
+	require.NoError(t, m.Start(ctx, 4098))
+	running, _ := m.Status()
+	assert.False(t, running)
+	assert.Equal(t, "fake is not installed", m.StatusHint())
+
+	fake.missing = ""
+	fake.startErr = errors.New("not logged in")
+	assert.Error(t, m.Start(ctx, 4098))
+	assert.Equal(t, "not logged in", m.StatusHint())
+
+	fake.startErr = nil
+	require.NoError(t, m.Start(ctx, 4098))
+	running, url := m.Status()
+	assert.True(t, running)
+	assert.Equal(t, "https://otto.example.com", url)
+	assert.Empty(t, m.StatusHint())
+
+	require.NoError(t, m.Stop())
+	assert.True(t, fake.stopped)
+	assert.Equal(t, []string{"https://otto.example.com", ""}, events)
+}
+
+func TestFunnelConfigServes(t *testing.T) {
+	status := []byte(`{
+		"TCP": {"443": {"HTTPS": true}},
+		"Web": {"node.tail1234.ts.net:443": {"Handlers": {"/": {"Proxy": "http://127.0.0.1:4098"}}}},
+		"AllowFunnel": {"node.tail1234.ts.net:443": true}
+	}`)
+	assert.True(t, funnelConfigServes(status, 4098))
+	assert.False(t, funnelConfigServes(status, 4099))
+	assert.False(t, funnelConfigServes([]byte(`{}`), 4098))
+
+	served := []byte(`{"Web": {"node.tail1234.ts.net:443": {"Handlers": {"/": {"Proxy": "http://127.0.0.1:4098"}}}}}`)
+	assert.False(t, funnelConfigServes(served, 4098), "served on the tailnet but not funneled")
+}
+
+func TestScanNgrokLog(t *testing.T) {
+	log := strings.Join([]string{
+		`not json`,
+		`{"lvl":"info","msg":"starting web service","addr":"127.0.0.1:4040"}`,
+		`{"lvl":"info","msg":"started tunnel","name":"command_line","addr":"http://localhost:4098","url":"https://abcd.ngrok-free.app"}`,
+		`{"lvl":"info","msg":"started tunnel","url":"https://second.ngrok-free.app"}`,
+	}, "\n")
+	urls := make(chan string, 1)
+	scanNgrokLog(strings.NewReader(log), urls)
+	assert.Equal(t, "https://abcd.ngrok-free.app", <-urls)
+}